@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cloudflare/tf-migrate/internal"
+	"github.com/cloudflare/tf-migrate/internal/coverage"
+	"github.com/cloudflare/tf-migrate/internal/registry"
+	"github.com/cloudflare/tf-migrate/internal/schema"
+	"github.com/cloudflare/tf-migrate/internal/transform"
+)
+
+var rootCmd = &cobra.Command{
+	Use:   "coverage-matrix",
+	Short: "Report which v4 attributes each migrator handles",
+	Long: `Builds an attribute-level coverage matrix of the registered migrators.
+
+Every attribute of every v4 resource and data source type is synthesised into a
+configuration, migrated, and looked up in the output, which is checked against
+the v5 provider schema. Each attribute is reported as unchanged, renamed,
+converted, dropped (with a diagnostic), dropped-silently, invalid (passed
+through to configuration the v5 schema rejects) or error.
+
+Generate the schemas with ` + "`terraform providers schema -json`" + ` in a directory that
+requires the v4 and the v5 provider respectively.
+
+Examples:
+  # Full matrix
+  coverage-matrix --v4-schema v4.json --v5-schema v5.json
+
+  # Only the attributes that need attention, for two resource types
+  coverage-matrix --v4-schema v4.json --v5-schema v5.json --problems-only \
+    --resources cloudflare_record,cloudflare_zone`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		v4Path, _ := cmd.Flags().GetString("v4-schema")
+		v5Path, _ := cmd.Flags().GetString("v5-schema")
+		sourceVersion, _ := cmd.Flags().GetString("source-version")
+		targetVersion, _ := cmd.Flags().GetString("target-version")
+		format, _ := cmd.Flags().GetString("format")
+		problemsOnly, _ := cmd.Flags().GetBool("problems-only")
+		resources, _ := cmd.Flags().GetString("resources")
+
+		if format != "text" && format != "json" {
+			return fmt.Errorf("unsupported format %q: use text or json", format)
+		}
+		v4, err := schema.Load(v4Path)
+		if err != nil {
+			return err
+		}
+		v5, err := schema.Load(v5Path)
+		if err != nil {
+			return err
+		}
+
+		var types []string
+		for _, r := range strings.Split(resources, ",") {
+			if r = strings.TrimSpace(r); r != "" {
+				types = append(types, r)
+			}
+		}
+
+		matrix := coverage.Build(coverage.Options{
+			V4:            v4,
+			V5:            v5,
+			Providers:     transform.NewMigrationProvider(internal.GetMigrator, internal.GetAllMigrators),
+			SourceVersion: sourceVersion,
+			TargetVersion: targetVersion,
+			Types:         types,
+		})
+
+		if format == "json" {
+			return coverage.WriteJSON(os.Stdout, matrix)
+		}
+		return coverage.WriteText(os.Stdout, matrix, problemsOnly)
+	},
+}
+
+func init() {
+	rootCmd.Flags().String("v4-schema", "", "terraform providers schema -json output of the source provider version")
+	rootCmd.Flags().String("v5-schema", "", "terraform providers schema -json output of the target provider version")
+	rootCmd.Flags().String("source-version", "v4", "Source provider version of the migrators")
+	rootCmd.Flags().String("target-version", "v5", "Target provider version of the migrators")
+	rootCmd.Flags().String("resources", "", "Only cover these v4 types, e.g. cloudflare_record,data.cloudflare_zone (comma-separated)")
+	rootCmd.Flags().String("format", "text", "Output format: text or json")
+	rootCmd.Flags().Bool("problems-only", false, "Only show attributes that are dropped silently, invalid in v5, or fail to migrate")
+	_ = rootCmd.MarkFlagRequired("v4-schema")
+	_ = rootCmd.MarkFlagRequired("v5-schema")
+}
+
+func main() {
+	// Initialize the migration registry once at startup
+	registry.RegisterAllMigrations()
+
+	if err := rootCmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	tfhcl "github.com/cloudflare/tf-migrate/internal/transform/hcl"
+)
+
+const (
+	backupSuffix = ".backup"
+
+	// backupManifestName is the file, next to the backups of a directory,
+	// that records what tf-migrate wrote to each backed-up file.
+	backupManifestName = ".tf-migrate-backups.json"
+)
+
+// backupManifest records the content tf-migrate wrote to each file of a
+// directory that it backed up, so that restore and cleanup can tell whether a
+// file was edited since it was migrated.
+type backupManifest struct {
+	// Files maps the base name of a migrated file to the SHA-256 of the
+	// content tf-migrate wrote to it.
+	Files map[string]string `json:"files"`
+}
+
+func readBackupManifest(dir string) (*backupManifest, error) {
+	m := &backupManifest{Files: make(map[string]string)}
+	data, err := os.ReadFile(filepath.Join(dir, backupManifestName))
+	if os.IsNotExist(err) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup manifest: %w", err)
+	}
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", filepath.Join(dir, backupManifestName), err)
+	}
+	if m.Files == nil {
+		m.Files = make(map[string]string)
+	}
+	return m, nil
+}
+
+// write writes m to dir, or removes the manifest of dir when m is empty.
+func (m *backupManifest) write(dir string) error {
+	path := filepath.Join(dir, backupManifestName)
+	if len(m.Files) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+		return nil
+	}
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// editedSinceMigration returns why file, whose content is content, may not
+// be overwritten, or "" when it holds exactly what tf-migrate wrote to it.
+func (m *backupManifest) editedSinceMigration(file string, content []byte) string {
+	recorded, ok := m.Files[filepath.Base(file)]
+	if !ok {
+		return "no record of what tf-migrate wrote to it"
+	}
+	if recorded != contentDigest(content) {
+		return "edited since it was migrated"
+	}
+	return ""
+}
+
+func contentDigest(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// recordBackups records the migrated content of every output file that has a
+// backup in the manifest of its directory.
+func recordBackups(outputPaths []string, contents map[string]string) error {
+	byDir := make(map[string][]string)
+	for _, path := range outputPaths {
+		if _, err := os.Stat(path + backupSuffix); err != nil {
+			continue
+		}
+		dir := filepath.Dir(path)
+		byDir[dir] = append(byDir[dir], path)
+	}
+
+	for dir, paths := range byDir {
+		m, err := readBackupManifest(dir)
+		if err != nil {
+			return err
+		}
+		for _, path := range paths {
+			m.Files[filepath.Base(path)] = contentDigest([]byte(contents[path]))
+		}
+		if err := m.write(dir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// findBackupFiles returns the .backup files of configuration files under dir,
+// skipping directories as findTerraformFilesWithRecursion does.
+func findBackupFiles(dir string, recursive bool, exclude []string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var backups []string
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		if entry.IsDir() {
+			if !recursive || isIgnoredDir(entry.Name()) || shouldExclude(entry.Name(), exclude) {
+				continue
+			}
+			sub, err := findBackupFiles(path, recursive, exclude)
+			if err != nil {
+				continue
+			}
+			backups = append(backups, sub...)
+			continue
+		}
+		if !strings.HasSuffix(entry.Name(), backupSuffix) {
+			continue
+		}
+		original := strings.TrimSuffix(entry.Name(), backupSuffix)
+		if strings.HasSuffix(original, ".tf") || tfhcl.IsJSONConfigFile(original) || isTerragruntConfigFile(original) {
+			backups = append(backups, path)
+		}
+	}
+	sort.Strings(backups)
+	return backups, nil
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+
+	"github.com/cloudflare/tf-migrate/internal/cache"
+	"github.com/cloudflare/tf-migrate/internal/pipeline"
+	"github.com/cloudflare/tf-migrate/internal/postprocess"
+	"github.com/cloudflare/tf-migrate/internal/transform"
+)
+
+// openCache opens the --cache-dir cache. Its fingerprint covers every input of
+// the run that can change how a file is migrated, besides the file itself and
+// its module's variables and locals, which are part of each file's key.
+//
+// Runs with plugins are not cached, as a plugin's behaviour is not versioned
+// with tf-migrate.
+func openCache(cfg *config) error {
+	if cfg.cacheDir == "" {
+		return nil
+	}
+	if len(cfg.pluginPaths) > 0 {
+		if cfg.verbose {
+			fmt.Println("Cache: disabled, as plugins are in use")
+		}
+		return nil
+	}
+	if cfg.reviewer != nil {
+		// A cached file would skip the review of its blocks.
+		if cfg.verbose {
+			fmt.Println("Cache: disabled, as transformations are reviewed")
+		}
+		return nil
+	}
+
+	toolVersion, err := cacheToolVersion()
+	if err != nil {
+		return err
+	}
+	resources := append([]string(nil), cfg.resourcesToMigrate...)
+	sort.Strings(resources)
+	fingerprint := []string{
+		toolVersion,
+		cfg.sourceVersion,
+		cfg.targetVersion,
+		strings.Join(resources, ","),
+		fmt.Sprint(cfg.migrationsFile != ""),
+		cacheRenameDigest(cfg),
+	}
+	for _, path := range cfg.ruleFiles {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read rule file: %w", err)
+		}
+		fingerprint = append(fingerprint, string(content))
+	}
+	if cfg.stateFile != "" {
+		content, err := os.ReadFile(cfg.stateFile)
+		if err != nil {
+			return fmt.Errorf("failed to read state file: %w", err)
+		}
+		fingerprint = append(fingerprint, cache.Digest(string(content)))
+	}
+
+	c, err := cache.Open(cfg.cacheDir, fingerprint...)
+	if err != nil {
+		return err
+	}
+	cfg.cache = c
+	return nil
+}
+
+var (
+	executableDigestOnce sync.Once
+	executableDigest     string
+	executableDigestErr  error
+)
+
+// cacheToolVersion returns the version that cache entries are keyed by. A dev
+// build has no version to tell it from the next one, so the digest of the
+// executable stands in for it.
+func cacheToolVersion() (string, error) {
+	if version != "dev" {
+		return version, nil
+	}
+	executableDigestOnce.Do(func() {
+		path, err := os.Executable()
+		if err != nil {
+			executableDigestErr = fmt.Errorf("failed to locate the tf-migrate executable for the cache: %w", err)
+			return
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			executableDigestErr = fmt.Errorf("failed to read the tf-migrate executable for the cache: %w", err)
+			return
+		}
+		executableDigest = "dev-" + cache.Digest(string(content))
+	})
+	return executableDigest, executableDigestErr
+}
+
+// cacheRenameDigest digests the cross-file renames collected from the
+// ResourceRenamer and AttributeRenamer migrators. Cached entries hold the
+// pipeline's output only, and cross-file references are rewritten in every
+// file on every run; the renames are part of the fingerprint so that a change
+// to them, from a rule file or --resources, never mixes with entries written
+// under the old ones.
+func cacheRenameDigest(cfg *config) string {
+	providers := getProviders(cfg.resourcesToMigrate...)
+	migrators := providers.GetAllMigrators(cfg.sourceVersion, cfg.targetVersion, cfg.resourcesToMigrate...)
+	rules := postprocess.CollectRules(hclog.NewNullLogger(), migrators)
+
+	var lines []string
+	for from, to := range rules.Renames {
+		lines = append(lines, "type "+from+" "+to)
+	}
+	for _, r := range rules.AttributeRenames {
+		lines = append(lines, "attribute "+r.ResourceType+" "+r.OldAttribute+" "+r.NewAttribute)
+	}
+	sort.Strings(lines)
+	return cache.Digest(lines...)
+}
+
+// evalContextDigests memoizes the digest of each module's evaluation context.
+var evalContextDigests sync.Map // *hcl.EvalContext -> string
+
+// evalContextDigest digests the variables and locals migrators can resolve in
+// ctx.
+func evalContextDigest(ctx *hcl.EvalContext) string {
+	if ctx == nil {
+		return ""
+	}
+	if digest, ok := evalContextDigests.Load(ctx); ok {
+		return digest.(string)
+	}
+	names := make([]string, 0, len(ctx.Variables))
+	for name := range ctx.Variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, 0, 2*len(names))
+	for _, name := range names {
+		parts = append(parts, name, ctx.Variables[name].GoString())
+	}
+	digest := cache.Digest(parts...)
+	evalContextDigests.Store(ctx, digest)
+	return digest
+}
+
+// transformFileCached migrates the file of ctx like transformFile, but takes
+// the result, its diagnostics and migration blocks from cfg.cache when the
+// file and everything it depends on are unchanged since a previous run.
+// ctx.CFGFile is not set for a cached file.
+func transformFileCached(p *pipeline.Pipeline, cfg config, ctx *transform.Context) ([]byte, error) {
+	if cfg.cache == nil {
+		return transformFile(p, cfg, ctx)
+	}
+
+	skip := make([]string, 0, len(ctx.SkipTypes))
+	for resourceType := range ctx.SkipTypes {
+		skip = append(skip, resourceType)
+	}
+	sort.Strings(skip)
+	key := cfg.cache.Key(ctx.FilePath, ctx.Content, evalContextDigest(ctx.EvalContext), strings.Join(skip, ","))
+	if entry, ok := cfg.cache.Get(key); ok {
+		blocks, err := parseMigrationBlocks(entry.MigrationBlocks)
+		if err == nil {
+			ctx.Diagnostics = append(ctx.Diagnostics, entry.HCL()...)
+			ctx.MigrationBlocks = blocks
+			return []byte(entry.Content), nil
+		}
+	}
+
+	transformed, err := transformFile(p, cfg, ctx)
+	if err != nil {
+		return nil, err
+	}
+	entry := &cache.Entry{
+		Content:     string(transformed),
+		Diagnostics: cache.NewDiagnostics(ctx.Diagnostics),
+	}
+	for _, block := range ctx.MigrationBlocks {
+		entry.MigrationBlocks += string(hclwrite.Format(block.BuildTokens(nil).Bytes())) + "\n"
+	}
+	if err := cfg.cache.Put(key, entry); err != nil {
+		// A cache that cannot be written only costs the next run time.
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	return transformed, nil
+}
+
+func parseMigrationBlocks(src string) ([]*hclwrite.Block, error) {
+	if src == "" {
+		return nil, nil
+	}
+	file, diags := hclwrite.ParseConfig([]byte(src), "migrations.tf", hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	return file.Body().Blocks(), nil
+}
+
+// printCacheStats prints how many files were taken from the cache.
+func printCacheStats(cfg config) {
+	if cfg.cache == nil || !cfg.verbose {
+		return
+	}
+	hits, misses := cfg.cache.Stats()
+	fmt.Printf("Cache: %d of %d file(s) unchanged since a previous run\n", hits, hits+misses)
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudflare/tf-migrate/internal/pipeline"
+)
+
+func TestProcessConfigFiles_Cache(t *testing.T) {
+	configDir := t.TempDir()
+	cacheDir := t.TempDir()
+	write := func(name, content string) {
+		require.NoError(t, os.WriteFile(filepath.Join(configDir, name), []byte(content), 0644))
+	}
+	write("argo.tf", `resource "cloudflare_argo" "a" {
+  zone_id        = "0da42c8d2132a9ddaf714f9e7c920711"
+  smart_routing  = "on"
+  tiered_caching = "on"
+}
+`)
+	write("record.tf", `resource "cloudflare_record" "r" {
+  zone_id = "0da42c8d2132a9ddaf714f9e7c920711"
+  name    = "www"
+  type    = "A"
+  value   = "192.0.2.1"
+}
+`)
+	write("outputs.tf", `output "host" {
+  value = cloudflare_record.r.hostname
+}
+
+output "zone" {
+  value = cloudflare_zone.z.zone
+}
+`)
+
+	run := func(resources ...string) (map[string]string, []string, int) {
+		t.Helper()
+		outputDir := t.TempDir()
+		cfg := config{
+			configDir:          configDir,
+			outputDir:          outputDir,
+			sourceVersion:      "v4",
+			targetVersion:      "v5",
+			resourcesToMigrate: resources,
+			cacheDir:           cacheDir,
+		}
+		require.NoError(t, openCache(&cfg))
+		log := newTestLogger()
+		p := pipeline.BuildConfigPipeline(log, getProviders(resources...))
+		_, diags, err := processConfigFiles(log, p, cfg)
+		require.NoError(t, err)
+
+		files := make(map[string]string)
+		for _, name := range []string{"argo.tf", "record.tf", "outputs.tf"} {
+			content, err := os.ReadFile(filepath.Join(outputDir, name))
+			require.NoError(t, err)
+			files[name] = string(content)
+		}
+		var summaries []string
+		for _, d := range diags {
+			summaries = append(summaries, d.Summary)
+		}
+		hits, _ := cfg.cache.Stats()
+		return files, summaries, hits
+	}
+
+	files, diags, hits := run()
+	assert.Equal(t, 0, hits)
+	assert.Contains(t, files["outputs.tf"], "cloudflare_dns_record.r.name")
+	require.NotEmpty(t, diags)
+
+	t.Run("unchanged files are taken from the cache", func(t *testing.T) {
+		cachedFiles, cachedDiags, hits := run()
+		assert.Equal(t, 3, hits)
+		assert.Equal(t, files, cachedFiles)
+		assert.Equal(t, diags, cachedDiags)
+	})
+
+	t.Run("a changed file is migrated again", func(t *testing.T) {
+		write("record.tf", `resource "cloudflare_record" "r" {
+  zone_id = "0da42c8d2132a9ddaf714f9e7c920711"
+  name    = "api"
+  type    = "A"
+  value   = "192.0.2.1"
+}
+`)
+		changedFiles, _, hits := run()
+		assert.Equal(t, 2, hits)
+		assert.Contains(t, changedFiles["record.tf"], `"api"`)
+		assert.Equal(t, files["outputs.tf"], changedFiles["outputs.tf"])
+	})
+
+	t.Run("cross-file renames follow the selected resources", func(t *testing.T) {
+		// Only the rename rules of the selected resources apply, so a cached
+		// outputs.tf from a run with different resources must not be reused.
+		filtered, _, hits := run("cloudflare_record")
+		assert.Equal(t, 0, hits)
+		assert.Contains(t, filtered["outputs.tf"], "cloudflare_dns_record.r.name")
+		assert.Contains(t, filtered["outputs.tf"], "cloudflare_zone.z.zone")
+
+		all, _, _ := run()
+		assert.Contains(t, all["outputs.tf"], "cloudflare_dns_record.r.name")
+		assert.Contains(t, all["outputs.tf"], "cloudflare_zone.z.name")
+	})
+}
+
+func TestOpenCache_DisabledWithPlugins(t *testing.T) {
+	cfg := config{cacheDir: t.TempDir(), pluginPaths: []string{"/usr/local/bin/migrator"}}
+	require.NoError(t, openCache(&cfg))
+	assert.Nil(t, cfg.cache)
+}
@@ -0,0 +1,309 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/spf13/cobra"
+
+	"github.com/cloudflare/tf-migrate/internal/parallel"
+	"github.com/cloudflare/tf-migrate/internal/pipeline"
+	"github.com/cloudflare/tf-migrate/internal/preflight"
+	tfhcl "github.com/cloudflare/tf-migrate/internal/transform/hcl"
+)
+
+// errFilesNeedMigration is returned by `tf-migrate check` when at least one
+// file would change. The report has already been printed, so main exits with
+// code 1 without printing the error.
+var errFilesNeedMigration = errors.New("files need migration")
+
+// inMemoryMigration holds the result of running the full migration without
+// writing anything to disk.
+type inMemoryMigration struct {
+	Files       []string          // input files, in discovery order
+	Originals   map[string]string // file path -> original content
+	Migrated    map[string]string // file path -> migrated and postprocessed content
+	Diagnostics hcl.Diagnostics
+}
+
+// checkedFile is a file that would be changed by a migration run.
+type checkedFile struct {
+	Path string
+	// Resources lists the resource blocks in the file that would be rewritten,
+	// classified the same way as the pre-migration scan.
+	Resources []preflight.Resource
+}
+
+// checkResult is the outcome of `tf-migrate check`.
+type checkResult struct {
+	Migration *inMemoryMigration
+	Changed   []checkedFile
+}
+
+func newCheckCommand(log hclog.Logger, cfg *config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "check",
+		Short: "Check whether any configuration files still need migration",
+		Long: `Runs the full migration pipeline, including cross-file reference updates,
+in memory and reports every file that would change. No files are written.
+
+Exit code 0: all files are already migrated.
+Exit code 1: at least one file still needs migration.`,
+		Example: `  # Fail CI if any v4 syntax remains
+  tf-migrate check
+
+  # Check a module tree and show what would change
+  tf-migrate --config-dir ./terraform check --recursive --diff`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			applyConfigDefaults(cfg)
+			cmd.SilenceUsage = true
+
+			if err := validateVersions(*cfg); err != nil {
+				return err
+			}
+			if err := loadRuleFiles(cfg); err != nil {
+				return err
+			}
+			stopPlugins, err := startPlugins(cfg)
+			if err != nil {
+				return err
+			}
+			defer stopPlugins()
+			if err := loadStateSnapshot(cfg); err != nil {
+				return err
+			}
+			evalCtxs, err := newEvalContexts(log, *cfg)
+			if err != nil {
+				return err
+			}
+			cfg.evalContexts = evalCtxs
+			if err := loadProviderSchema(cfg); err != nil {
+				return err
+			}
+			if err := openCache(cfg); err != nil {
+				return err
+			}
+
+			result, err := runCheck(log, *cfg)
+			if err != nil {
+				return err
+			}
+
+			if cfg.diff {
+				m := result.Migration
+				names := make(map[string]string, len(m.Files))
+				for _, file := range m.Files {
+					names[file] = diffDisplayName(cfg.configDir, file)
+				}
+				if err := writeMigrationDiff(*cfg, m.Files, names, m.Originals, m.Migrated); err != nil {
+					return err
+				}
+			}
+
+			printCheckReport(result, *cfg)
+			if len(result.Changed) > 0 {
+				return errFilesNeedMigration
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&cfg.recursive, "recursive", false, "Recursively process subdirectories (useful for module structures)")
+	cmd.Flags().StringSliceVar(&cfg.exclude, "exclude", []string{}, "Directories to exclude from the check (relative to config-dir, can be specified multiple times)")
+	cmd.Flags().BoolVar(&cfg.moduleGraph, "module-graph", false, "Follow local module sources (./ and ../) from each root module and check every module once, even outside --config-dir")
+	cmd.Flags().StringVar(&cfg.stateFile, "state-file", "", "Read v4 resource attributes from this state snapshot (terraform show -json or terraform state pull output)")
+	cmd.Flags().StringSliceVar(&cfg.varFiles, "var-file", []string{}, "Variable definitions file to resolve var.* references with, in addition to terraform.tfvars and *.auto.tfvars (can be specified multiple times)")
+	cmd.Flags().StringVar(&cfg.schemaFile, "schema-file", "", "Validate migrated files against this provider schema (terraform providers schema -json output)")
+	cmd.Flags().BoolVar(&cfg.diff, "diff", false, "Print a unified diff of the changes a migration would make")
+	cmd.Flags().BoolVarP(&cfg.verbose, "verbose", "v", false, "Show migration diagnostics for the checked files")
+
+	return cmd
+}
+
+// migrateInMemory runs the config pipeline and global postprocessing over every
+// file in cfg.configDir without writing anything.
+func migrateInMemory(log hclog.Logger, cfg config) (*inMemoryMigration, error) {
+	files, err := findConfigFiles(log, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list .tf files: %w", err)
+	}
+
+	m := &inMemoryMigration{
+		Files:     files,
+		Originals: make(map[string]string, len(files)),
+		Migrated:  make(map[string]string, len(files)),
+	}
+
+	contents := make([][]byte, len(files))
+	readErrs := make([]error, len(files))
+	parallel.ForEach(len(files), cfg.parallelism, func(i int) {
+		contents[i], readErrs[i] = os.ReadFile(files[i])
+	})
+	sources := make([]tfhcl.SourceFile, len(files))
+	for i, file := range files {
+		if readErrs[i] != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file, readErrs[i])
+		}
+		sources[i] = tfhcl.SourceFile{Name: file, Content: contents[i]}
+	}
+
+	providers := getProviders(cfg.resourcesToMigrate...)
+	scanned := pipeline.ScanConfigs(log, providers, cfg.sourceVersion, cfg.targetVersion, sources, cfg.parallelism)
+	cfg.cache = cfg.cache.With(scanned)
+
+	p := pipeline.BuildConfigPipeline(log, providers)
+	transformed := make([][]byte, len(files))
+	diags := make([]hcl.Diagnostics, len(files))
+	errs := make([]error, len(files))
+	parallel.ForEach(len(files), cfg.parallelism, func(i int) {
+		ctx := newTransformContext(cfg, files[i], contents[i])
+		transformed[i], errs[i] = transformFileCached(p, cfg, ctx)
+		diags[i] = ctx.Diagnostics
+	})
+
+	for i, file := range files {
+		m.Diagnostics = append(m.Diagnostics, diags[i]...)
+		if errs[i] != nil {
+			return nil, fmt.Errorf("failed to transform %s: %w", file, errs[i])
+		}
+
+		m.Originals[file] = string(contents[i])
+		m.Migrated[file] = string(transformed[i])
+	}
+
+	if len(files) > 0 {
+		m.Diagnostics = append(m.Diagnostics, postprocessContents(log, cfg, files, m.Migrated)...)
+		if cfg.providerSchema != nil {
+			m.Diagnostics = append(m.Diagnostics, validateContents(log, cfg, files, m.Migrated)...)
+		}
+	}
+
+	return m, nil
+}
+
+// runCheck migrates the configuration in memory and returns every file whose
+// content would change, together with the resources responsible.
+func runCheck(log hclog.Logger, cfg config) (*checkResult, error) {
+	m, err := migrateInMemory(log, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := runPreMigrationScan(log, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// Index scanned resources by file and address, matching the File field
+	// recorded by runPreMigrationScan.
+	scanned := make(map[string]preflight.Resource, len(report.Resources))
+	for _, r := range report.Resources {
+		scanned[r.File+":"+r.ResourceType+"."+r.ResourceName] = r
+	}
+
+	result := &checkResult{Migration: m}
+	for _, file := range m.Files {
+		if m.Originals[file] == m.Migrated[file] {
+			continue
+		}
+
+		cf := checkedFile{Path: file}
+		for _, addr := range changedResourceAddresses(filepath.Base(file), m.Originals[file], m.Migrated[file]) {
+			if r, ok := scanned[scanPath(cfg, file)+":"+addr]; ok {
+				cf.Resources = append(cf.Resources, r)
+			}
+		}
+		result.Changed = append(result.Changed, cf)
+	}
+
+	return result, nil
+}
+
+// changedResourceAddresses returns the addresses of resource blocks in original
+// that do not appear unchanged in migrated. Blocks are compared after
+// formatting, so whitespace-only differences are ignored. filename selects the
+// syntax both versions are parsed with.
+func changedResourceAddresses(filename, original, migrated string) []string {
+	before, diags := tfhcl.ParseConfigFile([]byte(original), filename)
+	if diags.HasErrors() {
+		return nil
+	}
+	after, diags := tfhcl.ParseConfigFile([]byte(migrated), filename)
+	if diags.HasErrors() {
+		return nil
+	}
+
+	unchanged := make(map[string]struct{})
+	for _, block := range after.Body().Blocks() {
+		if block.Type() != "resource" || len(block.Labels()) < 2 {
+			continue
+		}
+		unchanged[formattedBlockText(block)] = struct{}{}
+	}
+
+	var addrs []string
+	for _, block := range before.Body().Blocks() {
+		if block.Type() != "resource" || len(block.Labels()) < 2 {
+			continue
+		}
+		if _, ok := unchanged[formattedBlockText(block)]; ok {
+			continue
+		}
+		addrs = append(addrs, block.Labels()[0]+"."+block.Labels()[1])
+	}
+	return addrs
+}
+
+// formattedBlockText returns the canonical formatted text of a block.
+func formattedBlockText(block *hclwrite.Block) string {
+	return strings.TrimSpace(string(hclwrite.Format(block.BuildTokens(nil).Bytes())))
+}
+
+// printCheckReport prints the files and resources that still need migration.
+func printCheckReport(result *checkResult, cfg config) {
+	if cfg.verbose {
+		printDiagnostics(result.Migration.Diagnostics, cfg)
+	}
+
+	fmt.Println()
+	if len(result.Changed) == 0 {
+		fmt.Printf("✓ No files need migration (%d file(s) checked)\n", len(result.Migration.Files))
+		return
+	}
+
+	fmt.Printf("✗ %d of %d file(s) still need migration:\n", len(result.Changed), len(result.Migration.Files))
+	for _, cf := range result.Changed {
+		fmt.Println()
+		fmt.Printf("  %s\n", diffDisplayName(cfg.configDir, cf.Path))
+
+		resources := append([]preflight.Resource(nil), cf.Resources...)
+		sort.SliceStable(resources, func(i, j int) bool {
+			return resources[i].ResourceType+"."+resources[i].ResourceName <
+				resources[j].ResourceType+"."+resources[j].ResourceName
+		})
+
+		if len(resources) == 0 {
+			fmt.Println("    cross-file references or data sources need updating")
+			continue
+		}
+		for _, r := range resources {
+			switch r.Class {
+			case preflight.ClassRenamed:
+				fmt.Printf("    %s.%s → %s.%s\n", r.OldType, r.ResourceName, r.NewType, r.ResourceName)
+			case preflight.ClassManualIntervention:
+				fmt.Printf("    %s.%s (manual intervention: %s)\n", r.ResourceType, r.ResourceName, r.Detail)
+			default:
+				fmt.Printf("    %s.%s (config changes)\n", r.ResourceType, r.ResourceName)
+			}
+		}
+	}
+	fmt.Println()
+	fmt.Println("Run `tf-migrate migrate` to migrate these files.")
+}
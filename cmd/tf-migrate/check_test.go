@@ -0,0 +1,174 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudflare/tf-migrate/internal/pipeline"
+	"github.com/cloudflare/tf-migrate/internal/preflight"
+)
+
+func TestRunCheck(t *testing.T) {
+	t.Run("reports files and resources that need migration", func(t *testing.T) {
+		tmpDir := t.TempDir()
+
+		records := `resource "cloudflare_record" "www" {
+  zone_id = "abc123"
+  name    = "www"
+  type    = "A"
+  value   = "192.0.2.1"
+}
+`
+		outputs := `output "www_id" {
+  value = cloudflare_record.www.id
+}
+`
+		unrelated := `resource "aws_s3_bucket" "logs" {
+  bucket = "my-logs"
+}
+`
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "records.tf"), []byte(records), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "outputs.tf"), []byte(outputs), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "aws.tf"), []byte(unrelated), 0644))
+
+		cfg := config{configDir: tmpDir, sourceVersion: "v4", targetVersion: "v5"}
+		result, err := runCheck(newTestLogger(), cfg)
+		require.NoError(t, err)
+
+		require.Len(t, result.Changed, 2)
+		changed := make(map[string]checkedFile)
+		for _, cf := range result.Changed {
+			changed[filepath.Base(cf.Path)] = cf
+		}
+
+		require.Contains(t, changed, "records.tf")
+		require.Len(t, changed["records.tf"].Resources, 1)
+		r := changed["records.tf"].Resources[0]
+		assert.Equal(t, preflight.ClassRenamed, r.Class)
+		assert.Equal(t, "cloudflare_record", r.OldType)
+		assert.Equal(t, "cloudflare_dns_record", r.NewType)
+
+		// outputs.tf only changes through global postprocessing
+		require.Contains(t, changed, "outputs.tf")
+		assert.Empty(t, changed["outputs.tf"].Resources)
+
+		// Nothing is written in check mode
+		got, err := os.ReadFile(filepath.Join(tmpDir, "records.tf"))
+		require.NoError(t, err)
+		assert.Equal(t, records, string(got))
+	})
+
+	t.Run("resources are matched by path in recursive mode", func(t *testing.T) {
+		tmpDir := t.TempDir()
+
+		// Both modules have a main.tf with the same resource address.
+		record := `resource "cloudflare_record" "www" {
+  zone_id = "abc123"
+  name    = "www"
+  type    = "A"
+  value   = "192.0.2.1"
+}
+`
+		for _, dir := range []string{"dns", "edge"} {
+			require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, dir), 0755))
+			require.NoError(t, os.WriteFile(filepath.Join(tmpDir, dir, "main.tf"), []byte(record), 0644))
+		}
+
+		cfg := config{configDir: tmpDir, sourceVersion: "v4", targetVersion: "v5", recursive: true}
+		result, err := runCheck(newTestLogger(), cfg)
+		require.NoError(t, err)
+
+		require.Len(t, result.Changed, 2)
+		for _, cf := range result.Changed {
+			rel, err := filepath.Rel(tmpDir, cf.Path)
+			require.NoError(t, err)
+			require.Len(t, cf.Resources, 1)
+			assert.Equal(t, rel, cf.Resources[0].File)
+		}
+	})
+
+	t.Run("already migrated configuration passes", func(t *testing.T) {
+		tmpDir := t.TempDir()
+
+		content := `resource "cloudflare_dns_record" "www" {
+  zone_id = "abc123"
+  name    = "www"
+  type    = "A"
+  content = "192.0.2.1"
+  ttl     = 1
+}
+
+output "www_id" {
+  value = cloudflare_dns_record.www.id
+}
+`
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "main.tf"), []byte(content), 0644))
+
+		cfg := config{configDir: tmpDir, sourceVersion: "v4", targetVersion: "v5"}
+		result, err := runCheck(newTestLogger(), cfg)
+		require.NoError(t, err)
+		assert.Empty(t, result.Changed)
+		assert.Len(t, result.Migration.Files, 1)
+	})
+}
+
+func TestChangedResourceAddresses(t *testing.T) {
+	original := `resource "cloudflare_zone" "a" {
+  zone = "example.com"
+}
+
+resource "cloudflare_zone" "b" {
+  zone    =    "example.org"
+}
+`
+	migrated := `resource "cloudflare_zone" "a" {
+  name = "example.com"
+}
+
+resource "cloudflare_zone" "b" {
+  zone = "example.org"
+}
+`
+	// Only block "a" changed; "b" differs in whitespace only.
+	assert.Equal(t, []string{"cloudflare_zone.a"}, changedResourceAddresses("main.tf", original, migrated))
+}
+
+func TestProcessConfigFiles_RerunKeepsOriginalBackup(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	records := `resource "cloudflare_record" "www" {
+  zone_id = "abc123"
+  name    = "www"
+  type    = "A"
+  value   = "192.0.2.1"
+}
+`
+	path := filepath.Join(tmpDir, "records.tf")
+	require.NoError(t, os.WriteFile(path, []byte(records), 0644))
+
+	cfg := config{configDir: tmpDir, sourceVersion: "v4", targetVersion: "v5", backup: true}
+	log := newTestLogger()
+	p := pipeline.BuildConfigPipeline(log, getProviders())
+
+	_, _, err := processConfigFiles(log, p, cfg)
+	require.NoError(t, err)
+	migrated, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	// A second run over already-migrated files changes nothing and leaves the
+	// backup of the original v4 file intact.
+	_, _, err = processConfigFiles(log, p, cfg)
+	require.NoError(t, err)
+
+	got, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, string(migrated), string(got))
+
+	backup, err := os.ReadFile(path + ".backup")
+	require.NoError(t, err)
+	assert.Equal(t, records, string(backup))
+}
@@ -0,0 +1,311 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/spf13/cobra"
+
+	tfhcl "github.com/cloudflare/tf-migrate/internal/transform/hcl"
+)
+
+// cleanupResult is the outcome of `tf-migrate cleanup`.
+type cleanupResult struct {
+	Removed        []string            // generated migrations files that were (or would be) deleted
+	BackupsRemoved []string            // .backup files that were (or would be) deleted
+	BlocksRemoved  map[string][]string // migrated file -> generated moved/import/removed blocks removed from it
+	Pending        []string            // files left as they are until terraform apply is confirmed
+	Skipped        map[string]string   // files left in place -> reason
+}
+
+func newCleanupCommand(log hclog.Logger, cfg *config) *cobra.Command {
+	var yes bool
+	cmd := &cobra.Command{
+		Use:   "cleanup",
+		Short: "Remove backups and generated blocks once the migration has been applied",
+		Long: `Deletes the .backup files written by ` + "`tf-migrate migrate`" + `, the files written by
+` + "`tf-migrate migrate --migrations-file`" + `, and the moved, import and removed blocks
+that the migration added to the migrated files.
+
+Terraform needs those blocks to move state to the new resource addresses, so
+they are only removed once you confirm that ` + "`terraform apply`" + ` has completed in
+every workspace that uses the configuration. Until then, only the backups of
+files without generated blocks are deleted.
+
+Generated blocks are told apart from hand-written ones by comparing a file with
+its backup. Only files that start with the tf-migrate generated-file header are
+deleted, and a file is kept if anything other than moved, import or removed
+blocks has been added to it. A migrated file edited since the migration is
+left as it is; pass --force to remove its generated blocks anyway.`,
+		Example: `  # After terraform apply has run everywhere
+  tf-migrate cleanup
+
+  # In CI, without the confirmation prompt
+  tf-migrate cleanup --recursive --yes
+
+  # Preview what would be removed in a module tree
+  tf-migrate --config-dir ./terraform --dry-run cleanup --recursive`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			applyConfigDefaults(cfg)
+			cmd.SilenceUsage = true
+
+			stateApplied := yes || cfg.dryRun
+			if !stateApplied {
+				stateApplied = confirmStateApplied()
+			}
+
+			result, err := runCleanup(log, *cfg, stateApplied)
+			if err != nil {
+				return err
+			}
+			printCleanupReport(result, *cfg)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&cfg.recursive, "recursive", false, "Recursively process subdirectories (useful for module structures)")
+	cmd.Flags().StringSliceVar(&cfg.exclude, "exclude", []string{}, "Directories to exclude from cleanup (relative to config-dir, can be specified multiple times)")
+	cmd.Flags().BoolVar(&cfg.force, "force", false, "Remove generated blocks even from files edited since they were migrated")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Confirm that terraform apply has completed everywhere without prompting (for CI/non-interactive use)")
+
+	return cmd
+}
+
+// confirmStateApplied asks the user whether the migration has been applied.
+func confirmStateApplied() bool {
+	fmt.Print("Has terraform apply completed in every workspace that uses this configuration? [y/N]: ")
+
+	var answer string
+	if _, err := fmt.Scanln(&answer); err != nil {
+		return false
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// runCleanup finds the backups and generated migrations files under
+// cfg.configDir and deletes them, unless cfg.dryRun is set. Generated
+// migrations files and blocks are only removed when stateApplied is set.
+func runCleanup(log hclog.Logger, cfg config, stateApplied bool) (*cleanupResult, error) {
+	files, err := findTerraformFilesWithRecursion(cfg.configDir, cfg.recursive, cfg.exclude)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list .tf files: %w", err)
+	}
+
+	result := &cleanupResult{
+		BlocksRemoved: make(map[string][]string),
+		Skipped:       make(map[string]string),
+	}
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		if !isGeneratedMigrationsFile(content) {
+			continue
+		}
+
+		others, err := nonMigrationBlocks(file, content)
+		if err != nil {
+			result.Skipped[file] = err.Error()
+			continue
+		}
+		if len(others) > 0 {
+			result.Skipped[file] = "contains " + strings.Join(others, ", ")
+			continue
+		}
+		if !stateApplied {
+			result.Pending = append(result.Pending, file)
+			continue
+		}
+
+		if !cfg.dryRun {
+			if err := os.Remove(file); err != nil {
+				return nil, fmt.Errorf("failed to remove %s: %w", file, err)
+			}
+			log.Debug("Removed generated migrations file", "file", file)
+		}
+		result.Removed = append(result.Removed, file)
+	}
+
+	if err := cleanupBackups(log, cfg, stateApplied, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// cleanupBackups removes the generated blocks from every backed-up file and
+// deletes its backup. A backup is kept while its file still has generated
+// blocks, as it is what tells them apart from hand-written ones.
+func cleanupBackups(log hclog.Logger, cfg config, stateApplied bool, result *cleanupResult) error {
+	backups, err := findBackupFiles(cfg.configDir, cfg.recursive, cfg.exclude)
+	if err != nil {
+		return fmt.Errorf("failed to list backup files: %w", err)
+	}
+
+	manifests := make(map[string]*backupManifest)
+	var dirs []string
+	for _, backup := range backups {
+		dir := filepath.Dir(backup)
+		m, ok := manifests[dir]
+		if !ok {
+			if m, err = readBackupManifest(dir); err != nil {
+				return err
+			}
+			manifests[dir] = m
+			dirs = append(dirs, dir)
+		}
+
+		file := strings.TrimSuffix(backup, backupSuffix)
+		original, err := os.ReadFile(backup)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", backup, err)
+		}
+		current, err := os.ReadFile(file)
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to read %s: %w", file, err)
+		}
+
+		if err == nil {
+			cleaned, removed, err := removeGeneratedBlocks(file, current, original)
+			if err != nil {
+				result.Skipped[file] = err.Error()
+				continue
+			}
+			if len(removed) > 0 {
+				if !stateApplied {
+					result.Pending = append(result.Pending, file)
+					continue
+				}
+				if reason := m.editedSinceMigration(file, current); reason != "" && !cfg.force {
+					result.Skipped[file] = reason + "; use --force to remove its generated blocks"
+					continue
+				}
+				if !cfg.dryRun {
+					if err := os.WriteFile(file, cleaned, 0644); err != nil {
+						return fmt.Errorf("failed to write %s: %w", file, err)
+					}
+					log.Debug("Removed generated blocks", "file", file, "count", len(removed))
+				}
+				result.BlocksRemoved[file] = removed
+			}
+		}
+
+		if !cfg.dryRun {
+			if err := os.Remove(backup); err != nil {
+				return fmt.Errorf("failed to remove %s: %w", backup, err)
+			}
+			log.Debug("Removed backup", "file", backup)
+		}
+		delete(m.Files, filepath.Base(file))
+		result.BackupsRemoved = append(result.BackupsRemoved, backup)
+	}
+
+	if cfg.dryRun {
+		return nil
+	}
+	for _, dir := range dirs {
+		if err := manifests[dir].write(dir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeGeneratedBlocks removes from content, the content of the migrated
+// file, the moved, import and removed blocks that are not in original, its
+// content before the migration. It returns the new content and the removed
+// blocks. A .tf.json file is edited in native syntax and written back as JSON.
+func removeGeneratedBlocks(file string, content, original []byte) ([]byte, []string, error) {
+	existing := make(map[string]bool)
+	if origFile, diags := tfhcl.ParseConfigFile(original, file); !diags.HasErrors() {
+		for _, block := range origFile.Body().Blocks() {
+			if key := tfhcl.MigrationBlockKey(block); key != "" {
+				existing[key] = true
+			}
+		}
+	}
+
+	f, diags := tfhcl.ParseConfigFile(content, file)
+	if diags.HasErrors() {
+		return nil, nil, fmt.Errorf("failed to parse %s: %s", file, diags.Error())
+	}
+
+	var removed []string
+	body := f.Body()
+	for _, block := range body.Blocks() {
+		key := tfhcl.MigrationBlockKey(block)
+		if key == "" || existing[key] {
+			continue
+		}
+		body.RemoveBlock(block)
+		removed = append(removed, strings.ReplaceAll(key, "|", " "))
+	}
+	if len(removed) == 0 {
+		return content, nil, nil
+	}
+
+	cleaned := blankLineRuns.ReplaceAll(f.Bytes(), []byte("\n\n"))
+	cleaned = append(bytes.TrimRight(cleaned, "\n"), '\n')
+	cleaned = hclwrite.Format(cleaned)
+	if tfhcl.IsJSONConfigFile(file) {
+		converted, err := tfhcl.NativeToJSON(cleaned, file)
+		if err != nil {
+			return nil, nil, err
+		}
+		return converted, removed, nil
+	}
+	return cleaned, removed, nil
+}
+
+// blankLineRuns matches the blank lines left behind by removed blocks.
+var blankLineRuns = regexp.MustCompile(`\n{3,}`)
+
+// printCleanupReport prints the files removed and kept by cleanup.
+func printCleanupReport(result *cleanupResult, cfg config) {
+	verb := "Removed"
+	if cfg.dryRun {
+		verb = "Would remove"
+	}
+
+	if len(result.Removed) == 0 && len(result.BackupsRemoved) == 0 && len(result.Pending) == 0 && len(result.Skipped) == 0 {
+		fmt.Println("No backups or files generated by --migrations-file found.")
+		return
+	}
+
+	for _, file := range result.Removed {
+		fmt.Printf("✓ %s %s\n", verb, diffDisplayName(cfg.configDir, file))
+	}
+	blockFiles := make([]string, 0, len(result.BlocksRemoved))
+	for file := range result.BlocksRemoved {
+		blockFiles = append(blockFiles, file)
+	}
+	sort.Strings(blockFiles)
+	for _, file := range blockFiles {
+		fmt.Printf("✓ %s %d generated block(s) from %s\n", verb, len(result.BlocksRemoved[file]), diffDisplayName(cfg.configDir, file))
+	}
+	if len(result.BackupsRemoved) > 0 {
+		fmt.Printf("✓ %s %d backup file(s)\n", verb, len(result.BackupsRemoved))
+	}
+	skipped := make([]string, 0, len(result.Skipped))
+	for file := range result.Skipped {
+		skipped = append(skipped, file)
+	}
+	sort.Strings(skipped)
+	for _, file := range skipped {
+		fmt.Printf("⚠ Kept %s: %s\n", diffDisplayName(cfg.configDir, file), result.Skipped[file])
+	}
+	if len(result.Pending) > 0 {
+		fmt.Printf("\n%d file(s) still hold blocks Terraform needs to move state. Run cleanup again once terraform apply has completed everywhere:\n", len(result.Pending))
+		for _, file := range result.Pending {
+			fmt.Printf("  %s\n", diffDisplayName(cfg.configDir, file))
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudflare/tf-migrate/internal/pipeline"
+)
+
+func TestRunCleanup(t *testing.T) {
+	moves := migrationsFileHeader(time.Now()) + `moved {
+  from = cloudflare_record.www
+  to   = cloudflare_dns_record.www
+}
+`
+	edited := moves + `
+resource "cloudflare_dns_record" "extra" {
+  zone_id = "abc123"
+}
+`
+	handWritten := `moved {
+  from = cloudflare_record.api
+  to   = cloudflare_dns_record.api
+}
+`
+
+	setup := func(t *testing.T) string {
+		tmpDir := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "modules", "dns"), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, defaultMigrationsFile), []byte(moves), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "modules", "dns", defaultMigrationsFile), []byte(edited), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "moves.tf"), []byte(handWritten), 0644))
+		return tmpDir
+	}
+
+	t.Run("removes generated files and keeps edited ones", func(t *testing.T) {
+		tmpDir := setup(t)
+		cfg := config{configDir: tmpDir, recursive: true}
+
+		result, err := runCleanup(newTestLogger(), cfg, true)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{filepath.Join(tmpDir, defaultMigrationsFile)}, result.Removed)
+		_, err = os.Stat(filepath.Join(tmpDir, defaultMigrationsFile))
+		assert.True(t, os.IsNotExist(err))
+
+		edited := filepath.Join(tmpDir, "modules", "dns", defaultMigrationsFile)
+		require.Contains(t, result.Skipped, edited)
+		assert.Contains(t, result.Skipped[edited], "resource.cloudflare_dns_record.extra")
+		assert.FileExists(t, edited)
+
+		// Files without the generated header are never touched
+		assert.FileExists(t, filepath.Join(tmpDir, "moves.tf"))
+	})
+
+	t.Run("dry run removes nothing", func(t *testing.T) {
+		tmpDir := setup(t)
+		cfg := config{configDir: tmpDir, dryRun: true}
+
+		result, err := runCleanup(newTestLogger(), cfg, true)
+		require.NoError(t, err)
+		assert.Len(t, result.Removed, 1)
+		assert.FileExists(t, filepath.Join(tmpDir, defaultMigrationsFile))
+	})
+}
+
+// migrateInPlace migrates dir in place with backups, as `tf-migrate migrate`
+// does by default.
+func migrateInPlace(t *testing.T, dir string) {
+	t.Helper()
+	cfg := config{configDir: dir, sourceVersion: "v4", targetVersion: "v5", backup: true}
+	log := newTestLogger()
+	_, _, err := processConfigFiles(log, pipeline.BuildConfigPipeline(log, getProviders()), cfg)
+	require.NoError(t, err)
+}
+
+func TestRunCleanup_Backups(t *testing.T) {
+	record := `resource "cloudflare_record" "www" {
+  zone_id = "0da42c8d2132a9ddaf714f9e7c920711"
+  name    = "www"
+  type    = "A"
+  value   = "192.0.2.1"
+}
+
+moved {
+  from = cloudflare_record.old
+  to   = cloudflare_record.www
+}
+`
+	zone := `resource "cloudflare_zone" "z" {
+  zone       = "example.com"
+  account_id = "f037e56e89293a057740de681ac9abbe"
+}
+`
+	setup := func(t *testing.T) string {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "record.tf"), []byte(record), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "zone.tf"), []byte(zone), 0644))
+		migrateInPlace(t, dir)
+		require.FileExists(t, filepath.Join(dir, "record.tf.backup"))
+		require.FileExists(t, filepath.Join(dir, "zone.tf.backup"))
+		return dir
+	}
+
+	t.Run("keeps generated blocks until apply is confirmed", func(t *testing.T) {
+		dir := setup(t)
+		result, err := runCleanup(newTestLogger(), config{configDir: dir}, false)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{filepath.Join(dir, "record.tf")}, result.Pending)
+		assert.Equal(t, []string{filepath.Join(dir, "zone.tf.backup")}, result.BackupsRemoved)
+		assert.FileExists(t, filepath.Join(dir, "record.tf.backup"))
+		assert.NoFileExists(t, filepath.Join(dir, "zone.tf.backup"))
+	})
+
+	t.Run("removes generated blocks and backups", func(t *testing.T) {
+		dir := setup(t)
+		result, err := runCleanup(newTestLogger(), config{configDir: dir}, true)
+		require.NoError(t, err)
+
+		file := filepath.Join(dir, "record.tf")
+		assert.Equal(t, []string{"moved cloudflare_record.www cloudflare_dns_record.www"}, result.BlocksRemoved[file])
+		assert.Len(t, result.BackupsRemoved, 2)
+		content, err := os.ReadFile(file)
+		require.NoError(t, err)
+		assert.NotContains(t, string(content), "to   = cloudflare_dns_record.www")
+		// The hand-written moved block was in the original file and stays.
+		assert.Contains(t, string(content), "from = cloudflare_record.old")
+		assert.NoFileExists(t, filepath.Join(dir, backupManifestName))
+	})
+
+	t.Run("refuses to rewrite edited files", func(t *testing.T) {
+		dir := setup(t)
+		file := filepath.Join(dir, "record.tf")
+		content, err := os.ReadFile(file)
+		require.NoError(t, err)
+		edited := string(content) + "\n# reviewed\n"
+		require.NoError(t, os.WriteFile(file, []byte(edited), 0644))
+
+		result, err := runCleanup(newTestLogger(), config{configDir: dir}, true)
+		require.NoError(t, err)
+		assert.Contains(t, result.Skipped[file], "edited since it was migrated")
+		assert.FileExists(t, file+backupSuffix)
+		after, err := os.ReadFile(file)
+		require.NoError(t, err)
+		assert.Equal(t, edited, string(after))
+
+		result, err = runCleanup(newTestLogger(), config{configDir: dir, force: true}, true)
+		require.NoError(t, err)
+		assert.Contains(t, result.BlocksRemoved, file)
+		assert.NoFileExists(t, file+backupSuffix)
+	})
+}
+
+func TestRunCleanup_JSONBackups(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "record.tf.json")
+	require.NoError(t, os.WriteFile(file, []byte(`{
+  "resource": {
+    "cloudflare_record": {
+      "www": {
+        "zone_id": "0da42c8d2132a9ddaf714f9e7c920711",
+        "name": "www",
+        "type": "A",
+        "value": "192.0.2.1"
+      }
+    }
+  }
+}
+`), 0644))
+	migrateInPlace(t, dir)
+	require.FileExists(t, file+backupSuffix)
+	migrated, err := os.ReadFile(file)
+	require.NoError(t, err)
+	require.Contains(t, string(migrated), `"moved"`)
+
+	result, err := runCleanup(newTestLogger(), config{configDir: dir}, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{file}, result.Pending)
+	assert.FileExists(t, file+backupSuffix)
+
+	result, err = runCleanup(newTestLogger(), config{configDir: dir}, true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"moved cloudflare_record.www cloudflare_dns_record.www"}, result.BlocksRemoved[file])
+	assert.NoFileExists(t, file+backupSuffix)
+	content, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.NotContains(t, string(content), `"moved"`)
+	assert.Contains(t, string(content), `"cloudflare_dns_record"`)
+	assert.True(t, json.Valid(content), "cleaned file is JSON:\n%s", content)
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// diffContextLines is the number of unchanged lines shown around each change,
+// matching the default used by `diff -u` and `git diff`.
+const diffContextLines = 3
+
+// writeMigrationDiff renders a unified diff for every migrated file whose
+// content changed, then prints it (--diff) and/or writes it to cfg.patchFile
+// (--patch-file). originals and migrated are keyed by output path; names maps
+// each output path to the display name used in the diff headers.
+func writeMigrationDiff(cfg config, outputPaths []string, names, originals, migrated map[string]string) error {
+	var patch strings.Builder
+	changed := 0
+	for _, outputPath := range outputPaths {
+		d := unifiedDiff("a/"+names[outputPath], "b/"+names[outputPath], originals[outputPath], migrated[outputPath])
+		if d == "" {
+			continue
+		}
+		patch.WriteString(d)
+		changed++
+	}
+
+	if cfg.diff {
+		fmt.Println()
+		if changed == 0 {
+			fmt.Println("No changes.")
+		} else {
+			fmt.Print(patch.String())
+		}
+	}
+
+	if cfg.patchFile != "" {
+		if err := os.WriteFile(cfg.patchFile, []byte(patch.String()), 0644); err != nil {
+			return fmt.Errorf("failed to write patch file %s: %w", cfg.patchFile, err)
+		}
+		fmt.Printf("\nWrote diff for %d changed file(s) to %s\n", changed, cfg.patchFile)
+	}
+
+	return nil
+}
+
+// diffDisplayName returns the path of file relative to configDir using forward
+// slashes, for use in diff headers. Falls back to the base name.
+func diffDisplayName(configDir, file string) string {
+	rel, err := filepath.Rel(configDir, file)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return filepath.Base(file)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// diffLine is a single line of a line-level diff.
+type diffLine struct {
+	op   diffmatchpatch.Operation
+	text string // line content without the trailing newline
+	eol  bool   // whether the line was terminated by a newline in its source
+}
+
+// unifiedDiff returns a unified diff between before and after, labelled with
+// fromName and toName in the --- / +++ headers. Returns "" when the contents
+// are identical.
+//
+// The output is compatible with `git apply` and `patch -p1` when the names are
+// given with the conventional a/ and b/ prefixes.
+func unifiedDiff(fromName, toName, before, after string) string {
+	if before == after {
+		return ""
+	}
+
+	lines := diffLines(before, after)
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n", fromName)
+	fmt.Fprintf(&sb, "+++ %s\n", toName)
+
+	// Walk the line list, emitting one hunk per group of changes. Changes that
+	// are separated by at most 2*diffContextLines unchanged lines share a hunk.
+	oldLine, newLine := 1, 1
+	i := 0
+	for i < len(lines) {
+		// Advance to the next change
+		next := i
+		for next < len(lines) && lines[next].op == diffmatchpatch.DiffEqual {
+			next++
+		}
+		if next == len(lines) {
+			break
+		}
+
+		// Leading context
+		start := next - diffContextLines
+		if start < i {
+			start = i
+		}
+		for k := i; k < start; k++ {
+			oldLine++
+			newLine++
+		}
+
+		// Extend the hunk until a run of unchanged lines is long enough to split
+		end := next
+		for end < len(lines) {
+			if lines[end].op != diffmatchpatch.DiffEqual {
+				end++
+				continue
+			}
+			run := end
+			for run < len(lines) && lines[run].op == diffmatchpatch.DiffEqual {
+				run++
+			}
+			if run == len(lines) || run-end > 2*diffContextLines {
+				end += min(diffContextLines, run-end)
+				break
+			}
+			end = run
+		}
+
+		hunkOldStart, hunkNewStart := oldLine, newLine
+		oldCount, newCount := 0, 0
+		var body strings.Builder
+		for k := start; k < end; k++ {
+			l := lines[k]
+			switch l.op {
+			case diffmatchpatch.DiffEqual:
+				body.WriteString(" ")
+				oldCount++
+				newCount++
+			case diffmatchpatch.DiffDelete:
+				body.WriteString("-")
+				oldCount++
+			case diffmatchpatch.DiffInsert:
+				body.WriteString("+")
+				newCount++
+			}
+			body.WriteString(l.text)
+			body.WriteString("\n")
+			if !l.eol {
+				body.WriteString("\\ No newline at end of file\n")
+			}
+		}
+
+		fmt.Fprintf(&sb, "@@ -%s +%s @@\n",
+			hunkRange(hunkOldStart, oldCount), hunkRange(hunkNewStart, newCount))
+		sb.WriteString(body.String())
+
+		oldLine += oldCount
+		newLine += newCount
+		i = end
+	}
+
+	return sb.String()
+}
+
+// hunkRange formats a hunk header range. An empty range points at the line
+// before the change, following the GNU diff convention.
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start-1)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+// diffLines computes a line-level diff between before and after.
+func diffLines(before, after string) []diffLine {
+	dmp := diffmatchpatch.New()
+	a, b, lineArray := dmp.DiffLinesToChars(before, after)
+	diffs := dmp.DiffCharsToLines(dmp.DiffMain(a, b, false), lineArray)
+
+	var lines []diffLine
+	for _, d := range diffs {
+		text := d.Text
+		for text != "" {
+			idx := strings.IndexByte(text, '\n')
+			if idx < 0 {
+				lines = append(lines, diffLine{op: d.Type, text: text})
+				break
+			}
+			lines = append(lines, diffLine{op: d.Type, text: text[:idx], eol: true})
+			text = text[idx+1:]
+		}
+	}
+	return lines
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudflare/tf-migrate/internal/pipeline"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	t.Run("identical content produces no diff", func(t *testing.T) {
+		assert.Equal(t, "", unifiedDiff("a/main.tf", "b/main.tf", "x\n", "x\n"))
+	})
+
+	t.Run("single line change", func(t *testing.T) {
+		before := "a\nb\nc\n"
+		after := "a\nB\nc\n"
+		expected := `--- a/main.tf
++++ b/main.tf
+@@ -1,3 +1,3 @@
+ a
+-b
++B
+ c
+`
+		assert.Equal(t, expected, unifiedDiff("a/main.tf", "b/main.tf", before, after))
+	})
+
+	t.Run("distant changes produce separate hunks", func(t *testing.T) {
+		before := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n"
+		after := "one\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\ntwelve\n"
+		expected := `--- a/main.tf
++++ b/main.tf
+@@ -1,4 +1,4 @@
+-1
++one
+ 2
+ 3
+ 4
+@@ -9,4 +9,4 @@
+ 9
+ 10
+ 11
+-12
++twelve
+`
+		assert.Equal(t, expected, unifiedDiff("a/main.tf", "b/main.tf", before, after))
+	})
+
+	t.Run("nearby changes share a hunk", func(t *testing.T) {
+		before := "1\n2\n3\n4\n5\n6\n7\n8\n"
+		after := "one\n2\n3\n4\n5\n6\n7\neight\n"
+		d := unifiedDiff("a/main.tf", "b/main.tf", before, after)
+		assert.Equal(t, 1, strings.Count(d, "@@ -"))
+		assert.Contains(t, d, "@@ -1,8 +1,8 @@")
+	})
+
+	t.Run("pure insertion", func(t *testing.T) {
+		d := unifiedDiff("a/main.tf", "b/main.tf", "a\n", "a\nb\n")
+		assert.Contains(t, d, "@@ -1 +1,2 @@\n a\n+b\n")
+	})
+
+	t.Run("new content in empty file", func(t *testing.T) {
+		d := unifiedDiff("a/main.tf", "b/main.tf", "", "a\n")
+		assert.Contains(t, d, "@@ -0,0 +1 @@\n+a\n")
+	})
+
+	t.Run("missing trailing newline is marked", func(t *testing.T) {
+		d := unifiedDiff("a/main.tf", "b/main.tf", "a\nb", "a\nb\n")
+		assert.Contains(t, d, "-b\n\\ No newline at end of file\n+b\n")
+	})
+}
+
+func TestDiffDisplayName(t *testing.T) {
+	assert.Equal(t, "main.tf", diffDisplayName("/work", "/work/main.tf"))
+	assert.Equal(t, "modules/dns/main.tf", diffDisplayName("/work", "/work/modules/dns/main.tf"))
+	assert.Equal(t, "main.tf", diffDisplayName("/work", "/elsewhere/main.tf"))
+}
+
+func TestProcessConfigFiles_DryRunPatchFile(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	records := `resource "cloudflare_record" "www" {
+  zone_id = "abc123"
+  name    = "www"
+  type    = "A"
+  value   = "192.0.2.1"
+}
+`
+	outputs := `output "www_id" {
+  value = cloudflare_record.www.id
+}
+`
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "records.tf"), []byte(records), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "outputs.tf"), []byte(outputs), 0644))
+
+	patchPath := filepath.Join(t.TempDir(), "migration.patch")
+	cfg := config{
+		configDir:     tmpDir,
+		sourceVersion: "v4",
+		targetVersion: "v5",
+		dryRun:        true,
+		patchFile:     patchPath,
+	}
+
+	log := newTestLogger()
+	p := pipeline.BuildConfigPipeline(log, getProviders())
+	_, _, err := processConfigFiles(log, p, cfg)
+	require.NoError(t, err)
+
+	// Dry run must not touch the input files
+	got, err := os.ReadFile(filepath.Join(tmpDir, "records.tf"))
+	require.NoError(t, err)
+	assert.Equal(t, records, string(got))
+	_, err = os.Stat(filepath.Join(tmpDir, "records.tf.backup"))
+	assert.True(t, os.IsNotExist(err), "dry run must not create backups")
+
+	patch, err := os.ReadFile(patchPath)
+	require.NoError(t, err)
+	patchStr := string(patch)
+
+	assert.Contains(t, patchStr, "--- a/records.tf\n+++ b/records.tf\n")
+	assert.Contains(t, patchStr, `+resource "cloudflare_dns_record" "www" {`)
+
+	// The cross-file reference update from global postprocessing is included
+	assert.Contains(t, patchStr, "--- a/outputs.tf\n+++ b/outputs.tf\n")
+	assert.Contains(t, patchStr, "-  value = cloudflare_record.www.id\n")
+	assert.Contains(t, patchStr, "+  value = cloudflare_dns_record.www.id\n")
+}
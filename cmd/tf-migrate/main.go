@@ -1,7 +1,9 @@
 package main
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
@@ -16,10 +18,17 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/cloudflare/tf-migrate/internal"
+	"github.com/cloudflare/tf-migrate/internal/cache"
 	"github.com/cloudflare/tf-migrate/internal/logger"
+	"github.com/cloudflare/tf-migrate/internal/parallel"
 	"github.com/cloudflare/tf-migrate/internal/pipeline"
+	"github.com/cloudflare/tf-migrate/internal/plugin"
+	"github.com/cloudflare/tf-migrate/internal/postprocess"
 	"github.com/cloudflare/tf-migrate/internal/registry"
+	"github.com/cloudflare/tf-migrate/internal/rules"
+	"github.com/cloudflare/tf-migrate/internal/schema"
 	"github.com/cloudflare/tf-migrate/internal/transform"
+	tfhcl "github.com/cloudflare/tf-migrate/internal/transform/hcl"
 	"github.com/cloudflare/tf-migrate/internal/verifydrift"
 )
 
@@ -43,8 +52,29 @@ type config struct {
 	recursive             bool
 	exclude               []string // directories to exclude from migration (relative to configDir)
 	logLevel              string
-	skipPhaseCheck        bool // skip phased migration prompt and run full migration directly (for CI/e2e)
-	skipVersionCheck      bool // skip minimum provider version check (for testing/CI only)
+	skipPhaseCheck        bool   // skip phased migration prompt and run full migration directly (for CI/e2e)
+	skipVersionCheck      bool   // skip minimum provider version check (for testing/CI only)
+	diff                  bool   // print a unified diff of the migration (implies dryRun)
+	patchFile             string // write a unified diff of the migration to this file (implies dryRun)
+	migrationsFile        string // collect moved/import/removed blocks into this file per directory instead of inline
+	moduleGraph           bool   // migrate the local modules called by the root modules, following their source paths
+	stateFile             string // terraform show -json or terraform state pull output that migrators can read v4 state from
+	state                 *transform.StateSnapshot
+	varFiles              []string // extra .tfvars files for the root module, as with terraform -var-file
+	evalContexts          *evalContexts
+	schemaFile            string // terraform providers schema -json output to validate migrated files against
+	providerSchema        *schema.ProviderSchema
+	ruleFiles             []string // declarative migration rule files, registered over the built-in migrators
+	pluginPaths           []string // external migrator executables, registered over the built-in migrators
+	parallelism           int      // number of files migrated at once; 0 means one per CPU
+	cacheDir              string   // directory of the incremental migration cache; empty disables it
+	cache                 *cache.Cache
+	force                 bool   // restore and cleanup: overwrite files edited since they were migrated
+	repoConfigFile        string // repository config file; empty means .tf-migrate.yaml in config-dir or a parent
+	repoConfig            *repoConfig
+	driftExemptionFiles   []string // verify-drift: drift exemption files applied with the built-in ones
+	review                bool     // ask whether to accept, reject or edit each transformed block
+	reviewer              *reviewer
 
 	// Diagnostic output options
 	quiet   bool // Suppress warnings, only show errors
@@ -95,15 +125,33 @@ func main() {
 	rootCmd.PersistentFlags().BoolVar(&cfg.dryRun, "dry-run", false, "Perform a dry run without making changes")
 	rootCmd.PersistentFlags().StringVar(&cfg.sourceVersion, "source-version", "", "Source provider version (e.g., v4, v5)")
 	rootCmd.PersistentFlags().StringVar(&cfg.targetVersion, "target-version", "", "Target provider version (e.g., v5, v6)")
+	rootCmd.PersistentFlags().StringSliceVar(&cfg.ruleFiles, "rules-file", []string{}, "YAML migration rule file for resources without a built-in migrator, or to replace one (can be specified multiple times)")
+	rootCmd.PersistentFlags().IntVar(&cfg.parallelism, "parallelism", 0, "Number of files to migrate at once (0 = one per CPU)")
+	rootCmd.PersistentFlags().StringVar(&cfg.cacheDir, "cache-dir", "", "Cache migrated files in this directory, and skip the files whose inputs are unchanged on later runs")
+	rootCmd.PersistentFlags().StringVar(&cfg.repoConfigFile, "config-file", "", "Repository config file (default: "+repoConfigName+" in config-dir or a parent directory)")
+	rootCmd.PersistentFlags().StringSliceVar(&cfg.pluginPaths, "plugin", []string{}, "Migrator plugin executable for resources of other providers, or to replace a built-in migrator (can be specified multiple times)")
 
 	rootCmd.PersistentFlags().StringVarP(&cfg.logLevel, "log-level", "l", "warn", "Set log level (debug, info, warn, error, off)")
 
+	// Settings of the repository config file apply to the flags not given
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		return applyRepoConfig(cmd, cfg)
+	}
+
 	// Create logger instance
 	log := logger.New(cfg.logLevel)
 	rootCmd.AddCommand(newMigrateCommand(log, cfg))
+	rootCmd.AddCommand(newCheckCommand(log, cfg))
+	rootCmd.AddCommand(newCleanupCommand(log, cfg))
+	rootCmd.AddCommand(newRestoreCommand(log, cfg))
 	rootCmd.AddCommand(newVersionCommand())
-	rootCmd.AddCommand(newVerifyDriftCommand())
+	rootCmd.AddCommand(newVerifyDriftCommand(cfg))
+	// Errors are printed here, so that check can fail without repeating its report
+	rootCmd.SilenceErrors = true
 	if err := rootCmd.Execute(); err != nil {
+		if !errors.Is(err, errFilesNeedMigration) {
+			rootCmd.PrintErrln("Error:", err.Error())
+		}
 		os.Exit(1)
 	}
 }
@@ -129,18 +177,22 @@ Uses the global flags --config-dir and --resources to determine what to migrate.
   # Dry run to preview changes
   tf-migrate --dry-run migrate
 
+  # Print a unified diff of the migration without writing files
+  tf-migrate migrate --diff
+
+  # Write the migration as a patch file for review
+  tf-migrate migrate --patch-file migration.patch
+
+  # Collect moved/import/removed blocks into one file per directory
+  tf-migrate migrate --migrations-file tf_migrate_moves.tf
+
+  # Accept, reject or edit each transformation before it is written
+  tf-migrate migrate --review
+
   # Run with debug logging
   tf-migrate --log-level debug migrate`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if cfg.configDir == "" {
-				cfg.configDir = "."
-			}
-			if cfg.sourceVersion == "" {
-				cfg.sourceVersion = "v4"
-			}
-			if cfg.targetVersion == "" {
-				cfg.targetVersion = "v5"
-			}
+			applyConfigDefaults(cfg)
 
 			if cfg.verbose {
 				fmt.Println("Cloudflare Terraform Provider Migration Tool")
@@ -155,6 +207,10 @@ Uses the global flags --config-dir and --resources to determine what to migrate.
 				fmt.Println()
 			}
 
+			if cfg.diff || cfg.patchFile != "" {
+				cfg.dryRun = true
+			}
+
 			if cfg.dryRun {
 				fmt.Println("\n DRY RUN MODE - No changes will be made")
 			}
@@ -165,7 +221,47 @@ Uses the global flags --config-dir and --resources to determine what to migrate.
 				return err
 			}
 
-			return runMigration(log, *cfg)
+			if err := loadRuleFiles(cfg); err != nil {
+				cmd.SilenceUsage = true
+				return err
+			}
+			stopPlugins, err := startPlugins(cfg)
+			if err != nil {
+				cmd.SilenceUsage = true
+				return err
+			}
+			defer stopPlugins()
+			if err := loadStateSnapshot(cfg); err != nil {
+				cmd.SilenceUsage = true
+				return err
+			}
+			evalCtxs, err := newEvalContexts(log, *cfg)
+			if err != nil {
+				cmd.SilenceUsage = true
+				return err
+			}
+			cfg.evalContexts = evalCtxs
+			if err := loadProviderSchema(cfg); err != nil {
+				cmd.SilenceUsage = true
+				return err
+			}
+			if cfg.review {
+				// Blocks are reviewed one at a time, in file order.
+				cfg.parallelism = 1
+				cfg.reviewer = newReviewer(os.Stdin, os.Stdout)
+			}
+			if err := openCache(cfg); err != nil {
+				cmd.SilenceUsage = true
+				return err
+			}
+
+			if err := runMigration(log, *cfg); err != nil {
+				return err
+			}
+			if cfg.reviewer != nil {
+				fmt.Println(cfg.reviewer.summary())
+			}
+			return nil
 		},
 	}
 
@@ -180,6 +276,14 @@ Uses the global flags --config-dir and --resources to determine what to migrate.
 	cmd.Flags().BoolVar(&noBackup, "no-backup", false, "Skip creating backup files before migration (alias for --backup=false)")
 	cmd.Flags().BoolVar(&cfg.skipPhaseCheck, "skip-phase-check", false, "Skip the phased migration confirmation prompt and run the full migration directly (for CI/non-interactive use)")
 	cmd.Flags().BoolVar(&cfg.skipVersionCheck, "skip-version-check", false, "Skip the minimum provider version check (for testing/CI only)")
+	cmd.Flags().BoolVar(&cfg.diff, "diff", false, "Print a unified diff of the migrated files instead of writing them (implies --dry-run)")
+	cmd.Flags().StringVar(&cfg.patchFile, "patch-file", "", "Write a unified diff of the migrated files to this path instead of writing them (implies --dry-run)")
+	cmd.Flags().BoolVar(&cfg.moduleGraph, "module-graph", false, "Follow local module sources (./ and ../) from each root module and migrate every module once, even outside --config-dir")
+	cmd.Flags().StringVar(&cfg.stateFile, "state-file", "", "Read v4 resource attributes from this state snapshot (terraform show -json or terraform state pull output) to fill values the config does not spell out")
+	cmd.Flags().StringSliceVar(&cfg.varFiles, "var-file", []string{}, "Variable definitions file to resolve var.* references with, in addition to terraform.tfvars and *.auto.tfvars (can be specified multiple times)")
+	cmd.Flags().StringVar(&cfg.schemaFile, "schema-file", "", "Validate migrated files against this provider schema (terraform providers schema -json output)")
+	cmd.Flags().StringVar(&cfg.migrationsFile, "migrations-file", "", "Collect generated moved/import/removed blocks into this file in each directory (e.g. "+defaultMigrationsFile+") instead of placing them after their resource")
+	cmd.Flags().BoolVar(&cfg.review, "review", false, "Show each transformed block next to the original and its diagnostics, and accept, reject or edit it before the file is written")
 	cmd.PreRun = func(cmd *cobra.Command, args []string) {
 		if noBackup {
 			cfg.backup = false
@@ -193,6 +297,117 @@ Uses the global flags --config-dir and --resources to determine what to migrate.
 	return cmd
 }
 
+// loadRuleFiles registers the migrators of the --rules-file files. A rule
+// replaces the built-in migrator of its type.
+func loadRuleFiles(cfg *config) error {
+	for _, path := range cfg.ruleFiles {
+		loaded, err := rules.Load(path)
+		if err != nil {
+			return err
+		}
+		rules.Register(loaded)
+		if cfg.verbose {
+			fmt.Printf("Rule file: %s (%d rule(s))\n", path, len(loaded))
+		}
+	}
+	return nil
+}
+
+// startPlugins starts the --plugin executables and registers their
+// migrators. The returned function stops them again.
+func startPlugins(cfg *config) (func(), error) {
+	var started []*plugin.Plugin
+	stop := func() {
+		for _, p := range started {
+			if err := p.Close(); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			}
+		}
+	}
+	for _, path := range cfg.pluginPaths {
+		p, err := plugin.Start(path)
+		if err != nil {
+			stop()
+			return nil, err
+		}
+		started = append(started, p)
+		p.Register()
+		if cfg.verbose {
+			fmt.Printf("Plugin: %s (%d type(s))\n", path, len(p.Migrators()))
+		}
+	}
+	return stop, nil
+}
+
+// loadStateSnapshot reads the state snapshot given with --state-file into
+// cfg.state. The snapshot is only read locally; nothing is refreshed.
+func loadStateSnapshot(cfg *config) error {
+	if cfg.stateFile == "" {
+		return nil
+	}
+	state, err := transform.LoadStateSnapshot(cfg.stateFile)
+	if err != nil {
+		return err
+	}
+	cfg.state = state
+	if cfg.verbose {
+		fmt.Printf("State snapshot: %s (%d resource instances)\n", cfg.stateFile, state.Len())
+	}
+	return nil
+}
+
+// loadProviderSchema loads the --schema-file provider schema to validate
+// migrated files against into cfg.providerSchema.
+func loadProviderSchema(cfg *config) error {
+	if cfg.schemaFile == "" {
+		return nil
+	}
+	s, err := schema.Load(cfg.schemaFile)
+	if err != nil {
+		return err
+	}
+	cfg.providerSchema = s
+	return nil
+}
+
+// validateContents validates the migrated files against cfg.providerSchema.
+func validateContents(log hclog.Logger, cfg config, outputPaths []string, contents map[string]string) hcl.Diagnostics {
+	var diags hcl.Diagnostics
+	validated := 0
+	for _, path := range outputPaths {
+		content, ok := contents[path]
+		if !ok || isTerragruntConfigFile(filepath.Base(path)) {
+			continue
+		}
+		fileDiags := schema.Validate(cfg.providerSchema, path, []byte(content))
+		log.Debug("Validated file against provider schema", "file", filepath.Base(path), "issues", len(fileDiags))
+		diags = append(diags, fileDiags...)
+		validated++
+	}
+	if cfg.verbose {
+		if len(diags) == 0 {
+			fmt.Printf("✓ Validated %d file(s) against the provider schema\n", validated)
+		} else {
+			fmt.Printf("⚠ Provider schema validation found %d issue(s) in %d file(s)\n", len(diags), validated)
+		}
+	}
+	return diags
+}
+
+// applyConfigDefaults fills in the config directory and migration path when
+// they were not given on the command line.
+func applyConfigDefaults(cfg *config) {
+	if cfg.configDir == "" {
+		cfg.configDir = "."
+	}
+	if cfg.sourceVersion == "" {
+		cfg.sourceVersion = "v4"
+	}
+	if cfg.targetVersion == "" {
+		cfg.targetVersion = "v5"
+	}
+}
+
 func newVersionCommand() *cobra.Command {
 	return &cobra.Command{
 		Use:   "version",
@@ -203,7 +418,7 @@ func newVersionCommand() *cobra.Command {
 	}
 }
 
-func newVerifyDriftCommand() *cobra.Command {
+func newVerifyDriftCommand(cfg *config) *cobra.Command {
 	var planFile string
 	cmd := &cobra.Command{
 		Use:   "verify-drift",
@@ -215,13 +430,16 @@ Exit code 0: all drift is expected or none detected.
 Exit code 1: unexpected drift requires attention.`,
 		Example: `  # Export plan output and verify
   terraform plan > plan.txt
-  tf-migrate verify-drift --file plan.txt`,
+  tf-migrate verify-drift --file plan.txt
+
+  # Also accept the drift listed in a repository exemptions file
+  tf-migrate verify-drift --file plan.txt --exemptions-file ops/drift-exemptions.yaml`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			content, err := os.ReadFile(planFile)
 			if err != nil {
 				return fmt.Errorf("reading plan file: %w", err)
 			}
-			result, err := verifydrift.Verify(string(content))
+			result, err := verifydrift.Verify(string(content), cfg.driftExemptionFiles...)
 			if err != nil {
 				return fmt.Errorf("verifying drift: %w", err)
 			}
@@ -234,6 +452,7 @@ Exit code 1: unexpected drift requires attention.`,
 	}
 	cmd.Flags().StringVar(&planFile, "file", "", "Path to terraform plan output file (required)")
 	_ = cmd.MarkFlagRequired("file")
+	cmd.Flags().StringSliceVar(&cfg.driftExemptionFiles, "exemptions-file", []string{}, "Drift exemption file applied with the built-in exemptions (can be specified multiple times)")
 	return cmd
 }
 
@@ -406,90 +625,21 @@ func updateProviderVersionConstraint(log hclog.Logger, cfg config, diags hcl.Dia
 			// GitHub API was unreachable (rate-limited, offline, etc.).
 			// Do not write a stale hardcoded version — tell the user to update manually.
 			log.Debug("GitHub API unavailable — skipping provider version update")
+			// Directories that pin a version in the config file are still updated.
+			if _, err := writeProviderVersionConstraints(log, cfg, ""); err != nil {
+				return err
+			}
 			printVersionFetchFailure(cfg, diags)
 			return nil
 		}
 		log.Debug("Provider version fetched from GitHub API", "version", targetVersion)
 	}
 
-	files, err := findTerraformFilesWithRecursion(cfg.configDir, cfg.recursive, cfg.exclude)
+	updatedDirs, err := writeProviderVersionConstraints(log, cfg, targetVersion)
 	if err != nil {
 		return err
 	}
 
-	// Track which directories had their provider version updated
-	updatedDirs := make(map[string]bool)
-
-	for _, file := range files {
-		content, err := os.ReadFile(file)
-		if err != nil {
-			continue
-		}
-
-		parsed, diags := hclwrite.ParseConfig(content, filepath.Base(file), hcl.InitialPos)
-		if diags.HasErrors() {
-			continue
-		}
-
-		updated := false
-		for _, block := range parsed.Body().Blocks() {
-			if block.Type() != "terraform" {
-				continue
-			}
-			for _, inner := range block.Body().Blocks() {
-				if inner.Type() != "required_providers" {
-					continue
-				}
-				cfAttr := inner.Body().GetAttribute("cloudflare")
-				if cfAttr == nil {
-					continue
-				}
-				// The attribute value is an object expression — check if it
-				// contains source = "cloudflare/cloudflare"
-				attrStr := string(cfAttr.BuildTokens(nil).Bytes())
-				if !strings.Contains(attrStr, "cloudflare/cloudflare") {
-					continue
-				}
-				// Rewrite the version value using raw token replacement.
-				// We replace any existing version = "..." with the target.
-				newAttrStr := regexp.MustCompile(`version\s*=\s*"[^"]*"`).
-					ReplaceAllString(attrStr, `version = "`+targetVersion+`"`)
-				if newAttrStr == attrStr {
-					// No version constraint existed — add one
-					newAttrStr = strings.Replace(attrStr,
-						`source  = "cloudflare/cloudflare"`,
-						`source  = "cloudflare/cloudflare"`+"\n      version = \""+targetVersion+`"`,
-						1)
-					if newAttrStr == attrStr {
-						// Try without double space
-						newAttrStr = strings.Replace(attrStr,
-							`source = "cloudflare/cloudflare"`,
-							`source  = "cloudflare/cloudflare"`+"\n      version = \""+targetVersion+`"`,
-							1)
-					}
-				}
-				if newAttrStr != attrStr {
-					// Re-parse the file with the updated attribute text and write it.
-					newContent := strings.Replace(string(content), attrStr, newAttrStr, 1)
-					if err := os.WriteFile(file, []byte(newContent), 0644); err != nil {
-						log.Warn("Failed to write updated provider version", "file", file, "error", err)
-						continue
-					}
-					updated = true
-					if cfg.verbose {
-						fmt.Printf("✓ Updated cloudflare provider version to %s in %s\n",
-							targetVersion, filepath.Base(file))
-					}
-				}
-			}
-		}
-		if updated {
-			// Track the directory containing this file
-			dir := filepath.Dir(file)
-			updatedDirs[dir] = true
-		}
-	}
-
 	fmt.Println()
 	fmt.Printf("Provider version updated to %s.\n", targetVersion)
 	fmt.Println()
@@ -518,7 +668,7 @@ func updateProviderVersionConstraint(log hclog.Logger, cfg config, diags hcl.Dia
 	if len(updatedDirs) <= 1 {
 		// Single directory (or none) - use the original format
 		fmt.Printf("       cd %s\n", cfg.configDir)
-		fmt.Println("       terraform init -upgrade -backend=false")
+		fmt.Printf("       %s\n", lockFileInitCommand(cfg.configDir))
 	} else {
 		// Multiple directories with provider updates
 		// Sort for consistent output
@@ -536,7 +686,7 @@ func updateProviderVersionConstraint(log hclog.Logger, cfg config, diags hcl.Dia
 			}
 		}
 		for _, dir := range sortedDirs {
-			fmt.Printf("       cd %s && terraform init -upgrade -backend=false\n", dir)
+			fmt.Printf("       cd %s && %s\n", dir, lockFileInitCommand(dir))
 		}
 	}
 	fmt.Println()
@@ -557,6 +707,121 @@ func updateProviderVersionConstraint(log hclog.Logger, cfg config, diags hcl.Dia
 	return nil
 }
 
+// writeProviderVersionConstraints sets the cloudflare provider version
+// constraint of the configuration files to targetVersion, or to the version
+// their directory pins in the repository config file, and returns the
+// directories of the files it updated. Files with neither are left alone.
+func writeProviderVersionConstraints(log hclog.Logger, cfg config, targetVersion string) (map[string]bool, error) {
+	files, err := findConfigFiles(log, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// Track which directories had their provider version updated
+	updatedDirs := make(map[string]bool)
+
+	for _, file := range files {
+		// The constraint is rewritten as text, which only works for native syntax.
+		if tfhcl.IsJSONConfigFile(file) {
+			continue
+		}
+
+		version := targetVersion
+		if pinned := cfg.repoConfig.providerVersionFor(file); pinned != "" {
+			version = pinned
+		}
+		if version == "" {
+			continue
+		}
+
+		content, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		if tfhcl.FindIgnoreAnnotations(content, filepath.Base(file)).File {
+			continue
+		}
+
+		var newContent []byte
+		updated := false
+		if isTerragruntConfigFile(filepath.Base(file)) {
+			// The constraint is set in the Terraform code generated by the
+			// unit, not in the Terragrunt file itself.
+			newContent, _, err = rewriteGeneratedContents(content, filepath.Base(file), func(g generatedContent) (string, error) {
+				rewritten, _ := setCloudflareVersionConstraint([]byte(g.Content), g.Path, version)
+				return string(rewritten), nil
+			})
+			updated = err == nil && !bytes.Equal(newContent, content)
+		} else {
+			newContent, updated = setCloudflareVersionConstraint(content, filepath.Base(file), version)
+		}
+		if updated {
+			if err := os.WriteFile(file, newContent, 0644); err != nil {
+				log.Warn("Failed to write updated provider version", "file", file, "error", err)
+				continue
+			}
+			if err := recordBackups([]string{file}, map[string]string{file: string(newContent)}); err != nil {
+				log.Warn("Failed to record updated provider version for restore", "file", file, "error", err)
+			}
+			if cfg.verbose {
+				fmt.Printf("✓ Updated cloudflare provider version to %s in %s\n",
+					version, filepath.Base(file))
+			}
+			// Track the directory containing this file
+			dir := filepath.Dir(file)
+			updatedDirs[dir] = true
+		}
+	}
+	return updatedDirs, nil
+}
+
+// setCloudflareVersionConstraint sets the version constraint of the
+// cloudflare entry in required_providers to targetVersion, adding one if
+// there is none. It reports whether content changed.
+func setCloudflareVersionConstraint(content []byte, filename, targetVersion string) ([]byte, bool) {
+	parsed, diags := hclwrite.ParseConfig(content, filename, hcl.InitialPos)
+	if diags.HasErrors() {
+		return content, false
+	}
+
+	newContent := string(content)
+	for _, block := range parsed.Body().Blocks() {
+		if block.Type() != "terraform" {
+			continue
+		}
+		for _, inner := range block.Body().Blocks() {
+			if inner.Type() != "required_providers" {
+				continue
+			}
+			cfAttr := inner.Body().GetAttribute("cloudflare")
+			if cfAttr == nil {
+				continue
+			}
+			// The attribute value is an object expression — check if it
+			// contains source = "cloudflare/cloudflare" (any registry host)
+			attrStr := string(cfAttr.BuildTokens(nil).Bytes())
+			if !strings.Contains(attrStr, "cloudflare/cloudflare") {
+				continue
+			}
+			// Rewrite the version value using raw token replacement.
+			// We replace any existing version = "..." with the target.
+			versionRe := regexp.MustCompile(`version\s*=\s*"[^"]*"`)
+			newAttrStr := versionRe.ReplaceAllString(attrStr, `version = "`+targetVersion+`"`)
+			if !versionRe.MatchString(attrStr) {
+				// No version constraint existed — add one after the source,
+				// keeping whichever registry host it names.
+				newAttrStr = regexp.MustCompile(`source\s*=\s*"([^"]*cloudflare/cloudflare)"`).
+					ReplaceAllString(attrStr, `source  = "$1"`+"\n      version = \""+targetVersion+`"`)
+			}
+			if newAttrStr != attrStr {
+				newContent = strings.Replace(newContent, attrStr, newAttrStr, 1)
+			}
+		}
+	}
+
+	return []byte(newContent), newContent != string(content)
+}
+
 // printVersionFetchFailure is called when the GitHub API is unreachable.
 // Instead of silently writing a stale hardcoded version it tells the user
 // to look up the latest version and update required_providers manually.
@@ -601,7 +866,7 @@ func printVersionFetchFailure(cfg config, diags hcl.Diagnostics) {
 
 	fmt.Printf("  %d. Regenerate the lock file locally:\n", step)
 	fmt.Printf("       cd %s\n", cfg.configDir)
-	fmt.Println("       terraform init -upgrade -backend=false")
+	fmt.Printf("       %s\n", lockFileInitCommand(cfg.configDir))
 	fmt.Println()
 	step++
 
@@ -615,7 +880,7 @@ func printVersionFetchFailure(cfg config, diags hcl.Diagnostics) {
 // commented-out resource blocks from a previous phase-1 run, identified by
 // the phaseOneCommentPrefix marker.
 func findFilesWithPhaseOneComments(cfg config) []string {
-	files, err := findTerraformFilesWithRecursion(cfg.configDir, cfg.recursive, cfg.exclude)
+	files, err := findConfigFiles(hclog.NewNullLogger(), cfg)
 	if err != nil {
 		return nil
 	}
@@ -655,7 +920,7 @@ func confirmPhaseOneApplied(cfg config) (bool, error) {
 // detectPhaseOneResources scans all .tf files and returns a map of
 // filename → resource addresses for resources whose migrator implements PhaseOneTransformer.
 func detectPhaseOneResources(log hclog.Logger, cfg config) (map[string][]string, error) {
-	files, err := findTerraformFilesWithRecursion(cfg.configDir, cfg.recursive, cfg.exclude)
+	files, err := findConfigFiles(log, cfg)
 	if err != nil {
 		return nil, err
 	}
@@ -664,6 +929,12 @@ func detectPhaseOneResources(log hclog.Logger, cfg config) (map[string][]string,
 	found := make(map[string][]string)
 
 	for _, file := range files {
+		// Phase 1 comments out blocks as text, which JSON syntax has no
+		// equivalent for; .tf.json resources are migrated in a single phase.
+		if tfhcl.IsJSONConfigFile(file) {
+			continue
+		}
+
 		content, err := os.ReadFile(file)
 		if err != nil {
 			log.Warn("Failed to read file during phase-1 scan", "file", file, "error", err)
@@ -745,16 +1016,7 @@ func runPhaseOne(log hclog.Logger, cfg config, phaseOneResources map[string][]st
 			return fmt.Errorf("failed to parse %s: %w", file, diags)
 		}
 
-		ctx := &transform.Context{
-			Content:       content,
-			Filename:      filepath.Base(file),
-			FilePath:      file,
-			Diagnostics:   make(hcl.Diagnostics, 0),
-			Metadata:      make(map[string]interface{}),
-			SourceVersion: cfg.sourceVersion,
-			TargetVersion: cfg.targetVersion,
-			Resources:     cfg.resourcesToMigrate,
-		}
+		ctx := newTransformContext(cfg, file, content)
 
 		// Snapshot the normalized file bytes BEFORE calling BuildTokens on
 		// any block. hclwrite.File.Bytes() normalizes whitespace around
@@ -830,13 +1092,16 @@ func runPhaseOne(log hclog.Logger, cfg config, phaseOneResources map[string][]st
 		}
 
 		if cfg.backup {
-			if err := os.WriteFile(file+".backup", content, 0644); err != nil {
+			if err := os.WriteFile(file+backupSuffix, content, 0644); err != nil {
 				return fmt.Errorf("failed to create backup for %s: %w", file, err)
 			}
 		}
 		if err := os.WriteFile(file, []byte(newContent), 0644); err != nil {
 			return fmt.Errorf("failed to write %s: %w", file, err)
 		}
+		if err := recordBackups([]string{file}, map[string]string{file: newContent}); err != nil {
+			return err
+		}
 		fmt.Printf("✓ %s — commented out %d resource block(s), added removed {} block(s)\n",
 			filepath.Base(file), len(pairs))
 	}
@@ -971,13 +1236,123 @@ func restorePhaseOneFile(content string) string {
 	return strings.Join(out, "\n")
 }
 
+// newTransformContext builds the pipeline context for a single file.
+func newTransformContext(cfg config, file string, content []byte) *transform.Context {
+	ctx := &transform.Context{
+		Content:       content,
+		Filename:      filepath.Base(file),
+		FilePath:      file,
+		Diagnostics:   make(hcl.Diagnostics, 0),
+		Metadata:      make(map[string]interface{}),
+		SourceVersion: cfg.sourceVersion,
+		TargetVersion: cfg.targetVersion,
+		Resources:     cfg.resourcesToMigrate,
+		SkipTypes:     cfg.repoConfig.skipTypesFor(file),
+
+		CollectMigrationBlocks: cfg.migrationsFile != "",
+		State:                  cfg.state,
+		EvalContext:            cfg.evalContexts.forFile(file),
+	}
+	if cfg.reviewer != nil {
+		ctx.Review = cfg.reviewer.review
+	}
+	return ctx
+}
+
+// fileResult is the outcome of migrating one configuration file.
+type fileResult struct {
+	outputPath      string
+	transformed     []byte
+	cfgFile         *hclwrite.File
+	migrationBlocks []*hclwrite.Block
+	diagnostics     hcl.Diagnostics
+	err             error
+}
+
+// processConfigFile migrates file, whose content is content, and writes the
+// result and its backup unless cfg.dryRun is set. It is safe to call for
+// several files at once.
+func processConfigFile(log hclog.Logger, p *pipeline.Pipeline, cfg config, file string, content []byte) *fileResult {
+	ctx := newTransformContext(cfg, file, content)
+	transformed, err := transformFileCached(p, cfg, ctx)
+	r := &fileResult{diagnostics: ctx.Diagnostics}
+	if err != nil {
+		r.err = fmt.Errorf("failed to transform %s: %w", file, err)
+		return r
+	}
+	r.transformed = transformed
+	r.cfgFile = ctx.CFGFile
+	r.migrationBlocks = ctx.MigrationBlocks
+
+	// A file the pipeline leaves untouched is already migrated. Skip its
+	// backup so that re-running a migration never overwrites the backup of
+	// the original v4 file.
+	alreadyMigrated := bytes.Equal(transformed, content)
+	if alreadyMigrated {
+		log.Debug("File already migrated", "file", file)
+	}
+
+	if cfg.backup && !cfg.dryRun && cfg.outputDir == cfg.configDir && !alreadyMigrated {
+		backupPath := file + backupSuffix
+		if err := os.WriteFile(backupPath, content, 0644); err != nil {
+			r.err = fmt.Errorf("failed to create backup %s: %w", backupPath, err)
+			return r
+		}
+		log.Debug("Created backup", "path", backupPath)
+	}
+
+	// Calculate output path maintaining directory structure when recursive
+	if cfg.recursive || cfg.moduleGraph {
+		// Preserve directory structure relative to config dir
+		relPath, err := filepath.Rel(cfg.configDir, file)
+		if err != nil {
+			r.err = fmt.Errorf("failed to compute relative path: %w", err)
+			return r
+		}
+		if strings.HasPrefix(relPath, ".."+string(filepath.Separator)) && cfg.outputDir != cfg.configDir {
+			r.err = fmt.Errorf("module file %s is outside --config-dir and cannot be written to --output-dir; migrate in place instead", file)
+			return r
+		}
+		r.outputPath = filepath.Join(cfg.outputDir, relPath)
+	} else {
+		r.outputPath = filepath.Join(cfg.outputDir, filepath.Base(file))
+	}
+
+	if cfg.dryRun {
+		log.Debug("Would write file", "output", r.outputPath)
+		return r
+	}
+
+	// Create output directory (including subdirectories if needed)
+	if err := os.MkdirAll(filepath.Dir(r.outputPath), 0755); err != nil {
+		r.err = fmt.Errorf("failed to create output directory: %w", err)
+		return r
+	}
+	if err := os.WriteFile(r.outputPath, transformed, 0644); err != nil {
+		r.err = fmt.Errorf("failed to write %s: %w", r.outputPath, err)
+		return r
+	}
+	log.Debug("Migrated file", "output", r.outputPath)
+	return r
+}
+
 func processConfigFiles(log hclog.Logger, p *pipeline.Pipeline, cfg config) (map[string]*hclwrite.File, hcl.Diagnostics, error) {
 	if cfg.outputDir == "" {
 		cfg.outputDir = cfg.configDir
 	}
 
-	files, err := findTerraformFilesWithRecursion(cfg.configDir, cfg.recursive, cfg.exclude)
-	if err != nil {
+	var files []string
+	var err error
+	if cfg.moduleGraph {
+		graph, graphErr := buildModuleGraph(log, cfg)
+		if graphErr != nil {
+			return nil, nil, fmt.Errorf("failed to resolve module sources: %w", graphErr)
+		}
+		if cfg.verbose {
+			printModuleGraph(graph, cfg)
+		}
+		files = graph.Files
+	} else if files, err = findTerraformFilesWithRecursion(cfg.configDir, cfg.recursive, cfg.exclude); err != nil {
 		return nil, nil, fmt.Errorf("failed to list .tf files: %w", err)
 	}
 
@@ -993,316 +1368,239 @@ func processConfigFiles(log hclog.Logger, p *pipeline.Pipeline, cfg config) (map
 	// Store file paths for global postprocessing
 	outputPaths := make([]string, 0, len(files))
 
+	// The migrated content is kept in memory (keyed by output path) so that
+	// global postprocessing and --diff/--patch-file need not read it back.
+	migratedContents := make(map[string]string, len(files))
+	dryRunOriginals := make(map[string]string)
+	dryRunNames := make(map[string]string)
+
+	// With --migrations-file, moved/import/removed blocks are collected per
+	// output directory and written to that file after all files are migrated.
+	migrationBlocksByDir := make(map[string][]*hclwrite.Block)
+	var migrationDirs []string
+
 	// Collect diagnostics from all files
 	var allDiagnostics hcl.Diagnostics
 
+	contents := make([][]byte, len(files))
+	readErrs := make([]error, len(files))
+	parallel.ForEach(len(files), cfg.parallelism, func(i int) {
+		contents[i], readErrs[i] = os.ReadFile(files[i])
+	})
+	sources := make([]tfhcl.SourceFile, len(files))
+	for i, file := range files {
+		if readErrs[i] != nil {
+			return nil, allDiagnostics, fmt.Errorf("failed to read %s: %w", file, readErrs[i])
+		}
+		sources[i] = tfhcl.SourceFile{Name: file, Content: contents[i]}
+	}
+	scanned := pipeline.ScanConfigs(log, getProviders(cfg.resourcesToMigrate...), cfg.sourceVersion, cfg.targetVersion, sources, cfg.parallelism)
+	// What the scanners saw can change how any file is migrated.
+	cfg.cache = cfg.cache.With(scanned)
+
+	// Files are migrated and written on a worker pool. Results are kept by
+	// index and gathered in file order, so that output and diagnostics do not
+	// depend on scheduling.
+	results := make([]*fileResult, len(files))
+	parallel.ForEach(len(files), cfg.parallelism, func(i int) {
+		log.Debug("Processing file", "file", files[i], "index", i+1)
+		results[i] = processConfigFile(log, p, cfg, files[i], contents[i])
+	})
+	printCacheStats(cfg)
+
 	parsedConfigs := make(map[string]*hclwrite.File)
 	for i, file := range files {
+		r := results[i]
 		if cfg.verbose {
 			fmt.Printf("[%d/%d] Processing %s... ", i+1, len(files), filepath.Base(file))
 		}
-		log.Debug("Processing file", "file", file, "index", i+1)
 
-		content, err := os.ReadFile(file)
-		if err != nil {
-			return nil, allDiagnostics, fmt.Errorf("failed to read %s: %w", file, err)
+		// Collect diagnostics from this file's context
+		allDiagnostics = append(allDiagnostics, r.diagnostics...)
+		if r.err != nil {
+			return nil, allDiagnostics, r.err
 		}
 
-		if cfg.backup && !cfg.dryRun && cfg.outputDir == cfg.configDir {
-			backupPath := file + ".backup"
-			if err := os.WriteFile(backupPath, content, 0644); err != nil {
-				return nil, allDiagnostics, fmt.Errorf("failed to create backup %s: %w", backupPath, err)
+		if r.cfgFile != nil {
+			parsedConfigs[file] = r.cfgFile
+		}
+
+		if len(r.migrationBlocks) > 0 {
+			dir := filepath.Dir(r.outputPath)
+			if _, ok := migrationBlocksByDir[dir]; !ok {
+				migrationDirs = append(migrationDirs, dir)
 			}
-			log.Debug("Created backup", "path", backupPath)
+			migrationBlocksByDir[dir] = append(migrationBlocksByDir[dir], r.migrationBlocks...)
 		}
 
-		ctx := &transform.Context{
-			Content:       content,
-			Filename:      filepath.Base(file),
-			FilePath:      file,
-			Diagnostics:   make(hcl.Diagnostics, 0),
-			Metadata:      make(map[string]interface{}),
-			SourceVersion: cfg.sourceVersion,
-			TargetVersion: cfg.targetVersion,
-			Resources:     cfg.resourcesToMigrate,
+		outputPaths = append(outputPaths, r.outputPath)
+		migratedContents[r.outputPath] = string(r.transformed)
+		if cfg.dryRun {
+			if cfg.verbose {
+				fmt.Println("(dry run)")
+			}
+			dryRunOriginals[r.outputPath] = string(contents[i])
+			dryRunNames[r.outputPath] = diffDisplayName(cfg.configDir, file)
+			continue
 		}
-		transformed, err := p.Transform(ctx)
-		if err != nil {
-			return nil, allDiagnostics, fmt.Errorf("failed to transform %s: %w", file, err)
+		if cfg.verbose {
+			fmt.Println("✓")
 		}
+	}
 
-		// Collect diagnostics from this file's context
-		allDiagnostics = append(allDiagnostics, ctx.Diagnostics...)
+	for _, dir := range migrationDirs {
+		path := filepath.Join(dir, cfg.migrationsFile)
 
-		if ctx.CFGFile != nil {
-			parsedConfigs[file] = ctx.CFGFile
+		var existing []byte
+		if migrated, ok := migratedContents[path]; ok {
+			existing = []byte(migrated)
+		} else if existing, err = os.ReadFile(path); err != nil && !os.IsNotExist(err) {
+			return nil, allDiagnostics, fmt.Errorf("failed to read %s: %w", path, err)
 		}
 
-		// Calculate output path maintaining directory structure when recursive
-		var outputPath string
-		if cfg.recursive {
-			// Preserve directory structure relative to config dir
-			relPath, err := filepath.Rel(cfg.configDir, file)
-			if err != nil {
-				return nil, allDiagnostics, fmt.Errorf("failed to compute relative path: %w", err)
-			}
-			outputPath = filepath.Join(cfg.outputDir, relPath)
-		} else {
-			outputPath = filepath.Join(cfg.outputDir, filepath.Base(file))
+		content, err := renderMigrationsFile(path, existing, migrationBlocksByDir[dir])
+		if err != nil {
+			return nil, allDiagnostics, err
 		}
 
 		if cfg.dryRun {
-			if cfg.verbose {
-				fmt.Println("(dry run)")
+			if _, ok := migratedContents[path]; !ok {
+				outputPaths = append(outputPaths, path)
+				dryRunOriginals[path] = string(existing)
+				dryRunNames[path] = diffDisplayName(cfg.outputDir, path)
 			}
-			log.Debug("Would write file", "output", outputPath)
-			outputPaths = append(outputPaths, outputPath)
+			migratedContents[path] = string(content)
 			continue
 		}
 
-		// Create output directory (including subdirectories if needed)
-		outputDir := filepath.Dir(outputPath)
-		if err := os.MkdirAll(outputDir, 0755); err != nil {
-			return nil, allDiagnostics, fmt.Errorf("failed to create output directory: %w", err)
+		if err := os.WriteFile(path, content, 0644); err != nil {
+			return nil, allDiagnostics, fmt.Errorf("failed to write %s: %w", path, err)
 		}
-
-		if err := os.WriteFile(outputPath, transformed, 0644); err != nil {
-			return nil, allDiagnostics, fmt.Errorf("failed to write %s: %w", outputPath, err)
-		}
-		if cfg.verbose {
-			fmt.Println("✓")
+		if _, ok := migratedContents[path]; ok {
+			migratedContents[path] = string(content)
 		}
-		log.Debug("Migrated file", "output", outputPath)
-		outputPaths = append(outputPaths, outputPath)
-
+		log.Debug("Wrote migration blocks", "output", path, "count", len(migrationBlocksByDir[dir]))
 	}
 
 	// Apply global postprocessing for cross-file reference updates
 	if !cfg.dryRun && len(outputPaths) > 0 {
-		postDiags, err := applyGlobalPostprocessing(log, cfg, outputPaths)
+		postDiags, err := applyGlobalPostprocessing(log, cfg, outputPaths, migratedContents)
 		allDiagnostics = append(allDiagnostics, postDiags...)
 		if err != nil {
 			return nil, allDiagnostics, fmt.Errorf("failed to apply global postprocessing: %w", err)
 		}
 	}
 
-	return parsedConfigs, allDiagnostics, nil
-}
-
-func applyGlobalPostprocessing(log hclog.Logger, cfg config, outputPaths []string) (hcl.Diagnostics, error) {
-	var diags hcl.Diagnostics
-
-	// Collect resource renames, attribute renames, computed attribute mappings,
-	// and invalid attribute reference detectors from all migrators.
-	providers := getProviders(cfg.resourcesToMigrate...)
-	migrators := providers.GetAllMigrators(cfg.sourceVersion, cfg.targetVersion, cfg.resourcesToMigrate...)
-
-	// Map to store old type -> new type mappings
-	renames := make(map[string]string)
-	// Slice to store attribute renames
-	var attributeRenames []transform.AttributeRename
-	// Slice to store computed attribute mappings (for when both resource type AND attribute change)
-	var computedAttrMappings []transform.ComputedAttributeMapping
-	// Slice to store invalid attribute references to warn about
-	var invalidAttrRefs []transform.InvalidAttributeReference
-
-	for _, migrator := range migrators {
-		// Check if this migrator implements ResourceRenamer interface
-		if renamer, ok := migrator.(transform.ResourceRenamer); ok {
-			oldTypes, newType := renamer.GetResourceRename()
-			if len(oldTypes) > 0 && newType != "" {
-				for _, oldType := range oldTypes {
-					if oldType != newType {
-						renames[oldType] = newType
-						log.Debug("Collected resource rename", "old", oldType, "new", newType)
-					} else {
-						log.Debug("Resource type unchanged", "type", oldType)
-					}
-				}
-			} else {
-				log.Debug("Migrator implements ResourceRenamer but returned empty type names",
-					"oldTypes", oldTypes, "newType", newType)
-			}
-		} else {
-			log.Debug("Migrator does not implement ResourceRenamer interface - cross-file references may not be updated",
-				"migrator", fmt.Sprintf("%T", migrator))
-		}
-
-		// Check if this migrator implements AttributeRenamer interface
-		if attrRenamer, ok := migrator.(transform.AttributeRenamer); ok {
-			renames := attrRenamer.GetAttributeRenames()
-			if len(renames) > 0 {
-				attributeRenames = append(attributeRenames, renames...)
-				for _, r := range renames {
-					log.Debug("Collected attribute rename",
-						"resource_type", r.ResourceType,
-						"old_attr", r.OldAttribute,
-						"new_attr", r.NewAttribute)
-				}
-			}
-		}
-
-		// Check if this migrator implements ComputedAttributeMapper interface
-		if computedAttrMapper, ok := migrator.(transform.ComputedAttributeMapper); ok {
-			mappings := computedAttrMapper.GetComputedAttributeMappings()
-			if len(mappings) > 0 {
-				computedAttrMappings = append(computedAttrMappings, mappings...)
-				for _, m := range mappings {
-					log.Debug("Collected computed attribute mapping",
-						"old_type", m.OldResourceType,
-						"old_attr", m.OldAttribute,
-						"new_type", m.NewResourceType,
-						"new_attr", m.NewAttribute)
-				}
-			}
-		}
-
-		// Check if this migrator implements InvalidAttributeReferenceDetector interface
-		if detector, ok := migrator.(transform.InvalidAttributeReferenceDetector); ok {
-			refs := detector.GetInvalidAttributeReferences()
-			if len(refs) > 0 {
-				invalidAttrRefs = append(invalidAttrRefs, refs...)
-				for _, r := range refs {
-					log.Debug("Collected invalid attribute reference detector",
-						"resource_type", r.ResourceType,
-						"attribute", r.Attribute)
-				}
-			}
+	// Record what was written to the backed-up files, so that restore and
+	// cleanup can tell whether they were edited since.
+	if !cfg.dryRun && cfg.backup && cfg.outputDir == cfg.configDir {
+		if err := recordBackups(outputPaths, migratedContents); err != nil {
+			return nil, allDiagnostics, err
 		}
 	}
 
-	// If no renames or detectors found, skip global postprocessing
-	if len(renames) == 0 && len(attributeRenames) == 0 && len(computedAttrMappings) == 0 && len(invalidAttrRefs) == 0 {
-		log.Debug("No renames found, skipping global postprocessing")
-		return diags, nil
+	// In dry-run mode, postprocess the in-memory results so that the diff shows
+	// exactly what a real run would write.
+	if cfg.dryRun && (cfg.diff || cfg.patchFile != "") && len(outputPaths) > 0 {
+		postDiags := postprocessContents(log, cfg, outputPaths, migratedContents)
+		allDiagnostics = append(allDiagnostics, postDiags...)
+		if err := writeMigrationDiff(cfg, outputPaths, dryRunNames, dryRunOriginals, migratedContents); err != nil {
+			return nil, allDiagnostics, err
+		}
 	}
 
-	// Track resources that were intentionally converted to removed {} blocks.
-	// References to these addresses must NOT be rewritten to renamed types.
-	removedRefsByType, err := collectRemovedRefsByType(outputPaths)
-	if err != nil {
-		log.Warn("Failed to collect removed block references for postprocessing", "error", err)
+	// Validate the migrated files whether or not there were references to
+	// update, and on a dry run without a diff
+	if cfg.providerSchema != nil && len(outputPaths) > 0 {
+		allDiagnostics = append(allDiagnostics, validateContents(log, cfg, outputPaths, migratedContents)...)
 	}
 
-	// Track which renames were actually applied (content changed)
-	appliedRenames := make(map[string]string)
-	appliedAttrRenames := make(map[string]transform.AttributeRename)               // key: ResourceType.OldAttribute
-	appliedComputedMappings := make(map[string]transform.ComputedAttributeMapping) // key: OldResourceType.OldAttribute
+	return parsedConfigs, allDiagnostics, nil
+}
 
-	if cfg.verbose {
-		fmt.Printf("\nApplying cross-file reference updates across %d files...\n", len(outputPaths))
+// applyGlobalPostprocessing rewrites cross-file references in contents, the
+// migrated content of the output files as written, and writes back every file
+// that changed.
+func applyGlobalPostprocessing(log hclog.Logger, cfg config, outputPaths []string, contents map[string]string) (hcl.Diagnostics, error) {
+	original := make(map[string]string, len(contents))
+	for path, content := range contents {
+		original[path] = content
 	}
 
-	// Apply renames to all files
+	diags := postprocessContents(log, cfg, outputPaths, contents)
+
 	for _, outputPath := range outputPaths {
-		content, err := os.ReadFile(outputPath)
-		if err != nil {
-			log.Warn("Failed to read file for global postprocessing", "file", outputPath, "error", err)
+		content, ok := contents[outputPath]
+		if !ok || content == original[outputPath] {
 			continue
 		}
-
-		contentStr := string(content)
-		modified := false
-
-		// Apply computed attribute mappings FIRST (for when both resource type AND attribute name change)
-		// Example: cloudflare_tunnel.<name>.cname → cloudflare_zero_trust_tunnel_cloudflared.<name>.name
-		// This must happen BEFORE resource type renames so the pattern matches the old resource type
-		for _, mapping := range computedAttrMappings {
-			// Build regex pattern to match old resource type + old attribute
-			// Pattern: cloudflare_tunnel\.([a-zA-Z0-9_-]+)\.cname
-			pattern := mapping.OldResourceType + `\.([a-zA-Z0-9_-]+)\.` + mapping.OldAttribute
-			replacement := mapping.NewResourceType + ".$1." + mapping.NewAttribute
-			newContent := regexReplaceSkippingMovedBlocks(contentStr, pattern, replacement)
-
-			if newContent != contentStr {
-				modified = true
-				contentStr = newContent
-				key := mapping.OldResourceType + "." + mapping.OldAttribute
-				appliedComputedMappings[key] = mapping
-				log.Debug("Updated computed attribute references",
-					"file", filepath.Base(outputPath),
-					"old_type", mapping.OldResourceType,
-					"old_attr", mapping.OldAttribute,
-					"new_type", mapping.NewResourceType,
-					"new_attr", mapping.NewAttribute)
-			}
+		if err := os.WriteFile(outputPath, []byte(content), 0644); err != nil {
+			return diags, fmt.Errorf("failed to write updated file %s: %w", outputPath, err)
 		}
+	}
 
-		// Apply all resource type renames, but skip content within moved blocks
-		for oldType, newType := range renames {
-			newContent := replaceResourceTypeRefsSkippingMovedBlocks(contentStr, oldType, newType, removedRefsByType[oldType])
-			if newContent != contentStr {
-				modified = true
-				contentStr = newContent
-				appliedRenames[oldType] = newType
-				log.Debug("Updated references", "file", filepath.Base(outputPath), "old", oldType, "new", newType)
-			}
-		}
+	return diags, nil
+}
 
-		// Apply all attribute renames, but skip content within moved blocks
-		// Pattern: data.cloudflare_zones.<instance_name>.zones → data.cloudflare_zones.<instance_name>.result
-		// We need to match: <ResourceType>.<instance_name>.<OldAttribute>
-		for _, rename := range attributeRenames {
-			// Build regex pattern: data\.cloudflare_zones\.([a-zA-Z0-9_-]+)\.zones
-			// The instance name can contain letters, numbers, underscores, and hyphens
-			pattern := rename.ResourceType + `\.([a-zA-Z0-9_-]+)\.` + rename.OldAttribute
-			replacement := rename.ResourceType + ".$1." + rename.NewAttribute
-			newContent := regexReplaceSkippingMovedBlocks(contentStr, pattern, replacement)
-
-			if newContent != contentStr {
-				modified = true
-				contentStr = newContent
-				key := rename.ResourceType + "." + rename.OldAttribute
-				appliedAttrRenames[key] = rename
-				log.Debug("Updated attribute references",
-					"file", filepath.Base(outputPath),
-					"resource_type", rename.ResourceType,
-					"old_attr", rename.OldAttribute,
-					"new_attr", rename.NewAttribute)
-			}
-		}
+// postprocessContents applies cross-file reference updates to contents, which
+// maps each output path to its migrated file content. Entries are updated in
+// place. Paths missing from contents are skipped.
+func postprocessContents(log hclog.Logger, cfg config, outputPaths []string, contents map[string]string) hcl.Diagnostics {
+	var diags hcl.Diagnostics
 
-		// Write back if modified
-		if modified {
-			if err := os.WriteFile(outputPath, []byte(contentStr), 0644); err != nil {
-				return diags, fmt.Errorf("failed to write updated file %s: %w", outputPath, err)
-			}
+	// Collect resource renames, attribute renames, computed attribute mappings,
+	// and invalid attribute reference detectors from all migrators.
+	providers := getProviders(cfg.resourcesToMigrate...)
+	migrators := providers.GetAllMigrators(cfg.sourceVersion, cfg.targetVersion, cfg.resourcesToMigrate...)
+	rules := postprocess.CollectRules(log, migrators)
+	if cfg.repoConfig != nil {
+		// References to the types a directory skips keep their old type there.
+		rules.Keep = func(path string, ref tfhcl.Reference) bool {
+			return cfg.repoConfig.keepReference(inputPath(cfg, path), ref)
 		}
 	}
 
-	// Scan all output files for invalid attribute references and emit DiagWarnings.
-	// This runs after all rewrites so that already-fixed references (e.g. secret →
-	// tunnel_secret) don't produce false positives.
-	if len(invalidAttrRefs) > 0 {
-		invalidAttrDiags := scanForInvalidAttributeReferences(log, outputPaths, invalidAttrRefs)
-		diags = append(diags, invalidAttrDiags...)
+	// If no renames or detectors found, skip global postprocessing
+	if rules.Empty() {
+		log.Debug("No renames found, skipping global postprocessing")
+		return diags
+	}
+
+	if cfg.verbose {
+		fmt.Printf("\nApplying cross-file reference updates across %d files...\n", len(outputPaths))
+	}
+
+	result := postprocess.Apply(log, rules, outputPaths, contents, cfg.parallelism)
+	diags = append(diags, result.Diagnostics...)
+	if len(rules.InvalidAttributeReferences) > 0 {
+		// A module output backed by an invalid attribute breaks its callers too.
+		diags = append(diags, scanModuleCallSites(log, outputPaths, contents, rules.InvalidAttributeReferences)...)
 	}
 
 	if cfg.verbose {
-		totalApplied := len(appliedRenames) + len(appliedAttrRenames) + len(appliedComputedMappings)
-		if totalApplied > 0 {
+		if result.Applied() > 0 {
 			fmt.Printf("✓ Updated cross-file references (%d of %d rules applied)\n",
-				totalApplied, len(renames)+len(attributeRenames)+len(computedAttrMappings))
+				result.Applied(), rules.Count())
 
-			if len(appliedRenames) > 0 {
+			if len(result.AppliedRenames) > 0 {
 				fmt.Println("\n  Resource type renames applied:")
-				for oldType, newType := range appliedRenames {
+				for oldType, newType := range result.AppliedRenames {
 					fmt.Printf("    %s → %s\n", oldType, newType)
 				}
 			}
 
-			if len(appliedAttrRenames) > 0 {
+			if len(result.AppliedAttributeRenames) > 0 {
 				fmt.Println("\n  Attribute renames applied:")
-				for _, rename := range appliedAttrRenames {
+				for _, rename := range result.AppliedAttributeRenames {
 					fmt.Printf("    %s.*.%s → %s.*.%s\n",
 						rename.ResourceType, rename.OldAttribute,
 						rename.ResourceType, rename.NewAttribute)
 				}
 			}
 
-			if len(appliedComputedMappings) > 0 {
+			if len(result.AppliedComputedMappings) > 0 {
 				fmt.Println("\n  Computed attribute mappings applied:")
-				for _, mapping := range appliedComputedMappings {
+				for _, mapping := range result.AppliedComputedMappings {
 					fmt.Printf("    %s.*.%s → %s.*.%s\n",
 						mapping.OldResourceType, mapping.OldAttribute,
 						mapping.NewResourceType, mapping.NewAttribute)
@@ -1312,219 +1610,16 @@ func applyGlobalPostprocessing(log hclog.Logger, cfg config, outputPaths []strin
 			fmt.Println("✓ No cross-file references needed updating")
 		}
 	}
-	return diags, nil
-}
-
-// scanForInvalidAttributeReferences scans output files for cross-file references
-// to known-invalid attributes and returns a DiagWarning for each match found.
-func scanForInvalidAttributeReferences(log hclog.Logger, outputPaths []string, refs []transform.InvalidAttributeReference) hcl.Diagnostics {
-	var diags hcl.Diagnostics
-
-	for _, outputPath := range outputPaths {
-		content, err := os.ReadFile(outputPath)
-		if err != nil {
-			log.Warn("Failed to read file for invalid attribute scan", "file", outputPath, "error", err)
-			continue
-		}
-		contentStr := string(content)
-
-		for _, ref := range refs {
-			// Pattern: <ResourceType>.<instance_name>.<Attribute>
-			// Instance names may contain letters, digits, underscores, hyphens.
-			pattern := ref.ResourceType + `\.([a-zA-Z0-9_-]+)\.` + ref.Attribute
-			re, err := regexp.Compile(pattern)
-			if err != nil {
-				log.Warn("Failed to compile invalid attribute pattern", "pattern", pattern, "error", err)
-				continue
-			}
-
-			matches := re.FindAllString(contentStr, -1)
-			for _, match := range matches {
-				summary := fmt.Sprintf("Unknown attribute reference: %s", match)
-				detail := fmt.Sprintf("In %s\n\n  %s", filepath.Base(outputPath), ref.Suggestion)
-				log.Debug("Found invalid attribute reference",
-					"file", filepath.Base(outputPath),
-					"match", match)
-				diags = append(diags, &hcl.Diagnostic{
-					Severity: hcl.DiagWarning,
-					Summary:  summary,
-					Detail:   detail,
-				})
-			}
-		}
-	}
-
 	return diags
 }
 
-// collectRemovedRefsByType scans transformed files and returns addresses found in
-// removed { from = <type>.<name> } blocks as a map[type]set(name).
-func collectRemovedRefsByType(outputPaths []string) (map[string]map[string]struct{}, error) {
-	removed := make(map[string]map[string]struct{})
-
-	for _, outputPath := range outputPaths {
-		content, err := os.ReadFile(outputPath)
-		if err != nil {
-			return nil, fmt.Errorf("failed reading %s: %w", outputPath, err)
-		}
-
-		parsed, diags := hclwrite.ParseConfig(content, filepath.Base(outputPath), hcl.InitialPos)
-		if diags.HasErrors() {
-			continue
-		}
-
-		for _, block := range parsed.Body().Blocks() {
-			if block.Type() != "removed" {
-				continue
-			}
-			fromAttr := block.Body().GetAttribute("from")
-			if fromAttr == nil {
-				continue
-			}
-
-			from := extractTraversalString(fromAttr)
-			parts := strings.SplitN(from, ".", 2)
-			if len(parts) != 2 {
-				continue
-			}
-
-			resourceType, resourceName := parts[0], parts[1]
-			if _, ok := removed[resourceType]; !ok {
-				removed[resourceType] = make(map[string]struct{})
-			}
-			removed[resourceType][resourceName] = struct{}{}
-		}
-	}
-
-	return removed, nil
-}
-
-// replaceResourceTypeRefsSkippingMovedBlocks rewrites <oldType>.<name> to
-// <newType>.<name>, except when <name> is in excludedNames. Replacements are
-// skipped inside moved/removed blocks.
-func replaceResourceTypeRefsSkippingMovedBlocks(content, oldType, newType string, excludedNames map[string]struct{}) string {
-	pattern := regexp.MustCompile(regexp.QuoteMeta(oldType) + `\.([a-zA-Z0-9_-]+)`)
-	return regexReplaceFuncSkippingMovedBlocks(content, pattern, func(match string) string {
-		sub := pattern.FindStringSubmatch(match)
-		if len(sub) != 2 {
-			return match
-		}
-		name := sub[1]
-		if excludedNames != nil {
-			if _, skip := excludedNames[name]; skip {
-				return match
-			}
-		}
-		return newType + "." + name
-	})
-}
-
-// findProtectedBlockRanges returns the [start, end) byte ranges of all moved and
-// removed blocks in content. Uses brace counting to correctly handle nested braces
-// (e.g. a removed block containing a lifecycle { } sub-block).
-func findProtectedBlockRanges(content string) [][2]int {
-	var ranges [][2]int
-	i := 0
-	for i < len(content) {
-		// Look for "moved {" or "removed {" at the start of a line (possibly indented)
-		for _, keyword := range []string{"moved", "removed"} {
-			if !strings.HasPrefix(content[i:], keyword) {
-				continue
-			}
-			// Must be followed by optional whitespace then "{"
-			j := i + len(keyword)
-			for j < len(content) && (content[j] == ' ' || content[j] == '\t' || content[j] == '\n' || content[j] == '\r') {
-				j++
-			}
-			if j >= len(content) || content[j] != '{' {
-				continue
-			}
-			// Found a block — count braces to find the end
-			start := i
-			depth := 0
-			k := j
-			for k < len(content) {
-				if content[k] == '{' {
-					depth++
-				} else if content[k] == '}' {
-					depth--
-					if depth == 0 {
-						ranges = append(ranges, [2]int{start, k + 1})
-						i = k + 1
-						goto nextChar
-					}
-				}
-				k++
-			}
-		}
-		i++
-	nextChar:
-	}
-	return ranges
-}
-
-// replaceSkippingMovedBlocks replaces old with new in content, skipping moved and
-// removed blocks. Uses brace counting to correctly handle nested braces.
-func replaceSkippingMovedBlocks(content, old, new string) string {
-	protected := findProtectedBlockRanges(content)
-	if len(protected) == 0 {
-		return strings.ReplaceAll(content, old, new)
-	}
-
-	var result strings.Builder
-	lastEnd := 0
-	for _, r := range protected {
-		result.WriteString(strings.ReplaceAll(content[lastEnd:r[0]], old, new))
-		result.WriteString(content[r[0]:r[1]])
-		lastEnd = r[1]
-	}
-	result.WriteString(strings.ReplaceAll(content[lastEnd:], old, new))
-	return result.String()
-}
-
-// regexReplaceSkippingMovedBlocks replaces regex matches in content, skipping moved
-// and removed blocks. Uses brace counting to correctly handle nested braces.
-func regexReplaceSkippingMovedBlocks(content, pattern, replacement string) string {
-	protected := findProtectedBlockRanges(content)
-	re := regexp.MustCompile(pattern)
-	if len(protected) == 0 {
-		return re.ReplaceAllString(content, replacement)
-	}
-
-	var result strings.Builder
-	lastEnd := 0
-	for _, r := range protected {
-		result.WriteString(re.ReplaceAllString(content[lastEnd:r[0]], replacement))
-		result.WriteString(content[r[0]:r[1]])
-		lastEnd = r[1]
-	}
-	result.WriteString(re.ReplaceAllString(content[lastEnd:], replacement))
-	return result.String()
-}
-
-// regexReplaceFuncSkippingMovedBlocks replaces regex matches in content with a
-// callback, skipping moved and removed blocks.
-func regexReplaceFuncSkippingMovedBlocks(content string, re *regexp.Regexp, replacer func(string) string) string {
-	protected := findProtectedBlockRanges(content)
-	if len(protected) == 0 {
-		return re.ReplaceAllStringFunc(content, replacer)
-	}
-
-	var result strings.Builder
-	lastEnd := 0
-	for _, r := range protected {
-		result.WriteString(re.ReplaceAllStringFunc(content[lastEnd:r[0]], replacer))
-		result.WriteString(content[r[0]:r[1]])
-		lastEnd = r[1]
-	}
-	result.WriteString(re.ReplaceAllStringFunc(content[lastEnd:], replacer))
-	return result.String()
-}
-
 func findTerraformFiles(dir string) ([]string, error) {
 	return findTerraformFilesWithRecursion(dir, false, nil)
 }
 
+// findTerraformFilesWithRecursion returns the configuration files in dir: .tf
+// and .tf.json files, and Terragrunt configuration files. The directories
+// managed by terraform init and Terragrunt are never searched.
 func findTerraformFilesWithRecursion(dir string, recursive bool, exclude []string) ([]string, error) {
 	var files []string
 
@@ -1537,7 +1632,7 @@ func findTerraformFilesWithRecursion(dir string, recursive bool, exclude []strin
 		path := filepath.Join(dir, entry.Name())
 		if entry.IsDir() && recursive {
 			// Check if this directory should be excluded
-			if shouldExclude(entry.Name(), exclude) {
+			if isIgnoredDir(entry.Name()) || shouldExclude(entry.Name(), exclude) {
 				continue
 			}
 			// Recursively search subdirectories
@@ -1547,7 +1642,7 @@ func findTerraformFilesWithRecursion(dir string, recursive bool, exclude []strin
 				continue
 			}
 			files = append(files, subFiles...)
-		} else if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".tf") {
+		} else if !entry.IsDir() && (strings.HasSuffix(entry.Name(), ".tf") || tfhcl.IsJSONConfigFile(entry.Name()) || isTerragruntConfigFile(entry.Name())) {
 			files = append(files, path)
 		}
 	}
@@ -6,196 +6,93 @@ import (
 	"testing"
 
 	"github.com/hashicorp/go-hclog"
-	"github.com/hashicorp/hcl/v2"
 
-	"github.com/cloudflare/tf-migrate/internal/transform"
+	"github.com/cloudflare/tf-migrate/internal/pipeline"
+	"github.com/cloudflare/tf-migrate/internal/schema"
 )
 
 func newTestLogger() hclog.Logger { return hclog.NewNullLogger() }
 
-func TestReplaceResourceTypeRefsSkippingMovedBlocks(t *testing.T) {
-	input := `output "keep_old" {
-  value = cloudflare_access_policy.app_scoped.id
+func TestPostprocessContents(t *testing.T) {
+	cfg := config{sourceVersion: "v4", targetVersion: "v5"}
+	contents := map[string]string{
+		"/config/main.tf": `resource "cloudflare_zero_trust_tunnel_cloudflared" "t" {
+  account_id = "abc"
+  name       = "t"
 }
-
-output "rename" {
-  value = cloudflare_access_policy.account_level.id
-}
-
-removed {
-  from = cloudflare_access_policy.app_scoped
-  lifecycle {
-    destroy = false
-  }
+`,
+		"/config/outputs.tf": `output "record" {
+  value = cloudflare_record.www.hostname
 }
 
-moved {
-  from = cloudflare_access_policy.some_other
-  to   = cloudflare_zero_trust_access_policy.some_other
+output "token" {
+  value = cloudflare_zero_trust_tunnel_cloudflared.t.tunnel_token
 }
-`
-
-	excluded := map[string]struct{}{"app_scoped": {}}
-	got := replaceResourceTypeRefsSkippingMovedBlocks(
-		input,
-		"cloudflare_access_policy",
-		"cloudflare_zero_trust_access_policy",
-		excluded,
-	)
-
-	if !contains(got, "cloudflare_access_policy.app_scoped.id") {
-		t.Fatalf("expected app_scoped reference to remain old type, got:\n%s", got)
+`,
 	}
 
-	if !contains(got, "cloudflare_zero_trust_access_policy.account_level.id") {
-		t.Fatalf("expected account_level reference to be renamed, got:\n%s", got)
-	}
+	diags := postprocessContents(newTestLogger(), cfg, []string{"/config/main.tf", "/config/outputs.tf"}, contents)
 
-	if !contains(got, "from = cloudflare_access_policy.app_scoped") {
-		t.Fatalf("expected removed block from-address to remain unchanged, got:\n%s", got)
+	if !contains(contents["/config/outputs.tf"], "cloudflare_dns_record.www.name") {
+		t.Errorf("expected the record reference to be rewritten, got:\n%s", contents["/config/outputs.tf"])
 	}
-
-	if !contains(got, "from = cloudflare_access_policy.some_other") {
-		t.Fatalf("expected moved block from-address to remain unchanged, got:\n%s", got)
+	if len(diags) == 0 {
+		t.Fatal("expected a warning for the tunnel_token reference")
+	}
+	for _, diag := range diags {
+		if !contains(diag.Summary, "tunnel_token") {
+			t.Errorf("unexpected diagnostic: %s", diag.Summary)
+		}
 	}
 }
 
-func TestCollectRemovedRefsByType(t *testing.T) {
-	tmp := t.TempDir()
-	file := filepath.Join(tmp, "main.tf")
-
-	content := `removed {
-  from = cloudflare_access_policy.app_scoped
-  lifecycle { destroy = false }
-}
-
-removed {
-  from = cloudflare_zone_settings_override.legacy
-  lifecycle { destroy = false }
-}
+func TestProcessConfigFiles_ValidatesSchema(t *testing.T) {
+	s, err := schema.Parse([]byte(`{
+  "format_version": "1.0",
+  "provider_schemas": {
+    "registry.terraform.io/cloudflare/cloudflare": {
+      "provider": {"version": 0, "block": {}},
+      "resource_schemas": {}
+    }
+  }
+}`))
+	if err != nil {
+		t.Fatal(err)
+	}
 
-moved {
-  from = cloudflare_access_policy.other
-  to   = cloudflare_zero_trust_access_policy.other
+	// cloudflare_argo has no references to rewrite, and a dry run without a
+	// diff writes nothing, but the migrated output is still validated
+	dir := t.TempDir()
+	content := `resource "cloudflare_argo" "a" {
+  zone_id       = "0da42c8d2132a9ddaf714f9e7c920711"
+  smart_routing = "on"
 }
 `
-
-	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
-		t.Fatalf("failed writing fixture: %v", err)
+	if err := os.WriteFile(filepath.Join(dir, "main.tf"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
 	}
 
-	refs, err := collectRemovedRefsByType([]string{file})
-	if err != nil {
-		t.Fatalf("collectRemovedRefsByType returned error: %v", err)
+	cfg := config{
+		configDir:          dir,
+		sourceVersion:      "v4",
+		targetVersion:      "v5",
+		dryRun:             true,
+		resourcesToMigrate: []string{"cloudflare_argo"},
+		providerSchema:     s,
 	}
-
-	if _, ok := refs["cloudflare_access_policy"]["app_scoped"]; !ok {
-		t.Fatalf("expected cloudflare_access_policy.app_scoped in removed refs, got: %#v", refs)
-	}
-
-	if _, ok := refs["cloudflare_zone_settings_override"]["legacy"]; !ok {
-		t.Fatalf("expected cloudflare_zone_settings_override.legacy in removed refs, got: %#v", refs)
+	log := newTestLogger()
+	_, diags, err := processConfigFiles(log, pipeline.BuildConfigPipeline(log, getProviders(cfg.resourcesToMigrate...)), cfg)
+	if err != nil {
+		t.Fatal(err)
 	}
 
-	if _, ok := refs["cloudflare_access_policy"]["other"]; ok {
-		t.Fatalf("did not expect moved block address to be collected as removed ref: %#v", refs)
+	found := false
+	for _, diag := range diags {
+		if diag.Summary == "Type not in provider schema: cloudflare_argo_smart_routing" {
+			found = true
+		}
 	}
-}
-
-func TestScanForInvalidAttributeReferences(t *testing.T) {
-	refs := []transform.InvalidAttributeReference{
-		{
-			ResourceType: "cloudflare_zero_trust_tunnel_cloudflared",
-			Attribute:    "tunnel_token",
-			Suggestion:   "tunnel_token is not a valid attribute; use tunnel_secret instead",
-		},
+	if !found {
+		t.Errorf("expected a schema validation warning, got: %v", diags)
 	}
-
-	t.Run("detects_tunnel_token_reference", func(t *testing.T) {
-		tmp := t.TempDir()
-		file := filepath.Join(tmp, "consumers.tf")
-		content := `resource "vault_generic_secret" "token" {
-  data_json = jsonencode({
-    token = cloudflare_zero_trust_tunnel_cloudflared.my_tunnel.tunnel_token
-  })
-}
-`
-		if err := os.WriteFile(file, []byte(content), 0644); err != nil {
-			t.Fatalf("writing fixture: %v", err)
-		}
-
-		diags := scanForInvalidAttributeReferences(newTestLogger(), []string{file}, refs)
-		if len(diags) != 1 {
-			t.Fatalf("expected 1 diagnostic, got %d: %v", len(diags), diags)
-		}
-		if diags[0].Severity != hcl.DiagWarning {
-			t.Errorf("expected DiagWarning, got %v", diags[0].Severity)
-		}
-		if !contains(diags[0].Summary, "tunnel_token") {
-			t.Errorf("expected summary to mention tunnel_token, got: %s", diags[0].Summary)
-		}
-		if !contains(diags[0].Detail, "tunnel_secret") {
-			t.Errorf("expected detail to mention tunnel_secret, got: %s", diags[0].Detail)
-		}
-	})
-
-	t.Run("no_false_positive_for_valid_attribute", func(t *testing.T) {
-		tmp := t.TempDir()
-		file := filepath.Join(tmp, "consumers.tf")
-		// tunnel_secret is valid — must NOT produce a warning
-		content := `resource "vault_generic_secret" "secret" {
-  data_json = jsonencode({
-    secret = cloudflare_zero_trust_tunnel_cloudflared.my_tunnel.tunnel_secret
-  })
-}
-`
-		if err := os.WriteFile(file, []byte(content), 0644); err != nil {
-			t.Fatalf("writing fixture: %v", err)
-		}
-
-		diags := scanForInvalidAttributeReferences(newTestLogger(), []string{file}, refs)
-		if len(diags) != 0 {
-			t.Fatalf("expected no diagnostics for valid attribute, got %d: %v", len(diags), diags)
-		}
-	})
-
-	t.Run("multiple_matches_produce_multiple_warnings", func(t *testing.T) {
-		tmp := t.TempDir()
-		file := filepath.Join(tmp, "consumers.tf")
-		content := `resource "vault_generic_secret" "a" {
-  data_json = jsonencode({
-    a = cloudflare_zero_trust_tunnel_cloudflared.tunnel_a.tunnel_token
-    b = cloudflare_zero_trust_tunnel_cloudflared.tunnel_b.tunnel_token
-  })
-}
-`
-		if err := os.WriteFile(file, []byte(content), 0644); err != nil {
-			t.Fatalf("writing fixture: %v", err)
-		}
-
-		diags := scanForInvalidAttributeReferences(newTestLogger(), []string{file}, refs)
-		if len(diags) != 2 {
-			t.Fatalf("expected 2 diagnostics (one per match), got %d", len(diags))
-		}
-	})
-
-	t.Run("no_match_in_unrelated_resource_type", func(t *testing.T) {
-		tmp := t.TempDir()
-		file := filepath.Join(tmp, "consumers.tf")
-		// Different resource type — must not match
-		content := `resource "vault_generic_secret" "other" {
-  data_json = jsonencode({
-    tok = cloudflare_some_other_resource.foo.tunnel_token
-  })
-}
-`
-		if err := os.WriteFile(file, []byte(content), 0644); err != nil {
-			t.Fatalf("writing fixture: %v", err)
-		}
-
-		diags := scanForInvalidAttributeReferences(newTestLogger(), []string{file}, refs)
-		if len(diags) != 0 {
-			t.Fatalf("expected no diagnostics for different resource type, got %d", len(diags))
-		}
-	})
 }
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+
+	tfhcl "github.com/cloudflare/tf-migrate/internal/transform/hcl"
+)
+
+// defaultMigrationsFile is the file name suggested for --migrations-file.
+const defaultMigrationsFile = "tf_migrate_moves.tf"
+
+// migrationsFileMarker starts the header of every file generated with
+// --migrations-file. `tf-migrate cleanup` only deletes files that begin with it.
+const migrationsFileMarker = "# Generated by tf-migrate"
+
+// migrationsFileHeader returns the header comment written at the top of a new
+// --migrations-file.
+func migrationsFileHeader(now time.Time) string {
+	return fmt.Sprintf(`%s %s on %s.
+#
+# moved, import and removed blocks for the Cloudflare provider migration.
+# Once `+"`terraform apply`"+` has run in every workspace that uses this
+# configuration, delete this file with `+"`tf-migrate cleanup`"+`.
+
+`, migrationsFileMarker, version, now.Format("2006-01-02"))
+}
+
+// isGeneratedMigrationsFile reports whether content was written by
+// --migrations-file.
+func isGeneratedMigrationsFile(content []byte) bool {
+	return bytes.HasPrefix(content, []byte(migrationsFileMarker))
+}
+
+// renderMigrationsFile appends blocks to the existing content of a
+// --migrations-file, skipping any moved/import/removed block that is already
+// present so that re-running a migration does not duplicate them. A new file
+// starts with the generated-file header.
+func renderMigrationsFile(path string, existing []byte, blocks []*hclwrite.Block) ([]byte, error) {
+	if len(bytes.TrimSpace(existing)) == 0 {
+		existing = []byte(migrationsFileHeader(time.Now()))
+	}
+
+	file, diags := hclwrite.ParseConfig(existing, path, hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("failed to parse %s: %s", path, diags.Error())
+	}
+
+	body := file.Body()
+	seen := make(map[string]struct{})
+	for _, block := range body.Blocks() {
+		if key := tfhcl.MigrationBlockKey(block); key != "" {
+			seen[key] = struct{}{}
+		}
+	}
+
+	for _, block := range blocks {
+		if key := tfhcl.MigrationBlockKey(block); key != "" {
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+		}
+		if len(body.Blocks()) > 0 {
+			body.AppendNewline()
+		}
+		body.AppendBlock(block)
+	}
+
+	return hclwrite.Format(file.Bytes()), nil
+}
+
+// nonMigrationBlocks returns a description of every block in content that is
+// not a moved, import or removed block.
+func nonMigrationBlocks(path string, content []byte) ([]string, error) {
+	file, diags := hclwrite.ParseConfig(content, path, hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("failed to parse %s: %s", path, diags.Error())
+	}
+
+	var others []string
+	for _, block := range file.Body().Blocks() {
+		if tfhcl.IsMigrationBlock(block) {
+			continue
+		}
+		others = append(others, strings.Join(append([]string{block.Type()}, block.Labels()...), "."))
+	}
+	if len(file.Body().Attributes()) > 0 {
+		others = append(others, "top-level attributes")
+	}
+	return others, nil
+}
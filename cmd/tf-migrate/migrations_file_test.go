@@ -0,0 +1,100 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudflare/tf-migrate/internal/pipeline"
+	tfhcl "github.com/cloudflare/tf-migrate/internal/transform/hcl"
+)
+
+func TestProcessConfigFiles_MigrationsFile(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	records := `resource "cloudflare_record" "www" {
+  zone_id = "abc123"
+  name    = "www"
+  type    = "A"
+  value   = "192.0.2.1"
+}
+
+output "www_id" {
+  value = cloudflare_record.www.id
+}
+`
+	recordsPath := filepath.Join(tmpDir, "records.tf")
+	require.NoError(t, os.WriteFile(recordsPath, []byte(records), 0644))
+
+	cfg := config{
+		configDir:      tmpDir,
+		sourceVersion:  "v4",
+		targetVersion:  "v5",
+		migrationsFile: "migrations.tf",
+	}
+	log := newTestLogger()
+	p := pipeline.BuildConfigPipeline(log, getProviders())
+
+	_, _, err := processConfigFiles(log, p, cfg)
+	require.NoError(t, err)
+
+	migrated, err := os.ReadFile(recordsPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(migrated), `resource "cloudflare_dns_record" "www"`)
+	assert.NotContains(t, string(migrated), "moved {")
+
+	expectedMigrations := `moved {
+  from = cloudflare_record.www
+  to   = cloudflare_dns_record.www
+}
+`
+	migrations, err := os.ReadFile(filepath.Join(tmpDir, "migrations.tf"))
+	require.NoError(t, err)
+	assert.True(t, isGeneratedMigrationsFile(migrations), "new migrations file starts with the generated-file header")
+	assert.True(t, strings.HasSuffix(string(migrations), "configuration, delete this file with `tf-migrate cleanup`.\n\n"+expectedMigrations))
+
+	// Re-running picks up migrations.tf as an input file and must not
+	// duplicate its blocks.
+	_, _, err = processConfigFiles(log, p, cfg)
+	require.NoError(t, err)
+
+	migrations, err = os.ReadFile(filepath.Join(tmpDir, "migrations.tf"))
+	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(string(migrations), "moved {"))
+}
+
+func TestMigrationsFileHeader(t *testing.T) {
+	header := migrationsFileHeader(time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC))
+	assert.True(t, strings.HasPrefix(header, "# Generated by tf-migrate "+version+" on 2026-03-04.\n"))
+	assert.True(t, isGeneratedMigrationsFile([]byte(header)))
+}
+
+func TestRenderMigrationsFile_KeepsExistingContent(t *testing.T) {
+	existing := `# Hand-written moves
+moved {
+  from = cloudflare_record.a
+  to   = cloudflare_dns_record.a
+}
+`
+	blocks := []*hclwrite.Block{
+		tfhcl.CreateMovedBlock("cloudflare_record.a", "cloudflare_dns_record.a"),
+		tfhcl.CreateMovedBlock("cloudflare_record.b", "cloudflare_dns_record.b"),
+	}
+
+	out, err := renderMigrationsFile("migrations.tf", []byte(existing), blocks)
+	require.NoError(t, err)
+	assert.Equal(t, existing+`
+moved {
+  from = cloudflare_record.b
+  to   = cloudflare_dns_record.b
+}
+`, string(out))
+	// An existing file without the header does not become a generated file
+	assert.False(t, isGeneratedMigrationsFile(out))
+}
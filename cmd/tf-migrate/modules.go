@@ -0,0 +1,346 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+
+	"github.com/cloudflare/tf-migrate/internal/transform"
+	tfhcl "github.com/cloudflare/tf-migrate/internal/transform/hcl"
+)
+
+// moduleCall is a module block whose source is a local path.
+type moduleCall struct {
+	Name   string // label of the module block
+	Source string // source as written, e.g. "../modules/dns"
+	Dir    string // resolved module directory
+	File   string // file containing the module block
+}
+
+// isLocalModuleSource reports whether source is a local path. Terraform only
+// treats sources starting with ./ or ../ as local; anything else is fetched
+// from a registry or remote location, and is not ours to migrate.
+func isLocalModuleSource(source string) bool {
+	return strings.HasPrefix(source, "./") || strings.HasPrefix(source, "../")
+}
+
+// findLocalModuleCalls returns the module blocks with a local source in the
+// given file.
+func findLocalModuleCalls(file string, content []byte) ([]moduleCall, error) {
+	parsed, diags := tfhcl.ParseConfigFile(content, filepath.Base(file))
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("failed to parse %s: %s", file, diags.Error())
+	}
+
+	var calls []moduleCall
+	for _, block := range parsed.Body().Blocks() {
+		if block.Type() != "module" || len(block.Labels()) != 1 {
+			continue
+		}
+		source := tfhcl.ExtractStringFromAttribute(block.Body().GetAttribute("source"))
+		if !isLocalModuleSource(source) {
+			continue
+		}
+		calls = append(calls, moduleCall{
+			Name:   block.Labels()[0],
+			Source: source,
+			Dir:    filepath.Clean(filepath.Join(filepath.Dir(file), source)),
+			File:   file,
+		})
+	}
+	return calls, nil
+}
+
+// moduleGraph is the set of root modules under the config directory and the
+// local modules they call, directly or through other modules.
+type moduleGraph struct {
+	Roots   []string // root module directories
+	Modules []string // called module directories, each listed once
+	Files   []string // configuration files of every root and module, each listed once
+}
+
+// buildModuleGraph resolves the local module sources of the root modules in
+// cfg.configDir. Without --recursive, configDir is the only root; with it,
+// every directory under configDir that holds configuration and is not called
+// as a module by another one is a root. A module shared by several roots is
+// listed once, so it is migrated once.
+func buildModuleGraph(log hclog.Logger, cfg config) (*moduleGraph, error) {
+	candidates, err := findTerraformFilesWithRecursion(cfg.configDir, cfg.recursive, cfg.exclude)
+	if err != nil {
+		return nil, err
+	}
+
+	filesByDir := make(map[string][]string)
+	var dirs []string
+	for _, file := range candidates {
+		dir := filepath.Clean(filepath.Dir(file))
+		if _, ok := filesByDir[dir]; !ok {
+			dirs = append(dirs, dir)
+		}
+		filesByDir[dir] = append(filesByDir[dir], file)
+	}
+	if !cfg.recursive {
+		dirs = []string{filepath.Clean(cfg.configDir)}
+	}
+
+	graph := &moduleGraph{}
+	visited := make(map[string]bool)
+	called := make(map[string]bool)
+
+	// Walk the module calls of every directory, so that a directory under
+	// configDir that is called by another is known to be a module, not a root.
+	var visit func(dir string) error
+	visit = func(dir string) error {
+		if visited[dir] {
+			return nil
+		}
+		visited[dir] = true
+
+		files, ok := filesByDir[dir]
+		if !ok {
+			var err error
+			if files, err = findTerraformFilesWithRecursion(dir, false, nil); err != nil {
+				return err
+			}
+			filesByDir[dir] = files
+		}
+
+		for _, file := range files {
+			content, err := os.ReadFile(file)
+			if err != nil {
+				return fmt.Errorf("failed to read %s: %w", file, err)
+			}
+			calls, err := findLocalModuleCalls(file, content)
+			if err != nil {
+				log.Warn("Failed to parse file for module sources", "file", file, "error", err)
+				continue
+			}
+			for _, call := range calls {
+				if info, err := os.Stat(call.Dir); err != nil || !info.IsDir() {
+					log.Warn("Local module source not found", "module", call.Name, "source", call.Source, "file", file)
+					continue
+				}
+				if !called[call.Dir] {
+					called[call.Dir] = true
+					graph.Modules = append(graph.Modules, call.Dir)
+				}
+				if err := visit(call.Dir); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+
+	for _, dir := range dirs {
+		if err := visit(dir); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, dir := range dirs {
+		if !called[dir] {
+			graph.Roots = append(graph.Roots, dir)
+		}
+	}
+	for _, dir := range append(append([]string{}, graph.Roots...), graph.Modules...) {
+		graph.Files = append(graph.Files, filesByDir[dir]...)
+	}
+
+	log.Debug("Built module graph", "roots", len(graph.Roots), "modules", len(graph.Modules), "files", len(graph.Files))
+	return graph, nil
+}
+
+// findConfigFiles returns the configuration files a command operates on: the
+// files of the module graph with --module-graph, and the files found by
+// walking configDir otherwise.
+func findConfigFiles(log hclog.Logger, cfg config) ([]string, error) {
+	if !cfg.moduleGraph {
+		return findTerraformFilesWithRecursion(cfg.configDir, cfg.recursive, cfg.exclude)
+	}
+	graph, err := buildModuleGraph(log, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return graph.Files, nil
+}
+
+// scanModuleCallSites warns about references to module outputs whose value
+// references an attribute that does not exist in v5. The warning is reported
+// where the output is used, since that is the code that breaks, and names the
+// module output that needs to change. Only modules whose files are among
+// outputPaths are checked.
+func scanModuleCallSites(log hclog.Logger, outputPaths []string, contents map[string]string, refs []transform.InvalidAttributeReference) hcl.Diagnostics {
+	if len(refs) == 0 {
+		return nil
+	}
+
+	filesByDir := make(map[string][]string)
+	for _, path := range outputPaths {
+		if _, ok := contents[path]; ok {
+			dir := filepath.Clean(filepath.Dir(path))
+			filesByDir[dir] = append(filesByDir[dir], path)
+		}
+	}
+
+	// invalidOutputs caches, per module directory, the outputs that reference
+	// an invalid attribute.
+	invalidOutputs := make(map[string]map[string]invalidModuleOutput)
+	outputsOf := func(dir string) map[string]invalidModuleOutput {
+		if outputs, ok := invalidOutputs[dir]; ok {
+			return outputs
+		}
+		outputs := make(map[string]invalidModuleOutput)
+		for _, file := range filesByDir[dir] {
+			for name, invalid := range findInvalidModuleOutputs(file, contents[file], refs) {
+				outputs[name] = invalid
+			}
+		}
+		invalidOutputs[dir] = outputs
+		return outputs
+	}
+
+	// Module blocks and the references to their outputs are usually in
+	// different files of the calling module, so calls are indexed by directory.
+	callsByDir := make(map[string]map[string]moduleCall)
+	for _, path := range outputPaths {
+		content, ok := contents[path]
+		if !ok {
+			continue
+		}
+		calls, err := findLocalModuleCalls(path, []byte(content))
+		if err != nil {
+			continue
+		}
+		dir := filepath.Clean(filepath.Dir(path))
+		for _, call := range calls {
+			if callsByDir[dir] == nil {
+				callsByDir[dir] = make(map[string]moduleCall)
+			}
+			callsByDir[dir][call.Name] = call
+		}
+	}
+
+	var diags hcl.Diagnostics
+	for _, path := range outputPaths {
+		content, ok := contents[path]
+		if !ok {
+			continue
+		}
+		callsByName := callsByDir[filepath.Clean(filepath.Dir(path))]
+		if len(callsByName) == 0 {
+			continue
+		}
+
+		native, err := tfhcl.NativeSyntax([]byte(content), filepath.Base(path))
+		if err != nil {
+			continue
+		}
+		found, parseDiags := tfhcl.FindModuleOutputReferences(native, filepath.Base(path))
+		if parseDiags.HasErrors() {
+			continue
+		}
+
+		for _, ref := range found {
+			call, ok := callsByName[ref.Module]
+			if !ok || ref.Output == "" {
+				continue
+			}
+			invalid, ok := outputsOf(call.Dir)[ref.Output]
+			if !ok {
+				continue
+			}
+
+			match := "module." + ref.Module + "." + ref.Output
+			log.Debug("Found module output backed by an invalid attribute",
+				"file", filepath.Base(path), "match", match, "reference", invalid.Reference)
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagWarning,
+				Summary:  fmt.Sprintf("Module output references an unknown attribute: %s", match),
+				Detail: fmt.Sprintf("In %s (line %d)\n\nOutput %q of module %q (%s) is %s in %s.\n\n  %s",
+					filepath.Base(path), ref.Range.Start.Line, ref.Output, ref.Module, call.Source,
+					invalid.Reference, filepath.Base(invalid.File), invalid.Suggestion),
+			})
+		}
+	}
+
+	return diags
+}
+
+// invalidModuleOutput is a module output whose value references an attribute
+// that does not exist in v5.
+type invalidModuleOutput struct {
+	File       string
+	Reference  string // e.g. cloudflare_zero_trust_tunnel_cloudflared.t.tunnel_token
+	Suggestion string
+}
+
+// findInvalidModuleOutputs returns the outputs declared in a module file whose
+// value references one of refs, by output name.
+func findInvalidModuleOutputs(file, content string, refs []transform.InvalidAttributeReference) map[string]invalidModuleOutput {
+	native, err := tfhcl.NativeSyntax([]byte(content), filepath.Base(file))
+	if err != nil {
+		return nil
+	}
+	parsed, diags := hclsyntax.ParseConfig(native, filepath.Base(file), hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil
+	}
+	body, ok := parsed.Body.(*hclsyntax.Body)
+	if !ok {
+		return nil
+	}
+
+	references, diags := tfhcl.FindReferences(native, filepath.Base(file))
+	if diags.HasErrors() {
+		return nil
+	}
+
+	outputs := make(map[string]invalidModuleOutput)
+	for _, block := range body.Blocks {
+		if block.Type != "output" || len(block.Labels) != 1 {
+			continue
+		}
+		value, ok := block.Body.Attributes["value"]
+		if !ok {
+			continue
+		}
+		rng := value.Expr.Range()
+
+		for _, found := range references {
+			if found.TypeRange.Start.Byte < rng.Start.Byte || found.TypeRange.End.Byte > rng.End.Byte {
+				continue
+			}
+			for _, ref := range refs {
+				if found.Type != ref.ResourceType || found.Attribute != ref.Attribute {
+					continue
+				}
+				outputs[block.Labels[0]] = invalidModuleOutput{
+					File:       file,
+					Reference:  found.Type + "." + found.Name + "." + found.Attribute,
+					Suggestion: ref.Suggestion,
+				}
+			}
+		}
+	}
+	return outputs
+}
+
+// printModuleGraph prints the roots and modules found with --module-graph.
+func printModuleGraph(graph *moduleGraph, cfg config) {
+	fmt.Printf("Module graph: %d root module(s), %d local module(s)\n", len(graph.Roots), len(graph.Modules))
+	modules := append([]string{}, graph.Modules...)
+	sort.Strings(modules)
+	for _, dir := range modules {
+		if rel, err := filepath.Rel(cfg.configDir, dir); err == nil {
+			dir = filepath.ToSlash(rel)
+		}
+		fmt.Printf("  module %s\n", dir)
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudflare/tf-migrate/internal/transform"
+)
+
+func writeModuleFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+}
+
+func TestBuildModuleGraph(t *testing.T) {
+	t.Run("shared module is listed once", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		writeModuleFiles(t, tmpDir, map[string]string{
+			"prod/main.tf":                "module \"dns\" {\n  source = \"../modules/dns\"\n}\n",
+			"staging/main.tf":             "module \"dns\" {\n  source = \"../modules/dns/\"\n}\n",
+			"modules/dns/main.tf":         "module \"records\" {\n  source = \"./records\"\n}\n",
+			"modules/dns/vars.tf":         "variable \"zone_id\" {}\n",
+			"modules/dns/records/main.tf": "resource \"cloudflare_record\" \"a\" {}\n",
+			"remote/main.tf":              "module \"vpc\" {\n  source = \"terraform-aws-modules/vpc/aws\"\n}\n",
+		})
+
+		graph, err := buildModuleGraph(newTestLogger(), config{configDir: tmpDir, recursive: true})
+		require.NoError(t, err)
+
+		assert.ElementsMatch(t, []string{
+			filepath.Join(tmpDir, "prod"),
+			filepath.Join(tmpDir, "remote"),
+			filepath.Join(tmpDir, "staging"),
+		}, graph.Roots)
+		assert.ElementsMatch(t, []string{
+			filepath.Join(tmpDir, "modules", "dns"),
+			filepath.Join(tmpDir, "modules", "dns", "records"),
+		}, graph.Modules)
+		assert.ElementsMatch(t, []string{
+			filepath.Join(tmpDir, "prod", "main.tf"),
+			filepath.Join(tmpDir, "staging", "main.tf"),
+			filepath.Join(tmpDir, "remote", "main.tf"),
+			filepath.Join(tmpDir, "modules", "dns", "main.tf"),
+			filepath.Join(tmpDir, "modules", "dns", "vars.tf"),
+			filepath.Join(tmpDir, "modules", "dns", "records", "main.tf"),
+		}, graph.Files)
+	})
+
+	t.Run("follows sources outside the config dir", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		writeModuleFiles(t, tmpDir, map[string]string{
+			"live/main.tf":        "module \"dns\" {\n  source = \"../modules/dns\"\n}\n\nmodule \"gone\" {\n  source = \"./missing\"\n}\n",
+			"modules/dns/main.tf": "resource \"cloudflare_record\" \"a\" {}\n",
+		})
+
+		graph, err := buildModuleGraph(newTestLogger(), config{configDir: filepath.Join(tmpDir, "live")})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{filepath.Join(tmpDir, "live")}, graph.Roots)
+		assert.Equal(t, []string{filepath.Join(tmpDir, "modules", "dns")}, graph.Modules)
+		assert.Equal(t, []string{
+			filepath.Join(tmpDir, "live", "main.tf"),
+			filepath.Join(tmpDir, "modules", "dns", "main.tf"),
+		}, graph.Files)
+	})
+}
+
+func TestScanModuleCallSites(t *testing.T) {
+	refs := []transform.InvalidAttributeReference{{
+		ResourceType: "cloudflare_zero_trust_tunnel_cloudflared",
+		Attribute:    "tunnel_token",
+		Suggestion:   "Use the cloudflare_zero_trust_tunnel_cloudflared_token data source instead.",
+	}}
+
+	contents := map[string]string{
+		"/repo/live/main.tf": `module "tunnel" {
+  source = "../modules/tunnel"
+}
+`,
+		"/repo/live/outputs.tf": `output "token" {
+  value     = module.tunnel.token
+  sensitive = true
+}
+
+output "id" {
+  value = module.tunnel.id
+}
+`,
+		"/repo/modules/tunnel/main.tf": `resource "cloudflare_zero_trust_tunnel_cloudflared" "t" {
+  account_id = var.account_id
+  name       = "t"
+}
+`,
+		"/repo/modules/tunnel/outputs.tf": `output "token" {
+  value = cloudflare_zero_trust_tunnel_cloudflared.t.tunnel_token
+}
+
+output "id" {
+  value = cloudflare_zero_trust_tunnel_cloudflared.t.id
+}
+`,
+	}
+	outputPaths := []string{
+		"/repo/live/main.tf",
+		"/repo/live/outputs.tf",
+		"/repo/modules/tunnel/main.tf",
+		"/repo/modules/tunnel/outputs.tf",
+	}
+
+	diags := scanModuleCallSites(newTestLogger(), outputPaths, contents, refs)
+	require.Len(t, diags, 1)
+	assert.Equal(t, "Module output references an unknown attribute: module.tunnel.token", diags[0].Summary)
+	assert.Contains(t, diags[0].Detail, "In outputs.tf (line 2)")
+	assert.Contains(t, diags[0].Detail, `Output "token" of module "tunnel" (../modules/tunnel) is cloudflare_zero_trust_tunnel_cloudflared.t.tunnel_token in outputs.tf.`)
+	assert.Contains(t, diags[0].Detail, refs[0].Suggestion)
+
+	assert.Empty(t, scanModuleCallSites(newTestLogger(), outputPaths, contents, nil))
+}
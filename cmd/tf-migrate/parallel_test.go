@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudflare/tf-migrate/internal/pipeline"
+)
+
+// writeSyntheticWorkspace writes a workspace of modules directories with
+// files configuration files each. Every file declares a handful of resources
+// that are renamed or restructured by the migration, and references the
+// resources of the previous file, so that cross-file rewrites have work to do.
+func writeSyntheticWorkspace(tb testing.TB, dir string, modules, files int) {
+	tb.Helper()
+	for m := 0; m < modules; m++ {
+		moduleDir := filepath.Join(dir, fmt.Sprintf("module_%03d", m))
+		require.NoError(tb, os.MkdirAll(moduleDir, 0755))
+		for f := 0; f < files; f++ {
+			var b strings.Builder
+			fmt.Fprintf(&b, `resource "cloudflare_record" "r%[1]d" {
+  zone_id = var.zone_id
+  name    = "host-%[1]d"
+  type    = "A"
+  value   = "192.0.2.%[1]d"
+  proxied = true
+}
+
+resource "cloudflare_worker_domain" "d%[1]d" {
+  account_id = var.account_id
+  zone_id    = var.zone_id
+  hostname   = "app-%[1]d.example.com"
+  service    = "app"
+}
+
+resource "cloudflare_load_balancer_pool" "p%[1]d" {
+  account_id = var.account_id
+  name       = "pool-%[1]d"
+
+  origins {
+    name    = "origin-a"
+    address = "192.0.2.1"
+  }
+
+  origins {
+    name    = "origin-b"
+    address = "192.0.2.2"
+  }
+}
+`, f)
+			if f > 0 {
+				fmt.Fprintf(&b, `
+output "previous_%[1]d" {
+  value = {
+    host   = cloudflare_record.r%[1]d.hostname
+    record = cloudflare_record.r%[1]d.id
+    domain = cloudflare_worker_domain.d%[1]d.id
+  }
+}
+`, f-1)
+			}
+			path := filepath.Join(moduleDir, fmt.Sprintf("file_%03d.tf", f))
+			require.NoError(tb, os.WriteFile(path, []byte(b.String()), 0644))
+		}
+	}
+}
+
+func TestProcessConfigFiles_ParallelMatchesSequential(t *testing.T) {
+	run := func(parallelism int) (map[string]string, []string) {
+		dir := t.TempDir()
+		writeSyntheticWorkspace(t, dir, 3, 8)
+
+		cfg := config{
+			configDir:     dir,
+			sourceVersion: "v4",
+			targetVersion: "v5",
+			recursive:     true,
+			parallelism:   parallelism,
+		}
+		log := newTestLogger()
+		p := pipeline.BuildConfigPipeline(log, getProviders())
+		_, diags, err := processConfigFiles(log, p, cfg)
+		require.NoError(t, err)
+
+		files := make(map[string]string)
+		require.NoError(t, filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() {
+				return err
+			}
+			content, err := os.ReadFile(path)
+			rel, _ := filepath.Rel(dir, path)
+			files[rel] = string(content)
+			return err
+		}))
+		var summaries []string
+		for _, d := range diags {
+			summaries = append(summaries, d.Summary)
+		}
+		return files, summaries
+	}
+
+	sequentialFiles, sequentialDiags := run(1)
+	parallelFiles, parallelDiags := run(8)
+
+	assert.Equal(t, sequentialFiles, parallelFiles)
+	assert.Equal(t, sequentialDiags, parallelDiags)
+	assert.Contains(t, parallelFiles[filepath.Join("module_001", "file_003.tf")], "cloudflare_dns_record.r2.name")
+}
+
+// BenchmarkProcessConfigFiles migrates a synthetic workspace of 2,000 files
+// in place, sequentially and on the default worker pool. Run it with
+//
+//	go test -run '^$' -bench ProcessConfigFiles ./cmd/tf-migrate
+func BenchmarkProcessConfigFiles(b *testing.B) {
+	template := b.TempDir()
+	writeSyntheticWorkspace(b, template, 40, 50)
+
+	for _, bench := range []struct {
+		name        string
+		parallelism int
+	}{
+		{"sequential", 1},
+		{"parallel", 0},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			log := newTestLogger()
+			p := pipeline.BuildConfigPipeline(log, getProviders())
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				dir := b.TempDir()
+				require.NoError(b, os.CopyFS(dir, os.DirFS(template)))
+				cfg := config{
+					configDir:     dir,
+					sourceVersion: "v4",
+					targetVersion: "v5",
+					recursive:     true,
+					parallelism:   bench.parallelism,
+				}
+				b.StartTimer()
+
+				if _, _, err := processConfigFiles(log, p, cfg); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -7,367 +7,70 @@ import (
 	"strings"
 
 	"github.com/hashicorp/go-hclog"
-	"github.com/hashicorp/hcl/v2"
-	"github.com/hashicorp/hcl/v2/hclwrite"
 
-	"github.com/cloudflare/tf-migrate/internal/transform"
+	"github.com/cloudflare/tf-migrate/internal/preflight"
 	tfhcl "github.com/cloudflare/tf-migrate/internal/transform/hcl"
 )
 
-// resourceClassification describes how a resource will be handled during migration.
-type resourceClassification int
-
-const (
-	// classAutoMigrated means the resource has a transformer and will be fully auto-migrated.
-	classAutoMigrated resourceClassification = iota
-	// classRenamed means the resource will be renamed and a moved block generated.
-	classRenamed
-	// classManualIntervention means the resource has a transformer but requires manual steps.
-	classManualIntervention
-	// classUnsupported means no transformer is registered for this resource type.
-	classUnsupported
-)
-
-// scannedResource holds information about a resource found during pre-migration scanning.
-type scannedResource struct {
-	File         string
-	ResourceType string
-	ResourceName string
-	Class        resourceClassification
-	OldType      string // only set for classRenamed
-	NewType      string // only set for classRenamed
-	Detail       string // human-readable detail for manual intervention / unsupported
-}
-
-// existingMovedBlock represents a hand-written moved block found in the user's config.
-type existingMovedBlock struct {
-	File     string
-	FromType string
-	FromName string
-	ToType   string
-	ToName   string
-}
-
-// preflightReport is the result of a pre-migration scan.
-type preflightReport struct {
-	Resources   []scannedResource
-	MovedBlocks []existingMovedBlock
-	Warnings    []string
-}
-
 // runPreMigrationScan scans all .tf files and classifies resources and detects
 // existing moved blocks. It prints a summary and returns warnings for any issues.
-func runPreMigrationScan(log hclog.Logger, cfg config) (*preflightReport, error) {
-	files, err := findTerraformFilesWithRecursion(cfg.configDir, cfg.recursive, cfg.exclude)
+func runPreMigrationScan(log hclog.Logger, cfg config) (*preflight.Report, error) {
+	files, err := findConfigFiles(log, cfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find terraform files: %w", err)
 	}
 
-	providers := getProviders(cfg.resourcesToMigrate...)
-	report := &preflightReport{}
-
-	// Build rename map from all migrators (same logic as applyGlobalPostprocessing)
-	migrators := providers.GetAllMigrators(cfg.sourceVersion, cfg.targetVersion, cfg.resourcesToMigrate...)
-	renames := make(map[string]string) // old type -> new type
-	for _, migrator := range migrators {
-		if renamer, ok := migrator.(transform.ResourceRenamer); ok {
-			oldTypes, newType := renamer.GetResourceRename()
-			for _, oldType := range oldTypes {
-				if oldType != newType {
-					renames[oldType] = newType
-				}
-			}
-		}
-	}
-
+	// Files are reported by their path relative to the config directory.
+	paths := make([]string, 0, len(files))
+	contents := make(map[string][]byte, len(files))
 	for _, file := range files {
 		content, err := os.ReadFile(file)
 		if err != nil {
 			log.Warn("Failed to read file during pre-migration scan", "file", file, "error", err)
 			continue
 		}
-
-		parsed, diags := hclwrite.ParseConfig(content, filepath.Base(file), hcl.InitialPos)
-		if diags.HasErrors() {
-			log.Warn("Failed to parse file during pre-migration scan", "file", file, "error", diags)
-			continue
-		}
-
-		relFile := filepath.Base(file)
-
-		for _, block := range parsed.Body().Blocks() {
-			switch block.Type() {
-			case "resource":
-				if len(block.Labels()) < 2 {
-					continue
-				}
-				sr := classifyResource(block, relFile, providers, renames, cfg)
-				if sr != nil {
-					report.Resources = append(report.Resources, *sr)
-				}
-
-			case "moved":
-				mb := parseMovedBlock(block, relFile)
-				if mb != nil {
-					report.MovedBlocks = append(report.MovedBlocks, *mb)
-				}
-			}
-		}
-	}
-
-	// Check for conflicting moved blocks
-	report.Warnings = detectMovedBlockConflicts(report, renames)
-
-	return report, nil
-}
-
-// classifyResource determines how a resource will be handled during migration.
-func classifyResource(block *hclwrite.Block, file string, providers transform.MigrationProvider, renames map[string]string, cfg config) *scannedResource {
-	resourceType := block.Labels()[0]
-	resourceName := block.Labels()[1]
-
-	// Only scan cloudflare resources
-	if !strings.HasPrefix(resourceType, "cloudflare_") {
-		return nil
-	}
-
-	migrator := providers.GetMigrator(resourceType, cfg.sourceVersion, cfg.targetVersion)
-	if migrator == nil {
-		return &scannedResource{
-			File:         file,
-			ResourceType: resourceType,
-			ResourceName: resourceName,
-			Class:        classUnsupported,
-			Detail:       "no transformer registered -- manual migration required",
-		}
-	}
-
-	// cloudflare_access_policy resources with application_id are a special manual
-	// path in v4->v5: they become removed {} blocks and must be rewritten inline
-	// on cloudflare_zero_trust_access_application.policies.
-	if cfg.sourceVersion == "v4" && cfg.targetVersion == "v5" &&
-		resourceType == "cloudflare_access_policy" && block.Body().GetAttribute("application_id") != nil {
-		return &scannedResource{
-			File:         file,
-			ResourceType: resourceType,
-			ResourceName: resourceName,
-			Class:        classManualIntervention,
-			Detail:       "application_id detected -- tf-migrate will generate removed {}; migrate policy inline to cloudflare_zero_trust_access_application.policies (do not use moved block)",
-		}
-	}
-
-	// Check if this resource will be renamed
-	if newType, ok := renames[resourceType]; ok {
-		// Conditional renames: some v4 types map to one of two v5 types
-		// depending on block attributes. Override the static rename target
-		// with the per-instance target based on attribute inspection.
-		newType = resolveConditionalRename(resourceType, newType, block)
-
-		return &scannedResource{
-			File:         file,
-			ResourceType: resourceType,
-			ResourceName: resourceName,
-			Class:        classRenamed,
-			OldType:      resourceType,
-			NewType:      newType,
-		}
-	}
-
-	// Resource has a transformer but no rename -- config-only changes
-	return &scannedResource{
-		File:         file,
-		ResourceType: resourceType,
-		ResourceName: resourceName,
-		Class:        classAutoMigrated,
-	}
-}
-
-// resolveConditionalRename overrides the static rename target for resource types
-// where the v5 target depends on per-instance block attributes.
-//
-// Currently handles:
-//   - cloudflare_zero_trust_device_profiles / cloudflare_device_settings_policy:
-//     Routes to cloudflare_zero_trust_device_custom_profile when the block has
-//     match + precedence and is not explicitly default=true.
-//   - cloudflare_zero_trust_local_fallback_domain / cloudflare_fallback_domain:
-//     Routes to cloudflare_zero_trust_device_custom_profile_local_domain_fallback
-//     when the block has a non-empty policy_id.
-func resolveConditionalRename(resourceType, staticNewType string, block *hclwrite.Block) string {
-	body := block.Body()
-
-	switch resourceType {
-	case "cloudflare_zero_trust_device_profiles", "cloudflare_device_settings_policy":
-		// Mirror the routing logic from zero_trust_device_profiles TransformConfig:
-		// custom if !default && match && precedence, else default.
-		isExplicitDefault := false
-		if attr := body.GetAttribute("default"); attr != nil {
-			val, ok := tfhcl.ExtractBoolFromAttribute(attr)
-			if ok {
-				isExplicitDefault = val
-			}
-		}
-		hasMatch := body.GetAttribute("match") != nil
-		hasPrecedence := body.GetAttribute("precedence") != nil
-
-		if !isExplicitDefault && hasMatch && hasPrecedence {
-			return "cloudflare_zero_trust_device_custom_profile"
-		}
-
-	case "cloudflare_zero_trust_local_fallback_domain", "cloudflare_fallback_domain":
-		// Mirror the routing logic from zero_trust_local_fallback_domain TransformConfig:
-		// custom if policy_id is present and non-empty, else default.
-		if attr := body.GetAttribute("policy_id"); attr != nil {
-			val := tfhcl.ExtractStringFromAttribute(attr)
-			if val != "" || tfhcl.IsExpressionAttribute(attr) {
-				return "cloudflare_zero_trust_device_custom_profile_local_domain_fallback"
-			}
-		}
+		path := scanPath(cfg, file)
+		paths = append(paths, path)
+		contents[path] = content
 	}
 
-	return staticNewType
+	return preflight.Scan(log, preflight.Options{
+		Providers:     getProviders(cfg.resourcesToMigrate...),
+		SourceVersion: cfg.sourceVersion,
+		TargetVersion: cfg.targetVersion,
+		Resources:     cfg.resourcesToMigrate,
+	}, paths, contents), nil
 }
 
-// parseMovedBlock extracts from/to information from a moved block.
-func parseMovedBlock(block *hclwrite.Block, file string) *existingMovedBlock {
-	body := block.Body()
-
-	fromAttr := body.GetAttribute("from")
-	toAttr := body.GetAttribute("to")
-	if fromAttr == nil || toAttr == nil {
-		return nil
-	}
-
-	fromStr := extractTraversalString(fromAttr)
-	toStr := extractTraversalString(toAttr)
-	if fromStr == "" || toStr == "" {
-		return nil
-	}
-
-	fromParts := strings.SplitN(fromStr, ".", 2)
-	toParts := strings.SplitN(toStr, ".", 2)
-
-	// Only care about cloudflare resources
-	if !strings.HasPrefix(fromStr, "cloudflare_") && !strings.HasPrefix(toStr, "cloudflare_") {
-		return nil
+// scanPath returns the path the pre-migration scan reports file by: its path
+// relative to the config directory, or file itself when it lies outside.
+func scanPath(cfg config, file string) string {
+	rel, err := filepath.Rel(cfg.configDir, file)
+	if err != nil || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return file
 	}
-
-	mb := &existingMovedBlock{
-		File: file,
-	}
-	if len(fromParts) == 2 {
-		mb.FromType = fromParts[0]
-		mb.FromName = fromParts[1]
-	}
-	if len(toParts) == 2 {
-		mb.ToType = toParts[0]
-		mb.ToName = toParts[1]
-	}
-
-	return mb
-}
-
-// extractTraversalString extracts the string representation of a traversal attribute
-// (e.g., "cloudflare_access_application.admin_api" from `from = cloudflare_access_application.admin_api`).
-func extractTraversalString(attr *hclwrite.Attribute) string {
-	tokens := attr.Expr().BuildTokens(nil)
-	var parts []string
-	for _, tok := range tokens {
-		s := strings.TrimSpace(string(tok.Bytes))
-		if s != "" {
-			parts = append(parts, s)
-		}
-	}
-	return strings.Join(parts, "")
-}
-
-// detectMovedBlockConflicts checks for hand-written moved blocks that may conflict
-// with what tf-migrate would generate.
-func detectMovedBlockConflicts(report *preflightReport, renames map[string]string) []string {
-	var warnings []string
-
-	manualAddrs := make(map[string]struct{})
-	for _, r := range report.Resources {
-		if r.Class == classManualIntervention {
-			manualAddrs[r.ResourceType+"."+r.ResourceName] = struct{}{}
-		}
-	}
-
-	for _, mb := range report.MovedBlocks {
-		addr := mb.FromType + "." + mb.FromName
-
-		if _, isManual := manualAddrs[addr]; isManual {
-			warnings = append(warnings, fmt.Sprintf(
-				"Conflicting moved block in %s: %s → %s.%s\n"+
-					"  This resource requires manual migration (application_id path).\n"+
-					"  Do not use a moved block; keep the generated removed {} block and migrate inline policies manually.",
-				mb.File, addr, mb.ToType, mb.ToName))
-			continue
-		}
-
-		// Check if the from-type is a v4 name that tf-migrate would rename
-		if newType, ok := renames[mb.FromType]; ok {
-			if mb.ToType == newType {
-				// User's moved block matches what tf-migrate would generate -- duplicate
-				warnings = append(warnings, fmt.Sprintf(
-					"Pre-existing moved block in %s: %s → %s.%s\n"+
-						"  tf-migrate will generate this moved block automatically.\n"+
-						"  Consider removing it to avoid duplicates.",
-					mb.File, addr, mb.ToType, mb.ToName))
-			} else {
-				// User's moved block targets a different type than expected
-				warnings = append(warnings, fmt.Sprintf(
-					"Conflicting moved block in %s: %s → %s.%s\n"+
-						"  tf-migrate would rename %s to %s, not %s.\n"+
-						"  Please remove or correct this moved block before proceeding.",
-					mb.File, addr, mb.ToType, mb.ToName,
-					mb.FromType, newType, mb.ToType))
-			}
-		}
-
-		// Check if from-type doesn't exist in v5 provider at all and has no transformer
-		if _, ok := renames[mb.FromType]; !ok {
-			// Not a known rename -- check if it's even a cloudflare type
-			if strings.HasPrefix(mb.FromType, "cloudflare_") {
-				// Check if this type has any migrator registered
-				found := false
-				for _, r := range report.Resources {
-					if r.ResourceType == mb.FromType {
-						found = true
-						break
-					}
-				}
-				if !found {
-					// The moved block references a type that has no resources in config
-					// This could be a stale moved block or a cross-module reference
-					warnings = append(warnings, fmt.Sprintf(
-						"Moved block in %s references %s which has no matching resource in config.\n"+
-							"  Verify this moved block is still needed.",
-						mb.File, addr))
-				}
-			}
-		}
-	}
-
-	return warnings
+	return rel
 }
 
 // printPreflightReport prints the pre-migration scan results to stdout.
-func printPreflightReport(report *preflightReport, cfg config) {
+func printPreflightReport(report *preflight.Report, cfg config) {
 	if len(report.Resources) == 0 && len(report.MovedBlocks) == 0 {
 		return
 	}
 
-	var renamed, autoMigrated, manual, unsupported []scannedResource
+	var renamed, autoMigrated, manual, unsupported, ignored []preflight.Resource
 	for _, r := range report.Resources {
 		switch r.Class {
-		case classRenamed:
+		case preflight.ClassRenamed:
 			renamed = append(renamed, r)
-		case classAutoMigrated:
+		case preflight.ClassAutoMigrated:
 			autoMigrated = append(autoMigrated, r)
-		case classManualIntervention:
+		case preflight.ClassManualIntervention:
 			manual = append(manual, r)
-		case classUnsupported:
+		case preflight.ClassUnsupported:
 			unsupported = append(unsupported, r)
+		case preflight.ClassIgnored:
+			ignored = append(ignored, r)
 		}
 	}
 
@@ -423,6 +126,14 @@ func printPreflightReport(report *preflightReport, cfg config) {
 			fmt.Println()
 		}
 
+		if len(ignored) > 0 {
+			fmt.Printf("  Ignored resources -- left as they are (%d):\n", len(ignored))
+			for _, r := range ignored {
+				fmt.Printf("    %s.%s: %s\n", r.ResourceType, r.ResourceName, r.Detail)
+			}
+			fmt.Println()
+		}
+
 		if len(report.MovedBlocks) > 0 {
 			fmt.Printf("  Existing moved blocks found (%d):\n", len(report.MovedBlocks))
 			for _, mb := range report.MovedBlocks {
@@ -433,6 +144,9 @@ func printPreflightReport(report *preflightReport, cfg config) {
 	} else {
 		// Minimal output in non-verbose mode
 		fmt.Printf("  Migrated %d resources (%d renamed, %d config-only)\n",
-			len(report.Resources), len(renamed), len(autoMigrated))
+			len(report.Resources)-len(ignored), len(renamed), len(autoMigrated))
+		if len(ignored) > 0 {
+			fmt.Printf("  Ignored %d resources annotated with # %s\n", len(ignored), tfhcl.IgnoreDirective)
+		}
 	}
 }
@@ -7,6 +7,7 @@ import (
 
 	"github.com/hashicorp/go-hclog"
 
+	"github.com/cloudflare/tf-migrate/internal/preflight"
 	"github.com/cloudflare/tf-migrate/internal/registry"
 )
 
@@ -78,8 +79,8 @@ resource "aws_s3_bucket" "logs" {
 	for _, r := range report.Resources {
 		if r.ResourceType == "cloudflare_access_application" {
 			found = true
-			if r.Class != classRenamed {
-				t.Errorf("Expected access_application to be classRenamed, got %d", r.Class)
+			if r.Class != preflight.ClassRenamed {
+				t.Errorf("Expected access_application to be ClassRenamed, got %d", r.Class)
 			}
 			if r.NewType != "cloudflare_zero_trust_access_application" {
 				t.Errorf("Expected new type cloudflare_zero_trust_access_application, got %s", r.NewType)
@@ -193,8 +194,8 @@ resource "cloudflare_access_policy" "app_scoped" {
 	}
 
 	got := report.Resources[0]
-	if got.Class != classManualIntervention {
-		t.Fatalf("Expected classManualIntervention, got %d", got.Class)
+	if got.Class != preflight.ClassManualIntervention {
+		t.Fatalf("Expected ClassManualIntervention, got %d", got.Class)
 	}
 
 	if !contains(got.Detail, "application_id") {
@@ -206,124 +207,6 @@ resource "cloudflare_access_policy" "app_scoped" {
 	}
 }
 
-func TestDetectMovedBlockConflicts_DuplicateMovedBlock(t *testing.T) {
-	renames := map[string]string{
-		"cloudflare_access_application": "cloudflare_zero_trust_access_application",
-	}
-
-	report := &preflightReport{
-		MovedBlocks: []existingMovedBlock{
-			{
-				File:     "moved.tf",
-				FromType: "cloudflare_access_application",
-				FromName: "admin_api",
-				ToType:   "cloudflare_zero_trust_access_application",
-				ToName:   "admin_api",
-			},
-		},
-	}
-
-	warnings := detectMovedBlockConflicts(report, renames)
-	if len(warnings) != 1 {
-		t.Fatalf("Expected 1 warning, got %d: %v", len(warnings), warnings)
-	}
-	if !contains(warnings[0], "tf-migrate will generate this moved block automatically") {
-		t.Errorf("Unexpected warning: %s", warnings[0])
-	}
-}
-
-func TestDetectMovedBlockConflicts_ConflictingTarget(t *testing.T) {
-	renames := map[string]string{
-		"cloudflare_access_application": "cloudflare_zero_trust_access_application",
-	}
-
-	report := &preflightReport{
-		MovedBlocks: []existingMovedBlock{
-			{
-				File:     "moved.tf",
-				FromType: "cloudflare_access_application",
-				FromName: "admin_api",
-				ToType:   "cloudflare_wrong_type",
-				ToName:   "admin_api",
-			},
-		},
-	}
-
-	warnings := detectMovedBlockConflicts(report, renames)
-	if len(warnings) != 1 {
-		t.Fatalf("Expected 1 warning, got %d: %v", len(warnings), warnings)
-	}
-	if !contains(warnings[0], "Conflicting moved block") {
-		t.Errorf("Expected conflicting moved block warning, got: %s", warnings[0])
-	}
-}
-
-func TestDetectMovedBlockConflicts_NoConflict(t *testing.T) {
-	renames := map[string]string{
-		"cloudflare_access_application": "cloudflare_zero_trust_access_application",
-	}
-
-	// A moved block for a non-renamed resource (no conflict)
-	report := &preflightReport{
-		MovedBlocks: []existingMovedBlock{
-			{
-				File:     "moved.tf",
-				FromType: "cloudflare_custom_resource",
-				FromName: "example",
-				ToType:   "cloudflare_custom_resource_v2",
-				ToName:   "example",
-			},
-		},
-		Resources: []scannedResource{
-			{
-				ResourceType: "cloudflare_custom_resource",
-				ResourceName: "example",
-			},
-		},
-	}
-
-	warnings := detectMovedBlockConflicts(report, renames)
-	if len(warnings) != 0 {
-		t.Errorf("Expected no warnings, got %d: %v", len(warnings), warnings)
-	}
-}
-
-func TestDetectMovedBlockConflicts_ManualResourceMovedBlock(t *testing.T) {
-	renames := map[string]string{
-		"cloudflare_access_policy": "cloudflare_zero_trust_access_policy",
-	}
-
-	report := &preflightReport{
-		Resources: []scannedResource{
-			{
-				ResourceType: "cloudflare_access_policy",
-				ResourceName: "app_scoped",
-				Class:        classManualIntervention,
-			},
-		},
-		MovedBlocks: []existingMovedBlock{
-			{
-				File:     "moved.tf",
-				FromType: "cloudflare_access_policy",
-				FromName: "app_scoped",
-				ToType:   "cloudflare_zero_trust_access_policy",
-				ToName:   "app_scoped",
-			},
-		},
-	}
-
-	warnings := detectMovedBlockConflicts(report, renames)
-	if len(warnings) != 1 {
-		t.Fatalf("Expected 1 warning, got %d: %v", len(warnings), warnings)
-	}
-	if !contains(warnings[0], "requires manual migration") {
-		t.Fatalf("Expected manual migration warning, got: %s", warnings[0])
-	}
-	if !contains(warnings[0], "Do not use a moved block") {
-		t.Fatalf("Expected moved-block guidance warning, got: %s", warnings[0])
-	}
-}
-
 func TestClassifyResource_UnsupportedResource(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -353,8 +236,8 @@ resource "cloudflare_nonexistent_resource" "test" {
 	if len(report.Resources) != 1 {
 		t.Fatalf("Expected 1 resource, got %d", len(report.Resources))
 	}
-	if report.Resources[0].Class != classUnsupported {
-		t.Errorf("Expected classUnsupported, got %d", report.Resources[0].Class)
+	if report.Resources[0].Class != preflight.ClassUnsupported {
+		t.Errorf("Expected ClassUnsupported, got %d", report.Resources[0].Class)
 	}
 }
 
@@ -381,6 +264,63 @@ func TestRunPreMigrationScan_EmptyDirectory(t *testing.T) {
 	}
 }
 
+func TestRunPreMigrationScan_ClassifiesIgnoredResources(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"main.tf": `# tf-migrate:ignore migrated by hand
+resource "cloudflare_list" "allowed" {
+  account_id = "abc123"
+  name       = "allowed"
+  kind       = "ip"
+}
+
+resource "cloudflare_record" "www" {
+  zone_id = "abc123"
+  name    = "www"
+  type    = "A"
+  value   = "192.0.2.1"
+}
+`,
+		"legacy.tf": `# tf-migrate:ignore-file
+resource "cloudflare_record" "api" {
+  zone_id = "abc123"
+  name    = "api"
+  type    = "A"
+  value   = "192.0.2.2"
+}
+`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	report, err := runPreMigrationScan(hclog.NewNullLogger(), config{
+		configDir:     tmpDir,
+		sourceVersion: "v4",
+		targetVersion: "v5",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	classes := make(map[string]preflight.Class)
+	for _, r := range report.Resources {
+		classes[r.ResourceType+"."+r.ResourceName] = r.Class
+	}
+	want := map[string]preflight.Class{
+		"cloudflare_list.allowed": preflight.ClassIgnored,
+		"cloudflare_record.api":   preflight.ClassIgnored,
+		"cloudflare_record.www":   preflight.ClassRenamed,
+	}
+	for addr, class := range want {
+		if classes[addr] != class {
+			t.Errorf("%s: expected class %v, got %v", addr, class, classes[addr])
+		}
+	}
+}
+
 // TestPreflightScan_ConditionalRenames verifies that the pre-migration scan
 // correctly distinguishes default vs custom device profiles and fallback domains.
 // Regression test for https://github.com/cloudflare/tf-migrate/issues/290.
@@ -442,7 +382,7 @@ resource "cloudflare_fallback_domain" "custom_fallback" {
 	// Build a map of resource name → NewType for renamed resources
 	renames := make(map[string]string)
 	for _, r := range report.Resources {
-		if r.Class == classRenamed {
+		if r.Class == preflight.ClassRenamed {
 			renames[r.ResourceName] = r.NewType
 		}
 	}
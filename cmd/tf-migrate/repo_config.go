@@ -0,0 +1,281 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	tfhcl "github.com/cloudflare/tf-migrate/internal/transform/hcl"
+)
+
+// repoConfigName is the name of the repository config file, looked up in
+// --config-dir and its parent directories.
+const repoConfigName = ".tf-migrate.yaml"
+
+// repoConfig is a checked-in .tf-migrate.yaml. Its settings are defaults for
+// the flags of the same name: a flag given on the command line wins.
+//
+//	recursive: true
+//	exclude: [sandbox]
+//	resources: [cloudflare_record, cloudflare_zone]
+//	target_provider_version: 5.8.2
+//	skip_version_check: true
+//	drift_exemptions: [ops/drift-exemptions.yaml]
+//	directories:
+//	  envs/legacy:
+//	    skip_resources: [cloudflare_list]
+//	  envs/prod:
+//	    target_provider_version: 5.7.0
+//
+// Paths are relative to the directory of the file. Exclude is the exception:
+// like --exclude, it holds directory names or patterns, matched against the
+// name of every directory walked, at any depth.
+type repoConfig struct {
+	Recursive             *bool    `yaml:"recursive,omitempty"`
+	Exclude               []string `yaml:"exclude,omitempty"`
+	Resources             []string `yaml:"resources,omitempty"`
+	TargetProviderVersion string   `yaml:"target_provider_version,omitempty"`
+	SkipVersionCheck      *bool    `yaml:"skip_version_check,omitempty"`
+	// DriftExemptions are drift exemption files that verify-drift applies
+	// in addition to the built-in exemptions.
+	DriftExemptions []string `yaml:"drift_exemptions,omitempty"`
+	// Directories overrides settings for the files under a directory. When
+	// several entries match a file, skip_resources accumulate and the
+	// deepest target_provider_version wins.
+	Directories map[string]*directoryConfig `yaml:"directories,omitempty"`
+
+	// path is the file the config was read from.
+	path string
+}
+
+// directoryConfig holds the settings of repoConfig.Directories.
+type directoryConfig struct {
+	// SkipResources are resource types, with the data. prefix for data
+	// sources, that are left unmigrated in the directory.
+	SkipResources         []string `yaml:"skip_resources,omitempty"`
+	TargetProviderVersion string   `yaml:"target_provider_version,omitempty"`
+}
+
+// loadRepoConfig reads the repository config file at path.
+func loadRepoConfig(path string) (*repoConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	return parseRepoConfig(data, path)
+}
+
+// parseRepoConfig parses a repository config file. Unknown keys are errors,
+// so that a misspelt key is not silently ignored.
+func parseRepoConfig(data []byte, path string) (*repoConfig, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+
+	rc := &repoConfig{}
+	if err := dec.Decode(rc); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	rc.path = abs
+
+	dirs := make(map[string]*directoryConfig, len(rc.Directories))
+	for dir, dc := range rc.Directories {
+		if dc == nil {
+			dc = &directoryConfig{}
+		}
+		clean := filepath.Clean(filepath.FromSlash(dir))
+		if filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+			return nil, fmt.Errorf("%s: directory %q must be inside the directory of the config file", path, dir)
+		}
+		if _, ok := dirs[clean]; ok {
+			return nil, fmt.Errorf("%s: directory %q is configured more than once", path, dir)
+		}
+		dirs[clean] = dc
+	}
+	rc.Directories = dirs
+
+	for _, name := range rc.Exclude {
+		if strings.ContainsAny(name, `/\`) {
+			return nil, fmt.Errorf("%s: exclude %q must be a directory name or pattern, not a path", path, name)
+		}
+	}
+
+	for i, file := range rc.DriftExemptions {
+		rc.DriftExemptions[i] = rc.resolve(file)
+	}
+	return rc, nil
+}
+
+// findRepoConfig returns the path of the repository config file that applies
+// to dir: the first one found in dir or its parents, stopping at the root of
+// a git repository. It returns "" when there is none.
+func findRepoConfig(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for {
+		path := filepath.Join(dir, repoConfigName)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return "", nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// resolve returns path, relative to the directory of the config file, as an
+// absolute path.
+func (rc *repoConfig) resolve(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(filepath.Dir(rc.path), filepath.FromSlash(path))
+}
+
+// directoriesFor returns the directory entries that contain file, from the
+// shallowest to the deepest.
+func (rc *repoConfig) directoriesFor(file string) []*directoryConfig {
+	if rc == nil || len(rc.Directories) == 0 {
+		return nil
+	}
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return nil
+	}
+	rel, err := filepath.Rel(filepath.Dir(rc.path), filepath.Dir(abs))
+	if err != nil {
+		return nil
+	}
+
+	var matched []string
+	for dir := range rc.Directories {
+		if dir == "." || rel == dir || strings.HasPrefix(rel, dir+string(filepath.Separator)) {
+			matched = append(matched, dir)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool { return len(matched[i]) < len(matched[j]) })
+
+	dcs := make([]*directoryConfig, len(matched))
+	for i, dir := range matched {
+		dcs[i] = rc.Directories[dir]
+	}
+	return dcs
+}
+
+// skipTypesFor returns the resource types left unmigrated in file, or nil.
+func (rc *repoConfig) skipTypesFor(file string) map[string]bool {
+	var skip map[string]bool
+	for _, dc := range rc.directoriesFor(file) {
+		for _, t := range dc.SkipResources {
+			if skip == nil {
+				skip = make(map[string]bool)
+			}
+			skip[t] = true
+		}
+	}
+	return skip
+}
+
+// keepReference reports whether the reference ref in the file at path is to a
+// resource type left unmigrated in that file's directory, so that cross-file
+// rewrites leave it alone.
+func (rc *repoConfig) keepReference(path string, ref tfhcl.Reference) bool {
+	return rc.skipTypesFor(path)[ref.Type]
+}
+
+// providerVersionFor returns the provider version pinned for the directory of
+// file, or "".
+func (rc *repoConfig) providerVersionFor(file string) string {
+	version := ""
+	for _, dc := range rc.directoriesFor(file) {
+		if dc.TargetProviderVersion != "" {
+			version = dc.TargetProviderVersion
+		}
+	}
+	return version
+}
+
+// inputPath returns the configuration file that was migrated to outputPath.
+func inputPath(cfg config, outputPath string) string {
+	if cfg.outputDir == "" || cfg.outputDir == cfg.configDir {
+		return outputPath
+	}
+	rel, err := filepath.Rel(cfg.outputDir, outputPath)
+	if err != nil {
+		return outputPath
+	}
+	return filepath.Join(cfg.configDir, rel)
+}
+
+// applyRepoConfig finds the repository config file of cfg.configDir, or reads
+// the one given with --config-file, and applies its settings to the flags of
+// cmd that were not given on the command line.
+func applyRepoConfig(cmd *cobra.Command, cfg *config) error {
+	path := cfg.repoConfigFile
+	if path == "" {
+		dir := cfg.configDir
+		if dir == "" {
+			dir = "."
+		}
+		found, err := findRepoConfig(dir)
+		if err != nil {
+			return err
+		}
+		if found == "" {
+			return nil
+		}
+		path = found
+	}
+
+	rc, err := loadRepoConfig(path)
+	if err != nil {
+		return err
+	}
+	cfg.repoConfig = rc
+
+	// unset reports whether cmd has the flag and it was not given.
+	unset := func(name string) bool {
+		f := cmd.Flags().Lookup(name)
+		return f != nil && !f.Changed
+	}
+	if rc.Recursive != nil && unset("recursive") {
+		cfg.recursive = *rc.Recursive
+	}
+	if rc.Exclude != nil && unset("exclude") {
+		cfg.exclude = rc.Exclude
+	}
+	if rc.Resources != nil && unset("resources") {
+		cfg.resourcesToMigrate = rc.Resources
+	}
+	if rc.TargetProviderVersion != "" && unset("target-provider-version") {
+		cfg.targetProviderVersion = rc.TargetProviderVersion
+	}
+	if rc.SkipVersionCheck != nil && unset("skip-version-check") {
+		cfg.skipVersionCheck = *rc.SkipVersionCheck
+	}
+	if rc.DriftExemptions != nil && unset("exemptions-file") {
+		cfg.driftExemptionFiles = rc.DriftExemptions
+	}
+	if cfg.verbose {
+		fmt.Printf("Config file: %s\n", path)
+	}
+	return nil
+}
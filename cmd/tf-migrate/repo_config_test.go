@@ -0,0 +1,145 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudflare/tf-migrate/internal/pipeline"
+)
+
+func TestParseRepoConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, repoConfigName)
+
+	rc, err := parseRepoConfig([]byte(`
+recursive: true
+exclude: [sandbox]
+drift_exemptions: [ops/drift.yaml]
+directories:
+  envs:
+    skip_resources: [cloudflare_list]
+    target_provider_version: 5.7.0
+  envs/legacy/:
+    skip_resources: [data.cloudflare_zones]
+  envs/prod:
+    target_provider_version: 5.8.2
+`), path)
+	require.NoError(t, err)
+	assert.True(t, *rc.Recursive)
+	assert.Equal(t, []string{"sandbox"}, rc.Exclude)
+	assert.Equal(t, []string{filepath.Join(dir, "ops", "drift.yaml")}, rc.DriftExemptions)
+
+	legacy := filepath.Join(dir, "envs", "legacy", "main.tf")
+	assert.Equal(t, map[string]bool{"cloudflare_list": true, "data.cloudflare_zones": true}, rc.skipTypesFor(legacy))
+	assert.Equal(t, "5.7.0", rc.providerVersionFor(legacy))
+	assert.Equal(t, "5.8.2", rc.providerVersionFor(filepath.Join(dir, "envs", "prod", "net", "main.tf")))
+	assert.Nil(t, rc.skipTypesFor(filepath.Join(dir, "main.tf")))
+	assert.Empty(t, rc.providerVersionFor(filepath.Join(dir, "envs-old", "main.tf")))
+
+	var none *repoConfig
+	assert.Nil(t, none.skipTypesFor(legacy))
+	assert.Empty(t, none.providerVersionFor(legacy))
+
+	t.Run("errors", func(t *testing.T) {
+		for name, content := range map[string]string{
+			"unknown key":      "recursve: true\n",
+			"outside the repo": "directories:\n  ../other:\n    skip_resources: [cloudflare_list]\n",
+			"duplicate":        "directories:\n  envs:\n    skip_resources: [a]\n  envs/:\n    skip_resources: [b]\n",
+			"exclude path":     "exclude: [envs/sandbox]\n",
+		} {
+			_, err := parseRepoConfig([]byte(content), path)
+			assert.Error(t, err, name)
+		}
+	})
+}
+
+func TestFindRepoConfig(t *testing.T) {
+	repo := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(repo, ".git"), 0755))
+	module := filepath.Join(repo, "envs", "prod")
+	require.NoError(t, os.MkdirAll(module, 0755))
+
+	found, err := findRepoConfig(module)
+	require.NoError(t, err)
+	assert.Empty(t, found, "the search stops at the root of the repository")
+
+	path := filepath.Join(repo, repoConfigName)
+	require.NoError(t, os.WriteFile(path, nil, 0644))
+	found, err = findRepoConfig(module)
+	require.NoError(t, err)
+	assert.Equal(t, path, found)
+}
+
+func TestApplyRepoConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), repoConfigName)
+	require.NoError(t, os.WriteFile(path, []byte(`
+recursive: true
+exclude: [sandbox]
+target_provider_version: 5.8.2
+`), 0644))
+
+	cfg := &config{repoConfigFile: path}
+	cmd := &cobra.Command{Use: "migrate", RunE: func(*cobra.Command, []string) error { return nil }}
+	cmd.Flags().BoolVar(&cfg.recursive, "recursive", false, "")
+	cmd.Flags().StringSliceVar(&cfg.exclude, "exclude", []string{}, "")
+	cmd.Flags().StringVar(&cfg.targetProviderVersion, "target-provider-version", "", "")
+	require.NoError(t, cmd.ParseFlags([]string{"--target-provider-version", "5.9.0"}))
+
+	require.NoError(t, applyRepoConfig(cmd, cfg))
+	assert.True(t, cfg.recursive)
+	assert.Equal(t, []string{"sandbox"}, cfg.exclude)
+	assert.Equal(t, "5.9.0", cfg.targetProviderVersion, "a flag given on the command line wins")
+	require.NotNil(t, cfg.repoConfig)
+}
+
+func TestProcessConfigFiles_SkipResources(t *testing.T) {
+	configDir := t.TempDir()
+	legacy := filepath.Join(configDir, "legacy")
+	require.NoError(t, os.Mkdir(legacy, 0755))
+	record := `resource "cloudflare_record" "r" {
+  zone_id = "0da42c8d2132a9ddaf714f9e7c920711"
+  name    = "www"
+  type    = "A"
+  value   = "192.0.2.1"
+}
+
+output "host" {
+  value = cloudflare_record.r.hostname
+}
+`
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "main.tf"), []byte(record), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(legacy, "main.tf"), []byte(record), 0644))
+
+	rc, err := parseRepoConfig([]byte(`
+directories:
+  legacy:
+    skip_resources: [cloudflare_record]
+`), filepath.Join(configDir, repoConfigName))
+	require.NoError(t, err)
+
+	cfg := config{
+		configDir:     configDir,
+		outputDir:     configDir,
+		sourceVersion: "v4",
+		targetVersion: "v5",
+		recursive:     true,
+		repoConfig:    rc,
+	}
+	log := newTestLogger()
+	_, _, err = processConfigFiles(log, pipeline.BuildConfigPipeline(log, getProviders()), cfg)
+	require.NoError(t, err)
+
+	migrated, err := os.ReadFile(filepath.Join(configDir, "main.tf"))
+	require.NoError(t, err)
+	assert.Contains(t, string(migrated), `resource "cloudflare_dns_record" "r"`)
+	assert.Contains(t, string(migrated), "cloudflare_dns_record.r.name")
+
+	skipped, err := os.ReadFile(filepath.Join(legacy, "main.tf"))
+	require.NoError(t, err)
+	assert.Equal(t, record, string(skipped))
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/spf13/cobra"
+)
+
+// restoreResult is the outcome of `tf-migrate restore`.
+type restoreResult struct {
+	Restored []string          // files put back from their .backup (or that would be)
+	Removed  []string          // generated migrations files that were (or would be) deleted
+	Skipped  map[string]string // files left as they are -> reason
+}
+
+func newRestoreCommand(log hclog.Logger, cfg *config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "restore",
+		Short: "Undo a migration by putting back the files saved in .backup files",
+		Long: `Puts every file that ` + "`tf-migrate migrate`" + ` backed up back from its .backup file,
+and deletes the backup. Files generated with --migrations-file in the same
+directories are deleted, as they only hold blocks for the migrated resources.
+
+A file that was edited after it was migrated is left as it is, so that no
+work is lost; pass --force to restore it anyway.`,
+		Example: `  # Undo the migration of the current directory
+  tf-migrate restore
+
+  # Preview what would be restored in a module tree
+  tf-migrate --config-dir ./terraform --dry-run restore --recursive`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			applyConfigDefaults(cfg)
+			cmd.SilenceUsage = true
+
+			result, err := runRestore(log, *cfg)
+			if err != nil {
+				return err
+			}
+			printRestoreReport(result, *cfg)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&cfg.recursive, "recursive", false, "Recursively process subdirectories (useful for module structures)")
+	cmd.Flags().StringSliceVar(&cfg.exclude, "exclude", []string{}, "Directories to exclude from restore (relative to config-dir, can be specified multiple times)")
+	cmd.Flags().BoolVar(&cfg.force, "force", false, "Restore files even if they were edited since they were migrated")
+
+	return cmd
+}
+
+// runRestore restores the files backed up under cfg.configDir, unless
+// cfg.dryRun is set.
+func runRestore(log hclog.Logger, cfg config) (*restoreResult, error) {
+	backups, err := findBackupFiles(cfg.configDir, cfg.recursive, cfg.exclude)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list backup files: %w", err)
+	}
+
+	result := &restoreResult{Skipped: make(map[string]string)}
+	manifests := make(map[string]*backupManifest)
+	var dirs []string
+	for _, backup := range backups {
+		dir := filepath.Dir(backup)
+		m, ok := manifests[dir]
+		if !ok {
+			if m, err = readBackupManifest(dir); err != nil {
+				return nil, err
+			}
+			manifests[dir] = m
+			dirs = append(dirs, dir)
+		}
+
+		file := backup[:len(backup)-len(backupSuffix)]
+		original, err := os.ReadFile(backup)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", backup, err)
+		}
+		current, err := os.ReadFile(file)
+		switch {
+		case os.IsNotExist(err):
+		case err != nil:
+			return nil, fmt.Errorf("failed to read %s: %w", file, err)
+		case bytes.Equal(current, original):
+		default:
+			if reason := m.editedSinceMigration(file, current); reason != "" && !cfg.force {
+				result.Skipped[file] = reason + "; use --force to restore it"
+				continue
+			}
+		}
+
+		if !cfg.dryRun {
+			if err := os.WriteFile(file, original, 0644); err != nil {
+				return nil, fmt.Errorf("failed to restore %s: %w", file, err)
+			}
+			if err := os.Remove(backup); err != nil {
+				return nil, fmt.Errorf("failed to remove %s: %w", backup, err)
+			}
+			log.Debug("Restored file", "file", file)
+		}
+		delete(m.Files, filepath.Base(file))
+		result.Restored = append(result.Restored, file)
+	}
+
+	restoredDirs := make(map[string]bool)
+	for _, file := range result.Restored {
+		restoredDirs[filepath.Dir(file)] = true
+	}
+	for _, dir := range dirs {
+		if !cfg.dryRun {
+			if err := manifests[dir].write(dir); err != nil {
+				return nil, err
+			}
+		}
+		if !restoredDirs[dir] {
+			continue
+		}
+		removed, err := removeGeneratedMigrationsFiles(log, dir, cfg.dryRun)
+		if err != nil {
+			return nil, err
+		}
+		result.Removed = append(result.Removed, removed...)
+	}
+
+	return result, nil
+}
+
+// removeGeneratedMigrationsFiles deletes the files of dir written by
+// --migrations-file that hold nothing but moved, import and removed blocks.
+func removeGeneratedMigrationsFiles(log hclog.Logger, dir string, dryRun bool) ([]string, error) {
+	files, err := findTerraformFilesWithRecursion(dir, false, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list .tf files: %w", err)
+	}
+	var removed []string
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		if !isGeneratedMigrationsFile(content) {
+			continue
+		}
+		if others, err := nonMigrationBlocks(file, content); err != nil || len(others) > 0 {
+			continue
+		}
+		if !dryRun {
+			if err := os.Remove(file); err != nil {
+				return nil, fmt.Errorf("failed to remove %s: %w", file, err)
+			}
+			log.Debug("Removed generated migrations file", "file", file)
+		}
+		removed = append(removed, file)
+	}
+	return removed, nil
+}
+
+// printRestoreReport prints the files restored and kept by restore.
+func printRestoreReport(result *restoreResult, cfg config) {
+	restored, removed := "Restored", "Removed"
+	if cfg.dryRun {
+		restored, removed = "Would restore", "Would remove"
+	}
+
+	if len(result.Restored) == 0 && len(result.Skipped) == 0 {
+		fmt.Println("No .backup files found.")
+		return
+	}
+
+	for _, file := range result.Restored {
+		fmt.Printf("✓ %s %s\n", restored, diffDisplayName(cfg.configDir, file))
+	}
+	for _, file := range result.Removed {
+		fmt.Printf("✓ %s %s\n", removed, diffDisplayName(cfg.configDir, file))
+	}
+	skipped := make([]string, 0, len(result.Skipped))
+	for file := range result.Skipped {
+		skipped = append(skipped, file)
+	}
+	sort.Strings(skipped)
+	for _, file := range skipped {
+		fmt.Printf("⚠ Kept %s: %s\n", diffDisplayName(cfg.configDir, file), result.Skipped[file])
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudflare/tf-migrate/internal/pipeline"
+)
+
+func TestRunRestore(t *testing.T) {
+	files := map[string]string{
+		"record.tf": `resource "cloudflare_record" "www" {
+  zone_id = "0da42c8d2132a9ddaf714f9e7c920711"
+  name    = "www"
+  type    = "A"
+  value   = "192.0.2.1"
+}
+`,
+		filepath.Join("modules", "dns", "main.tf"): `resource "cloudflare_record" "api" {
+  zone_id = "0da42c8d2132a9ddaf714f9e7c920711"
+  name    = "api"
+  type    = "A"
+  value   = "192.0.2.2"
+}
+`,
+	}
+	setup := func(t *testing.T) string {
+		dir := t.TempDir()
+		for name, content := range files {
+			require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755))
+			require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+		}
+		cfg := config{configDir: dir, sourceVersion: "v4", targetVersion: "v5", backup: true, recursive: true, migrationsFile: defaultMigrationsFile}
+		log := newTestLogger()
+		_, _, err := processConfigFiles(log, pipeline.BuildConfigPipeline(log, getProviders()), cfg)
+		require.NoError(t, err)
+		require.FileExists(t, filepath.Join(dir, defaultMigrationsFile))
+		return dir
+	}
+	assertRestored := func(t *testing.T, dir, name string) {
+		t.Helper()
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		require.NoError(t, err)
+		assert.Equal(t, files[name], string(content))
+		assert.NoFileExists(t, filepath.Join(dir, name+backupSuffix))
+	}
+
+	t.Run("restores every backed-up file", func(t *testing.T) {
+		dir := setup(t)
+		result, err := runRestore(newTestLogger(), config{configDir: dir, recursive: true})
+		require.NoError(t, err)
+
+		assert.Len(t, result.Restored, 2)
+		assertRestored(t, dir, "record.tf")
+		assertRestored(t, dir, filepath.Join("modules", "dns", "main.tf"))
+		assert.NoFileExists(t, filepath.Join(dir, defaultMigrationsFile))
+		assert.NoFileExists(t, filepath.Join(dir, backupManifestName))
+	})
+
+	t.Run("limited to a directory", func(t *testing.T) {
+		dir := setup(t)
+		_, err := runRestore(newTestLogger(), config{configDir: filepath.Join(dir, "modules", "dns")})
+		require.NoError(t, err)
+
+		assertRestored(t, dir, filepath.Join("modules", "dns", "main.tf"))
+		assert.FileExists(t, filepath.Join(dir, "record.tf"+backupSuffix))
+	})
+
+	t.Run("dry run changes nothing", func(t *testing.T) {
+		dir := setup(t)
+		result, err := runRestore(newTestLogger(), config{configDir: dir, dryRun: true})
+		require.NoError(t, err)
+		assert.Len(t, result.Restored, 1)
+		assert.FileExists(t, filepath.Join(dir, "record.tf"+backupSuffix))
+	})
+
+	t.Run("keeps edited files unless forced", func(t *testing.T) {
+		dir := setup(t)
+		file := filepath.Join(dir, "record.tf")
+		require.NoError(t, os.WriteFile(file, []byte("# edited\n"), 0644))
+
+		result, err := runRestore(newTestLogger(), config{configDir: dir})
+		require.NoError(t, err)
+		assert.Contains(t, result.Skipped[file], "edited since it was migrated")
+		assert.Empty(t, result.Removed)
+		content, err := os.ReadFile(file)
+		require.NoError(t, err)
+		assert.Equal(t, "# edited\n", string(content))
+
+		_, err = runRestore(newTestLogger(), config{configDir: dir, force: true})
+		require.NoError(t, err)
+		assertRestored(t, dir, "record.tf")
+	})
+}
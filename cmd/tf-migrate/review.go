@@ -0,0 +1,239 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+
+	"github.com/cloudflare/tf-migrate/internal/transform"
+)
+
+// defaultReviewWidth is the terminal width assumed when $COLUMNS is not set.
+const defaultReviewWidth = 120
+
+// reviewer asks, for each transformed block of a --review migration, whether
+// to accept, reject or edit the transformation.
+type reviewer struct {
+	in    *bufio.Reader
+	out   io.Writer
+	width int
+	// edit opens path in the user's editor and returns when it is closed.
+	edit func(path string) error
+
+	mu        sync.Mutex
+	acceptAll bool
+	accepted  int
+	rejected  int
+	edited    int
+}
+
+// newReviewer returns a reviewer that prompts on out and reads answers from
+// in, sized to the terminal width given by $COLUMNS.
+func newReviewer(in io.Reader, out io.Writer) *reviewer {
+	width := defaultReviewWidth
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		width = columns
+	}
+	return &reviewer{
+		in:    bufio.NewReader(in),
+		out:   out,
+		width: width,
+		edit:  runEditor,
+	}
+}
+
+// review shows the original block, its transformation and the diagnostics
+// reported for it, and asks what to write. It is used as
+// transform.Context.Review.
+func (r *reviewer) review(b *transform.BlockReview) (transform.ReviewDecision, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.acceptAll {
+		r.accepted++
+		return transform.ReviewDecision{Action: transform.ReviewAccept}, nil
+	}
+
+	migrated := renderBlocks(b.Result.Blocks)
+	if !b.Result.RemoveOriginal {
+		// An in-place transformation changes the block itself, whatever
+		// else it returns.
+		migrated = renderBlocks(b.Result.Blocks[:min(1, len(b.Result.Blocks))])
+	}
+	r.show(b, migrated)
+
+	for {
+		fmt.Fprint(r.out, "Apply this migration? [a]ccept, [r]eject, [e]dit, accept [A]ll remaining: ")
+		line, err := r.in.ReadString('\n')
+		if err != nil && line == "" {
+			return transform.ReviewDecision{}, fmt.Errorf("no answer to review prompt: %w", err)
+		}
+
+		switch strings.TrimSpace(line) {
+		case "a", "accept", "y", "yes":
+			r.accepted++
+			return transform.ReviewDecision{Action: transform.ReviewAccept}, nil
+		case "A":
+			r.acceptAll = true
+			r.accepted++
+			return transform.ReviewDecision{Action: transform.ReviewAccept}, nil
+		case "r", "reject", "n", "no":
+			r.rejected++
+			return transform.ReviewDecision{Action: transform.ReviewReject}, nil
+		case "e", "edit":
+			blocks, err := r.editBlocks(migrated)
+			if err != nil {
+				fmt.Fprintf(r.out, "  %s\n", err)
+				continue
+			}
+			r.edited++
+			return transform.ReviewDecision{Action: transform.ReviewEdit, Blocks: blocks}, nil
+		}
+	}
+}
+
+// show prints the original and migrated text of b side by side, or one above
+// the other when they do not fit the terminal, followed by its diagnostics.
+func (r *reviewer) show(b *transform.BlockReview, migrated string) {
+	fmt.Fprintln(r.out)
+	fmt.Fprintf(r.out, "── %s: %s ──\n", b.Filename, b.Address)
+
+	original := strings.Split(strings.TrimRight(renderBlocks([]*hclwrite.Block{b.Original}), "\n"), "\n")
+	result := strings.Split(strings.TrimRight(migrated, "\n"), "\n")
+	if migrated == "" {
+		result = []string{"(removed)"}
+	}
+
+	column := (r.width - 3) / 2
+	fits := true
+	for _, line := range append(append([]string(nil), original...), result...) {
+		if len([]rune(line)) > column {
+			fits = false
+			break
+		}
+	}
+
+	if fits {
+		fmt.Fprintf(r.out, "%-*s │ %s\n", column, "v4", "v5")
+		for i := 0; i < max(len(original), len(result)); i++ {
+			var left, right string
+			if i < len(original) {
+				left = original[i]
+			}
+			if i < len(result) {
+				right = result[i]
+			}
+			fmt.Fprintln(r.out, strings.TrimRight(fmt.Sprintf("%-*s │ %s", column, left, right), " "))
+		}
+	} else {
+		fmt.Fprintln(r.out, "v4:")
+		for _, line := range original {
+			fmt.Fprintf(r.out, "  %s\n", line)
+		}
+		fmt.Fprintln(r.out, "v5:")
+		for _, line := range result {
+			fmt.Fprintf(r.out, "  %s\n", line)
+		}
+	}
+
+	if len(b.Diagnostics) > 0 {
+		fmt.Fprintln(r.out, "Diagnostics:")
+		for _, d := range b.Diagnostics {
+			severity := "info"
+			switch d.Severity {
+			case hcl.DiagError:
+				severity = "error"
+			case hcl.DiagWarning:
+				severity = "warning"
+			}
+			fmt.Fprintf(r.out, "  %s: %s\n", severity, d.Summary)
+			if d.Detail != "" {
+				fmt.Fprintf(r.out, "    %s\n", d.Detail)
+			}
+		}
+	}
+	fmt.Fprintln(r.out)
+}
+
+// editBlocks opens migrated in the editor and returns the blocks it holds
+// once the editor is closed.
+func (r *reviewer) editBlocks(migrated string) ([]*hclwrite.Block, error) {
+	f, err := os.CreateTemp("", "tf-migrate-review-*.tf")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file to edit: %w", err)
+	}
+	path := f.Name()
+	defer os.Remove(path)
+	_, err = f.WriteString(migrated)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to write file to edit: %w", err)
+	}
+
+	if err := r.edit(path); err != nil {
+		return nil, fmt.Errorf("editor failed: %w", err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read edited file: %w", err)
+	}
+	file, diags := hclwrite.ParseConfig(content, "edited.tf", hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("edited configuration is invalid: %s", diags.Error())
+	}
+	if len(file.Body().Attributes()) > 0 {
+		return nil, fmt.Errorf("edited configuration must contain only blocks")
+	}
+	return file.Body().Blocks(), nil
+}
+
+// summary describes the decisions taken so far.
+func (r *reviewer) summary() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return fmt.Sprintf("Review: %d accepted, %d rejected, %d edited", r.accepted, r.rejected, r.edited)
+}
+
+// renderBlocks returns the text of blocks separated by blank lines.
+func renderBlocks(blocks []*hclwrite.Block) string {
+	var tokens hclwrite.Tokens
+	for i, block := range blocks {
+		if i > 0 {
+			tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenNewline, Bytes: []byte("\n")})
+		}
+		tokens = append(tokens, block.BuildTokens(nil)...)
+	}
+	// Writing the tokens through a File applies the spacing that generated
+	// tokens lack.
+	file := hclwrite.NewEmptyFile()
+	file.Body().AppendUnstructuredTokens(tokens)
+	return string(file.Bytes())
+}
+
+// runEditor opens path in $VISUAL, $EDITOR or vi, on the terminal.
+func runEditor(path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	args := strings.Fields(editor)
+	cmd := exec.Command(args[0], append(args[1:], path)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudflare/tf-migrate/internal/pipeline"
+	"github.com/cloudflare/tf-migrate/internal/transform"
+)
+
+func newTestReview(t *testing.T) *transform.BlockReview {
+	t.Helper()
+	file, diags := hclwrite.ParseConfig([]byte(`resource "cloudflare_record" "www" {
+  value = "192.0.2.1"
+}
+
+resource "cloudflare_dns_record" "www" {
+  content = "192.0.2.1"
+}
+`), "main.tf", hcl.InitialPos)
+	require.False(t, diags.HasErrors())
+	blocks := file.Body().Blocks()
+	return &transform.BlockReview{
+		Filename: "main.tf",
+		Address:  "cloudflare_record.www",
+		Original: blocks[0],
+		Result:   &transform.TransformResult{Blocks: blocks[1:], RemoveOriginal: true},
+		Diagnostics: hcl.Diagnostics{{
+			Severity: hcl.DiagWarning,
+			Summary:  "Check the TTL",
+			Detail:   "ttl is now required",
+		}},
+	}
+}
+
+func TestReviewer(t *testing.T) {
+	t.Run("side by side", func(t *testing.T) {
+		var out strings.Builder
+		r := newReviewer(strings.NewReader("maybe\nr\n"), &out)
+		r.width = 83
+
+		decision, err := r.review(newTestReview(t))
+		require.NoError(t, err)
+		assert.Equal(t, transform.ReviewReject, decision.Action)
+		assert.Contains(t, out.String(), `── main.tf: cloudflare_record.www ──
+v4                                       │ v5
+resource "cloudflare_record" "www" {     │ resource "cloudflare_dns_record" "www" {
+  value = "192.0.2.1"                    │   content = "192.0.2.1"
+}                                        │ }
+Diagnostics:
+  warning: Check the TTL
+    ttl is now required
+`)
+		assert.Equal(t, 2, strings.Count(out.String(), "Apply this migration?"), "an unknown answer asks again")
+	})
+
+	t.Run("stacked", func(t *testing.T) {
+		var out strings.Builder
+		r := newReviewer(strings.NewReader("a\n"), &out)
+		r.width = 40
+
+		decision, err := r.review(newTestReview(t))
+		require.NoError(t, err)
+		assert.Equal(t, transform.ReviewAccept, decision.Action)
+		assert.Contains(t, out.String(), `v4:
+  resource "cloudflare_record" "www" {
+    value = "192.0.2.1"
+  }
+v5:
+  resource "cloudflare_dns_record" "www" {
+`)
+	})
+
+	t.Run("edit", func(t *testing.T) {
+		var out strings.Builder
+		r := newReviewer(strings.NewReader("e\ne\n"), &out)
+		edits := []string{"resource {", `resource "cloudflare_dns_record" "www" {
+  content = "192.0.2.2"
+}
+`}
+		r.edit = func(path string) error {
+			content, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.Contains(t, string(content), `resource "cloudflare_dns_record" "www"`, "the editor opens the migrated blocks")
+			edit := edits[0]
+			edits = edits[1:]
+			return os.WriteFile(path, []byte(edit), 0644)
+		}
+
+		decision, err := r.review(newTestReview(t))
+		require.NoError(t, err)
+		assert.Contains(t, out.String(), "edited configuration is invalid", "an invalid edit asks again")
+		assert.Equal(t, transform.ReviewEdit, decision.Action)
+		require.Len(t, decision.Blocks, 1)
+		assert.Equal(t, `"192.0.2.2"`, strings.TrimSpace(string(decision.Blocks[0].Body().GetAttribute("content").Expr().BuildTokens(nil).Bytes())))
+	})
+
+	t.Run("accept all", func(t *testing.T) {
+		var out strings.Builder
+		r := newReviewer(strings.NewReader("A\n"), &out)
+		for range 3 {
+			decision, err := r.review(newTestReview(t))
+			require.NoError(t, err)
+			assert.Equal(t, transform.ReviewAccept, decision.Action)
+		}
+		assert.Equal(t, 1, strings.Count(out.String(), "Apply this migration?"))
+		assert.Equal(t, "Review: 3 accepted, 0 rejected, 0 edited", r.summary())
+	})
+
+	t.Run("no answer", func(t *testing.T) {
+		var out strings.Builder
+		_, err := newReviewer(strings.NewReader(""), &out).review(newTestReview(t))
+		assert.Error(t, err)
+	})
+}
+
+func TestProcessConfigFiles_Review(t *testing.T) {
+	configDir := t.TempDir()
+	input := `resource "cloudflare_record" "a" {
+  zone_id = "0da42c8d2132a9ddaf714f9e7c920711"
+  name    = "a"
+  type    = "A"
+  value   = "192.0.2.1"
+}
+
+resource "cloudflare_record" "b" {
+  zone_id = "0da42c8d2132a9ddaf714f9e7c920711"
+  name    = "b"
+  type    = "A"
+  value   = "192.0.2.2"
+}
+`
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "main.tf"), []byte(input), 0644))
+
+	var out strings.Builder
+	cfg := config{
+		configDir:     configDir,
+		outputDir:     configDir,
+		sourceVersion: "v4",
+		targetVersion: "v5",
+		parallelism:   1,
+		reviewer:      newReviewer(strings.NewReader("a\nr\n"), &out),
+	}
+	log := newTestLogger()
+	_, _, err := processConfigFiles(log, pipeline.BuildConfigPipeline(log, getProviders()), cfg)
+	require.NoError(t, err)
+
+	migrated, err := os.ReadFile(filepath.Join(configDir, "main.tf"))
+	require.NoError(t, err)
+	assert.Contains(t, string(migrated), `resource "cloudflare_dns_record" "a"`)
+	assert.Contains(t, string(migrated), `# tf-migrate:ignore rejected in review
+resource "cloudflare_record" "b" {
+  zone_id = "0da42c8d2132a9ddaf714f9e7c920711"
+  name    = "b"
+  type    = "A"
+  value   = "192.0.2.2"
+}`)
+	assert.Equal(t, "Review: 1 accepted, 1 rejected, 0 edited", cfg.reviewer.summary())
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/cloudflare/tf-migrate/internal/pipeline"
+	"github.com/cloudflare/tf-migrate/internal/transform"
+	tfhcl "github.com/cloudflare/tf-migrate/internal/transform/hcl"
+)
+
+// Terragrunt configuration is not Terraform code, but its generate blocks
+// write Terraform code (typically the provider block and required_providers
+// constraint) into every unit's working directory. tf-migrate migrates the
+// contents of those blocks and leaves the rest of the file alone.
+
+// isTerragruntConfigFile reports whether name is a Terragrunt unit
+// configuration file, or the root configuration units conventionally include.
+func isTerragruntConfigFile(name string) bool {
+	return name == "terragrunt.hcl" || name == "root.hcl"
+}
+
+// isIgnoredDir reports whether a directory holds tool-managed copies of the
+// configuration that must never be migrated: the providers and modules
+// downloaded by terraform init, and Terragrunt's download cache.
+func isIgnoredDir(name string) bool {
+	return name == ".terraform" || name == ".terragrunt-cache"
+}
+
+// generatedContent is the literal contents of a generate block.
+type generatedContent struct {
+	// Path is the file the block generates, e.g. "provider.tf".
+	Path string
+	// Content is the Terraform code the block generates.
+	Content string
+	// Range covers the contents expression in the Terragrunt file.
+	Range hcl.Range
+	// marker is the heredoc opening marker, e.g. "<<EOF" or "<<-EOF".
+	marker string
+	// indent and closingIndent are the indentation of the contents and of the
+	// closing marker of an indented (<<-) heredoc.
+	indent        string
+	closingIndent string
+}
+
+// findGeneratedContents returns the generate blocks of a Terragrunt file whose
+// contents can be migrated, and a warning for each block whose contents
+// interpolate Terragrunt expressions: those cannot be parsed as Terraform code.
+func findGeneratedContents(content []byte, filename string) ([]generatedContent, hcl.Diagnostics) {
+	file, diags := hclsyntax.ParseConfig(content, filename, hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	body, ok := file.Body.(*hclsyntax.Body)
+	if !ok {
+		return nil, nil
+	}
+
+	var found []generatedContent
+	var warnings hcl.Diagnostics
+	for _, block := range body.Blocks {
+		if block.Type != "generate" || len(block.Labels) != 1 {
+			continue
+		}
+		attr, ok := block.Body.Attributes["contents"]
+		if !ok {
+			continue
+		}
+
+		path := block.Labels[0]
+		if pathAttr, ok := block.Body.Attributes["path"]; ok {
+			if value, diags := pathAttr.Expr.Value(nil); !diags.HasErrors() && value.Type() == cty.String && !value.IsNull() {
+				path = value.AsString()
+			}
+		}
+
+		rng := attr.Expr.Range()
+		src := string(content[rng.Start.Byte:rng.End.Byte])
+
+		value, valueDiags := attr.Expr.Value(nil)
+		if valueDiags.HasErrors() || !value.IsKnown() || value.IsNull() || value.Type() != cty.String {
+			// Only blocks that configure the Cloudflare provider need attention.
+			if !strings.Contains(src, "cloudflare") {
+				continue
+			}
+			warnings = append(warnings, &hcl.Diagnostic{
+				Severity: hcl.DiagWarning,
+				Summary:  fmt.Sprintf("Terragrunt generate block not migrated: %s", path),
+				Detail: fmt.Sprintf("generate %q (%s)\n\nThe contents interpolate Terragrunt expressions, so they cannot be migrated "+
+					"automatically. Update the Cloudflare provider configuration in this block by hand.", block.Labels[0], filename),
+			})
+			continue
+		}
+
+		marker, indent, closingIndent := "<<EOF", "", ""
+		if strings.HasPrefix(src, "<<") {
+			lines := strings.Split(src, "\n")
+			marker = strings.TrimSpace(lines[0])
+			if strings.HasPrefix(marker, "<<-") {
+				for _, line := range lines[1 : len(lines)-1] {
+					if strings.TrimSpace(line) != "" {
+						indent = leadingWhitespace(line)
+						break
+					}
+				}
+				closingIndent = leadingWhitespace(lines[len(lines)-1])
+			}
+		}
+
+		found = append(found, generatedContent{
+			Path:          path,
+			Content:       value.AsString(),
+			Range:         rng,
+			marker:        marker,
+			indent:        indent,
+			closingIndent: closingIndent,
+		})
+	}
+
+	return found, warnings
+}
+
+// heredoc returns content as a heredoc using the block's original marker.
+// Template sequences are escaped so that Terragrunt writes them verbatim.
+func (g generatedContent) heredoc(content string) string {
+	content = strings.ReplaceAll(content, "${", "$${")
+	content = strings.ReplaceAll(content, "%{", "%%{")
+	content = strings.TrimSuffix(content, "\n")
+
+	var b strings.Builder
+	b.WriteString(g.marker + "\n")
+	for _, line := range strings.Split(content, "\n") {
+		if line != "" {
+			b.WriteString(g.indent + line)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString(g.closingIndent + strings.TrimLeft(g.marker, "<-"))
+	return b.String()
+}
+
+func leadingWhitespace(line string) string {
+	return line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+}
+
+// rewriteGeneratedContents applies rewrite to the contents of every generate
+// block in a Terragrunt file and returns the updated file. A block is only
+// rewritten when rewrite changes its contents.
+func rewriteGeneratedContents(content []byte, filename string, rewrite func(generatedContent) (string, error)) ([]byte, hcl.Diagnostics, error) {
+	generated, diags := findGeneratedContents(content, filename)
+	if diags.HasErrors() {
+		return content, nil, fmt.Errorf("failed to parse %s: %s", filename, diags.Error())
+	}
+
+	var edits []tfhcl.TextEdit
+	for _, g := range generated {
+		rewritten, err := rewrite(g)
+		if err != nil {
+			return content, diags, fmt.Errorf("failed to migrate generate block for %s in %s: %w", g.Path, filename, err)
+		}
+		if rewritten == g.Content {
+			continue
+		}
+		edits = append(edits, tfhcl.TextEdit{Range: g.Range, Text: g.heredoc(rewritten)})
+	}
+
+	return tfhcl.ApplyEdits(content, edits), diags, nil
+}
+
+// transformTerragruntFile runs the pipeline over the contents of each generate
+// block in a Terragrunt file. Diagnostics and migration blocks from every
+// block are collected into ctx.
+func transformTerragruntFile(p *pipeline.Pipeline, cfg config, ctx *transform.Context) ([]byte, error) {
+	content, diags, err := rewriteGeneratedContents(ctx.Content, ctx.Filename, func(g generatedContent) (string, error) {
+		generatedCtx := newTransformContext(cfg, ctx.FilePath, []byte(g.Content))
+		generatedCtx.Filename = g.Path
+
+		transformed, err := p.Transform(generatedCtx)
+		ctx.Diagnostics = append(ctx.Diagnostics, generatedCtx.Diagnostics...)
+		ctx.MigrationBlocks = append(ctx.MigrationBlocks, generatedCtx.MigrationBlocks...)
+		if err != nil {
+			return "", err
+		}
+
+		// Formatting alone is not a migration.
+		if bytes.Equal(transformed, hclwrite.Format([]byte(g.Content))) {
+			return g.Content, nil
+		}
+		return string(transformed), nil
+	})
+	ctx.Diagnostics = append(ctx.Diagnostics, diags...)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx.Content = content
+	return content, nil
+}
+
+// transformFile runs the pipeline over a single discovered file. Terragrunt
+// files are migrated through their generate blocks.
+func transformFile(p *pipeline.Pipeline, cfg config, ctx *transform.Context) ([]byte, error) {
+	if isTerragruntConfigFile(ctx.Filename) {
+		return transformTerragruntFile(p, cfg, ctx)
+	}
+	return p.Transform(ctx)
+}
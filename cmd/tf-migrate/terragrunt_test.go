@@ -0,0 +1,169 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudflare/tf-migrate/internal/pipeline"
+)
+
+const terragruntRoot = `generate "provider" {
+  path      = "provider.tf"
+  if_exists = "overwrite_terragrunt"
+  contents  = <<-EOF
+    terraform {
+      required_providers {
+        cloudflare = {
+          source  = "cloudflare/cloudflare"
+          version = "~> 4.52"
+        }
+      }
+    }
+
+    provider "cloudflare" {
+      api_token    = var.cloudflare_api_token
+      api_hostname = "api.example.com"
+    }
+  EOF
+}
+
+generate "backend" {
+  path     = "backend.tf"
+  contents = <<EOF
+terraform {
+  backend "s3" {
+    key = "${path_relative_to_include()}/terraform.tfstate"
+  }
+}
+EOF
+}
+`
+
+func TestFindTerraformFilesTerragrunt(t *testing.T) {
+	tmpDir := t.TempDir()
+	unit := filepath.Join(tmpDir, "live", "dns")
+	cache := filepath.Join(unit, ".terragrunt-cache", "abc")
+	require.NoError(t, os.MkdirAll(cache, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "root.hcl"), []byte(terragruntRoot), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(unit, "terragrunt.hcl"), []byte("include \"root\" {}\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(unit, "other.hcl"), []byte("locals {}\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(cache, "main.tf"), []byte("resource \"cloudflare_record\" \"x\" {}\n"), 0644))
+
+	files, err := findTerraformFilesWithRecursion(tmpDir, true, nil)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{
+		filepath.Join(tmpDir, "root.hcl"),
+		filepath.Join(unit, "terragrunt.hcl"),
+	}, files)
+}
+
+func TestTransformTerragruntFile(t *testing.T) {
+	cfg := config{sourceVersion: "v4", targetVersion: "v5"}
+	p := pipeline.BuildConfigPipeline(newTestLogger(), getProviders())
+
+	t.Run("migrates generated provider configuration", func(t *testing.T) {
+		ctx := newTransformContext(cfg, "/live/root.hcl", []byte(terragruntRoot))
+		transformed, err := transformFile(p, cfg, ctx)
+		require.NoError(t, err)
+
+		assert.Contains(t, string(transformed), `  contents  = <<-EOF
+    terraform {
+      required_providers {
+        cloudflare = {
+          source  = "cloudflare/cloudflare"
+          version = "~> 4.52"
+        }
+      }
+    }
+
+    provider "cloudflare" {
+      api_token = var.cloudflare_api_token
+      base_url  = "https://api.example.com/client/v4"
+    }
+  EOF
+}`)
+		// The backend block does not configure the provider and interpolates
+		// Terragrunt functions; it is left alone without a warning.
+		assert.Contains(t, string(transformed), `key = "${path_relative_to_include()}/terraform.tfstate"`)
+		assert.False(t, ctx.Diagnostics.HasErrors())
+		for _, diag := range ctx.Diagnostics {
+			assert.NotEqual(t, hcl.DiagWarning, diag.Severity, diag.Summary)
+		}
+
+		// A second run leaves the file unchanged.
+		again, err := transformFile(p, cfg, newTransformContext(cfg, "/live/root.hcl", transformed))
+		require.NoError(t, err)
+		assert.Equal(t, string(transformed), string(again))
+	})
+
+	t.Run("escapes template sequences in generated code", func(t *testing.T) {
+		content := `generate "provider" {
+  path     = "provider.tf"
+  contents = <<EOF
+provider "cloudflare" {
+  api_token = "$${var.token}"
+  retries   = 3
+}
+EOF
+}
+`
+		transformed, err := transformFile(p, cfg, newTransformContext(cfg, "terragrunt.hcl", []byte(content)))
+		require.NoError(t, err)
+		assert.Equal(t, `generate "provider" {
+  path     = "provider.tf"
+  contents = <<EOF
+provider "cloudflare" {
+  api_token = "$${var.token}"
+}
+EOF
+}
+`, string(transformed))
+	})
+
+	t.Run("warns about interpolated provider configuration", func(t *testing.T) {
+		content := `generate "provider" {
+  path     = "provider.tf"
+  contents = <<EOF
+provider "cloudflare" {
+  api_token = "${local.token}"
+}
+EOF
+}
+`
+		ctx := newTransformContext(cfg, "terragrunt.hcl", []byte(content))
+		transformed, err := transformFile(p, cfg, ctx)
+		require.NoError(t, err)
+		assert.Equal(t, content, string(transformed))
+		require.Len(t, ctx.Diagnostics, 1)
+		assert.Equal(t, "Terragrunt generate block not migrated: provider.tf", ctx.Diagnostics[0].Summary)
+	})
+}
+
+func TestUpdateProviderVersionConstraintTerragrunt(t *testing.T) {
+	tmpDir := t.TempDir()
+	rootFile := filepath.Join(tmpDir, "root.hcl")
+	require.NoError(t, os.WriteFile(rootFile, []byte(terragruntRoot), 0644))
+
+	cfg := config{
+		configDir:             tmpDir,
+		sourceVersion:         "v4",
+		targetVersion:         "v5",
+		targetProviderVersion: "5.9.0",
+	}
+	require.NoError(t, updateProviderVersionConstraint(newTestLogger(), cfg, nil))
+
+	result, err := os.ReadFile(rootFile)
+	require.NoError(t, err)
+	assert.Contains(t, string(result), `          version = "5.9.0"`)
+	assert.NotContains(t, string(result), `~> 4.52`)
+
+	constraint, source, err := parseVersionFromRequiredProviders(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "5.9.0", constraint)
+	assert.Equal(t, rootFile, source)
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/hcl/v2"
+
+	tfhcl "github.com/cloudflare/tf-migrate/internal/transform/hcl"
+)
+
+// evalContexts builds, once per module directory, the evaluation context that
+// lets migrators resolve expressions over variables and locals. Only root
+// modules take their variables from defaults and .tfvars files; in a module
+// called by another, the module call sets them, so they are left unresolved.
+type evalContexts struct {
+	log      hclog.Logger
+	rootDir  string
+	varFiles []tfhcl.SourceFile // --var-file files, applied to rootDir only
+	modules  map[string]bool    // directories called as local modules
+
+	// mu guards byDir. It is held while a context is built, so that the
+	// files of a module are all read before any of them is migrated.
+	mu    sync.Mutex
+	byDir map[string]*hcl.EvalContext
+}
+
+// newEvalContexts reads the --var-file files up front, so that a missing file
+// is reported before anything is migrated, and finds the directories called
+// as local modules.
+func newEvalContexts(log hclog.Logger, cfg config) (*evalContexts, error) {
+	e := &evalContexts{
+		log:     log,
+		rootDir: filepath.Clean(cfg.configDir),
+		modules: make(map[string]bool),
+		byDir:   make(map[string]*hcl.EvalContext),
+	}
+	// Problems with module sources are reported by the migration itself.
+	graph, err := buildModuleGraph(hclog.NewNullLogger(), cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find local modules: %w", err)
+	}
+	for _, dir := range graph.Modules {
+		e.modules[dir] = true
+	}
+	for _, path := range cfg.varFiles {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read variable definitions file: %w", err)
+		}
+		e.varFiles = append(e.varFiles, tfhcl.SourceFile{Name: path, Content: content})
+	}
+	return e, nil
+}
+
+// forFile returns the evaluation context of the module containing file. It
+// returns nil when e is nil.
+func (e *evalContexts) forFile(file string) *hcl.EvalContext {
+	if e == nil {
+		return nil
+	}
+	dir := filepath.Clean(filepath.Dir(file))
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if ctx, ok := e.byDir[dir]; ok {
+		return ctx
+	}
+
+	var configFiles []tfhcl.SourceFile
+	files, err := findTerraformFilesWithRecursion(dir, false, nil)
+	if err != nil {
+		e.log.Debug("Failed to list module files for evaluation", "dir", dir, "error", err)
+	}
+	for _, path := range files {
+		if isTerragruntConfigFile(filepath.Base(path)) {
+			continue
+		}
+		if content, err := os.ReadFile(path); err == nil {
+			configFiles = append(configFiles, tfhcl.SourceFile{Name: path, Content: content})
+		}
+	}
+
+	var ctx *hcl.EvalContext
+	if e.modules[dir] {
+		ctx = tfhcl.BuildModuleEvalContext(configFiles)
+	} else {
+		varFiles := autoLoadedVarFiles(dir)
+		if dir == e.rootDir {
+			varFiles = append(varFiles, e.varFiles...)
+		}
+		ctx = tfhcl.BuildEvalContext(configFiles, varFiles)
+	}
+	e.byDir[dir] = ctx
+	return ctx
+}
+
+// autoLoadedVarFiles returns the variable definitions files Terraform loads
+// automatically from dir, in the order it loads them: terraform.tfvars, then
+// terraform.tfvars.json, then *.auto.tfvars and *.auto.tfvars.json in
+// lexical order.
+func autoLoadedVarFiles(dir string) []tfhcl.SourceFile {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	var auto []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() && (strings.HasSuffix(name, ".auto.tfvars") || strings.HasSuffix(name, ".auto.tfvars.json")) {
+			auto = append(auto, name)
+		}
+	}
+	sort.Strings(auto)
+
+	var files []tfhcl.SourceFile
+	for _, name := range append([]string{"terraform.tfvars", "terraform.tfvars.json"}, auto...) {
+		if content, err := os.ReadFile(filepath.Join(dir, name)); err == nil {
+			files = append(files, tfhcl.SourceFile{Name: name, Content: content})
+		}
+	}
+	return files
+}
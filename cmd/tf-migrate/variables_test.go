@@ -0,0 +1,65 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestEvalContexts(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeModuleFiles(t, tmpDir, map[string]string{
+		"variables.tf":                 "variable \"a\" {\n  default = \"default\"\n}\nvariable \"b\" {}\nvariable \"c\" {}\n",
+		"terraform.tfvars":             "a = \"tfvars\"\nb = \"tfvars\"\n",
+		"b.auto.tfvars":                "b = \"auto\"\n",
+		"prod.tfvars":                  "c = \"prod\"\n",
+		"terragrunt.hcl":               "inputs = {}\n",
+		"modules/dns/variables.tf":     "variable \"c\" {\n  default = \"module\"\n}\n",
+		"modules/dns/ignored.tfvar":    "c = \"ignored\"\n",
+		"main.tf":                      "module \"aop\" {\n  source   = \"./modules/aop\"\n  hostname = \"app.example.com\"\n}\n",
+		"modules/aop/variables.tf":     "variable \"hostname\" {\n  default = null\n}\n",
+		"modules/aop/terraform.tfvars": "hostname = \"tfvars\"\n",
+	})
+
+	e, err := newEvalContexts(newTestLogger(), config{
+		configDir: tmpDir,
+		varFiles:  []string{filepath.Join(tmpDir, "prod.tfvars")},
+	})
+	require.NoError(t, err)
+
+	root := e.forFile(filepath.Join(tmpDir, "main.tf")).Variables["var"]
+	assert.Equal(t, cty.StringVal("tfvars"), root.GetAttr("a"))
+	assert.Equal(t, cty.StringVal("auto"), root.GetAttr("b"))
+	assert.Equal(t, cty.StringVal("prod"), root.GetAttr("c"))
+
+	// --var-file only applies to the root module.
+	module := e.forFile(filepath.Join(tmpDir, "modules", "dns", "main.tf")).Variables["var"]
+	assert.Equal(t, cty.StringVal("module"), module.GetAttr("c"))
+
+	// A called module gets its variables from the module call, so neither
+	// defaults nor .tfvars files are used.
+	called := e.forFile(filepath.Join(tmpDir, "modules", "aop", "main.tf")).Variables["var"]
+	assert.False(t, called.Type().HasAttribute("hostname"))
+
+	assert.Nil(t, (*evalContexts)(nil).forFile(filepath.Join(tmpDir, "main.tf")))
+
+	_, err = newEvalContexts(newTestLogger(), config{configDir: tmpDir, varFiles: []string{filepath.Join(tmpDir, "missing.tfvars")}})
+	assert.ErrorContains(t, err, "failed to read variable definitions file")
+}
+
+func TestAutoLoadedVarFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, name := range []string{"z.auto.tfvars", "a.auto.tfvars.json", "terraform.tfvars.json", "terraform.tfvars", "other.tfvars"} {
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, name), []byte{}, 0644))
+	}
+
+	var names []string
+	for _, file := range autoLoadedVarFiles(tmpDir) {
+		names = append(names, file.Name)
+	}
+	assert.Equal(t, []string{"terraform.tfvars", "terraform.tfvars.json", "a.auto.tfvars.json", "z.auto.tfvars"}, names)
+}
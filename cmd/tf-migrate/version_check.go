@@ -10,6 +10,8 @@ import (
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclwrite"
+
+	tfhcl "github.com/cloudflare/tf-migrate/internal/transform/hcl"
 )
 
 // minimumProviderVersion is the minimum Cloudflare provider version required
@@ -61,6 +63,45 @@ func checkMinimumProviderVersion(cfg config) error {
 		cfg.configDir, minimumProviderVersion)
 }
 
+// cloudflareProviderAddresses are the addresses the Cloudflare provider is
+// recorded under in .terraform.lock.hcl: by Terraform, by OpenTofu, and by
+// older Terraform releases that omitted the registry host.
+var cloudflareProviderAddresses = []string{
+	"registry.terraform.io/cloudflare/cloudflare",
+	"registry.opentofu.org/cloudflare/cloudflare",
+	"cloudflare/cloudflare",
+}
+
+// isCloudflareProviderAddress reports whether addr is a Cloudflare provider
+// address. Registry hosts are case-insensitive.
+func isCloudflareProviderAddress(addr string) bool {
+	addr = strings.ToLower(addr)
+	for _, known := range cloudflareProviderAddresses {
+		if addr == known {
+			return true
+		}
+	}
+	return false
+}
+
+// lockFileInitCommand returns the command that regenerates the lock file in
+// dir: terragrunt for a Terragrunt unit (or every unit under a root.hcl),
+// tofu when the existing lock file was written by OpenTofu, and terraform
+// otherwise.
+func lockFileInitCommand(dir string) string {
+	if _, err := os.Stat(filepath.Join(dir, "terragrunt.hcl")); err == nil {
+		return "terragrunt init -upgrade -backend=false"
+	}
+	if _, err := os.Stat(filepath.Join(dir, "root.hcl")); err == nil {
+		return "terragrunt run-all init -upgrade -backend=false"
+	}
+	content, err := os.ReadFile(filepath.Join(dir, ".terraform.lock.hcl"))
+	if err == nil && strings.Contains(string(content), "registry.opentofu.org/") {
+		return "tofu init -upgrade -backend=false"
+	}
+	return "terraform init -upgrade -backend=false"
+}
+
 // parseVersionFromLockFile reads .terraform.lock.hcl and extracts the
 // installed Cloudflare provider version.
 // Returns empty string if the cloudflare provider is not in the lock file.
@@ -90,11 +131,7 @@ func parseVersionFromLockFile(configDir string) (string, error) {
 			continue
 		}
 
-		// Check for registry.terraform.io/cloudflare/cloudflare
-		// or just cloudflare/cloudflare (older formats)
-		providerLabel := labels[0]
-		if providerLabel == "registry.terraform.io/cloudflare/cloudflare" ||
-			providerLabel == "cloudflare/cloudflare" {
+		if isCloudflareProviderAddress(labels[0]) {
 
 			versionAttr := block.Body().GetAttribute("version")
 			if versionAttr == nil {
@@ -126,38 +163,25 @@ func parseVersionFromRequiredProviders(cfg config) (versionConstraint, sourceFil
 			continue
 		}
 
-		parsed, diags := hclwrite.ParseConfig(content, filepath.Base(file), hcl.InitialPos)
-		if diags.HasErrors() {
-			continue
+		// Terragrunt units declare the constraint in the Terraform code their
+		// generate blocks write.
+		var sources [][]byte
+		if isTerragruntConfigFile(filepath.Base(file)) {
+			generated, _ := findGeneratedContents(content, filepath.Base(file))
+			for _, g := range generated {
+				sources = append(sources, []byte(g.Content))
+			}
+		} else {
+			sources = append(sources, content)
 		}
 
-		for _, block := range parsed.Body().Blocks() {
-			if block.Type() != "terraform" {
+		for _, source := range sources {
+			parsed, diags := tfhcl.ParseConfigFile(source, filepath.Base(file))
+			if diags.HasErrors() {
 				continue
 			}
-
-			for _, inner := range block.Body().Blocks() {
-				if inner.Type() != "required_providers" {
-					continue
-				}
-
-				cfAttr := inner.Body().GetAttribute("cloudflare")
-				if cfAttr == nil {
-					continue
-				}
-
-				// Extract the version from the cloudflare attribute
-				attrStr := string(cfAttr.BuildTokens(nil).Bytes())
-				if !strings.Contains(attrStr, "cloudflare/cloudflare") {
-					continue
-				}
-
-				// Look for version = "..." pattern
-				re := regexp.MustCompile(`version\s*=\s*"([^"]+)"`)
-				matches := re.FindStringSubmatch(attrStr)
-				if len(matches) > 1 {
-					return matches[1], file, nil
-				}
+			if constraint := cloudflareVersionConstraint(parsed); constraint != "" {
+				return constraint, file, nil
 			}
 		}
 	}
@@ -165,6 +189,42 @@ func parseVersionFromRequiredProviders(cfg config) (versionConstraint, sourceFil
 	return "", "", fmt.Errorf("no required_providers block with cloudflare provider found")
 }
 
+// cloudflareVersionConstraint returns the version constraint of the cloudflare
+// entry in the file's required_providers block, if any.
+func cloudflareVersionConstraint(parsed *hclwrite.File) string {
+	for _, block := range parsed.Body().Blocks() {
+		if block.Type() != "terraform" {
+			continue
+		}
+
+		for _, inner := range block.Body().Blocks() {
+			if inner.Type() != "required_providers" {
+				continue
+			}
+
+			cfAttr := inner.Body().GetAttribute("cloudflare")
+			if cfAttr == nil {
+				continue
+			}
+
+			// Extract the version from the cloudflare attribute. The source
+			// may name any registry host, e.g. registry.opentofu.org.
+			attrStr := string(cfAttr.BuildTokens(nil).Bytes())
+			if !strings.Contains(attrStr, "cloudflare/cloudflare") {
+				continue
+			}
+
+			// Look for version = "..." pattern
+			re := regexp.MustCompile(`version\s*=\s*"([^"]+)"`)
+			matches := re.FindStringSubmatch(attrStr)
+			if len(matches) > 1 {
+				return matches[1]
+			}
+		}
+	}
+	return ""
+}
+
 // verifyVersionMeetsMinimum compares a version string against the minimum required.
 // Versions must be in MAJOR.MINOR.PATCH format.
 func verifyVersionMeetsMinimum(version string) error {
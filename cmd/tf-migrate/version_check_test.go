@@ -138,6 +138,27 @@ provider "registry.terraform.io/hashicorp/aws" {
 		t.Errorf("Expected empty version, got %s", version)
 	}
 
+	// Test with a lock file written by OpenTofu
+	lockContentTofu := `# This file is maintained automatically by "tofu init".
+provider "registry.opentofu.org/cloudflare/cloudflare" {
+  version     = "4.52.7"
+  constraints = "~> 4.52"
+}
+`
+	tempDirTofu := t.TempDir()
+	err = os.WriteFile(filepath.Join(tempDirTofu, ".terraform.lock.hcl"), []byte(lockContentTofu), 0644)
+	if err != nil {
+		t.Fatalf("Failed to write lock file: %v", err)
+	}
+
+	version, err = parseVersionFromLockFile(tempDirTofu)
+	if err != nil {
+		t.Fatalf("parseVersionFromLockFile failed: %v", err)
+	}
+	if version != "4.52.7" {
+		t.Errorf("Expected version 4.52.7, got %s", version)
+	}
+
 	// Test with no lock file
 	tempDir3 := t.TempDir()
 	_, err = parseVersionFromLockFile(tempDir3)
@@ -158,6 +158,39 @@ func TestUpdateProviderVersionConstraint(t *testing.T) {
 }`,
 			shouldUpdate: true,
 		},
+		{
+			name: "keep OpenTofu registry source",
+			input: `terraform {
+  required_providers {
+    cloudflare = {
+      source = "registry.opentofu.org/cloudflare/cloudflare"
+    }
+  }
+}`,
+			targetVersion: "5.19.0-beta.4",
+			expected: `terraform {
+  required_providers {
+    cloudflare = {
+      source  = "registry.opentofu.org/cloudflare/cloudflare"
+      version = "5.19.0-beta.4"
+    }
+  }
+}`,
+			shouldUpdate: true,
+		},
+		{
+			name: "do not duplicate a version already at the target",
+			input: `terraform {
+  required_providers {
+    cloudflare = {
+      source  = "cloudflare/cloudflare"
+      version = "5.19.0-beta.4"
+    }
+  }
+}`,
+			targetVersion: "5.19.0-beta.4",
+			shouldUpdate:  false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -92,6 +92,29 @@ func (r *TestRunner) RunTest(t *testing.T, test TestCase) {
 	})
 }
 
+// RunIdempotencyTest migrates a test case's expected output a second time and
+// asserts the result is unchanged. This guarantees that re-running tf-migrate
+// on an already-migrated directory is a no-op: no blocks are re-transformed
+// and no moved, removed or import blocks are duplicated.
+func (r *TestRunner) RunIdempotencyTest(t *testing.T, test TestCase) {
+	t.Run(test.Resource, func(t *testing.T) {
+		tempDir := t.TempDir()
+
+		expectedDir := filepath.Join(r.BaseDir, "testdata", test.Resource, "expected")
+		if err := r.copyDirectory(expectedDir, tempDir); err != nil {
+			t.Fatalf("Failed to copy expected files: %v", err)
+		}
+
+		if err := r.runMigration(tempDir); err != nil {
+			t.Fatalf("Migration failed: %v", err)
+		}
+
+		if err := r.compareDirectories(expectedDir, tempDir); err != nil {
+			t.Errorf("Migrating already-migrated output was not a no-op: %v", err)
+		}
+	})
+}
+
 // copyDirectory copies all files from src to dst
 func (r *TestRunner) copyDirectory(src, dst string) error {
 	entries, err := os.ReadDir(src)
@@ -170,7 +193,7 @@ func (r *TestRunner) compareDirectories(expectedDir, actualDir string) error {
 			if err := r.compareJSONFiles(expectedFile, actualFile); err != nil {
 				errors = append(errors, fmt.Sprintf("%s: %v", entry.Name(), err))
 			}
-		case ".tf":
+		case ".tf", ".json":
 			if err := r.compareTextFiles(expectedFile, actualFile); err != nil {
 				errors = append(errors, fmt.Sprintf("%s: %v", entry.Name(), err))
 			}
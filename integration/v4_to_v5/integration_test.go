@@ -49,6 +49,33 @@ func TestV4ToV5Migration(t *testing.T) {
 	}
 }
 
+// TestV4ToV5MigrationIdempotency verifies that migrating each test case's
+// expected output again leaves it unchanged.
+func TestV4ToV5MigrationIdempotency(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration tests in short mode")
+	}
+
+	runner, err := integration.NewTestRunner("v4", "v5")
+	if err != nil {
+		t.Fatalf("Failed to create test runner: %v", err)
+	}
+
+	entries, err := os.ReadDir("testdata")
+	if err != nil {
+		t.Fatalf("Failed to read testdata directory: %v", err)
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		runner.RunIdempotencyTest(t, integration.TestCase{
+			Resource: entry.Name(),
+		})
+	}
+}
+
 // TestSingleResource allows testing a specific resource during development
 func TestSingleResource(t *testing.T) {
 	if testing.Short() {
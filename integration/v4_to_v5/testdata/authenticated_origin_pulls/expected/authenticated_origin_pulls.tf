@@ -6,16 +6,6 @@ variable "cloudflare_zone_id" {
   type        = string
 }
 
-
-
-
-# TKT-001: for_each variant — cert_id referencing per-hostname cert
-locals {
-  hostnames = { "api" = "api.example.com", "web" = "web.example.com" }
-}
-
-
-
 # Basic zone-wide AOP
 resource "cloudflare_authenticated_origin_pulls_settings" "zone_wide" {
   zone_id = var.cloudflare_zone_id
@@ -50,6 +40,11 @@ resource "cloudflare_authenticated_origin_pulls" "hostname_aop" {
   }]
 }
 
+# TKT-001: for_each variant — cert_id referencing per-hostname cert
+locals {
+  hostnames = { "api" = "api.example.com", "web" = "web.example.com" }
+}
+
 resource "cloudflare_authenticated_origin_pulls_hostname_certificate" "multi_cert" {
   for_each    = local.hostnames
   zone_id     = var.cloudflare_zone_id
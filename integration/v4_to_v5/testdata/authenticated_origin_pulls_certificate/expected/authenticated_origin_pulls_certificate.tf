@@ -4,13 +4,6 @@ variable "cloudflare_zone_id" {
   type = string
 }
 
-
-
-# Output references to test that they are preserved
-output "per_zone_1_status" {
-  value = cloudflare_authenticated_origin_pulls_certificate.per_zone_1.status
-}
-
 # Per-zone certificate - will migrate to cloudflare_authenticated_origin_pulls_certificate
 resource "cloudflare_authenticated_origin_pulls_certificate" "per_zone_1" {
   zone_id     = var.cloudflare_zone_id
@@ -135,3 +128,8 @@ moved {
   from = cloudflare_authenticated_origin_pulls_certificate.per_hostname_1
   to   = cloudflare_authenticated_origin_pulls_hostname_certificate.per_hostname_1
 }
+
+# Output references to test that they are preserved
+output "per_zone_1_status" {
+  value = cloudflare_authenticated_origin_pulls_certificate.per_zone_1.status
+}
@@ -13,124 +13,6 @@ variable "cloudflare_domain" {
   type        = string
 }
 
-
-
-
-
-
-
-
-
-
-# ========================================
-# Advanced Terraform Patterns for Testing
-# ========================================
-
-# Pattern 1: Variable references
-variable "domain_name" {
-  type    = string
-  default = "cf-tf-test.com"
-}
-
-variable "record_ttl" {
-  type    = number
-  default = 3600
-}
-
-# Pattern 2: Local values with expressions
-locals {
-  name_prefix    = "cftftest"
-  proxied_ttl    = 1
-  tags           = ["e2e-test", "migration-test"]
-  common_zone_id = var.cloudflare_zone_id
-
-  # Complex expression
-  subdomain_prefix = "cftftest"
-  full_subdomain   = "${local.subdomain_prefix}.${var.domain_name}"
-}
-
-# Pattern 3: for_each with map
-variable "subdomains" {
-  type = map(object({
-    value   = string
-    proxied = bool
-  }))
-  default = {
-    "api" = {
-      value   = "192.0.2.10"
-      proxied = true
-    }
-    "www" = {
-      value   = "192.0.2.11"
-      proxied = true
-    }
-    "static" = {
-      value   = "192.0.2.12"
-      proxied = false
-    }
-  }
-}
-
-
-# Pattern 4: for_each with list converted to set
-variable "txt_records" {
-  type = list(object({
-    name  = string
-    value = string
-  }))
-  default = [
-    {
-      name  = "_dmarc"
-      value = "v=DMARC1; p=quarantine; rua=mailto:dmarc@cf-tf-test.com"
-    },
-    {
-      name  = "_dkim"
-      value = "v=DKIM1; k=rsa; p=MIGfMA0GCS..."
-    }
-  ]
-}
-
-
-# Pattern 5: Count-based resources
-variable "backup_mx_count" {
-  type    = number
-  default = 3
-}
-
-
-# Pattern 6: Conditional resource creation
-variable "enable_ipv6" {
-  type    = bool
-  default = true
-}
-
-
-# Pattern 7: Dynamic blocks (if supported)
-variable "caa_records" {
-  type = list(object({
-    flags = number
-    tag   = string
-    value = string
-  }))
-  default = [
-    {
-      flags = 0
-      tag   = "issue"
-      value = "letsencrypt.org"
-    },
-    {
-      flags = 0
-      tag   = "issuewild"
-      value = "letsencrypt.org"
-    }
-  ]
-}
-
-
-
-
-
-
 # Standard DNS records
 resource "cloudflare_dns_record" "example_a" {
   zone_id = var.cloudflare_zone_id
@@ -278,6 +160,55 @@ moved {
   to   = cloudflare_dns_record.example_openpgpkey
 }
 
+# ========================================
+# Advanced Terraform Patterns for Testing
+# ========================================
+
+# Pattern 1: Variable references
+variable "domain_name" {
+  type    = string
+  default = "cf-tf-test.com"
+}
+
+variable "record_ttl" {
+  type    = number
+  default = 3600
+}
+
+# Pattern 2: Local values with expressions
+locals {
+  name_prefix    = "cftftest"
+  proxied_ttl    = 1
+  tags           = ["e2e-test", "migration-test"]
+  common_zone_id = var.cloudflare_zone_id
+
+  # Complex expression
+  subdomain_prefix = "cftftest"
+  full_subdomain   = "${local.subdomain_prefix}.${var.domain_name}"
+}
+
+# Pattern 3: for_each with map
+variable "subdomains" {
+  type = map(object({
+    value   = string
+    proxied = bool
+  }))
+  default = {
+    "api" = {
+      value   = "192.0.2.10"
+      proxied = true
+    }
+    "www" = {
+      value   = "192.0.2.11"
+      proxied = true
+    }
+    "static" = {
+      value   = "192.0.2.12"
+      proxied = false
+    }
+  }
+}
+
 resource "cloudflare_dns_record" "subdomain_a_records" {
   for_each = var.subdomains
 
@@ -294,6 +225,24 @@ moved {
   to   = cloudflare_dns_record.subdomain_a_records
 }
 
+# Pattern 4: for_each with list converted to set
+variable "txt_records" {
+  type = list(object({
+    name  = string
+    value = string
+  }))
+  default = [
+    {
+      name  = "_dmarc"
+      value = "v=DMARC1; p=quarantine; rua=mailto:dmarc@cf-tf-test.com"
+    },
+    {
+      name  = "_dkim"
+      value = "v=DKIM1; k=rsa; p=MIGfMA0GCS..."
+    }
+  ]
+}
+
 resource "cloudflare_dns_record" "security_txt_records" {
   for_each = { for idx, record in var.txt_records : record.name => record }
 
@@ -309,6 +258,12 @@ moved {
   to   = cloudflare_dns_record.security_txt_records
 }
 
+# Pattern 5: Count-based resources
+variable "backup_mx_count" {
+  type    = number
+  default = 3
+}
+
 resource "cloudflare_dns_record" "backup_mx" {
   count = var.backup_mx_count
 
@@ -325,6 +280,12 @@ moved {
   to   = cloudflare_dns_record.backup_mx
 }
 
+# Pattern 6: Conditional resource creation
+variable "enable_ipv6" {
+  type    = bool
+  default = true
+}
+
 resource "cloudflare_dns_record" "ipv6_aaaa" {
   count = var.enable_ipv6 ? 1 : 0
 
@@ -341,6 +302,27 @@ moved {
   to   = cloudflare_dns_record.ipv6_aaaa
 }
 
+# Pattern 7: Dynamic blocks (if supported)
+variable "caa_records" {
+  type = list(object({
+    flags = number
+    tag   = string
+    value = string
+  }))
+  default = [
+    {
+      flags = 0
+      tag   = "issue"
+      value = "letsencrypt.org"
+    },
+    {
+      flags = 0
+      tag   = "issuewild"
+      value = "letsencrypt.org"
+    }
+  ]
+}
+
 # Pattern 8: Resource with complex data structures
 resource "cloudflare_dns_record" "dnslink" {
   zone_id = var.cloudflare_zone_id
@@ -35,40 +35,6 @@ locals {
   ])
 }
 
-
-
-
-
-
-
-
-
-
-
-
-
-
-
-
-
-
-
-
-
-
-# =============================================================================
-# Output for verification
-# =============================================================================
-output "total_rules_created" {
-  value       = 27
-  description = "Total number of leaked credential check rules created for testing"
-}
-
-output "conditional_rules_enabled" {
-  value       = var.enable_optional_rules
-  description = "Whether conditional rules are enabled"
-}
-
 import {
   to = cloudflare_leaked_credential_check_rule.basic
   id = "<zone_id>/<detection_id>"
@@ -366,3 +332,16 @@ resource "cloudflare_leaked_credential_check_rule" "deep_json" {
   username = "lookup_json_string(lookup_json_string(http.request.body.raw, \"payload\"), \"username\")"
   password = "lookup_json_string(lookup_json_string(http.request.body.raw, \"payload\"), \"password\")"
 }
+
+# =============================================================================
+# Output for verification
+# =============================================================================
+output "total_rules_created" {
+  value       = 27
+  description = "Total number of leaked credential check rules created for testing"
+}
+
+output "conditional_rules_enabled" {
+  value       = var.enable_optional_rules
+  description = "Whether conditional rules are enabled"
+}
@@ -65,9 +65,9 @@ resource "cloudflare_list" "asn_list" {
   kind        = "asn"
   description = "ASN list with dynamic items"
 
-  items = [for item in var.dynamic_asn_list : {
-    asn     = item.number
-    comment = item.description
+  items = [for value in var.dynamic_asn_list : {
+    asn     = value.number
+    comment = value.description
   }]
 }
 
@@ -86,9 +86,12 @@ resource "cloudflare_list" "hostname_list" {
   description = "Hostname list with mixed items"
 
 
-  items = concat([{ hostname = { url_hostname = "example.com" }, comment = "Static hostname" }], [for host in var.dynamic_hostnames : {
-    hostname = { url_hostname = host }
-  }])
+  items = concat(
+    [{ hostname = { url_hostname = "example.com" }, comment = "Static hostname" }],
+    [for value in var.dynamic_hostnames : {
+      hostname = { url_hostname = value }
+    }],
+  )
 }
 
 # ========================================
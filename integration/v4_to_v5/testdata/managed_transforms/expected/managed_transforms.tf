@@ -13,7 +13,6 @@ variable "cloudflare_domain" {
   type        = string
 }
 
-
 # Single comprehensive test covering all valid header types
 resource "cloudflare_managed_transforms" "test" {
   zone_id = var.cloudflare_zone_id
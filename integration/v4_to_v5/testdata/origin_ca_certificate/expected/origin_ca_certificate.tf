@@ -33,7 +33,7 @@ locals {
   test_domain     = "cftftest-example.${var.cloudflare_domain}"
   wildcard_domain = "*.cftftest-example.${var.cloudflare_domain}"
   ecc_type        = "origin-ecc"
-  default_csr = <<EOT
+  default_csr     = <<EOT
 -----BEGIN CERTIFICATE REQUEST-----
 MIICuDCCAaACAQAwczELMAkGA1UEBhMCVVMxEzARBgNVBAgMCkNhbGlmb3JuaWEx
 FjAUBgNVBAcMDVNhbiBGcmFuY2lzY28xGDAWBgNVBAoMD0V4YW1wbGUgQ29tcGFu
@@ -223,9 +223,9 @@ locals {
 resource "cloudflare_origin_ca_certificate" "foreach_map" {
   for_each = local.certificates_map
 
-  csr          = local.default_csr
-  request_type = "origin-rsa"
-  hostnames    = [each.value.hostname]
+  csr                = local.default_csr
+  request_type       = "origin-rsa"
+  hostnames          = [each.value.hostname]
   requested_validity = each.value.validity
 }
 
@@ -253,9 +253,9 @@ resource "cloudflare_origin_ca_certificate" "foreach_set" {
 resource "cloudflare_origin_ca_certificate" "count_pattern" {
   count = 3
 
-  csr          = local.default_csr
-  request_type = "origin-rsa"
-  hostnames    = ["cftftest-count-${count.index}.${var.cloudflare_domain}"]
+  csr                = local.default_csr
+  request_type       = "origin-rsa"
+  hostnames          = ["cftftest-count-${count.index}.${var.cloudflare_domain}"]
   requested_validity = 365
 }
 
@@ -311,7 +311,7 @@ resource "cloudflare_origin_ca_certificate" "with_functions" {
 
 # Maximal configuration (all possible fields)
 resource "cloudflare_origin_ca_certificate" "maximal" {
-  csr          = <<EOT
+  csr                = <<EOT
 -----BEGIN CERTIFICATE REQUEST-----
 MIICuDCCAaACAQAwczELMAkGA1UEBhMCVVMxEzARBgNVBAgMCkNhbGlmb3JuaWEx
 FjAUBgNVBAcMDVNhbiBGcmFuY2lzY28xGDAWBgNVBAoMD0V4YW1wbGUgQ29tcGFu
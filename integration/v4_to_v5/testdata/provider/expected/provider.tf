@@ -0,0 +1,32 @@
+variable "cloudflare_api_token" {
+  type = string
+}
+
+variable "api_hostname" {
+  type    = string
+  default = "api.cloudflare.com"
+}
+
+# Default provider with v4-only tuning arguments
+provider "cloudflare" {
+  api_token = var.cloudflare_api_token
+}
+
+# Aliased provider with a custom endpoint
+provider "cloudflare" {
+  alias     = "staging"
+  api_token = var.cloudflare_api_token
+  base_url  = "https://${var.api_hostname}/client/v4"
+}
+
+provider "cloudflare" {
+  alias = "origin_ca"
+}
+
+resource "cloudflare_zone" "example" {
+  provider = cloudflare.staging
+  name     = "example.com"
+  account = {
+    id = "f037e56e89293a057740de681ac9abbe"
+  }
+}
@@ -0,0 +1,38 @@
+variable "cloudflare_api_token" {
+  type = string
+}
+
+variable "api_hostname" {
+  type    = string
+  default = "api.cloudflare.com"
+}
+
+# Default provider with v4-only tuning arguments
+provider "cloudflare" {
+  api_token          = var.cloudflare_api_token
+  account_id         = "f037e56e89293a057740de681ac9abbe"
+  rps                = 4
+  retries            = 3
+  min_backoff        = 1
+  max_backoff        = 30
+  api_client_logging = true
+}
+
+# Aliased provider with a custom endpoint
+provider "cloudflare" {
+  alias         = "staging"
+  api_token     = var.cloudflare_api_token
+  api_hostname  = var.api_hostname
+  api_base_path = "/client/v4"
+}
+
+provider "cloudflare" {
+  alias                = "origin_ca"
+  api_user_service_key = "v1.0-example-service-key"
+}
+
+resource "cloudflare_zone" "example" {
+  provider   = cloudflare.staging
+  account_id = "f037e56e89293a057740de681ac9abbe"
+  zone       = "example.com"
+}
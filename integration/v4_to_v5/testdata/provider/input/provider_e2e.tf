@@ -0,0 +1,7 @@
+# Provider blocks cannot be declared inside the e2e modules, which inherit the
+# root module's provider, so the provider migration is covered by the
+# integration test only.
+variable "cloudflare_account_id" {
+  description = "Cloudflare account ID"
+  type        = string
+}
@@ -21,47 +21,6 @@ locals {
   domain = data.cloudflare_zone.this.name
 }
 
-
-
-
-
-# Regional hostname with no timeouts
-resource "cloudflare_regional_hostname" "minimal" {
-  zone_id    = var.cloudflare_zone_id
-  hostname   = "cftftest-rh-minimal.${local.domain}"
-  region_key = "us"
-
-  depends_on = [cloudflare_dns_record.rh_minimal]
-}
-
-# Regional hostname with timeouts (timeouts should be removed in v5)
-resource "cloudflare_regional_hostname" "with_timeouts" {
-  zone_id    = var.cloudflare_zone_id
-  hostname   = "cftftest-rh-timeouts.${local.domain}"
-  region_key = "eu"
-
-
-  depends_on = [cloudflare_dns_record.rh_timeouts]
-}
-
-# Regional hostname with only create timeout
-resource "cloudflare_regional_hostname" "create_timeout" {
-  zone_id    = var.cloudflare_zone_id
-  hostname   = "cftftest-rh-create-timeout.${local.domain}"
-  region_key = "ca"
-
-
-  depends_on = [cloudflare_dns_record.rh_create_timeout]
-}
-
-resource "cloudflare_regional_hostname" "no_timeouts" {
-  zone_id    = var.cloudflare_zone_id
-  hostname   = "cftftest-rh-no-timeouts.${local.domain}"
-  region_key = "ca"
-
-  depends_on = [cloudflare_dns_record.rh_no_timeouts]
-}
-
 # DNS Records (required before creating regional hostnames)
 resource "cloudflare_dns_record" "rh_minimal" {
   zone_id = var.cloudflare_zone_id
@@ -118,3 +77,40 @@ moved {
   from = cloudflare_record.rh_no_timeouts
   to   = cloudflare_dns_record.rh_no_timeouts
 }
+
+# Regional hostname with no timeouts
+resource "cloudflare_regional_hostname" "minimal" {
+  zone_id    = var.cloudflare_zone_id
+  hostname   = "cftftest-rh-minimal.${local.domain}"
+  region_key = "us"
+
+  depends_on = [cloudflare_dns_record.rh_minimal]
+}
+
+# Regional hostname with timeouts (timeouts should be removed in v5)
+resource "cloudflare_regional_hostname" "with_timeouts" {
+  zone_id    = var.cloudflare_zone_id
+  hostname   = "cftftest-rh-timeouts.${local.domain}"
+  region_key = "eu"
+
+
+  depends_on = [cloudflare_dns_record.rh_timeouts]
+}
+
+# Regional hostname with only create timeout
+resource "cloudflare_regional_hostname" "create_timeout" {
+  zone_id    = var.cloudflare_zone_id
+  hostname   = "cftftest-rh-create-timeout.${local.domain}"
+  region_key = "ca"
+
+
+  depends_on = [cloudflare_dns_record.rh_create_timeout]
+}
+
+resource "cloudflare_regional_hostname" "no_timeouts" {
+  zone_id    = var.cloudflare_zone_id
+  hostname   = "cftftest-rh-no-timeouts.${local.domain}"
+  region_key = "ca"
+
+  depends_on = [cloudflare_dns_record.rh_no_timeouts]
+}
@@ -0,0 +1,4 @@
+# References into a .tf.json file from native syntax are rewritten too.
+output "srv_id" {
+  value = cloudflare_record.srv.id
+}
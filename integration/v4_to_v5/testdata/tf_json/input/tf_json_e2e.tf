@@ -0,0 +1,6 @@
+# The e2e runner only copies native syntax files, so JSON syntax is covered by
+# the integration test only.
+variable "cloudflare_zone_id" {
+  description = "Cloudflare zone ID"
+  type        = string
+}
@@ -210,6 +210,16 @@ resource "cloudflare_workers_script" "segment_proxy" {
   script_name = "${local.name_prefix}-segment-proxy"
 }
 
+resource "cloudflare_workers_script" "legacy_proxy" {
+  account_id  = var.cloudflare_account_id
+  content     = "addEventListener('fetch', event => { event.respondWith(new Response('ok')); });"
+  script_name = "${local.name_prefix}-legacy-proxy"
+}
+
+moved {
+  from = cloudflare_worker_script.legacy_proxy
+  to   = cloudflare_workers_script.legacy_proxy
+}
 
 resource "cloudflare_workers_script" "segment_proxy_indexed" {
   count       = 1
@@ -273,14 +283,3 @@ resource "cloudflare_workers_route" "script_ref_dot_index" {
 # - Special patterns: wildcard, exact paths, query params, special chars
 # - Environment configs: 3 instances (prod/staging/dev)
 ###############################################################################
-
-resource "cloudflare_workers_script" "legacy_proxy" {
-  account_id  = var.cloudflare_account_id
-  content     = "addEventListener('fetch', event => { event.respondWith(new Response('ok')); });"
-  script_name = "${local.name_prefix}-legacy-proxy"
-}
-
-moved {
-  from = cloudflare_worker_script.legacy_proxy
-  to   = cloudflare_workers_script.legacy_proxy
-}
@@ -240,35 +240,6 @@ resource "cloudflare_workers_for_platforms_dispatch_namespace" "depends_derived"
 # These resources use the deprecated v4 name and should be renamed during migration
 # ==============================================================================
 
-
-# Test 36-38: Deprecated resource with for_each (3 instances)
-locals {
-  deprecated_map = {
-    alpha = "${local.name_prefix}-deprecated-alpha"
-    beta  = "${local.name_prefix}-deprecated-beta"
-    gamma = "${local.name_prefix}-deprecated-gamma"
-  }
-}
-
-
-
-
-# ==============================================================================
-# Summary: 42 total resource instances
-# ==============================================================================
-# - Pattern 1 (Basic): 4 instances
-# - Pattern 2 (for_each map): 5 instances
-# - Pattern 3 (for_each set): 5 instances
-# - Pattern 4 (count): 5 instances
-# - Pattern 5 (conditional): 1 instance
-# - Pattern 6 (cross-reference): 2 instances
-# - Pattern 7 (lifecycle): 3 instances
-# - Pattern 8 (functions): 4 instances
-# - Pattern 9 (interpolation): 1 instance
-# - Edge cases: 4 instances
-# - Pattern 10 (deprecated name): 8 instances
-# Total: 42 instances (exceeds 15-30 target)
-
 # Test 35: Basic deprecated resource name
 resource "cloudflare_workers_for_platforms_dispatch_namespace" "deprecated_basic" {
   account_id = var.cloudflare_account_id
@@ -280,6 +251,15 @@ moved {
   to   = cloudflare_workers_for_platforms_dispatch_namespace.deprecated_basic
 }
 
+# Test 36-38: Deprecated resource with for_each (3 instances)
+locals {
+  deprecated_map = {
+    alpha = "${local.name_prefix}-deprecated-alpha"
+    beta  = "${local.name_prefix}-deprecated-beta"
+    gamma = "${local.name_prefix}-deprecated-gamma"
+  }
+}
+
 resource "cloudflare_workers_for_platforms_dispatch_namespace" "deprecated_for_each" {
   for_each   = local.deprecated_map
   account_id = var.cloudflare_account_id
@@ -317,3 +297,19 @@ moved {
   from = cloudflare_workers_for_platforms_namespace.deprecated_lifecycle
   to   = cloudflare_workers_for_platforms_dispatch_namespace.deprecated_lifecycle
 }
+
+# ==============================================================================
+# Summary: 42 total resource instances
+# ==============================================================================
+# - Pattern 1 (Basic): 4 instances
+# - Pattern 2 (for_each map): 5 instances
+# - Pattern 3 (for_each set): 5 instances
+# - Pattern 4 (count): 5 instances
+# - Pattern 5 (conditional): 1 instance
+# - Pattern 6 (cross-reference): 2 instances
+# - Pattern 7 (lifecycle): 3 instances
+# - Pattern 8 (functions): 4 instances
+# - Pattern 9 (interpolation): 1 instance
+# - Edge cases: 4 instances
+# - Pattern 10 (deprecated name): 8 instances
+# Total: 42 instances (exceeds 15-30 target)
@@ -503,6 +503,24 @@ resource "cloudflare_workers_script" "local_refs" {
 # Tests resource rename: cloudflare_worker_script → cloudflare_workers_script
 # ========================================
 
+resource "cloudflare_workers_script" "singular_name" {
+  account_id = var.cloudflare_account_id
+  content    = local.common_content
+
+  script_name = "cftftest-singular-resource"
+  bindings = [
+    {
+      type = "plain_text"
+      name = "SINGULAR"
+      text = "true"
+    }
+  ]
+}
+
+moved {
+  from = cloudflare_worker_script.singular_name
+  to   = cloudflare_workers_script.singular_name
+}
 
 # ========================================
 # Pattern 27: Complex for_each with list transformation
@@ -616,22 +634,3 @@ resource "cloudflare_workers_script" "dynamic_kv" {
       namespace_id = cloudflare_workers_kv_namespace.test_kv.id
   }] : [])
 }
-
-resource "cloudflare_workers_script" "singular_name" {
-  account_id = var.cloudflare_account_id
-  content    = local.common_content
-
-  script_name = "cftftest-singular-resource"
-  bindings = [
-    {
-      type = "plain_text"
-      name = "SINGULAR"
-      text = "true"
-    }
-  ]
-}
-
-moved {
-  from = cloudflare_worker_script.singular_name
-  to   = cloudflare_workers_script.singular_name
-}
@@ -430,6 +430,19 @@ resource "cloudflare_zero_trust_access_application" "special_chars" {
 # v4 name option 2: cloudflare_zero_trust_access_application
 # v5 name: cloudflare_zero_trust_access_application
 
+# Resource using v4 name option 1
+resource "cloudflare_zero_trust_access_application" "resourcename_opt1" {
+  account_id                 = var.cloudflare_account_id
+  name                       = "${local.name_prefix} Pattern9 Option1"
+  domain                     = "pattern9-opt1.${local.app_domain_suffix}"
+  type                       = "self_hosted"
+  http_only_cookie_attribute = false
+}
+
+moved {
+  from = cloudflare_access_application.resourcename_opt1
+  to   = cloudflare_zero_trust_access_application.resourcename_opt1
+}
 
 # Resource using v4 name option 2
 resource "cloudflare_zero_trust_access_application" "resourcename_opt2" {
@@ -454,18 +467,3 @@ resource "cloudflare_zero_trust_access_application" "self_hosted_with_saas_app"
 
   http_only_cookie_attribute = false
 }
-
-# Resource using v4 name option 1
-resource "cloudflare_zero_trust_access_application" "resourcename_opt1" {
-  account_id                 = var.cloudflare_account_id
-  name                       = "${local.name_prefix} Pattern9 Option1"
-  domain                     = "pattern9-opt1.${local.app_domain_suffix}"
-  type                       = "self_hosted"
-  http_only_cookie_attribute = false
-}
-
-moved {
-  from = cloudflare_access_application.resourcename_opt1
-  to   = cloudflare_zero_trust_access_application.resourcename_opt1
-}
-
@@ -41,95 +41,6 @@ locals {
 # 1. BASIC RESOURCES
 ##########################
 
-
-
-
-
-
-
-##########################
-# 2. FOR_EACH PATTERNS
-##########################
-
-
-
-
-##########################
-# 3. COUNT PATTERN
-##########################
-
-
-##########################
-# 4. CONDITIONAL RESOURCES
-##########################
-
-variable "enable_backup_cert" {
-  type    = bool
-  default = true
-}
-
-
-##########################
-# 5. VARIABLE REFERENCES
-##########################
-
-
-##########################
-# 6. TERRAFORM FUNCTIONS
-##########################
-
-
-
-##########################
-# 7. CROSS-RESOURCE REFERENCES
-##########################
-
-
-
-##########################
-# 8. EDGE CASES
-##########################
-
-
-
-
-##########################
-# 9. LIFECYCLE META-ARGUMENTS
-##########################
-
-
-
-##########################
-# 10. DEPENDS_ON
-##########################
-
-
-##########################
-# 11. PATTERN 9: CROSS-RESOURCE REFERENCES WITH BOTH V4 NAMES
-##########################
-# This validates that GetResourceRename() returns ALL v4 names for cross-file reference updates
-# v4 name option 1: cloudflare_access_mutual_tls_certificate
-# v4 name option 2: cloudflare_zero_trust_access_mtls_certificate
-# v5 name: cloudflare_zero_trust_access_mtls_certificate
-
-
-
-
-
-# Summary: This file contains 30+ resource instances covering:
-# - Basic configurations (6 resources)
-# - for_each patterns with maps and sets (9 resources)
-# - count patterns (3 resources)
-# - Conditional resources (1 resource)
-# - Variable references (1 resource)
-# - Terraform functions (2 resources)
-# - Cross-resource references (2 resources)
-# - Edge cases (3 resources)
-# - Lifecycle meta-arguments (2 resources)
-# - Dependencies (1 resource)
-# - Pattern 9: Cross-resource references with both v4 names (4 resources)
-# Total: 34 resource instances
-
 # Basic certificate with account_id and all fields
 resource "cloudflare_zero_trust_access_mtls_certificate" "basic_account" {
   account_id  = var.cloudflare_account_id
@@ -218,6 +129,10 @@ moved {
   to   = cloudflare_zero_trust_access_mtls_certificate.many_hostnames
 }
 
+##########################
+# 2. FOR_EACH PATTERNS
+##########################
+
 # for_each with map - different environments
 resource "cloudflare_zero_trust_access_mtls_certificate" "environments" {
   for_each = local.cert_names
@@ -267,6 +182,10 @@ moved {
   to   = cloudflare_zero_trust_access_mtls_certificate.regions
 }
 
+##########################
+# 3. COUNT PATTERN
+##########################
+
 # count-based resources
 resource "cloudflare_zero_trust_access_mtls_certificate" "counted" {
   count = 3
@@ -284,6 +203,15 @@ moved {
   to   = cloudflare_zero_trust_access_mtls_certificate.counted
 }
 
+##########################
+# 4. CONDITIONAL RESOURCES
+##########################
+
+variable "enable_backup_cert" {
+  type    = bool
+  default = true
+}
+
 # Conditional resource using count
 resource "cloudflare_zero_trust_access_mtls_certificate" "conditional" {
   count = var.enable_backup_cert ? 1 : 0
@@ -299,6 +227,10 @@ moved {
   to   = cloudflare_zero_trust_access_mtls_certificate.conditional
 }
 
+##########################
+# 5. VARIABLE REFERENCES
+##########################
+
 # Using variables for all values
 resource "cloudflare_zero_trust_access_mtls_certificate" "variable_ref" {
   account_id           = var.cloudflare_account_id
@@ -312,6 +244,10 @@ moved {
   to   = cloudflare_zero_trust_access_mtls_certificate.variable_ref
 }
 
+##########################
+# 6. TERRAFORM FUNCTIONS
+##########################
+
 # Using join() function
 resource "cloudflare_zero_trust_access_mtls_certificate" "with_join" {
   account_id  = var.cloudflare_account_id
@@ -340,6 +276,10 @@ moved {
   to   = cloudflare_zero_trust_access_mtls_certificate.with_format
 }
 
+##########################
+# 7. CROSS-RESOURCE REFERENCES
+##########################
+
 # These would reference other resources in real scenarios
 # For integration tests, we use hardcoded values
 resource "cloudflare_zero_trust_access_mtls_certificate" "app_cert" {
@@ -367,6 +307,10 @@ moved {
   to   = cloudflare_zero_trust_access_mtls_certificate.backend_cert
 }
 
+##########################
+# 8. EDGE CASES
+##########################
+
 # Very long certificate name
 resource "cloudflare_zero_trust_access_mtls_certificate" "long_name" {
   account_id  = var.cloudflare_account_id
@@ -405,6 +349,10 @@ moved {
   to   = cloudflare_zero_trust_access_mtls_certificate.zone_scoped
 }
 
+##########################
+# 9. LIFECYCLE META-ARGUMENTS
+##########################
+
 # Certificate with create_before_destroy
 resource "cloudflare_zero_trust_access_mtls_certificate" "with_lifecycle" {
   account_id  = var.cloudflare_account_id
@@ -438,6 +386,10 @@ moved {
   to   = cloudflare_zero_trust_access_mtls_certificate.ignore_changes
 }
 
+##########################
+# 10. DEPENDS_ON
+##########################
+
 # Certificate with explicit dependency
 resource "cloudflare_zero_trust_access_mtls_certificate" "dependent" {
   account_id           = var.cloudflare_account_id
@@ -455,6 +407,14 @@ moved {
   to   = cloudflare_zero_trust_access_mtls_certificate.dependent
 }
 
+##########################
+# 11. PATTERN 9: CROSS-RESOURCE REFERENCES WITH BOTH V4 NAMES
+##########################
+# This validates that GetResourceRename() returns ALL v4 names for cross-file reference updates
+# v4 name option 1: cloudflare_access_mutual_tls_certificate
+# v4 name option 2: cloudflare_zero_trust_access_mtls_certificate
+# v5 name: cloudflare_zero_trust_access_mtls_certificate
+
 # Resource using v4 name option 1
 resource "cloudflare_zero_trust_access_mtls_certificate" "resourcename_opt1" {
   account_id           = var.cloudflare_account_id
@@ -507,3 +467,17 @@ moved {
   from = cloudflare_access_application.ref_opt2
   to   = cloudflare_zero_trust_access_application.ref_opt2
 }
+
+# Summary: This file contains 30+ resource instances covering:
+# - Basic configurations (6 resources)
+# - for_each patterns with maps and sets (9 resources)
+# - count patterns (3 resources)
+# - Conditional resources (1 resource)
+# - Variable references (1 resource)
+# - Terraform functions (2 resources)
+# - Cross-resource references (2 resources)
+# - Edge cases (3 resources)
+# - Lifecycle meta-arguments (2 resources)
+# - Dependencies (1 resource)
+# - Pattern 9: Cross-resource references with both v4 names (4 resources)
+# Total: 34 resource instances
@@ -282,14 +282,11 @@ variable "services" {
 resource "cloudflare_zero_trust_access_mtls_hostname_settings" "services" {
   account_id = var.cloudflare_account_id
 
-  dynamic "settings" {
-    for_each = var.services
-    content {
-      hostname                      = "${local.name_prefix}-${settings.value.name}.${local.base_domain}"
-      china_network                 = settings.value.china_enabled
-      client_certificate_forwarding = settings.value.forward_enabled
-    }
-  }
+  settings = [for value in var.services : {
+    hostname                      = "${local.name_prefix}-${value.name}.${local.base_domain}"
+    china_network                 = value.china_enabled
+    client_certificate_forwarding = value.forward_enabled
+  }]
 }
 
 # Pattern 9: Lifecycle meta-arguments
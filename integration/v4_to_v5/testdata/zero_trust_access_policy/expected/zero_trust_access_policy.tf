@@ -30,100 +30,6 @@ locals {
 # Application-specific policies (with application_id in v4) cannot be migrated
 # as they use different API endpoints and are fundamentally different resources.
 
-
-
-
-
-
-
-
-
-
-
-# Pattern Group 8: Edge Cases
-
-
-
-
-
-
-
-
-
-
-
-# ============================================================
-# Research team issue reproductions (TKT-002 through TKT-006)
-# ============================================================
-
-
-
-
-
-
-
-
-
-
-# TKT-003: application_id + precedence must be removed from policy
-# (mirrors research team's app_azul_mtc_worker.tf)
-# In v4, application-scoped policies had application_id + precedence.
-# In v5, application_id and precedence are removed; the binding is done
-# via the cloudflare_zero_trust_access_application.policies block.
-# tf-migrate removes application_id and precedence with a warning.
-resource "cloudflare_zero_trust_access_application" "test_app" {
-  account_id                 = var.cloudflare_account_id
-  name                       = "${local.name_prefix}-test-app"
-  domain                     = "test.${var.cloudflare_domain}"
-  type                       = "self_hosted"
-  http_only_cookie_attribute = false
-}
-
-
-# ============================================================================
-# BUGS-2006: Already-v5-named resources with block syntax not converted
-# These resources already have the v5 name but nested blocks are still in
-# v4 block syntax — tf-migrate must still convert them.
-# ============================================================================
-
-# Already v5-named: simple include block with email
-resource "cloudflare_zero_trust_access_policy" "bugs2006_email" {
-  account_id = var.cloudflare_account_id
-  name       = "${local.name_prefix}-bugs2006-email"
-  decision   = "allow"
-
-  include = [{ email = { email = "sara@example.com" } }]
-}
-
-# Already v5-named: multiple condition blocks
-resource "cloudflare_zero_trust_access_policy" "bugs2006_multi_condition" {
-  account_id = var.cloudflare_account_id
-  name       = "${local.name_prefix}-bugs2006-multi"
-  decision   = "allow"
-
-
-
-  include = [{ email_domain = { domain = "cloudflare.com" } }]
-  exclude = [{ geo = { country_code = "CN" } },
-  { geo = { country_code = "RU" } }]
-  require = [{ certificate = {} }]
-}
-
-# Already v5-named: everyone boolean condition
-resource "cloudflare_zero_trust_access_policy" "bugs2006_everyone" {
-  account_id = var.cloudflare_account_id
-  name       = "${local.name_prefix}-bugs2006-everyone"
-  decision   = "allow"
-
-  include = [{ everyone = {} }]
-}
-
-
-
-
-
-
-
 # Basic test cases
 resource "cloudflare_zero_trust_access_policy" "example" {
   account_id       = var.cloudflare_account_id
@@ -316,6 +222,8 @@ moved {
   to   = cloudflare_zero_trust_access_policy.with_prevent_destroy
 }
 
+# Pattern Group 8: Edge Cases
+
 # Minimal resource (only required fields)
 resource "cloudflare_zero_trust_access_policy" "minimal" {
   account_id       = var.cloudflare_account_id
@@ -490,6 +398,10 @@ moved {
   to   = cloudflare_zero_trust_access_policy.bypass_policy
 }
 
+# ============================================================
+# Research team issue reproductions (TKT-002 through TKT-006)
+# ============================================================
+
 # TKT-002: include/exclude/require block → attribute list conversion
 # TKT-005: email_domain from list to {domain = ...} object
 resource "cloudflare_zero_trust_access_policy" "email_domain_policy" {
@@ -622,6 +534,20 @@ moved {
   to   = cloudflare_zero_trust_access_policy.combined_research_team_policy
 }
 
+# TKT-003: application_id + precedence must be removed from policy
+# (mirrors research team's app_azul_mtc_worker.tf)
+# In v4, application-scoped policies had application_id + precedence.
+# In v5, application_id and precedence are removed; the binding is done
+# via the cloudflare_zero_trust_access_application.policies block.
+# tf-migrate removes application_id and precedence with a warning.
+resource "cloudflare_zero_trust_access_application" "test_app" {
+  account_id                 = var.cloudflare_account_id
+  name                       = "${local.name_prefix}-test-app"
+  domain                     = "test.${var.cloudflare_domain}"
+  type                       = "self_hosted"
+  http_only_cookie_attribute = false
+}
+
 removed {
   from = cloudflare_access_policy.app_scoped_policy
   lifecycle {
@@ -634,6 +560,44 @@ removed {
   # MIGRATION WARNING: This is NOT recoverable without reconstructing policies from git history or backups.
 }
 
+# ============================================================================
+# BUGS-2006: Already-v5-named resources with block syntax not converted
+# These resources already have the v5 name but nested blocks are still in
+# v4 block syntax — tf-migrate must still convert them.
+# ============================================================================
+
+# Already v5-named: simple include block with email
+resource "cloudflare_zero_trust_access_policy" "bugs2006_email" {
+  account_id = var.cloudflare_account_id
+  name       = "${local.name_prefix}-bugs2006-email"
+  decision   = "allow"
+
+  include = [{ email = { email = "sara@example.com" } }]
+}
+
+# Already v5-named: multiple condition blocks
+resource "cloudflare_zero_trust_access_policy" "bugs2006_multi_condition" {
+  account_id = var.cloudflare_account_id
+  name       = "${local.name_prefix}-bugs2006-multi"
+  decision   = "allow"
+
+
+
+  include = [{ email_domain = { domain = "cloudflare.com" } }]
+  exclude = [{ geo = { country_code = "CN" } },
+  { geo = { country_code = "RU" } }]
+  require = [{ certificate = {} }]
+}
+
+# Already v5-named: everyone boolean condition
+resource "cloudflare_zero_trust_access_policy" "bugs2006_everyone" {
+  account_id = var.cloudflare_account_id
+  name       = "${local.name_prefix}-bugs2006-everyone"
+  decision   = "allow"
+
+  include = [{ everyone = {} }]
+}
+
 # BUGS-2007: nested and list selector migrations
 resource "cloudflare_zero_trust_access_policy" "bugs2007_lists" {
   account_id = var.cloudflare_account_id
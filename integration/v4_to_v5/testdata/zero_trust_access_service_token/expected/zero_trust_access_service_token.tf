@@ -190,6 +190,19 @@ resource "cloudflare_zero_trust_access_service_token" "zero_values" {
   duration   = "8760h"
 }
 
+# Legacy resource name (cloudflare_access_service_token - deprecated)
+resource "cloudflare_zero_trust_access_service_token" "legacy_name" {
+  account_id                        = var.cloudflare_account_id
+  name                              = "${local.name_prefix}-legacy-name-token"
+  duration                          = "8760h"
+  client_secret_version             = 2
+  previous_client_secret_expires_at = "2024-12-31T23:59:59Z"
+}
+
+moved {
+  from = cloudflare_access_service_token.legacy_name
+  to   = cloudflare_zero_trust_access_service_token.legacy_name
+}
 
 # Resource without min_days_for_renewal (deprecated field)
 resource "cloudflare_zero_trust_access_service_token" "without_deprecated" {
@@ -236,6 +249,17 @@ resource "cloudflare_zero_trust_access_service_token" "max_duration" {
 # v4 name option 2: cloudflare_zero_trust_access_service_token
 # v5 name: cloudflare_zero_trust_access_service_token
 
+# Resource using v4 name option 1
+resource "cloudflare_zero_trust_access_service_token" "resourcename_opt1" {
+  account_id = var.cloudflare_account_id
+  name       = "${local.name_prefix}-pattern9-opt1-token"
+  duration   = "8760h"
+}
+
+moved {
+  from = cloudflare_access_service_token.resourcename_opt1
+  to   = cloudflare_zero_trust_access_service_token.resourcename_opt1
+}
 
 # Resource using v4 name option 2
 resource "cloudflare_zero_trust_access_service_token" "resourcename_opt2" {
@@ -265,29 +289,3 @@ resource "cloudflare_zero_trust_access_application" "ref_opt2" {
   depends_on                 = [cloudflare_zero_trust_access_service_token.resourcename_opt2]
   http_only_cookie_attribute = false
 }
-
-# Legacy resource name (cloudflare_access_service_token - deprecated)
-resource "cloudflare_zero_trust_access_service_token" "legacy_name" {
-  account_id                        = var.cloudflare_account_id
-  name                              = "${local.name_prefix}-legacy-name-token"
-  duration                          = "8760h"
-  client_secret_version             = 2
-  previous_client_secret_expires_at = "2024-12-31T23:59:59Z"
-}
-
-moved {
-  from = cloudflare_access_service_token.legacy_name
-  to   = cloudflare_zero_trust_access_service_token.legacy_name
-}
-
-# Resource using v4 name option 1
-resource "cloudflare_zero_trust_access_service_token" "resourcename_opt1" {
-  account_id = var.cloudflare_account_id
-  name       = "${local.name_prefix}-pattern9-opt1-token"
-  duration   = "8760h"
-}
-
-moved {
-  from = cloudflare_access_service_token.resourcename_opt1
-  to   = cloudflare_zero_trust_access_service_token.resourcename_opt1
-}
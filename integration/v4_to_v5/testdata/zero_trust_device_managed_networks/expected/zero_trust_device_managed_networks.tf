@@ -38,51 +38,6 @@ locals {
   }
 }
 
-
-
-
-
-locals {
-  enable_test_network = true
-  enable_dev_network  = false
-}
-
-
-
-
-
-
-
-
-variable "custom_network_name" {
-  type    = string
-  default = "custom"
-}
-
-
-# ============================================================================
-# Pattern 9: Cross-resource reference using both v4 names
-# ============================================================================
-# This validates that GetResourceRename() returns ALL v4 names for cross-file reference updates
-# v4 name option 1: cloudflare_device_managed_networks
-# v4 name option 2: cloudflare_zero_trust_device_managed_networks
-# v5 name: cloudflare_zero_trust_device_managed_networks
-
-
-# Resource using v4 name option 2
-resource "cloudflare_zero_trust_device_managed_networks" "resourcename_opt2" {
-  account_id = var.cloudflare_account_id
-  name       = "${local.name_prefix}-pattern9-opt2"
-  type       = "tls"
-
-  config = {
-    tls_sockaddr = "pattern9-opt2.cf-tf-test.com:443"
-    sha256       = "bb22222222222222222222222222222222222222222222222222222222222222"
-  }
-}
-
-
-
 resource "cloudflare_zero_trust_device_managed_networks" "basic" {
   account_id = var.cloudflare_account_id
   name       = "${local.name_prefix}-basic-network"
@@ -153,6 +108,11 @@ moved {
   to   = cloudflare_zero_trust_device_managed_networks.counted
 }
 
+locals {
+  enable_test_network = true
+  enable_dev_network  = false
+}
+
 resource "cloudflare_zero_trust_device_managed_networks" "conditional_enabled" {
   count = local.enable_test_network ? 1 : 0
 
@@ -273,6 +233,11 @@ moved {
   to   = cloudflare_zero_trust_device_managed_networks.special_hash
 }
 
+variable "custom_network_name" {
+  type    = string
+  default = "custom"
+}
+
 resource "cloudflare_zero_trust_device_managed_networks" "with_variables" {
   account_id = var.cloudflare_account_id
   name       = "${local.name_prefix}-${var.custom_network_name}"
@@ -289,6 +254,14 @@ moved {
   to   = cloudflare_zero_trust_device_managed_networks.with_variables
 }
 
+# ============================================================================
+# Pattern 9: Cross-resource reference using both v4 names
+# ============================================================================
+# This validates that GetResourceRename() returns ALL v4 names for cross-file reference updates
+# v4 name option 1: cloudflare_device_managed_networks
+# v4 name option 2: cloudflare_zero_trust_device_managed_networks
+# v5 name: cloudflare_zero_trust_device_managed_networks
+
 # Resource using v4 name option 1
 resource "cloudflare_zero_trust_device_managed_networks" "resourcename_opt1" {
   account_id = var.cloudflare_account_id
@@ -306,6 +279,18 @@ moved {
   to   = cloudflare_zero_trust_device_managed_networks.resourcename_opt1
 }
 
+# Resource using v4 name option 2
+resource "cloudflare_zero_trust_device_managed_networks" "resourcename_opt2" {
+  account_id = var.cloudflare_account_id
+  name       = "${local.name_prefix}-pattern9-opt2"
+  type       = "tls"
+
+  config = {
+    tls_sockaddr = "pattern9-opt2.cf-tf-test.com:443"
+    sha256       = "bb22222222222222222222222222222222222222222222222222222222222222"
+  }
+}
+
 # Dependent resource that references option 1
 resource "cloudflare_zero_trust_device_custom_profile" "ref_opt1" {
   account_id  = var.cloudflare_account_id
@@ -23,115 +23,6 @@ locals {
   ws1_auth_url       = "https://na.uemauth.vmwservices.com/connect/token"
 }
 
-
-# Pattern 4: for_each with sets (3-5 items)
-resource "cloudflare_zero_trust_device_posture_integration" "set_integrations" {
-  for_each = toset(["intune", "kolide", "sentinelone_s2s"])
-
-  account_id = var.cloudflare_account_id
-  name       = "${local.integration_prefix}-${each.key}"
-  type       = each.key
-  interval   = "24h"
-
-  config = {
-    client_id     = "${each.key}-client"
-    client_secret = "${each.key}-secret"
-  }
-}
-
-
-# Pattern 6: Conditional resource creation (count with ternary)
-resource "cloudflare_zero_trust_device_posture_integration" "conditional" {
-  count = var.enable_integrations ? 2 : 0
-
-  account_id = var.cloudflare_account_id
-  name       = "${local.integration_prefix}-conditional-${count.index}"
-  type       = "tanium_s2s"
-
-  interval = "24h"
-  config = {
-    api_url       = "https://tanium-${count.index}.example.com"
-    client_secret = "tanium-secret-${count.index}"
-  }
-}
-
-
-resource "cloudflare_zero_trust_device_posture_integration" "secondary" {
-  # References the primary integration's name
-  account_id = var.cloudflare_account_id
-  name       = "${cloudflare_zero_trust_device_posture_integration.primary.name}-secondary"
-  type       = "crowdstrike_s2s"
-  interval   = cloudflare_zero_trust_device_posture_integration.primary.interval
-
-  config = {
-    client_id     = "secondary-client"
-    client_secret = "secondary-secret"
-    customer_id   = "secondary-customer"
-  }
-}
-
-
-resource "cloudflare_zero_trust_device_posture_integration" "prevent_destroy" {
-  account_id = var.cloudflare_account_id
-  name       = "${local.integration_prefix}-prevent-destroy"
-  type       = "kolide"
-  interval   = "24h"
-
-
-  lifecycle {
-    prevent_destroy = true
-  }
-  config = {
-    client_id            = "prevent-client"
-    client_secret        = "prevent-secret"
-    access_client_id     = "prevent-access-id"
-    access_client_secret = "prevent-access-secret"
-  }
-}
-
-
-# Additional edge cases
-resource "cloudflare_zero_trust_device_posture_integration" "minimal" {
-  account_id = var.cloudflare_account_id
-  name       = "${local.integration_prefix}-minimal"
-  type       = "custom_s2s"
-  interval   = "30m"
-
-  config = {
-    api_url = "https://minimal.example.com"
-  }
-}
-
-
-resource "cloudflare_zero_trust_device_posture_integration" "no_interval" {
-  account_id = var.cloudflare_account_id
-  name       = "${local.integration_prefix}-no-interval"
-  type       = "intune"
-
-  interval = "24h"
-  config = {
-    client_id     = "no-interval-client"
-    client_secret = "no-interval-secret"
-  }
-}
-
-
-# Total resource instances:
-# - map_integrations: 3 (workspace_one, crowdstrike, uptycs)
-# - set_integrations: 3 (intune, kolide, sentinelone_s2s)
-# - count_integrations: 3
-# - conditional: 2
-# - primary: 1
-# - secondary: 1
-# - lifecycle_test: 1
-# - prevent_destroy: 1
-# - function_test: 1
-# - minimal: 1
-# - all_fields: 1
-# - no_interval: 1
-# - dynamic_test: 1
-# TOTAL: 20 resource instances
-
 # Pattern 3: for_each with maps (3-5 resources)
 resource "cloudflare_zero_trust_device_posture_integration" "map_integrations" {
   for_each = {
@@ -179,6 +70,21 @@ moved {
   to   = cloudflare_zero_trust_device_posture_integration.map_integrations
 }
 
+# Pattern 4: for_each with sets (3-5 items)
+resource "cloudflare_zero_trust_device_posture_integration" "set_integrations" {
+  for_each = toset(["intune", "kolide", "sentinelone_s2s"])
+
+  account_id = var.cloudflare_account_id
+  name       = "${local.integration_prefix}-${each.key}"
+  type       = each.key
+  interval   = "24h"
+
+  config = {
+    client_id     = "${each.key}-client"
+    client_secret = "${each.key}-secret"
+  }
+}
+
 # Pattern 5: count-based resources (at least 3)
 resource "cloudflare_zero_trust_device_posture_integration" "count_integrations" {
   count = 3
@@ -200,6 +106,21 @@ moved {
   to   = cloudflare_zero_trust_device_posture_integration.count_integrations
 }
 
+# Pattern 6: Conditional resource creation (count with ternary)
+resource "cloudflare_zero_trust_device_posture_integration" "conditional" {
+  count = var.enable_integrations ? 2 : 0
+
+  account_id = var.cloudflare_account_id
+  name       = "${local.integration_prefix}-conditional-${count.index}"
+  type       = "tanium_s2s"
+
+  interval = "24h"
+  config = {
+    api_url       = "https://tanium-${count.index}.example.com"
+    client_secret = "tanium-secret-${count.index}"
+  }
+}
+
 # Pattern 7: Cross-resource references
 resource "cloudflare_zero_trust_device_posture_integration" "primary" {
   account_id = var.cloudflare_account_id
@@ -220,6 +141,20 @@ moved {
   to   = cloudflare_zero_trust_device_posture_integration.primary
 }
 
+resource "cloudflare_zero_trust_device_posture_integration" "secondary" {
+  # References the primary integration's name
+  account_id = var.cloudflare_account_id
+  name       = "${cloudflare_zero_trust_device_posture_integration.primary.name}-secondary"
+  type       = "crowdstrike_s2s"
+  interval   = cloudflare_zero_trust_device_posture_integration.primary.interval
+
+  config = {
+    client_id     = "secondary-client"
+    client_secret = "secondary-secret"
+    customer_id   = "secondary-customer"
+  }
+}
+
 # Pattern 8: Lifecycle meta-arguments
 resource "cloudflare_zero_trust_device_posture_integration" "lifecycle_test" {
   account_id = var.cloudflare_account_id
@@ -246,6 +181,24 @@ moved {
   to   = cloudflare_zero_trust_device_posture_integration.lifecycle_test
 }
 
+resource "cloudflare_zero_trust_device_posture_integration" "prevent_destroy" {
+  account_id = var.cloudflare_account_id
+  name       = "${local.integration_prefix}-prevent-destroy"
+  type       = "kolide"
+  interval   = "24h"
+
+
+  lifecycle {
+    prevent_destroy = true
+  }
+  config = {
+    client_id            = "prevent-client"
+    client_secret        = "prevent-secret"
+    access_client_id     = "prevent-access-id"
+    access_client_secret = "prevent-access-secret"
+  }
+}
+
 # Pattern 9: Terraform functions
 resource "cloudflare_zero_trust_device_posture_integration" "function_test" {
   account_id = var.cloudflare_account_id
@@ -266,6 +219,18 @@ moved {
   to   = cloudflare_zero_trust_device_posture_integration.function_test
 }
 
+# Additional edge cases
+resource "cloudflare_zero_trust_device_posture_integration" "minimal" {
+  account_id = var.cloudflare_account_id
+  name       = "${local.integration_prefix}-minimal"
+  type       = "custom_s2s"
+  interval   = "30m"
+
+  config = {
+    api_url = "https://minimal.example.com"
+  }
+}
+
 resource "cloudflare_zero_trust_device_posture_integration" "all_fields" {
   account_id = var.cloudflare_account_id
   name       = "${local.integration_prefix}-all-fields"
@@ -289,6 +254,18 @@ moved {
   to   = cloudflare_zero_trust_device_posture_integration.all_fields
 }
 
+resource "cloudflare_zero_trust_device_posture_integration" "no_interval" {
+  account_id = var.cloudflare_account_id
+  name       = "${local.integration_prefix}-no-interval"
+  type       = "intune"
+
+  interval = "24h"
+  config = {
+    client_id     = "no-interval-client"
+    client_secret = "no-interval-secret"
+  }
+}
+
 # Dynamic block example
 resource "cloudflare_zero_trust_device_posture_integration" "dynamic_test" {
   account_id = var.cloudflare_account_id
@@ -310,3 +287,19 @@ moved {
   from = cloudflare_device_posture_integration.dynamic_test
   to   = cloudflare_zero_trust_device_posture_integration.dynamic_test
 }
+
+# Total resource instances:
+# - map_integrations: 3 (workspace_one, crowdstrike, uptycs)
+# - set_integrations: 3 (intune, kolide, sentinelone_s2s)
+# - count_integrations: 3
+# - conditional: 2
+# - primary: 1
+# - secondary: 1
+# - lifecycle_test: 1
+# - prevent_destroy: 1
+# - function_test: 1
+# - minimal: 1
+# - all_fields: 1
+# - no_interval: 1
+# - dynamic_test: 1
+# TOTAL: 20 resource instances
@@ -29,96 +29,6 @@ locals {
 # Pattern Group 2: for_each with Maps (5 resources)
 # ============================================================================
 
-
-# ============================================================================
-# Pattern Group 3: for_each with Sets (4 items)
-# ============================================================================
-
-
-# ============================================================================
-# Pattern Group 4: count-based Resources (3 instances)
-# ============================================================================
-
-
-# ============================================================================
-# Pattern Group 5: Conditional Creation
-# ============================================================================
-
-
-
-# ============================================================================
-# Pattern Group 7: Terraform Functions
-# ============================================================================
-
-
-
-# ============================================================================
-# Pattern Group 8: Lifecycle Meta-Arguments
-# ============================================================================
-
-
-
-# ============================================================================
-# Pattern Group 9: Edge Cases
-# ============================================================================
-
-
-
-
-# ============================================================================
-# Original Test Cases (Comprehensive Coverage)
-# ============================================================================
-
-
-
-
-
-
-
-# ============================================================================
-# Pattern Group 9: Cross-File References (Resource Rename Test)
-# ============================================================================
-
-# Pattern 9 tests that cross-file references are updated when resource names change.
-# The migrator renames:
-#   cloudflare_device_posture_rule -> cloudflare_zero_trust_device_posture_rule
-#   cloudflare_zero_trust_device_posture_rule -> cloudflare_zero_trust_device_posture_rule (no-op)
-# We create dependent resources (gateway policies) that reference these posture rules via depends_on.
-# After migration, the references must be updated to use the new resource names.
-
-
-
-# Dependent resources that reference the above posture rules
-# Using realistic resources that would depend on posture rules
-
-# Gateway policy depending on old-name posture rule
-# Note: Using high precedence values (100000+) to avoid conflicts with zero_trust_gateway_policy tests
-resource "cloudflare_zero_trust_gateway_policy" "depends_on_old_posture" {
-  account_id  = var.cloudflare_account_id
-  name        = "cftftest Gateway Policy - Old Posture Rule"
-  description = "Policy depending on old-name posture rule"
-  action      = "block"
-  precedence  = 100000
-  enabled     = true
-  traffic     = "any(dns.domains[*] == \"example-old.com\")"
-
-  depends_on = [cloudflare_zero_trust_device_posture_rule.ref_source_old_name]
-}
-
-# Gateway policy depending on new-name posture rule
-# Note: Using high precedence values (100000+) to avoid conflicts with zero_trust_gateway_policy tests
-resource "cloudflare_zero_trust_gateway_policy" "depends_on_new_posture" {
-  account_id  = var.cloudflare_account_id
-  name        = "cftftest Gateway Policy - New Posture Rule"
-  description = "Policy depending on new-name posture rule"
-  action      = "allow"
-  precedence  = 100001
-  enabled     = true
-  traffic     = "any(dns.domains[*] == \"example-new.com\")"
-
-  depends_on = [cloudflare_zero_trust_device_posture_rule.ref_source_new_name]
-}
-
 resource "cloudflare_zero_trust_device_posture_rule" "map_example" {
   for_each = {
     "prod" = {
@@ -185,6 +95,10 @@ moved {
   to   = cloudflare_zero_trust_device_posture_rule.map_example
 }
 
+# ============================================================================
+# Pattern Group 3: for_each with Sets (4 items)
+# ============================================================================
+
 resource "cloudflare_zero_trust_device_posture_rule" "set_example" {
   for_each = toset([
     "alpha",
@@ -212,6 +126,10 @@ moved {
   to   = cloudflare_zero_trust_device_posture_rule.set_example
 }
 
+# ============================================================================
+# Pattern Group 4: count-based Resources (3 instances)
+# ============================================================================
+
 resource "cloudflare_zero_trust_device_posture_rule" "counted" {
   count = 3
 
@@ -236,6 +154,10 @@ moved {
   to   = cloudflare_zero_trust_device_posture_rule.counted
 }
 
+# ============================================================================
+# Pattern Group 5: Conditional Creation
+# ============================================================================
+
 resource "cloudflare_zero_trust_device_posture_rule" "conditional_enabled" {
   count = local.enable_firewall_rules ? 1 : 0
 
@@ -283,6 +205,10 @@ moved {
   to   = cloudflare_zero_trust_device_posture_rule.conditional_disabled
 }
 
+# ============================================================================
+# Pattern Group 7: Terraform Functions
+# ============================================================================
+
 resource "cloudflare_zero_trust_device_posture_rule" "with_functions" {
   account_id = local.common_account
 
@@ -329,6 +255,10 @@ moved {
   to   = cloudflare_zero_trust_device_posture_rule.with_interpolation
 }
 
+# ============================================================================
+# Pattern Group 8: Lifecycle Meta-Arguments
+# ============================================================================
+
 resource "cloudflare_zero_trust_device_posture_rule" "with_lifecycle" {
   account_id  = var.cloudflare_account_id
   name        = "${local.name_prefix}-lifecycle-test-rule"
@@ -380,6 +310,10 @@ moved {
   to   = cloudflare_zero_trust_device_posture_rule.with_prevent_destroy
 }
 
+# ============================================================================
+# Pattern Group 9: Edge Cases
+# ============================================================================
+
 # Minimal resource (only required fields)
 resource "cloudflare_zero_trust_device_posture_rule" "minimal" {
   account_id = var.cloudflare_account_id
@@ -451,6 +385,10 @@ moved {
   to   = cloudflare_zero_trust_device_posture_rule.with_nulls
 }
 
+# ============================================================================
+# Original Test Cases (Comprehensive Coverage)
+# ============================================================================
+
 # Test case 1: Basic os_version rule with input and match
 resource "cloudflare_zero_trust_device_posture_rule" "basic" {
   account_id  = var.cloudflare_account_id
@@ -591,6 +529,17 @@ moved {
   to   = cloudflare_zero_trust_device_posture_rule.domain_joined
 }
 
+# ============================================================================
+# Pattern Group 9: Cross-File References (Resource Rename Test)
+# ============================================================================
+
+# Pattern 9 tests that cross-file references are updated when resource names change.
+# The migrator renames:
+#   cloudflare_device_posture_rule -> cloudflare_zero_trust_device_posture_rule
+#   cloudflare_zero_trust_device_posture_rule -> cloudflare_zero_trust_device_posture_rule (no-op)
+# We create dependent resources (gateway policies) that reference these posture rules via depends_on.
+# After migration, the references must be updated to use the new resource names.
+
 # Using old v4 name (cloudflare_device_posture_rule) -> becomes cloudflare_zero_trust_device_posture_rule
 resource "cloudflare_zero_trust_device_posture_rule" "ref_source_old_name" {
   account_id  = var.cloudflare_account_id
@@ -631,3 +580,34 @@ resource "cloudflare_zero_trust_device_posture_rule" "ref_source_new_name" {
     platform = "windows"
   }]
 }
+
+# Dependent resources that reference the above posture rules
+# Using realistic resources that would depend on posture rules
+
+# Gateway policy depending on old-name posture rule
+# Note: Using high precedence values (100000+) to avoid conflicts with zero_trust_gateway_policy tests
+resource "cloudflare_zero_trust_gateway_policy" "depends_on_old_posture" {
+  account_id  = var.cloudflare_account_id
+  name        = "cftftest Gateway Policy - Old Posture Rule"
+  description = "Policy depending on old-name posture rule"
+  action      = "block"
+  precedence  = 100000
+  enabled     = true
+  traffic     = "any(dns.domains[*] == \"example-old.com\")"
+
+  depends_on = [cloudflare_zero_trust_device_posture_rule.ref_source_old_name]
+}
+
+# Gateway policy depending on new-name posture rule
+# Note: Using high precedence values (100000+) to avoid conflicts with zero_trust_gateway_policy tests
+resource "cloudflare_zero_trust_gateway_policy" "depends_on_new_posture" {
+  account_id  = var.cloudflare_account_id
+  name        = "cftftest Gateway Policy - New Posture Rule"
+  description = "Policy depending on new-name posture rule"
+  action      = "allow"
+  precedence  = 100001
+  enabled     = true
+  traffic     = "any(dns.domains[*] == \"example-new.com\")"
+
+  depends_on = [cloudflare_zero_trust_device_posture_rule.ref_source_new_name]
+}
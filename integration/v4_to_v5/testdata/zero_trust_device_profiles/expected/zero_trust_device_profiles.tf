@@ -19,43 +19,6 @@ locals {
   test_tags      = ["test", "migration", "device_profile"]
 }
 
-
-
-
-
-
-
-
-
-
-
-
-
-
-
-
-
-
-# ============================================================================
-# Pattern Group 9: Cross-File References (Resource Rename Test)
-# ============================================================================
-
-# Pattern 9 tests that cross-file references are updated when resource names change.
-# The migrator renames:
-#   cloudflare_zero_trust_device_profiles -> cloudflare_zero_trust_device_default_profile (for default=true)
-#   cloudflare_device_settings_policy -> cloudflare_zero_trust_device_default_profile
-# We create dependent resources that reference these profiles via depends_on.
-# After migration, the references must be updated to use the new resource names.
-
-
-
-# Dependent resources that reference the above profiles
-# Using realistic resources that would depend on device profiles
-
-
-
-
-
 resource "cloudflare_zero_trust_device_default_profile" "map_example" {
   for_each = {
     "profile1" = {
@@ -394,6 +357,17 @@ moved {
   to   = cloudflare_zero_trust_device_custom_profile.custom_teams
 }
 
+# ============================================================================
+# Pattern Group 9: Cross-File References (Resource Rename Test)
+# ============================================================================
+
+# Pattern 9 tests that cross-file references are updated when resource names change.
+# The migrator renames:
+#   cloudflare_zero_trust_device_profiles -> cloudflare_zero_trust_device_default_profile (for default=true)
+#   cloudflare_device_settings_policy -> cloudflare_zero_trust_device_default_profile
+# We create dependent resources that reference these profiles via depends_on.
+# After migration, the references must be updated to use the new resource names.
+
 # Using old v4 name (cloudflare_device_settings_policy) -> becomes cloudflare_zero_trust_device_default_profile
 resource "cloudflare_zero_trust_device_default_profile" "ref_source_old_name" {
   account_id = var.cloudflare_account_id
@@ -426,6 +400,9 @@ moved {
   to   = cloudflare_zero_trust_device_default_profile.ref_source_new_name
 }
 
+# Dependent resources that reference the above profiles
+# Using realistic resources that would depend on device profiles
+
 # Device posture rule depending on old-name profile
 resource "cloudflare_zero_trust_device_posture_rule" "depends_on_old_profile" {
   account_id  = var.cloudflare_account_id
@@ -464,3 +441,5 @@ resource "cloudflare_zero_trust_device_posture_rule" "depends_on_new_profile" {
     platform = "windows"
   }]
 }
+
+
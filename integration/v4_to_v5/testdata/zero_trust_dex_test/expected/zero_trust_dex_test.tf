@@ -291,21 +291,6 @@ resource "cloudflare_zero_trust_dex_test" "api_with_path" {
 #   cloudflare_device_dex_test -> cloudflare_zero_trust_dex_test
 #   cloudflare_zero_trust_dex_test -> cloudflare_zero_trust_dex_test (no-op)
 
-
-# Using new v4 name (cloudflare_zero_trust_dex_test) -> stays cloudflare_zero_trust_dex_test
-resource "cloudflare_zero_trust_dex_test" "ref_source_new_name" {
-  account_id  = var.cloudflare_account_id
-  name        = "${local.name_prefix}-ref-new-name"
-  description = "Referenced by dex test rename"
-  interval    = "2h0m0s"
-  enabled     = true
-
-  data = {
-    kind = "traceroute"
-    host = "1.1.1.1"
-  }
-}
-
 # Using old v4 name (cloudflare_device_dex_test) -> becomes cloudflare_zero_trust_dex_test
 resource "cloudflare_zero_trust_dex_test" "ref_source_old_name" {
   account_id  = var.cloudflare_account_id
@@ -325,3 +310,17 @@ moved {
   from = cloudflare_device_dex_test.ref_source_old_name
   to   = cloudflare_zero_trust_dex_test.ref_source_old_name
 }
+
+# Using new v4 name (cloudflare_zero_trust_dex_test) -> stays cloudflare_zero_trust_dex_test
+resource "cloudflare_zero_trust_dex_test" "ref_source_new_name" {
+  account_id  = var.cloudflare_account_id
+  name        = "${local.name_prefix}-ref-new-name"
+  description = "Referenced by dex test rename"
+  interval    = "2h0m0s"
+  enabled     = true
+
+  data = {
+    kind = "traceroute"
+    host = "1.1.1.1"
+  }
+}
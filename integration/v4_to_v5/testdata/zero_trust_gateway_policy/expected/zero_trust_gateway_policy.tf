@@ -65,116 +65,6 @@ locals {
 # Pattern Group 1: Basic Resources (Edge Cases)
 # ============================================================================
 
-
-
-
-
-
-
-# ============================================================================
-# Pattern Group 2: for_each with Maps
-# ============================================================================
-
-
-# ============================================================================
-# Pattern Group 3: for_each with Sets
-# ============================================================================
-
-
-# ============================================================================
-# Pattern Group 4: count-based Resources
-# ============================================================================
-
-
-# ============================================================================
-# Pattern Group 5: Conditional Creation
-# ============================================================================
-
-
-
-# ============================================================================
-# Pattern Group 6: Terraform Functions
-# ============================================================================
-
-
-
-# ============================================================================
-# Pattern Group 7: Lifecycle Meta-Arguments
-# ============================================================================
-
-
-
-# ============================================================================
-# Pattern Group 8: Additional Edge Cases & Action Types
-# ============================================================================
-
-
-
-
-
-
-
-# Total: 28 base resources + 3 from for_each map + 4 from for_each set + 3 from count = 38 instances
-
-# ============================================================================
-# BUGS-2007: Already-v5-named resources with block syntax not converted
-# These resources already have the v5 name but rule_settings and nested
-# blocks are still in v4 block syntax — tf-migrate must still convert them.
-# ============================================================================
-
-# Already v5-named: rule_settings with flat attrs (block_page_reason rename needed)
-resource "cloudflare_zero_trust_gateway_policy" "bugs2007_block" {
-  account_id  = var.cloudflare_account_id
-  name        = "${local.name_prefix} BUGS-2007 Block Policy"
-  description = "BUGS-2007 block policy with block_page_reason"
-  action      = "block"
-  precedence  = 2000
-  filters     = local.dns_filter
-  traffic     = local.common_traffic_expression
-
-  rule_settings = {
-    block_page_enabled = true
-    block_reason       = "Access denied by policy"
-  }
-}
-
-# Already v5-named: rule_settings with nested notification_settings
-resource "cloudflare_zero_trust_gateway_policy" "bugs2007_notification" {
-  account_id  = var.cloudflare_account_id
-  name        = "${local.name_prefix} BUGS-2007 Notify Policy"
-  description = "BUGS-2007 policy with notification_settings"
-  action      = "block"
-  precedence  = 2100
-  filters     = local.dns_filter
-  traffic     = local.common_traffic_expression
-
-  rule_settings = {
-    notification_settings = {
-      enabled = true
-      msg     = "You have been blocked"
-    }
-  }
-}
-
-# Already v5-named: rule_settings with nested l4override
-resource "cloudflare_zero_trust_gateway_policy" "bugs2007_l4" {
-  account_id  = var.cloudflare_account_id
-  name        = "${local.name_prefix} BUGS-2007 L4 Policy"
-  description = "BUGS-2007 policy with l4override"
-  action      = "l4_override"
-  precedence  = 2200
-  filters     = local.l4_filter
-  traffic     = "net.dst.ip == 93.184.216.34"
-
-  rule_settings = {
-    l4override = {
-      ip   = "10.0.0.1"
-      port = 8080
-    }
-  }
-}
-
-
 # 1. Minimal gateway policy
 resource "cloudflare_zero_trust_gateway_policy" "minimal" {
   account_id  = local.common_account_id
@@ -313,6 +203,10 @@ moved {
   to   = cloudflare_zero_trust_gateway_policy.simple_resolver
 }
 
+# ============================================================================
+# Pattern Group 2: for_each with Maps
+# ============================================================================
+
 # 7-9. Resources created with for_each over map
 resource "cloudflare_zero_trust_gateway_policy" "policy_configs" {
   for_each = var.policy_configs
@@ -332,6 +226,10 @@ moved {
   to   = cloudflare_zero_trust_gateway_policy.policy_configs
 }
 
+# ============================================================================
+# Pattern Group 3: for_each with Sets
+# ============================================================================
+
 # 10-13. Resources created with for_each over set
 resource "cloudflare_zero_trust_gateway_policy" "environment_policies" {
   for_each = toset(["staging", "production", "development", "testing"])
@@ -351,6 +249,10 @@ moved {
   to   = cloudflare_zero_trust_gateway_policy.environment_policies
 }
 
+# ============================================================================
+# Pattern Group 4: count-based Resources
+# ============================================================================
+
 # 14-16. Resources created with count
 resource "cloudflare_zero_trust_gateway_policy" "tiered_policies" {
   count = 3
@@ -370,6 +272,10 @@ moved {
   to   = cloudflare_zero_trust_gateway_policy.tiered_policies
 }
 
+# ============================================================================
+# Pattern Group 5: Conditional Creation
+# ============================================================================
+
 # 17. Conditionally created policy
 resource "cloudflare_zero_trust_gateway_policy" "conditional_enabled" {
   count = var.enable_security_policies ? 1 : 0
@@ -413,6 +319,10 @@ moved {
   to   = cloudflare_zero_trust_gateway_policy.conditional_disabled
 }
 
+# ============================================================================
+# Pattern Group 6: Terraform Functions
+# ============================================================================
+
 # 19. Policy using join() function
 resource "cloudflare_zero_trust_gateway_policy" "with_join" {
   account_id  = var.cloudflare_account_id
@@ -447,6 +357,10 @@ moved {
   to   = cloudflare_zero_trust_gateway_policy.with_interpolation
 }
 
+# ============================================================================
+# Pattern Group 7: Lifecycle Meta-Arguments
+# ============================================================================
+
 # 21. Policy with lifecycle block
 resource "cloudflare_zero_trust_gateway_policy" "with_lifecycle" {
   account_id  = var.cloudflare_account_id
@@ -495,6 +409,10 @@ moved {
   to   = cloudflare_zero_trust_gateway_policy.with_prevent_destroy
 }
 
+# ============================================================================
+# Pattern Group 8: Additional Edge Cases & Action Types
+# ============================================================================
+
 # 23. L4 Override policy with specific settings
 resource "cloudflare_zero_trust_gateway_policy" "l4_override_detailed" {
   account_id  = var.cloudflare_account_id
@@ -636,6 +554,66 @@ moved {
   to   = cloudflare_zero_trust_gateway_policy.with_override_ips
 }
 
+# Total: 28 base resources + 3 from for_each map + 4 from for_each set + 3 from count = 38 instances
+
+# ============================================================================
+# BUGS-2007: Already-v5-named resources with block syntax not converted
+# These resources already have the v5 name but rule_settings and nested
+# blocks are still in v4 block syntax — tf-migrate must still convert them.
+# ============================================================================
+
+# Already v5-named: rule_settings with flat attrs (block_page_reason rename needed)
+resource "cloudflare_zero_trust_gateway_policy" "bugs2007_block" {
+  account_id  = var.cloudflare_account_id
+  name        = "${local.name_prefix} BUGS-2007 Block Policy"
+  description = "BUGS-2007 block policy with block_page_reason"
+  action      = "block"
+  precedence  = 2000
+  filters     = local.dns_filter
+  traffic     = local.common_traffic_expression
+
+  rule_settings = {
+    block_page_enabled = true
+    block_reason       = "Access denied by policy"
+  }
+}
+
+# Already v5-named: rule_settings with nested notification_settings
+resource "cloudflare_zero_trust_gateway_policy" "bugs2007_notification" {
+  account_id  = var.cloudflare_account_id
+  name        = "${local.name_prefix} BUGS-2007 Notify Policy"
+  description = "BUGS-2007 policy with notification_settings"
+  action      = "block"
+  precedence  = 2100
+  filters     = local.dns_filter
+  traffic     = local.common_traffic_expression
+
+  rule_settings = {
+    notification_settings = {
+      enabled = true
+      msg     = "You have been blocked"
+    }
+  }
+}
+
+# Already v5-named: rule_settings with nested l4override
+resource "cloudflare_zero_trust_gateway_policy" "bugs2007_l4" {
+  account_id  = var.cloudflare_account_id
+  name        = "${local.name_prefix} BUGS-2007 L4 Policy"
+  description = "BUGS-2007 policy with l4override"
+  action      = "l4_override"
+  precedence  = 2200
+  filters     = local.l4_filter
+  traffic     = "net.dst.ip == 93.184.216.34"
+
+  rule_settings = {
+    l4override = {
+      ip   = "10.0.0.1"
+      port = 8080
+    }
+  }
+}
+
 # Nested dns_resolvers should be preserved during block->attribute conversion
 resource "cloudflare_zero_trust_gateway_policy" "with_dns_resolvers_nested" {
   account_id  = var.cloudflare_account_id
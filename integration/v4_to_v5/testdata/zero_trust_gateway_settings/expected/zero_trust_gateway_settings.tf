@@ -63,162 +63,6 @@ locals {
 # Pattern Group 1: Basic Resources (Edge Cases & Field Combinations)
 # ============================================================================
 
-
-
-
-
-
-
-
-
-
-
-# 11. Using v5 name in v4 (cloudflare_zero_trust_gateway_settings alias)
-resource "cloudflare_zero_trust_gateway_settings" "v5_name_in_v4" {
-  account_id = var.cloudflare_account_id
-
-
-  settings = {
-    activity_log = {
-      enabled = true
-    }
-    tls_decrypt = {
-      enabled = false
-    }
-    browser_isolation = {
-      url_browser_isolation_enabled = true
-      non_identity_enabled          = false
-    }
-    block_page = {
-      enabled     = true
-      name        = "V5 Name Test"
-      footer_text = "Using v5 resource name"
-    }
-    fips = {
-      tls = true
-    }
-  }
-}
-
-# ============================================================================
-# Pattern Group 2: for_each with Maps
-# ============================================================================
-
-
-# ============================================================================
-# Pattern Group 3: for_each with Sets (Environment Configs)
-# ============================================================================
-
-
-# ============================================================================
-# Pattern Group 4: count-based Resources
-# ============================================================================
-
-
-# ============================================================================
-# Pattern Group 5: Conditional Creation
-# ============================================================================
-
-
-
-# ============================================================================
-# Pattern Group 6: Terraform Functions
-# ============================================================================
-
-
-
-
-# ============================================================================
-# Pattern Group 7: Lifecycle Meta-Arguments
-# ============================================================================
-
-
-
-
-# ============================================================================
-# Pattern Group 8: All MaxItems:1 Blocks in Different Combinations
-# ============================================================================
-
-
-
-# Total resources:
-# - Basic: 11 resources (1-11)
-# - for_each map: 3 resources (12-14)
-# - for_each set: 3 resources (15-17)
-# - count: 3 resources (18-20)
-# - conditional: 1 resource (21, 22 not created due to default var)
-# - functions: 3 resources (23-25)
-# - lifecycle: 3 resources (26-28)
-# - combinations: 2 resources (29-30)
-# Total: 29 resource instances created
-
-# ============================================================================
-# Pattern Group 9: Cross-File References (Resource Rename Test)
-# ============================================================================
-
-# Pattern 9 tests that cross-file references are updated when resource names change.
-# The migrator renames cloudflare_teams_account -> cloudflare_zero_trust_gateway_settings
-# and cloudflare_zero_trust_gateway_settings -> cloudflare_zero_trust_gateway_settings (no-op).
-# We create dependent resources (gateway policies) that reference these settings via depends_on.
-# After migration, the references must be updated to use the new resource names.
-
-
-# 32. New name v4 resource (cloudflare_zero_trust_gateway_settings) - stays cloudflare_zero_trust_gateway_settings
-resource "cloudflare_zero_trust_gateway_settings" "ref_source_new" {
-  account_id = var.cloudflare_account_id
-
-
-  settings = {
-    activity_log = {
-      enabled = true
-    }
-    tls_decrypt = {
-      enabled = false
-    }
-    browser_isolation = {
-      url_browser_isolation_enabled = false
-      non_identity_enabled          = false
-    }
-    block_page = {
-      enabled     = true
-      name        = "Cross-Ref New Name Source"
-      footer_text = "Referenced by gateway policy (new name)"
-    }
-    antivirus = {
-      enabled_download_phase = true
-      enabled_upload_phase   = false
-      fail_closed            = false
-    }
-  }
-}
-
-# Dependent resources that reference the above settings
-# Note: Using realistic resource type that would depend on gateway settings
-
-# 33. Gateway policy depending on old-name settings
-resource "cloudflare_zero_trust_gateway_policy" "depends_on_old_name" {
-  account_id  = var.cloudflare_account_id
-  name        = "cftftest Policy Depending on Old Name Settings"
-  description = "This policy depends on settings created with old resource name"
-  action      = "block"
-  precedence  = 1000
-  enabled     = true
-
-  depends_on = [cloudflare_zero_trust_gateway_settings.ref_source_old]
-}
-
-# 34. Gateway policy depending on new-name settings
-resource "cloudflare_zero_trust_gateway_policy" "depends_on_new_name" {
-  account_id  = var.cloudflare_account_id
-  name        = "cftftest Policy Depending on New Name Settings"
-  description = "This policy depends on settings created with new resource name"
-  action      = "allow"
-  precedence  = 2000
-  enabled     = true
-
-  depends_on = [cloudflare_zero_trust_gateway_settings.ref_source_new]
-}
-
 # 1. Minimal configuration (only account_id)
 resource "cloudflare_zero_trust_gateway_settings" "minimal" {
   account_id = var.cloudflare_account_id
@@ -535,6 +379,37 @@ moved {
   to   = cloudflare_zero_trust_gateway_settings.with_both_deprecated
 }
 
+# 11. Using v5 name in v4 (cloudflare_zero_trust_gateway_settings alias)
+resource "cloudflare_zero_trust_gateway_settings" "v5_name_in_v4" {
+  account_id = var.cloudflare_account_id
+
+
+  settings = {
+    activity_log = {
+      enabled = true
+    }
+    tls_decrypt = {
+      enabled = false
+    }
+    browser_isolation = {
+      url_browser_isolation_enabled = true
+      non_identity_enabled          = false
+    }
+    block_page = {
+      enabled     = true
+      name        = "V5 Name Test"
+      footer_text = "Using v5 resource name"
+    }
+    fips = {
+      tls = true
+    }
+  }
+}
+
+# ============================================================================
+# Pattern Group 2: for_each with Maps
+# ============================================================================
+
 # 12-14. Resources created with for_each over account configs
 resource "cloudflare_zero_trust_gateway_settings" "account_configs" {
   for_each = var.account_configs
@@ -568,6 +443,10 @@ moved {
   to   = cloudflare_zero_trust_gateway_settings.account_configs
 }
 
+# ============================================================================
+# Pattern Group 3: for_each with Sets (Environment Configs)
+# ============================================================================
+
 # 15-17. Resources created with for_each over environments
 resource "cloudflare_zero_trust_gateway_settings" "environment_configs" {
   for_each = toset(["dev", "staging", "prod"])
@@ -599,6 +478,10 @@ moved {
   to   = cloudflare_zero_trust_gateway_settings.environment_configs
 }
 
+# ============================================================================
+# Pattern Group 4: count-based Resources
+# ============================================================================
+
 # 18-20. Resources created with count (tiered accounts)
 resource "cloudflare_zero_trust_gateway_settings" "tiered_accounts" {
   count = 3
@@ -633,6 +516,10 @@ moved {
   to   = cloudflare_zero_trust_gateway_settings.tiered_accounts
 }
 
+# ============================================================================
+# Pattern Group 5: Conditional Creation
+# ============================================================================
+
 # 21. Conditionally created (with browser isolation)
 resource "cloudflare_zero_trust_gateway_settings" "conditional_enabled" {
   count = var.enable_browser_isolation ? 1 : 0
@@ -684,6 +571,10 @@ moved {
   to   = cloudflare_zero_trust_gateway_settings.conditional_disabled
 }
 
+# ============================================================================
+# Pattern Group 6: Terraform Functions
+# ============================================================================
+
 # 23. Using join() function
 resource "cloudflare_zero_trust_gateway_settings" "with_join" {
   account_id = join("-", ["join", var.cloudflare_account_id])
@@ -771,6 +662,10 @@ moved {
   to   = cloudflare_zero_trust_gateway_settings.with_ternary
 }
 
+# ============================================================================
+# Pattern Group 7: Lifecycle Meta-Arguments
+# ============================================================================
+
 # 26. With lifecycle - create_before_destroy
 resource "cloudflare_zero_trust_gateway_settings" "with_lifecycle_create" {
   account_id = var.cloudflare_account_id
@@ -865,6 +760,10 @@ moved {
   to   = cloudflare_zero_trust_gateway_settings.with_lifecycle_prevent
 }
 
+# ============================================================================
+# Pattern Group 8: All MaxItems:1 Blocks in Different Combinations
+# ============================================================================
+
 # 29. Extended email matching + certificate
 resource "cloudflare_zero_trust_gateway_settings" "email_and_cert" {
   account_id = var.cloudflare_account_id
@@ -924,6 +823,27 @@ moved {
   to   = cloudflare_zero_trust_gateway_settings.all_scanning
 }
 
+# Total resources:
+# - Basic: 11 resources (1-11)
+# - for_each map: 3 resources (12-14)
+# - for_each set: 3 resources (15-17)
+# - count: 3 resources (18-20)
+# - conditional: 1 resource (21, 22 not created due to default var)
+# - functions: 3 resources (23-25)
+# - lifecycle: 3 resources (26-28)
+# - combinations: 2 resources (29-30)
+# Total: 29 resource instances created
+
+# ============================================================================
+# Pattern Group 9: Cross-File References (Resource Rename Test)
+# ============================================================================
+
+# Pattern 9 tests that cross-file references are updated when resource names change.
+# The migrator renames cloudflare_teams_account -> cloudflare_zero_trust_gateway_settings
+# and cloudflare_zero_trust_gateway_settings -> cloudflare_zero_trust_gateway_settings (no-op).
+# We create dependent resources (gateway policies) that reference these settings via depends_on.
+# After migration, the references must be updated to use the new resource names.
+
 # 31. Old name v4 resource (cloudflare_teams_account) - will become cloudflare_zero_trust_gateway_settings
 resource "cloudflare_zero_trust_gateway_settings" "ref_source_old" {
   account_id = var.cloudflare_account_id
@@ -951,3 +871,59 @@ moved {
   from = cloudflare_teams_account.ref_source_old
   to   = cloudflare_zero_trust_gateway_settings.ref_source_old
 }
+
+# 32. New name v4 resource (cloudflare_zero_trust_gateway_settings) - stays cloudflare_zero_trust_gateway_settings
+resource "cloudflare_zero_trust_gateway_settings" "ref_source_new" {
+  account_id = var.cloudflare_account_id
+
+
+  settings = {
+    activity_log = {
+      enabled = true
+    }
+    tls_decrypt = {
+      enabled = false
+    }
+    browser_isolation = {
+      url_browser_isolation_enabled = false
+      non_identity_enabled          = false
+    }
+    block_page = {
+      enabled     = true
+      name        = "Cross-Ref New Name Source"
+      footer_text = "Referenced by gateway policy (new name)"
+    }
+    antivirus = {
+      enabled_download_phase = true
+      enabled_upload_phase   = false
+      fail_closed            = false
+    }
+  }
+}
+
+# Dependent resources that reference the above settings
+# Note: Using realistic resource type that would depend on gateway settings
+
+# 33. Gateway policy depending on old-name settings
+resource "cloudflare_zero_trust_gateway_policy" "depends_on_old_name" {
+  account_id  = var.cloudflare_account_id
+  name        = "cftftest Policy Depending on Old Name Settings"
+  description = "This policy depends on settings created with old resource name"
+  action      = "block"
+  precedence  = 1000
+  enabled     = true
+
+  depends_on = [cloudflare_zero_trust_gateway_settings.ref_source_old]
+}
+
+# 34. Gateway policy depending on new-name settings
+resource "cloudflare_zero_trust_gateway_policy" "depends_on_new_name" {
+  account_id  = var.cloudflare_account_id
+  name        = "cftftest Policy Depending on New Name Settings"
+  description = "This policy depends on settings created with new resource name"
+  action      = "allow"
+  precedence  = 2000
+  enabled     = true
+
+  depends_on = [cloudflare_zero_trust_gateway_settings.ref_source_new]
+}
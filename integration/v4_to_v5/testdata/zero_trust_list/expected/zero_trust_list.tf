@@ -62,106 +62,6 @@ locals {
 # Pattern Group 1: Basic Resources (Edge Cases)
 # ============================================================================
 
-
-
-
-
-
-
-# ============================================================================
-# Pattern Group 2: for_each with Maps
-# ============================================================================
-
-
-# ============================================================================
-# Pattern Group 3: for_each with Sets
-# ============================================================================
-
-
-# ============================================================================
-# Pattern Group 4: count-based Resources
-# ============================================================================
-
-
-# ============================================================================
-# Pattern Group 5: Conditional Creation
-# ============================================================================
-
-
-
-# ============================================================================
-# Pattern Group 6: Terraform Functions
-# ============================================================================
-
-
-
-# ============================================================================
-# Pattern Group 7: Lifecycle Meta-Arguments
-# ============================================================================
-
-
-
-# ============================================================================
-# Pattern Group 8: Additional Edge Cases & Type Coverage
-# ============================================================================
-
-
-
-
-
-# ============================================================================
-# BUGS-2009: Already-v5-named resources with v4 block syntax
-# These resources already have the v5 name but items_with_description blocks
-# are still in v4 block syntax — tf-migrate must still convert them.
-# ============================================================================
-
-# 27. Already v5-named: simple items array
-resource "cloudflare_zero_trust_list" "bugs2009_simple" {
-  account_id = var.cloudflare_account_id
-  name       = "${local.name_prefix} BUGS-2009 Simple"
-  type       = "IP"
-  items = [{
-    description = null
-    value       = "10.0.0.1"
-    }, {
-    description = null
-    value       = "10.0.0.2"
-  }]
-}
-
-# 28. Already v5-named: items_with_description blocks
-resource "cloudflare_zero_trust_list" "bugs2009_blocks" {
-  account_id = var.cloudflare_account_id
-  name       = "${local.name_prefix} BUGS-2009 Blocks"
-  type       = "DOMAIN"
-
-
-  items = [{
-    description = "First block"
-    value       = "bugs2009-block1.cf-tf-test.com"
-    }, {
-    description = "Second block"
-    value       = "bugs2009-block2.cf-tf-test.com"
-  }]
-}
-
-# 29. Already v5-named: mixed items and blocks
-resource "cloudflare_zero_trust_list" "bugs2009_mixed" {
-  account_id = var.cloudflare_account_id
-  name       = "${local.name_prefix} BUGS-2009 Mixed"
-  type       = "EMAIL"
-
-  items = [{
-    description = "Support email"
-    value       = "bugs2009-support@cf-tf-test.com"
-    }, {
-    description = null
-    value       = "bugs2009-admin@cf-tf-test.com"
-  }]
-}
-
-# Total: 26 base resources + 3 from for_each map + 4 from for_each set + 3 from count + 3 BUGS-2009 = 39 instances
-
 # 1. Minimal resource - only required fields
 resource "cloudflare_zero_trust_list" "minimal" {
   account_id = local.common_account_id
@@ -297,6 +197,10 @@ moved {
   to   = cloudflare_zero_trust_list.email_list
 }
 
+# ============================================================================
+# Pattern Group 2: for_each with Maps
+# ============================================================================
+
 # 7-9. Resources created with for_each over map
 resource "cloudflare_zero_trust_list" "security_domains" {
   for_each = var.security_domains
@@ -322,6 +226,10 @@ moved {
   to   = cloudflare_zero_trust_list.security_domains
 }
 
+# ============================================================================
+# Pattern Group 3: for_each with Sets
+# ============================================================================
+
 # 10-13. Resources created with for_each over set
 resource "cloudflare_zero_trust_list" "list_types" {
   for_each = toset(["staging", "production", "development", "testing"])
@@ -343,6 +251,10 @@ moved {
   to   = cloudflare_zero_trust_list.list_types
 }
 
+# ============================================================================
+# Pattern Group 4: count-based Resources
+# ============================================================================
+
 # 14-16. Resources created with count
 resource "cloudflare_zero_trust_list" "ip_ranges" {
   count = 3
@@ -364,6 +276,10 @@ moved {
   to   = cloudflare_zero_trust_list.ip_ranges
 }
 
+# ============================================================================
+# Pattern Group 5: Conditional Creation
+# ============================================================================
+
 # 17. Conditionally created resource
 resource "cloudflare_zero_trust_list" "conditional_enabled" {
   count = var.enable_security_lists ? 1 : 0
@@ -405,6 +321,10 @@ moved {
   to   = cloudflare_zero_trust_list.conditional_disabled
 }
 
+# ============================================================================
+# Pattern Group 6: Terraform Functions
+# ============================================================================
+
 # 19. Resource using join() function
 resource "cloudflare_zero_trust_list" "with_join" {
   account_id  = var.cloudflare_account_id
@@ -443,6 +363,10 @@ moved {
   to   = cloudflare_zero_trust_list.with_interpolation
 }
 
+# ============================================================================
+# Pattern Group 7: Lifecycle Meta-Arguments
+# ============================================================================
+
 # 21. Resource with lifecycle block
 resource "cloudflare_zero_trust_list" "with_lifecycle" {
   account_id  = var.cloudflare_account_id
@@ -486,6 +410,10 @@ moved {
   to   = cloudflare_zero_trust_list.with_prevent_destroy
 }
 
+# ============================================================================
+# Pattern Group 8: Additional Edge Cases & Type Coverage
+# ============================================================================
+
 # 23. URL list with only items_with_description
 resource "cloudflare_zero_trust_list" "url_list" {
   account_id = var.cloudflare_account_id
@@ -581,3 +509,55 @@ moved {
   to   = cloudflare_zero_trust_list.complex_emails
 }
 
+# ============================================================================
+# BUGS-2009: Already-v5-named resources with v4 block syntax
+# These resources already have the v5 name but items_with_description blocks
+# are still in v4 block syntax — tf-migrate must still convert them.
+# ============================================================================
+
+# 27. Already v5-named: simple items array
+resource "cloudflare_zero_trust_list" "bugs2009_simple" {
+  account_id = var.cloudflare_account_id
+  name       = "${local.name_prefix} BUGS-2009 Simple"
+  type       = "IP"
+  items = [{
+    description = null
+    value       = "10.0.0.1"
+    }, {
+    description = null
+    value       = "10.0.0.2"
+  }]
+}
+
+# 28. Already v5-named: items_with_description blocks
+resource "cloudflare_zero_trust_list" "bugs2009_blocks" {
+  account_id = var.cloudflare_account_id
+  name       = "${local.name_prefix} BUGS-2009 Blocks"
+  type       = "DOMAIN"
+
+
+  items = [{
+    description = "First block"
+    value       = "bugs2009-block1.cf-tf-test.com"
+    }, {
+    description = "Second block"
+    value       = "bugs2009-block2.cf-tf-test.com"
+  }]
+}
+
+# 29. Already v5-named: mixed items and blocks
+resource "cloudflare_zero_trust_list" "bugs2009_mixed" {
+  account_id = var.cloudflare_account_id
+  name       = "${local.name_prefix} BUGS-2009 Mixed"
+  type       = "EMAIL"
+
+  items = [{
+    description = "Support email"
+    value       = "bugs2009-support@cf-tf-test.com"
+    }, {
+    description = null
+    value       = "bugs2009-admin@cf-tf-test.com"
+  }]
+}
+
+# Total: 26 base resources + 3 from for_each map + 4 from for_each set + 3 from count + 3 BUGS-2009 = 39 instances
@@ -16,32 +16,6 @@ variable "cloudflare_domain" {
   type        = string
 }
 
-
-
-
-
-
-
-# ============================================================================
-# Pattern Group 9: Cross-File References (Resource Rename Test)
-# ============================================================================
-
-# Pattern 9 tests that cross-file references are updated when resource names change.
-# The migrator renames:
-#   cloudflare_zero_trust_local_fallback_domain -> cloudflare_zero_trust_device_default_profile_local_domain_fallback (default)
-#   cloudflare_zero_trust_local_fallback_domain -> cloudflare_zero_trust_device_custom_profile_local_domain_fallback (custom)
-#   cloudflare_fallback_domain -> cloudflare_zero_trust_device_default_profile_local_domain_fallback (default)
-#   cloudflare_fallback_domain -> cloudflare_zero_trust_device_custom_profile_local_domain_fallback (custom)
-# We create dependent resources (device profiles) that reference these fallback domains via depends_on.
-# After migration, the references must be updated to use the new resource names.
-
-
-
-# Dependent resources that reference the above fallback domains
-# Using realistic resources that would depend on fallback domains
-
-
-
 # Default profile - no policy_id
 resource "cloudflare_zero_trust_device_default_profile_local_domain_fallback" "default_single" {
   account_id = var.cloudflare_account_id
@@ -157,6 +131,19 @@ moved {
   to   = cloudflare_zero_trust_device_custom_profile_local_domain_fallback.deprecated_custom
 }
 
+# ============================================================================
+# Pattern Group 9: Cross-File References (Resource Rename Test)
+# ============================================================================
+
+# Pattern 9 tests that cross-file references are updated when resource names change.
+# The migrator renames:
+#   cloudflare_zero_trust_local_fallback_domain -> cloudflare_zero_trust_device_default_profile_local_domain_fallback (default)
+#   cloudflare_zero_trust_local_fallback_domain -> cloudflare_zero_trust_device_custom_profile_local_domain_fallback (custom)
+#   cloudflare_fallback_domain -> cloudflare_zero_trust_device_default_profile_local_domain_fallback (default)
+#   cloudflare_fallback_domain -> cloudflare_zero_trust_device_custom_profile_local_domain_fallback (custom)
+# We create dependent resources (device profiles) that reference these fallback domains via depends_on.
+# After migration, the references must be updated to use the new resource names.
+
 # Using old v4 name (cloudflare_fallback_domain) - default profile
 resource "cloudflare_zero_trust_device_default_profile_local_domain_fallback" "ref_source_old_default" {
   account_id = var.cloudflare_account_id
@@ -191,6 +178,9 @@ moved {
   to   = cloudflare_zero_trust_device_default_profile_local_domain_fallback.ref_source_new_default
 }
 
+# Dependent resources that reference the above fallback domains
+# Using realistic resources that would depend on fallback domains
+
 # Device profile depending on old-name fallback domain
 resource "cloudflare_zero_trust_device_default_profile" "depends_on_old_fallback" {
   account_id = var.cloudflare_account_id
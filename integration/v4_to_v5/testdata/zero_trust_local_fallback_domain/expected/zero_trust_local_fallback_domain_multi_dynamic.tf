@@ -9,9 +9,6 @@ variable "cloudflare_account_id" {
   type        = string
 }
 
-
-
-
 # Pattern 1: Multiple dynamic "domains" blocks — custom profile (issue #288 exact scenario)
 resource "cloudflare_zero_trust_device_custom_profile_local_domain_fallback" "cftftest_multi_dynamic_custom" {
   account_id = var.cloudflare_account_id
@@ -69,12 +66,13 @@ resource "cloudflare_zero_trust_device_default_profile_local_domain_fallback" "c
 
 
   domains = concat(
+    [{
+      suffix = "static-only.corp"
+    }],
     [for value in toset(["dynamic.corp", "dynamic.internal"]) : {
       suffix = value
     }],
     [{
-      suffix = "static-only.corp"
-      }, {
       suffix      = "another-static.corp"
       description = "Another static domain"
     }],
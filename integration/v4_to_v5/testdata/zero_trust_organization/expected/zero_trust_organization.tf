@@ -22,49 +22,6 @@ locals {
   }
 }
 
-
-
-
-
-
-
-
-
-
-
-
-
-
-
-
-
-
-
-
-
-
-
-
-
-
-
-
-
-
-
-
-# ============================================================================
-# Pattern 9: Cross-resource reference using both v4 names
-# ============================================================================
-# This validates that GetResourceRename() returns ALL v4 names for cross-file reference updates
-# v4 name option 1: cloudflare_access_organization
-# v4 name option 2: cloudflare_zero_trust_access_organization
-# v5 name: cloudflare_zero_trust_organization
-
-
-
-
-
 # ===== Basic Scenarios (8) =====
 resource "cloudflare_zero_trust_organization" "minimal_account" {
   account_id  = var.cloudflare_account_id
@@ -544,6 +501,14 @@ moved {
   to   = cloudflare_zero_trust_organization.zone_comprehensive
 }
 
+# ============================================================================
+# Pattern 9: Cross-resource reference using both v4 names
+# ============================================================================
+# This validates that GetResourceRename() returns ALL v4 names for cross-file reference updates
+# v4 name option 1: cloudflare_access_organization
+# v4 name option 2: cloudflare_zero_trust_access_organization
+# v5 name: cloudflare_zero_trust_organization
+
 # Resource using v4 name option 1
 resource "cloudflare_zero_trust_organization" "resourcename_opt1" {
   account_id  = var.cloudflare_account_id
@@ -14,9 +14,49 @@ locals {
 
 
 
+# Custom profile with single tunnel
+resource "cloudflare_zero_trust_device_custom_profile" "single_tunnel" {
+  account_id = local.account_id
+  name       = "single_tunnel_profile"
+  match      = "identity.groups == \"developers\""
+  precedence = 1000
+  exclude = [{
+    address     = "172.16.0.0/12"
+    description = "Dev environment"
+  }]
+}
 
+moved {
+  from = cloudflare_zero_trust_device_profiles.single_tunnel
+  to   = cloudflare_zero_trust_device_custom_profile.single_tunnel
+}
 
 
+# Custom profile with multiple tunnels
+resource "cloudflare_zero_trust_device_custom_profile" "multiple_tunnels" {
+  account_id = local.account_id
+  name       = "multiple_tunnels_profile"
+  match      = "identity.groups == \"admins\""
+  precedence = 1100
+  include = [{
+    address     = "10.100.0.0/16"
+    description = "Admin resources"
+    host        = "prod.internal"
+  }]
+  exclude = [{
+    address     = "172.20.0.0/16"
+    description = "Admin network 1"
+    }, {
+    address = "172.21.0.0/16"
+    host    = "admin.internal"
+  }]
+}
+
+moved {
+  from = cloudflare_zero_trust_device_profiles.multiple_tunnels
+  to   = cloudflare_zero_trust_device_custom_profile.multiple_tunnels
+}
+
 
 
 
@@ -83,7 +123,7 @@ locals {
 *  # Reference to non-existent device profile
 *  resource "cloudflare_split_tunnel" "missing_profile" {
 *    account_id = local.account_id
-*    policy_id  = cloudflare_zero_trust_device_default_profile.nonexistent.id
+*    policy_id  = cloudflare_zero_trust_device_profiles.nonexistent.id
 *    mode       = "include"
 *  
 *    tunnels {
@@ -108,23 +148,6 @@ removed {
   }
 }
 
-# Custom profile with single tunnel
-resource "cloudflare_zero_trust_device_custom_profile" "single_tunnel" {
-  account_id = local.account_id
-  name       = "single_tunnel_profile"
-  match      = "identity.groups == \"developers\""
-  precedence = 1000
-  exclude = [{
-    address     = "172.16.0.0/12"
-    description = "Dev environment"
-  }]
-}
-
-moved {
-  from = cloudflare_zero_trust_device_profiles.single_tunnel
-  to   = cloudflare_zero_trust_device_custom_profile.single_tunnel
-}
-
 removed {
   from = cloudflare_split_tunnel.single
   lifecycle {
@@ -132,31 +155,6 @@ removed {
   }
 }
 
-# Custom profile with multiple tunnels
-resource "cloudflare_zero_trust_device_custom_profile" "multiple_tunnels" {
-  account_id = local.account_id
-  name       = "multiple_tunnels_profile"
-  match      = "identity.groups == \"admins\""
-  precedence = 1100
-  include = [{
-    address     = "10.100.0.0/16"
-    description = "Admin resources"
-    host        = "prod.internal"
-  }]
-  exclude = [{
-    address     = "172.20.0.0/16"
-    description = "Admin network 1"
-    }, {
-    address = "172.21.0.0/16"
-    host    = "admin.internal"
-  }]
-}
-
-moved {
-  from = cloudflare_zero_trust_device_profiles.multiple_tunnels
-  to   = cloudflare_zero_trust_device_custom_profile.multiple_tunnels
-}
-
 removed {
   from = cloudflare_split_tunnel.multi_exclude
   lifecycle {
@@ -17,8 +17,44 @@ variable "cloudflare_domain" {
 # Basic Tunnel Resources
 # ========================================
 
+# Basic tunnel with minimal configuration
+resource "cloudflare_zero_trust_tunnel_cloudflared" "minimal" {
+  account_id    = var.cloudflare_account_id
+  name          = "${local.name_prefix}-minimal-tunnel"
+  config_src    = "local"
+  tunnel_secret = base64encode("test-secret-that-is-at-least-32-bytes-long")
+}
+
+moved {
+  from = cloudflare_tunnel.minimal
+  to   = cloudflare_zero_trust_tunnel_cloudflared.minimal
+}
 
+# Tunnel with local config source
+resource "cloudflare_zero_trust_tunnel_cloudflared" "local_config" {
+  account_id    = var.cloudflare_account_id
+  name          = "${local.name_prefix}-local-config-tunnel"
+  config_src    = "local"
+  tunnel_secret = base64encode("another-secret-32-bytes-or-longer-here")
+}
 
+moved {
+  from = cloudflare_tunnel.local_config
+  to   = cloudflare_zero_trust_tunnel_cloudflared.local_config
+}
+
+# Tunnel with cloudflare config source
+resource "cloudflare_zero_trust_tunnel_cloudflared" "cloudflare_config" {
+  account_id    = var.cloudflare_account_id
+  name          = "${local.name_prefix}-cloudflare-config-tunnel"
+  config_src    = "cloudflare"
+  tunnel_secret = base64encode("remote-tunnel-secret-32-bytes-minimum")
+}
+
+moved {
+  from = cloudflare_tunnel.cloudflare_config
+  to   = cloudflare_zero_trust_tunnel_cloudflared.cloudflare_config
+}
 
 # ========================================
 # Advanced Terraform Patterns for Testing
@@ -44,6 +80,18 @@ locals {
   full_tunnel_name   = "${local.name_prefix}-${var.tunnel_prefix}-${local.tunnel_suffix}"
 }
 
+# Tunnel using variables and locals
+resource "cloudflare_zero_trust_tunnel_cloudflared" "with_vars" {
+  account_id    = local.common_account_id
+  name          = local.full_tunnel_name
+  config_src    = var.config_source
+  tunnel_secret = base64encode(local.tunnel_secret_base)
+}
+
+moved {
+  from = cloudflare_tunnel.with_vars
+  to   = cloudflare_zero_trust_tunnel_cloudflared.with_vars
+}
 
 # Pattern 3: for_each with map
 variable "application_tunnels" {
@@ -67,6 +115,19 @@ variable "application_tunnels" {
   }
 }
 
+resource "cloudflare_zero_trust_tunnel_cloudflared" "applications" {
+  for_each = var.application_tunnels
+
+  account_id    = var.cloudflare_account_id
+  name          = "${local.name_prefix}-${each.key}-tunnel"
+  config_src    = each.value.config_src
+  tunnel_secret = base64encode(each.value.secret)
+}
+
+moved {
+  from = cloudflare_tunnel.applications
+  to   = cloudflare_zero_trust_tunnel_cloudflared.applications
+}
 
 # Pattern 4: for_each with list converted to set
 variable "environment_tunnels" {
@@ -94,106 +155,6 @@ variable "environment_tunnels" {
   ]
 }
 
-
-# Pattern 5: Count-based resources
-variable "replica_count" {
-  type    = number
-  default = 3
-}
-
-
-# Pattern 6: Conditional resource creation
-variable "enable_backup_tunnel" {
-  type    = bool
-  default = true
-}
-
-
-
-
-
-# Pattern 9: Using terraform expressions
-variable "use_cloudflare_config" {
-  type    = bool
-  default = false
-}
-
-
-
-
-# Pattern 12: Complex expression for config_src
-variable "is_production" {
-  type    = bool
-  default = true
-}
-
-
-# Basic tunnel with minimal configuration
-resource "cloudflare_zero_trust_tunnel_cloudflared" "minimal" {
-  account_id    = var.cloudflare_account_id
-  name          = "${local.name_prefix}-minimal-tunnel"
-  config_src    = "local"
-  tunnel_secret = base64encode("test-secret-that-is-at-least-32-bytes-long")
-}
-
-moved {
-  from = cloudflare_tunnel.minimal
-  to   = cloudflare_zero_trust_tunnel_cloudflared.minimal
-}
-
-# Tunnel with local config source
-resource "cloudflare_zero_trust_tunnel_cloudflared" "local_config" {
-  account_id    = var.cloudflare_account_id
-  name          = "${local.name_prefix}-local-config-tunnel"
-  config_src    = "local"
-  tunnel_secret = base64encode("another-secret-32-bytes-or-longer-here")
-}
-
-moved {
-  from = cloudflare_tunnel.local_config
-  to   = cloudflare_zero_trust_tunnel_cloudflared.local_config
-}
-
-# Tunnel with cloudflare config source
-resource "cloudflare_zero_trust_tunnel_cloudflared" "cloudflare_config" {
-  account_id    = var.cloudflare_account_id
-  name          = "${local.name_prefix}-cloudflare-config-tunnel"
-  config_src    = "cloudflare"
-  tunnel_secret = base64encode("remote-tunnel-secret-32-bytes-minimum")
-}
-
-moved {
-  from = cloudflare_tunnel.cloudflare_config
-  to   = cloudflare_zero_trust_tunnel_cloudflared.cloudflare_config
-}
-
-# Tunnel using variables and locals
-resource "cloudflare_zero_trust_tunnel_cloudflared" "with_vars" {
-  account_id    = local.common_account_id
-  name          = local.full_tunnel_name
-  config_src    = var.config_source
-  tunnel_secret = base64encode(local.tunnel_secret_base)
-}
-
-moved {
-  from = cloudflare_tunnel.with_vars
-  to   = cloudflare_zero_trust_tunnel_cloudflared.with_vars
-}
-
-resource "cloudflare_zero_trust_tunnel_cloudflared" "applications" {
-  for_each = var.application_tunnels
-
-  account_id    = var.cloudflare_account_id
-  name          = "${local.name_prefix}-${each.key}-tunnel"
-  config_src    = each.value.config_src
-  tunnel_secret = base64encode(each.value.secret)
-}
-
-moved {
-  from = cloudflare_tunnel.applications
-  to   = cloudflare_zero_trust_tunnel_cloudflared.applications
-}
-
 resource "cloudflare_zero_trust_tunnel_cloudflared" "environments" {
   for_each = { for idx, tunnel in var.environment_tunnels : tunnel.name => tunnel }
 
@@ -208,6 +169,12 @@ moved {
   to   = cloudflare_zero_trust_tunnel_cloudflared.environments
 }
 
+# Pattern 5: Count-based resources
+variable "replica_count" {
+  type    = number
+  default = 3
+}
+
 resource "cloudflare_zero_trust_tunnel_cloudflared" "replicas" {
   count = var.replica_count
 
@@ -222,6 +189,12 @@ moved {
   to   = cloudflare_zero_trust_tunnel_cloudflared.replicas
 }
 
+# Pattern 6: Conditional resource creation
+variable "enable_backup_tunnel" {
+  type    = bool
+  default = true
+}
+
 resource "cloudflare_zero_trust_tunnel_cloudflared" "backup" {
   count = var.enable_backup_tunnel ? 1 : 0
 
@@ -280,6 +253,12 @@ moved {
   to   = cloudflare_zero_trust_tunnel_cloudflared.protected
 }
 
+# Pattern 9: Using terraform expressions
+variable "use_cloudflare_config" {
+  type    = bool
+  default = false
+}
+
 resource "cloudflare_zero_trust_tunnel_cloudflared" "conditional_config" {
   account_id    = var.cloudflare_account_id
   name          = "${local.name_prefix}-conditional-config-tunnel"
@@ -318,6 +297,12 @@ moved {
   to   = cloudflare_zero_trust_tunnel_cloudflared.interpolated
 }
 
+# Pattern 12: Complex expression for config_src
+variable "is_production" {
+  type    = bool
+  default = true
+}
+
 resource "cloudflare_zero_trust_tunnel_cloudflared" "complex_config" {
   account_id    = var.cloudflare_account_id
   name          = "${local.name_prefix}-${var.is_production ? "prod" : "dev"}-complex-tunnel"
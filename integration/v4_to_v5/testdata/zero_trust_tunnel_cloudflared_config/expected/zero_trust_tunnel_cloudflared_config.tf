@@ -20,56 +20,6 @@ locals {
 # Parent Tunnel Resources
 # ========================================
 
-
-
-
-# ========================================
-# Tunnel Config Resources
-# ========================================
-
-# Test 1: Minimal configuration using preferred resource name
-resource "cloudflare_zero_trust_tunnel_cloudflared_config" "minimal" {
-  account_id = var.cloudflare_account_id
-  tunnel_id  = cloudflare_zero_trust_tunnel_cloudflared.minimal.id
-
-  config = {
-    ingress = [
-      {
-        service = "http_status:404"
-      }
-    ]
-  }
-}
-
-
-
-# ============================================================================
-# Pattern 9: Cross-resource reference using both v4 names
-# ============================================================================
-# This validates that GetResourceRename() returns ALL v4 names for cross-file reference updates
-# v4 name option 1: cloudflare_tunnel_config
-# v4 name option 2: cloudflare_zero_trust_tunnel_cloudflared_config
-# v5 name: cloudflare_zero_trust_tunnel_cloudflared_config
-
-
-
-
-# Resource using v4 name option 2
-resource "cloudflare_zero_trust_tunnel_cloudflared_config" "resourcename_opt2" {
-  account_id = var.cloudflare_account_id
-  tunnel_id  = cloudflare_zero_trust_tunnel_cloudflared.resourcename_opt2.id
-
-  config = {
-    ingress = [
-      {
-        service = "http_status:404"
-      }
-    ]
-  }
-}
-
-
-
 # Tunnel for minimal config test
 resource "cloudflare_zero_trust_tunnel_cloudflared" "minimal" {
   account_id    = var.cloudflare_account_id
@@ -109,6 +59,24 @@ moved {
   to   = cloudflare_zero_trust_tunnel_cloudflared.deprecated_name
 }
 
+# ========================================
+# Tunnel Config Resources
+# ========================================
+
+# Test 1: Minimal configuration using preferred resource name
+resource "cloudflare_zero_trust_tunnel_cloudflared_config" "minimal" {
+  account_id = var.cloudflare_account_id
+  tunnel_id  = cloudflare_zero_trust_tunnel_cloudflared.minimal.id
+
+  config = {
+    ingress = [
+      {
+        service = "http_status:404"
+      }
+    ]
+  }
+}
+
 # Test 2: Deprecated resource name (cloudflare_tunnel_config)
 resource "cloudflare_zero_trust_tunnel_cloudflared_config" "deprecated_name" {
   account_id = var.cloudflare_account_id
@@ -143,6 +111,7 @@ resource "cloudflare_zero_trust_tunnel_cloudflared_config" "comprehensive" {
         hostname = "app.example.com"
         path     = "/api"
         service  = "http://localhost:8080"
+        # Ingress-level origin_request
         origin_request = {
           connect_timeout          = 15
           tls_timeout              = 5
@@ -166,6 +135,7 @@ resource "cloudflare_zero_trust_tunnel_cloudflared_config" "comprehensive" {
         service = "http_status:404"
       }
     ]
+    # Config-level origin_request
     origin_request = {
       connect_timeout          = 30
       tls_timeout              = 10
@@ -180,6 +150,7 @@ resource "cloudflare_zero_trust_tunnel_cloudflared_config" "comprehensive" {
       proxy_type               = ""
       http2_origin             = false
       no_happy_eyeballs        = false
+      # access block (MaxItems:1 array -> object)
       access = {
         required  = true
         team_name = "my-team"
@@ -194,6 +165,14 @@ moved {
   to   = cloudflare_zero_trust_tunnel_cloudflared_config.comprehensive
 }
 
+# ============================================================================
+# Pattern 9: Cross-resource reference using both v4 names
+# ============================================================================
+# This validates that GetResourceRename() returns ALL v4 names for cross-file reference updates
+# v4 name option 1: cloudflare_tunnel_config
+# v4 name option 2: cloudflare_zero_trust_tunnel_cloudflared_config
+# v5 name: cloudflare_zero_trust_tunnel_cloudflared_config
+
 # Parent tunnels for Pattern 9
 resource "cloudflare_zero_trust_tunnel_cloudflared" "resourcename_opt1" {
   account_id    = var.cloudflare_account_id
@@ -238,6 +217,20 @@ moved {
   to   = cloudflare_zero_trust_tunnel_cloudflared_config.resourcename_opt1
 }
 
+# Resource using v4 name option 2
+resource "cloudflare_zero_trust_tunnel_cloudflared_config" "resourcename_opt2" {
+  account_id = var.cloudflare_account_id
+  tunnel_id  = cloudflare_zero_trust_tunnel_cloudflared.resourcename_opt2.id
+
+  config = {
+    ingress = [
+      {
+        service = "http_status:404"
+      }
+    ]
+  }
+}
+
 # Dependent resource that references option 1
 resource "cloudflare_zero_trust_tunnel_cloudflared_route" "ref_opt1" {
   account_id = var.cloudflare_account_id
@@ -19,8 +19,44 @@ variable "cloudflare_domain" {
 # These tunnel resources are included to support cross-resource
 # references in the tunnel_route resources below
 
+# Basic tunnel with minimal configuration
+resource "cloudflare_zero_trust_tunnel_cloudflared" "minimal" {
+  account_id    = var.cloudflare_account_id
+  name          = "${local.name_prefix}-route-minimal-tunnel"
+  config_src    = "local"
+  tunnel_secret = base64encode("test-secret-that-is-at-least-32-bytes-long")
+}
 
+moved {
+  from = cloudflare_tunnel.minimal
+  to   = cloudflare_zero_trust_tunnel_cloudflared.minimal
+}
 
+# Tunnel with local config source
+resource "cloudflare_zero_trust_tunnel_cloudflared" "local_config" {
+  account_id    = var.cloudflare_account_id
+  name          = "${local.name_prefix}-route-local-config-tunnel"
+  config_src    = "local"
+  tunnel_secret = base64encode("another-secret-32-bytes-or-longer-here")
+}
+
+moved {
+  from = cloudflare_tunnel.local_config
+  to   = cloudflare_zero_trust_tunnel_cloudflared.local_config
+}
+
+# Tunnel with cloudflare config source
+resource "cloudflare_zero_trust_tunnel_cloudflared" "cloudflare_config" {
+  account_id    = var.cloudflare_account_id
+  name          = "${local.name_prefix}-route-cloudflare-config-tunnel"
+  config_src    = "cloudflare"
+  tunnel_secret = base64encode("remote-tunnel-secret-32-bytes-minimum")
+}
+
+moved {
+  from = cloudflare_tunnel.cloudflare_config
+  to   = cloudflare_zero_trust_tunnel_cloudflared.cloudflare_config
+}
 
 # ========================================
 # Tunnel Resources - Advanced Patterns
@@ -46,6 +82,18 @@ locals {
   full_tunnel_name   = "${var.tunnel_prefix}-${local.tunnel_suffix}"
 }
 
+# Tunnel using variables and locals
+resource "cloudflare_zero_trust_tunnel_cloudflared" "with_vars" {
+  account_id    = local.common_account_id
+  name          = "${local.name_prefix}-route-${local.full_tunnel_name}"
+  config_src    = var.config_source
+  tunnel_secret = base64encode(local.tunnel_secret_base)
+}
+
+moved {
+  from = cloudflare_tunnel.with_vars
+  to   = cloudflare_zero_trust_tunnel_cloudflared.with_vars
+}
 
 # for_each tunnels with map
 variable "application_tunnels" {
@@ -69,6 +117,19 @@ variable "application_tunnels" {
   }
 }
 
+resource "cloudflare_zero_trust_tunnel_cloudflared" "applications" {
+  for_each = var.application_tunnels
+
+  account_id    = var.cloudflare_account_id
+  name          = "${local.name_prefix}-route-${each.key}-tunnel"
+  config_src    = each.value.config_src
+  tunnel_secret = base64encode(each.value.secret)
+}
+
+moved {
+  from = cloudflare_tunnel.applications
+  to   = cloudflare_zero_trust_tunnel_cloudflared.applications
+}
 
 # for_each tunnels with list converted to set
 variable "environment_tunnels" {
@@ -96,214 +157,6 @@ variable "environment_tunnels" {
   ]
 }
 
-
-# Count-based tunnel resources
-variable "replica_count" {
-  type    = number
-  default = 3
-}
-
-
-# Conditional tunnel creation
-variable "enable_backup_tunnel" {
-  type    = bool
-  default = true
-}
-
-
-
-
-
-# Tunnel using terraform expressions
-variable "use_cloudflare_config" {
-  type    = bool
-  default = false
-}
-
-
-
-
-# Complex expression for config_src
-variable "is_production" {
-  type    = bool
-  default = true
-}
-
-
-# ========================================
-# Tunnel Route Resources
-# ========================================
-# These resources reference the tunnels defined above
-
-# ========================================
-# Basic Resource Configurations
-# ========================================
-
-
-
-
-
-
-# ========================================
-# Variable-Driven Configurations
-# ========================================
-
-variable "tunnel_networks" {
-  type = map(object({
-    network = string
-    comment = string
-  }))
-  default = {
-    "staging" = {
-      network = "10.10.0.0/16"
-      comment = "Staging environment"
-    }
-    "development" = {
-      network = "10.20.0.0/16"
-      comment = "Development environment"
-    }
-  }
-}
-
-# ========================================
-# Local Values with Expressions
-# ========================================
-
-locals {
-  # Network configuration
-  network_prefix = "10.100"
-  base_comment   = "Automated tunnel route"
-
-  # Computed values
-  primary_network = "${local.network_prefix}.0.0/16"
-  backup_network  = "${local.network_prefix}.128.0/17"
-
-  # Tags for routes
-  environment_tags  = ["production", "automated", "managed"]
-  route_description = "${local.base_comment} - ${local.environment_tags[0]}"
-}
-
-# ========================================
-# Production-Like Patterns
-# ========================================
-
-
-# Pattern 2: for_each with set conversion referencing environment tunnels
-variable "additional_networks" {
-  type = list(object({
-    env     = string
-    network = string
-    comment = string
-  }))
-  default = [
-    {
-      env     = "dev"
-      network = "10.30.0.0/16"
-      comment = "Additional network 1"
-    },
-    {
-      env     = "staging"
-      network = "10.40.0.0/16"
-      comment = "Additional network 2"
-    }
-  ]
-}
-
-
-# Pattern 3: Count-based resources referencing replicas
-variable "subnet_count" {
-  type    = number
-  default = 3
-}
-
-
-# Pattern 4: Conditional resources using locals and backup tunnel
-locals {
-  enable_backup_routes = true
-  backup_networks = local.enable_backup_routes ? [
-    "10.200.0.0/16",
-    "10.201.0.0/16"
-  ] : []
-}
-
-
-# ========================================
-# Edge Cases and Complex Scenarios
-# ========================================
-
-
-
-
-
-
-
-
-# Basic tunnel with minimal configuration
-resource "cloudflare_zero_trust_tunnel_cloudflared" "minimal" {
-  account_id    = var.cloudflare_account_id
-  name          = "${local.name_prefix}-route-minimal-tunnel"
-  config_src    = "local"
-  tunnel_secret = base64encode("test-secret-that-is-at-least-32-bytes-long")
-}
-
-moved {
-  from = cloudflare_tunnel.minimal
-  to   = cloudflare_zero_trust_tunnel_cloudflared.minimal
-}
-
-# Tunnel with local config source
-resource "cloudflare_zero_trust_tunnel_cloudflared" "local_config" {
-  account_id    = var.cloudflare_account_id
-  name          = "${local.name_prefix}-route-local-config-tunnel"
-  config_src    = "local"
-  tunnel_secret = base64encode("another-secret-32-bytes-or-longer-here")
-}
-
-moved {
-  from = cloudflare_tunnel.local_config
-  to   = cloudflare_zero_trust_tunnel_cloudflared.local_config
-}
-
-# Tunnel with cloudflare config source
-resource "cloudflare_zero_trust_tunnel_cloudflared" "cloudflare_config" {
-  account_id    = var.cloudflare_account_id
-  name          = "${local.name_prefix}-route-cloudflare-config-tunnel"
-  config_src    = "cloudflare"
-  tunnel_secret = base64encode("remote-tunnel-secret-32-bytes-minimum")
-}
-
-moved {
-  from = cloudflare_tunnel.cloudflare_config
-  to   = cloudflare_zero_trust_tunnel_cloudflared.cloudflare_config
-}
-
-# Tunnel using variables and locals
-resource "cloudflare_zero_trust_tunnel_cloudflared" "with_vars" {
-  account_id    = local.common_account_id
-  name          = "${local.name_prefix}-route-${local.full_tunnel_name}"
-  config_src    = var.config_source
-  tunnel_secret = base64encode(local.tunnel_secret_base)
-}
-
-moved {
-  from = cloudflare_tunnel.with_vars
-  to   = cloudflare_zero_trust_tunnel_cloudflared.with_vars
-}
-
-resource "cloudflare_zero_trust_tunnel_cloudflared" "applications" {
-  for_each = var.application_tunnels
-
-  account_id    = var.cloudflare_account_id
-  name          = "${local.name_prefix}-route-${each.key}-tunnel"
-  config_src    = each.value.config_src
-  tunnel_secret = base64encode(each.value.secret)
-}
-
-moved {
-  from = cloudflare_tunnel.applications
-  to   = cloudflare_zero_trust_tunnel_cloudflared.applications
-}
-
 resource "cloudflare_zero_trust_tunnel_cloudflared" "environments" {
   for_each = { for idx, tunnel in var.environment_tunnels : tunnel.name => tunnel }
 
@@ -318,6 +171,12 @@ moved {
   to   = cloudflare_zero_trust_tunnel_cloudflared.environments
 }
 
+# Count-based tunnel resources
+variable "replica_count" {
+  type    = number
+  default = 3
+}
+
 resource "cloudflare_zero_trust_tunnel_cloudflared" "replicas" {
   count = var.replica_count
 
@@ -332,6 +191,12 @@ moved {
   to   = cloudflare_zero_trust_tunnel_cloudflared.replicas
 }
 
+# Conditional tunnel creation
+variable "enable_backup_tunnel" {
+  type    = bool
+  default = true
+}
+
 resource "cloudflare_zero_trust_tunnel_cloudflared" "backup" {
   count = var.enable_backup_tunnel ? 1 : 0
 
@@ -389,6 +254,12 @@ moved {
   to   = cloudflare_zero_trust_tunnel_cloudflared.protected
 }
 
+# Tunnel using terraform expressions
+variable "use_cloudflare_config" {
+  type    = bool
+  default = false
+}
+
 resource "cloudflare_zero_trust_tunnel_cloudflared" "conditional_config" {
   account_id    = var.cloudflare_account_id
   name          = "${local.name_prefix}-route-conditional-config-tunnel"
@@ -427,6 +298,12 @@ moved {
   to   = cloudflare_zero_trust_tunnel_cloudflared.interpolated
 }
 
+# Complex expression for config_src
+variable "is_production" {
+  type    = bool
+  default = true
+}
+
 resource "cloudflare_zero_trust_tunnel_cloudflared" "complex_config" {
   account_id    = var.cloudflare_account_id
   name          = "${local.name_prefix}-route-${var.is_production ? "prod" : "dev"}-complex-tunnel"
@@ -439,6 +316,15 @@ moved {
   to   = cloudflare_zero_trust_tunnel_cloudflared.complex_config
 }
 
+# ========================================
+# Tunnel Route Resources
+# ========================================
+# These resources reference the tunnels defined above
+
+# ========================================
+# Basic Resource Configurations
+# ========================================
+
 # Test Case 1: Minimal resource referencing minimal tunnel
 resource "cloudflare_zero_trust_tunnel_cloudflared_route" "minimal" {
   account_id = var.cloudflare_account_id
@@ -503,6 +389,49 @@ moved {
   to   = cloudflare_zero_trust_tunnel_cloudflared_route.special_chars
 }
 
+# ========================================
+# Variable-Driven Configurations
+# ========================================
+
+variable "tunnel_networks" {
+  type = map(object({
+    network = string
+    comment = string
+  }))
+  default = {
+    "staging" = {
+      network = "10.10.0.0/16"
+      comment = "Staging environment"
+    }
+    "development" = {
+      network = "10.20.0.0/16"
+      comment = "Development environment"
+    }
+  }
+}
+
+# ========================================
+# Local Values with Expressions
+# ========================================
+
+locals {
+  # Network configuration
+  network_prefix = "10.100"
+  base_comment   = "Automated tunnel route"
+
+  # Computed values
+  primary_network = "${local.network_prefix}.0.0/16"
+  backup_network  = "${local.network_prefix}.128.0/17"
+
+  # Tags for routes
+  environment_tags  = ["production", "automated", "managed"]
+  route_description = "${local.base_comment} - ${local.environment_tags[0]}"
+}
+
+# ========================================
+# Production-Like Patterns
+# ========================================
+
 # Pattern 1: for_each with map referencing applications tunnels
 resource "cloudflare_zero_trust_tunnel_cloudflared_route" "environment_routes" {
   for_each = var.tunnel_networks
@@ -518,6 +447,27 @@ moved {
   to   = cloudflare_zero_trust_tunnel_cloudflared_route.environment_routes
 }
 
+# Pattern 2: for_each with set conversion referencing environment tunnels
+variable "additional_networks" {
+  type = list(object({
+    env     = string
+    network = string
+    comment = string
+  }))
+  default = [
+    {
+      env     = "dev"
+      network = "10.30.0.0/16"
+      comment = "Additional network 1"
+    },
+    {
+      env     = "staging"
+      network = "10.40.0.0/16"
+      comment = "Additional network 2"
+    }
+  ]
+}
+
 resource "cloudflare_zero_trust_tunnel_cloudflared_route" "additional_routes" {
   for_each = { for idx, net in var.additional_networks : net.network => net }
 
@@ -532,6 +482,12 @@ moved {
   to   = cloudflare_zero_trust_tunnel_cloudflared_route.additional_routes
 }
 
+# Pattern 3: Count-based resources referencing replicas
+variable "subnet_count" {
+  type    = number
+  default = 3
+}
+
 resource "cloudflare_zero_trust_tunnel_cloudflared_route" "subnet_routes" {
   count = var.subnet_count
 
@@ -546,6 +502,15 @@ moved {
   to   = cloudflare_zero_trust_tunnel_cloudflared_route.subnet_routes
 }
 
+# Pattern 4: Conditional resources using locals and backup tunnel
+locals {
+  enable_backup_routes = true
+  backup_networks = local.enable_backup_routes ? [
+    "10.200.0.0/16",
+    "10.201.0.0/16"
+  ] : []
+}
+
 resource "cloudflare_zero_trust_tunnel_cloudflared_route" "backup_routes" {
   for_each = toset(local.backup_networks)
 
@@ -560,6 +525,10 @@ moved {
   to   = cloudflare_zero_trust_tunnel_cloudflared_route.backup_routes
 }
 
+# ========================================
+# Edge Cases and Complex Scenarios
+# ========================================
+
 # Test Case 6: Private IPv4 ranges referencing secondary tunnel
 resource "cloudflare_zero_trust_tunnel_cloudflared_route" "private_ranges" {
   account_id = var.cloudflare_account_id
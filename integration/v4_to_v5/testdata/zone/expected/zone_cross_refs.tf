@@ -1,13 +1,6 @@
 # Cross-file references to cloudflare_zone.*.zone
 # These should be rewritten to .name after migration
 
-
-
-# Pattern: .zone reference in a local
-locals {
-  cftftest_zone_domain = cloudflare_zone.minimal.name
-}
-
 # Pattern: Direct .zone reference in another resource
 resource "cloudflare_dns_record" "cftftest_zone_xref" {
   zone_id = cloudflare_zone.minimal.id
@@ -35,3 +28,8 @@ moved {
   from = cloudflare_record.cftftest_zone_xref_interp
   to   = cloudflare_dns_record.cftftest_zone_xref_interp
 }
+
+# Pattern: .zone reference in a local
+locals {
+  cftftest_zone_domain = cloudflare_zone.minimal.name
+}
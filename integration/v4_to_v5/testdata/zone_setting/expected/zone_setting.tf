@@ -21,29 +21,6 @@ locals {
   cache_ttls      = [14400, 28800, 43200]
 }
 
-
-
-
-# Test Cases 4-6: Removed - Invalid Pattern
-# Multiple zone_settings_override resources for the same zone will conflict
-# In v4, cloudflare_zone_settings_override manages ALL settings for a zone
-# Having multiple such resources causes them to overwrite each other
-# This pattern is not supported and should not be used
-
-# Test Case 7: Conditional creation
-locals {
-  enable_advanced_settings = true
-  enable_test_settings     = false
-}
-
-
-
-
-
-
-
-
-
 resource "cloudflare_zone_setting" "minimal_always_online" {
   zone_id    = var.cloudflare_zone_id
   setting_id = "always_online"
@@ -151,6 +128,18 @@ removed {
   #   to = module.mymod.cloudflare_zone_setting.with_security_header_<setting>
 }
 
+# Test Cases 4-6: Removed - Invalid Pattern
+# Multiple zone_settings_override resources for the same zone will conflict
+# In v4, cloudflare_zone_settings_override manages ALL settings for a zone
+# Having multiple such resources causes them to overwrite each other
+# This pattern is not supported and should not be used
+
+# Test Case 7: Conditional creation
+locals {
+  enable_advanced_settings = true
+  enable_test_settings     = false
+}
+
 resource "cloudflare_zone_setting" "conditional_enabled_rocket_loader" {
   zone_id    = var.cloudflare_zone_id
   setting_id = "rocket_loader"
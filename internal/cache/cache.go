@@ -0,0 +1,200 @@
+// Package cache keeps the result of migrating a file on disk, so that a
+// repeated run over the same configuration skips the files whose inputs did
+// not change.
+//
+// An entry is looked up by a key that hashes the file's path and content
+// together with the fingerprint the cache was opened with. The fingerprint
+// covers everything else the migration of a file depends on — the tool
+// version, the selected resources, rule files and so on — so an entry is
+// never reused across runs that could migrate the file differently. Entries
+// are never updated in place: a changed input gives a new key.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+// formatVersion is part of every key, so that entries written in an older
+// layout are not read back.
+const formatVersion = "1"
+
+// Entry is the cached result of migrating one file.
+type Entry struct {
+	Content     string       `json:"content"`
+	Diagnostics []Diagnostic `json:"diagnostics,omitempty"`
+	// MigrationBlocks is the HCL of the moved, import and removed blocks
+	// collected for --migrations-file.
+	MigrationBlocks string `json:"migration_blocks,omitempty"`
+}
+
+// Diagnostic is the part of an hcl.Diagnostic that survives a round trip
+// through the cache.
+type Diagnostic struct {
+	Severity hcl.DiagnosticSeverity `json:"severity"`
+	Summary  string                 `json:"summary"`
+	Detail   string                 `json:"detail,omitempty"`
+	Subject  *hcl.Range             `json:"subject,omitempty"`
+	Context  *hcl.Range             `json:"context,omitempty"`
+}
+
+// NewDiagnostics converts diags for an Entry.
+func NewDiagnostics(diags hcl.Diagnostics) []Diagnostic {
+	var out []Diagnostic
+	for _, d := range diags {
+		out = append(out, Diagnostic{
+			Severity: d.Severity,
+			Summary:  d.Summary,
+			Detail:   d.Detail,
+			Subject:  d.Subject,
+			Context:  d.Context,
+		})
+	}
+	return out
+}
+
+// HCL converts the diagnostics of an Entry back.
+func (e *Entry) HCL() hcl.Diagnostics {
+	diags := make(hcl.Diagnostics, 0, len(e.Diagnostics))
+	for _, d := range e.Diagnostics {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: d.Severity,
+			Summary:  d.Summary,
+			Detail:   d.Detail,
+			Subject:  d.Subject,
+			Context:  d.Context,
+		})
+	}
+	return diags
+}
+
+// Cache is a directory of entries. Its methods are safe for concurrent use,
+// and a nil *Cache is an empty cache that stores nothing.
+type Cache struct {
+	dir         string
+	fingerprint string
+	stats       *stats
+}
+
+type stats struct {
+	hits, misses atomic.Int64
+}
+
+// Open opens the cache in dir, creating the directory if needed. Entries are
+// only found by caches opened with the same fingerprint.
+func Open(dir string, fingerprint ...string) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	return &Cache{
+		dir:         dir,
+		fingerprint: Digest(append([]string{formatVersion}, fingerprint...)...),
+		stats:       &stats{},
+	}, nil
+}
+
+// With returns a cache over the same directory and statistics whose
+// fingerprint is extended with parts.
+func (c *Cache) With(parts ...string) *Cache {
+	if c == nil {
+		return nil
+	}
+	return &Cache{
+		dir:         c.dir,
+		fingerprint: Digest(append([]string{c.fingerprint}, parts...)...),
+		stats:       c.stats,
+	}
+}
+
+// Key returns the key of the file at path with content, and of extra, the
+// inputs of the file that are not shared by every file.
+func (c *Cache) Key(path string, content []byte, extra ...string) string {
+	return Digest(append([]string{c.fingerprint, path, string(content)}, extra...)...)
+}
+
+// Get returns the entry stored under key. An entry that cannot be read is
+// treated as missing.
+func (c *Cache) Get(key string) (*Entry, bool) {
+	if c == nil {
+		return nil, false
+	}
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		c.stats.misses.Add(1)
+		return nil, false
+	}
+	var e Entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		c.stats.misses.Add(1)
+		return nil, false
+	}
+	c.stats.hits.Add(1)
+	return &e, true
+}
+
+// Put stores e under key. The entry is written to a temporary file and
+// renamed into place, so a concurrent Get never reads half an entry.
+func (c *Cache) Put(key string, e *Entry) error {
+	if c == nil {
+		return nil
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	path := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".entry-*")
+	if err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	return nil
+}
+
+// Stats returns how many lookups found an entry and how many did not.
+func (c *Cache) Stats() (hits, misses int) {
+	if c == nil {
+		return 0, 0
+	}
+	return int(c.stats.hits.Load()), int(c.stats.misses.Load())
+}
+
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.dir, key[:2], key+".json")
+}
+
+// Digest returns a hex SHA-256 of parts. Every part is length-prefixed, so
+// that no two different lists of parts share a digest.
+func Digest(parts ...string) string {
+	h := sha256.New()
+	var n [8]byte
+	for _, part := range parts {
+		binary.BigEndian.PutUint64(n[:], uint64(len(part)))
+		h.Write(n[:])
+		h.Write([]byte(part))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
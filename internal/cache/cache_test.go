@@ -0,0 +1,65 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache(t *testing.T) {
+	dir := t.TempDir()
+	c, err := Open(dir, "v1.0.0", "dns_record")
+	require.NoError(t, err)
+
+	key := c.Key("main.tf", []byte(`resource "cloudflare_record" "r" {}`))
+	_, ok := c.Get(key)
+	assert.False(t, ok)
+
+	diags := hcl.Diagnostics{{
+		Severity: hcl.DiagWarning,
+		Summary:  "check r",
+		Subject:  &hcl.Range{Filename: "main.tf", Start: hcl.Pos{Line: 1, Column: 1}, End: hcl.Pos{Line: 1, Column: 9}},
+	}}
+	require.NoError(t, c.Put(key, &Entry{Content: "migrated", Diagnostics: NewDiagnostics(diags)}))
+
+	entry, ok := c.Get(key)
+	require.True(t, ok)
+	assert.Equal(t, "migrated", entry.Content)
+	assert.Equal(t, diags, entry.HCL())
+	hits, misses := c.Stats()
+	assert.Equal(t, 1, hits)
+	assert.Equal(t, 1, misses)
+
+	t.Run("keys", func(t *testing.T) {
+		other, err := Open(dir, "v1.0.1", "dns_record")
+		require.NoError(t, err)
+		assert.NotEqual(t, key, other.Key("main.tf", []byte(`resource "cloudflare_record" "r" {}`)))
+		assert.NotEqual(t, key, c.Key("other.tf", []byte(`resource "cloudflare_record" "r" {}`)))
+		assert.NotEqual(t, key, c.Key("main.tf", []byte(`resource "cloudflare_record" "s" {}`)))
+		assert.NotEqual(t, key, c.Key("main.tf", []byte(`resource "cloudflare_record" "r" {}`), "locals"))
+		assert.NotEqual(t, key, c.With("scanned").Key("main.tf", []byte(`resource "cloudflare_record" "r" {}`)))
+	})
+
+	t.Run("corrupt entry", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, key[:2], key+".json"), []byte("{"), 0644))
+		_, ok := c.Get(key)
+		assert.False(t, ok)
+	})
+
+	t.Run("nil cache", func(t *testing.T) {
+		var c *Cache
+		_, ok := c.Get(key)
+		assert.False(t, ok)
+		assert.NoError(t, c.Put(key, &Entry{}))
+		assert.Nil(t, c.With("scanned"))
+	})
+}
+
+func TestDigest(t *testing.T) {
+	assert.NotEqual(t, Digest("ab", "c"), Digest("a", "bc"))
+	assert.Equal(t, Digest("a", "b"), Digest("a", "b"))
+}
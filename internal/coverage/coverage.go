@@ -0,0 +1,359 @@
+// Package coverage builds an attribute-level coverage matrix of the
+// registered migrators. Every attribute of a v4 resource type is synthesised
+// into a configuration, migrated through the configuration pipeline, and
+// located in the output, which is checked against the v5 provider schema.
+// This shows, per attribute, whether a migrator renames, converts or drops
+// it, or passes it through into configuration the v5 provider rejects.
+package coverage
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/hcl/v2"
+
+	"github.com/cloudflare/tf-migrate/internal/pipeline"
+	"github.com/cloudflare/tf-migrate/internal/schema"
+	"github.com/cloudflare/tf-migrate/internal/transform"
+)
+
+// Status is what a migrator does with a v4 attribute.
+type Status string
+
+const (
+	// StatusUnchanged means the attribute is kept as is and is valid in v5.
+	StatusUnchanged Status = "unchanged"
+	// StatusRenamed means the value moved to another attribute that is valid in v5.
+	StatusRenamed Status = "renamed"
+	// StatusConverted means the attribute is kept under the same name, but
+	// its value or form changed, e.g. a block became an attribute.
+	StatusConverted Status = "converted"
+	// StatusDropped means the attribute was removed and a diagnostic tells
+	// the user about it.
+	StatusDropped Status = "dropped"
+	// StatusDroppedSilently means the attribute was removed without a diagnostic.
+	StatusDroppedSilently Status = "dropped-silently"
+	// StatusInvalid means the attribute was passed through to configuration
+	// that is not valid in the v5 schema.
+	StatusInvalid Status = "invalid"
+	// StatusError means the migrator failed on the synthesised configuration.
+	StatusError Status = "error"
+)
+
+// Statuses lists every status in report order.
+var Statuses = []Status{StatusUnchanged, StatusRenamed, StatusConverted, StatusDropped, StatusDroppedSilently, StatusInvalid, StatusError}
+
+// AttributeResult is the coverage of a single v4 attribute.
+type AttributeResult struct {
+	// Path is the attribute path in the v4 schema, e.g. "rules.action".
+	Path   string `json:"path"`
+	Status Status `json:"status"`
+	// Target is where the value ended up in the v5 configuration, for
+	// renamed and invalid attributes. It is prefixed with the resource type
+	// when the value moved to a different resource.
+	Target string `json:"target,omitempty"`
+	// Diagnostic is the summary of the diagnostic explaining a dropped
+	// attribute, or the error of a failed migration.
+	Diagnostic string `json:"diagnostic,omitempty"`
+}
+
+// ResourceResult is the coverage of a v4 resource or data source type.
+type ResourceResult struct {
+	// Type is the v4 type; data sources are prefixed with "data.".
+	Type string `json:"type"`
+	// TargetType is the type the migrator writes, when it has a v5 schema.
+	TargetType string `json:"target_type,omitempty"`
+	// NoMigrator is set when no migrator is registered for the type.
+	NoMigrator bool `json:"no_migrator,omitempty"`
+	// Error is set when the migrator fails on the required attributes alone.
+	Error      string            `json:"error,omitempty"`
+	Attributes []AttributeResult `json:"attributes,omitempty"`
+}
+
+// Matrix is the coverage of every v4 type.
+type Matrix struct {
+	Resources []ResourceResult `json:"resources"`
+}
+
+// Counts returns the number of attributes with each status.
+func (m *Matrix) Counts() map[Status]int {
+	counts := make(map[Status]int)
+	for _, r := range m.Resources {
+		for _, a := range r.Attributes {
+			counts[a.Status]++
+		}
+	}
+	return counts
+}
+
+// Options configures Build.
+type Options struct {
+	V4 *schema.ProviderSchema
+	V5 *schema.ProviderSchema
+	// Providers looks up the migrators to exercise.
+	Providers     transform.MigrationProvider
+	SourceVersion string
+	TargetVersion string
+	// Types restricts the matrix to these v4 types, e.g. "cloudflare_record"
+	// or "data.cloudflare_zone". All types in the v4 schema are covered when
+	// it is empty.
+	Types []string
+	Log   hclog.Logger
+}
+
+// Build exercises the migrator of every v4 type in opts.V4 and returns the
+// coverage matrix.
+func Build(opts Options) *Matrix {
+	if opts.Log == nil {
+		opts.Log = hclog.NewNullLogger()
+	}
+	b := &builder{opts: opts, pipeline: pipeline.BuildConfigPipeline(opts.Log, opts.Providers)}
+
+	matrix := &Matrix{}
+	for _, t := range b.types() {
+		matrix.Resources = append(matrix.Resources, b.resource(t))
+	}
+	return matrix
+}
+
+// v4Type is a resource or data source type of the v4 schema.
+type v4Type struct {
+	name   string // as keyed in the registry, e.g. "data.cloudflare_zone"
+	label  string // the block label, e.g. "cloudflare_zone"
+	data   bool
+	schema *schema.Schema
+}
+
+type builder struct {
+	opts     Options
+	pipeline *pipeline.Pipeline
+}
+
+func (b *builder) types() []v4Type {
+	var types []v4Type
+	for _, name := range sortedKeys(b.opts.V4.ResourceSchemas) {
+		types = append(types, v4Type{name: name, label: name, schema: b.opts.V4.ResourceSchemas[name]})
+	}
+	for _, name := range sortedKeys(b.opts.V4.DataSourceSchemas) {
+		types = append(types, v4Type{name: "data." + name, label: name, data: true, schema: b.opts.V4.DataSourceSchemas[name]})
+	}
+	if len(b.opts.Types) == 0 {
+		return types
+	}
+
+	wanted := make(map[string]bool)
+	for _, t := range b.opts.Types {
+		wanted[t] = true
+	}
+	var filtered []v4Type
+	for _, t := range types {
+		if wanted[t.name] {
+			filtered = append(filtered, t)
+		}
+	}
+	return filtered
+}
+
+func (b *builder) resource(t v4Type) ResourceResult {
+	result := ResourceResult{Type: t.name}
+	if b.opts.Providers.GetMigrator(t.name, b.opts.SourceVersion, b.opts.TargetVersion) == nil {
+		result.NoMigrator = true
+		return result
+	}
+	if t.schema == nil || t.schema.Block == nil {
+		return result
+	}
+
+	baseline := b.run(t, nil)
+	if baseline.err != nil {
+		result.Error = baseline.err.Error()
+		return result
+	}
+	result.TargetType = b.targetType(baseline)
+
+	for _, path := range attributePaths(t.schema.Block, nil) {
+		result.Attributes = append(result.Attributes, b.attribute(t, path, baseline, result.TargetType))
+	}
+	return result
+}
+
+// targetType returns the type of the first block in the baseline output
+// that the v5 schema defines.
+func (b *builder) targetType(baseline *run) string {
+	for _, block := range baseline.output {
+		if b.v5Schema(block.data, block.resourceType) != nil {
+			return block.resourceType
+		}
+	}
+	return ""
+}
+
+func (b *builder) attribute(t v4Type, path []string, baseline *run, targetType string) AttributeResult {
+	result := AttributeResult{Path: strings.Join(path, ".")}
+
+	r := baseline
+	if !r.covers(path) {
+		r = b.run(t, path)
+	}
+	if r.err != nil {
+		result.Status = StatusError
+		result.Diagnostic = r.err.Error()
+		return result
+	}
+
+	input := r.input[result.Path]
+	candidates := r.find(path)
+	if len(candidates) == 0 {
+		if diag := r.explain(path, baseline); diag != nil {
+			result.Status = StatusDropped
+			result.Diagnostic = diag.Summary
+		} else {
+			result.Status = StatusDroppedSilently
+		}
+		return result
+	}
+
+	best, valid, bestPath := b.best(candidates, path)
+	target := strings.Join(bestPath, ".")
+	if best.resourceType != targetType {
+		target = best.resourceType + "." + target
+	}
+	switch {
+	case !valid:
+		result.Status = StatusInvalid
+		result.Target = target
+	case target != result.Path:
+		result.Status = StatusRenamed
+		result.Target = target
+	case best.form[:len(bestPath)] != input.form || normalize(best.source) != normalize(input.source):
+		result.Status = StatusConverted
+	default:
+		result.Status = StatusUnchanged
+	}
+	return result
+}
+
+// best picks the occurrence of an attribute value in the output that
+// explains it best: one valid in v5 over one that is not, then one at the
+// original path, then the most specific one. It returns the occurrence,
+// whether it is valid in v5, and its path as far as the v5 schema defines it.
+func (b *builder) best(candidates []occurrence, path []string) (occurrence, bool, []string) {
+	type scored struct {
+		occ   occurrence
+		valid bool
+		path  []string
+	}
+	var all []scored
+	for _, c := range candidates {
+		valid, n := resolve(b.v5Schema(c.data, c.resourceType), c.path)
+		all = append(all, scored{occ: c, valid: valid, path: c.path[:n]})
+	}
+	original := strings.Join(path, ".")
+	sort.SliceStable(all, func(i, j int) bool {
+		if all[i].valid != all[j].valid {
+			return all[i].valid
+		}
+		iSame, jSame := strings.Join(all[i].path, ".") == original, strings.Join(all[j].path, ".") == original
+		if iSame != jSame {
+			return iSame
+		}
+		return len(all[i].occ.path) > len(all[j].occ.path)
+	})
+	return all[0].occ, all[0].valid, all[0].path
+}
+
+func (b *builder) v5Schema(data bool, resourceType string) *schema.Schema {
+	if data {
+		return b.opts.V5.DataSourceSchemas[resourceType]
+	}
+	return b.opts.V5.ResourceSchemas[resourceType]
+}
+
+// resolve walks path through s. It reports whether the path names an
+// attribute or block that can be set in configuration, and how many segments
+// of path the schema describes: segments below an attribute of a plain
+// object or map type are keys of its value.
+func resolve(s *schema.Schema, path []string) (bool, int) {
+	if s == nil || s.Block == nil {
+		return false, len(path)
+	}
+	block := s.Block
+	var nested *schema.NestedType
+	for i, name := range path {
+		var attr *schema.Attribute
+		if nested != nil {
+			attr = nested.Attributes[name]
+		} else {
+			attr = block.Attributes[name]
+			if attr == nil && block.BlockTypes[name] != nil && block.BlockTypes[name].Block != nil {
+				block = block.BlockTypes[name].Block
+				if i == len(path)-1 {
+					return true, len(path)
+				}
+				continue
+			}
+		}
+		switch {
+		case attr == nil:
+			return false, i + 1
+		case attr.ComputedOnly():
+			return false, i + 1
+		case attr.NestedType == nil || i == len(path)-1:
+			return true, i + 1
+		}
+		nested = attr.NestedType
+	}
+	return true, len(path)
+}
+
+// explain returns the diagnostic that explains why the attribute at path is
+// missing from the output: a diagnostic not raised for the required
+// attributes alone, or else one that mentions the attribute by name.
+func (r *run) explain(path []string, baseline *run) *hcl.Diagnostic {
+	seen := make(map[string]bool)
+	for _, diag := range baseline.diags {
+		seen[diag.Summary+diag.Detail] = true
+	}
+	if r != baseline {
+		for _, diag := range r.diags {
+			if !seen[diag.Summary+diag.Detail] {
+				return diag
+			}
+		}
+	}
+	name := path[len(path)-1]
+	for _, diag := range r.diags {
+		if mentions(diag.Summary, name) || mentions(diag.Detail, name) {
+			return diag
+		}
+	}
+	return nil
+}
+
+func normalize(source string) string {
+	return strings.Join(strings.Fields(source), "")
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// String returns a short description of the result, e.g. "renamed to content".
+func (a AttributeResult) String() string {
+	switch {
+	case a.Target != "" && a.Status == StatusRenamed:
+		return fmt.Sprintf("%s to %s", a.Status, a.Target)
+	case a.Target != "":
+		return fmt.Sprintf("%s as %s", a.Status, a.Target)
+	case a.Diagnostic != "":
+		return fmt.Sprintf("%s: %s", a.Status, a.Diagnostic)
+	}
+	return string(a.Status)
+}
@@ -0,0 +1,194 @@
+package coverage
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudflare/tf-migrate/internal/schema"
+	"github.com/cloudflare/tf-migrate/internal/transform"
+	tfhcl "github.com/cloudflare/tf-migrate/internal/transform/hcl"
+)
+
+const v4Schema = `{
+  "format_version": "1.0",
+  "provider_schemas": {
+    "registry.terraform.io/cloudflare/cloudflare": {
+      "resource_schemas": {
+        "cloudflare_widget": {
+          "version": 0,
+          "block": {
+            "attributes": {
+              "id": {"type": "string", "optional": true, "computed": true},
+              "zone_id": {"type": "string", "required": true},
+              "value": {"type": "string", "optional": true},
+              "enabled": {"type": "bool", "optional": true},
+              "legacy": {"type": "string", "optional": true},
+              "obsolete": {"type": "number", "optional": true},
+              "unknown": {"type": "string", "optional": true},
+              "hostname": {"type": "string", "computed": true}
+            },
+            "block_types": {
+              "settings": {
+                "nesting_mode": "list",
+                "max_items": 1,
+                "block": {"attributes": {"mode": {"type": "string", "optional": true}}}
+              }
+            }
+          }
+        },
+        "cloudflare_gadget": {
+          "version": 0,
+          "block": {"attributes": {"name": {"type": "string", "optional": true}}}
+        },
+        "cloudflare_broken": {
+          "version": 0,
+          "block": {"attributes": {"name": {"type": "string", "required": true}}}
+        }
+      }
+    }
+  }
+}`
+
+const v5Schema = `{
+  "format_version": "1.0",
+  "provider_schemas": {
+    "registry.terraform.io/cloudflare/cloudflare": {
+      "resource_schemas": {
+        "cloudflare_gizmo": {
+          "version": 0,
+          "block": {
+            "attributes": {
+              "id": {"type": "string", "computed": true},
+              "zone_id": {"type": "string", "required": true},
+              "content": {"type": "string", "optional": true},
+              "enabled": {"type": "bool", "optional": true},
+              "legacy": {"type": "string", "optional": true},
+              "settings": {
+                "nested_type": {"attributes": {"mode": {"type": "string", "optional": true}}, "nesting_mode": "single"},
+                "optional": true
+              }
+            }
+          }
+        }
+      }
+    }
+  }
+}`
+
+// widgetMigrator renames cloudflare_widget to cloudflare_gizmo and exercises
+// every status but unchanged on one attribute each.
+type widgetMigrator struct{}
+
+func (m *widgetMigrator) CanHandle(resourceType string) bool { return resourceType == "cloudflare_widget" }
+func (m *widgetMigrator) GetResourceType() string            { return "cloudflare_gizmo" }
+func (m *widgetMigrator) Preprocess(content string) string   { return content }
+
+func (m *widgetMigrator) TransformConfig(ctx *transform.Context, block *hclwrite.Block) (*transform.TransformResult, error) {
+	body := block.Body()
+	tfhcl.RenameResourceType(block, "cloudflare_widget", "cloudflare_gizmo")
+	tfhcl.RenameAttribute(body, "value", "content")
+	if body.GetAttribute("legacy") != nil {
+		body.RemoveAttribute("legacy")
+		ctx.Diagnostics = append(ctx.Diagnostics, &hcl.Diagnostic{
+			Severity: hcl.DiagWarning,
+			Summary:  "legacy was removed",
+		})
+	}
+	tfhcl.RemoveAttributes(body, "obsolete")
+	tfhcl.ConvertBlocksToAttribute(body, "settings", "settings", nil)
+	return &transform.TransformResult{Blocks: []*hclwrite.Block{block}}, nil
+}
+
+// brokenMigrator fails on every configuration.
+type brokenMigrator struct{ widgetMigrator }
+
+func (m *brokenMigrator) TransformConfig(*transform.Context, *hclwrite.Block) (*transform.TransformResult, error) {
+	panic("boom")
+}
+
+func buildTestMatrix(t *testing.T) *Matrix {
+	t.Helper()
+	v4, err := schema.Parse([]byte(v4Schema))
+	require.NoError(t, err)
+	v5, err := schema.Parse([]byte(v5Schema))
+	require.NoError(t, err)
+
+	migrators := map[string]transform.ResourceTransformer{
+		"cloudflare_widget": &widgetMigrator{},
+		"cloudflare_broken": &brokenMigrator{},
+	}
+	providers := transform.NewMigrationProvider(
+		func(resourceType, source, target string) transform.ResourceTransformer {
+			return migrators[resourceType]
+		},
+		func(source, target string, resources ...string) []transform.ResourceTransformer {
+			return []transform.ResourceTransformer{migrators["cloudflare_widget"], migrators["cloudflare_broken"]}
+		},
+	)
+	return Build(Options{V4: v4, V5: v5, Providers: providers, SourceVersion: "v4", TargetVersion: "v5"})
+}
+
+func TestBuild(t *testing.T) {
+	matrix := buildTestMatrix(t)
+	require.Len(t, matrix.Resources, 3)
+
+	broken := matrix.Resources[0]
+	assert.Equal(t, "cloudflare_broken", broken.Type)
+	assert.Contains(t, broken.Error, "migrator panicked: boom")
+
+	gadget := matrix.Resources[1]
+	assert.Equal(t, "cloudflare_gadget", gadget.Type)
+	assert.True(t, gadget.NoMigrator)
+
+	widget := matrix.Resources[2]
+	assert.Equal(t, "cloudflare_widget", widget.Type)
+	assert.Equal(t, "cloudflare_gizmo", widget.TargetType)
+	assert.Equal(t, []AttributeResult{
+		{Path: "enabled", Status: StatusUnchanged},
+		{Path: "legacy", Status: StatusDropped, Diagnostic: "legacy was removed"},
+		{Path: "obsolete", Status: StatusDroppedSilently},
+		{Path: "unknown", Status: StatusInvalid, Target: "unknown"},
+		{Path: "value", Status: StatusRenamed, Target: "content"},
+		{Path: "zone_id", Status: StatusUnchanged},
+		{Path: "settings.mode", Status: StatusConverted},
+	}, widget.Attributes)
+
+	counts := matrix.Counts()
+	assert.Equal(t, 2, counts[StatusUnchanged])
+	assert.Equal(t, 1, counts[StatusInvalid])
+}
+
+func TestBuildTypes(t *testing.T) {
+	v4, err := schema.Parse([]byte(v4Schema))
+	require.NoError(t, err)
+	v5, err := schema.Parse([]byte(v5Schema))
+	require.NoError(t, err)
+
+	providers := transform.NewMigrationProvider(nil, nil)
+	matrix := Build(Options{V4: v4, V5: v5, Providers: providers, Types: []string{"cloudflare_gadget"}})
+	require.Len(t, matrix.Resources, 1)
+	assert.Equal(t, "cloudflare_gadget", matrix.Resources[0].Type)
+}
+
+func TestWriteText(t *testing.T) {
+	matrix := buildTestMatrix(t)
+
+	var all bytes.Buffer
+	require.NoError(t, WriteText(&all, matrix, false))
+	assert.Contains(t, all.String(), "cloudflare_widget → cloudflare_gizmo\n")
+	assert.Contains(t, all.String(), "renamed to content")
+	assert.Contains(t, all.String(), "cloudflare_gadget  no migrator")
+	assert.Contains(t, all.String(), "3 type(s), 7 attribute(s): 2 unchanged, 1 renamed, 1 converted, 1 dropped, 1 dropped-silently, 1 invalid\n")
+
+	var problems bytes.Buffer
+	require.NoError(t, WriteText(&problems, matrix, true))
+	assert.NotContains(t, problems.String(), "renamed to content")
+	assert.NotContains(t, problems.String(), "no migrator")
+	assert.Contains(t, problems.String(), "invalid as unknown")
+	assert.Contains(t, problems.String(), "dropped-silently")
+}
@@ -0,0 +1,76 @@
+package coverage
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// IsProblem reports whether a status needs attention from the author of the
+// migrator: the attribute is lost without telling the user, or produces
+// configuration the v5 provider rejects.
+func (s Status) IsProblem() bool {
+	return s == StatusDroppedSilently || s == StatusInvalid || s == StatusError
+}
+
+// WriteText writes the matrix as a table per type. With problemsOnly, only
+// attributes whose status is a problem, and types with such attributes, are
+// written.
+func WriteText(w io.Writer, m *Matrix, problemsOnly bool) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	types, attributes := 0, 0
+	for _, r := range m.Resources {
+		var rows []AttributeResult
+		for _, a := range r.Attributes {
+			if !problemsOnly || a.Status.IsProblem() {
+				rows = append(rows, a)
+			}
+		}
+		attributes += len(r.Attributes)
+		types++
+
+		switch {
+		case r.NoMigrator:
+			if !problemsOnly {
+				fmt.Fprintf(tw, "%s\tno migrator\n", r.Type)
+			}
+			continue
+		case r.Error != "":
+			fmt.Fprintf(tw, "%s\terror: %s\n", r.Type, r.Error)
+			continue
+		case len(rows) == 0 && problemsOnly:
+			continue
+		}
+
+		header := r.Type
+		if r.TargetType != "" && r.TargetType != strings.TrimPrefix(r.Type, "data.") {
+			header += " → " + r.TargetType
+		}
+		fmt.Fprintf(tw, "%s\n", header)
+		for _, a := range rows {
+			fmt.Fprintf(tw, "  %s\t%s\n", a.Path, a)
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	counts := m.Counts()
+	var parts []string
+	for _, s := range Statuses {
+		if counts[s] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[s], s))
+		}
+	}
+	_, err := fmt.Fprintf(w, "\n%d type(s), %d attribute(s): %s\n", types, attributes, strings.Join(parts, ", "))
+	return err
+}
+
+// WriteJSON writes the matrix as indented JSON.
+func WriteJSON(w io.Writer, m *Matrix) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(m)
+}
@@ -0,0 +1,388 @@
+package coverage
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+
+	"github.com/cloudflare/tf-migrate/internal/schema"
+	"github.com/cloudflare/tf-migrate/internal/transform"
+)
+
+// run is the migration of one synthesised configuration.
+type run struct {
+	// tokens maps the path of every synthesised attribute to the placeholder
+	// that identifies its value, or "" for booleans and blocks, which can
+	// only be found by path.
+	tokens map[string]string
+	input  map[string]occurrence
+	output []outputBlock
+	diags  hcl.Diagnostics
+	err    error
+}
+
+// outputBlock is a resource or data block of the migrated configuration.
+type outputBlock struct {
+	resourceType string
+	data         bool
+	occurrences  []occurrence
+}
+
+// occurrence is an attribute, block or object key found in a configuration.
+type occurrence struct {
+	resourceType string
+	data         bool
+	path         []string
+	// form has a letter per path segment: "a" for an attribute, "b" for a
+	// block and "o" for a key of an object value.
+	form   string
+	source string
+}
+
+// run synthesises a configuration with the required attributes of t and the
+// attribute at target, and migrates it. With a nil target, only the
+// required attributes are set.
+func (b *builder) run(t v4Type, target []string) *run {
+	r := &run{tokens: make(map[string]string), input: make(map[string]occurrence)}
+
+	kind := "resource"
+	if t.data {
+		kind = "data"
+	}
+	file := hclwrite.NewEmptyFile()
+	block := file.Body().AppendNewBlock(kind, []string{t.label, "coverage"})
+	synthesise(block.Body(), t.schema.Block, nil, target, &generator{}, r.tokens)
+	content := file.Bytes()
+
+	for _, block := range parseBlocks(content) {
+		for _, occ := range block.occurrences {
+			r.input[strings.Join(occ.path, ".")] = occ
+		}
+	}
+
+	ctx := &transform.Context{
+		Content:       content,
+		Filename:      "main.tf",
+		FilePath:      "main.tf",
+		Diagnostics:   make(hcl.Diagnostics, 0),
+		Metadata:      make(map[string]interface{}),
+		SourceVersion: b.opts.SourceVersion,
+		TargetVersion: b.opts.TargetVersion,
+	}
+	output, err := b.transform(ctx)
+	r.diags = ctx.Diagnostics
+	if err != nil {
+		r.err = err
+		return r
+	}
+	r.output = parseBlocks(output)
+	return r
+}
+
+// transform runs the pipeline, turning a panic of a migrator into an error.
+func (b *builder) transform(ctx *transform.Context) (output []byte, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("migrator panicked: %v", p)
+		}
+	}()
+	return b.pipeline.Transform(ctx)
+}
+
+// covers reports whether the run synthesised the attribute at path.
+func (r *run) covers(path []string) bool {
+	_, ok := r.tokens[strings.Join(path, ".")]
+	return ok
+}
+
+// find returns where the attribute at path ended up in the output: the
+// most specific occurrences holding its placeholder, or for values without
+// a placeholder, the occurrences at the same path.
+func (r *run) find(path []string) []occurrence {
+	var matches []occurrence
+	var pattern *regexp.Regexp
+	if token := r.tokens[strings.Join(path, ".")]; token != "" {
+		pattern = regexp.MustCompile(`\b` + regexp.QuoteMeta(token) + `\b`)
+	}
+	for _, block := range r.output {
+		var blockMatches []occurrence
+		for _, occ := range block.occurrences {
+			if pattern != nil && pattern.MatchString(occ.source) || pattern == nil && strings.Join(occ.path, ".") == strings.Join(path, ".") {
+				blockMatches = append(blockMatches, occ)
+			}
+		}
+		for _, occ := range blockMatches {
+			if !hasMoreSpecific(occ, blockMatches) {
+				matches = append(matches, occ)
+			}
+		}
+	}
+	return matches
+}
+
+func hasMoreSpecific(occ occurrence, occurrences []occurrence) bool {
+	prefix := strings.Join(occ.path, ".") + "."
+	for _, other := range occurrences {
+		if strings.HasPrefix(strings.Join(other.path, ".")+".", prefix) && len(other.path) > len(occ.path) {
+			return true
+		}
+	}
+	return false
+}
+
+// attributePaths returns the path of every attribute of b that can be set
+// in configuration. Blocks without such attributes are returned themselves.
+func attributePaths(b *schema.Block, prefix []string) [][]string {
+	var paths [][]string
+	for _, name := range sortedKeys(b.Attributes) {
+		if settable(b.Attributes[name], prefix, name) {
+			paths = append(paths, appendPath(prefix, name))
+		}
+	}
+	for _, name := range sortedKeys(b.BlockTypes) {
+		nested := b.BlockTypes[name]
+		var children [][]string
+		if nested.Block != nil {
+			children = attributePaths(nested.Block, appendPath(prefix, name))
+		}
+		if len(children) == 0 {
+			children = [][]string{appendPath(prefix, name)}
+		}
+		paths = append(paths, children...)
+	}
+	return paths
+}
+
+// settable reports whether an attribute is synthesised. The id of a
+// resource, which v4 schemas list as optional, is never set in practice.
+func settable(attr *schema.Attribute, prefix []string, name string) bool {
+	return !attr.ComputedOnly() && !(len(prefix) == 0 && name == "id")
+}
+
+// synthesise writes the required attributes and blocks of b into body, and
+// the attribute or block at target. It records the placeholder of every
+// value it writes in tokens.
+func synthesise(body *hclwrite.Body, b *schema.Block, prefix, target []string, gen *generator, tokens map[string]string) {
+	for _, name := range sortedKeys(b.Attributes) {
+		attr := b.Attributes[name]
+		isTarget := len(target) == 1 && target[0] == name
+		if !settable(attr, prefix, name) || !attr.Required && !isTarget {
+			continue
+		}
+		value, token := gen.attribute(attr)
+		body.SetAttributeValue(name, value)
+		tokens[strings.Join(appendPath(prefix, name), ".")] = token
+	}
+	for _, name := range sortedKeys(b.BlockTypes) {
+		nested := b.BlockTypes[name]
+		isTarget := len(target) > 0 && target[0] == name
+		if nested.MinItems == 0 && !isTarget {
+			continue
+		}
+		var childTarget []string
+		if isTarget {
+			childTarget = target[1:]
+		}
+		child := body.AppendNewBlock(name, nil)
+		if nested.Block != nil {
+			synthesise(child.Body(), nested.Block, appendPath(prefix, name), childTarget, gen, tokens)
+		}
+		tokens[strings.Join(appendPath(prefix, name), ".")] = ""
+	}
+}
+
+// generator produces placeholder values. Strings and numbers are unique, so
+// that they can be found in the output even when a migrator renames or
+// moves them.
+type generator struct {
+	n int
+}
+
+func (g *generator) attribute(attr *schema.Attribute) (cty.Value, string) {
+	if attr.NestedType != nil {
+		return g.nested(attr.NestedType)
+	}
+	ty, err := ctyjson.UnmarshalType(attr.Type)
+	if err != nil {
+		ty = cty.DynamicPseudoType
+	}
+	return g.value(ty)
+}
+
+func (g *generator) nested(nt *schema.NestedType) (cty.Value, string) {
+	attrs := make(map[string]cty.Value)
+	var token string
+	for _, name := range sortedKeys(nt.Attributes) {
+		if nt.Attributes[name].ComputedOnly() {
+			continue
+		}
+		value, t := g.attribute(nt.Attributes[name])
+		attrs[name] = value
+		if token == "" {
+			token = t
+		}
+	}
+	obj := cty.ObjectVal(attrs)
+	switch nt.NestingMode {
+	case "list", "set":
+		return cty.TupleVal([]cty.Value{obj}), token
+	case "map":
+		return cty.ObjectVal(map[string]cty.Value{"key": obj}), token
+	}
+	return obj, token
+}
+
+// value returns a placeholder of type ty and the token that identifies it.
+// The value of a bool, or of an object without strings or numbers, has no
+// token.
+func (g *generator) value(ty cty.Type) (cty.Value, string) {
+	switch {
+	case ty == cty.Bool:
+		return cty.True, ""
+	case ty == cty.Number:
+		g.n++
+		n := 90000 + g.n
+		return cty.NumberIntVal(int64(n)), strconv.Itoa(n)
+	case ty.IsListType() || ty.IsSetType():
+		elem, token := g.value(ty.ElementType())
+		return cty.TupleVal([]cty.Value{elem}), token
+	case ty.IsMapType():
+		elem, token := g.value(ty.ElementType())
+		return cty.ObjectVal(map[string]cty.Value{"key": elem}), token
+	case ty.IsObjectType():
+		attrs := make(map[string]cty.Value)
+		var token string
+		for _, name := range sortedKeys(ty.AttributeTypes()) {
+			value, t := g.value(ty.AttributeType(name))
+			attrs[name] = value
+			if token == "" {
+				token = t
+			}
+		}
+		return cty.ObjectVal(attrs), token
+	case ty.IsTupleType():
+		return cty.EmptyTupleVal, ""
+	}
+	g.n++
+	token := fmt.Sprintf("tfmcov%03d", g.n)
+	return cty.StringVal(token), token
+}
+
+// parseBlocks returns the cloudflare resource and data blocks of a
+// configuration with every attribute, block and object key in them.
+func parseBlocks(content []byte) []outputBlock {
+	file, diags := hclsyntax.ParseConfig(content, "main.tf", hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil
+	}
+	body, ok := file.Body.(*hclsyntax.Body)
+	if !ok {
+		return nil
+	}
+
+	var blocks []outputBlock
+	for _, block := range body.Blocks {
+		if block.Type != "resource" && block.Type != "data" || len(block.Labels) != 2 || !strings.HasPrefix(block.Labels[0], "cloudflare_") {
+			continue
+		}
+		out := outputBlock{resourceType: block.Labels[0], data: block.Type == "data"}
+		walkBody(block.Body, content, nil, "", func(path []string, form, source string) {
+			out.occurrences = append(out.occurrences, occurrence{
+				resourceType: out.resourceType,
+				data:         out.data,
+				path:         path,
+				form:         form,
+				source:       source,
+			})
+		})
+		blocks = append(blocks, out)
+	}
+	return blocks
+}
+
+type emitFunc func(path []string, form, source string)
+
+func walkBody(body *hclsyntax.Body, content []byte, prefix []string, form string, emit emitFunc) {
+	names := make([]string, 0, len(body.Attributes))
+	for name := range body.Attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		expr := body.Attributes[name].Expr
+		path := appendPath(prefix, name)
+		emit(path, form+"a", string(expr.Range().SliceBytes(content)))
+		walkExpr(expr, content, path, form+"a", emit)
+	}
+
+	for _, block := range body.Blocks {
+		name, blockBody := block.Type, block.Body
+		if block.Type == "dynamic" && len(block.Labels) == 1 {
+			name = block.Labels[0]
+			for _, inner := range block.Body.Blocks {
+				if inner.Type == "content" {
+					blockBody = inner.Body
+				}
+			}
+		}
+		path := appendPath(prefix, name)
+		emit(path, form+"b", "")
+		walkBody(blockBody, content, path, form+"b", emit)
+	}
+}
+
+func walkExpr(expr hclsyntax.Expression, content []byte, path []string, form string, emit emitFunc) {
+	switch e := expr.(type) {
+	case *hclsyntax.ObjectConsExpr:
+		for _, item := range e.Items {
+			key, ok := objectKey(item.KeyExpr)
+			if !ok {
+				continue
+			}
+			itemPath := appendPath(path, key)
+			emit(itemPath, form+"o", string(item.ValueExpr.Range().SliceBytes(content)))
+			walkExpr(item.ValueExpr, content, itemPath, form+"o", emit)
+		}
+	case *hclsyntax.TupleConsExpr:
+		for _, elem := range e.Exprs {
+			walkExpr(elem, content, path, form, emit)
+		}
+	}
+}
+
+// objectKey returns the key of an object item when it is a static name or
+// string.
+func objectKey(expr hclsyntax.Expression) (string, bool) {
+	if wrapped, ok := expr.(*hclsyntax.ObjectConsKeyExpr); ok {
+		if name := hcl.ExprAsKeyword(wrapped.Wrapped); name != "" && !wrapped.ForceNonLiteral {
+			return name, true
+		}
+		expr = wrapped.Wrapped
+	}
+	if template, ok := expr.(*hclsyntax.TemplateExpr); ok && template.IsStringLiteral() {
+		value, diags := template.Value(nil)
+		if !diags.HasErrors() {
+			return value.AsString(), true
+		}
+	}
+	return "", false
+}
+
+// mentions reports whether text mentions name as a whole word.
+func mentions(text, name string) bool {
+	return regexp.MustCompile(`\b` + regexp.QuoteMeta(name) + `\b`).MatchString(text)
+}
+
+func appendPath(prefix []string, name string) []string {
+	path := make([]string, len(prefix), len(prefix)+1)
+	copy(path, prefix)
+	return append(path, name)
+}
@@ -139,12 +139,12 @@ func RunMigrate(resources string, yes bool, targetProviderVersion string) error
 		printYellow("Warning: Failed to hoist import blocks to root: %v", err)
 	}
 
-	// Clean up backup files
+	// Clean up backup files and the manifest tf-migrate restore reads
 	if err := filepath.Walk(generatedDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return nil
 		}
-		if strings.HasSuffix(info.Name(), ".backup") || strings.HasSuffix(info.Name(), ".tfstate.backup") {
+		if strings.HasSuffix(info.Name(), ".backup") || strings.HasSuffix(info.Name(), ".tfstate.backup") || info.Name() == ".tf-migrate-backups.json" {
 			if err := os.Remove(path); err != nil {
 				printYellow("Warning: Failed to remove backup file %s: %v", path, err)
 			}
@@ -4,11 +4,13 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclwrite"
 	"github.com/zclconf/go-cty/cty"
 
 	"github.com/cloudflare/tf-migrate/internal/handlers"
 	"github.com/cloudflare/tf-migrate/internal/transform"
+	tfhcl "github.com/cloudflare/tf-migrate/internal/transform/hcl"
 )
 
 func TestFormatterHandler(t *testing.T) {
@@ -90,18 +92,31 @@ resource "resource_b" "b" {
 		{
 			name: "Format with comments preserved",
 			setupAST: func() *hclwrite.File {
-				f := hclwrite.NewEmptyFile()
+				f, _ := hclwrite.ParseConfig([]byte(`# Entry points
+resource "test" "example" {
+  name = "test" # display name
 
-				// Add a resource with attributes
-				block := f.Body().AppendNewBlock("resource", []string{"test", "example"})
-				block.Body().SetAttributeValue("name", cty.StringVal("test"))
+  # Public entry point
+  destinations {
+    uri = "https://app.example.com" # production
+  }
+}
+`), "main.tf", hcl.InitialPos)
 
-				// Note: hclwrite doesn't preserve comments in the CFGFile,
-				// so this test mainly ensures formatting doesn't break
+				// Comments survive block-to-attribute conversions too
+				tfhcl.ConvertBlocksToAttributeList(f.Body().Blocks()[0].Body(), "destinations", nil)
 				return f
 			},
-			expectedOutput: `resource "test" "example" {
-  name = "test"
+			expectedOutput: `# Entry points
+resource "test" "example" {
+  name = "test" # display name
+
+  destinations = [
+    # Public entry point
+    {
+      uri = "https://app.example.com" # production
+    }
+  ]
 }`,
 		},
 		{
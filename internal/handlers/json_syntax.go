@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+
+	"github.com/cloudflare/tf-migrate/internal/transform"
+	tfhcl "github.com/cloudflare/tf-migrate/internal/transform/hcl"
+)
+
+// JSONDecodeHandler converts a .tf.json file to native syntax so that the
+// rest of the pipeline, and every migrator, can work on it unchanged. Files
+// in native syntax are passed through.
+type JSONDecodeHandler struct {
+	transform.BaseHandler
+	log hclog.Logger
+}
+
+func NewJSONDecodeHandler(log hclog.Logger) transform.TransformationHandler {
+	return &JSONDecodeHandler{
+		log: log,
+	}
+}
+
+func (h *JSONDecodeHandler) Handle(ctx *transform.Context) (*transform.Context, error) {
+	if !tfhcl.IsJSONConfigFile(ctx.Filename) {
+		return h.Next(ctx)
+	}
+
+	native, err := tfhcl.JSONToNative(ctx.Content, ctx.Filename)
+	if err != nil {
+		return ctx, err
+	}
+	h.log.Debug("Converted JSON configuration to native syntax", "file", ctx.Filename)
+
+	ctx.JSONSource = ctx.Content
+	ctx.Content = native
+	return h.Next(ctx)
+}
+
+// JSONEncodeHandler converts the migrated native syntax of a .tf.json file
+// back to JSON. When no migrator changed the file, the original JSON is
+// returned byte for byte.
+type JSONEncodeHandler struct {
+	transform.BaseHandler
+	log hclog.Logger
+}
+
+func NewJSONEncodeHandler(log hclog.Logger) transform.TransformationHandler {
+	return &JSONEncodeHandler{
+		log: log,
+	}
+}
+
+func (h *JSONEncodeHandler) Handle(ctx *transform.Context) (*transform.Context, error) {
+	if ctx.JSONSource == nil {
+		return h.Next(ctx)
+	}
+
+	original, err := tfhcl.JSONToNative(ctx.JSONSource, ctx.Filename)
+	if err != nil {
+		return ctx, err
+	}
+	if bytes.Equal(ctx.Content, hclwrite.Format(original)) {
+		ctx.Content = ctx.JSONSource
+		return h.Next(ctx)
+	}
+
+	content, err := tfhcl.NativeToJSON(ctx.Content, ctx.Filename)
+	if err != nil {
+		return ctx, fmt.Errorf("failed to convert %s back to JSON: %w", ctx.Filename, err)
+	}
+	ctx.Content = content
+	return h.Next(ctx)
+}
@@ -0,0 +1,92 @@
+package handlers_test
+
+import (
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudflare/tf-migrate/internal/handlers"
+	"github.com/cloudflare/tf-migrate/internal/transform"
+)
+
+const jsonConfig = `{
+  "resource": {
+    "cloudflare_zone": {
+      "z": {"zone": "example.com", "account_id": "${var.account_id}"}
+    }
+  }
+}
+`
+
+func TestJSONDecodeHandler(t *testing.T) {
+	log := hclog.NewNullLogger()
+
+	t.Run("converts .tf.json files", func(t *testing.T) {
+		ctx := &transform.Context{Content: []byte(jsonConfig), Filename: "main.tf.json"}
+		result, err := handlers.NewJSONDecodeHandler(log).Handle(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []byte(jsonConfig), result.JSONSource)
+		assert.Contains(t, string(result.Content), `resource "cloudflare_zone" "z" {`)
+		assert.Contains(t, string(result.Content), `account_id = var.account_id`)
+	})
+
+	t.Run("passes native syntax through", func(t *testing.T) {
+		content := []byte(`resource "cloudflare_zone" "z" {}`)
+		ctx := &transform.Context{Content: content, Filename: "main.tf"}
+		result, err := handlers.NewJSONDecodeHandler(log).Handle(ctx)
+		require.NoError(t, err)
+		assert.Nil(t, result.JSONSource)
+		assert.Equal(t, content, result.Content)
+	})
+
+	t.Run("invalid JSON", func(t *testing.T) {
+		ctx := &transform.Context{Content: []byte(`{"resource": `), Filename: "main.tf.json"}
+		_, err := handlers.NewJSONDecodeHandler(log).Handle(ctx)
+		assert.Error(t, err)
+	})
+}
+
+func TestJSONEncodeHandler(t *testing.T) {
+	log := hclog.NewNullLogger()
+
+	decode := func(t *testing.T) *transform.Context {
+		ctx := &transform.Context{Content: []byte(jsonConfig), Filename: "main.tf.json"}
+		result, err := handlers.NewJSONDecodeHandler(log).Handle(ctx)
+		require.NoError(t, err)
+		return result
+	}
+
+	t.Run("returns the original JSON when nothing changed", func(t *testing.T) {
+		result, err := handlers.NewJSONEncodeHandler(log).Handle(decode(t))
+		require.NoError(t, err)
+		assert.Equal(t, jsonConfig, string(result.Content))
+	})
+
+	t.Run("converts migrated content back to JSON", func(t *testing.T) {
+		ctx := decode(t)
+		ctx.Content = []byte(`resource "cloudflare_zone" "z" {
+  name    = "example.com"
+  account = { id = var.account_id }
+}
+`)
+		result, err := handlers.NewJSONEncodeHandler(log).Handle(ctx)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{
+  "resource": {
+    "cloudflare_zone": {
+      "z": {"name": "example.com", "account": {"id": "${var.account_id}"}}
+    }
+  }
+}`, string(result.Content))
+	})
+
+	t.Run("passes native syntax through", func(t *testing.T) {
+		content := []byte(`resource "cloudflare_zone" "z" {}`)
+		ctx := &transform.Context{Content: content, Filename: "main.tf"}
+		result, err := handlers.NewJSONEncodeHandler(log).Handle(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, content, result.Content)
+	})
+}
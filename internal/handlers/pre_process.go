@@ -1,7 +1,11 @@
 package handlers
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/cloudflare/tf-migrate/internal/transform"
+	tfhcl "github.com/cloudflare/tf-migrate/internal/transform/hcl"
 )
 
 type PreprocessHandler struct {
@@ -16,15 +20,66 @@ func NewPreprocessHandler(provider transform.MigrationProvider) transform.Transf
 }
 
 func (h *PreprocessHandler) Handle(ctx *transform.Context) (*transform.Context, error) {
-	contentStr := string(ctx.Content)
+	// .tf.json files have no comments to annotate.
+	if ctx.JSONSource == nil {
+		ctx.Ignored = tfhcl.FindIgnoreAnnotations(ctx.Content, ctx.Filename)
+	}
+	if ctx.Ignored.File {
+		// The file is left exactly as it is.
+		return ctx, nil
+	}
+
+	// Preprocessors rewrite the text of the whole file, so ignored blocks are
+	// swapped for placeholders while they run.
+	contentStr, ignored := maskIgnoredBlocks(ctx)
 	contentStr = h.applyAllPreprocessors(ctx, contentStr)
+	for placeholder, block := range ignored {
+		if !strings.Contains(contentStr, placeholder) {
+			return ctx, fmt.Errorf("preprocessing removed an ignored block of %s", ctx.Filename)
+		}
+		contentStr = strings.Replace(contentStr, placeholder, block, 1)
+	}
 	ctx.Content = []byte(contentStr)
 	return h.Next(ctx)
 }
 
 func (h *PreprocessHandler) applyAllPreprocessors(ctx *transform.Context, content string) string {
 	for _, migrator := range h.provider.GetAllMigrators(ctx.SourceVersion, ctx.TargetVersion, ctx.Resources...) {
+		if handlesSkippedType(migrator, ctx.SkipTypes) {
+			continue
+		}
 		content = migrator.Preprocess(content)
 	}
 	return content
 }
+
+// handlesSkippedType reports whether migrator handles one of the resource
+// types left as they are, whose text its preprocessing must not touch.
+func handlesSkippedType(migrator transform.ResourceTransformer, skip map[string]bool) bool {
+	for resourceType := range skip {
+		if migrator.CanHandle(resourceType) {
+			return true
+		}
+	}
+	return false
+}
+
+// maskIgnoredBlocks returns ctx.Content with each ignored block replaced by a
+// placeholder comment, and the text of the blocks keyed by placeholder.
+func maskIgnoredBlocks(ctx *transform.Context) (string, map[string]string) {
+	if len(ctx.Ignored.Blocks) == 0 {
+		return string(ctx.Content), nil
+	}
+	var b strings.Builder
+	ignored := make(map[string]string, len(ctx.Ignored.Blocks))
+	offset := 0
+	for i, block := range ctx.Ignored.Blocks {
+		placeholder := fmt.Sprintf("# %s block %d.", tfhcl.IgnoreDirective, i)
+		b.Write(ctx.Content[offset:block.Range.Start.Byte])
+		b.WriteString(placeholder)
+		ignored[placeholder] = string(ctx.Content[block.Range.Start.Byte:block.Range.End.Byte])
+		offset = block.Range.End.Byte
+	}
+	b.Write(ctx.Content[offset:])
+	return b.String(), ignored
+}
@@ -1,6 +1,7 @@
 package handlers_test
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/cloudflare/tf-migrate/internal/handlers"
@@ -150,3 +151,49 @@ func TestPreprocessHandlerCallsAllRegisteredPreprocessors(t *testing.T) {
 		}
 	}
 }
+
+func TestPreprocessHandlerIgnoreAnnotations(t *testing.T) {
+	rename := &MockResourceTransformer{
+		resourceType: "old_resource",
+		preprocessFunc: func(content string) string {
+			return strings.ReplaceAll(content, "old_attr", "new_attr")
+		},
+	}
+	provider := NewMockMigratorProvider([]*MockResourceTransformer{rename})
+
+	t.Run("ignored blocks are not preprocessed", func(t *testing.T) {
+		input := `resource "old_resource" "a" {
+  old_attr = 1
+}
+
+# tf-migrate:ignore
+resource "old_resource" "b" {
+  old_attr = 2
+}
+`
+		ctx := &transform.Context{Content: []byte(input)}
+		result, err := handlers.NewPreprocessHandler(provider).Handle(ctx)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		expected := strings.Replace(input, "old_attr = 1", "new_attr = 1", 1)
+		if string(result.Content) != expected {
+			t.Errorf("Expected output:\n%s\nGot:\n%s", expected, string(result.Content))
+		}
+		if !result.Ignored.Ignores("old_resource", "b") {
+			t.Errorf("Expected old_resource.b to be ignored, got %+v", result.Ignored)
+		}
+	})
+
+	t.Run("ignored files are left as they are", func(t *testing.T) {
+		input := "# tf-migrate:ignore-file\nresource \"old_resource\" \"a\" {\n  old_attr = 1\n}\n"
+		ctx := &transform.Context{Content: []byte(input)}
+		result, err := handlers.NewPreprocessHandler(provider).Handle(ctx)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if string(result.Content) != input || !result.Ignored.File {
+			t.Errorf("Expected the file to be left as it is, got:\n%s", string(result.Content))
+		}
+	})
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/hcl/v2"
+
+	"github.com/cloudflare/tf-migrate/internal/transform"
+)
+
+// cloudflareProviderName is the local name of the provider whose configuration
+// blocks are migrated.
+const cloudflareProviderName = "cloudflare"
+
+// ProviderTransformHandler migrates provider "cloudflare" blocks. The block is
+// edited in place, so alias and any argument the migrator does not touch are
+// preserved. Provider migrators are looked up with a "provider." prefix.
+type ProviderTransformHandler struct {
+	transform.BaseHandler
+	log      hclog.Logger
+	provider transform.MigrationProvider
+}
+
+func NewProviderTransformHandler(log hclog.Logger, provider transform.MigrationProvider) transform.TransformationHandler {
+	return &ProviderTransformHandler{
+		log:      log,
+		provider: provider,
+	}
+}
+
+func (h *ProviderTransformHandler) Handle(ctx *transform.Context) (*transform.Context, error) {
+	if ctx.CFGFile == nil {
+		return ctx, fmt.Errorf("CFGFile is nil - ParseHandler must run before ProviderTransformHandler")
+	}
+
+	migratorKey := "provider." + cloudflareProviderName
+	migrator := h.provider.GetMigrator(migratorKey, ctx.SourceVersion, ctx.TargetVersion)
+	if migrator == nil {
+		h.log.Debug("No migrator found for provider block", "source", ctx.SourceVersion, "target", ctx.TargetVersion)
+		return h.Next(ctx)
+	}
+
+	for _, block := range ctx.CFGFile.Body().Blocks() {
+		if block.Type() != "provider" {
+			continue
+		}
+		labels := block.Labels()
+		if len(labels) != 1 || labels[0] != cloudflareProviderName {
+			continue
+		}
+
+		if _, err := migrator.TransformConfig(ctx, block); err != nil {
+			h.log.Error("Error transforming provider block", "error", err)
+			ctx.Diagnostics = append(ctx.Diagnostics, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  fmt.Sprintf("Failed to transform provider %q block", cloudflareProviderName),
+				Detail:   err.Error(),
+			})
+			continue
+		}
+
+		key := fmt.Sprintf("transformed_%s", migratorKey)
+		if count, ok := ctx.Metadata[key]; ok {
+			ctx.Metadata[key] = count.(int) + 1
+		} else {
+			ctx.Metadata[key] = 1
+		}
+	}
+
+	return h.Next(ctx)
+}
@@ -0,0 +1,129 @@
+package handlers_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+
+	"github.com/cloudflare/tf-migrate/internal/handlers"
+	"github.com/cloudflare/tf-migrate/internal/transform"
+)
+
+func TestProviderTransformHandler(t *testing.T) {
+	input := `provider "cloudflare" {
+  api_token = "token"
+  rps       = 4
+}
+
+provider "cloudflare" {
+  alias = "secondary"
+  rps   = 4
+}
+
+provider "aws" {
+  rps = 4
+}
+
+resource "cloudflare_record" "example" {
+  rps = 4
+}
+`
+
+	var transformed []string
+	providerMigrator := &MockResourceTransformer{
+		resourceType: "provider.cloudflare",
+		transformFunc: func(ctx *transform.Context, block *hclwrite.Block) (*transform.TransformResult, error) {
+			transformed = append(transformed, strings.Join(append([]string{block.Type()}, block.Labels()...), "."))
+			block.Body().RemoveAttribute("rps")
+			return &transform.TransformResult{Blocks: []*hclwrite.Block{block}}, nil
+		},
+	}
+
+	file, diags := hclwrite.ParseConfig([]byte(input), "main.tf", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatalf("Failed to parse input: %v", diags)
+	}
+	ctx := &transform.Context{
+		CFGFile:  file,
+		Metadata: make(map[string]interface{}),
+	}
+
+	handler := handlers.NewProviderTransformHandler(log, NewMockMigratorProvider([]*MockResourceTransformer{providerMigrator}))
+	result, err := handler.Handle(ctx)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(transformed) != 2 {
+		t.Fatalf("Expected both cloudflare provider blocks to be transformed, got %v", transformed)
+	}
+	if got := result.Metadata["transformed_provider.cloudflare"]; got != 2 {
+		t.Errorf("Expected transformed count 2, got %v", got)
+	}
+
+	blocks := result.CFGFile.Body().Blocks()
+	if blocks[0].Body().GetAttribute("rps") != nil || blocks[1].Body().GetAttribute("rps") != nil {
+		t.Error("Expected rps to be removed from cloudflare provider blocks")
+	}
+	if blocks[1].Body().GetAttribute("alias") == nil {
+		t.Error("Expected alias to be preserved")
+	}
+	if blocks[2].Body().GetAttribute("rps") == nil {
+		t.Error("Expected other providers to be left untouched")
+	}
+	if blocks[3].Body().GetAttribute("rps") == nil {
+		t.Error("Expected resource blocks to be left untouched")
+	}
+}
+
+func TestProviderTransformHandlerNoMigrator(t *testing.T) {
+	input := `provider "cloudflare" {
+  rps = 4
+}
+`
+	file, diags := hclwrite.ParseConfig([]byte(input), "main.tf", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatalf("Failed to parse input: %v", diags)
+	}
+	ctx := &transform.Context{CFGFile: file, Metadata: make(map[string]interface{})}
+
+	handler := handlers.NewProviderTransformHandler(log, NewMockMigratorProvider(nil))
+	result, err := handler.Handle(ctx)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if string(result.CFGFile.Bytes()) != input {
+		t.Errorf("Expected file to be unchanged, got:\n%s", result.CFGFile.Bytes())
+	}
+}
+
+func TestProviderTransformHandlerError(t *testing.T) {
+	file, _ := hclwrite.ParseConfig([]byte("provider \"cloudflare\" {}\n"), "main.tf", hcl.InitialPos)
+	ctx := &transform.Context{CFGFile: file, Metadata: make(map[string]interface{})}
+
+	providerMigrator := &MockResourceTransformer{
+		resourceType: "provider.cloudflare",
+		transformFunc: func(ctx *transform.Context, block *hclwrite.Block) (*transform.TransformResult, error) {
+			return nil, fmt.Errorf("boom")
+		},
+	}
+
+	handler := handlers.NewProviderTransformHandler(log, NewMockMigratorProvider([]*MockResourceTransformer{providerMigrator}))
+	result, err := handler.Handle(ctx)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !result.Diagnostics.HasErrors() {
+		t.Error("Expected an error diagnostic")
+	}
+}
+
+func TestProviderTransformHandlerNilFile(t *testing.T) {
+	handler := handlers.NewProviderTransformHandler(log, NewMockMigratorProvider(nil))
+	if _, err := handler.Handle(&transform.Context{}); err == nil {
+		t.Error("Expected error when CFGFile is nil")
+	}
+}
@@ -5,9 +5,11 @@ import (
 
 	"github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/hashicorp/hcl/v2/hclwrite"
 
 	"github.com/cloudflare/tf-migrate/internal/transform"
+	tfhcl "github.com/cloudflare/tf-migrate/internal/transform/hcl"
 )
 
 type ResourceTransformHandler struct {
@@ -16,6 +18,20 @@ type ResourceTransformHandler struct {
 	provider transform.MigrationProvider
 }
 
+// blockReplacement records the blocks that take the place of an original block
+// which a migrator asked to remove.
+type blockReplacement struct {
+	original *hclwrite.Block
+	blocks   []*hclwrite.Block
+	// leading holds tokens, such as comments, written before the blocks.
+	leading hclwrite.Tokens
+}
+
+// rejectedMarker is written above a block whose transformation was rejected
+// in review. The ignore directive keeps later runs from migrating it.
+const rejectedMarker = "# TODO: migrate this block by hand; its automatic migration was rejected in review.\n" +
+	"# " + tfhcl.IgnoreDirective + " rejected in review\n"
+
 func NewResourceTransformHandler(log hclog.Logger, provider transform.MigrationProvider) transform.TransformationHandler {
 	return &ResourceTransformHandler{
 		log:      log,
@@ -31,8 +47,21 @@ func (h *ResourceTransformHandler) Handle(ctx *transform.Context) (*transform.Co
 	body := ctx.CFGFile.Body()
 	blocks := body.Blocks()
 
-	var blocksToRemove []*hclwrite.Block
-	var blocksToAdd []*hclwrite.Block
+	var replacements []blockReplacement
+
+	// Track moved/import/removed blocks already present so that re-running a
+	// migration never emits them twice.
+	seenMigrationBlocks := make(map[string]struct{})
+	for _, block := range blocks {
+		if key := tfhcl.MigrationBlockKey(block); key != "" {
+			seenMigrationBlocks[key] = struct{}{}
+		}
+	}
+	for _, block := range ctx.MigrationBlocks {
+		if key := tfhcl.MigrationBlockKey(block); key != "" {
+			seenMigrationBlocks[key] = struct{}{}
+		}
+	}
 
 	for _, block := range blocks {
 		if block.Type() != "resource" && block.Type() != "data" {
@@ -49,12 +78,33 @@ func (h *ResourceTransformHandler) Handle(ctx *transform.Context) (*transform.Co
 		if block.Type() == "data" {
 			resourceType = "data." + resourceType
 		}
+		if ctx.Ignored.Ignores(resourceType, labels[len(labels)-1]) {
+			h.log.Debug("Skipping ignored resource", "type", resourceType, "name", labels[len(labels)-1])
+			continue
+		}
+		if ctx.SkipTypes[resourceType] {
+			h.log.Debug("Skipping resource type", "type", resourceType, "file", ctx.Filename)
+			continue
+		}
 		migrator := h.provider.GetMigrator(resourceType, ctx.SourceVersion, ctx.TargetVersion)
 		if migrator == nil {
 			h.log.Debug("No migrator found for resource type", "type", resourceType, "source", ctx.SourceVersion, "target", ctx.TargetVersion)
 			continue
 		}
 
+		if detector, ok := migrator.(transform.AlreadyMigratedDetector); ok && detector.IsAlreadyMigrated(block) {
+			h.log.Debug("Skipping already migrated resource", "type", resourceType, "name", labels[len(labels)-1])
+			continue
+		}
+
+		// The migrator may change the block in place, so keep a copy of it
+		// to show and restore in review.
+		var original *hclwrite.Block
+		diagStart := len(ctx.Diagnostics)
+		if ctx.Review != nil {
+			original = tfhcl.CloneBlock(block)
+		}
+
 		result, err := migrator.TransformConfig(ctx, block)
 		if err != nil {
 			h.log.Error("Error transforming resource", "type", resourceType, "error", err)
@@ -66,11 +116,48 @@ func (h *ResourceTransformHandler) Handle(ctx *transform.Context) (*transform.Co
 			continue
 		}
 
+		if original != nil {
+			decision, err := ctx.Review(&transform.BlockReview{
+				Filename:    ctx.Filename,
+				Address:     resourceType + "." + labels[len(labels)-1],
+				Original:    original,
+				Result:      result,
+				Diagnostics: append(hcl.Diagnostics(nil), ctx.Diagnostics[diagStart:]...),
+			})
+			if err != nil {
+				return ctx, fmt.Errorf("reviewing %s.%s: %w", resourceType, labels[len(labels)-1], err)
+			}
+			switch decision.Action {
+			case transform.ReviewReject:
+				h.log.Debug("Transformation rejected in review", "type", resourceType, "name", labels[len(labels)-1])
+				ctx.Diagnostics = ctx.Diagnostics[:diagStart]
+				replacements = append(replacements, blockReplacement{
+					original: block,
+					blocks:   []*hclwrite.Block{original},
+					leading:  hclwrite.Tokens{{Type: hclsyntax.TokenComment, Bytes: []byte(rejectedMarker)}},
+				})
+				continue
+			case transform.ReviewEdit:
+				result = &transform.TransformResult{Blocks: decision.Blocks, RemoveOriginal: true}
+			}
+		}
+
 		if result.RemoveOriginal {
-			blocksToRemove = append(blocksToRemove, block)
-			if len(result.Blocks) > 0 {
-				blocksToAdd = append(blocksToAdd, result.Blocks...)
+			var newBlocks []*hclwrite.Block
+			for _, newBlock := range result.Blocks {
+				if key := tfhcl.MigrationBlockKey(newBlock); key != "" {
+					if _, ok := seenMigrationBlocks[key]; ok {
+						continue
+					}
+					seenMigrationBlocks[key] = struct{}{}
+				}
+				if ctx.CollectMigrationBlocks && tfhcl.IsMigrationBlock(newBlock) {
+					ctx.MigrationBlocks = append(ctx.MigrationBlocks, newBlock)
+					continue
+				}
+				newBlocks = append(newBlocks, newBlock)
 			}
+			replacements = append(replacements, blockReplacement{original: block, blocks: newBlocks})
 		}
 		key := fmt.Sprintf("transformed_%s", resourceType)
 		if count, ok := ctx.Metadata[key]; ok {
@@ -80,21 +167,84 @@ func (h *ResourceTransformHandler) Handle(ctx *transform.Context) (*transform.Co
 		}
 	}
 
-	for _, block := range blocksToRemove {
-		body.RemoveBlock(block)
+	if len(replacements) > 0 {
+		file, err := replaceBlocksInPlace(ctx.CFGFile, ctx.Filename, replacements)
+		if err != nil {
+			return ctx, err
+		}
+		ctx.CFGFile = file
 	}
 
-	for i, block := range blocksToAdd {
-		// Add newline before first block to separate from previous content
-		if i == 0 {
-			body.AppendNewline()
+	return h.Next(ctx)
+}
+
+// replaceBlocksInPlace returns a copy of file in which each original block is
+// replaced, at its original position, by its replacement blocks separated by
+// blank lines. hclwrite has no API for inserting a block mid-body, so the file
+// tokens are spliced and the result re-parsed.
+func replaceBlocksInPlace(file *hclwrite.File, filename string, replacements []blockReplacement) (*hclwrite.File, error) {
+	// Block tokens are shared with the file's token sequence, so each block's
+	// span can be located by token identity.
+	byFirstToken := make(map[*hclwrite.Token]blockReplacement, len(replacements))
+	for _, r := range replacements {
+		tokens := r.original.BuildTokens(nil)
+		if len(tokens) == 0 {
+			continue
 		}
-		body.AppendBlock(block)
-		// Add newline after each block except the last to separate split resources
-		if i < len(blocksToAdd)-1 {
-			body.AppendNewline()
+		byFirstToken[tokens[0]] = r
+	}
+
+	newline := func() *hclwrite.Token {
+		return &hclwrite.Token{Type: hclsyntax.TokenNewline, Bytes: []byte("\n")}
+	}
+
+	all := file.BuildTokens(nil)
+	out := make(hclwrite.Tokens, 0, len(all))
+	placed := make(map[*hclwrite.Block]bool, len(replacements))
+	for i := 0; i < len(all); i++ {
+		r, ok := byFirstToken[all[i]]
+		if !ok {
+			out = append(out, all[i])
+			continue
+		}
+
+		original := r.original.BuildTokens(nil)
+		i += len(original) - 1
+		placed[r.original] = true
+
+		out = append(out, r.leading...)
+		for j, block := range r.blocks {
+			if j > 0 {
+				out = append(out, newline())
+			}
+			out = append(out, block.BuildTokens(nil)...)
 		}
 	}
 
-	return h.Next(ctx)
+	// A migrator may already have removed another resource's block from the
+	// file (e.g. when merging resources). Its replacements have no position
+	// left, so they go at the end of the file.
+	for _, r := range replacements {
+		if placed[r.original] {
+			continue
+		}
+		for j, block := range r.blocks {
+			out = append(out, newline())
+			if j == 0 {
+				out = append(out, r.leading...)
+			}
+			out = append(out, block.BuildTokens(nil)...)
+		}
+	}
+
+	// Writing the tokens through a File applies the spacing that generated
+	// tokens lack, so the bytes lex back into the same tokens.
+	spliced := hclwrite.NewEmptyFile()
+	spliced.Body().AppendUnstructuredTokens(out)
+
+	replaced, diags := hclwrite.ParseConfig(spliced.Bytes(), filename, hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("failed to rebuild %s after transformation: %s", filename, diags.Error())
+	}
+	return replaced, nil
 }
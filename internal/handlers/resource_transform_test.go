@@ -6,11 +6,13 @@ import (
 	"testing"
 
 	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclwrite"
 	"github.com/zclconf/go-cty/cty"
 
 	"github.com/cloudflare/tf-migrate/internal/handlers"
 	"github.com/cloudflare/tf-migrate/internal/transform"
+	tfhcl "github.com/cloudflare/tf-migrate/internal/transform/hcl"
 )
 
 type MockResourceTransformer struct {
@@ -409,6 +411,415 @@ resource "tracked_resource" "three" {}`
 	}
 }
 
+func TestResourceTransformHandlerSkipTypes(t *testing.T) {
+	rename := func(to string) func(ctx *transform.Context, block *hclwrite.Block) (*transform.TransformResult, error) {
+		return func(ctx *transform.Context, block *hclwrite.Block) (*transform.TransformResult, error) {
+			block.SetLabels([]string{to, block.Labels()[1]})
+			return &transform.TransformResult{Blocks: []*hclwrite.Block{block}}, nil
+		}
+	}
+	provider := NewMockMigratorProvider([]*MockResourceTransformer{
+		{resourceType: "old_list", transformFunc: rename("new_list"), preprocessFunc: func(content string) string {
+			return strings.ReplaceAll(content, "list_items", "items")
+		}},
+		{resourceType: "old_record", transformFunc: rename("new_record")},
+	})
+
+	input := `resource "old_list" "a" {
+  list_items = []
+}
+resource "old_record" "b" {}
+`
+	ctx := &transform.Context{
+		Content:   []byte(input),
+		Metadata:  make(map[string]interface{}),
+		SkipTypes: map[string]bool{"old_list": true},
+	}
+	ctx, err := handlers.NewPreprocessHandler(provider).Handle(ctx)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	ctx, _ = handlers.NewParseHandler(log).Handle(ctx)
+	result, err := handlers.NewResourceTransformHandler(log, provider).Handle(ctx)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	output := string(result.CFGFile.Bytes())
+	if !strings.Contains(output, `resource "old_list" "a" {
+  list_items = []
+}`) {
+		t.Errorf("Expected the skipped resource type to be left as it is, got:\n%s", output)
+	}
+	if !strings.Contains(output, `resource "new_record" "b"`) {
+		t.Errorf("Expected the other resource type to be migrated, got:\n%s", output)
+	}
+}
+
+func TestResourceTransformHandlerIgnoredBlocks(t *testing.T) {
+	provider := NewMockMigratorProvider([]*MockResourceTransformer{{
+		resourceType: "old_record",
+		transformFunc: func(ctx *transform.Context, block *hclwrite.Block) (*transform.TransformResult, error) {
+			block.SetLabels([]string{"new_record", block.Labels()[1]})
+			return &transform.TransformResult{Blocks: []*hclwrite.Block{block}}, nil
+		},
+	}})
+
+	input := `# tf-migrate:ignore migrated by hand
+resource "old_record" "a" {}
+
+resource "old_record" "b" {}
+`
+	ctx := &transform.Context{
+		Content:  []byte(input),
+		Metadata: make(map[string]interface{}),
+		Ignored:  tfhcl.FindIgnoreAnnotations([]byte(input), "main.tf"),
+	}
+	ctx, _ = handlers.NewParseHandler(log).Handle(ctx)
+	result, err := handlers.NewResourceTransformHandler(log, provider).Handle(ctx)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	output := string(result.CFGFile.Bytes())
+	if !strings.Contains(output, `resource "old_record" "a"`) || !strings.Contains(output, `resource "new_record" "b"`) {
+		t.Errorf("Expected only the ignored block to be left as it is, got:\n%s", output)
+	}
+	if count := result.Metadata["transformed_old_record"]; count != 1 {
+		t.Errorf("Expected 1 transformed resource, got %v", count)
+	}
+}
+
+func TestResourceTransformHandlerReview(t *testing.T) {
+	provider := NewMockMigratorProvider([]*MockResourceTransformer{{
+		resourceType: "old_record",
+		transformFunc: func(ctx *transform.Context, block *hclwrite.Block) (*transform.TransformResult, error) {
+			block.SetLabels([]string{"new_record", block.Labels()[1]})
+			block.Body().SetAttributeValue("ttl", cty.NumberIntVal(1))
+			ctx.Diagnostics = append(ctx.Diagnostics, &hcl.Diagnostic{Severity: hcl.DiagWarning, Summary: "check ttl"})
+			return &transform.TransformResult{Blocks: []*hclwrite.Block{block}}, nil
+		},
+	}})
+
+	input := `resource "old_record" "a" {
+  name = "a"
+}
+
+resource "old_record" "b" {
+  name = "b"
+}
+`
+	run := func(review func(*transform.BlockReview) (transform.ReviewDecision, error)) (*transform.Context, error) {
+		ctx := &transform.Context{
+			Content:  []byte(input),
+			Filename: "main.tf",
+			Metadata: make(map[string]interface{}),
+			Review:   review,
+		}
+		ctx, _ = handlers.NewParseHandler(log).Handle(ctx)
+		return handlers.NewResourceTransformHandler(log, provider).Handle(ctx)
+	}
+
+	t.Run("accept and reject", func(t *testing.T) {
+		var reviewed []*transform.BlockReview
+		result, err := run(func(r *transform.BlockReview) (transform.ReviewDecision, error) {
+			reviewed = append(reviewed, r)
+			if r.Address == "old_record.b" {
+				return transform.ReviewDecision{Action: transform.ReviewReject}, nil
+			}
+			return transform.ReviewDecision{Action: transform.ReviewAccept}, nil
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if len(reviewed) != 2 {
+			t.Fatalf("Expected 2 reviewed blocks, got %d", len(reviewed))
+		}
+		first := reviewed[0]
+		if first.Filename != "main.tf" || first.Address != "old_record.a" {
+			t.Errorf("Unexpected review of %s in %s", first.Address, first.Filename)
+		}
+		if labels := first.Original.Labels(); labels[0] != "old_record" {
+			t.Errorf("Expected the original block as it was before the transformation, got %v", labels)
+		}
+		if labels := first.Result.Blocks[0].Labels(); labels[0] != "new_record" {
+			t.Errorf("Expected the transformed block in the result, got %v", labels)
+		}
+		if len(first.Diagnostics) != 1 || first.Diagnostics[0].Summary != "check ttl" {
+			t.Errorf("Expected the diagnostics of the transformation, got %v", first.Diagnostics)
+		}
+
+		expected := `resource "new_record" "a" {
+  name = "a"
+  ttl  = 1
+}
+
+# TODO: migrate this block by hand; its automatic migration was rejected in review.
+# tf-migrate:ignore rejected in review
+resource "old_record" "b" {
+  name = "b"
+}
+`
+		if output := string(result.CFGFile.Bytes()); output != expected {
+			t.Errorf("Expected:\n%s\nGot:\n%s", expected, output)
+		}
+		if len(result.Diagnostics) != 1 {
+			t.Errorf("Expected the diagnostics of the rejected block to be dropped, got %v", result.Diagnostics)
+		}
+		if count := result.Metadata["transformed_old_record"]; count != 1 {
+			t.Errorf("Expected 1 transformed resource, got %v", count)
+		}
+		if !tfhcl.FindIgnoreAnnotations(result.CFGFile.Bytes(), "main.tf").Ignores("old_record", "b") {
+			t.Error("Expected the rejected block to be ignored by later runs")
+		}
+	})
+
+	t.Run("edit", func(t *testing.T) {
+		result, err := run(func(r *transform.BlockReview) (transform.ReviewDecision, error) {
+			edited := hclwrite.NewBlock("resource", []string{"new_record", "edited_" + r.Original.Labels()[1]})
+			return transform.ReviewDecision{Action: transform.ReviewEdit, Blocks: []*hclwrite.Block{edited}}, nil
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		expected := `resource "new_record" "edited_a" {
+}
+
+resource "new_record" "edited_b" {
+}
+`
+		if output := string(result.CFGFile.Bytes()); output != expected {
+			t.Errorf("Expected:\n%s\nGot:\n%s", expected, output)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		_, err := run(func(*transform.BlockReview) (transform.ReviewDecision, error) {
+			return transform.ReviewDecision{}, fmt.Errorf("review aborted")
+		})
+		if err == nil || !strings.Contains(err.Error(), "review aborted") {
+			t.Errorf("Expected the review error, got %v", err)
+		}
+	})
+}
+
+type mockAlreadyMigratedTransformer struct {
+	*MockResourceTransformer
+	isAlreadyMigrated func(block *hclwrite.Block) bool
+}
+
+func (m *mockAlreadyMigratedTransformer) IsAlreadyMigrated(block *hclwrite.Block) bool {
+	return m.isAlreadyMigrated(block)
+}
+
+func TestResourceTransformHandlerIdempotency(t *testing.T) {
+	// renameWithMoved mimics a migrator that renames old_resource to new_resource
+	// in place and emits a moved block.
+	renameWithMoved := func(ctx *transform.Context, block *hclwrite.Block) (*transform.TransformResult, error) {
+		name := block.Labels()[1]
+		block.SetLabels([]string{"new_resource", name})
+		moved := hclwrite.NewBlock("moved", nil)
+		moved.Body().SetAttributeTraversal("from", hcl.Traversal{hcl.TraverseRoot{Name: "old_resource"}, hcl.TraverseAttr{Name: name}})
+		moved.Body().SetAttributeTraversal("to", hcl.Traversal{hcl.TraverseRoot{Name: "new_resource"}, hcl.TraverseAttr{Name: name}})
+		return &transform.TransformResult{
+			Blocks:         []*hclwrite.Block{block, moved},
+			RemoveOriginal: true,
+		}, nil
+	}
+
+	run := func(t *testing.T, provider transform.MigrationProvider, input string) string {
+		t.Helper()
+		ctx := &transform.Context{
+			Content:  []byte(input),
+			Filename: "test.tf",
+			Metadata: make(map[string]interface{}),
+		}
+		ctx, err := handlers.NewParseHandler(log).Handle(ctx)
+		if err != nil {
+			t.Fatalf("Failed to parse input: %v", err)
+		}
+		ctx, err = handlers.NewResourceTransformHandler(log, provider).Handle(ctx)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return string(hclwrite.Format(ctx.CFGFile.Bytes()))
+	}
+
+	t.Run("existing moved block is not duplicated", func(t *testing.T) {
+		provider := NewMockMigratorProvider([]*MockResourceTransformer{
+			{resourceType: "old_resource", transformFunc: renameWithMoved},
+		})
+		input := `resource "old_resource" "a" {
+  name = "a"
+}
+
+moved {
+  from = old_resource.a
+  to   = new_resource.a
+}
+`
+		output := run(t, provider, input)
+		if count := strings.Count(output, "moved {"); count != 1 {
+			t.Errorf("Expected 1 moved block, got %d:\n%s", count, output)
+		}
+		// With nothing new to add, the resource stays at its original position
+		if !strings.HasPrefix(output, `resource "new_resource" "a"`) {
+			t.Errorf("Expected resource to stay in place, got:\n%s", output)
+		}
+	})
+
+	t.Run("second run is a no-op", func(t *testing.T) {
+		migrator := &mockAlreadyMigratedTransformer{
+			MockResourceTransformer: &MockResourceTransformer{resourceType: "old_resource", transformFunc: renameWithMoved},
+			isAlreadyMigrated: func(block *hclwrite.Block) bool {
+				return block.Labels()[0] == "new_resource"
+			},
+		}
+		provider := &MockMigratorProvider{transformers: map[string]transform.ResourceTransformer{
+			"old_resource": migrator,
+			"new_resource": migrator,
+		}}
+
+		first := run(t, provider, `resource "old_resource" "a" {
+  name = "a"
+}
+`)
+		second := run(t, provider, first)
+		if first != second {
+			t.Errorf("Expected second run to be a no-op.\nfirst:\n%s\nsecond:\n%s", first, second)
+		}
+	})
+
+	t.Run("already migrated block is skipped", func(t *testing.T) {
+		called := false
+		migrator := &mockAlreadyMigratedTransformer{
+			MockResourceTransformer: &MockResourceTransformer{
+				resourceType: "dual_resource",
+				transformFunc: func(ctx *transform.Context, block *hclwrite.Block) (*transform.TransformResult, error) {
+					called = true
+					return &transform.TransformResult{Blocks: []*hclwrite.Block{block}}, nil
+				},
+			},
+			isAlreadyMigrated: func(block *hclwrite.Block) bool {
+				return block.Body().GetAttribute("settings") != nil
+			},
+		}
+		provider := &MockMigratorProvider{transformers: map[string]transform.ResourceTransformer{"dual_resource": migrator}}
+
+		ctx := &transform.Context{
+			Content:  []byte(`resource "dual_resource" "a" { settings = {} }`),
+			Metadata: make(map[string]interface{}),
+		}
+		ctx, _ = handlers.NewParseHandler(log).Handle(ctx)
+		result, err := handlers.NewResourceTransformHandler(log, provider).Handle(ctx)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if called {
+			t.Error("TransformConfig should not be called for an already migrated block")
+		}
+		if _, ok := result.Metadata["transformed_dual_resource"]; ok {
+			t.Error("Already migrated block should not be counted as transformed")
+		}
+	})
+}
+
+func TestResourceTransformHandlerPreservesPosition(t *testing.T) {
+	// split replaces old_resource with two resources and a moved block.
+	split := &MockResourceTransformer{
+		resourceType: "old_resource",
+		transformFunc: func(ctx *transform.Context, block *hclwrite.Block) (*transform.TransformResult, error) {
+			name := block.Labels()[1]
+			first := hclwrite.NewBlock("resource", []string{"new_resource", name})
+			first.Body().SetAttributeValue("part", cty.StringVal("first"))
+			second := hclwrite.NewBlock("resource", []string{"new_resource_extra", name})
+			second.Body().SetAttributeValue("part", cty.StringVal("second"))
+			moved := hclwrite.NewBlock("moved", nil)
+			moved.Body().SetAttributeTraversal("from", hcl.Traversal{hcl.TraverseRoot{Name: "old_resource"}, hcl.TraverseAttr{Name: name}})
+			moved.Body().SetAttributeTraversal("to", hcl.Traversal{hcl.TraverseRoot{Name: "new_resource"}, hcl.TraverseAttr{Name: name}})
+			return &transform.TransformResult{
+				Blocks:         []*hclwrite.Block{first, second, moved},
+				RemoveOriginal: true,
+			}, nil
+		},
+	}
+
+	input := `# leading
+output "before" {
+  value = 1
+}
+
+resource "old_resource" "a" {
+  part = "original"
+}
+
+output "after" {
+  value = 2
+}
+`
+
+	run := func(t *testing.T, collect bool) *transform.Context {
+		t.Helper()
+		ctx := &transform.Context{
+			Content:                []byte(input),
+			Filename:               "test.tf",
+			Metadata:               make(map[string]interface{}),
+			CollectMigrationBlocks: collect,
+		}
+		ctx, err := handlers.NewParseHandler(log).Handle(ctx)
+		if err != nil {
+			t.Fatalf("Failed to parse input: %v", err)
+		}
+		ctx, err = handlers.NewResourceTransformHandler(log, NewMockMigratorProvider([]*MockResourceTransformer{split})).Handle(ctx)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return ctx
+	}
+
+	blockOrder := func(ctx *transform.Context) []string {
+		var order []string
+		for _, block := range ctx.CFGFile.Body().Blocks() {
+			order = append(order, strings.Join(append([]string{block.Type()}, block.Labels()...), "."))
+		}
+		return order
+	}
+
+	t.Run("replacement blocks take the original position", func(t *testing.T) {
+		ctx := run(t, false)
+		expected := []string{
+			"output.before",
+			"resource.new_resource.a",
+			"resource.new_resource_extra.a",
+			"moved",
+			"output.after",
+		}
+		if got := blockOrder(ctx); strings.Join(got, ",") != strings.Join(expected, ",") {
+			t.Errorf("Expected block order %v, got %v", expected, got)
+		}
+		if !strings.HasPrefix(string(ctx.CFGFile.Bytes()), "# leading\n") {
+			t.Error("Expected leading comment to be preserved")
+		}
+	})
+
+	t.Run("migration blocks are collected when requested", func(t *testing.T) {
+		ctx := run(t, true)
+		expected := []string{
+			"output.before",
+			"resource.new_resource.a",
+			"resource.new_resource_extra.a",
+			"output.after",
+		}
+		if got := blockOrder(ctx); strings.Join(got, ",") != strings.Join(expected, ",") {
+			t.Errorf("Expected block order %v, got %v", expected, got)
+		}
+		if len(ctx.MigrationBlocks) != 1 || ctx.MigrationBlocks[0].Type() != "moved" {
+			t.Errorf("Expected one collected moved block, got %d", len(ctx.MigrationBlocks))
+		}
+	})
+}
+
 func contains(slice []string, item string) bool {
 	for _, s := range slice {
 		if s == item {
@@ -0,0 +1,52 @@
+// Package parallel runs independent per-file work on a bounded pool of
+// goroutines.
+package parallel
+
+import (
+	"runtime"
+	"sync"
+)
+
+// Workers returns the number of workers to use for n: GOMAXPROCS when n is
+// zero or negative.
+func Workers(n int) int {
+	if n <= 0 {
+		return runtime.GOMAXPROCS(0)
+	}
+	return n
+}
+
+// ForEach calls fn(i) for every i in [0, count) on at most workers goroutines
+// (see Workers) and returns once every call has returned. Calls are handed out
+// in index order but may run and finish in any order, so fn should store its
+// results by index to keep them deterministic. With one worker, fn runs on
+// the calling goroutine.
+func ForEach(count, workers int, fn func(i int)) {
+	workers = Workers(workers)
+	if workers > count {
+		workers = count
+	}
+	if workers <= 1 {
+		for i := 0; i < count; i++ {
+			fn(i)
+		}
+		return
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				fn(i)
+			}
+		}()
+	}
+	for i := 0; i < count; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+}
@@ -0,0 +1,41 @@
+package parallel
+
+import (
+	"runtime"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkers(t *testing.T) {
+	assert.Equal(t, runtime.GOMAXPROCS(0), Workers(0))
+	assert.Equal(t, runtime.GOMAXPROCS(0), Workers(-1))
+	assert.Equal(t, 3, Workers(3))
+}
+
+func TestForEach(t *testing.T) {
+	for _, workers := range []int{0, 1, 4, 100} {
+		results := make([]int, 50)
+		var running, peak int32
+		ForEach(len(results), workers, func(i int) {
+			n := atomic.AddInt32(&running, 1)
+			for {
+				p := atomic.LoadInt32(&peak)
+				if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+					break
+				}
+			}
+			results[i] = i * i
+			atomic.AddInt32(&running, -1)
+		})
+		for i, r := range results {
+			assert.Equal(t, i*i, r)
+		}
+		assert.LessOrEqual(t, int(peak), Workers(workers))
+	}
+
+	called := false
+	ForEach(0, 4, func(int) { called = true })
+	assert.False(t, called)
+}
@@ -1,10 +1,17 @@
 package pipeline
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+
 	"github.com/hashicorp/go-hclog"
 
 	"github.com/cloudflare/tf-migrate/internal/handlers"
+	"github.com/cloudflare/tf-migrate/internal/parallel"
 	"github.com/cloudflare/tf-migrate/internal/transform"
+	tfhcl "github.com/cloudflare/tf-migrate/internal/transform/hcl"
 )
 
 type Pipeline struct {
@@ -13,20 +20,27 @@ type Pipeline struct {
 }
 
 // BuildConfigPipeline creates the standard pipeline for HCL configuration files
-// Pipeline: Preprocess → Parse → Transform → Format
+// Pipeline: JSON Decode → Preprocess → Parse → Provider Transform → Resource Transform → Format → JSON Encode
+// The JSON handlers only act on .tf.json files.
 func BuildConfigPipeline(log hclog.Logger, providers transform.MigrationProvider) *Pipeline {
+	jsonDecode := handlers.NewJSONDecodeHandler(log)
 	preprocess := handlers.NewPreprocessHandler(providers)
 	parse := handlers.NewParseHandler(log)
+	providerTransformer := handlers.NewProviderTransformHandler(log, providers)
 	resourceTransformer := handlers.NewResourceTransformHandler(log, providers)
 	format := handlers.NewFormatterHandler(log)
+	jsonEncode := handlers.NewJSONEncodeHandler(log)
 
 	// Chain handlers
+	jsonDecode.SetNext(preprocess)
 	preprocess.SetNext(parse)
-	parse.SetNext(resourceTransformer)
+	parse.SetNext(providerTransformer)
+	providerTransformer.SetNext(resourceTransformer)
 	resourceTransformer.SetNext(format)
+	format.SetNext(jsonEncode)
 
 	return &Pipeline{
-		handler: preprocess,
+		handler: jsonDecode,
 		log:     log,
 	}
 }
@@ -44,3 +58,59 @@ func (p *Pipeline) Transform(ctx *transform.Context) ([]byte, error) {
 
 	return result.Content, nil
 }
+
+// ScanConfigs passes every resource and data block of files to the migrator
+// that handles it, when that migrator implements transform.ConfigScanner,
+// after resetting every scanner. It must run before any of the files is
+// transformed. Files are parsed on up to
+// workers goroutines; files that fail to parse are skipped, as the pipeline
+// reports them.
+//
+// ScanConfigs returns a digest of the blocks that were scanned, which changes
+// whenever what the scanners saw changes, or "" when no migrator scans.
+func ScanConfigs(log hclog.Logger, providers transform.MigrationProvider, sourceVersion, targetVersion string, files []tfhcl.SourceFile, workers int) string {
+	scanning := false
+	for _, migrator := range providers.GetAllMigrators(sourceVersion, targetVersion) {
+		if scanner, ok := migrator.(transform.ConfigScanner); ok {
+			scanner.Reset()
+			scanning = true
+		}
+	}
+	if !scanning {
+		return ""
+	}
+
+	digests := make([][]byte, len(files))
+	parallel.ForEach(len(files), workers, func(i int) {
+		file, diags := tfhcl.ParseConfigFile(files[i].Content, filepath.Base(files[i].Name))
+		if diags.HasErrors() {
+			log.Debug("Skipping unparsable file in config scan", "file", files[i].Name)
+			return
+		}
+		h := sha256.New()
+		for _, block := range file.Body().Blocks() {
+			if block.Type() != "resource" && block.Type() != "data" {
+				continue
+			}
+			labels := block.Labels()
+			if len(labels) < 1 {
+				continue
+			}
+			resourceType := labels[0]
+			if block.Type() == "data" {
+				resourceType = "data." + resourceType
+			}
+			if scanner, ok := providers.GetMigrator(resourceType, sourceVersion, targetVersion).(transform.ConfigScanner); ok {
+				scanner.ScanConfig(block)
+				h.Write(block.BuildTokens(nil).Bytes())
+			}
+		}
+		digests[i] = h.Sum(nil)
+	})
+
+	h := sha256.New()
+	for i, digest := range digests {
+		fmt.Fprintf(h, "%s\x00%x\x00", files[i].Name, digest)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -2,7 +2,9 @@ package pipeline_test
 
 import (
 	"fmt"
+	"sort"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/hashicorp/go-hclog"
@@ -12,6 +14,7 @@ import (
 
 	"github.com/cloudflare/tf-migrate/internal/pipeline"
 	"github.com/cloudflare/tf-migrate/internal/transform"
+	tfhcl "github.com/cloudflare/tf-migrate/internal/transform/hcl"
 )
 
 type MockHandler struct {
@@ -377,6 +380,60 @@ func TestResourceTransformationWithSplitting(t *testing.T) {
 }
 
 // Test error propagation through pipeline
+// scanningTransformer records the names of the blocks it is given by
+// ScanConfigs.
+type scanningTransformer struct {
+	MockResourceTransformer
+	mu      sync.Mutex
+	scanned []string
+}
+
+func (s *scanningTransformer) ScanConfig(block *hclwrite.Block) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.scanned = append(s.scanned, block.Labels()[1])
+}
+
+func (s *scanningTransformer) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.scanned = nil
+}
+
+func TestScanConfigs(t *testing.T) {
+	scanner := &scanningTransformer{MockResourceTransformer: MockResourceTransformer{resourceType: "test_scanned"}}
+	providers := setupTestMigrators(t, scanner, &MockResourceTransformer{resourceType: "test_other"})
+
+	files := []tfhcl.SourceFile{
+		{Name: "a.tf", Content: []byte(`resource "test_scanned" "a" {}` + "\n" + `resource "test_other" "b" {}`)},
+		{Name: "b.tf", Content: []byte(`resource "test_scanned" "c" {}`)},
+		{Name: "broken.tf", Content: []byte(`resource "test_scanned" {{{`)},
+		{Name: "c.tf.json", Content: []byte(`{"resource": {"test_scanned": {"d": {}}}}`)},
+	}
+	digest := pipeline.ScanConfigs(log, providers, sourceVersion, targetVersion, files, 2)
+
+	sort.Strings(scanner.scanned)
+	if got := strings.Join(scanner.scanned, ","); got != "a,c,d" {
+		t.Errorf("scanned %q, want a,c,d", got)
+	}
+
+	// The digest only follows the scanned blocks.
+	files[0].Content = []byte(`resource "test_scanned" "a" {}` + "\n" + `resource "test_other" "renamed" {}`)
+	if got := pipeline.ScanConfigs(log, providers, sourceVersion, targetVersion, files, 2); got != digest {
+		t.Errorf("digest changed with a block that is not scanned")
+	}
+	files[1].Content = []byte(`resource "test_scanned" "renamed" {}`)
+	if got := pipeline.ScanConfigs(log, providers, sourceVersion, targetVersion, files, 2); got == digest {
+		t.Errorf("digest did not change with a scanned block")
+	}
+
+	// Each scan starts afresh.
+	pipeline.ScanConfigs(log, providers, sourceVersion, targetVersion, files[1:2], 2)
+	if got := strings.Join(scanner.scanned, ","); got != "renamed" {
+		t.Errorf("scanned %q after a second scan, want renamed", got)
+	}
+}
+
 func TestPipelineErrorPropagation(t *testing.T) {
 	// Test with nil content - should handle gracefully as empty content
 	providers := setupTestMigrators(t)
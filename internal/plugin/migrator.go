@@ -0,0 +1,122 @@
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+
+	"github.com/cloudflare/tf-migrate/internal/transform"
+)
+
+// Migrator is the ResourceTransformer of one type migrated by a plugin.
+type Migrator struct {
+	plugin *Plugin
+	desc   Description
+}
+
+// Description returns what the plugin said about the type.
+func (m *Migrator) Description() Description {
+	return m.desc
+}
+
+func (m *Migrator) CanHandle(resourceType string) bool {
+	return resourceType == m.desc.Type
+}
+
+func (m *Migrator) GetResourceType() string {
+	if m.desc.RenameTo != "" {
+		return m.desc.RenameTo
+	}
+	return strings.TrimPrefix(m.desc.Type, "data.")
+}
+
+func (m *Migrator) Preprocess(content string) string {
+	return content
+}
+
+// GetResourceRename implements the ResourceRenamer interface, so references
+// to the type in other files are rewritten.
+func (m *Migrator) GetResourceRename() ([]string, string) {
+	return []string{m.desc.Type}, m.targetReferenceType()
+}
+
+// GetAttributeRenames implements the AttributeRenamer interface.
+func (m *Migrator) GetAttributeRenames() []transform.AttributeRename {
+	from := make([]string, 0, len(m.desc.AttributeRenames))
+	for old := range m.desc.AttributeRenames {
+		from = append(from, old)
+	}
+	sort.Strings(from)
+
+	var renames []transform.AttributeRename
+	for _, old := range from {
+		renames = append(renames, transform.AttributeRename{
+			ResourceType: m.targetReferenceType(),
+			OldAttribute: old,
+			NewAttribute: m.desc.AttributeRenames[old],
+		})
+	}
+	return renames
+}
+
+// targetReferenceType returns the target type as it appears in references,
+// with the data. prefix of data sources.
+func (m *Migrator) targetReferenceType() string {
+	if strings.HasPrefix(m.desc.Type, "data.") {
+		return "data." + m.GetResourceType()
+	}
+	return m.GetResourceType()
+}
+
+func (m *Migrator) TransformConfig(ctx *transform.Context, block *hclwrite.Block) (*transform.TransformResult, error) {
+	var resp TransformResponse
+	err := m.plugin.call(Request{
+		Method:        MethodTransform,
+		Type:          m.desc.Type,
+		File:          ctx.Filename,
+		SourceVersion: ctx.SourceVersion,
+		TargetVersion: ctx.TargetVersion,
+		Block:         string(hclwrite.Format(block.BuildTokens(nil).Bytes())),
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, d := range resp.Diagnostics {
+		ctx.Diagnostics = append(ctx.Diagnostics, &hcl.Diagnostic{
+			Severity: severity(d.Severity),
+			Summary:  d.Summary,
+			Detail:   d.Detail,
+		})
+	}
+	if resp.Error != "" {
+		return nil, errors.New(resp.Error)
+	}
+	if resp.Blocks == nil {
+		return &transform.TransformResult{Blocks: []*hclwrite.Block{block}}, nil
+	}
+
+	file, diags := hclwrite.ParseConfig([]byte(*resp.Blocks), ctx.Filename, hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("plugin %s returned invalid HCL: %s", m.plugin.path, diags.Error())
+	}
+	if len(file.Body().Attributes()) > 0 {
+		return nil, fmt.Errorf("plugin %s returned attributes outside a block", m.plugin.path)
+	}
+	return &transform.TransformResult{Blocks: file.Body().Blocks(), RemoveOriginal: true}, nil
+}
+
+func severity(s string) hcl.DiagnosticSeverity {
+	switch s {
+	case SeverityError:
+		return hcl.DiagError
+	case SeverityInfo:
+		return transform.DiagInfo
+	default:
+		return hcl.DiagWarning
+	}
+}
@@ -0,0 +1,200 @@
+package plugin
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudflare/tf-migrate/internal"
+)
+
+// maxMessageSize bounds a single response line.
+const maxMessageSize = 64 << 20
+
+// closeTimeout is how long Close waits for a plugin to exit after its stdin
+// is closed before killing it.
+const closeTimeout = 5 * time.Second
+
+// requestTimeout is how long a plugin has to answer a request before it is
+// killed. It is a variable so that tests can shorten it.
+var requestTimeout = time.Minute
+
+var identifier = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// Plugin is a running plugin process.
+type Plugin struct {
+	path      string
+	cmd       *exec.Cmd
+	stdin     io.WriteCloser
+	stdout    *bufio.Scanner
+	migrators []*Migrator
+
+	// mu serialises requests: the protocol has one request in flight at a
+	// time.
+	mu  sync.Mutex
+	err error // set once the plugin failed; every later request fails with it
+}
+
+// Start starts the plugin at path and asks it which types it migrates.
+func Start(path string) (*Plugin, error) {
+	cmd := exec.Command(path)
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start plugin %s: %w", path, err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start plugin %s: %w", path, err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start plugin %s: %w", path, err)
+	}
+
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxMessageSize)
+	p := &Plugin{path: path, cmd: cmd, stdin: stdin, stdout: scanner}
+
+	var resp DescribeResponse
+	if err := p.call(Request{Method: MethodDescribe, ProtocolVersion: ProtocolVersion}, &resp); err != nil {
+		p.Close()
+		return nil, err
+	}
+	if resp.ProtocolVersion != ProtocolVersion {
+		p.Close()
+		return nil, fmt.Errorf("plugin %s speaks protocol version %d, tf-migrate speaks %d", path, resp.ProtocolVersion, ProtocolVersion)
+	}
+	seen := make(map[string]bool)
+	for i := range resp.Migrators {
+		d := resp.Migrators[i]
+		if d.SourceVersion == "" {
+			d.SourceVersion = "v4"
+		}
+		if d.TargetVersion == "" {
+			d.TargetVersion = "v5"
+		}
+		if err := validate(d); err != nil {
+			p.Close()
+			return nil, fmt.Errorf("plugin %s: %w", path, err)
+		}
+		key := d.Type + ":" + d.SourceVersion + ":" + d.TargetVersion
+		if seen[key] {
+			p.Close()
+			return nil, fmt.Errorf("plugin %s: %s is described more than once", path, d.Type)
+		}
+		seen[key] = true
+		p.migrators = append(p.migrators, &Migrator{plugin: p, desc: d})
+	}
+	return p, nil
+}
+
+func validate(d Description) error {
+	if !identifier.MatchString(strings.TrimPrefix(d.Type, "data.")) {
+		return fmt.Errorf("invalid type %q", d.Type)
+	}
+	if d.RenameTo != "" && !identifier.MatchString(d.RenameTo) {
+		return fmt.Errorf("%s: invalid rename_to %q; give the type without the data. prefix", d.Type, d.RenameTo)
+	}
+	for from, to := range d.AttributeRenames {
+		if !identifier.MatchString(from) || !identifier.MatchString(to) {
+			return fmt.Errorf("%s: invalid attribute rename %q: %q", d.Type, from, to)
+		}
+	}
+	return nil
+}
+
+// Path returns the path the plugin was started from.
+func (p *Plugin) Path() string {
+	return p.path
+}
+
+// Migrators returns a migrator for every type the plugin migrates.
+func (p *Plugin) Migrators() []*Migrator {
+	return p.migrators
+}
+
+// Register registers the migrators of the plugin with the migration
+// registry, replacing any migrator registered for the same type and
+// versions.
+func (p *Plugin) Register() {
+	for _, m := range p.migrators {
+		internal.RegisterMigrator(m.desc.Type, m.desc.SourceVersion, m.desc.TargetVersion, m)
+	}
+}
+
+// call sends req and decodes the response into resp.
+func (p *Plugin) call(req Request, resp interface{}) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err != nil {
+		return p.err
+	}
+
+	data, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	// The request is sent and read in the background, so that a plugin that
+	// hangs, or stops reading its input, can be killed.
+	done := make(chan error, 1)
+	go func() {
+		if _, err := p.stdin.Write(append(data, '\n')); err != nil {
+			done <- fmt.Errorf("plugin %s: failed to send %s request: %w", p.path, req.Method, err)
+			return
+		}
+		if !p.stdout.Scan() {
+			err := p.stdout.Err()
+			if err == nil {
+				err = io.ErrUnexpectedEOF
+			}
+			done <- fmt.Errorf("plugin %s: no response to %s request: %w", p.path, req.Method, err)
+			return
+		}
+		done <- nil
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			p.err = err
+			return p.err
+		}
+	case <-time.After(requestTimeout):
+		p.cmd.Process.Kill()
+		p.err = fmt.Errorf("plugin %s: no response to %s request within %s; the plugin was stopped", p.path, req.Method, requestTimeout)
+		return p.err
+	}
+	if err := json.Unmarshal(p.stdout.Bytes(), resp); err != nil {
+		p.err = fmt.Errorf("plugin %s: invalid response to %s request: %w", p.path, req.Method, err)
+		return p.err
+	}
+	return nil
+}
+
+// Close closes the plugin's stdin and waits for it to exit, killing it if it
+// does not exit in time.
+func (p *Plugin) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err == nil {
+		p.err = fmt.Errorf("plugin %s is closed", p.path)
+	}
+	p.stdin.Close()
+
+	done := make(chan error, 1)
+	go func() { done <- p.cmd.Wait() }()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(closeTimeout):
+		p.cmd.Process.Kill()
+		<-done
+		return fmt.Errorf("plugin %s did not exit after its input was closed", p.path)
+	}
+}
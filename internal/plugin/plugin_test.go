@@ -0,0 +1,179 @@
+package plugin
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudflare/tf-migrate/internal/testhelpers"
+	"github.com/cloudflare/tf-migrate/internal/transform"
+)
+
+// TestMain runs the test binary as a fake plugin when it is started by a
+// test with TF_MIGRATE_TEST_PLUGIN set to the behaviour wanted.
+func TestMain(m *testing.M) {
+	if mode := os.Getenv("TF_MIGRATE_TEST_PLUGIN"); mode != "" {
+		os.Exit(fakePlugin(mode))
+	}
+	os.Exit(m.Run())
+}
+
+// fakePlugin migrates acme_widget to acme_gizmo, renaming label to name.
+func fakePlugin(mode string) int {
+	in := bufio.NewScanner(os.Stdin)
+	out := json.NewEncoder(os.Stdout)
+	for in.Scan() {
+		var req Request
+		if err := json.Unmarshal(in.Bytes(), &req); err != nil {
+			return 1
+		}
+		switch req.Method {
+		case MethodDescribe:
+			version := ProtocolVersion
+			if mode == "version" {
+				version = 99
+			}
+			out.Encode(DescribeResponse{ProtocolVersion: version, Migrators: []Description{
+				{Type: "acme_widget", RenameTo: "acme_gizmo", AttributeRenames: map[string]string{"label": "name"}},
+				{Type: "data.acme_widgets"},
+			}})
+		case MethodTransform:
+			if mode == "crash" {
+				return 1
+			}
+			if mode == "hang" {
+				time.Sleep(time.Hour)
+			}
+			var resp TransformResponse
+			switch {
+			case strings.Contains(req.Block, "broken"):
+				resp.Error = "cannot migrate broken widgets"
+			case req.Type == "data.acme_widgets":
+				resp.Diagnostics = []Diagnostic{{Severity: SeverityInfo, Summary: "data.acme_widgets is unchanged"}}
+			default:
+				name := strings.Fields(req.Block)[2]
+				name = strings.Trim(name, `"`)
+				blocks := strings.Replace(req.Block, `"acme_widget"`, `"acme_gizmo"`, 1)
+				blocks = strings.Replace(blocks, "label", "name", 1)
+				blocks += fmt.Sprintf("\nmoved {\n  from = acme_widget.%s\n  to   = acme_gizmo.%s\n}\n", name, name)
+				resp.Blocks = &blocks
+				resp.Diagnostics = []Diagnostic{{Summary: "check " + name}}
+			}
+			out.Encode(resp)
+		}
+	}
+	return 0
+}
+
+func startFake(t *testing.T, mode string) (*Plugin, error) {
+	t.Helper()
+	t.Setenv("TF_MIGRATE_TEST_PLUGIN", mode)
+	p, err := Start(os.Args[0])
+	if p != nil {
+		t.Cleanup(func() { p.Close() })
+	}
+	return p, err
+}
+
+func parseBlock(t *testing.T, src string) *hclwrite.Block {
+	t.Helper()
+	file, diags := hclwrite.ParseConfig([]byte(src), "main.tf", hcl.InitialPos)
+	require.False(t, diags.HasErrors())
+	return file.Body().Blocks()[0]
+}
+
+func TestPlugin(t *testing.T) {
+	p, err := startFake(t, "ok")
+	require.NoError(t, err)
+	require.Len(t, p.Migrators(), 2)
+
+	widget, widgets := p.Migrators()[0], p.Migrators()[1]
+	assert.True(t, widget.CanHandle("acme_widget"))
+	assert.Equal(t, "acme_gizmo", widget.GetResourceType())
+	assert.Equal(t, "v4", widget.Description().SourceVersion)
+	oldTypes, newType := widget.GetResourceRename()
+	assert.Equal(t, []string{"acme_widget"}, oldTypes)
+	assert.Equal(t, "acme_gizmo", newType)
+	assert.Equal(t, []transform.AttributeRename{
+		{ResourceType: "acme_gizmo", OldAttribute: "label", NewAttribute: "name"},
+	}, widget.GetAttributeRenames())
+	assert.Equal(t, "acme_widgets", widgets.GetResourceType())
+
+	t.Run("Replace", func(t *testing.T) {
+		testhelpers.RunConfigTransformTests(t, []testhelpers.ConfigTestCase{
+			{
+				Name: "renamed with moved block",
+				Input: `resource "acme_widget" "a" {
+  label = "x"
+}`,
+				Expected: `resource "acme_gizmo" "a" {
+  name = "x"
+}
+
+moved {
+  from = acme_widget.a
+  to   = acme_gizmo.a
+}`,
+			},
+		}, widget)
+	})
+
+	t.Run("Unchanged", func(t *testing.T) {
+		testhelpers.RunConfigTransformTests(t, []testhelpers.ConfigTestCase{
+			{
+				Name:     "no blocks in response",
+				Input:    `data "acme_widgets" "all" {}`,
+				Expected: `data "acme_widgets" "all" {}`,
+			},
+		}, widgets)
+	})
+
+	t.Run("Diagnostics", func(t *testing.T) {
+		ctx := &transform.Context{Filename: "main.tf"}
+		_, err := widget.TransformConfig(ctx, parseBlock(t, "resource \"acme_widget\" \"a\" {\n  label = \"x\"\n}\n"))
+		require.NoError(t, err)
+		require.Len(t, ctx.Diagnostics, 1)
+		assert.Equal(t, hcl.DiagWarning, ctx.Diagnostics[0].Severity)
+		assert.Equal(t, "check a", ctx.Diagnostics[0].Summary)
+
+		_, err = widget.TransformConfig(ctx, parseBlock(t, "resource \"acme_widget\" \"broken\" {}\n"))
+		assert.EqualError(t, err, "cannot migrate broken widgets")
+	})
+}
+
+func TestPluginErrors(t *testing.T) {
+	_, err := startFake(t, "version")
+	assert.ErrorContains(t, err, "speaks protocol version 99")
+
+	_, err = Start("/nonexistent/plugin")
+	assert.ErrorContains(t, err, "failed to start plugin /nonexistent/plugin")
+
+	p, err := startFake(t, "crash")
+	require.NoError(t, err)
+	_, err = p.Migrators()[0].TransformConfig(&transform.Context{}, parseBlock(t, "resource \"acme_widget\" \"a\" {}\n"))
+	assert.ErrorContains(t, err, "no response to transform request")
+
+	t.Run("a plugin that does not answer is stopped", func(t *testing.T) {
+		defer func(timeout time.Duration) { requestTimeout = timeout }(requestTimeout)
+		p, err := startFake(t, "hang")
+		require.NoError(t, err)
+
+		requestTimeout = 100 * time.Millisecond
+		m := p.Migrators()[0]
+		_, err = m.TransformConfig(&transform.Context{}, parseBlock(t, "resource \"acme_widget\" \"a\" {}\n"))
+		assert.ErrorContains(t, err, "plugin "+os.Args[0]+": no response to transform request within 100ms")
+
+		// Later requests fail with the same error
+		_, err = m.TransformConfig(&transform.Context{}, parseBlock(t, "resource \"acme_widget\" \"b\" {}\n"))
+		assert.ErrorContains(t, err, "within 100ms")
+	})
+}
@@ -0,0 +1,90 @@
+// Package plugin runs migrators that live outside the tf-migrate binary. A
+// plugin is an executable that tf-migrate starts once per run and talks to
+// over its stdin and stdout, one JSON message per line: tf-migrate writes a
+// request, the plugin answers it with one response. The plugin exits when
+// its stdin is closed, and may log to stderr, which is passed through.
+//
+// The first request is always describe, which the plugin answers with the
+// types it migrates:
+//
+//	{"method":"describe","protocol_version":1}
+//	{"protocol_version":1,"migrators":[{"type":"acme_widget","rename_to":"acme_gizmo","attribute_renames":{"label":"name"}}]}
+//
+// Then, for every resource or data block of one of those types, tf-migrate
+// sends a transform request with the block's HCL, and the plugin answers with
+// the HCL of the blocks that replace it, and diagnostics:
+//
+//	{"method":"transform","type":"acme_widget","file":"main.tf","source_version":"v4","target_version":"v5","block":"resource \"acme_widget\" \"a\" {\n  label = \"x\"\n}\n"}
+//	{"blocks":"resource \"acme_gizmo\" \"a\" {\n  name = \"x\"\n}\n\nmoved {\n  from = acme_widget.a\n  to   = acme_gizmo.a\n}\n","diagnostics":[{"severity":"warning","summary":"check acme_gizmo.a"}]}
+//
+// A response without blocks leaves the block unchanged, and an empty blocks
+// string removes it. A response with an error fails the block, as an error
+// from a built-in migrator does.
+package plugin
+
+// ProtocolVersion is the version of the protocol tf-migrate speaks. A plugin
+// that answers describe with another version is refused.
+const ProtocolVersion = 1
+
+// Request methods.
+const (
+	MethodDescribe  = "describe"
+	MethodTransform = "transform"
+)
+
+// Values of Diagnostic.Severity.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityInfo    = "info"
+)
+
+// Request is a message from tf-migrate to a plugin.
+type Request struct {
+	Method          string `json:"method"`
+	ProtocolVersion int    `json:"protocol_version,omitempty"`
+
+	// Transform requests only.
+	Type          string `json:"type,omitempty"`
+	File          string `json:"file,omitempty"`
+	SourceVersion string `json:"source_version,omitempty"`
+	TargetVersion string `json:"target_version,omitempty"`
+	Block         string `json:"block,omitempty"`
+}
+
+// DescribeResponse answers a describe request.
+type DescribeResponse struct {
+	ProtocolVersion int           `json:"protocol_version"`
+	Migrators       []Description `json:"migrators"`
+}
+
+// Description describes one type a plugin migrates.
+type Description struct {
+	// Type is the source type, with the data. prefix for data sources.
+	Type string `json:"type"`
+	// SourceVersion and TargetVersion default to v4 and v5.
+	SourceVersion string `json:"source_version,omitempty"`
+	TargetVersion string `json:"target_version,omitempty"`
+	// RenameTo is the target type when it changes, without the data. prefix.
+	RenameTo string `json:"rename_to,omitempty"`
+	// AttributeRenames maps old attribute names to new ones, so references
+	// to them in other files are rewritten.
+	AttributeRenames map[string]string `json:"attribute_renames,omitempty"`
+}
+
+// TransformResponse answers a transform request.
+type TransformResponse struct {
+	// Blocks is the HCL of the blocks that replace the block, or nil to
+	// leave it unchanged.
+	Blocks      *string      `json:"blocks,omitempty"`
+	Diagnostics []Diagnostic `json:"diagnostics,omitempty"`
+	Error       string       `json:"error,omitempty"`
+}
+
+// Diagnostic is reported by a plugin for a block.
+type Diagnostic struct {
+	// Severity is error, warning or info; it defaults to warning.
+	Severity string `json:"severity,omitempty"`
+	Summary  string `json:"summary"`
+	Detail   string `json:"detail,omitempty"`
+}
@@ -0,0 +1,456 @@
+// Package postprocess applies the cross-file part of a migration: once every
+// file has been through the config pipeline, references to resources and
+// attributes that a migrator renamed are rewritten in all files, and
+// references to attributes that no longer exist are reported.
+//
+// It works on file contents in memory, keyed by path, so that it can be used
+// both by the CLI and when embedding tf-migrate as a library.
+package postprocess
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+
+	"github.com/cloudflare/tf-migrate/internal/parallel"
+	"github.com/cloudflare/tf-migrate/internal/transform"
+	tfhcl "github.com/cloudflare/tf-migrate/internal/transform/hcl"
+)
+
+// Rules are the cross-file rules declared by the migrators through the
+// optional ResourceRenamer, AttributeRenamer, ComputedAttributeMapper and
+// InvalidAttributeReferenceDetector interfaces.
+type Rules struct {
+	// Renames maps old resource types to new ones.
+	Renames                    map[string]string
+	AttributeRenames           []transform.AttributeRename
+	ComputedAttributeMappings  []transform.ComputedAttributeMapping
+	InvalidAttributeReferences []transform.InvalidAttributeReference
+
+	// Keep reports whether the reference ref in the file at path must be left
+	// as it is, e.g. because its resource type is not migrated there. It may
+	// be nil.
+	Keep func(path string, ref tfhcl.Reference) bool
+}
+
+// Empty reports whether there is nothing to rewrite or scan for.
+func (r Rules) Empty() bool {
+	return len(r.Renames) == 0 && len(r.AttributeRenames) == 0 && len(r.ComputedAttributeMappings) == 0 && len(r.InvalidAttributeReferences) == 0
+}
+
+// Count returns the number of rewrite rules.
+func (r Rules) Count() int {
+	return len(r.Renames) + len(r.AttributeRenames) + len(r.ComputedAttributeMappings)
+}
+
+// CollectRules collects the cross-file rules of migrators.
+func CollectRules(log hclog.Logger, migrators []transform.ResourceTransformer) Rules {
+	rules := Rules{Renames: make(map[string]string)}
+
+	for _, migrator := range migrators {
+		// Check if this migrator implements ResourceRenamer interface
+		if renamer, ok := migrator.(transform.ResourceRenamer); ok {
+			oldTypes, newType := renamer.GetResourceRename()
+			if len(oldTypes) > 0 && newType != "" {
+				for _, oldType := range oldTypes {
+					if oldType != newType {
+						rules.Renames[oldType] = newType
+						log.Debug("Collected resource rename", "old", oldType, "new", newType)
+					} else {
+						log.Debug("Resource type unchanged", "type", oldType)
+					}
+				}
+			} else {
+				log.Debug("Migrator implements ResourceRenamer but returned empty type names",
+					"oldTypes", oldTypes, "newType", newType)
+			}
+		} else {
+			log.Debug("Migrator does not implement ResourceRenamer interface - cross-file references may not be updated",
+				"migrator", fmt.Sprintf("%T", migrator))
+		}
+
+		// Check if this migrator implements AttributeRenamer interface
+		if attrRenamer, ok := migrator.(transform.AttributeRenamer); ok {
+			renames := attrRenamer.GetAttributeRenames()
+			if len(renames) > 0 {
+				rules.AttributeRenames = append(rules.AttributeRenames, renames...)
+				for _, r := range renames {
+					log.Debug("Collected attribute rename",
+						"resource_type", r.ResourceType,
+						"old_attr", r.OldAttribute,
+						"new_attr", r.NewAttribute)
+				}
+			}
+		}
+
+		// Check if this migrator implements ComputedAttributeMapper interface
+		if computedAttrMapper, ok := migrator.(transform.ComputedAttributeMapper); ok {
+			mappings := computedAttrMapper.GetComputedAttributeMappings()
+			if len(mappings) > 0 {
+				rules.ComputedAttributeMappings = append(rules.ComputedAttributeMappings, mappings...)
+				for _, m := range mappings {
+					log.Debug("Collected computed attribute mapping",
+						"old_type", m.OldResourceType,
+						"old_attr", m.OldAttribute,
+						"new_type", m.NewResourceType,
+						"new_attr", m.NewAttribute)
+				}
+			}
+		}
+
+		// Check if this migrator implements InvalidAttributeReferenceDetector interface
+		if detector, ok := migrator.(transform.InvalidAttributeReferenceDetector); ok {
+			refs := detector.GetInvalidAttributeReferences()
+			if len(refs) > 0 {
+				rules.InvalidAttributeReferences = append(rules.InvalidAttributeReferences, refs...)
+				for _, r := range refs {
+					log.Debug("Collected invalid attribute reference detector",
+						"resource_type", r.ResourceType,
+						"attribute", r.Attribute)
+				}
+			}
+		}
+	}
+
+	return rules
+}
+
+// Result is the outcome of Apply.
+type Result struct {
+	// AppliedRenames maps the old resource types whose references were
+	// rewritten to their new type.
+	AppliedRenames map[string]string
+	// AppliedAttributeRenames holds the attribute renames that were applied,
+	// keyed by ResourceType.OldAttribute.
+	AppliedAttributeRenames map[string]transform.AttributeRename
+	// AppliedComputedMappings holds the computed attribute mappings that were
+	// applied, keyed by OldResourceType.OldAttribute.
+	AppliedComputedMappings map[string]transform.ComputedAttributeMapping
+	// Diagnostics holds a warning for every reference to an invalid attribute.
+	Diagnostics hcl.Diagnostics
+}
+
+// Applied returns the number of rules that matched at least one reference.
+func (r *Result) Applied() int {
+	return len(r.AppliedRenames) + len(r.AppliedAttributeRenames) + len(r.AppliedComputedMappings)
+}
+
+// Apply rewrites references in contents, which maps each path to its
+// migrated content, according to rules. Entries are updated in place; paths
+// missing from contents are skipped. After rewriting, references to invalid
+// attributes are reported as warnings.
+//
+// Each file is parsed once, on up to workers goroutines (see
+// parallel.Workers); the references found are then rewritten and checked
+// against every rule without parsing the file again.
+func Apply(log hclog.Logger, rules Rules, paths []string, contents map[string]string, workers int) *Result {
+	scanned := make([]*scannedFile, len(paths))
+	parallel.ForEach(len(paths), workers, func(i int) {
+		if content, ok := contents[paths[i]]; ok {
+			scanned[i] = scanFile(paths[i], content)
+		}
+	})
+
+	// Track resources that were intentionally converted to removed {} blocks.
+	// References to these addresses must NOT be rewritten to renamed types.
+	removedRefsByType := make(map[string]map[string]struct{})
+	// Resources annotated with "# tf-migrate:ignore" keep their old type and
+	// attributes, so references to them are not rewritten at all.
+	ignoredRefsByType := make(map[string]map[string]struct{})
+	for i, file := range scanned {
+		if file == nil {
+			continue
+		}
+		if file.err != nil {
+			log.Warn("Failed to parse file for cross-file reference updates", "file", paths[i], "error", file.err)
+			continue
+		}
+		for _, from := range file.removed {
+			if _, ok := removedRefsByType[from.Type]; !ok {
+				removedRefsByType[from.Type] = make(map[string]struct{})
+			}
+			removedRefsByType[from.Type][from.Name] = struct{}{}
+		}
+		for _, ref := range file.ignored {
+			if _, ok := ignoredRefsByType[ref.Type]; !ok {
+				ignoredRefsByType[ref.Type] = make(map[string]struct{})
+			}
+			ignoredRefsByType[ref.Type][ref.Name] = struct{}{}
+		}
+	}
+
+	rewriter := newReferenceRewriter(rules, removedRefsByType)
+	rewriter.ignoredRefsByType = ignoredRefsByType
+
+	// Edits are decided in path order, so that the bookkeeping of applied
+	// rules is deterministic, and applied to the files in parallel.
+	edits := make([][]tfhcl.TextEdit, len(paths))
+	for i, file := range scanned {
+		if file != nil && file.err == nil {
+			edits[i] = rewriter.rewrite(paths[i], file.refs)
+		}
+	}
+	rewritten := make([]string, len(paths))
+	parallel.ForEach(len(paths), workers, func(i int) {
+		if len(edits[i]) == 0 {
+			return
+		}
+		content, err := scanned[i].apply(filepath.Base(paths[i]), edits[i])
+		if err != nil {
+			log.Warn("Failed to convert file back to JSON after cross-file reference updates", "file", paths[i], "error", err)
+			return
+		}
+		rewritten[i] = content
+	})
+	for i, path := range paths {
+		if rewritten[i] != "" && rewritten[i] != contents[path] {
+			contents[path] = rewritten[i]
+			log.Debug("Updated references", "file", filepath.Base(path))
+		}
+	}
+
+	result := &Result{
+		AppliedRenames:          rewriter.appliedRenames,
+		AppliedAttributeRenames: rewriter.appliedAttrRenames,
+		AppliedComputedMappings: rewriter.appliedComputedMappings,
+	}
+
+	// Report references to invalid attributes. The references were updated
+	// by the rewrite, so that already-fixed references (e.g. secret →
+	// tunnel_secret) don't produce false positives.
+	if len(rules.InvalidAttributeReferences) > 0 {
+		result.Diagnostics = scanInvalidAttributeReferences(log, paths, scanned, rules.InvalidAttributeReferences)
+	}
+	return result
+}
+
+// scannedFile is a file parsed for Apply.
+type scannedFile struct {
+	// native is the content in native syntax; for a .tf.json file it is the
+	// converted content.
+	native []byte
+	json   bool
+	// refs holds the references of the file outside moved and removed
+	// blocks. rewrite updates their Type and Attribute.
+	refs []tfhcl.Reference
+	// removed holds the addresses of the removed blocks of the file.
+	removed []tfhcl.Reference
+	// ignored holds the addresses of the blocks of the file annotated with
+	// "# tf-migrate:ignore".
+	ignored []tfhcl.Reference
+	err     error
+}
+
+// scanFile parses content, which is in the syntax of path, and collects its
+// references and removed blocks.
+func scanFile(path, content string) *scannedFile {
+	filename := filepath.Base(path)
+	file := &scannedFile{native: []byte(content), json: tfhcl.IsJSONConfigFile(filename)}
+	if file.json {
+		native, err := tfhcl.JSONToNative([]byte(content), filename)
+		if err != nil {
+			file.err = err
+			return file
+		}
+		file.native = native
+	}
+
+	parsed, diags := hclsyntax.ParseConfig(file.native, filename, hcl.InitialPos)
+	if diags.HasErrors() {
+		file.err = diags
+		return file
+	}
+	body, ok := parsed.Body.(*hclsyntax.Body)
+	if !ok {
+		return file
+	}
+
+	file.refs = tfhcl.FindBodyReferences(body, "moved", "removed")
+	if !file.json {
+		// Ignored files and blocks are left as they are, references included.
+		annotations := tfhcl.FindIgnoreAnnotations(file.native, filename)
+		refs := file.refs[:0]
+		for _, ref := range file.refs {
+			if !annotations.Covers(ref.TypeRange) {
+				refs = append(refs, ref)
+			}
+		}
+		file.refs = refs
+		for _, block := range body.Blocks {
+			if (block.Type != "resource" && block.Type != "data") || len(block.Labels) < 2 {
+				continue
+			}
+			resourceType := block.Labels[0]
+			if block.Type == "data" {
+				resourceType = "data." + resourceType
+			}
+			if annotations.Ignores(resourceType, block.Labels[1]) {
+				file.ignored = append(file.ignored, tfhcl.Reference{Type: resourceType, Name: block.Labels[1]})
+			}
+		}
+	}
+	for _, block := range body.Blocks {
+		if block.Type != "removed" {
+			continue
+		}
+		from, ok := block.Body.Attributes["from"]
+		if !ok {
+			continue
+		}
+		traversal, diags := hcl.AbsTraversalForExpr(from.Expr)
+		if diags.HasErrors() || len(traversal) < 2 {
+			continue
+		}
+		name, ok := traversal[1].(hcl.TraverseAttr)
+		if !ok {
+			continue
+		}
+		file.removed = append(file.removed, tfhcl.Reference{Type: traversal.RootName(), Name: name.Name})
+	}
+	return file
+}
+
+// apply returns the content of the file with edits applied, converted back
+// to JSON for a .tf.json file.
+func (f *scannedFile) apply(filename string, edits []tfhcl.TextEdit) (string, error) {
+	content := tfhcl.ApplyEdits(f.native, edits)
+	if !f.json {
+		return string(content), nil
+	}
+	converted, err := tfhcl.NativeToJSON(content, filename)
+	if err != nil {
+		return "", err
+	}
+	return string(converted), nil
+}
+
+// scanInvalidAttributeReferences returns a DiagWarning for each reference in
+// scanned to a known-invalid attribute.
+func scanInvalidAttributeReferences(log hclog.Logger, paths []string, scanned []*scannedFile, refs []transform.InvalidAttributeReference) hcl.Diagnostics {
+	var diags hcl.Diagnostics
+
+	for i, file := range scanned {
+		if file == nil || file.err != nil {
+			continue
+		}
+		path := paths[i]
+
+		for _, found := range file.refs {
+			for _, ref := range refs {
+				if found.Type != ref.ResourceType || found.Attribute != ref.Attribute {
+					continue
+				}
+				match := found.Type + "." + found.Name + "." + found.Attribute
+				summary := fmt.Sprintf("Unknown attribute reference: %s", match)
+				detail := fmt.Sprintf("In %s\n\n  %s", filepath.Base(path), ref.Suggestion)
+				log.Debug("Found invalid attribute reference",
+					"file", filepath.Base(path),
+					"match", match)
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagWarning,
+					Summary:  summary,
+					Detail:   detail,
+				})
+			}
+		}
+	}
+
+	return diags
+}
+
+// referenceRewriter rewrites references to resources whose type or attributes
+// were renamed by a migrator. References are found by walking the expressions
+// of each file (see tfhcl.FindReferences), so text inside string literals,
+// heredocs and comments is never touched, and indexed (x["a"], x[count.index])
+// and splat (x[*]) forms are rewritten along with plain references. moved and
+// removed blocks are skipped: their addresses must keep the old type.
+type referenceRewriter struct {
+	renames map[string]string
+	// attributeRenames indexes the attribute renames by ResourceType, in
+	// rule order.
+	attributeRenames     map[string][]transform.AttributeRename
+	computedAttrMappings []transform.ComputedAttributeMapping
+	// removedRefsByType holds the addresses converted to removed {} blocks.
+	// References to these keep their old type.
+	removedRefsByType map[string]map[string]struct{}
+	// ignoredRefsByType holds the addresses of ignored resources. References
+	// to these are left as they are.
+	ignoredRefsByType map[string]map[string]struct{}
+	keep              func(path string, ref tfhcl.Reference) bool
+
+	appliedRenames          map[string]string
+	appliedAttrRenames      map[string]transform.AttributeRename
+	appliedComputedMappings map[string]transform.ComputedAttributeMapping
+}
+
+func newReferenceRewriter(rules Rules, removedRefsByType map[string]map[string]struct{}) *referenceRewriter {
+	r := &referenceRewriter{
+		renames:                 rules.Renames,
+		attributeRenames:        make(map[string][]transform.AttributeRename),
+		computedAttrMappings:    rules.ComputedAttributeMappings,
+		removedRefsByType:       removedRefsByType,
+		keep:                    rules.Keep,
+		appliedRenames:          make(map[string]string),
+		appliedAttrRenames:      make(map[string]transform.AttributeRename),          // key: ResourceType.OldAttribute
+		appliedComputedMappings: make(map[string]transform.ComputedAttributeMapping), // key: OldResourceType.OldAttribute
+	}
+	for _, rename := range rules.AttributeRenames {
+		r.attributeRenames[rename.ResourceType] = append(r.attributeRenames[rename.ResourceType], rename)
+	}
+	return r
+}
+
+// rewrite returns the edits that update every matching reference in refs,
+// found in the file at path, and updates refs to match. Computed attribute
+// mappings are applied first (they match the old resource type), then
+// resource type renames, then attribute renames on the resulting type.
+func (r *referenceRewriter) rewrite(path string, refs []tfhcl.Reference) []tfhcl.TextEdit {
+	var edits []tfhcl.TextEdit
+	for i := range refs {
+		ref := &refs[i]
+		if _, ignored := r.ignoredRefsByType[ref.Type][ref.Name]; ignored {
+			continue
+		}
+		if r.keep != nil && r.keep(path, *ref) {
+			continue
+		}
+		resourceType, attribute := ref.Type, ref.Attribute
+
+		for _, mapping := range r.computedAttrMappings {
+			if attribute != "" && resourceType == mapping.OldResourceType && attribute == mapping.OldAttribute {
+				resourceType, attribute = mapping.NewResourceType, mapping.NewAttribute
+				r.appliedComputedMappings[mapping.OldResourceType+"."+mapping.OldAttribute] = mapping
+			}
+		}
+
+		if newType, ok := r.renames[resourceType]; ok && !r.isRemoved(resourceType, ref.Name) {
+			r.appliedRenames[resourceType] = newType
+			resourceType = newType
+		}
+
+		for _, rename := range r.attributeRenames[resourceType] {
+			if attribute != "" && attribute == rename.OldAttribute {
+				attribute = rename.NewAttribute
+				r.appliedAttrRenames[rename.ResourceType+"."+rename.OldAttribute] = rename
+			}
+		}
+
+		if resourceType != ref.Type {
+			edits = append(edits, tfhcl.TextEdit{Range: ref.TypeRange, Text: resourceType})
+			ref.Type = resourceType
+		}
+		if attribute != ref.Attribute {
+			edits = append(edits, tfhcl.TextEdit{Range: ref.AttributeRange, Text: attribute})
+			ref.Attribute = attribute
+		}
+	}
+	return edits
+}
+
+func (r *referenceRewriter) isRemoved(resourceType, name string) bool {
+	_, ok := r.removedRefsByType[resourceType][name]
+	return ok
+}
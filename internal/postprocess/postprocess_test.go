@@ -0,0 +1,442 @@
+package postprocess
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+
+	"github.com/cloudflare/tf-migrate/internal/transform"
+	tfhcl "github.com/cloudflare/tf-migrate/internal/transform/hcl"
+)
+
+// rewriteContent rewrites the references of a main.tf with content.
+func rewriteContent(rewriter *referenceRewriter, content string) (string, error) {
+	file := scanFile("main.tf", content)
+	if file.err != nil {
+		return "", file.err
+	}
+	return file.apply("main.tf", rewriter.rewrite("main.tf", file.refs))
+}
+
+// scanContent scans a single file for scanInvalidAttributeReferences.
+func scanContent(file, content string) []*scannedFile {
+	return []*scannedFile{scanFile(file, content)}
+}
+
+func TestReferenceRewriterSkipsMovedAndRemovedBlocks(t *testing.T) {
+	input := `output "keep_old" {
+  value = cloudflare_access_policy.app_scoped.id
+}
+
+output "rename" {
+  value = cloudflare_access_policy.account_level.id
+}
+
+removed {
+  from = cloudflare_access_policy.app_scoped
+  lifecycle {
+    destroy = false
+  }
+}
+
+moved {
+  from = cloudflare_access_policy.some_other
+  to   = cloudflare_zero_trust_access_policy.some_other
+}
+`
+
+	rewriter := newReferenceRewriter(
+		Rules{Renames: map[string]string{"cloudflare_access_policy": "cloudflare_zero_trust_access_policy"}},
+		map[string]map[string]struct{}{"cloudflare_access_policy": {"app_scoped": {}}},
+	)
+	got, err := rewriteContent(rewriter, input)
+	if err != nil {
+		t.Fatalf("rewrite returned error: %v", err)
+	}
+
+	if !strings.Contains(got, "cloudflare_access_policy.app_scoped.id") {
+		t.Fatalf("expected app_scoped reference to remain old type, got:\n%s", got)
+	}
+
+	if !strings.Contains(got, "cloudflare_zero_trust_access_policy.account_level.id") {
+		t.Fatalf("expected account_level reference to be renamed, got:\n%s", got)
+	}
+
+	if !strings.Contains(got, "from = cloudflare_access_policy.app_scoped") {
+		t.Fatalf("expected removed block from-address to remain unchanged, got:\n%s", got)
+	}
+
+	if !strings.Contains(got, "from = cloudflare_access_policy.some_other") {
+		t.Fatalf("expected moved block from-address to remain unchanged, got:\n%s", got)
+	}
+}
+
+func TestReferenceRewriter(t *testing.T) {
+	rewriter := newReferenceRewriter(Rules{
+		Renames: map[string]string{
+			"cloudflare_record":                        "cloudflare_dns_record",
+			"data.cloudflare_access_identity_provider": "data.cloudflare_zero_trust_access_identity_provider",
+		},
+		ComputedAttributeMappings: []transform.ComputedAttributeMapping{{
+			OldResourceType: "cloudflare_record",
+			OldAttribute:    "hostname",
+			NewResourceType: "cloudflare_dns_record",
+			NewAttribute:    "name",
+		}},
+		AttributeRenames: []transform.AttributeRename{{
+			ResourceType: "data.cloudflare_zones",
+			OldAttribute: "zones",
+			NewAttribute: "result",
+		}},
+	}, nil)
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "plain reference",
+			input:    `a = cloudflare_record.x.hostname`,
+			expected: `a = cloudflare_dns_record.x.name`,
+		},
+		{
+			name:     "literal index key",
+			input:    `a = cloudflare_record.x["a"].hostname`,
+			expected: `a = cloudflare_dns_record.x["a"].name`,
+		},
+		{
+			name:     "count.index",
+			input:    `a = cloudflare_record.x[count.index].hostname`,
+			expected: `a = cloudflare_dns_record.x[count.index].name`,
+		},
+		{
+			name:     "each.key",
+			input:    `a = cloudflare_record.x[each.key].hostname`,
+			expected: `a = cloudflare_dns_record.x[each.key].name`,
+		},
+		{
+			name:     "full splat",
+			input:    `a = cloudflare_record.x[*].hostname`,
+			expected: `a = cloudflare_dns_record.x[*].name`,
+		},
+		{
+			name:     "attribute splat",
+			input:    `a = cloudflare_record.x.*.hostname`,
+			expected: `a = cloudflare_dns_record.x.*.name`,
+		},
+		{
+			name:     "other attribute keeps its name",
+			input:    `a = cloudflare_record.x.id`,
+			expected: `a = cloudflare_dns_record.x.id`,
+		},
+		{
+			name:     "instance reference",
+			input:    `a = [cloudflare_record.x]`,
+			expected: `a = [cloudflare_dns_record.x]`,
+		},
+		{
+			name:     "interpolation is rewritten, template text is not",
+			input:    `a = "${cloudflare_record.x.hostname} cloudflare_record.y.hostname"`,
+			expected: `a = "${cloudflare_dns_record.x.name} cloudflare_record.y.hostname"`,
+		},
+		{
+			name: "heredoc text is not rewritten",
+			input: `a = <<EOT
+cloudflare_record.x.hostname ${cloudflare_record.y.hostname}
+EOT
+`,
+			expected: `a = <<EOT
+cloudflare_record.x.hostname ${cloudflare_dns_record.y.name}
+EOT
+`,
+		},
+		{
+			name:     "comments are not rewritten",
+			input:    "# cloudflare_record.x.hostname\na = 1",
+			expected: "# cloudflare_record.x.hostname\na = 1",
+		},
+		{
+			name:     "datasource rename",
+			input:    `a = data.cloudflare_access_identity_provider.x.id`,
+			expected: `a = data.cloudflare_zero_trust_access_identity_provider.x.id`,
+		},
+		{
+			name:     "datasource attribute rename with index",
+			input:    `a = data.cloudflare_zones.x.zones[0].id`,
+			expected: `a = data.cloudflare_zones.x.result[0].id`,
+		},
+		{
+			name:     "resource and datasource of the same type are distinct",
+			input:    `a = data.cloudflare_record.x.hostname`,
+			expected: `a = data.cloudflare_record.x.hostname`,
+		},
+		{
+			name:     "longer type with renamed prefix is untouched",
+			input:    `a = cloudflare_record_set.x.hostname`,
+			expected: `a = cloudflare_record_set.x.hostname`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := rewriteContent(rewriter, tt.input)
+			if err != nil {
+				t.Fatalf("rewrite returned error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestScanFileRemovedBlocks(t *testing.T) {
+	file := "main.tf"
+	content := `removed {
+  from = cloudflare_access_policy.app_scoped
+  lifecycle { destroy = false }
+}
+
+removed {
+  from = cloudflare_zone_settings_override.legacy
+  lifecycle { destroy = false }
+}
+
+moved {
+  from = cloudflare_access_policy.other
+  to   = cloudflare_zero_trust_access_policy.other
+}
+`
+
+	refs := make(map[string]map[string]struct{})
+	for _, from := range scanFile(file, content).removed {
+		if refs[from.Type] == nil {
+			refs[from.Type] = make(map[string]struct{})
+		}
+		refs[from.Type][from.Name] = struct{}{}
+	}
+
+	if _, ok := refs["cloudflare_access_policy"]["app_scoped"]; !ok {
+		t.Fatalf("expected cloudflare_access_policy.app_scoped in removed refs, got: %#v", refs)
+	}
+
+	if _, ok := refs["cloudflare_zone_settings_override"]["legacy"]; !ok {
+		t.Fatalf("expected cloudflare_zone_settings_override.legacy in removed refs, got: %#v", refs)
+	}
+
+	if _, ok := refs["cloudflare_access_policy"]["other"]; ok {
+		t.Fatalf("did not expect moved block address to be collected as removed ref: %#v", refs)
+	}
+}
+
+func TestScanInvalidAttributeReferences(t *testing.T) {
+	refs := []transform.InvalidAttributeReference{
+		{
+			ResourceType: "cloudflare_zero_trust_tunnel_cloudflared",
+			Attribute:    "tunnel_token",
+			Suggestion:   "tunnel_token is not a valid attribute; use tunnel_secret instead",
+		},
+	}
+
+	t.Run("detects_tunnel_token_reference", func(t *testing.T) {
+		file := "consumers.tf"
+		content := `resource "vault_generic_secret" "token" {
+  data_json = jsonencode({
+    token = cloudflare_zero_trust_tunnel_cloudflared.my_tunnel.tunnel_token
+  })
+}
+`
+		diags := scanInvalidAttributeReferences(hclog.NewNullLogger(), []string{file}, scanContent(file, content), refs)
+		if len(diags) != 1 {
+			t.Fatalf("expected 1 diagnostic, got %d: %v", len(diags), diags)
+		}
+		if diags[0].Severity != hcl.DiagWarning {
+			t.Errorf("expected DiagWarning, got %v", diags[0].Severity)
+		}
+		if !strings.Contains(diags[0].Summary, "tunnel_token") {
+			t.Errorf("expected summary to mention tunnel_token, got: %s", diags[0].Summary)
+		}
+		if !strings.Contains(diags[0].Detail, "tunnel_secret") {
+			t.Errorf("expected detail to mention tunnel_secret, got: %s", diags[0].Detail)
+		}
+	})
+
+	t.Run("no_false_positive_for_valid_attribute", func(t *testing.T) {
+		file := "consumers.tf"
+		// tunnel_secret is valid — must NOT produce a warning
+		content := `resource "vault_generic_secret" "secret" {
+  data_json = jsonencode({
+    secret = cloudflare_zero_trust_tunnel_cloudflared.my_tunnel.tunnel_secret
+  })
+}
+`
+		diags := scanInvalidAttributeReferences(hclog.NewNullLogger(), []string{file}, scanContent(file, content), refs)
+		if len(diags) != 0 {
+			t.Fatalf("expected no diagnostics for valid attribute, got %d: %v", len(diags), diags)
+		}
+	})
+
+	t.Run("multiple_matches_produce_multiple_warnings", func(t *testing.T) {
+		file := "consumers.tf"
+		content := `resource "vault_generic_secret" "a" {
+  data_json = jsonencode({
+    a = cloudflare_zero_trust_tunnel_cloudflared.tunnel_a.tunnel_token
+    b = cloudflare_zero_trust_tunnel_cloudflared.tunnel_b.tunnel_token
+  })
+}
+`
+		diags := scanInvalidAttributeReferences(hclog.NewNullLogger(), []string{file}, scanContent(file, content), refs)
+		if len(diags) != 2 {
+			t.Fatalf("expected 2 diagnostics (one per match), got %d", len(diags))
+		}
+	})
+
+	t.Run("no_match_in_unrelated_resource_type", func(t *testing.T) {
+		file := "consumers.tf"
+		// Different resource type — must not match
+		content := `resource "vault_generic_secret" "other" {
+  data_json = jsonencode({
+    tok = cloudflare_some_other_resource.foo.tunnel_token
+  })
+}
+`
+		diags := scanInvalidAttributeReferences(hclog.NewNullLogger(), []string{file}, scanContent(file, content), refs)
+		if len(diags) != 0 {
+			t.Fatalf("expected no diagnostics for different resource type, got %d", len(diags))
+		}
+	})
+}
+
+// renamingMigrator declares a resource and an attribute rename.
+type renamingMigrator struct{}
+
+func (m *renamingMigrator) CanHandle(resourceType string) bool {
+	return resourceType == "cloudflare_record"
+}
+func (m *renamingMigrator) GetResourceType() string          { return "cloudflare_dns_record" }
+func (m *renamingMigrator) Preprocess(content string) string { return content }
+func (m *renamingMigrator) TransformConfig(*transform.Context, *hclwrite.Block) (*transform.TransformResult, error) {
+	return nil, nil
+}
+func (m *renamingMigrator) GetResourceRename() ([]string, string) {
+	return []string{"cloudflare_record"}, "cloudflare_dns_record"
+}
+func (m *renamingMigrator) GetAttributeRenames() []transform.AttributeRename {
+	return []transform.AttributeRename{{ResourceType: "cloudflare_dns_record", OldAttribute: "hostname", NewAttribute: "name"}}
+}
+
+func TestApply(t *testing.T) {
+	rules := CollectRules(hclog.NewNullLogger(), []transform.ResourceTransformer{&renamingMigrator{}})
+	if rules.Empty() || rules.Count() != 2 {
+		t.Fatalf("expected 2 rules, got %#v", rules)
+	}
+
+	contents := map[string]string{
+		"dns/main.tf":  "resource \"cloudflare_dns_record\" \"www\" {\n  name = \"www\"\n}\n",
+		"outputs.tf":   "output \"host\" {\n  value = cloudflare_record.www.hostname\n}\n",
+		"untouched.tf": "output \"x\" {\n  value = 1\n}\n",
+		"json.tf.json": `{"output": {"host": {"value": "${cloudflare_record.www.hostname}"}}}`,
+	}
+	paths := []string{"dns/main.tf", "json.tf.json", "outputs.tf", "untouched.tf", "missing.tf"}
+	result := Apply(hclog.NewNullLogger(), rules, paths, contents, 0)
+
+	if got, want := contents["outputs.tf"], "output \"host\" {\n  value = cloudflare_dns_record.www.name\n}\n"; got != want {
+		t.Errorf("outputs.tf = %q, want %q", got, want)
+	}
+	if got := contents["json.tf.json"]; !strings.Contains(got, "${cloudflare_dns_record.www.name}") {
+		t.Errorf("json.tf.json = %q, want the reference rewritten", got)
+	}
+	if result.Applied() != 2 || result.AppliedRenames["cloudflare_record"] != "cloudflare_dns_record" {
+		t.Errorf("unexpected applied rules: %#v", result)
+	}
+	if len(result.Diagnostics) != 0 {
+		t.Errorf("expected no diagnostics, got %v", result.Diagnostics)
+	}
+	if _, ok := contents["missing.tf"]; ok {
+		t.Errorf("missing paths must not be added to contents")
+	}
+
+	t.Run("ignored resources", func(t *testing.T) {
+		contents := map[string]string{
+			"list.tf": "# tf-migrate:ignore\nresource \"cloudflare_record\" \"www\" {\n  name = \"www\"\n}\n",
+			"outputs.tf": "output \"host\" {\n  value = cloudflare_record.www.hostname\n}\n\n" +
+				"# tf-migrate:ignore\nresource \"cloudflare_dns_record\" \"other\" {\n  content = cloudflare_record.api.hostname\n}\n",
+			"ignored.tf": "# tf-migrate:ignore-file\noutput \"api\" {\n  value = cloudflare_record.api.hostname\n}\n",
+			"api.tf":     "output \"api\" {\n  value = cloudflare_record.api.hostname\n}\n",
+		}
+		original := make(map[string]string)
+		for path, content := range contents {
+			original[path] = content
+		}
+		Apply(hclog.NewNullLogger(), rules, []string{"api.tf", "ignored.tf", "list.tf", "outputs.tf"}, contents, 0)
+
+		for _, path := range []string{"list.tf", "outputs.tf", "ignored.tf"} {
+			if contents[path] != original[path] {
+				t.Errorf("%s = %q, want it unchanged", path, contents[path])
+			}
+		}
+		if got := contents["api.tf"]; !strings.Contains(got, "cloudflare_dns_record.api.name") {
+			t.Errorf("api.tf = %q, want the reference rewritten", got)
+		}
+	})
+
+	t.Run("kept references", func(t *testing.T) {
+		output := "output \"host\" {\n  value = cloudflare_record.www.hostname\n}\n"
+		contents := map[string]string{"legacy/outputs.tf": output, "outputs.tf": output}
+		rules := rules
+		rules.Keep = func(path string, ref tfhcl.Reference) bool {
+			return strings.HasPrefix(path, "legacy/") && ref.Type == "cloudflare_record"
+		}
+		Apply(hclog.NewNullLogger(), rules, []string{"legacy/outputs.tf", "outputs.tf"}, contents, 0)
+
+		if got := contents["legacy/outputs.tf"]; got != output {
+			t.Errorf("legacy/outputs.tf = %q, want it unchanged", got)
+		}
+		if got := contents["outputs.tf"]; !strings.Contains(got, "cloudflare_dns_record.www.name") {
+			t.Errorf("outputs.tf = %q, want the reference rewritten", got)
+		}
+	})
+}
+
+// BenchmarkApply rewrites references across a synthetic workspace of 2,000
+// files. Run it with
+//
+//	go test -run '^$' -bench Apply ./internal/postprocess
+func BenchmarkApply(b *testing.B) {
+	rules := CollectRules(hclog.NewNullLogger(), []transform.ResourceTransformer{&renamingMigrator{}})
+
+	original := make(map[string]string)
+	var paths []string
+	for i := 0; i < 2000; i++ {
+		path := fmt.Sprintf("module_%02d/file_%03d.tf", i/50, i%50)
+		paths = append(paths, path)
+		original[path] = fmt.Sprintf(`resource "cloudflare_dns_record" "r%[1]d" {
+  zone_id = var.zone_id
+  name    = "host-%[1]d"
+  type    = "A"
+  content = "192.0.2.1"
+}
+
+output "previous_%[1]d" {
+  value = [cloudflare_record.r%[1]d.hostname, cloudflare_record.r%[1]d.id, var.zone_id]
+}
+`, i)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		contents := make(map[string]string, len(original))
+		for path, content := range original {
+			contents[path] = content
+		}
+		b.StartTimer()
+
+		Apply(hclog.NewNullLogger(), rules, paths, contents, 0)
+	}
+}
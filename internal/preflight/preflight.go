@@ -0,0 +1,367 @@
+// Package preflight scans configuration before a migration: it classifies
+// every Cloudflare resource by how it will be migrated, and finds
+// hand-written moved blocks that conflict with the ones tf-migrate generates.
+package preflight
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+
+	"github.com/cloudflare/tf-migrate/internal/transform"
+	tfhcl "github.com/cloudflare/tf-migrate/internal/transform/hcl"
+)
+
+// Class describes how a resource will be handled during migration.
+type Class int
+
+const (
+	// ClassAutoMigrated means the resource has a transformer and will be fully auto-migrated.
+	ClassAutoMigrated Class = iota
+	// ClassRenamed means the resource will be renamed and a moved block generated.
+	ClassRenamed
+	// ClassManualIntervention means the resource has a transformer but requires manual steps.
+	ClassManualIntervention
+	// ClassUnsupported means no transformer is registered for this resource type.
+	ClassUnsupported
+	// ClassIgnored means the resource is annotated with "# tf-migrate:ignore",
+	// or its file with "# tf-migrate:ignore-file", and is left as it is.
+	ClassIgnored
+)
+
+// Resource holds information about a resource found during pre-migration scanning.
+type Resource struct {
+	File         string
+	ResourceType string
+	ResourceName string
+	Class        Class
+	OldType      string // only set for ClassRenamed
+	NewType      string // only set for ClassRenamed
+	Detail       string // human-readable detail for manual intervention / unsupported / ignored
+}
+
+// MovedBlock represents a hand-written moved block found in the user's config.
+type MovedBlock struct {
+	File     string
+	FromType string
+	FromName string
+	ToType   string
+	ToName   string
+}
+
+// Report is the result of a pre-migration scan.
+type Report struct {
+	Resources   []Resource
+	MovedBlocks []MovedBlock
+	Warnings    []string
+}
+
+// Options selects the migrators a scan classifies resources with.
+type Options struct {
+	Providers     transform.MigrationProvider
+	SourceVersion string
+	TargetVersion string
+	// Resources restricts the migrators to these resource types, as with
+	// --resources.
+	Resources []string
+}
+
+// Scan classifies the resources and collects the moved blocks of contents,
+// which maps each path in paths to its content. Paths missing from contents
+// and files that fail to parse are skipped. Resources, moved blocks and
+// warnings name their file by its path in paths.
+func Scan(log hclog.Logger, opts Options, paths []string, contents map[string][]byte) *Report {
+	report := &Report{}
+
+	// Build rename map from all migrators (same logic as the cross-file postprocessing)
+	migrators := opts.Providers.GetAllMigrators(opts.SourceVersion, opts.TargetVersion, opts.Resources...)
+	renames := make(map[string]string) // old type -> new type
+	for _, migrator := range migrators {
+		if renamer, ok := migrator.(transform.ResourceRenamer); ok {
+			oldTypes, newType := renamer.GetResourceRename()
+			for _, oldType := range oldTypes {
+				if oldType != newType {
+					renames[oldType] = newType
+				}
+			}
+		}
+	}
+
+	for _, path := range paths {
+		content, ok := contents[path]
+		if !ok {
+			continue
+		}
+
+		parsed, diags := tfhcl.ParseConfigFile(content, filepath.Base(path))
+		if diags.HasErrors() {
+			log.Warn("Failed to parse file during pre-migration scan", "file", path, "error", diags)
+			continue
+		}
+		var ignored tfhcl.IgnoreAnnotations
+		if !tfhcl.IsJSONConfigFile(filepath.Base(path)) {
+			ignored = tfhcl.FindIgnoreAnnotations(content, filepath.Base(path))
+		}
+
+		for _, block := range parsed.Body().Blocks() {
+			switch block.Type() {
+			case "resource":
+				if len(block.Labels()) < 2 {
+					continue
+				}
+				sr := classifyResource(block, path, renames, opts)
+				if sr != nil && ignored.Ignores(sr.ResourceType, sr.ResourceName) {
+					sr = &Resource{
+						File:         path,
+						ResourceType: sr.ResourceType,
+						ResourceName: sr.ResourceName,
+						Class:        ClassIgnored,
+						Detail:       ignoreDetail(ignored),
+					}
+				}
+				if sr != nil {
+					report.Resources = append(report.Resources, *sr)
+				}
+
+			case "moved":
+				mb := parseMovedBlock(block, path)
+				if mb != nil {
+					report.MovedBlocks = append(report.MovedBlocks, *mb)
+				}
+			}
+		}
+	}
+
+	// Check for conflicting moved blocks
+	report.Warnings = DetectMovedBlockConflicts(report, renames)
+
+	return report
+}
+
+// classifyResource determines how a resource will be handled during migration.
+func classifyResource(block *hclwrite.Block, file string, renames map[string]string, opts Options) *Resource {
+	resourceType := block.Labels()[0]
+	resourceName := block.Labels()[1]
+
+	// Only scan cloudflare resources
+	if !strings.HasPrefix(resourceType, "cloudflare_") {
+		return nil
+	}
+
+	migrator := opts.Providers.GetMigrator(resourceType, opts.SourceVersion, opts.TargetVersion)
+	if migrator == nil {
+		return &Resource{
+			File:         file,
+			ResourceType: resourceType,
+			ResourceName: resourceName,
+			Class:        ClassUnsupported,
+			Detail:       "no transformer registered -- manual migration required",
+		}
+	}
+
+	// cloudflare_access_policy resources with application_id are a special manual
+	// path in v4->v5: they become removed {} blocks and must be rewritten inline
+	// on cloudflare_zero_trust_access_application.policies.
+	if opts.SourceVersion == "v4" && opts.TargetVersion == "v5" &&
+		resourceType == "cloudflare_access_policy" && block.Body().GetAttribute("application_id") != nil {
+		return &Resource{
+			File:         file,
+			ResourceType: resourceType,
+			ResourceName: resourceName,
+			Class:        ClassManualIntervention,
+			Detail:       "application_id detected -- tf-migrate will generate removed {}; migrate policy inline to cloudflare_zero_trust_access_application.policies (do not use moved block)",
+		}
+	}
+
+	// Check if this resource will be renamed
+	if newType, ok := renames[resourceType]; ok {
+		// Conditional renames: some v4 types map to one of two v5 types
+		// depending on block attributes. Override the static rename target
+		// with the per-instance target based on attribute inspection.
+		newType = resolveConditionalRename(resourceType, newType, block)
+
+		return &Resource{
+			File:         file,
+			ResourceType: resourceType,
+			ResourceName: resourceName,
+			Class:        ClassRenamed,
+			OldType:      resourceType,
+			NewType:      newType,
+		}
+	}
+
+	// Resource has a transformer but no rename -- config-only changes
+	return &Resource{
+		File:         file,
+		ResourceType: resourceType,
+		ResourceName: resourceName,
+		Class:        ClassAutoMigrated,
+	}
+}
+
+// ignoreDetail explains why the resources of a file with annotations are
+// ignored.
+func ignoreDetail(annotations tfhcl.IgnoreAnnotations) string {
+	if annotations.File {
+		return "file annotated with # " + tfhcl.IgnoreFileDirective
+	}
+	return "annotated with # " + tfhcl.IgnoreDirective
+}
+
+// resolveConditionalRename overrides the static rename target for resource types
+// where the v5 target depends on per-instance block attributes.
+//
+// Currently handles:
+//   - cloudflare_zero_trust_device_profiles / cloudflare_device_settings_policy:
+//     Routes to cloudflare_zero_trust_device_custom_profile when the block has
+//     match + precedence and is not explicitly default=true.
+//   - cloudflare_zero_trust_local_fallback_domain / cloudflare_fallback_domain:
+//     Routes to cloudflare_zero_trust_device_custom_profile_local_domain_fallback
+//     when the block has a non-empty policy_id.
+func resolveConditionalRename(resourceType, staticNewType string, block *hclwrite.Block) string {
+	body := block.Body()
+
+	switch resourceType {
+	case "cloudflare_zero_trust_device_profiles", "cloudflare_device_settings_policy":
+		// Mirror the routing logic from zero_trust_device_profiles TransformConfig:
+		// custom if !default && match && precedence, else default.
+		isExplicitDefault := false
+		if attr := body.GetAttribute("default"); attr != nil {
+			val, ok := tfhcl.ExtractBoolFromAttribute(attr)
+			if ok {
+				isExplicitDefault = val
+			}
+		}
+		hasMatch := body.GetAttribute("match") != nil
+		hasPrecedence := body.GetAttribute("precedence") != nil
+
+		if !isExplicitDefault && hasMatch && hasPrecedence {
+			return "cloudflare_zero_trust_device_custom_profile"
+		}
+
+	case "cloudflare_zero_trust_local_fallback_domain", "cloudflare_fallback_domain":
+		// Mirror the routing logic from zero_trust_local_fallback_domain TransformConfig:
+		// custom if policy_id is present and non-empty, else default.
+		if attr := body.GetAttribute("policy_id"); attr != nil {
+			val := tfhcl.ExtractStringFromAttribute(attr)
+			if val != "" || tfhcl.IsExpressionAttribute(attr) {
+				return "cloudflare_zero_trust_device_custom_profile_local_domain_fallback"
+			}
+		}
+	}
+
+	return staticNewType
+}
+
+// parseMovedBlock extracts from/to information from a moved block.
+func parseMovedBlock(block *hclwrite.Block, file string) *MovedBlock {
+	body := block.Body()
+
+	fromAttr := body.GetAttribute("from")
+	toAttr := body.GetAttribute("to")
+	if fromAttr == nil || toAttr == nil {
+		return nil
+	}
+
+	fromStr := tfhcl.TraversalString(fromAttr)
+	toStr := tfhcl.TraversalString(toAttr)
+	if fromStr == "" || toStr == "" {
+		return nil
+	}
+
+	fromParts := strings.SplitN(fromStr, ".", 2)
+	toParts := strings.SplitN(toStr, ".", 2)
+
+	// Only care about cloudflare resources
+	if !strings.HasPrefix(fromStr, "cloudflare_") && !strings.HasPrefix(toStr, "cloudflare_") {
+		return nil
+	}
+
+	mb := &MovedBlock{
+		File: file,
+	}
+	if len(fromParts) == 2 {
+		mb.FromType = fromParts[0]
+		mb.FromName = fromParts[1]
+	}
+	if len(toParts) == 2 {
+		mb.ToType = toParts[0]
+		mb.ToName = toParts[1]
+	}
+
+	return mb
+}
+
+// DetectMovedBlockConflicts checks for hand-written moved blocks that may conflict
+// with what tf-migrate would generate.
+func DetectMovedBlockConflicts(report *Report, renames map[string]string) []string {
+	var warnings []string
+
+	manualAddrs := make(map[string]struct{})
+	for _, r := range report.Resources {
+		if r.Class == ClassManualIntervention {
+			manualAddrs[r.ResourceType+"."+r.ResourceName] = struct{}{}
+		}
+	}
+
+	for _, mb := range report.MovedBlocks {
+		addr := mb.FromType + "." + mb.FromName
+
+		if _, isManual := manualAddrs[addr]; isManual {
+			warnings = append(warnings, fmt.Sprintf(
+				"Conflicting moved block in %s: %s → %s.%s\n"+
+					"  This resource requires manual migration (application_id path).\n"+
+					"  Do not use a moved block; keep the generated removed {} block and migrate inline policies manually.",
+				mb.File, addr, mb.ToType, mb.ToName))
+			continue
+		}
+
+		// Check if the from-type is a v4 name that tf-migrate would rename
+		if newType, ok := renames[mb.FromType]; ok {
+			if mb.ToType == newType {
+				// User's moved block matches what tf-migrate would generate -- duplicate
+				warnings = append(warnings, fmt.Sprintf(
+					"Pre-existing moved block in %s: %s → %s.%s\n"+
+						"  tf-migrate will generate this moved block automatically.\n"+
+						"  Consider removing it to avoid duplicates.",
+					mb.File, addr, mb.ToType, mb.ToName))
+			} else {
+				// User's moved block targets a different type than expected
+				warnings = append(warnings, fmt.Sprintf(
+					"Conflicting moved block in %s: %s → %s.%s\n"+
+						"  tf-migrate would rename %s to %s, not %s.\n"+
+						"  Please remove or correct this moved block before proceeding.",
+					mb.File, addr, mb.ToType, mb.ToName,
+					mb.FromType, newType, mb.ToType))
+			}
+		}
+
+		// Check if from-type doesn't exist in v5 provider at all and has no transformer
+		if _, ok := renames[mb.FromType]; !ok {
+			// Not a known rename -- check if it's even a cloudflare type
+			if strings.HasPrefix(mb.FromType, "cloudflare_") {
+				// Check if this type has any migrator registered
+				found := false
+				for _, r := range report.Resources {
+					if r.ResourceType == mb.FromType {
+						found = true
+						break
+					}
+				}
+				if !found {
+					// The moved block references a type that has no resources in config
+					// This could be a stale moved block or a cross-module reference
+					warnings = append(warnings, fmt.Sprintf(
+						"Moved block in %s references %s which has no matching resource in config.\n"+
+							"  Verify this moved block is still needed.",
+						mb.File, addr))
+				}
+			}
+		}
+	}
+
+	return warnings
+}
@@ -0,0 +1,124 @@
+package preflight
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDetectMovedBlockConflicts_DuplicateMovedBlock(t *testing.T) {
+	renames := map[string]string{
+		"cloudflare_access_application": "cloudflare_zero_trust_access_application",
+	}
+
+	report := &Report{
+		MovedBlocks: []MovedBlock{
+			{
+				File:     "moved.tf",
+				FromType: "cloudflare_access_application",
+				FromName: "admin_api",
+				ToType:   "cloudflare_zero_trust_access_application",
+				ToName:   "admin_api",
+			},
+		},
+	}
+
+	warnings := DetectMovedBlockConflicts(report, renames)
+	if len(warnings) != 1 {
+		t.Fatalf("Expected 1 warning, got %d: %v", len(warnings), warnings)
+	}
+	if !strings.Contains(warnings[0], "tf-migrate will generate this moved block automatically") {
+		t.Errorf("Unexpected warning: %s", warnings[0])
+	}
+}
+
+func TestDetectMovedBlockConflicts_ConflictingTarget(t *testing.T) {
+	renames := map[string]string{
+		"cloudflare_access_application": "cloudflare_zero_trust_access_application",
+	}
+
+	report := &Report{
+		MovedBlocks: []MovedBlock{
+			{
+				File:     "moved.tf",
+				FromType: "cloudflare_access_application",
+				FromName: "admin_api",
+				ToType:   "cloudflare_wrong_type",
+				ToName:   "admin_api",
+			},
+		},
+	}
+
+	warnings := DetectMovedBlockConflicts(report, renames)
+	if len(warnings) != 1 {
+		t.Fatalf("Expected 1 warning, got %d: %v", len(warnings), warnings)
+	}
+	if !strings.Contains(warnings[0], "Conflicting moved block") {
+		t.Errorf("Expected conflicting moved block warning, got: %s", warnings[0])
+	}
+}
+
+func TestDetectMovedBlockConflicts_NoConflict(t *testing.T) {
+	renames := map[string]string{
+		"cloudflare_access_application": "cloudflare_zero_trust_access_application",
+	}
+
+	// A moved block for a non-renamed resource (no conflict)
+	report := &Report{
+		MovedBlocks: []MovedBlock{
+			{
+				File:     "moved.tf",
+				FromType: "cloudflare_custom_resource",
+				FromName: "example",
+				ToType:   "cloudflare_custom_resource_v2",
+				ToName:   "example",
+			},
+		},
+		Resources: []Resource{
+			{
+				ResourceType: "cloudflare_custom_resource",
+				ResourceName: "example",
+			},
+		},
+	}
+
+	warnings := DetectMovedBlockConflicts(report, renames)
+	if len(warnings) != 0 {
+		t.Errorf("Expected no warnings, got %d: %v", len(warnings), warnings)
+	}
+}
+
+func TestDetectMovedBlockConflicts_ManualResourceMovedBlock(t *testing.T) {
+	renames := map[string]string{
+		"cloudflare_access_policy": "cloudflare_zero_trust_access_policy",
+	}
+
+	report := &Report{
+		Resources: []Resource{
+			{
+				ResourceType: "cloudflare_access_policy",
+				ResourceName: "app_scoped",
+				Class:        ClassManualIntervention,
+			},
+		},
+		MovedBlocks: []MovedBlock{
+			{
+				File:     "moved.tf",
+				FromType: "cloudflare_access_policy",
+				FromName: "app_scoped",
+				ToType:   "cloudflare_zero_trust_access_policy",
+				ToName:   "app_scoped",
+			},
+		},
+	}
+
+	warnings := DetectMovedBlockConflicts(report, renames)
+	if len(warnings) != 1 {
+		t.Fatalf("Expected 1 warning, got %d: %v", len(warnings), warnings)
+	}
+	if !strings.Contains(warnings[0], "requires manual migration") {
+		t.Fatalf("Expected manual migration warning, got: %s", warnings[0])
+	}
+	if !strings.Contains(warnings[0], "Do not use a moved block") {
+		t.Fatalf("Expected moved-block guidance warning, got: %s", warnings[0])
+	}
+}
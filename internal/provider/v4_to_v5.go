@@ -0,0 +1,189 @@
+package provider
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+
+	"github.com/cloudflare/tf-migrate/internal"
+	"github.com/cloudflare/tf-migrate/internal/transform"
+	tfhcl "github.com/cloudflare/tf-migrate/internal/transform/hcl"
+)
+
+// MigratorKey is the key the provider block migrator is registered under.
+// Provider blocks are looked up with a "provider." prefix, in the same way
+// datasources use "data.".
+const MigratorKey = "provider.cloudflare"
+
+const (
+	defaultAPIHostname = "api.cloudflare.com"
+	defaultAPIBasePath = "/client/v4"
+)
+
+// V4ToV5Migrator handles the migration of the provider "cloudflare" block from v4 to v5.
+// Key transformations:
+// 1. api_hostname + api_base_path → base_url
+// 2. api_user_service_key, account_id → removed (warning: must be replaced by hand)
+// 3. rps, retries, min_backoff, max_backoff, api_client_logging → removed (no v5 equivalent)
+// 4. alias and every other argument are left untouched
+type V4ToV5Migrator struct{}
+
+// NewV4ToV5Migrator creates a new migrator for the provider "cloudflare" block v4 to v5.
+func NewV4ToV5Migrator() transform.ResourceTransformer {
+	migrator := &V4ToV5Migrator{}
+	internal.RegisterMigrator(MigratorKey, "v4", "v5", migrator)
+	return migrator
+}
+
+// GetResourceType returns the key of the block this migrator handles.
+func (m *V4ToV5Migrator) GetResourceType() string {
+	return MigratorKey
+}
+
+// CanHandle determines if this migrator can handle the given block type.
+func (m *V4ToV5Migrator) CanHandle(resourceType string) bool {
+	return resourceType == MigratorKey
+}
+
+// Preprocess handles string-level transformations before HCL parsing.
+// No preprocessing needed for the provider block.
+func (m *V4ToV5Migrator) Preprocess(content string) string {
+	return content
+}
+
+// removedArgument is a v4 provider argument that has no v5 equivalent.
+type removedArgument struct {
+	name     string
+	severity hcl.DiagnosticSeverity
+	detail   string
+}
+
+var removedArguments = []removedArgument{
+	{
+		name:     "account_id",
+		severity: hcl.DiagWarning,
+		detail: "The v5 provider has no provider-level account_id. Resources that relied on it as a default " +
+			"must now set account_id themselves, otherwise terraform plan will fail with a missing required argument.",
+	},
+	{
+		name:     "api_user_service_key",
+		severity: hcl.DiagWarning,
+		detail: "User service keys are no longer supported by the v5 provider. Origin CA certificates can be " +
+			"managed with an api_token that has the SSL and Certificates permission.",
+	},
+	{
+		name:     "rps",
+		severity: transform.DiagInfo,
+		detail:   "The v5 provider rate limits requests itself; rps can no longer be configured.",
+	},
+	{
+		name:     "retries",
+		severity: transform.DiagInfo,
+		detail:   "The v5 provider retries failed requests itself; retries can no longer be configured.",
+	},
+	{
+		name:     "min_backoff",
+		severity: transform.DiagInfo,
+		detail:   "The v5 provider retries failed requests itself; min_backoff can no longer be configured.",
+	},
+	{
+		name:     "max_backoff",
+		severity: transform.DiagInfo,
+		detail:   "The v5 provider retries failed requests itself; max_backoff can no longer be configured.",
+	},
+	{
+		name:     "api_client_logging",
+		severity: transform.DiagInfo,
+		detail:   "Set the TF_LOG=DEBUG environment variable to log API requests and responses with the v5 provider.",
+	},
+}
+
+// TransformConfig rewrites a provider "cloudflare" block in place.
+func (m *V4ToV5Migrator) TransformConfig(ctx *transform.Context, block *hclwrite.Block) (*transform.TransformResult, error) {
+	body := block.Body()
+	name := providerName(block)
+
+	m.convertBaseURL(body)
+
+	for _, arg := range removedArguments {
+		if body.GetAttribute(arg.name) == nil {
+			continue
+		}
+		body.RemoveAttribute(arg.name)
+		ctx.Diagnostics = append(ctx.Diagnostics, &hcl.Diagnostic{
+			Severity: arg.severity,
+			Summary:  fmt.Sprintf("Provider argument removed: %s in %s", arg.name, name),
+			Detail:   fmt.Sprintf("%s (%s)\n\n%s", name, ctx.Filename, arg.detail),
+		})
+	}
+
+	return &transform.TransformResult{
+		Blocks:         []*hclwrite.Block{block},
+		RemoveOriginal: false,
+	}, nil
+}
+
+// convertBaseURL replaces api_hostname and api_base_path with the single
+// base_url argument used by v5. An argument that is not set falls back to the
+// v4 default, so that setting only one of them keeps the same endpoint.
+func (m *V4ToV5Migrator) convertBaseURL(body *hclwrite.Body) {
+	hostAttr := body.GetAttribute("api_hostname")
+	pathAttr := body.GetAttribute("api_base_path")
+	if hostAttr == nil && pathAttr == nil {
+		return
+	}
+
+	var tokens hclwrite.Tokens
+	tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenOQuote, Bytes: []byte(`"`)})
+	tokens = append(tokens, templatePart("https://"))
+	tokens = append(tokens, templateTokens(hostAttr, defaultAPIHostname)...)
+	tokens = append(tokens, templateTokens(pathAttr, defaultAPIBasePath)...)
+	tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenCQuote, Bytes: []byte(`"`)})
+
+	body.RemoveAttribute("api_hostname")
+	body.RemoveAttribute("api_base_path")
+	body.SetAttributeRaw("base_url", tokens)
+}
+
+// templateTokens returns the tokens that splice attr into a quoted template:
+// the literal text for a plain string, an interpolation for any other
+// expression, and fallback when attr is not set.
+func templateTokens(attr *hclwrite.Attribute, fallback string) hclwrite.Tokens {
+	if attr == nil {
+		return hclwrite.Tokens{templatePart(fallback)}
+	}
+
+	exprTokens := attr.Expr().BuildTokens(nil)
+	if isPlainString(exprTokens) {
+		return hclwrite.Tokens{templatePart(string(exprTokens[1].Bytes))}
+	}
+
+	tokens := hclwrite.Tokens{{Type: hclsyntax.TokenTemplateInterp, Bytes: []byte("${")}}
+	tokens = append(tokens, exprTokens...)
+	return append(tokens, &hclwrite.Token{Type: hclsyntax.TokenTemplateSeqEnd, Bytes: []byte("}")})
+}
+
+// isPlainString reports whether tokens are a quoted string without interpolation.
+func isPlainString(tokens hclwrite.Tokens) bool {
+	if len(tokens) != 3 {
+		return false
+	}
+	return tokens[0].Type == hclsyntax.TokenOQuote &&
+		tokens[1].Type == hclsyntax.TokenQuotedLit &&
+		tokens[2].Type == hclsyntax.TokenCQuote
+}
+
+func templatePart(text string) *hclwrite.Token {
+	return &hclwrite.Token{Type: hclsyntax.TokenQuotedLit, Bytes: []byte(text)}
+}
+
+// providerName returns a readable name for the block, including its alias.
+func providerName(block *hclwrite.Block) string {
+	name := `provider "cloudflare"`
+	if alias := tfhcl.ExtractStringFromAttribute(block.Body().GetAttribute("alias")); alias != "" {
+		name += fmt.Sprintf(" (alias %q)", alias)
+	}
+	return name
+}
@@ -0,0 +1,125 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudflare/tf-migrate/internal/transform"
+)
+
+func TestV4ToV5Transformation(t *testing.T) {
+	migrator := NewV4ToV5Migrator()
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		warnings []string
+	}{
+		{
+			name: "no changes needed",
+			input: `provider "cloudflare" {
+  api_token = var.cloudflare_api_token
+}
+`,
+			expected: `provider "cloudflare" {
+  api_token = var.cloudflare_api_token
+}
+`,
+		},
+		{
+			name: "removed tuning arguments",
+			input: `provider "cloudflare" {
+  api_token          = var.cloudflare_api_token
+  rps                = 4
+  retries            = 3
+  min_backoff        = 1
+  max_backoff        = 30
+  api_client_logging = true
+}
+`,
+			expected: `provider "cloudflare" {
+  api_token = var.cloudflare_api_token
+}
+`,
+		},
+		{
+			name: "account_id and api_user_service_key warn",
+			input: `provider "cloudflare" {
+  alias                = "ca"
+  account_id           = "f037e56e89293a057740de681ac9abbe"
+  api_user_service_key = var.service_key
+}
+`,
+			expected: `provider "cloudflare" {
+  alias = "ca"
+}
+`,
+			warnings: []string{
+				`Provider argument removed: account_id in provider "cloudflare" (alias "ca")`,
+				`Provider argument removed: api_user_service_key in provider "cloudflare" (alias "ca")`,
+			},
+		},
+		{
+			name: "api_hostname and api_base_path literals",
+			input: `provider "cloudflare" {
+  api_hostname  = "api.staging.cloudflare.com"
+  api_base_path = "/client/v4"
+}
+`,
+			expected: `provider "cloudflare" {
+  base_url = "https://api.staging.cloudflare.com/client/v4"
+}
+`,
+		},
+		{
+			name: "api_hostname expression uses default base path",
+			input: `provider "cloudflare" {
+  api_hostname = var.api_hostname
+}
+`,
+			expected: `provider "cloudflare" {
+  base_url = "https://${var.api_hostname}/client/v4"
+}
+`,
+		},
+		{
+			name: "api_base_path only uses default hostname",
+			input: `provider "cloudflare" {
+  api_base_path = "/client/v4beta"
+}
+`,
+			expected: `provider "cloudflare" {
+  base_url = "https://api.cloudflare.com/client/v4beta"
+}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, diags := hclwrite.ParseConfig([]byte(tt.input), "main.tf", hcl.InitialPos)
+			require.False(t, diags.HasErrors(), diags.Error())
+
+			ctx := &transform.Context{Filename: "main.tf"}
+			block := file.Body().Blocks()[0]
+			result, err := migrator.TransformConfig(ctx, block)
+			require.NoError(t, err)
+			assert.False(t, result.RemoveOriginal)
+
+			assert.Equal(t, tt.expected, string(hclwrite.Format(file.Bytes())))
+
+			var warnings []string
+			for _, diag := range ctx.Diagnostics {
+				if diag.Severity == hcl.DiagWarning {
+					warnings = append(warnings, diag.Summary)
+				}
+			}
+			assert.Equal(t, tt.warnings, warnings)
+		})
+	}
+}
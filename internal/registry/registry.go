@@ -7,6 +7,7 @@ import (
 	rulesetsdata "github.com/cloudflare/tf-migrate/internal/datasources/rulesets"
 	zonedata "github.com/cloudflare/tf-migrate/internal/datasources/zone"
 	zonesdata "github.com/cloudflare/tf-migrate/internal/datasources/zones"
+	"github.com/cloudflare/tf-migrate/internal/provider"
 	"github.com/cloudflare/tf-migrate/internal/resources/access_rule"
 	"github.com/cloudflare/tf-migrate/internal/resources/account"
 	"github.com/cloudflare/tf-migrate/internal/resources/account_member"
@@ -104,6 +105,9 @@ func RegisterAllMigrations() {
 	zonedata.NewV4ToV5Migrator()
 	zonesdata.NewV4ToV5Migrator()
 
+	// Provider configuration
+	provider.NewV4ToV5Migrator()
+
 	// Resources
 	access_rule.NewV4ToV5Migrator()
 	account.NewV4ToV5Migrator()
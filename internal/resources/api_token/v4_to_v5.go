@@ -59,15 +59,16 @@ func (m *V4ToV5Migrator) TransformConfig(ctx *transform.Context, block *hclwrite
 	}, nil
 }
 
+// transformPolicyBlocks converts policy blocks to the policies list attribute.
+// Dynamic policy blocks become for expressions, merged with static policies via concat().
 func (m *V4ToV5Migrator) transformPolicyBlocks(body *hclwrite.Body) {
-	policyBlocks := tfhcl.FindBlocksByType(body, "policy")
+	policyBlocks := tfhcl.FindBlocksWithDynamic(body, "policy")
 	if len(policyBlocks) == 0 {
 		return
 	}
 
-	var policyObjects []hclwrite.Tokens
 	for _, policyBlock := range policyBlocks {
-		policyBody := policyBlock.Body()
+		policyBody := tfhcl.ContentBlock(policyBlock).Body()
 
 		// Ensure effect attribute exists (required in v5, optional in v4)
 		if policyBody.GetAttribute("effect") == nil {
@@ -82,15 +83,13 @@ func (m *V4ToV5Migrator) transformPolicyBlocks(body *hclwrite.Body) {
 		// v4: resources = { "com.cloudflare.api.account.*" = "*" }
 		// v5: resources = jsonencode({ "com.cloudflare.api.account.*" = "*" })
 		m.transformResources(policyBody)
-
-		objTokens := tfhcl.BuildObjectFromBlock(policyBlock)
-		policyObjects = append(policyObjects, objTokens)
 	}
 
-	listTokens := hclwrite.TokensForTuple(policyObjects)
-	body.SetAttributeRaw("policies", listTokens)
+	body.SetAttributeRaw("policies", tfhcl.BuildListFromBlocks(policyBlocks, tfhcl.BuildObjectFromBlock))
 
-	tfhcl.RemoveBlocksByType(body, "policy")
+	for _, policyBlock := range policyBlocks {
+		body.RemoveBlock(policyBlock)
+	}
 }
 
 // transformPermissionGroups converts permission_groups from list of strings to list of objects
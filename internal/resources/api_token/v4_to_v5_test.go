@@ -396,6 +396,56 @@ resource "cloudflare_api_token" "empty_perms" {
       "com.cloudflare.api.account.*" = "*"
     })
   }]
+}`,
+			},
+			{
+				Name: "api token with dynamic policy blocks",
+				Input: `
+resource "cloudflare_api_token" "dynamic_policy" {
+  name = "dynamic-policy-token"
+
+  policy {
+    permission_groups = ["c8fed203ed3043cba015a93ad1616f1f"]
+    resources = {
+      "com.cloudflare.api.account.*" = "*"
+    }
+  }
+
+  dynamic "policy" {
+    for_each = var.zone_ids
+    iterator = zone
+    content {
+      permission_groups = ["82e64a83756745bbbb1c9c2701bf816b"]
+      resources = {
+        "com.cloudflare.api.account.zone.${zone.value}" = "*"
+      }
+    }
+  }
+}`,
+				Expected: `
+resource "cloudflare_api_token" "dynamic_policy" {
+  name = "dynamic-policy-token"
+
+  policies = concat(
+    [{
+      resources = jsonencode({
+        "com.cloudflare.api.account.*" = "*"
+      })
+      effect = "allow"
+      permission_groups = [{
+        id = "c8fed203ed3043cba015a93ad1616f1f"
+      }]
+    }],
+    [for value in var.zone_ids : {
+      resources = jsonencode({
+        "com.cloudflare.api.account.zone.${value}" = "*"
+      })
+      effect = "allow"
+      permission_groups = [{
+        id = "82e64a83756745bbbb1c9c2701bf816b"
+      }]
+    }],
+  )
 }`,
 			},
 			{
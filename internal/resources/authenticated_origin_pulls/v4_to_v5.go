@@ -68,6 +68,14 @@ func (m *V4ToV5Migrator) GetResourceRename() ([]string, string) {
 	return []string{"cloudflare_authenticated_origin_pulls"}, "cloudflare_authenticated_origin_pulls_settings"
 }
 
+// IsAlreadyMigrated implements the AlreadyMigratedDetector interface.
+// A per-hostname resource that already uses the nested v5 config attribute
+// must not be treated as a v4 global/per-zone resource and renamed.
+func (m *V4ToV5Migrator) IsAlreadyMigrated(block *hclwrite.Block) bool {
+	body := block.Body()
+	return body.GetAttribute("config") != nil && body.GetAttribute("hostname") == nil
+}
+
 // TransformConfig handles configuration file transformations.
 // Routes resources based on hostname presence:
 // - WITHOUT hostname → cloudflare_authenticated_origin_pulls_settings (Global/Per-Zone AOP)
@@ -78,8 +86,28 @@ func (m *V4ToV5Migrator) TransformConfig(ctx *transform.Context, block *hclwrite
 	// Get the resource name before any modifications
 	resourceName := block.Labels()[1]
 
-	// Check if hostname attribute exists
-	hasHostname := body.GetAttribute("hostname") != nil
+	// Check if hostname attribute exists. A hostname that evaluates to null
+	// (e.g. a variable defaulting to null) is the same as no hostname.
+	hostnameAttr := body.GetAttribute("hostname")
+	hasHostname := hostnameAttr != nil
+	if value, literal, ok := tfhcl.EvaluateAttribute(hostnameAttr, ctx.EvalContext); ok && value.IsNull() {
+		if !literal {
+			// The value came from variable defaults or .tfvars, which the
+			// plan may override, and it decides the resource type.
+			expr := strings.TrimSpace(string(hostnameAttr.Expr().BuildTokens(nil).Bytes()))
+			ctx.Diagnostics = append(ctx.Diagnostics, &hcl.Diagnostic{
+				Severity: hcl.DiagWarning,
+				Summary:  fmt.Sprintf("Resolved hostname to null: cloudflare_authenticated_origin_pulls.%s", resourceName),
+				Detail: fmt.Sprintf(
+					"In %s, hostname = %s resolved to null from variable defaults, locals and .tfvars files, so the resource\n"+
+						"was migrated to cloudflare_authenticated_origin_pulls_settings and hostname was removed.\n"+
+						"If the value used when you plan is not null, migrate it as a per-hostname cloudflare_authenticated_origin_pulls instead.",
+					ctx.Filename, expr),
+			})
+		}
+		body.RemoveAttribute("hostname")
+		hasHostname = false
+	}
 
 	if hasHostname {
 		// Per-Hostname AOP: Keep as cloudflare_authenticated_origin_pulls
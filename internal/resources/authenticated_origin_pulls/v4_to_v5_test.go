@@ -3,7 +3,12 @@ package authenticated_origin_pulls
 import (
 	"testing"
 
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+
 	"github.com/cloudflare/tf-migrate/internal/testhelpers"
+	"github.com/cloudflare/tf-migrate/internal/transform"
+	tfhcl "github.com/cloudflare/tf-migrate/internal/transform/hcl"
 )
 
 func TestConfigTransformation(t *testing.T) {
@@ -175,6 +180,28 @@ moved {
   to   = cloudflare_authenticated_origin_pulls_settings.example
 }`,
 		},
+		{
+			Name: "hostname from a variable that defaults to null is zone-wide AOP",
+			Input: `
+resource "cloudflare_authenticated_origin_pulls" "example" {
+  zone_id  = "0da42c8d2132a9ddaf714f9e7c920711"
+  hostname = var.hostname
+  enabled  = true
+}`,
+			Expected: `
+resource "cloudflare_authenticated_origin_pulls_settings" "example" {
+  zone_id = "0da42c8d2132a9ddaf714f9e7c920711"
+  enabled = true
+}
+moved {
+  from = cloudflare_authenticated_origin_pulls.example
+  to   = cloudflare_authenticated_origin_pulls_settings.example
+}`,
+			EvalContext: tfhcl.BuildEvalContext([]tfhcl.SourceFile{{
+				Name:    "variables.tf",
+				Content: []byte("variable \"hostname\" {\n  default = null\n}\n"),
+			}}, nil),
+		},
 	}
 
 	testhelpers.RunConfigTransformTests(t, testCases, migrator)
@@ -191,3 +218,82 @@ func TestCanHandle(t *testing.T) {
 		t.Error("Expected migrator to not handle cloudflare_some_other_resource")
 	}
 }
+
+func TestIsAlreadyMigrated(t *testing.T) {
+	migrator := NewV4ToV5Migrator().(transform.AlreadyMigratedDetector)
+
+	tests := []struct {
+		name     string
+		input    string
+		expected bool
+	}{
+		{
+			name: "v4 per-hostname",
+			input: `resource "cloudflare_authenticated_origin_pulls" "a" {
+  zone_id  = "abc"
+  hostname = "example.com"
+  enabled  = true
+}`,
+			expected: false,
+		},
+		{
+			name: "v4 per-zone",
+			input: `resource "cloudflare_authenticated_origin_pulls" "a" {
+  zone_id = "abc"
+  enabled = true
+}`,
+			expected: false,
+		},
+		{
+			name: "v5 per-hostname with config",
+			input: `resource "cloudflare_authenticated_origin_pulls" "a" {
+  zone_id = "abc"
+  config  = [{ hostname = "example.com", enabled = true }]
+}`,
+			expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, diags := hclwrite.ParseConfig([]byte(tt.input), "test.tf", hcl.InitialPos)
+			if diags.HasErrors() {
+				t.Fatalf("Failed to parse input: %v", diags)
+			}
+			if got := migrator.IsAlreadyMigrated(file.Body().Blocks()[0]); got != tt.expected {
+				t.Errorf("IsAlreadyMigrated() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNullHostnameFromVariableWarns(t *testing.T) {
+	migrator := NewV4ToV5Migrator()
+	file, diags := hclwrite.ParseConfig([]byte(`resource "cloudflare_authenticated_origin_pulls" "example" {
+  zone_id  = "0da42c8d2132a9ddaf714f9e7c920711"
+  hostname = var.hostname
+  enabled  = true
+}`), "test.tf", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatalf("Failed to parse input: %v", diags)
+	}
+
+	ctx := &transform.Context{
+		Filename: "test.tf",
+		EvalContext: tfhcl.BuildEvalContext([]tfhcl.SourceFile{{
+			Name:    "variables.tf",
+			Content: []byte("variable \"hostname\" {\n  default = null\n}\n"),
+		}}, nil),
+	}
+	if _, err := migrator.TransformConfig(ctx, file.Body().Blocks()[0]); err != nil {
+		t.Fatalf("TransformConfig() error = %v", err)
+	}
+
+	if len(ctx.Diagnostics) != 1 {
+		t.Fatalf("expected one diagnostic, got %v", ctx.Diagnostics)
+	}
+	diag := ctx.Diagnostics[0]
+	if diag.Severity != hcl.DiagWarning || diag.Summary != "Resolved hostname to null: cloudflare_authenticated_origin_pulls.example" {
+		t.Errorf("unexpected diagnostic: %v", diag)
+	}
+}
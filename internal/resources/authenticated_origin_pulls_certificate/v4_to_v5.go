@@ -54,6 +54,27 @@ func (m *V4ToV5Migrator) IsPerHostname(resourceName string) bool {
 	return m.perHostnameNames[resourceName]
 }
 
+// ScanConfig implements the ConfigScanner interface, recording per-hostname
+// certificates up front so that the authenticated_origin_pulls migrator can
+// update cert_id references to them whichever file they are in.
+func (m *V4ToV5Migrator) ScanConfig(block *hclwrite.Block) {
+	typeAttr := block.Body().GetAttribute("type")
+	if typeAttr == nil || tfhcl.ExtractStringFromAttribute(typeAttr) != "per-hostname" {
+		return
+	}
+	m.mu.Lock()
+	m.perHostnameNames[tfhcl.GetResourceName(block)] = true
+	m.mu.Unlock()
+}
+
+// Reset implements the ConfigScanner interface, forgetting the certificates
+// of an earlier migration.
+func (m *V4ToV5Migrator) Reset() {
+	m.mu.Lock()
+	m.perHostnameNames = make(map[string]bool)
+	m.mu.Unlock()
+}
+
 func (m *V4ToV5Migrator) TransformConfig(ctx *transform.Context, block *hclwrite.Block) (*transform.TransformResult, error) {
 	body := block.Body()
 	resourceName := tfhcl.GetResourceName(block)
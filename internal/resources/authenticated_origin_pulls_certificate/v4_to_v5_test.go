@@ -3,6 +3,11 @@ package authenticated_origin_pulls_certificate
 import (
 	"testing"
 
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
 	"github.com/cloudflare/tf-migrate/internal/testhelpers"
 )
 
@@ -140,3 +145,25 @@ moved {
 
 	testhelpers.RunConfigTransformTests(t, tests, migrator)
 }
+
+func TestScanConfig(t *testing.T) {
+	migrator := NewV4ToV5Migrator().(*V4ToV5Migrator)
+
+	file, diags := hclwrite.ParseConfig([]byte(`
+resource "cloudflare_authenticated_origin_pulls_certificate" "host" {
+  zone_id = "abc123"
+  type    = "per-hostname"
+}
+
+resource "cloudflare_authenticated_origin_pulls_certificate" "zone" {
+  zone_id = "abc123"
+  type    = "per-zone"
+}`), "certs.tf", hcl.InitialPos)
+	require.False(t, diags.HasErrors())
+	for _, block := range file.Body().Blocks() {
+		migrator.ScanConfig(block)
+	}
+
+	assert.True(t, migrator.IsPerHostname("host"))
+	assert.False(t, migrator.IsPerHostname("zone"))
+}
@@ -1,7 +1,12 @@
 package byo_ip_prefix
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/tidwall/gjson"
+	"github.com/zclconf/go-cty/cty"
 
 	"github.com/cloudflare/tf-migrate/internal"
 	"github.com/cloudflare/tf-migrate/internal/transform"
@@ -31,17 +36,54 @@ func (m *V4ToV5Migrator) Preprocess(content string) string {
 	return content
 }
 
+// IsAlreadyMigrated implements the AlreadyMigratedDetector interface.
+// The v5 resource requires asn and cidr, which v4 did not have.
+func (m *V4ToV5Migrator) IsAlreadyMigrated(block *hclwrite.Block) bool {
+	body := block.Body()
+	return body.GetAttribute("asn") != nil && body.GetAttribute("cidr") != nil
+}
+
 func (m *V4ToV5Migrator) TransformConfig(ctx *transform.Context, block *hclwrite.Block) (*transform.TransformResult, error) {
 	body := block.Body()
+	resourceName := tfhcl.GetResourceName(block)
 
 	// Remove v4-only fields that don't exist in v5
 	// prefix_id - becomes 'id' in v5 (computed, not in config)
 	// advertisement - replaced by 'advertised' in v5 (computed)
 	tfhcl.RemoveAttributes(body, "prefix_id", "advertisement")
 
+	// Fill asn and cidr from the state snapshot when it records them (e.g. a
+	// snapshot refreshed with a provider version that reads them).
+	var missing []string
+	if body.GetAttribute("asn") == nil {
+		if asn, ok := ctx.StateAttribute("cloudflare_byo_ip_prefix", resourceName, "asn"); ok && asn.Type == gjson.Number {
+			body.SetAttributeValue("asn", cty.NumberIntVal(asn.Int()))
+		} else {
+			missing = append(missing, "'asn'")
+		}
+	}
+	if body.GetAttribute("cidr") == nil {
+		if cidr, ok := ctx.StateAttribute("cloudflare_byo_ip_prefix", resourceName, "cidr"); ok && cidr.Type == gjson.String {
+			body.SetAttributeValue("cidr", cty.StringVal(cidr.String()))
+		} else {
+			missing = append(missing, "'cidr'")
+		}
+	}
+	if len(missing) == 0 {
+		return &transform.TransformResult{
+			Blocks:         []*hclwrite.Block{block},
+			RemoveOriginal: false,
+		}, nil
+	}
+
 	// Add warning comment for required v5 fields
 	// User must manually add asn and cidr after migration
-	warningMsg := "This resource requires manual intervention to add v5 required fields 'asn' and 'cidr'. Find values in Cloudflare Dashboard → Manage Account → IP Addresses → IP Prefixes. See migration documentation for details."
+	where := "Find values in Cloudflare Dashboard → Manage Account → IP Addresses → IP Prefixes."
+	if prefixID, ok := ctx.StateAttribute("cloudflare_byo_ip_prefix", resourceName, "prefix_id"); ok && prefixID.String() != "" {
+		where = fmt.Sprintf("Find values for prefix %s in Cloudflare Dashboard → Manage Account → IP Addresses → IP Prefixes.", prefixID.String())
+	}
+	warningMsg := fmt.Sprintf("This resource requires manual intervention to add v5 required fields %s. %s See migration documentation for details.",
+		strings.Join(missing, " and "), where)
 	tfhcl.AppendWarningComment(body, warningMsg)
 
 	return &transform.TransformResult{
@@ -49,4 +91,3 @@ func (m *V4ToV5Migrator) TransformConfig(ctx *transform.Context, block *hclwrite
 		RemoveOriginal: false,
 	}, nil
 }
-
@@ -3,7 +3,10 @@ package byo_ip_prefix
 import (
 	"testing"
 
+	"github.com/stretchr/testify/require"
+
 	"github.com/cloudflare/tf-migrate/internal/testhelpers"
+	"github.com/cloudflare/tf-migrate/internal/transform"
 )
 
 func TestV4ToV5Transformation(t *testing.T) {
@@ -81,4 +84,73 @@ resource "cloudflare_byo_ip_prefix" "second" {
 		testhelpers.RunConfigTransformTests(t, tests, migrator)
 	})
 
+	t.Run("ConfigTransformationWithState", func(t *testing.T) {
+		state, err := transform.ParseStateSnapshot([]byte(`{
+  "format_version": "1.0",
+  "values": {
+    "root_module": {
+      "resources": [
+        {
+          "address": "cloudflare_byo_ip_prefix.example",
+          "mode": "managed",
+          "type": "cloudflare_byo_ip_prefix",
+          "name": "example",
+          "values": {"prefix_id": "prefix-abc123", "asn": 13335, "cidr": "192.0.2.0/24"}
+        },
+        {
+          "address": "cloudflare_byo_ip_prefix.counted[0]",
+          "mode": "managed",
+          "type": "cloudflare_byo_ip_prefix",
+          "name": "counted",
+          "index": 0,
+          "values": {"prefix_id": "prefix-0", "asn": 13335, "cidr": "192.0.2.0/24"}
+        },
+        {
+          "address": "cloudflare_byo_ip_prefix.counted[1]",
+          "mode": "managed",
+          "type": "cloudflare_byo_ip_prefix",
+          "name": "counted",
+          "index": 1,
+          "values": {"prefix_id": "prefix-1", "asn": 13335, "cidr": "198.51.100.0/24"}
+        }
+      ]
+    }
+  }
+}`))
+		require.NoError(t, err)
+
+		tests := []testhelpers.ConfigTestCase{
+			{
+				Name: "asn and cidr are filled from state",
+				Input: `
+resource "cloudflare_byo_ip_prefix" "example" {
+  account_id = var.account_id
+  prefix_id  = "prefix-abc123"
+}`,
+				Expected: `resource "cloudflare_byo_ip_prefix" "example" {
+  account_id = var.account_id
+  asn        = 13335
+  cidr       = "192.0.2.0/24"
+}`,
+				State: state,
+			},
+			{
+				Name: "values that differ between instances are not filled",
+				Input: `
+resource "cloudflare_byo_ip_prefix" "counted" {
+  count      = 2
+  account_id = var.account_id
+  prefix_id  = "prefix-${count.index}"
+}`,
+				Expected: `resource "cloudflare_byo_ip_prefix" "counted" {
+  count      = 2
+  account_id = var.account_id
+  asn        = 13335
+}`,
+				State: state,
+			},
+		}
+
+		testhelpers.RunConfigTransformTests(t, tests, migrator)
+	})
 }
@@ -3,6 +3,7 @@ package dns_record
 import (
 	"strings"
 
+	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/hashicorp/hcl/v2/hclwrite"
 	"github.com/zclconf/go-cty/cty"
@@ -78,6 +79,16 @@ func (m *V4ToV5Migrator) TransformConfig(ctx *transform.Context, block *hclwrite
 	if typeAttr != nil {
 		// Extract the record type value
 		recordType = tfhcl.ExtractStringFromAttribute(typeAttr)
+		// A type set from a variable or expression is read from the state
+		// snapshot, or evaluated from variables and locals, so the record is
+		// migrated the same way as a literal type.
+		if !isStaticString(typeAttr) {
+			if stateType, ok := ctx.StateAttribute(originalResourceType, resourceName, "type"); ok {
+				recordType = stateType.String()
+			} else if value, ok := ctx.EvaluateString(typeAttr); ok {
+				recordType = value
+			}
+		}
 	}
 
 	// Complex record types that use the data field instead of content/value
@@ -217,3 +228,14 @@ func (m *V4ToV5Migrator) processDataAttribute(block *hclwrite.Block, recordType
 		block.Body().SetAttributeRaw("data", newTokens)
 	}
 }
+
+// isStaticString reports whether attr is set to a string literal, as opposed
+// to a variable or another expression.
+func isStaticString(attr *hclwrite.Attribute) bool {
+	expr, diags := hclsyntax.ParseExpression(attr.Expr().BuildTokens(nil).Bytes(), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return false
+	}
+	value, diags := expr.Value(nil)
+	return !diags.HasErrors() && value.Type() == cty.String
+}
@@ -3,7 +3,11 @@ package dns_record
 import (
 	"testing"
 
+	"github.com/stretchr/testify/require"
+
 	"github.com/cloudflare/tf-migrate/internal/testhelpers"
+	"github.com/cloudflare/tf-migrate/internal/transform"
+	tfhcl "github.com/cloudflare/tf-migrate/internal/transform/hcl"
 )
 
 var migrator = NewV4ToV5Migrator()
@@ -564,4 +568,86 @@ moved {
 
 		testhelpers.RunConfigTransformTests(t, tests, migrator)
 	})
+
+	t.Run("ConfigTransformationWithState", func(t *testing.T) {
+		state, err := transform.ParseStateSnapshot([]byte(`{
+  "version": 4,
+  "resources": [
+    {
+      "module": "module.dns",
+      "mode": "managed",
+      "type": "cloudflare_record",
+      "name": "alias",
+      "instances": [{"attributes": {"type": "CNAME", "name": "WWW.example.com"}}]
+    }
+  ]
+}`))
+		require.NoError(t, err)
+
+		tests := []testhelpers.ConfigTestCase{
+			{
+				Name: "type from a variable is read from state",
+				Input: `
+resource "cloudflare_record" "alias" {
+  zone_id = var.zone_id
+  name    = "WWW.example.com"
+  type    = var.record_type
+  value   = "example.com"
+}`,
+				Expected: `resource "cloudflare_dns_record" "alias" {
+  zone_id = var.zone_id
+  name    = "www.example.com"
+  type    = var.record_type
+  content = "example.com"
+  ttl     = 1
+}
+
+moved {
+  from = cloudflare_record.alias
+  to   = cloudflare_dns_record.alias
+}`,
+				State: state,
+			},
+			{
+				Name: "type from a local is evaluated when not in state",
+				Input: `
+resource "cloudflare_record" "srv" {
+  zone_id = var.zone_id
+  name    = "_sip._tcp"
+  type    = local.srv
+  data {
+    priority = 5
+    weight   = 10
+    port     = 5060
+    target   = "sip.example.com"
+  }
+}`,
+				Expected: `resource "cloudflare_dns_record" "srv" {
+  zone_id  = var.zone_id
+  name     = "_sip._tcp"
+  type     = local.srv
+  priority = 5
+  ttl      = 1
+  data = {
+    priority = 5
+    weight   = 10
+    port     = 5060
+    target   = "sip.example.com"
+  }
+}
+
+moved {
+  from = cloudflare_record.srv
+  to   = cloudflare_dns_record.srv
+}`,
+				State: state,
+				EvalContext: tfhcl.BuildEvalContext([]tfhcl.SourceFile{{
+					Name:    "locals.tf",
+					Content: []byte("locals {\n  srv = \"SRV\"\n}\n"),
+				}}, nil),
+			},
+		}
+
+		testhelpers.RunConfigTransformTests(t, tests, migrator)
+	})
 }
@@ -57,7 +57,7 @@ func (m *V4ToV5Migrator) TransformConfig(ctx *transform.Context, block *hclwrite
 	typeAttr := body.GetAttribute("type")
 	if typeAttr == nil {
 		// If no type specified, v5 defaults to "HTTP", so treat as HTTP
-		return m.transformToHTTPConfig(block)
+		return m.transformToHTTPConfig(ctx, block)
 	}
 
 	// Extract the type value
@@ -68,12 +68,12 @@ func (m *V4ToV5Migrator) TransformConfig(ctx *transform.Context, block *hclwrite
 		return m.transformToTCPConfig(block)
 	} else {
 		// HTTP or HTTPS both use http_config
-		return m.transformToHTTPConfig(block)
+		return m.transformToHTTPConfig(ctx, block)
 	}
 }
 
 // transformToHTTPConfig creates http_config nested attribute and moves HTTP fields into it
-func (m *V4ToV5Migrator) transformToHTTPConfig(block *hclwrite.Block) (*transform.TransformResult, error) {
+func (m *V4ToV5Migrator) transformToHTTPConfig(ctx *transform.Context, block *hclwrite.Block) (*transform.TransformResult, error) {
 	body := block.Body()
 
 	// List of fields that should move into http_config
@@ -93,10 +93,7 @@ func (m *V4ToV5Migrator) transformToHTTPConfig(block *hclwrite.Block) (*transfor
 	// Handle header blocks specially - they need to convert from Set to Map
 	// v4: header { header = "Host" values = ["example.com"] }
 	// v5: http_config = { header = { "Host" = ["example.com"] } }
-	headerTokens, err := m.buildHeaderMapTokens(body)
-	if err != nil {
-		return nil, err
-	}
+	headerTokens, headerBlocks := m.buildHeaderMapTokens(body)
 	if headerTokens != nil {
 		fields["header"] = headerTokens
 	}
@@ -111,8 +108,11 @@ func (m *V4ToV5Migrator) transformToHTTPConfig(block *hclwrite.Block) (*transfor
 		}
 
 		// Remove header blocks
-		hcl.RemoveBlocksByType(body, "header")
+		for _, headerBlock := range headerBlocks {
+			body.RemoveBlock(headerBlock)
+		}
 	}
+	ctx.WarnDynamicBlocks(body, "cloudflare_healthcheck."+hcl.GetResourceName(block), "header")
 
 	// Path B: Resource NOT renamed
 	// Return the modified block, no moved block needed
@@ -150,47 +150,10 @@ func (m *V4ToV5Migrator) transformToTCPConfig(block *hclwrite.Block) (*transform
 // buildHeaderMapTokens converts v4 header blocks to v5 header map tokens
 // v4: header { header = "Host" values = ["example.com"] }
 // v5: header = { "Host" = ["example.com"] }
-func (m *V4ToV5Migrator) buildHeaderMapTokens(body *hclwrite.Body) (hclwrite.Tokens, error) {
-	// Find all header blocks
-	headerBlocks := hcl.FindBlocksByType(body, "header")
-	if len(headerBlocks) == 0 {
-		return nil, nil
-	}
-
-	// Build a list of object attributes for the header map
-	var headerAttrs []hclwrite.ObjectAttrTokens
-
-	for _, block := range headerBlocks {
-		blockBody := block.Body()
-
-		// Get the header name
-		headerAttr := blockBody.GetAttribute("header")
-		if headerAttr == nil {
-			continue
-		}
-
-		// Get the values
-		valuesAttr := blockBody.GetAttribute("values")
-		if valuesAttr == nil {
-			continue
-		}
-
-		// Use the header value as the map key and values as the map value
-		nameTokens := headerAttr.Expr().BuildTokens(nil)
-		valueTokens := valuesAttr.Expr().BuildTokens(nil)
-
-		headerAttrs = append(headerAttrs, hclwrite.ObjectAttrTokens{
-			Name:  nameTokens,
-			Value: valueTokens,
-		})
-	}
-
-	if len(headerAttrs) == 0 {
-		return nil, nil
-	}
-
-	// Create the object tokens for the header map
-	return hclwrite.TokensForObject(headerAttrs), nil
+// Dynamic header blocks become for expressions, merged with the static headers.
+// The header blocks that were converted are returned for removal.
+func (m *V4ToV5Migrator) buildHeaderMapTokens(body *hclwrite.Body) (hclwrite.Tokens, []*hclwrite.Block) {
+	return hcl.BuildMapFromBlocks(hcl.FindBlocksWithDynamic(body, "header"), "header", "values")
 }
 
 func init() {
@@ -117,6 +117,33 @@ func TestV4ToV5ConfigTransformation(t *testing.T) {
       "User-Agent" = ["HealthChecker/1.0"]
     }
   }
+}`,
+		},
+		{
+			Name: "HTTP with dynamic header block",
+			Input: `resource "cloudflare_healthcheck" "with_headers" {
+  zone_id = "abc123"
+  name    = "header-check"
+  address = "example.com"
+  type    = "HTTP"
+
+  dynamic "header" {
+    for_each = var.headers
+    content {
+      header = header.key
+      values = header.value
+    }
+  }
+}`,
+			Expected: `resource "cloudflare_healthcheck" "with_headers" {
+  zone_id = "abc123"
+  name    = "header-check"
+  address = "example.com"
+  type    = "HTTP"
+
+  http_config = {
+    header = { for key, value in var.headers : key => value }
+  }
 }`,
 		},
 		{
@@ -60,7 +60,9 @@ func (m *V4ToV5Migrator) GetResourceRename() ([]string, string) {
 // exists" if the rule was actually created by the v4 provider.
 //
 // We emit a MIGRATION WARNING comment so users know they may need to manually
-// import the existing rule.
+// import the existing rule. With a state snapshot, resources whose
+// detection_id was stored are left alone, and the zone_id of the import block
+// is read from state when the config does not spell it out.
 func (m *V4ToV5Migrator) TransformConfig(ctx *transform.Context, block *hclwrite.Block) (*transform.TransformResult, error) {
 	body := block.Body()
 	resourceName := block.Labels()[1]
@@ -69,7 +71,7 @@ func (m *V4ToV5Migrator) TransformConfig(ctx *transform.Context, block *hclwrite
 	// Generate an import {} block with placeholder IDs so the user can fill
 	// them in without any manual HCL editing. Also emit a terminal diagnostic
 	// with the curl command to find the real detection_id.
-	if body.GetAttribute("id") == nil {
+	if body.GetAttribute("id") == nil && !hasDetectionIDInState(ctx, resourceName) {
 		// Extract zone_id if it's a literal 32-char hex string; otherwise keep placeholder.
 		zoneID := "<zone_id>"
 		if zoneAttr := body.GetAttribute("zone_id"); zoneAttr != nil {
@@ -78,6 +80,11 @@ func (m *V4ToV5Migrator) TransformConfig(ctx *transform.Context, block *hclwrite
 				zoneID = extracted
 			}
 		}
+		if zoneID == "<zone_id>" {
+			if stateZoneID, ok := ctx.StateAttribute("cloudflare_leaked_credential_check_rule", resourceName, "zone_id"); ok && len(stateZoneID.String()) == 32 {
+				zoneID = stateZoneID.String()
+			}
+		}
 
 		importID := fmt.Sprintf("%s/<detection_id>", zoneID)
 		listCmd := fmt.Sprintf(
@@ -121,3 +128,19 @@ func (m *V4ToV5Migrator) TransformConfig(ctx *transform.Context, block *hclwrite
 		RemoveOriginal: false,
 	}, nil
 }
+
+// hasDetectionIDInState reports whether the state snapshot shows that the v4
+// bug did not hit this resource: every instance of it has a non-empty id,
+// which is the detection_id, so the v5 provider can read it as is.
+func hasDetectionIDInState(ctx *transform.Context, resourceName string) bool {
+	instances := ctx.State.Instances("cloudflare_leaked_credential_check_rule", resourceName)
+	if len(instances) == 0 {
+		return false
+	}
+	for _, instance := range instances {
+		if instance.Attributes.Get("id").String() == "" {
+			return false
+		}
+	}
+	return true
+}
@@ -3,7 +3,10 @@ package leaked_credential_check_rule
 import (
 	"testing"
 
+	"github.com/stretchr/testify/require"
+
 	"github.com/cloudflare/tf-migrate/internal/testhelpers"
+	"github.com/cloudflare/tf-migrate/internal/transform"
 )
 
 func TestV4ToV5Transformation(t *testing.T) {
@@ -150,4 +153,60 @@ resource "cloudflare_leaked_credential_check_rule" "var_zone" {
 
 		testhelpers.RunConfigTransformTests(t, tests, migrator)
 	})
+
+	t.Run("ConfigTransformationWithState", func(t *testing.T) {
+		state, err := transform.ParseStateSnapshot([]byte(`{
+  "version": 4,
+  "resources": [
+    {
+      "mode": "managed",
+      "type": "cloudflare_leaked_credential_check_rule",
+      "name": "stored",
+      "instances": [{"attributes": {"id": "d1a2b3c4", "zone_id": "0da42c8d2132a9ddaf714f9e7c920711"}}]
+    },
+    {
+      "mode": "managed",
+      "type": "cloudflare_leaked_credential_check_rule",
+      "name": "missing",
+      "instances": [{"attributes": {"id": "", "zone_id": "0da42c8d2132a9ddaf714f9e7c920711"}}]
+    }
+  ]
+}`))
+		require.NoError(t, err)
+
+		tests := []testhelpers.ConfigTestCase{
+			{
+				Name: "detection_id stored in state needs no import block",
+				Input: `
+resource "cloudflare_leaked_credential_check_rule" "stored" {
+  zone_id  = var.zone_id
+  username = "http.request.body.form.username"
+}`,
+				Expected: `resource "cloudflare_leaked_credential_check_rule" "stored" {
+  zone_id  = var.zone_id
+  username = "http.request.body.form.username"
+}`,
+				State: state,
+			},
+			{
+				Name: "empty id in state resolves zone_id of the import block",
+				Input: `
+resource "cloudflare_leaked_credential_check_rule" "missing" {
+  zone_id  = var.zone_id
+  username = "http.request.body.form.username"
+}`,
+				Expected: `import {
+  to = cloudflare_leaked_credential_check_rule.missing
+  id = "0da42c8d2132a9ddaf714f9e7c920711/<detection_id>"
+}
+resource "cloudflare_leaked_credential_check_rule" "missing" {
+  zone_id  = var.zone_id
+  username = "http.request.body.form.username"
+}`,
+				State: state,
+			},
+		}
+
+		testhelpers.RunConfigTransformTests(t, tests, migrator)
+	})
 }
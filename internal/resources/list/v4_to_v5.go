@@ -51,11 +51,10 @@ func (m *V4ToV5Migrator) TransformConfig(ctx *transform.Context, block *hclwrite
 		}, nil
 	}
 
-	itemBlocks := tfhcl.FindBlocksByType(body, "item")
-	dynamicBlocks := findDynamicItemBlocks(body)
+	itemBlocks := tfhcl.FindBlocksWithDynamic(body, "item")
 
-	if len(dynamicBlocks) > 0 {
-		transformListWithDynamicBlocks(body, itemBlocks, dynamicBlocks, kind)
+	if hasDynamicItemBlocks(itemBlocks) {
+		transformListWithDynamicBlocks(body, itemBlocks, kind)
 	} else if len(itemBlocks) > 0 {
 		transformStaticItemBlocks(body, itemBlocks, kind)
 	}
@@ -66,14 +65,13 @@ func (m *V4ToV5Migrator) TransformConfig(ctx *transform.Context, block *hclwrite
 	}, nil
 }
 
-func findDynamicItemBlocks(body *hclwrite.Body) []*hclwrite.Block {
-	var dynamicBlocks []*hclwrite.Block
-	for _, block := range body.Blocks() {
-		if block.Type() == "dynamic" && len(block.Labels()) > 0 && block.Labels()[0] == "item" {
-			dynamicBlocks = append(dynamicBlocks, block)
+func hasDynamicItemBlocks(itemBlocks []*hclwrite.Block) bool {
+	for _, block := range itemBlocks {
+		if block.Type() == "dynamic" {
+			return true
 		}
 	}
-	return dynamicBlocks
+	return false
 }
 
 func transformStaticItemBlocks(body *hclwrite.Body, itemBlocks []*hclwrite.Block, kind string) {
@@ -241,109 +239,74 @@ func buildItemObjectFromBlock(itemBlock *hclwrite.Block, kind string) cty.Value
 	return cty.ObjectVal(itemMap)
 }
 
-func transformListWithDynamicBlocks(body *hclwrite.Body, staticBlocks, dynamicBlocks []*hclwrite.Block, kind string) {
-	var itemsExprStr string
-
-	// Build for expressions from dynamic blocks
-	var forExprs []string
-	for _, dynBlock := range dynamicBlocks {
-		forExpr := buildForExpressionFromDynamic(dynBlock, kind)
-		if forExpr != "" {
-			forExprs = append(forExprs, forExpr)
+// transformListWithDynamicBlocks sets items from static and dynamic item blocks.
+// Consecutive static items become a tuple and each dynamic block a for expression,
+// merged with concat() in source order.
+func transformListWithDynamicBlocks(body *hclwrite.Body, itemBlocks []*hclwrite.Block, kind string) {
+	var exprs []hclwrite.Tokens
+	var staticBlocks []*hclwrite.Block
+
+	flushStatic := func() {
+		if staticExpr := buildStaticItemsExpressionString(staticBlocks, kind); staticExpr != "" {
+			if tokens, err := tfhcl.TokensForExpressionString(staticExpr); err == nil {
+				exprs = append(exprs, tokens)
+			}
 		}
+		staticBlocks = nil
 	}
 
-	// Build static items expression
-	var staticExpr string
-	if len(staticBlocks) > 0 {
-		staticExpr = buildStaticItemsExpressionString(staticBlocks, kind)
-	}
-
-	// Combine expressions
-	if len(forExprs) == 1 && staticExpr == "" {
-		// Single dynamic block only
-		itemsExprStr = forExprs[0]
-	} else if len(forExprs) == 0 && staticExpr != "" {
-		// Static items only - use the regular function
-		transformStaticItemBlocks(body, staticBlocks, kind)
-		for _, block := range dynamicBlocks {
-			body.RemoveBlock(block)
+	for _, block := range itemBlocks {
+		if block.Type() != "dynamic" {
+			staticBlocks = append(staticBlocks, block)
+			continue
 		}
-		return
-	} else if len(forExprs) > 0 || staticExpr != "" {
-		// Mixed or multiple - create concat expression
-		var allExprs []string
-		if staticExpr != "" {
-			allExprs = append(allExprs, staticExpr)
+		flushStatic()
+		if forExpr := buildForExpressionFromDynamic(block, kind); forExpr != nil {
+			exprs = append(exprs, forExpr)
 		}
-		allExprs = append(allExprs, forExprs...)
-		itemsExprStr = fmt.Sprintf("concat(%s)", strings.Join(allExprs, ", "))
 	}
+	flushStatic()
 
 	// Remove all item-related blocks
-	for _, block := range staticBlocks {
-		body.RemoveBlock(block)
-	}
-	for _, block := range dynamicBlocks {
+	for _, block := range itemBlocks {
 		body.RemoveBlock(block)
 	}
 
 	// Set the items attribute
-	if itemsExprStr != "" {
-		setItemsAttributeFromString(body, itemsExprStr)
+	switch len(exprs) {
+	case 0:
+	case 1:
+		body.SetAttributeRaw("items", exprs[0])
+	default:
+		body.SetAttributeRaw("items", tfhcl.BuildConcatExpression(exprs))
 	}
 }
 
-// buildForExpressionFromDynamic creates a for expression string from a dynamic block
-func buildForExpressionFromDynamic(dynBlock *hclwrite.Block, kind string) string {
-	dynBody := dynBlock.Body()
-
-	// Get for_each expression
-	forEachAttr := dynBody.GetAttribute("for_each")
-	if forEachAttr == nil {
-		return ""
-	}
-
-	forEachStr := strings.TrimSpace(string(forEachAttr.Expr().BuildTokens(nil).Bytes()))
-
-	// Get iterator name (default is the block label)
-	iteratorName := dynBlock.Labels()[0]
-	if iterAttr := dynBody.GetAttribute("iterator"); iterAttr != nil {
-		iteratorName = tfhcl.ExtractStringFromAttribute(iterAttr)
-	}
-
-	// Find content block
-	var contentBlock *hclwrite.Block
-	for _, b := range dynBody.Blocks() {
-		if b.Type() == "content" {
-			contentBlock = b
-			break
+// buildForExpressionFromDynamic creates a for expression from a dynamic block.
+// Returns nil if the content block yields no item fields.
+func buildForExpressionFromDynamic(dynBlock *hclwrite.Block, kind string) hclwrite.Tokens {
+	var objTokens hclwrite.Tokens
+	forExpr := tfhcl.DynamicBlockToForExpression(dynBlock, func(contentBlock *hclwrite.Block) hclwrite.Tokens {
+		if objExpr := buildObjectFromContentBlock(contentBlock, kind); objExpr != "" {
+			objTokens, _ = tfhcl.TokensForExpressionString(objExpr)
 		}
+		return objTokens
+	})
+	if objTokens == nil {
+		return nil
 	}
-
-	if contentBlock == nil {
-		return ""
-	}
-
-	// Build object expression from content
-	objExpr := buildObjectFromContentBlock(contentBlock, kind, iteratorName)
-	if objExpr == "" {
-		return ""
-	}
-
-	// Create the for expression
-	return fmt.Sprintf("[for %s in %s : %s]", iteratorName, forEachStr, objExpr)
+	return forExpr
 }
 
 // buildObjectFromContentBlock creates an object expression string from a content block
-func buildObjectFromContentBlock(contentBlock *hclwrite.Block, kind string, iteratorName string) string {
+func buildObjectFromContentBlock(contentBlock *hclwrite.Block, kind string) string {
 	contentBody := contentBlock.Body()
 	var fields []string
 
 	// Process value block first (to get ip, asn, hostname, redirect)
 	for _, vBlock := range contentBody.Blocks() {
 		if vBlock.Type() == "value" {
-			valueFields := extractValueBlockFields(vBlock, kind, iteratorName)
+			valueFields := extractValueBlockFields(vBlock, kind)
 			fields = append(fields, valueFields...)
 		}
 	}
@@ -351,7 +314,6 @@ func buildObjectFromContentBlock(contentBlock *hclwrite.Block, kind string, iter
 	// Then add comment attribute
 	if commentAttr := contentBody.GetAttribute("comment"); commentAttr != nil {
 		commentExpr := strings.TrimSpace(string(commentAttr.Expr().BuildTokens(nil).Bytes()))
-		commentExpr = tfhcl.StripIteratorValueSuffix(commentExpr, iteratorName)
 		fields = append(fields, fmt.Sprintf("comment = %s", commentExpr))
 	}
 
@@ -363,7 +325,7 @@ func buildObjectFromContentBlock(contentBlock *hclwrite.Block, kind string, iter
 }
 
 // extractValueBlockFields extracts field expressions from a value block
-func extractValueBlockFields(vBlock *hclwrite.Block, kind string, iteratorName string) []string {
+func extractValueBlockFields(vBlock *hclwrite.Block, kind string) []string {
 	vBody := vBlock.Body()
 	var fields []string
 
@@ -371,7 +333,6 @@ func extractValueBlockFields(vBlock *hclwrite.Block, kind string, iteratorName s
 	case "ip":
 		if ipAttr := vBody.GetAttribute("ip"); ipAttr != nil {
 			ipExpr := strings.TrimSpace(string(ipAttr.Expr().BuildTokens(nil).Bytes()))
-			ipExpr = tfhcl.StripIteratorValueSuffix(ipExpr, iteratorName)
 			ipExpr = normalizeIPAddressInExpr(ipExpr)
 			fields = append(fields, fmt.Sprintf("ip = %s", ipExpr))
 		}
@@ -379,14 +340,13 @@ func extractValueBlockFields(vBlock *hclwrite.Block, kind string, iteratorName s
 	case "asn":
 		if asnAttr := vBody.GetAttribute("asn"); asnAttr != nil {
 			asnExpr := strings.TrimSpace(string(asnAttr.Expr().BuildTokens(nil).Bytes()))
-			asnExpr = tfhcl.StripIteratorValueSuffix(asnExpr, iteratorName)
 			fields = append(fields, fmt.Sprintf("asn = %s", asnExpr))
 		}
 
 	case "hostname":
 		for _, hBlock := range vBody.Blocks() {
 			if hBlock.Type() == "hostname" {
-				hostnameObj := buildHostnameObjectString(hBlock, iteratorName)
+				hostnameObj := buildHostnameObjectString(hBlock)
 				if hostnameObj != "" {
 					fields = append(fields, fmt.Sprintf("hostname = %s", hostnameObj))
 				}
@@ -396,7 +356,7 @@ func extractValueBlockFields(vBlock *hclwrite.Block, kind string, iteratorName s
 	case "redirect":
 		for _, rBlock := range vBody.Blocks() {
 			if rBlock.Type() == "redirect" {
-				redirectObj := buildRedirectObjectString(rBlock, iteratorName)
+				redirectObj := buildRedirectObjectString(rBlock)
 				if redirectObj != "" {
 					fields = append(fields, fmt.Sprintf("redirect = %s", redirectObj))
 				}
@@ -408,13 +368,12 @@ func extractValueBlockFields(vBlock *hclwrite.Block, kind string, iteratorName s
 }
 
 // buildHostnameObjectString creates a hostname object expression string
-func buildHostnameObjectString(hBlock *hclwrite.Block, iteratorName string) string {
+func buildHostnameObjectString(hBlock *hclwrite.Block) string {
 	hBody := hBlock.Body()
 	var fields []string
 
 	if urlAttr := hBody.GetAttribute("url_hostname"); urlAttr != nil {
 		urlExpr := strings.TrimSpace(string(urlAttr.Expr().BuildTokens(nil).Bytes()))
-		urlExpr = tfhcl.StripIteratorValueSuffix(urlExpr, iteratorName)
 		fields = append(fields, fmt.Sprintf("url_hostname = %s", urlExpr))
 	}
 
@@ -426,14 +385,13 @@ func buildHostnameObjectString(hBlock *hclwrite.Block, iteratorName string) stri
 }
 
 // buildRedirectObjectString creates a redirect object expression string with boolean conversions
-func buildRedirectObjectString(rBlock *hclwrite.Block, iteratorName string) string {
+func buildRedirectObjectString(rBlock *hclwrite.Block) string {
 	rBody := rBlock.Body()
 	var fields []string
 
 	// Required fields
 	if sourceAttr := rBody.GetAttribute("source_url"); sourceAttr != nil {
 		sourceExpr := strings.TrimSpace(string(sourceAttr.Expr().BuildTokens(nil).Bytes()))
-		sourceExpr = tfhcl.StripIteratorValueSuffix(sourceExpr, iteratorName)
 		// For literal strings, ensure path is present
 		sourceExpr = ensureSourceURLHasPathInExpr(sourceExpr)
 		fields = append(fields, fmt.Sprintf("source_url = %s", sourceExpr))
@@ -441,7 +399,6 @@ func buildRedirectObjectString(rBlock *hclwrite.Block, iteratorName string) stri
 
 	if targetAttr := rBody.GetAttribute("target_url"); targetAttr != nil {
 		targetExpr := strings.TrimSpace(string(targetAttr.Expr().BuildTokens(nil).Bytes()))
-		targetExpr = tfhcl.StripIteratorValueSuffix(targetExpr, iteratorName)
 		fields = append(fields, fmt.Sprintf("target_url = %s", targetExpr))
 	}
 
@@ -463,7 +420,6 @@ func buildRedirectObjectString(rBlock *hclwrite.Block, iteratorName string) stri
 			} else {
 				// Keep original expression (might be dynamic)
 				exprStr := strings.TrimSpace(string(attr.Expr().BuildTokens(nil).Bytes()))
-				exprStr = tfhcl.StripIteratorValueSuffix(exprStr, iteratorName)
 				// Convert "enabled"/"disabled" strings in expressions
 				exprStr = tfhcl.ConvertEnabledDisabledInExpr(exprStr)
 				fields = append(fields, fmt.Sprintf("%s = %s", field, exprStr))
@@ -474,7 +430,6 @@ func buildRedirectObjectString(rBlock *hclwrite.Block, iteratorName string) stri
 	// Optional status_code
 	if statusAttr := rBody.GetAttribute("status_code"); statusAttr != nil {
 		statusExpr := strings.TrimSpace(string(statusAttr.Expr().BuildTokens(nil).Bytes()))
-		statusExpr = tfhcl.StripIteratorValueSuffix(statusExpr, iteratorName)
 		fields = append(fields, fmt.Sprintf("status_code = %s", statusExpr))
 	}
 
@@ -511,7 +466,7 @@ func buildObjectStringFromItemBlock(block *hclwrite.Block, kind string) string {
 	// Process value block first
 	for _, vBlock := range body.Blocks() {
 		if vBlock.Type() == "value" {
-			valueFields := extractValueBlockFields(vBlock, kind, "")
+			valueFields := extractValueBlockFields(vBlock, kind)
 			fields = append(fields, valueFields...)
 		}
 	}
@@ -304,8 +304,8 @@ func testDynamicBlockConfig(t *testing.T) {
   name       = "dynamic_list"
   kind       = "ip"
 
-  items = [for item in var.ip_list : {
-    ip = item
+  items = [for value in var.ip_list : {
+    ip = value
   }]
 }`,
 		},
@@ -336,9 +336,12 @@ func testDynamicBlockConfig(t *testing.T) {
   name       = "mixed"
   kind       = "ip"
 
-  items = concat([{ ip = "1.1.1.1" }], [for item in var.additional_ips : {
-    ip = item
-  }])
+  items = concat(
+    [{ ip = "1.1.1.1" }],
+    [for value in var.additional_ips : {
+      ip = value
+    }],
+  )
 }`,
 		},
 		{
@@ -363,8 +366,8 @@ func testDynamicBlockConfig(t *testing.T) {
   name       = "test"
   kind       = "ip"
 
-  items = [for ip_item in var.ip_addresses : {
-    ip = ip_item
+  items = [for value in var.ip_addresses : {
+    ip = value
   }]
 }`,
 		},
@@ -390,9 +393,9 @@ func testDynamicBlockConfig(t *testing.T) {
   name       = "test"
   kind       = "asn"
 
-  items = [for item in var.asn_list : {
-    asn     = item.number
-    comment = item.description
+  items = [for value in var.asn_list : {
+    asn     = value.number
+    comment = value.description
   }]
 }`,
 		},
@@ -420,8 +423,8 @@ func testDynamicBlockConfig(t *testing.T) {
   name       = "test"
   kind       = "hostname"
 
-  items = [for host in var.hostnames : {
-    hostname = { url_hostname = host }
+  items = [for value in var.hostnames : {
+    hostname = { url_hostname = value }
   }]
 }`,
 		},
@@ -452,14 +455,51 @@ func testDynamicBlockConfig(t *testing.T) {
   name       = "test"
   kind       = "redirect"
 
-  items = [for redir in var.redirects : {
+  items = [for value in var.redirects : {
     redirect = {
-      source_url         = redir.from
-      target_url         = redir.to
+      source_url         = value.from
+      target_url         = value.to
       include_subdomains = true
       status_code        = 301
     }
   }]
+}`,
+		},
+		{
+			Name: "Dynamic block over a map followed by a static item",
+			Input: `resource "cloudflare_list" "test" {
+  account_id = "abc123"
+  name       = "test"
+  kind       = "ip"
+
+  dynamic "item" {
+    for_each = var.ips_by_owner
+    content {
+      value {
+        ip = item.value
+      }
+      comment = item.key
+    }
+  }
+
+  item {
+    value {
+      ip = "1.1.1.1"
+    }
+  }
+}`,
+			Expected: `resource "cloudflare_list" "test" {
+  account_id = "abc123"
+  name       = "test"
+  kind       = "ip"
+
+  items = concat(
+    [for key, value in var.ips_by_owner : {
+      ip      = value
+      comment = key
+    }],
+    [{ ip = "1.1.1.1" }],
+  )
 }`,
 		},
 	}
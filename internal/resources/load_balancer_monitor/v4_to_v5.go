@@ -44,15 +44,15 @@ func (m *V4ToV5Migrator) TransformConfig(ctx *transform.Context, block *hclwrite
 	// Transform header blocks to map attribute
 	// v4: header { header = "Host" values = ["example.com"] }
 	// v5: header = { "Host" = ["example.com"] }
-	headerTokens, err := m.buildHeaderMapTokens(body)
-	if err != nil {
-		return nil, err
-	}
+	headerTokens, headerBlocks := m.buildHeaderMapTokens(body)
 	if headerTokens != nil {
 		body.SetAttributeRaw("header", headerTokens)
 		// Remove the old header blocks
-		tfhcl.RemoveBlocksByType(body, "header")
+		for _, headerBlock := range headerBlocks {
+			body.RemoveBlock(headerBlock)
+		}
 	}
+	ctx.WarnDynamicBlocks(body, "cloudflare_load_balancer_monitor."+tfhcl.GetResourceName(block), "header")
 
 	return &transform.TransformResult{
 		Blocks:         []*hclwrite.Block{block},
@@ -63,47 +63,10 @@ func (m *V4ToV5Migrator) TransformConfig(ctx *transform.Context, block *hclwrite
 // buildHeaderMapTokens converts v4 header blocks to v5 header map tokens
 // v4: header { header = "Host" values = ["example.com"] }
 // v5: header = { "Host" = ["example.com"] }
-func (m *V4ToV5Migrator) buildHeaderMapTokens(body *hclwrite.Body) (hclwrite.Tokens, error) {
-	// Find all header blocks
-	headerBlocks := tfhcl.FindBlocksByType(body, "header")
-	if len(headerBlocks) == 0 {
-		return nil, nil
-	}
-
-	// Build a list of object attributes for the header map
-	var headerAttrs []hclwrite.ObjectAttrTokens
-
-	for _, block := range headerBlocks {
-		blockBody := block.Body()
-
-		// Get the header name
-		headerAttr := blockBody.GetAttribute("header")
-		if headerAttr == nil {
-			continue
-		}
-
-		// Get the values
-		valuesAttr := blockBody.GetAttribute("values")
-		if valuesAttr == nil {
-			continue
-		}
-
-		// Use the header value as the map key and values as the map value
-		nameTokens := headerAttr.Expr().BuildTokens(nil)
-		valueTokens := valuesAttr.Expr().BuildTokens(nil)
-
-		headerAttrs = append(headerAttrs, hclwrite.ObjectAttrTokens{
-			Name:  nameTokens,
-			Value: valueTokens,
-		})
-	}
-
-	if len(headerAttrs) == 0 {
-		return nil, nil
-	}
-
-	// Create the object tokens for the header map
-	return hclwrite.TokensForObject(headerAttrs), nil
+// Dynamic header blocks become for expressions, merged with the static headers.
+// The header blocks that were converted are returned for removal.
+func (m *V4ToV5Migrator) buildHeaderMapTokens(body *hclwrite.Body) (hclwrite.Tokens, []*hclwrite.Block) {
+	return tfhcl.BuildMapFromBlocks(tfhcl.FindBlocksWithDynamic(body, "header"), "header", "values")
 }
 
 func init() {
@@ -102,6 +102,58 @@ func TestV4ToV5Transformation(t *testing.T) {
   header = {
     "Host" = ["cf-tf-test.com"]
   }
+}`,
+			},
+			{
+				Name: "Dynamic header merged with static header",
+				Input: `resource "cloudflare_load_balancer_monitor" "example" {
+  account_id = "abc123"
+
+  header {
+    header = "Host"
+    values = ["cf-tf-test.com"]
+  }
+
+  dynamic "header" {
+    for_each = var.headers
+    content {
+      header = header.key
+      values = header.value
+    }
+  }
+}`,
+				Expected: `resource "cloudflare_load_balancer_monitor" "example" {
+  account_id = "abc123"
+
+  header = merge(
+    {
+      "Host" = ["cf-tf-test.com"]
+    },
+    { for key, value in var.headers : key => value },
+  )
+}`,
+			},
+			{
+				Name: "Dynamic header without for_each left unchanged",
+				Input: `resource "cloudflare_load_balancer_monitor" "example" {
+  account_id = "abc123"
+
+  dynamic "header" {
+    content {
+      header = "Host"
+      values = ["cf-tf-test.com"]
+    }
+  }
+}`,
+				Expected: `resource "cloudflare_load_balancer_monitor" "example" {
+  account_id = "abc123"
+
+  dynamic "header" {
+    content {
+      header = "Host"
+      values = ["cf-tf-test.com"]
+    }
+  }
 }`,
 			},
 		}
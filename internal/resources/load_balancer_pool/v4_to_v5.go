@@ -1,8 +1,10 @@
 package load_balancer_pool
 
 import (
+	"fmt"
 	"strings"
 
+	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/hashicorp/hcl/v2/hclwrite"
 
@@ -44,36 +46,42 @@ func (m *V4ToV5Migrator) GetResourceRename() ([]string, string) {
 func (m *V4ToV5Migrator) TransformConfig(ctx *transform.Context, block *hclwrite.Block) (*transform.TransformResult, error) {
 	body := block.Body()
 
-	// Pre-process origins blocks to transform nested header blocks
+	// Pre-process origins blocks, and the content of dynamic origins blocks,
+	// to transform nested header blocks
 	// v4: header { header = "Host" values = [...] }
 	// v5: header = { host = [...] }
-	originsBlocks := tfhcl.FindBlocksByType(body, "origins")
-	for _, originBlock := range originsBlocks {
-		transformHeaderBlock(originBlock.Body())
-	}
-
-	// Pre-process dynamic origins blocks to transform nested header blocks
-	dynamicBlocks := tfhcl.FindBlocksByType(body, "dynamic")
-	for _, dynamicBlock := range dynamicBlocks {
-		labels := dynamicBlock.Labels()
-		if len(labels) > 0 && labels[0] == "origins" {
-			// Find the content block within the dynamic block
-			contentBlock := tfhcl.FindBlockByType(dynamicBlock.Body(), "content")
-			if contentBlock != nil {
-				transformHeaderBlock(contentBlock.Body())
-			}
+	// If a header cannot be converted, the origins are left as blocks, since
+	// converting them would drop the header
+	originBlocks := tfhcl.FindBlocksWithDynamic(body, "origins")
+	convertOrigins := true
+	for _, originBlock := range originBlocks {
+		if !headersConvertible(tfhcl.ContentBlock(originBlock).Body()) {
+			convertOrigins = false
 		}
 	}
+	if !convertOrigins {
+		ctx.Diagnostics = append(ctx.Diagnostics, &hcl.Diagnostic{
+			Severity: hcl.DiagWarning,
+			Summary:  fmt.Sprintf("Origin header requires manual migration: cloudflare_load_balancer_pool.%s", tfhcl.GetResourceName(block)),
+			Detail: `An origins header block could not be converted and the origins blocks were left unchanged.
+
+The v5 provider only supports the Host header, as header = { host = [...] } in each
+origin, and header blocks need a literal header name. Rewrite the origins as the v5
+origins attribute.`,
+		})
+	} else {
+		for _, originBlock := range originBlocks {
+			transformHeaderBlock(tfhcl.ContentBlock(originBlock).Body())
+		}
 
-	// Convert dynamic "origins" blocks to for expressions
-	// v4: dynamic "origins" { for_each = ... content { ... } }
-	// v5: origins = [for value in ... : { ... }]
-	tfhcl.ConvertDynamicBlocksToForExpression(body, "origins")
-
-	// Transform origins blocks to origins attribute array
-	// v4: origins { name = "origin1" address = "1.2.3.4" }
-	// v5: origins = [{ name = "origin1" address = "1.2.3.4" }]
-	tfhcl.ConvertBlocksToArrayAttribute(body, "origins", false)
+		// Transform origins blocks to origins attribute array, with dynamic
+		// origins blocks converted to for expressions
+		// v4: origins { name = "origin1" address = "1.2.3.4" }
+		// v5: origins = [{ name = "origin1" address = "1.2.3.4" }]
+		// v4: dynamic "origins" { for_each = ... content { ... } }
+		// v5: origins = [for value in ... : { ... }]
+		tfhcl.ConvertBlocksToArrayAttribute(body, "origins", false)
+	}
 
 	// Transform load_shedding block to attribute (MaxItems:1)
 	// v4: load_shedding { ... }
@@ -94,71 +102,103 @@ func (m *V4ToV5Migrator) TransformConfig(ctx *transform.Context, block *hclwrite
 // transformHeaderBlock transforms a header block from v4 to v5 format
 // v4: header { header = "Host" values = [...] }
 // v5: header = { host = [...] }
+// A dynamic header block becomes a for expression, of which v5 takes the one element:
+// v4: dynamic "header" { for_each = ... content { header = "Host" values = header.value } }
+// v5: header = one([for value in ... : { host = value }])
 func transformHeaderBlock(body *hclwrite.Body) {
-	headerBlocks := tfhcl.FindBlocksByType(body, "header")
-	if len(headerBlocks) == 0 {
-		return
-	}
-
-	// For each header block, transform it to the v5 format
-	for _, headerBlock := range headerBlocks {
-		headerBody := headerBlock.Body()
-
-		// Get the header name (e.g., "Host")
-		headerAttr := headerBody.GetAttribute("header")
-		if headerAttr == nil {
+	for _, headerBlock := range tfhcl.FindBlocksWithDynamic(body, "header") {
+		headerObjTokens := headerObjectTokens(tfhcl.ContentBlock(headerBlock))
+		if headerObjTokens == nil {
 			continue
 		}
 
-		// Get the values
-		valuesAttr := headerBody.GetAttribute("values")
-		if valuesAttr == nil {
-			continue
+		if headerBlock.Type() == "dynamic" {
+			forTokens := tfhcl.DynamicBlockToForExpression(headerBlock, headerObjectTokens)
+			headerObjTokens = append(hclwrite.Tokens{
+				{Type: hclsyntax.TokenIdent, Bytes: []byte("one")},
+				{Type: hclsyntax.TokenOParen, Bytes: []byte("(")},
+			}, forTokens...)
+			headerObjTokens = append(headerObjTokens, &hclwrite.Token{Type: hclsyntax.TokenCParen, Bytes: []byte(")")})
 		}
 
-		// Extract header name from attribute
-		headerName := ""
-		headerTokens := headerAttr.Expr().BuildTokens(nil)
-		for _, token := range headerTokens {
-			if token.Type == hclsyntax.TokenQuotedLit {
-				headerName = string(token.Bytes)
-				break
-			}
-		}
+		// Replace the header block with a header attribute
+		body.SetAttributeRaw("header", headerObjTokens)
+		body.RemoveBlock(headerBlock)
+	}
+}
 
-		if headerName == "" {
-			continue
+// headersConvertible reports whether transformHeaderBlock can convert every
+// header block in body, including dynamic ones.
+func headersConvertible(body *hclwrite.Body) bool {
+	for _, dynamicBlock := range tfhcl.FindDynamicBlocks(body, "header") {
+		if !tfhcl.IsConvertibleDynamicBlock(dynamicBlock) {
+			return false
 		}
+	}
+	for _, headerBlock := range tfhcl.FindBlocksWithDynamic(body, "header") {
+		if headerObjectTokens(tfhcl.ContentBlock(headerBlock)) == nil {
+			return false
+		}
+	}
+	return true
+}
 
-		// Convert header name to lowercase for the key
-		headerKey := strings.ToLower(headerName)
+// headerObjectTokens builds the v5 header object, { host = [...] }, from a v4
+// header block, or returns nil if the block lacks the header name or values.
+func headerObjectTokens(headerBlock *hclwrite.Block) hclwrite.Tokens {
+	headerBody := headerBlock.Body()
 
-		// Get the values tokens
-		valuesTokens := valuesAttr.Expr().BuildTokens(nil)
+	// Get the header name (e.g., "Host")
+	headerAttr := headerBody.GetAttribute("header")
+	if headerAttr == nil {
+		return nil
+	}
 
-		// Build the new header object: { host = [...] }
-		var headerObjTokens hclwrite.Tokens
-		headerObjTokens = append(headerObjTokens, &hclwrite.Token{
-			Type:  hclsyntax.TokenOBrace,
-			Bytes: []byte("{"),
-		})
-		headerObjTokens = append(headerObjTokens, &hclwrite.Token{
-			Type:  hclsyntax.TokenIdent,
-			Bytes: []byte(headerKey),
-		})
-		headerObjTokens = append(headerObjTokens, &hclwrite.Token{
-			Type:  hclsyntax.TokenEqual,
-			Bytes: []byte(" = "),
-		})
-		headerObjTokens = append(headerObjTokens, valuesTokens...)
-		headerObjTokens = append(headerObjTokens, &hclwrite.Token{
-			Type:  hclsyntax.TokenCBrace,
-			Bytes: []byte(" }"),
-		})
+	// Get the values
+	valuesAttr := headerBody.GetAttribute("values")
+	if valuesAttr == nil {
+		return nil
+	}
 
-		// Replace the header block with a header attribute
-		body.SetAttributeRaw("header", headerObjTokens)
-		body.RemoveBlock(headerBlock)
+	// Extract header name from attribute
+	headerName := ""
+	headerTokens := headerAttr.Expr().BuildTokens(nil)
+	for _, token := range headerTokens {
+		if token.Type == hclsyntax.TokenQuotedLit {
+			headerName = string(token.Bytes)
+			break
+		}
+	}
+
+	if headerName == "" {
+		return nil
 	}
-}
 
+	// Convert header name to lowercase for the key
+	headerKey := strings.ToLower(headerName)
+
+	// Get the values tokens
+	valuesTokens := valuesAttr.Expr().BuildTokens(nil)
+
+	// Build the new header object: { host = [...] }
+	var headerObjTokens hclwrite.Tokens
+	headerObjTokens = append(headerObjTokens, &hclwrite.Token{
+		Type:  hclsyntax.TokenOBrace,
+		Bytes: []byte("{"),
+	})
+	headerObjTokens = append(headerObjTokens, &hclwrite.Token{
+		Type:         hclsyntax.TokenIdent,
+		Bytes:        []byte(headerKey),
+		SpacesBefore: 1,
+	})
+	headerObjTokens = append(headerObjTokens, &hclwrite.Token{
+		Type:  hclsyntax.TokenEqual,
+		Bytes: []byte(" = "),
+	})
+	headerObjTokens = append(headerObjTokens, valuesTokens...)
+	headerObjTokens = append(headerObjTokens, &hclwrite.Token{
+		Type:  hclsyntax.TokenCBrace,
+		Bytes: []byte(" }"),
+	})
+	return headerObjTokens
+}
@@ -527,3 +527,90 @@ resource "cloudflare_load_balancer_pool" "test" {
 	// 3. No more header blocks
 	assert.NotContains(t, output, "header {", "Header blocks should be removed")
 }
+
+// TestDynamicHeaderBlock tests that a dynamic header block in an origin becomes
+// a for expression.
+func TestDynamicHeaderBlock(t *testing.T) {
+	input := `
+resource "cloudflare_load_balancer_pool" "test" {
+  account_id = "abc123"
+  name       = "test"
+
+  origins {
+    name    = "origin-1"
+    address = "192.0.2.1"
+
+    dynamic "header" {
+      for_each = var.host == null ? [] : [var.host]
+      content {
+        header = "Host"
+        values = [header.value]
+      }
+    }
+  }
+}
+`
+
+	output, ctx := transformPool(t, input)
+
+	assert.Contains(t, output, "header  = one([for value in var.host == null ? [] : [var.host] : { host = [value] }])")
+	assert.NotContains(t, output, `dynamic "header"`)
+	assert.Empty(t, ctx.Diagnostics)
+}
+
+// TestUnconvertibleHeaderBlock tests that origins are left as blocks, with a
+// warning, when one of their headers cannot be converted.
+func TestUnconvertibleHeaderBlock(t *testing.T) {
+	input := `
+resource "cloudflare_load_balancer_pool" "test" {
+  account_id = "abc123"
+  name       = "test"
+
+  origins {
+    name    = "origin-1"
+    address = "192.0.2.1"
+
+    header {
+      header = "Host"
+      values = ["test1.example.com"]
+    }
+  }
+
+  origins {
+    name    = "origin-2"
+    address = "192.0.2.2"
+
+    dynamic "header" {
+      for_each = var.headers
+      content {
+        header = header.key
+        values = header.value
+      }
+    }
+  }
+}
+`
+
+	output, ctx := transformPool(t, input)
+
+	assert.Equal(t, string(hclwrite.Format([]byte(input))), output)
+	require.Len(t, ctx.Diagnostics, 1)
+	assert.Equal(t, hcl.DiagWarning, ctx.Diagnostics[0].Severity)
+	assert.Equal(t, "Origin header requires manual migration: cloudflare_load_balancer_pool.test", ctx.Diagnostics[0].Summary)
+}
+
+func transformPool(t *testing.T, input string) (string, *transform.Context) {
+	t.Helper()
+
+	file, diags := hclwrite.ParseConfig([]byte(input), "test.tf", hcl.InitialPos)
+	require.False(t, diags.HasErrors(), "Failed to parse input HCL")
+
+	migrator := &V4ToV5Migrator{}
+	ctx := &transform.Context{}
+	_, err := migrator.TransformConfig(ctx, file.Body().Blocks()[0])
+	require.NoError(t, err, "TransformConfig failed")
+
+	output := string(hclwrite.Format(file.Bytes()))
+	t.Logf("Output:\n%s", output)
+	return output, ctx
+}
@@ -1,49 +1,17 @@
 package logpull_retention
 
 import (
-	"github.com/cloudflare/tf-migrate/internal"
+	_ "embed"
+
+	"github.com/cloudflare/tf-migrate/internal/rules"
 	"github.com/cloudflare/tf-migrate/internal/transform"
-	tfhcl "github.com/cloudflare/tf-migrate/internal/transform/hcl"
-	"github.com/hashicorp/hcl/v2/hclwrite"
 )
 
-type V4ToV5Migrator struct {
-}
+//go:embed v4_to_v5.yaml
+var v4ToV5Rule []byte
 
+// NewV4ToV5Migrator registers and returns the migrator of cloudflare_logpull_retention
+// from v4 to v5, which applies the rule in v4_to_v5.yaml.
 func NewV4ToV5Migrator() transform.ResourceTransformer {
-	migrator := &V4ToV5Migrator{}
-	internal.RegisterMigrator("cloudflare_logpull_retention", "v4", "v5", migrator)
-	return migrator
-}
-
-func (m *V4ToV5Migrator) GetResourceType() string {
-	return "cloudflare_logpull_retention"
-}
-
-func (m *V4ToV5Migrator) CanHandle(resourceType string) bool {
-	return resourceType == "cloudflare_logpull_retention"
-}
-
-func (m *V4ToV5Migrator) Preprocess(content string) string {
-	// No preprocessing needed for this simple migration
-	return content
+	return rules.MustRegister(v4ToV5Rule, "v4_to_v5.yaml")
 }
-
-// GetResourceRename implements the ResourceRenamer interface
-// This resource does not rename, so we return the same name for both old and new
-func (m *V4ToV5Migrator) GetResourceRename() ([]string, string) {
-	return []string{"cloudflare_logpull_retention"}, "cloudflare_logpull_retention"
-}
-
-func (m *V4ToV5Migrator) TransformConfig(ctx *transform.Context, block *hclwrite.Block) (*transform.TransformResult, error) {
-	body := block.Body()
-
-	// Rename enabled → flag
-	tfhcl.RenameAttribute(body, "enabled", "flag")
-
-	return &transform.TransformResult{
-		Blocks:         []*hclwrite.Block{block},
-		RemoveOriginal: false,
-	}, nil
-}
-
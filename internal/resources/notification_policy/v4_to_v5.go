@@ -49,7 +49,7 @@ func (m *V4ToV5Migrator) TransformConfig(ctx *transform.Context, block *hclwrite
 	tfhcl.ConvertBlocksToAttribute(body, "filters", "filters", func(block *hclwrite.Block) {})
 
 	// Restructure integration fields into mechanisms
-	m.restructureIntegrationFields(body)
+	m.restructureIntegrationFields(ctx, block)
 
 	return &transform.TransformResult{
 		Blocks:         []*hclwrite.Block{block},
@@ -84,80 +84,77 @@ func (m *V4ToV5Migrator) validateAlertTypes(ctx *transform.Context, body *hclwri
 }
 
 // restructureIntegrationFields converts v4 integration fields to v5 mechanisms structure
-func (m *V4ToV5Migrator) restructureIntegrationFields(body *hclwrite.Body) {
-	// Collect all integration blocks
-	emailBlocks := tfhcl.FindBlocksByType(body, "email_integration")
-	webhooksBlocks := tfhcl.FindBlocksByType(body, "webhooks_integration")
-	pagerdutyBlocks := tfhcl.FindBlocksByType(body, "pagerduty_integration")
-
-	if len(emailBlocks) == 0 && len(webhooksBlocks) == 0 && len(pagerdutyBlocks) == 0 {
-		return
-	}
+// Dynamic integration blocks become for expressions in the mechanism lists;
+// those that cannot be converted are left in place with a warning.
+func (m *V4ToV5Migrator) restructureIntegrationFields(ctx *transform.Context, block *hclwrite.Block) {
+	body := block.Body()
 
 	// Build mechanisms structure
 	mechanismsTokens := []hclwrite.ObjectAttrTokens{}
 
-	// Add email integrations
-	if len(emailBlocks) > 0 {
-		emailArray := m.buildIntegrationArray(emailBlocks)
-		mechanismsTokens = append(mechanismsTokens, hclwrite.ObjectAttrTokens{
-			Name:  hclwrite.TokensForIdentifier("email"),
-			Value: emailArray,
-		})
-	}
-
-	// Add webhooks integrations
-	if len(webhooksBlocks) > 0 {
-		webhooksArray := m.buildIntegrationArray(webhooksBlocks)
-		mechanismsTokens = append(mechanismsTokens, hclwrite.ObjectAttrTokens{
-			Name:  hclwrite.TokensForIdentifier("webhooks"),
-			Value: webhooksArray,
-		})
+	for _, integration := range []struct {
+		blockType string
+		mechanism string
+	}{
+		{"email_integration", "email"},
+		{"webhooks_integration", "webhooks"},
+		{"pagerduty_integration", "pagerduty"},
+	} {
+		var integrationBlocks []*hclwrite.Block
+		for _, integrationBlock := range tfhcl.FindBlocksWithDynamic(body, integration.blockType) {
+			if integrationBlock.Type() == "dynamic" && m.buildIntegrationObject(tfhcl.ContentBlock(integrationBlock)) == nil {
+				continue
+			}
+			integrationBlocks = append(integrationBlocks, integrationBlock)
+		}
+		if len(integrationBlocks) > 0 {
+			mechanismsTokens = append(mechanismsTokens, hclwrite.ObjectAttrTokens{
+				Name:  hclwrite.TokensForIdentifier(integration.mechanism),
+				Value: m.buildIntegrationArray(integrationBlocks),
+			})
+
+			// Remove old integration blocks
+			for _, integrationBlock := range integrationBlocks {
+				body.RemoveBlock(integrationBlock)
+			}
+		}
+		ctx.WarnDynamicBlocks(body, "cloudflare_notification_policy."+tfhcl.GetResourceName(block), integration.blockType)
 	}
 
-	// Add pagerduty integrations
-	if len(pagerdutyBlocks) > 0 {
-		pagerdutyArray := m.buildIntegrationArray(pagerdutyBlocks)
-		mechanismsTokens = append(mechanismsTokens, hclwrite.ObjectAttrTokens{
-			Name:  hclwrite.TokensForIdentifier("pagerduty"),
-			Value: pagerdutyArray,
-		})
+	if len(mechanismsTokens) == 0 {
+		return
 	}
 
-	// Remove old integration blocks first
-	tfhcl.RemoveBlocksByType(body, "email_integration")
-	tfhcl.RemoveBlocksByType(body, "webhooks_integration")
-	tfhcl.RemoveBlocksByType(body, "pagerduty_integration")
-
 	// Create mechanisms attribute after removal
 	body.SetAttributeRaw("mechanisms", hclwrite.TokensForObject(mechanismsTokens))
 }
 
 // buildIntegrationArray converts integration blocks to an array of objects with only id field
 func (m *V4ToV5Migrator) buildIntegrationArray(blocks []*hclwrite.Block) hclwrite.Tokens {
-	var arrayElements []hclwrite.Tokens
-
+	var listBlocks []*hclwrite.Block
 	for _, block := range blocks {
-		blockBody := block.Body()
-
-		// Extract id attribute
-		idAttr := blockBody.GetAttribute("id")
-		if idAttr == nil {
-			continue
+		// Blocks without an id are dropped
+		if block.Type() == "dynamic" || m.buildIntegrationObject(block) != nil {
+			listBlocks = append(listBlocks, block)
 		}
-
-		// Create object with only id field (name field is dropped)
-		objTokens := hclwrite.TokensForObject([]hclwrite.ObjectAttrTokens{
-			{
-				Name:  hclwrite.TokensForIdentifier("id"),
-				Value: idAttr.Expr().BuildTokens(nil),
-			},
-		})
-
-		arrayElements = append(arrayElements, objTokens)
 	}
-
-	// Build array tokens using TokensForTuple (which creates arrays in HCL)
-	return hclwrite.TokensForTuple(arrayElements)
+	if len(listBlocks) == 0 {
+		return hclwrite.TokensForTuple(nil)
+	}
+	return tfhcl.BuildListFromBlocks(listBlocks, m.buildIntegrationObject)
 }
 
+// buildIntegrationObject creates an object with only the id field of an
+// integration block (name field is dropped), or returns nil if it has no id.
+func (m *V4ToV5Migrator) buildIntegrationObject(block *hclwrite.Block) hclwrite.Tokens {
+	idAttr := block.Body().GetAttribute("id")
+	if idAttr == nil {
+		return nil
+	}
+	return hclwrite.TokensForObject([]hclwrite.ObjectAttrTokens{
+		{
+			Name:  hclwrite.TokensForIdentifier("id"),
+			Value: idAttr.Expr().BuildTokens(nil),
+		},
+	})
+}
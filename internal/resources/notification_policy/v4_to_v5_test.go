@@ -190,6 +190,57 @@ resource "cloudflare_notification_policy" "multi_integration" {
       id = "pagerduty-444"
     }]
   }
+}`,
+		},
+		{
+			Name: "dynamic integration blocks",
+			Input: `
+resource "cloudflare_notification_policy" "dynamic_integration" {
+  account_id = "f037e56e89293a057740de681ac9abbe"
+  name       = "Dynamic Integration Test"
+  alert_type = "universal_ssl_event_type"
+  enabled    = true
+
+  email_integration {
+    id = "email-123"
+  }
+
+  dynamic "email_integration" {
+    for_each = var.emails
+    content {
+      id   = email_integration.value
+      name = email_integration.value
+    }
+  }
+
+  dynamic "webhooks_integration" {
+    for_each = var.webhooks
+    iterator = webhook
+    content {
+      id = webhook.value.id
+    }
+  }
+}`,
+			Expected: `
+resource "cloudflare_notification_policy" "dynamic_integration" {
+  account_id = "f037e56e89293a057740de681ac9abbe"
+  name       = "Dynamic Integration Test"
+  alert_type = "universal_ssl_event_type"
+  enabled    = true
+
+  mechanisms = {
+    email = concat(
+      [{
+        id = "email-123"
+      }],
+      [for value in var.emails : {
+        id = value
+      }],
+    )
+    webhooks = [for value in var.webhooks : {
+      id = value.id
+    }]
+  }
 }`,
 		},
 		{
@@ -58,6 +58,7 @@ func (m *V4ToV5Migrator) TransformConfig(ctx *transform.Context, block *hclwrite
 	}
 
 	// Step 1: Find and process actions block
+	keepActionsBlock := false
 	actionsBlock := tfhcl.FindBlockByType(body, "actions")
 	if actionsBlock != nil {
 		actionsBody := actionsBlock.Body()
@@ -92,7 +93,11 @@ These fields are no longer supported in the v5 provider:
 		// MUST do this BEFORE converting actions block, while blocks still exist
 		// v4: cache_ttl_by_status { codes = "200" ttl = 3600 }
 		// v5: cache_ttl_by_status = { "200" = "3600" }
+		// A dynamic block that cannot be converted keeps actions a block, so
+		// that it is not dropped
 		m.transformCacheTTLByStatus(actionsBody)
+		ctx.WarnDynamicBlocks(actionsBody, "cloudflare_page_rule."+resourceName, "cache_ttl_by_status")
+		keepActionsBlock = len(tfhcl.FindDynamicBlocks(actionsBody, "cache_ttl_by_status")) > 0
 
 		// Step 1c: Process nested forwarding_url block (if exists)
 		// Convert forwarding_url TypeList MaxItems:1 block to SingleNestedAttribute
@@ -148,7 +153,9 @@ These fields are no longer supported in the v5 provider:
 
 	// Step 2: Convert actions block to attribute (must be LAST!)
 	// Convert actions TypeList MaxItems:1 block to SingleNestedAttribute
-	tfhcl.ConvertSingleBlockToAttribute(body, "actions", "actions")
+	if !keepActionsBlock {
+		tfhcl.ConvertSingleBlockToAttribute(body, "actions", "actions")
+	}
 
 	return &transform.TransformResult{
 		Blocks:         []*hclwrite.Block{block},
@@ -159,10 +166,24 @@ These fields are no longer supported in the v5 provider:
 // transformCacheTTLByStatus transforms cache_ttl_by_status blocks to map syntax
 // v4: cache_ttl_by_status { codes = "200" ttl = 3600 }
 // v5: cache_ttl_by_status = { "200" = "3600" }
+// Dynamic blocks become for expressions, merged with the static entries:
+// v5: cache_ttl_by_status = { for key, value in ... : key => value }
 func (m *V4ToV5Migrator) transformCacheTTLByStatus(body *hclwrite.Body) {
 	// Find all cache_ttl_by_status blocks
 	blocks := tfhcl.FindBlocksByType(body, "cache_ttl_by_status")
-	if len(blocks) == 0 {
+
+	// Convert dynamic blocks, leaving those that cannot be converted in place
+	var parts []hclwrite.Tokens
+	for _, block := range tfhcl.FindBlocksWithDynamic(body, "cache_ttl_by_status") {
+		if block.Type() != "dynamic" {
+			continue
+		}
+		if forTokens := tfhcl.DynamicBlockToMapExpression(block, "codes", "ttl"); forTokens != nil {
+			parts = append(parts, forTokens)
+			body.RemoveBlock(block)
+		}
+	}
+	if len(blocks) == 0 && len(parts) == 0 {
 		return
 	}
 
@@ -201,7 +222,7 @@ func (m *V4ToV5Migrator) transformCacheTTLByStatus(body *hclwrite.Body) {
 	// This ensures the blocks don't interfere with the new attribute
 	tfhcl.RemoveBlocksByType(body, "cache_ttl_by_status")
 
-	// If we have entries, create map attribute
+	// If we have entries, create map attribute, merged with the dynamic blocks
 	if len(entries) > 0 {
 		// Build map tokens: cache_ttl_by_status = { "200" = "3600", "404" = "300" }
 		// Use TokensForObject to get properly formatted map
@@ -239,6 +260,9 @@ func (m *V4ToV5Migrator) transformCacheTTLByStatus(body *hclwrite.Body) {
 
 		// Use TokensForObject to create properly formatted object
 		objTokens := hclwrite.TokensForObject(attrs)
-		body.SetAttributeRaw("cache_ttl_by_status", objTokens)
+		parts = append([]hclwrite.Tokens{objTokens}, parts...)
+	}
+	if len(parts) > 0 {
+		body.SetAttributeRaw("cache_ttl_by_status", tfhcl.TokensForMerge(parts))
 	}
 }
@@ -122,6 +122,69 @@ func TestV4ToV5Transformation(t *testing.T) {
 					}
 				}`,
 			},
+			{
+				Name: "With dynamic cache_ttl_by_status block merged into map",
+				Input: `resource "cloudflare_page_rule" "example" {
+  zone_id = "abc123"
+  target  = "example.com/*"
+  actions {
+    cache_ttl_by_status {
+      codes = "200"
+      ttl   = 3600
+    }
+    dynamic "cache_ttl_by_status" {
+      for_each = var.error_ttls
+      content {
+        codes = cache_ttl_by_status.key
+        ttl   = cache_ttl_by_status.value
+      }
+    }
+  }
+}`,
+				Expected: `resource "cloudflare_page_rule" "example" {
+  zone_id = "abc123"
+  target  = "example.com/*"
+  status  = "active"
+  actions = {
+    cache_ttl_by_status = merge(
+      {
+        "200" = "3600"
+      },
+      { for key, value in var.error_ttls : key => value },
+    )
+  }
+}`,
+			},
+			{
+				Name: "Dynamic cache_ttl_by_status block without for_each keeps actions block",
+				Input: `resource "cloudflare_page_rule" "example" {
+  zone_id = "abc123"
+  target  = "example.com/*"
+  actions {
+    cache_level = "cache_everything"
+    dynamic "cache_ttl_by_status" {
+      content {
+        codes = "200"
+        ttl   = 3600
+      }
+    }
+  }
+}`,
+				Expected: `resource "cloudflare_page_rule" "example" {
+  zone_id = "abc123"
+  target  = "example.com/*"
+  status  = "active"
+  actions {
+    cache_level = "cache_everything"
+    dynamic "cache_ttl_by_status" {
+      content {
+        codes = "200"
+        ttl   = 3600
+      }
+    }
+  }
+}`,
+			},
 			{
 				Name: "Removes server_side_exclude",
 				Input: `resource "cloudflare_page_rule" "example" {
@@ -1,60 +1,17 @@
 package queue
 
 import (
-	"github.com/hashicorp/hcl/v2/hclwrite"
+	_ "embed"
 
-	"github.com/cloudflare/tf-migrate/internal"
+	"github.com/cloudflare/tf-migrate/internal/rules"
 	"github.com/cloudflare/tf-migrate/internal/transform"
-	tfhcl "github.com/cloudflare/tf-migrate/internal/transform/hcl"
 )
 
-// V4ToV5Migrator handles migration of Queue resources from v4 to v5
-type V4ToV5Migrator struct{}
+//go:embed v4_to_v5.yaml
+var v4ToV5Rule []byte
 
+// NewV4ToV5Migrator registers and returns the migrator of cloudflare_queue
+// from v4 to v5, which applies the rule in v4_to_v5.yaml.
 func NewV4ToV5Migrator() transform.ResourceTransformer {
-	migrator := &V4ToV5Migrator{}
-	// Register the v4 resource name (same as v5 - no rename)
-	internal.RegisterMigrator("cloudflare_queue", "v4", "v5", migrator)
-	return migrator
+	return rules.MustRegister(v4ToV5Rule, "v4_to_v5.yaml")
 }
-
-func (m *V4ToV5Migrator) GetResourceType() string {
-	// Return the v5 resource name (same as v4)
-	return "cloudflare_queue"
-}
-
-func (m *V4ToV5Migrator) CanHandle(resourceType string) bool {
-	// Check for the v4 resource name
-	return resourceType == "cloudflare_queue"
-}
-
-func (m *V4ToV5Migrator) Preprocess(content string) string {
-	// No preprocessing needed for queue migration
-	return content
-}
-
-func (m *V4ToV5Migrator) Postprocess(content string) string {
-	// No postprocessing needed
-	return content
-}
-
-// GetResourceRename implements the ResourceRenamer interface.
-// The resource name doesn't change between v4 and v5, but we implement this
-// to ensure the resource participates in global cross-file reference updates.
-func (m *V4ToV5Migrator) GetResourceRename() ([]string, string) {
-	return []string{"cloudflare_queue"}, "cloudflare_queue"
-}
-
-func (m *V4ToV5Migrator) TransformConfig(ctx *transform.Context, block *hclwrite.Block) (*transform.TransformResult, error) {
-	// No resource type rename needed (cloudflare_queue stays the same)
-	body := block.Body()
-
-	// Only transformation: rename name → queue_name
-	tfhcl.RenameAttribute(body, "name", "queue_name")
-
-	return &transform.TransformResult{
-		Blocks:         []*hclwrite.Block{block},
-		RemoveOriginal: false,
-	}, nil
-}
-
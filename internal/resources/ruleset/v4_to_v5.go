@@ -55,22 +55,12 @@ func (m *V4ToV5Migrator) GetResourceRename() ([]string, string) {
 func (m *V4ToV5Migrator) TransformConfig(ctx *transform.Context, block *hclwrite.Block) (*transform.TransformResult, error) {
 	body := block.Body()
 
-	// Handle dynamic "rules" blocks - convert to for expression
-	// v4: dynamic "rules" { for_each = var.rules content { ... } }
-	// v5: rules = [for rule in var.rules : { ... }]
-	dynamicBlocks := hcl.FindBlocksByType(body, "dynamic")
-	for _, dynBlock := range dynamicBlocks {
-		labels := dynBlock.Labels()
-		if len(labels) > 0 && labels[0] == "rules" {
-			convertDynamicRulesToForExpression(body, dynBlock)
-		}
-	}
-
 	// First, handle special case for headers blocks within action_parameters
 	// In v5, headers is a MapNestedAttribute where the "name" field becomes the map key
-	rulesBlocks := hcl.FindBlocksByType(body, "rules")
+	// The content of dynamic "rules" blocks is handled the same way
+	rulesBlocks := hcl.FindBlocksWithDynamic(body, "rules")
 	for _, ruleBlock := range rulesBlocks {
-		ruleBody := ruleBlock.Body()
+		ruleBody := hcl.ContentBlock(ruleBlock).Body()
 		actionParamsBlocks := hcl.FindBlocksByType(ruleBody, "action_parameters")
 		for _, actionParamsBlock := range actionParamsBlocks {
 			actionParamsBody := actionParamsBlock.Body()
@@ -112,7 +102,8 @@ func (m *V4ToV5Migrator) TransformConfig(ctx *transform.Context, block *hclwrite
 
 	// Convert all nested blocks to attributes recursively.
 	// This will handle:
-	// 1. Top-level rules blocks -> rules = [...]
+	// 1. Top-level rules blocks -> rules = [...], with dynamic "rules" blocks
+	//    as for expressions: rules = [for value in var.rules : { ... }]
 	// 2. Nested action_parameters blocks inside each rule -> action_parameters = {...}
 	// 3. Nested ratelimit, exposed_credential_check blocks
 	// 4. Deeply nested blocks like overrides.rules and overrides.categories
@@ -503,154 +494,3 @@ func convertRulesMapValuesToLists(body *hclwrite.Body) {
 
 	body.SetAttributeRaw("rules", newTokens)
 }
-
-// convertDynamicRulesToForExpression converts dynamic "rules" blocks to rules = [for ...] syntax
-// v4: dynamic "rules" { for_each = var.rules content { ... } }
-// v5: rules = [for rule in var.rules : { ... }]
-func convertDynamicRulesToForExpression(body *hclwrite.Body, dynamicBlock *hclwrite.Block) {
-	dynBody := dynamicBlock.Body()
-
-	// Extract for_each expression
-	forEachAttr := dynBody.GetAttribute("for_each")
-	if forEachAttr == nil {
-		return
-	}
-	forEachExpr := forEachAttr.Expr().BuildTokens(nil)
-
-	// Get iterator name from block label (defaults to the block type name, which is "rules")
-	iteratorName := "rules"
-	if iteratorAttr := dynBody.GetAttribute("iterator"); iteratorAttr != nil {
-		// If iterator is specified, use that name
-		iteratorTokens := iteratorAttr.Expr().BuildTokens(nil)
-		iteratorName = strings.TrimSpace(string(iteratorTokens.Bytes()))
-	}
-
-	// Find the content block
-	contentBlocks := hcl.FindBlocksByType(dynBody, "content")
-	if len(contentBlocks) == 0 {
-		return
-	}
-	contentBlock := contentBlocks[0]
-	contentBody := contentBlock.Body()
-
-	// Build the for expression: [for <iterator> in <for_each> : { ... }]
-	tokens := hclwrite.Tokens{
-		{Type: hclsyntax.TokenOBrack, Bytes: []byte{'['}},
-		{Type: hclsyntax.TokenIdent, Bytes: []byte("for ")},
-		{Type: hclsyntax.TokenIdent, Bytes: []byte(iteratorName)},
-		{Type: hclsyntax.TokenIdent, Bytes: []byte(" in ")},
-	}
-	tokens = append(tokens, forEachExpr...)
-	tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenIdent, Bytes: []byte(" : ")})
-	tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenOBrace, Bytes: []byte{'{'}})
-	tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenNewline, Bytes: []byte{'\n'}})
-
-	// Add all attributes from the content block
-	// Need to transform references from iterator.value.field to iterator.field
-	attrs := contentBody.Attributes()
-	var attrNames []string
-	for attrName := range attrs {
-		attrNames = append(attrNames, attrName)
-	}
-	sort.Strings(attrNames)
-
-	for _, attrName := range attrNames {
-		attr := attrs[attrName]
-		tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenIdent, Bytes: []byte("    ")}) // indent
-		tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenIdent, Bytes: []byte(attrName)})
-		tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenIdent, Bytes: []byte(" = ")})
-
-		// Transform the expression to remove .value
-		// e.g., rules.value.expression -> rules.expression
-		exprTokens := attr.Expr().BuildTokens(nil)
-		transformedTokens := transformIteratorValueReferences(exprTokens, iteratorName)
-		tokens = append(tokens, transformedTokens...)
-
-		tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenNewline, Bytes: []byte{'\n'}})
-	}
-
-	// Handle nested blocks in content (like action_parameters)
-	for _, nestedBlock := range contentBody.Blocks() {
-		tokens = append(tokens, buildNestedBlockTokens(nestedBlock, "    ", iteratorName)...)
-	}
-
-	tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenIdent, Bytes: []byte("  ")}) // indent
-	tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenCBrace, Bytes: []byte{'}'}})
-	tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenCBrack, Bytes: []byte{']'}})
-
-	// Set the rules attribute with the for expression
-	body.SetAttributeRaw("rules", tokens)
-
-	// Remove the dynamic block
-	body.RemoveBlock(dynamicBlock)
-}
-
-// transformIteratorValueReferences transforms iterator.value.field to iterator.field
-func transformIteratorValueReferences(tokens hclwrite.Tokens, iteratorName string) hclwrite.Tokens {
-	result := hclwrite.Tokens{}
-	i := 0
-	for i < len(tokens) {
-		token := tokens[i]
-
-		// Look for pattern: <iteratorName> . value . <field>
-		if i+4 < len(tokens) &&
-			string(token.Bytes) == iteratorName &&
-			tokens[i+1].Type == hclsyntax.TokenDot &&
-			string(tokens[i+2].Bytes) == "value" &&
-			tokens[i+3].Type == hclsyntax.TokenDot {
-			// Skip the ".value." part (keep iterator and first dot, skip "value" and second dot)
-			result = append(result, token)       // iterator name
-			result = append(result, tokens[i+1]) // first dot
-			// Skip tokens[i+2] (value) and tokens[i+3] (second dot)
-			i += 4
-			continue
-		}
-
-		result = append(result, token)
-		i++
-	}
-	return result
-}
-
-// buildNestedBlockTokens recursively builds tokens for nested blocks
-func buildNestedBlockTokens(block *hclwrite.Block, indent string, iteratorName string) hclwrite.Tokens {
-	tokens := hclwrite.Tokens{}
-
-	tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenIdent, Bytes: []byte(indent)})
-	tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenIdent, Bytes: []byte(block.Type())})
-	tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenIdent, Bytes: []byte(" = ")})
-	tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenOBrace, Bytes: []byte{'{'}})
-	tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenNewline, Bytes: []byte{'\n'}})
-
-	blockBody := block.Body()
-	attrs := blockBody.Attributes()
-	var attrNames []string
-	for attrName := range attrs {
-		attrNames = append(attrNames, attrName)
-	}
-	sort.Strings(attrNames)
-
-	for _, attrName := range attrNames {
-		attr := attrs[attrName]
-		tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenIdent, Bytes: []byte(indent + "  ")})
-		tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenIdent, Bytes: []byte(attrName)})
-		tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenIdent, Bytes: []byte(" = ")})
-
-		exprTokens := attr.Expr().BuildTokens(nil)
-		transformedTokens := transformIteratorValueReferences(exprTokens, iteratorName)
-		tokens = append(tokens, transformedTokens...)
-
-		tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenNewline, Bytes: []byte{'\n'}})
-	}
-
-	// Handle nested blocks recursively
-	for _, nestedBlock := range blockBody.Blocks() {
-		tokens = append(tokens, buildNestedBlockTokens(nestedBlock, indent+"  ", iteratorName)...)
-	}
-
-	tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenIdent, Bytes: []byte(indent)})
-	tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenCBrace, Bytes: []byte{'}'}})
-	tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenNewline, Bytes: []byte{'\n'}})
-
-	return tokens
-}
@@ -276,11 +276,11 @@ func TestV4ToV5Transformation(t *testing.T) {
   kind    = "zone"
   phase   = "http_request_firewall_custom"
 
-  rules = [for rules in local.rule_configs : {
-    action      = rules.action
-    description = rules.description
+  rules = [for value in local.rule_configs : {
+    action      = value.action
+    expression  = value.expression
+    description = value.description
     enabled     = true
-    expression  = rules.expression
   }]
 }`,
 			},
@@ -311,12 +311,12 @@ func TestV4ToV5Transformation(t *testing.T) {
   kind    = "zone"
   phase   = "http_request_firewall_custom"
 
-  rules = [for rules in var.rules : {
-    action      = rules.action
-    description = rules.description
-    expression  = rules.expression
+  rules = [for value in var.rules : {
+    action      = value.action
+    expression  = value.expression
+    description = value.description
     action_parameters = {
-      id = rules.id
+      id = value.id
     }
   }]
 }`,
@@ -345,10 +345,10 @@ func TestV4ToV5Transformation(t *testing.T) {
   kind    = "zone"
   phase   = "http_request_firewall_custom"
 
-  rules = [for rule in var.firewall_rules : {
-    action      = rule.action
-    description = rule.desc
-    expression  = rule.expression
+  rules = [for value in var.firewall_rules : {
+    action      = value.action
+    expression  = value.expression
+    description = value.desc
   }]
 }`,
 			},
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/hashicorp/hcl/v2/hclwrite"
 
 	"github.com/cloudflare/tf-migrate/internal"
@@ -101,7 +102,6 @@ Use resource tags via cloudflare_workers_script_tags if needed.`,
 // transformBindings converts v4 binding blocks and dispatch_namespace attribute to v5 unified bindings list
 func (m *V4ToV5Migrator) transformBindings(body *hclwrite.Body) {
 	var bindingObjects []string
-	var dynamicExprs []string
 
 	// Map of v4 block types to v5 binding types
 	bindingTypeMap := map[string]string{
@@ -117,7 +117,15 @@ func (m *V4ToV5Migrator) transformBindings(body *hclwrite.Body) {
 		"hyperdrive_config_binding": "hyperdrive",
 	}
 
-	// Process blocks in document order to preserve binding order
+	// Process blocks in document order to preserve binding order. Static bindings
+	// are collected into tuples between the expressions of dynamic blocks.
+	var bindingLists []string
+	flushStatic := func() {
+		if len(bindingObjects) > 0 {
+			bindingLists = append(bindingLists, "[\n  "+joinBindings(bindingObjects)+"\n]")
+			bindingObjects = nil
+		}
+	}
 	for _, block := range body.Blocks() {
 		// Handle dynamic blocks
 		if block.Type() == "dynamic" {
@@ -126,7 +134,8 @@ func (m *V4ToV5Migrator) transformBindings(body *hclwrite.Body) {
 				if v5BindingType, ok := bindingTypeMap[labels[0]]; ok {
 					dynamicExpr := m.convertDynamicBindingToExpr(block, v5BindingType)
 					if dynamicExpr != "" {
-						dynamicExprs = append(dynamicExprs, dynamicExpr)
+						flushStatic()
+						bindingLists = append(bindingLists, dynamicExpr)
 					}
 				}
 			}
@@ -147,20 +156,18 @@ func (m *V4ToV5Migrator) transformBindings(body *hclwrite.Body) {
 		}
 		body.RemoveAttribute("dispatch_namespace")
 	}
+	flushStatic()
 
 	// Create unified bindings list if we have any bindings
-	if len(bindingObjects) > 0 || len(dynamicExprs) > 0 {
-		var bindingsValue string
-		if len(dynamicExprs) > 0 {
+	if len(bindingLists) > 0 {
+		bindingsValue := bindingLists[0]
+		if len(bindingLists) > 1 {
 			// Use concat when there are dynamic expressions
-			staticPart := "[\n  " + joinBindings(bindingObjects) + "\n]"
-			bindingsValue = "concat(" + staticPart + ", " + dynamicExprs[0]
-			for i := 1; i < len(dynamicExprs); i++ {
-				bindingsValue += ", " + dynamicExprs[i]
+			bindingsValue = "concat(" + bindingLists[0]
+			for i := 1; i < len(bindingLists); i++ {
+				bindingsValue += ", " + bindingLists[i]
 			}
 			bindingsValue += ")"
-		} else {
-			bindingsValue = "[\n  " + joinBindings(bindingObjects) + "\n]"
 		}
 		// Use SetAttributeFromExpressionString to set the bindings array
 		tfhcl.SetAttributeFromExpressionString(body, "bindings", bindingsValue)
@@ -175,73 +182,74 @@ func (m *V4ToV5Migrator) transformBindings(body *hclwrite.Body) {
 	removeDynamicBlocks(body, bindingTypeMap)
 }
 
-// convertDynamicBindingToExpr converts a dynamic binding block to a conditional expression
-// e.g., dynamic "queue_binding" { for_each = cond ? [1] : [] content { ... } }
+// convertDynamicBindingToExpr converts a dynamic binding block to a list expression.
+// A dynamic block toggled by a condition whose content doesn't use the iterator,
+// e.g., dynamic "queue_binding" { for_each = cond ? [1] : [] content { ... } },
 // becomes: cond ? [{ type = "queue" ... }] : []
+// Any other dynamic block becomes a for expression over its for_each value.
 func (m *V4ToV5Migrator) convertDynamicBindingToExpr(block *hclwrite.Block, bindingType string) string {
-	blockBody := block.Body()
-
-	// Get the for_each attribute
-	forEachAttr := blockBody.GetAttribute("for_each")
-	if forEachAttr == nil {
+	var bindingObj string
+	forExpr := tfhcl.DynamicBlockToForExpression(block, func(content *hclwrite.Block) hclwrite.Tokens {
+		// Convert the content block to a binding object
+		bindingObj = m.convertBindingBlockToObject(content, bindingType)
+		tokens, err := tfhcl.TokensForExpressionString(bindingObj)
+		if err != nil {
+			bindingObj = ""
+		}
+		return tokens
+	})
+	if forExpr == nil || bindingObj == "" {
 		return ""
 	}
 
-	forEachExpr := exprToString(forEachAttr.Expr())
-
-	// Find the content block
-	var contentBlock *hclwrite.Block
-	for _, b := range blockBody.Blocks() {
-		if b.Type() == "content" {
-			contentBlock = b
-			break
-		}
+	// Extract condition from for_each expression if it's a ternary like "cond ? [1] : []"
+	forEachExpr := exprToString(block.Body().GetAttribute("for_each").Expr())
+	if condition := extractCondition(forEachExpr); condition != "" && !usesIterator(block, bindingObj) {
+		return condition + " ? [" + bindingObj + "] : []"
 	}
 
-	if contentBlock == nil {
+	return string(forExpr.Bytes())
+}
+
+// extractCondition returns the condition of a for_each expression that toggles a
+// single block, e.g., "each.value.use_queue ? [1] : []" → "each.value.use_queue"
+func extractCondition(expr string) string {
+	parsed, diags := hclsyntax.ParseExpression([]byte(expr), "for_each", hcl.InitialPos)
+	if diags.HasErrors() {
 		return ""
 	}
-
-	// Convert the content block to a binding object
-	bindingObj := m.convertBindingBlockToObject(contentBlock, bindingType)
-	if bindingObj == "" {
+	cond, ok := parsed.(*hclsyntax.ConditionalExpr)
+	if !ok {
 		return ""
 	}
-
-	// Extract condition from for_each expression if it's a ternary like "cond ? [1] : []"
-	condition := extractCondition(forEachExpr)
-	if condition != "" {
-		return condition + " ? [" + bindingObj + "] : []"
+	trueList, ok := cond.TrueResult.(*hclsyntax.TupleConsExpr)
+	if !ok || len(trueList.Exprs) != 1 {
+		return ""
 	}
-
-	// Fallback: wrap in the for_each expression
-	return forEachExpr + " != [] ? [" + bindingObj + "] : []"
+	falseList, ok := cond.FalseResult.(*hclsyntax.TupleConsExpr)
+	if !ok || len(falseList.Exprs) != 0 {
+		return ""
+	}
+	return string(cond.Condition.Range().SliceBytes([]byte(expr)))
 }
 
-// extractCondition tries to extract the condition from a ternary for_each expression
-// e.g., "each.value.use_queue ? [1] : []" → "each.value.use_queue"
-func extractCondition(expr string) string {
-	// Look for pattern: "condition ? [...] : []"
-	if idx := -1; idx < len(expr) {
-		for i := 0; i < len(expr); i++ {
-			if expr[i] == '?' {
-				idx = i
-				break
-			}
-		}
-		if idx != -1 {
-			condition := expr[:idx]
-			// Trim whitespace
-			for len(condition) > 0 && (condition[0] == ' ' || condition[0] == '\t') {
-				condition = condition[1:]
-			}
-			for len(condition) > 0 && (condition[len(condition)-1] == ' ' || condition[len(condition)-1] == '\t') {
-				condition = condition[:len(condition)-1]
-			}
-			return condition
+// usesIterator reports whether a binding object built from the content of a
+// dynamic block refers to the block's iterator
+func usesIterator(block *hclwrite.Block, bindingObj string) bool {
+	iterator := block.Labels()[0]
+	if iteratorAttr := block.Body().GetAttribute("iterator"); iteratorAttr != nil {
+		iterator = tfhcl.TraversalString(iteratorAttr)
+	}
+	tokens, err := tfhcl.TokensForExpressionString(bindingObj)
+	if err != nil {
+		return true
+	}
+	for i, token := range tokens {
+		if string(token.Bytes) == iterator && (i == 0 || tokens[i-1].Type != hclsyntax.TokenDot) {
+			return true
 		}
 	}
-	return ""
+	return false
 }
 
 // convertBindingBlockToObject converts a v4 binding block to a v5 binding object string
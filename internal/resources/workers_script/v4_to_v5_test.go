@@ -282,6 +282,46 @@ func TestWorkerScriptConfigTransform_Bindings(t *testing.T) {
       id   = "db-456"
     }
   ]
+}`,
+		},
+		{
+			Name: "dynamic binding over a map becomes a for expression",
+			Input: `resource "cloudflare_workers_script" "example" {
+  account_id = "f037e56e89293a057740de681ac9abbe"
+  name       = "my-worker"
+  content    = "addEventListener('fetch', event => { event.respondWith(new Response('Hello')); });"
+
+  dynamic "plain_text_binding" {
+    for_each = var.enabled ? var.vars : {}
+    iterator = binding
+    content {
+      name = binding.key
+      text = binding.value
+    }
+  }
+
+  dynamic "queue_binding" {
+    for_each = var.use_queue ? [1] : []
+    content {
+      binding = "QUEUE"
+      queue   = "my-queue"
+    }
+  }
+}`,
+			Expected: `resource "cloudflare_workers_script" "example" {
+  account_id = "f037e56e89293a057740de681ac9abbe"
+  content    = "addEventListener('fetch', event => { event.respondWith(new Response('Hello')); });"
+
+  script_name = "my-worker"
+  bindings = concat([for key, value in var.enabled ? var.vars : {} : {
+    type = "plain_text"
+    name = key
+    text = value
+    }], var.use_queue ? [{
+    type       = "queue"
+    name       = "QUEUE"
+    queue_name = "my-queue"
+  }] : [])
 }`,
 		},
 	}
@@ -69,7 +69,11 @@ func (m *V4ToV5Migrator) TransformConfig(ctx *transform.Context, block *hclwrite
 	tfhcl.EnsureAttribute(body, "type", "self_hosted")
 
 	// Get the application type for type-gated attribute filtering
+	// A type set from a variable or local is evaluated when its value is known.
 	appType := tfhcl.ExtractStringFromAttribute(body.GetAttribute("type"))
+	if value, ok := ctx.EvaluateString(body.GetAttribute("type")); ok {
+		appType = value
+	}
 
 	// V5 changed the default for http_only_cookie_attribute from false to true
 	// Explicitly set to false to maintain v4 behavior when not specified
@@ -134,6 +138,9 @@ func (m *V4ToV5Migrator) TransformConfig(ctx *transform.Context, block *hclwrite
 	m.transformSaasAppBlock(body)
 	m.transformScimConfigBlock(body)
 	m.transformTargetCriteriaBlocks(body)
+	for _, blockType := range []string{"saas_app", "scim_config", "target_criteria"} {
+		ctx.WarnDynamicBlocks(body, "cloudflare_zero_trust_access_application."+resourceName, blockType)
+	}
 
 	// Build result blocks
 	blocks := []*hclwrite.Block{block}
@@ -284,19 +291,22 @@ func sortOIDCScopes(strings []string) {
 	})
 }
 
+// transformSaasAppBlock converts saas_app and its nested blocks to attributes.
+// Dynamic blocks are transformed through their content, and become for
+// expressions when the blocks are converted.
 func (m *V4ToV5Migrator) transformSaasAppBlock(body *hclwrite.Body) {
-	saasAppBlocks := tfhcl.FindBlocksByType(body, "saas_app")
+	saasAppBlocks := tfhcl.FindBlocksWithDynamic(body, "saas_app")
 	if len(saasAppBlocks) == 0 {
 		return
 	}
 
 	for _, saasAppBlock := range saasAppBlocks {
-		saasAppBody := saasAppBlock.Body()
+		saasAppBody := tfhcl.ContentBlock(saasAppBlock).Body()
 
 		// Process custom_attribute blocks before converting to list
-		customAttrBlocks := tfhcl.FindBlocksByType(saasAppBody, "custom_attribute")
+		customAttrBlocks := tfhcl.FindBlocksWithDynamic(saasAppBody, "custom_attribute")
 		for _, customAttrBlock := range customAttrBlocks {
-			customAttrBody := customAttrBlock.Body()
+			customAttrBody := tfhcl.ContentBlock(customAttrBlock).Body()
 			// Convert source block
 			if sourceBlock := tfhcl.FindBlockByType(customAttrBody, "source"); sourceBlock != nil {
 				sourceBody := sourceBlock.Body()
@@ -313,9 +323,9 @@ func (m *V4ToV5Migrator) transformSaasAppBlock(body *hclwrite.Body) {
 		}
 
 		// Process custom_claim blocks before converting to list
-		customClaimBlocks := tfhcl.FindBlocksByType(saasAppBody, "custom_claim")
+		customClaimBlocks := tfhcl.FindBlocksWithDynamic(saasAppBody, "custom_claim")
 		for _, customClaimBlock := range customClaimBlocks {
-			customClaimBody := customClaimBlock.Body()
+			customClaimBody := tfhcl.ContentBlock(customClaimBlock).Body()
 			// Convert source block to attribute
 			// NOTE: For custom_claims (OIDC), name_by_idp stays as a map, so no transformation needed
 			tfhcl.ConvertSingleBlockToAttribute(customClaimBody, "source", "source")
@@ -337,14 +347,16 @@ func (m *V4ToV5Migrator) transformSaasAppBlock(body *hclwrite.Body) {
 	tfhcl.ConvertSingleBlockToAttribute(body, "saas_app", "saas_app")
 }
 
+// transformScimConfigBlock converts scim_config and its nested blocks to
+// attributes, dynamic blocks included.
 func (m *V4ToV5Migrator) transformScimConfigBlock(body *hclwrite.Body) {
-	scimConfigBlocks := tfhcl.FindBlocksByType(body, "scim_config")
+	scimConfigBlocks := tfhcl.FindBlocksWithDynamic(body, "scim_config")
 	if len(scimConfigBlocks) == 0 {
 		return
 	}
 
 	for _, scimConfigBlock := range scimConfigBlocks {
-		scimConfigBody := scimConfigBlock.Body()
+		scimConfigBody := tfhcl.ContentBlock(scimConfigBlock).Body()
 
 		// Process authentication block
 		if authBlock := tfhcl.FindBlockByType(scimConfigBody, "authentication"); authBlock != nil {
@@ -357,9 +369,9 @@ func (m *V4ToV5Migrator) transformScimConfigBlock(body *hclwrite.Body) {
 		tfhcl.ConvertSingleBlockToAttribute(scimConfigBody, "authentication", "authentication")
 
 		// Process mappings blocks
-		mappingsBlocks := tfhcl.FindBlocksByType(scimConfigBody, "mappings")
+		mappingsBlocks := tfhcl.FindBlocksWithDynamic(scimConfigBody, "mappings")
 		for _, mappingBlock := range mappingsBlocks {
-			mappingBody := mappingBlock.Body()
+			mappingBody := tfhcl.ContentBlock(mappingBlock).Body()
 			// Convert operations block to attribute
 			tfhcl.ConvertSingleBlockToAttribute(mappingBody, "operations", "operations")
 		}
@@ -374,11 +386,11 @@ func (m *V4ToV5Migrator) transformScimConfigBlock(body *hclwrite.Body) {
 
 func (m *V4ToV5Migrator) transformTargetCriteriaBlocks(body *hclwrite.Body) {
 	// Get all target_criteria blocks
-	targetCriteriaBlocks := tfhcl.FindBlocksByType(body, "target_criteria")
+	targetCriteriaBlocks := tfhcl.FindBlocksWithDynamic(body, "target_criteria")
 
 	// Convert nested target_attributes blocks within each target_criteria block to a map
 	for _, tcBlock := range targetCriteriaBlocks {
-		tcBody := tcBlock.Body()
+		tcBody := tfhcl.ContentBlock(tcBlock).Body()
 		// Convert target_attributes blocks to map attribute
 		m.convertTargetAttributesToMap(tcBody)
 	}
@@ -388,75 +400,14 @@ func (m *V4ToV5Migrator) transformTargetCriteriaBlocks(body *hclwrite.Body) {
 }
 
 // convertTargetAttributesToMap converts target_attributes blocks to a map attribute
-// where keys are the "name" values and values are the "values" arrays
+// where keys are the "name" values and values are the "values" arrays.
+// Dynamic target_attributes blocks become for expressions merged into the map.
 func (m *V4ToV5Migrator) convertTargetAttributesToMap(body *hclwrite.Body) {
-	targetAttrBlocks := tfhcl.FindBlocksByType(body, "target_attributes")
-	if len(targetAttrBlocks) == 0 {
+	mapTokens, targetAttrBlocks := tfhcl.BuildMapFromBlocks(tfhcl.FindBlocksWithDynamic(body, "target_attributes"), "name", "values")
+	if mapTokens == nil {
 		return
 	}
 
-	// Build map tokens
-	var mapTokens hclwrite.Tokens
-
-	// Opening brace
-	mapTokens = append(mapTokens, &hclwrite.Token{
-		Type:  hclsyntax.TokenOBrace,
-		Bytes: []byte("{"),
-	})
-	mapTokens = append(mapTokens, &hclwrite.Token{
-		Type:  hclsyntax.TokenNewline,
-		Bytes: []byte("\n"),
-	})
-
-	// Process each target_attributes block
-	for _, block := range targetAttrBlocks {
-		blockBody := block.Body()
-
-		// Get the name attribute (the map key)
-		nameAttr := blockBody.GetAttribute("name")
-		if nameAttr == nil {
-			continue
-		}
-
-		// Get the values attribute (the map value)
-		valuesAttr := blockBody.GetAttribute("values")
-		if valuesAttr == nil {
-			continue
-		}
-
-		// Add indentation
-		mapTokens = append(mapTokens, &hclwrite.Token{
-			Type:  hclsyntax.TokenIdent,
-			Bytes: []byte("  "),
-		})
-
-		// Add the key (name value as a quoted string)
-		nameTokens := nameAttr.Expr().BuildTokens(nil)
-		mapTokens = append(mapTokens, nameTokens...)
-
-		// Add equals sign
-		mapTokens = append(mapTokens, &hclwrite.Token{
-			Type:  hclsyntax.TokenEqual,
-			Bytes: []byte(" = "),
-		})
-
-		// Add the value (values array)
-		valuesTokens := valuesAttr.Expr().BuildTokens(nil)
-		mapTokens = append(mapTokens, valuesTokens...)
-
-		// Add newline
-		mapTokens = append(mapTokens, &hclwrite.Token{
-			Type:  hclsyntax.TokenNewline,
-			Bytes: []byte("\n"),
-		})
-	}
-
-	// Closing brace
-	mapTokens = append(mapTokens, &hclwrite.Token{
-		Type:  hclsyntax.TokenCBrace,
-		Bytes: []byte("}"),
-	})
-
 	// Set the map attribute
 	body.SetAttributeRaw("target_attributes", mapTokens)
 
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/cloudflare/tf-migrate/internal/testhelpers"
+	tfhcl "github.com/cloudflare/tf-migrate/internal/transform/hcl"
 )
 
 func TestV4ToV5Transformation(t *testing.T) {
@@ -1440,7 +1441,7 @@ moved {
 }`,
 			},
 			{
-				Name: "dynamic block preserved",
+				Name: "dynamic destinations converted to for expression",
 				Input: `resource "cloudflare_zero_trust_access_application" "app" {
   account_id = "abc123"
   name       = "Test App"
@@ -1458,12 +1459,46 @@ moved {
   name       = "Test App"
   type       = "warp"
 
-  dynamic "destinations" {
-    for_each = var.destinations
+  destinations = [for value in var.destinations : {
+    uri = value.uri
+  }]
+}`,
+			},
+			{
+				Name: "dynamic target_criteria with dynamic target_attributes",
+				Input: `resource "cloudflare_zero_trust_access_application" "app" {
+  account_id = "abc123"
+  name       = "SSH App"
+  type       = "ssh"
+
+  dynamic "target_criteria" {
+    for_each = var.targets
+    iterator = target
     content {
-      uri = destinations.value.uri
+      port     = target.value.port
+      protocol = "SSH"
+
+      dynamic "target_attributes" {
+        for_each = target.value.attributes
+        content {
+          name   = target_attributes.key
+          values = target_attributes.value
+        }
+      }
     }
   }
+}`,
+				Expected: `resource "cloudflare_zero_trust_access_application" "app" {
+  account_id = "abc123"
+  name       = "SSH App"
+  type       = "ssh"
+
+  http_only_cookie_attribute = false
+  target_criteria = [for target_value in var.targets : {
+    port              = target_value.port
+    protocol          = "SSH"
+    target_attributes = { for key, value in target_value.attributes : key => value }
+  }]
 }`,
 			},
 			{
@@ -1970,6 +2005,28 @@ func TestSaasAppStripping(t *testing.T) {
   self_hosted_domains        = ["app.example.com"]
 }`,
 		},
+		{
+			Name: "variable type resolved from tfvars — invalid attributes removed",
+			Input: `resource "cloudflare_zero_trust_access_application" "dynamic_app" {
+  account_id = "abc123"
+  name       = "Dynamic App"
+  domain     = "app.example.com"
+  type       = var.app_type
+
+  http_only_cookie_attribute = false
+  self_hosted_domains        = ["app.example.com"]
+}`,
+			Expected: `resource "cloudflare_zero_trust_access_application" "dynamic_app" {
+  account_id = "abc123"
+  name       = "Dynamic App"
+  domain     = "app.example.com"
+  type       = var.app_type
+}`,
+			EvalContext: tfhcl.BuildEvalContext(
+				[]tfhcl.SourceFile{{Name: "variables.tf", Content: []byte("variable \"app_type\" {}\n")}},
+				[]tfhcl.SourceFile{{Name: "terraform.tfvars", Content: []byte("app_type = \"bookmark\"\n")}},
+			),
+		},
 	}
 
 	testhelpers.RunConfigTransformTests(t, tests, migrator)
@@ -812,30 +812,28 @@ func (m *V4ToV5Migrator) TransformConfig(ctx *transform.Context, block *hclwrite
 
 	body := block.Body()
 
-	// Check for dynamic blocks for include/exclude/require which can't be auto-migrated
-	dynamicConditions := m.detectDynamicConditionBlocks(body)
-	if len(dynamicConditions) > 0 {
+	// 1. First convert include/exclude/require blocks to attributes
+	// This handles both v4 formats:
+	// - Format A: include { email = ["a@example.com"] }
+	// - Format B: include { email = { email = "a@example.com" } } (cf-terraforming)
+	// ConvertBlocksToArrayAttribute collects ALL blocks first, then creates single attribute
+	// Dynamic blocks become for expressions; those whose content can't be transformed are
+	// left in place and reported
+	if dynamicConditions := m.convertConditionBlocksToAttributes(body); len(dynamicConditions) > 0 {
 		ctx.Diagnostics = append(ctx.Diagnostics, &hcl.Diagnostic{
 			Severity: hcl.DiagWarning,
 			Summary:  fmt.Sprintf("Dynamic block requires manual migration: cloudflare_zero_trust_access_group.%s", resourceName),
-			Detail: fmt.Sprintf(`Dynamic blocks for %s cannot be automatically migrated to v5.
+			Detail: fmt.Sprintf(`Dynamic blocks for %s could not be automatically migrated to v5.
 
 The v5 provider uses list attributes for include/exclude/require instead of blocks.
-Dynamic blocks are not supported with list attributes.
+The content of these dynamic blocks is too complex to rewrite as a for expression.
 
 To migrate manually:
-  1. Convert dynamic blocks to static lists, OR
+  1. Rewrite the dynamic blocks as a for expression in the list attribute, OR
   2. Use for_each at the resource level instead of dynamic blocks`, strings.Join(dynamicConditions, ", ")),
 		})
 	}
 
-	// 1. First convert include/exclude/require blocks to attributes
-	// This handles both v4 formats:
-	// - Format A: include { email = ["a@example.com"] }
-	// - Format B: include { email = { email = "a@example.com" } } (cf-terraforming)
-	// ConvertBlocksToArrayAttribute collects ALL blocks first, then creates single attribute
-	m.convertConditionBlocksToAttributes(body)
-
 	// 2. Transform condition attributes (boolean selectors, array expansion, etc.)
 	m.transformConditionAttributes(body)
 
@@ -854,53 +852,176 @@ To migrate manually:
 	}, nil
 }
 
-// detectDynamicConditionBlocks checks for dynamic blocks for include/exclude/require
-// Returns the list of dynamic block names found
-func (m *V4ToV5Migrator) detectDynamicConditionBlocks(body *hclwrite.Body) []string {
-	var dynamicConditions []string
-	conditionNames := map[string]bool{"include": true, "exclude": true, "require": true}
-
-	for _, block := range body.Blocks() {
-		if block.Type() == "dynamic" && len(block.Labels()) > 0 {
-			if conditionNames[block.Labels()[0]] {
-				dynamicConditions = append(dynamicConditions, block.Labels()[0])
-			}
-		}
-	}
-
-	return dynamicConditions
-}
-
 // convertConditionBlocksToAttributes converts include/exclude/require blocks to attribute arrays
 // This handles both v4 formats by collecting ALL blocks of the same type first
 // It also handles nested blocks (like github, gsuite, etc.) by converting them to attributes
-func (m *V4ToV5Migrator) convertConditionBlocksToAttributes(body *hclwrite.Body) {
+// Returns the names of dynamic blocks that could not be converted
+func (m *V4ToV5Migrator) convertConditionBlocksToAttributes(body *hclwrite.Body) []string {
 	conditionNames := []string{"include", "exclude", "require"}
+	var dynamicConditions []string
 
 	for _, condName := range conditionNames {
-		blocks := tfhcl.FindBlocksByType(body, condName)
+		blocks := tfhcl.FindBlocksWithDynamic(body, condName)
 		if len(blocks) == 0 {
 			continue
 		}
 
-		// Build array of objects from blocks, handling nested blocks
-		var objectTokens []hclwrite.Tokens
-		for _, block := range blocks {
+		// Dynamic blocks are converted on a copy, so that one whose content can't be
+		// transformed is left exactly as it was when it is reported
+		originals := make(map[*hclwrite.Block]*hclwrite.Block)
+		for i, block := range blocks {
+			if block.Type() == "dynamic" {
+				if clone := tfhcl.CloneBlock(block); clone != nil {
+					originals[clone] = block
+					blocks[i] = clone
+				}
+			}
 			// Convert nested blocks to attributes first (github, gsuite, azure, okta, saml)
-			m.convertNestedBlocksToAttributes(block.Body())
+			m.convertNestedBlocksToAttributes(tfhcl.ContentBlock(blocks[i]).Body())
+		}
 
-			// Now build object from the block (which only has attributes now)
-			objTokens := tfhcl.BuildObjectFromBlock(block)
-			objectTokens = append(objectTokens, objTokens)
+		var arrayTokens hclwrite.Tokens
+		var converted []*hclwrite.Block
+		if !hasDynamicBlock(blocks) {
+			// Build array of objects from blocks (which only have attributes now);
+			// transformConditionAttributes rewrites the objects afterwards
+			var objectTokens []hclwrite.Tokens
+			for _, block := range blocks {
+				objectTokens = append(objectTokens, tfhcl.BuildObjectFromBlock(block))
+			}
+			arrayTokens = tfhcl.BuildArrayFromObjects(objectTokens)
+			converted = blocks
+		} else {
+			arrayTokens, converted = m.buildConditionListWithDynamic(condName, blocks)
+			if len(converted) < len(blocks) {
+				dynamicConditions = append(dynamicConditions, condName)
+			}
+		}
+		if len(converted) == 0 {
+			continue
 		}
 
-		// Build array from objects and set as attribute
-		arrayTokens := tfhcl.BuildArrayFromObjects(objectTokens)
 		body.SetAttributeRaw(condName, arrayTokens)
 
 		// Remove original blocks
-		tfhcl.RemoveBlocksByType(body, condName)
+		for _, block := range converted {
+			if original, ok := originals[block]; ok {
+				block = original
+			}
+			body.RemoveBlock(block)
+		}
+	}
+
+	return dynamicConditions
+}
+
+// hasDynamicBlock reports whether any of blocks is a dynamic block
+func hasDynamicBlock(blocks []*hclwrite.Block) bool {
+	for _, block := range blocks {
+		if block.Type() == "dynamic" {
+			return true
+		}
+	}
+	return false
+}
+
+// buildConditionListWithDynamic builds the v5 condition list for static and dynamic
+// condition blocks, transformed already since transformConditionAttributes leaves
+// concat() and for expressions alone. Each dynamic block becomes a for expression
+// over its for_each value:
+//
+//	dynamic "include" {
+//	  for_each = var.emails
+//	  content {
+//	    email = [include.value]
+//	  }
+//	}
+//
+// becomes [for value in var.emails : { email = { email = value } }], wrapped in
+// flatten() when the content expands to several conditions. Static and dynamic
+// parts are merged with concat() in source order. Dynamic blocks whose content
+// can't be transformed are left out; the converted blocks are returned.
+func (m *V4ToV5Migrator) buildConditionListWithDynamic(condName string, blocks []*hclwrite.Block) (hclwrite.Tokens, []*hclwrite.Block) {
+	var lists []hclwrite.Tokens
+	var converted []*hclwrite.Block
+	var static []hclwrite.Tokens
+
+	flushStatic := func() {
+		if len(static) == 0 {
+			return
+		}
+		arrayTokens := tfhcl.BuildArrayFromObjects(static)
+		if transformed := m.transformConditionTokens(condName, arrayTokens); transformed != nil {
+			arrayTokens = transformed
+		}
+		lists = append(lists, arrayTokens)
+		static = nil
+	}
+
+	for _, block := range blocks {
+		if block.Type() != "dynamic" {
+			static = append(static, tfhcl.BuildObjectFromBlock(block))
+			converted = append(converted, block)
+			continue
+		}
+
+		ok, flatten := true, false
+		forTokens := tfhcl.DynamicBlockToForExpression(block, func(content *hclwrite.Block) hclwrite.Tokens {
+			element, expanded := m.transformConditionElement(condName, content)
+			ok, flatten = element != nil, expanded
+			return element
+		})
+		if !ok {
+			continue
+		}
+		if flatten {
+			forTokens = append(append(hclwrite.Tokens{
+				{Type: hclsyntax.TokenIdent, Bytes: []byte("flatten")},
+				{Type: hclsyntax.TokenOParen, Bytes: []byte("(")},
+			}, forTokens...), &hclwrite.Token{Type: hclsyntax.TokenCParen, Bytes: []byte(")")})
+		}
+
+		flushStatic()
+		lists = append(lists, forTokens)
+		converted = append(converted, block)
+	}
+	flushStatic()
+
+	if len(lists) == 1 {
+		return lists[0], converted
+	}
+	return tfhcl.BuildConcatExpression(lists), converted
+}
+
+// transformConditionElement transforms the content block of a dynamic condition
+// block into the element of a for expression. A content block that expands to a
+// single condition yields that condition; otherwise the list of conditions is
+// returned and expanded is true. Returns nil when the content can't be transformed.
+func (m *V4ToV5Migrator) transformConditionElement(condName string, content *hclwrite.Block) (element hclwrite.Tokens, expanded bool) {
+	listTokens := tfhcl.BuildArrayFromObjects([]hclwrite.Tokens{tfhcl.BuildObjectFromBlock(content)})
+	transformed := m.transformConditionTokens(condName, listTokens)
+	if transformed == nil {
+		return nil, false
+	}
+
+	src := hclwrite.Format(transformed.Bytes())
+	expr, diags := hclsyntax.ParseExpression(src, condName, hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, false
+	}
+	exprSrc := string(src)
+	if tup, ok := expr.(*hclsyntax.TupleConsExpr); ok && len(tup.Exprs) == 1 {
+		exprSrc = string(tup.Exprs[0].Range().SliceBytes(src))
+	} else {
+		expanded = true
+	}
+
+	// Tokens are re-parsed so the iterator references in them can be rewritten
+	element, err := tfhcl.TokensForExpressionString(exprSrc)
+	if err != nil {
+		return nil, false
 	}
+	return element, expanded
 }
 
 // convertNestedBlocksToAttributes converts nested blocks (github, gsuite, etc.) to attributes
@@ -910,24 +1031,18 @@ func (m *V4ToV5Migrator) convertNestedBlocksToAttributes(body *hclwrite.Body) {
 	nestedBlockTypes := []string{"github", "gsuite", "azure", "okta", "saml", "external_evaluation", "auth_context"}
 
 	for _, blockType := range nestedBlockTypes {
-		blocks := tfhcl.FindBlocksByType(body, blockType)
+		blocks := tfhcl.FindBlocksWithDynamic(body, blockType)
 		if len(blocks) == 0 {
 			continue
 		}
 
-		// Convert blocks to array of objects
-		var objectTokens []hclwrite.Tokens
-		for _, block := range blocks {
-			objTokens := tfhcl.BuildObjectFromBlock(block)
-			objectTokens = append(objectTokens, objTokens)
-		}
-
-		// Build array and set as attribute
-		arrayTokens := tfhcl.BuildArrayFromObjects(objectTokens)
-		body.SetAttributeRaw(blockType, arrayTokens)
+		// Build array of objects, or for expressions for dynamic blocks, and set as attribute
+		body.SetAttributeRaw(blockType, tfhcl.BuildListFromBlocks(blocks, tfhcl.BuildObjectFromBlock))
 
 		// Remove original blocks
-		tfhcl.RemoveBlocksByType(body, blockType)
+		for _, block := range blocks {
+			body.RemoveBlock(block)
+		}
 	}
 }
 
@@ -978,47 +1093,51 @@ func (m *V4ToV5Migrator) transformConditionAttributes(body *hclwrite.Body) {
 			continue
 		}
 
-		// Parse the attribute expression
-		expr := attr.Expr()
-		originalTokens := expr.BuildTokens(nil)
-		src := hclwrite.Format(originalTokens.Bytes())
+		if tokens := m.transformConditionTokens(attrName, attr.Expr().BuildTokens(nil)); tokens != nil {
+			body.SetAttributeRaw(attrName, tokens)
+		}
+	}
+}
 
-		// Normalize IP addresses in the source before parsing
-		src = []byte(m.normalizeIPsInSource(string(src)))
+// transformConditionTokens transforms a condition list expression as described for
+// transformConditionAttributes. Returns nil when the expression can't be transformed
+// and should be preserved as-is.
+func (m *V4ToV5Migrator) transformConditionTokens(attrName string, tokens hclwrite.Tokens) hclwrite.Tokens {
+	src := hclwrite.Format(tokens.Bytes())
 
-		// Parse as syntax expression to manipulate
-		syntaxExpr, diags := hclsyntax.ParseExpression(src, attrName, hcl.InitialPos)
-		if diags.HasErrors() {
-			// Can't parse - leave as is
-			continue
-		}
+	// Normalize IP addresses in the source before parsing
+	src = []byte(m.normalizeIPsInSource(string(src)))
 
-		// Check if this contains a for expression that needs special handling
-		// e.g., [{email = [for i in range(2) : "user${i}@example.com"]}]
-		if forExprTransformed := m.tryTransformForExpressionInCondition(syntaxExpr, string(src)); forExprTransformed != "" {
-			// Successfully transformed a for expression - use the result
-			body.SetAttributeRaw(attrName, hclwrite.Tokens{
-				&hclwrite.Token{Type: hclsyntax.TokenIdent, Bytes: []byte(forExprTransformed)},
-			})
-			continue
-		}
+	// Parse as syntax expression to manipulate
+	syntaxExpr, diags := hclsyntax.ParseExpression(src, attrName, hcl.InitialPos)
+	if diags.HasErrors() {
+		// Can't parse - leave as is
+		return nil
+	}
 
-		// Check if this is a simple tuple/array that we can transform
-		// For other complex expressions, preserve as-is
-		if !m.canTransformConditionExpression(syntaxExpr) {
-			continue
+	// Check if this contains a for expression that needs special handling
+	// e.g., [{email = [for i in range(2) : "user${i}@example.com"]}]
+	if forExprTransformed := m.tryTransformForExpressionInCondition(syntaxExpr, string(src)); forExprTransformed != "" {
+		// Successfully transformed a for expression - use the result
+		return hclwrite.Tokens{
+			&hclwrite.Token{Type: hclsyntax.TokenIdent, Bytes: []byte(forExprTransformed)},
 		}
+	}
 
-		// Transform the expression
-		transformedExpr := m.transformConditionExpression(syntaxExpr)
-		if transformedExpr == nil {
-			continue
-		}
+	// Check if this is a simple tuple/array that we can transform
+	// For other complex expressions, preserve as-is
+	if !m.canTransformConditionExpression(syntaxExpr) {
+		return nil
+	}
 
-		// Convert back to tokens and set the attribute
-		tokens := m.exprToTokens(transformedExpr)
-		body.SetAttributeRaw(attrName, tokens)
+	// Transform the expression
+	transformedExpr := m.transformConditionExpression(syntaxExpr)
+	if transformedExpr == nil {
+		return nil
 	}
+
+	// Convert back to tokens
+	return m.exprToTokens(transformedExpr)
 }
 
 // tryTransformForExpressionInCondition checks if the condition contains an object
@@ -1154,6 +1154,152 @@ moved {
   from = cloudflare_access_group.test
   to   = cloudflare_zero_trust_access_group.test
 }
+`,
+		},
+		{
+			Name: "dynamic include merged with static include",
+			Input: `
+resource "cloudflare_zero_trust_access_group" "test" {
+  account_id = "abc123"
+  name       = "Test Group"
+  include {
+    email = ["admin@example.com"]
+  }
+  dynamic "include" {
+    for_each = var.emails
+    iterator = user
+    content {
+      email = [user.value]
+    }
+  }
+}
+`,
+			Expected: `
+resource "cloudflare_zero_trust_access_group" "test" {
+  account_id = "abc123"
+  name       = "Test Group"
+  include = concat(
+    [
+      {
+        email = {
+          email = "admin@example.com"
+        }
+      },
+    ],
+    [for value in var.emails : {
+      email = {
+        email = value
+      }
+    }],
+  )
+}
+`,
+		},
+		{
+			Name: "dynamic require expanding to several conditions",
+			Input: `
+resource "cloudflare_zero_trust_access_group" "test" {
+  account_id = "abc123"
+  name       = "Test Group"
+  include {
+    everyone = true
+  }
+  dynamic "require" {
+    for_each = var.countries
+    content {
+      geo = [require.key, require.value]
+    }
+  }
+}
+`,
+			Expected: `
+resource "cloudflare_zero_trust_access_group" "test" {
+  account_id = "abc123"
+  name       = "Test Group"
+  include = [
+    {
+      everyone = {}
+    },
+  ]
+  require = flatten([for key, value in var.countries : [
+    {
+      geo = {
+        country_code = key
+      }
+    },
+    {
+      geo = {
+        country_code = value
+      }
+    },
+  ]])
+}
+`,
+		},
+		{
+			Name: "dynamic include with complex content left for manual migration",
+			Input: `
+resource "cloudflare_zero_trust_access_group" "test" {
+  account_id = "abc123"
+  name       = "Test Group"
+  dynamic "include" {
+    for_each = var.domains
+    content {
+      email_domain = [lower(include.value)]
+    }
+  }
+}
+`,
+			Expected: `
+resource "cloudflare_zero_trust_access_group" "test" {
+  account_id = "abc123"
+  name       = "Test Group"
+  dynamic "include" {
+    for_each = var.domains
+    content {
+      email_domain = [lower(include.value)]
+    }
+  }
+}
+`,
+		},
+		{
+			Name: "dynamic include with nested dynamic github left unchanged",
+			Input: `
+resource "cloudflare_zero_trust_access_group" "test" {
+  account_id = "abc123"
+  name       = "Test Group"
+  dynamic "include" {
+    for_each = var.github_groups
+    iterator = grp
+    content {
+      dynamic "github" {
+        for_each = grp.value.gh
+        content {
+          name = github.value
+        }
+      }
+    }
+  }
+}
+`,
+			Expected: `
+resource "cloudflare_zero_trust_access_group" "test" {
+  account_id = "abc123"
+  name       = "Test Group"
+  dynamic "include" {
+    for_each = var.github_groups
+    iterator = grp
+    content {
+      dynamic "github" {
+        for_each = grp.value.gh
+        content {
+          name = github.value
+        }
+      }
+    }
+  }
+}
 `,
 		},
 	}
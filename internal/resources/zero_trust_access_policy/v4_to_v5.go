@@ -173,13 +173,23 @@ func (m *V4ToV5Migrator) TransformConfig(ctx *transform.Context, block *hclwrite
 	// Convert connection_rules block to attribute syntax
 	tfhcl.ConvertSingleBlockToAttribute(body, "connection_rules", "connection_rules")
 
-	// 4. Normalize nested condition selector blocks inside include/exclude/require.
-	// Convert e.g. include { azure { ... } } -> include { azure = [{ ... }] }
-	// before converting include/exclude/require blocks themselves.
-	m.normalizeNestedConditionBlocks(body)
+	// 4. Convert include/exclude/require blocks to attributes, normalizing nested
+	// condition selector blocks first. Dynamic blocks become for expressions;
+	// those whose content can't be transformed are left in place and reported
+	if dynamicConditions := m.convertConditionBlocksToAttributes(body); len(dynamicConditions) > 0 {
+		ctx.Diagnostics = append(ctx.Diagnostics, &hcl.Diagnostic{
+			Severity: hcl.DiagWarning,
+			Summary:  fmt.Sprintf("Dynamic block requires manual migration: cloudflare_zero_trust_access_policy.%s", resourceName),
+			Detail: fmt.Sprintf(`Dynamic blocks for %s could not be automatically migrated to v5.
+
+The v5 provider uses list attributes for include/exclude/require instead of blocks.
+The content of these dynamic blocks is too complex to rewrite as a for expression.
 
-	// 5. First convert include/exclude/require blocks to attributes if needed
-	m.convertConditionBlocksToAttributes(body)
+To migrate manually:
+  1. Rewrite the dynamic blocks as a for expression in the list attribute, OR
+  2. Use for_each at the resource level instead of dynamic blocks`, strings.Join(dynamicConditions, ", ")),
+		})
+	}
 
 	// 6. Then process include/exclude/require condition transformations
 	// These require complex AST manipulation
@@ -203,30 +213,174 @@ func (m *V4ToV5Migrator) TransformConfig(ctx *transform.Context, block *hclwrite
 
 // normalizeNestedConditionBlocks converts nested condition selector blocks into attribute form
 // so they survive include/exclude/require block-to-attribute conversion.
-func (m *V4ToV5Migrator) normalizeNestedConditionBlocks(body *hclwrite.Body) {
-	conditionNames := []string{"include", "exclude", "require"}
+// Convert e.g. include { azure { ... } } -> include { azure = [{ ... }] }
+func (m *V4ToV5Migrator) normalizeNestedConditionBlocks(condBody *hclwrite.Body) {
 	nestedSelectors := []string{"github", "saml", "oidc", "azure", "okta", "gsuite", "external_evaluation", "auth_context"}
 
-	for _, condName := range conditionNames {
-		for _, condBlock := range tfhcl.FindBlocksByType(body, condName) {
-			condBody := condBlock.Body()
-			for _, nested := range nestedSelectors {
-				tfhcl.ConvertBlocksToArrayAttribute(condBody, nested, false)
-			}
-		}
+	for _, nested := range nestedSelectors {
+		tfhcl.ConvertBlocksToArrayAttribute(condBody, nested, false)
 	}
 }
 
 // convertConditionBlocksToAttributes converts include/exclude/require blocks to attribute arrays
 // This is what Grit patterns do in the old migration, but we need to handle it here
-func (m *V4ToV5Migrator) convertConditionBlocksToAttributes(body *hclwrite.Body) {
+// Returns the names of dynamic blocks that could not be converted
+func (m *V4ToV5Migrator) convertConditionBlocksToAttributes(body *hclwrite.Body) []string {
 	conditionNames := []string{"include", "exclude", "require"}
+	var dynamicConditions []string
 
 	for _, condName := range conditionNames {
-		// Use the helper to convert blocks to attributes
-		// This will convert: include { email = [...] } -> include = [{ email = [...] }]
-		tfhcl.ConvertBlocksToArrayAttribute(body, condName, false)
+		blocks := tfhcl.FindBlocksWithDynamic(body, condName)
+		if !hasDynamicBlock(blocks) {
+			for _, block := range blocks {
+				m.normalizeNestedConditionBlocks(block.Body())
+			}
+			// Use the helper to convert blocks to attributes
+			// This will convert: include { email = [...] } -> include = [{ email = [...] }]
+			tfhcl.ConvertBlocksToArrayAttribute(body, condName, false)
+			continue
+		}
+
+		// Dynamic blocks are converted on a copy, so that one whose content can't be
+		// transformed is left exactly as it was when it is reported
+		originals := make(map[*hclwrite.Block]*hclwrite.Block)
+		for i, block := range blocks {
+			if block.Type() == "dynamic" {
+				if clone := tfhcl.CloneBlock(block); clone != nil {
+					originals[clone] = block
+					blocks[i] = clone
+				}
+			}
+			m.normalizeNestedConditionBlocks(tfhcl.ContentBlock(blocks[i]).Body())
+		}
+
+		listTokens, converted := m.buildConditionListWithDynamic(condName, blocks)
+		if len(converted) < len(blocks) {
+			dynamicConditions = append(dynamicConditions, condName)
+		}
+		if len(converted) == 0 {
+			continue
+		}
+
+		body.SetAttributeRaw(condName, listTokens)
+		for _, block := range converted {
+			if original, ok := originals[block]; ok {
+				block = original
+			}
+			body.RemoveBlock(block)
+		}
+	}
+
+	return dynamicConditions
+}
+
+// hasDynamicBlock reports whether any of blocks is a dynamic block
+func hasDynamicBlock(blocks []*hclwrite.Block) bool {
+	for _, block := range blocks {
+		if block.Type() == "dynamic" {
+			return true
+		}
 	}
+	return false
+}
+
+// buildConditionListWithDynamic builds the v5 condition list for static and dynamic
+// condition blocks, transformed already since transformConditionAttributes leaves
+// concat() and for expressions alone. Each dynamic block becomes a for expression
+// over its for_each value:
+//
+//	dynamic "include" {
+//	  for_each = var.emails
+//	  content {
+//	    email = [include.value]
+//	  }
+//	}
+//
+// becomes [for value in var.emails : { email = { email = value } }], wrapped in
+// flatten() when the content expands to several conditions. Static and dynamic
+// parts are merged with concat() in source order. Dynamic blocks whose content
+// can't be transformed are left out; the converted blocks are returned.
+func (m *V4ToV5Migrator) buildConditionListWithDynamic(condName string, blocks []*hclwrite.Block) (hclwrite.Tokens, []*hclwrite.Block) {
+	var lists []hclwrite.Tokens
+	var converted []*hclwrite.Block
+	var static []hclwrite.Tokens
+
+	flushStatic := func() {
+		if len(static) == 0 {
+			return
+		}
+		arrayTokens := tfhcl.BuildArrayFromObjects(static)
+		if transformed := m.transformConditionTokens(condName, arrayTokens); transformed != nil {
+			arrayTokens = transformed
+		}
+		lists = append(lists, arrayTokens)
+		static = nil
+	}
+
+	for _, block := range blocks {
+		if block.Type() != "dynamic" {
+			static = append(static, tfhcl.BuildObjectFromBlock(block))
+			converted = append(converted, block)
+			continue
+		}
+
+		ok, flatten := true, false
+		forTokens := tfhcl.DynamicBlockToForExpression(block, func(content *hclwrite.Block) hclwrite.Tokens {
+			element, expanded := m.transformConditionElement(condName, content)
+			ok, flatten = element != nil, expanded
+			return element
+		})
+		if !ok || forTokens == nil {
+			continue
+		}
+		if flatten {
+			forTokens = append(append(hclwrite.Tokens{
+				{Type: hclsyntax.TokenIdent, Bytes: []byte("flatten")},
+				{Type: hclsyntax.TokenOParen, Bytes: []byte("(")},
+			}, forTokens...), &hclwrite.Token{Type: hclsyntax.TokenCParen, Bytes: []byte(")")})
+		}
+
+		flushStatic()
+		lists = append(lists, forTokens)
+		converted = append(converted, block)
+	}
+	flushStatic()
+
+	if len(lists) == 1 {
+		return lists[0], converted
+	}
+	return tfhcl.BuildConcatExpression(lists), converted
+}
+
+// transformConditionElement transforms the content block of a dynamic condition
+// block into the element of a for expression. A content block that expands to a
+// single condition yields that condition; otherwise the list of conditions is
+// returned and expanded is true. Returns nil when the content can't be transformed.
+func (m *V4ToV5Migrator) transformConditionElement(condName string, content *hclwrite.Block) (element hclwrite.Tokens, expanded bool) {
+	listTokens := tfhcl.BuildArrayFromObjects([]hclwrite.Tokens{tfhcl.BuildObjectFromBlock(content)})
+	transformed := m.transformConditionTokens(condName, listTokens)
+	if transformed == nil {
+		return nil, false
+	}
+
+	src := hclwrite.Format(transformed.Bytes())
+	expr, diags := hclsyntax.ParseExpression(src, condName, hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, false
+	}
+	exprSrc := string(src)
+	if tup, ok := expr.(*hclsyntax.TupleConsExpr); ok && len(tup.Exprs) == 1 {
+		exprSrc = string(tup.Exprs[0].Range().SliceBytes(src))
+	} else {
+		expanded = true
+	}
+
+	// Tokens are re-parsed so the iterator references in them can be rewritten
+	element, err := tfhcl.TokensForExpressionString(exprSrc)
+	if err != nil {
+		return nil, false
+	}
+	return element, expanded
 }
 
 // removeEmptyConditionArrays removes empty exclude and require arrays
@@ -275,31 +429,41 @@ func (m *V4ToV5Migrator) transformConditionAttributes(body *hclwrite.Body) {
 			continue
 		}
 
-		// Parse the attribute expression
-		expr := attr.Expr()
-		src := hclwrite.Format(expr.BuildTokens(nil).Bytes())
+		if tokens := m.transformConditionTokens(attrName, attr.Expr().BuildTokens(nil)); tokens != nil {
+			body.SetAttributeRaw(attrName, tokens)
+		}
+	}
+}
 
-		// Normalize IP addresses in the source before parsing
-		// This ensures single IPs like "192.168.1.1" become "192.168.1.1/32"
-		src = []byte(m.normalizeIPsInSource(string(src)))
+// transformConditionTokens transforms a condition list expression as described for
+// transformConditionAttributes. Returns nil when the expression can't be transformed
+// and should be preserved as-is.
+func (m *V4ToV5Migrator) transformConditionTokens(attrName string, tokens hclwrite.Tokens) hclwrite.Tokens {
+	src := hclwrite.Format(tokens.Bytes())
 
-		// Parse as syntax expression to manipulate
-		syntaxExpr, diags := hclsyntax.ParseExpression(src, attrName, hcl.InitialPos)
-		if diags.HasErrors() {
-			// Can't parse - leave as is
-			continue
-		}
+	// Normalize IP addresses in the source before parsing
+	// This ensures single IPs like "192.168.1.1" become "192.168.1.1/32"
+	src = []byte(m.normalizeIPsInSource(string(src)))
 
-		// Transform the expression
-		transformedExpr := m.transformConditionExpression(syntaxExpr)
-		if transformedExpr == nil {
-			continue
-		}
+	// Parse as syntax expression to manipulate
+	syntaxExpr, diags := hclsyntax.ParseExpression(src, attrName, hcl.InitialPos)
+	if diags.HasErrors() {
+		// Can't parse - leave as is
+		return nil
+	}
 
-		// Convert back to tokens and set the attribute
-		tokens := m.exprToTokens(transformedExpr)
-		body.SetAttributeRaw(attrName, tokens)
+	// Transform the expression
+	transformedExpr := m.transformConditionExpression(syntaxExpr)
+	if transformedExpr == nil {
+		return nil
 	}
+
+	// Convert back to tokens; expressions the serializer doesn't know are left as-is
+	transformed := m.exprToTokens(transformedExpr)
+	if strings.Contains(string(transformed.Bytes()), unknownExprMarker) {
+		return nil
+	}
+	return transformed
 }
 
 // transformConditionExpression transforms a condition list expression
@@ -979,6 +1143,9 @@ func (m *V4ToV5Migrator) syntaxToBytesAsTokens(expr hclsyntax.Expression) hclwri
 	return m.buildExprTokens(expr)
 }
 
+// unknownExprMarker stands in for expressions buildExprTokens can't serialize
+const unknownExprMarker = "/* UNKNOWN EXPR TYPE */"
+
 // buildExprTokens recursively builds hclwrite tokens from hclsyntax expression
 func (m *V4ToV5Migrator) buildExprTokens(expr hclsyntax.Expression) hclwrite.Tokens {
 	var tokens hclwrite.Tokens
@@ -1204,7 +1371,7 @@ func (m *V4ToV5Migrator) buildExprTokens(expr hclsyntax.Expression) hclwrite.Tok
 
 	default:
 		// Fallback: try to serialize using the source range bytes from the original file
-		tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenComment, Bytes: []byte("/* UNKNOWN EXPR TYPE */")})
+		tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenComment, Bytes: []byte(unknownExprMarker)})
 		tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenIdent, Bytes: []byte("null")})
 	}
 
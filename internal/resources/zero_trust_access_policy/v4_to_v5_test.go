@@ -765,6 +765,84 @@ resource "cloudflare_zero_trust_access_policy" "test" {
   { login_method = { id = "warp" } }]
 }
 
+moved {
+  from = cloudflare_access_policy.test
+  to   = cloudflare_zero_trust_access_policy.test
+}`,
+		},
+		{
+			Name: "dynamic include merged with static include",
+			Input: `
+resource "cloudflare_access_policy" "test" {
+  account_id = "account-123"
+  name       = "Test Policy"
+  decision   = "allow"
+
+  include {
+    everyone = true
+  }
+
+  dynamic "include" {
+    for_each = var.emails
+    iterator = user
+    content {
+      email = [user.value]
+    }
+  }
+
+  include {
+    email = ["a@b.c"]
+  }
+}`,
+			Expected: `
+resource "cloudflare_zero_trust_access_policy" "test" {
+  account_id = "account-123"
+  name       = "Test Policy"
+  decision   = "allow"
+
+  include = concat(
+    [{ everyone = {} }],
+    [for value in var.emails : { email = { email = value } }],
+    [{ email = { email = "a@b.c" } }],
+  )
+}
+
+moved {
+  from = cloudflare_access_policy.test
+  to   = cloudflare_zero_trust_access_policy.test
+}`,
+		},
+		{
+			Name: "dynamic require expanding to several conditions",
+			Input: `
+resource "cloudflare_access_policy" "test" {
+  account_id = "account-123"
+  name       = "Test Policy"
+  decision   = "allow"
+
+  include {
+    everyone = true
+  }
+
+  dynamic "require" {
+    for_each = var.teams
+    content {
+      group        = [require.value.group]
+      email_domain = [require.value.domain]
+    }
+  }
+}`,
+			Expected: `
+resource "cloudflare_zero_trust_access_policy" "test" {
+  account_id = "account-123"
+  name       = "Test Policy"
+  decision   = "allow"
+
+  include = [{ everyone = {} }]
+  require = flatten([for value in var.teams : [{ group = { id = value.group } },
+  { email_domain = { domain = value.domain } }]])
+}
+
 moved {
   from = cloudflare_access_policy.test
   to   = cloudflare_zero_trust_access_policy.test
@@ -5,7 +5,6 @@ import (
 	"regexp"
 	"strings"
 
-	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/hashicorp/hcl/v2/hclwrite"
 	"github.com/zclconf/go-cty/cty"
@@ -53,6 +52,15 @@ func (m *V4ToV5Migrator) GetResourceRename() ([]string, string) {
 	return []string{"cloudflare_dlp_profile", "cloudflare_zero_trust_dlp_profile"}, "cloudflare_zero_trust_dlp_custom_profile"
 }
 
+// IsAlreadyMigrated implements the AlreadyMigratedDetector interface.
+// v5 profiles carry their kind in the resource type rather than a type attribute.
+func (m *V4ToV5Migrator) IsAlreadyMigrated(block *hclwrite.Block) bool {
+	currentType := block.Labels()[0]
+	isV5Type := currentType == "cloudflare_zero_trust_dlp_custom_profile" ||
+		currentType == "cloudflare_zero_trust_dlp_predefined_profile"
+	return isV5Type && block.Body().GetAttribute("type") == nil
+}
+
 func (m *V4ToV5Migrator) TransformConfig(ctx *transform.Context, block *hclwrite.Block) (*transform.TransformResult, error) {
 	body := block.Body()
 	resourceName := tfhcl.GetResourceName(block)
@@ -77,7 +85,7 @@ func (m *V4ToV5Migrator) TransformConfig(ctx *transform.Context, block *hclwrite
 		}
 		tfhcl.RemoveAttributes(body, "type")
 		//m.ensureContextAwareness(body)
-		m.transformCustomEntryBlocks(ctx, body, resourceName)
+		m.transformCustomEntryBlocks(body)
 
 	case "predefined":
 		newType = "cloudflare_zero_trust_dlp_predefined_profile"
@@ -109,63 +117,23 @@ func (m *V4ToV5Migrator) TransformConfig(ctx *transform.Context, block *hclwrite
 	}, nil
 }
 
-func (m *V4ToV5Migrator) transformCustomEntryBlocks(ctx *transform.Context, body *hclwrite.Body, resourceName string) {
-	var entryBlocks []*hclwrite.Block
-	var hasDynamicEntry bool
-
-	for _, block := range body.Blocks() {
-		if block.Type() == "entry" {
-			entryBlocks = append(entryBlocks, block)
-		} else if block.Type() == "dynamic" && len(block.Labels()) > 0 && block.Labels()[0] == "entry" {
-			hasDynamicEntry = true
-			// Since entries in v5 is a list attribute (not blocks), and dynamic blocks can't be used with attributes,
-			// we need to leave dynamic blocks as-is and let the user manually convert them
-			// This is a limitation of the v5 schema where entries is a list of objects
-		}
+// transformCustomEntryBlocks converts entry blocks to the entries list attribute.
+// Dynamic entry blocks become for expressions, merged with static entries via concat().
+func (m *V4ToV5Migrator) transformCustomEntryBlocks(body *hclwrite.Body) {
+	entryBlocks := tfhcl.FindBlocksWithDynamic(body, "entry")
+	if len(entryBlocks) == 0 {
+		return
 	}
 
-	// Only transform static entry blocks if there are no dynamic blocks
-	// If there are dynamic blocks, we can't auto-migrate properly
-	if len(entryBlocks) > 0 && !hasDynamicEntry {
-		var entryObjects []hclwrite.Tokens
-		for _, entryBlock := range entryBlocks {
-			entryBody := entryBlock.Body()
-			tfhcl.RemoveAttributes(entryBody, "id")
-			m.transformPatternBlock(entryBody)
-			objTokens := tfhcl.BuildObjectFromBlock(entryBlock)
-			entryObjects = append(entryObjects, objTokens)
-		}
-
-		arrayTokens := hclwrite.TokensForTuple(entryObjects)
-		body.SetAttributeRaw("entries", arrayTokens)
-		tfhcl.RemoveBlocksByType(body, "entry")
-	} else if hasDynamicEntry {
-		// For resources with dynamic entry blocks, we need a different approach
-		// Since v5 uses a list attribute instead of blocks, dynamic blocks won't work
-		// We'll add a comment to notify the user
-		body.AppendNewline()
-		commentTokens := hclwrite.Tokens{
-			{Type: hclsyntax.TokenComment, Bytes: []byte("# WARNING: Dynamic entry blocks cannot be automatically migrated to v5.\n")},
-			{Type: hclsyntax.TokenComment, Bytes: []byte("# The v5 provider uses 'entries' as a list attribute, which doesn't support dynamic blocks.\n")},
-			{Type: hclsyntax.TokenComment, Bytes: []byte("# Please manually convert dynamic entries to a static list or use for_each at the resource level.\n")},
-		}
-		for _, token := range commentTokens {
-			body.AppendUnstructuredTokens(hclwrite.Tokens{token})
-		}
-
-		// Add diagnostic warning
-		ctx.Diagnostics = append(ctx.Diagnostics, &hcl.Diagnostic{
-			Severity: hcl.DiagWarning,
-			Summary:  fmt.Sprintf("Dynamic block requires manual migration: cloudflare_zero_trust_dlp_custom_profile.%s", resourceName),
-			Detail: `Dynamic entry blocks cannot be automatically migrated to v5.
-
-The v5 provider uses 'entries' as a list attribute instead of blocks.
-Dynamic blocks are not supported with list attributes.
+	for _, entryBlock := range entryBlocks {
+		entryBody := tfhcl.ContentBlock(entryBlock).Body()
+		tfhcl.RemoveAttributes(entryBody, "id")
+		m.transformPatternBlock(entryBody)
+	}
 
-To migrate manually:
-  1. Convert dynamic entries to a static list, OR
-  2. Use for_each at the resource level instead of dynamic blocks`,
-		})
+	body.SetAttributeRaw("entries", tfhcl.BuildListFromBlocks(entryBlocks, tfhcl.BuildObjectFromBlock))
+	for _, entryBlock := range entryBlocks {
+		body.RemoveBlock(entryBlock)
 	}
 }
 
@@ -3,7 +3,11 @@ package zero_trust_dlp_custom_profile
 import (
 	"testing"
 
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+
 	"github.com/cloudflare/tf-migrate/internal/testhelpers"
+	"github.com/cloudflare/tf-migrate/internal/transform"
 )
 
 func TestV4ToV5Transformation(t *testing.T) {
@@ -209,6 +213,62 @@ moved {
 moved {
   from = cloudflare_zero_trust_dlp_profile.example
   to   = cloudflare_zero_trust_dlp_custom_profile.example
+}`,
+			},
+			{
+				Name: "Dynamic entry blocks merged with static entries",
+				Input: `resource "cloudflare_dlp_profile" "dynamic" {
+  account_id          = "123456789"
+  name                = "Dynamic Profile"
+  type                = "custom"
+  allowed_match_count = 1
+
+  entry {
+    name    = "Static Entry"
+    enabled = true
+    pattern {
+      regex = "static"
+    }
+  }
+
+  dynamic "entry" {
+    for_each = var.patterns
+    content {
+      id      = "ignored"
+      name    = entry.key
+      enabled = true
+      pattern {
+        regex = entry.value
+      }
+    }
+  }
+}`,
+				Expected: `resource "cloudflare_zero_trust_dlp_custom_profile" "dynamic" {
+  account_id          = "123456789"
+  name                = "Dynamic Profile"
+  allowed_match_count = 1
+
+  entries = concat(
+    [{
+      name    = "Static Entry"
+      enabled = true
+      pattern = {
+        regex = "static"
+      }
+    }],
+    [for key, value in var.patterns : {
+      name    = key
+      enabled = true
+      pattern = {
+        regex = value
+      }
+    }],
+  )
+}
+
+moved {
+  from = cloudflare_dlp_profile.dynamic
+  to   = cloudflare_zero_trust_dlp_custom_profile.dynamic
 }`,
 			},
 			{
@@ -329,3 +389,47 @@ func TestPreprocessing(t *testing.T) {
 		})
 	}
 }
+
+func TestIsAlreadyMigrated(t *testing.T) {
+	migrator := NewV4ToV5Migrator().(transform.AlreadyMigratedDetector)
+
+	tests := []struct {
+		name     string
+		input    string
+		expected bool
+	}{
+		{
+			name: "v4 profile",
+			input: `resource "cloudflare_dlp_profile" "a" {
+  type = "custom"
+}`,
+			expected: false,
+		},
+		{
+			name: "v5 type with leftover type attribute",
+			input: `resource "cloudflare_zero_trust_dlp_custom_profile" "a" {
+  type = "custom"
+}`,
+			expected: false,
+		},
+		{
+			name: "v5 predefined profile",
+			input: `resource "cloudflare_zero_trust_dlp_predefined_profile" "a" {
+  enabled_entries = ["aws-access-key-id"]
+}`,
+			expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, diags := hclwrite.ParseConfig([]byte(tt.input), "test.tf", hcl.InitialPos)
+			if diags.HasErrors() {
+				t.Fatalf("Failed to parse input: %v", diags)
+			}
+			if got := migrator.IsAlreadyMigrated(file.Body().Blocks()[0]); got != tt.expected {
+				t.Errorf("IsAlreadyMigrated() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
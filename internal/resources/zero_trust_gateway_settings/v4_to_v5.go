@@ -45,6 +45,12 @@ func (m *V4ToV5Migrator) Preprocess(content string) string {
 	return content
 }
 
+// IsAlreadyMigrated implements the AlreadyMigratedDetector interface.
+// Only the v5 resource has the top-level settings attribute.
+func (m *V4ToV5Migrator) IsAlreadyMigrated(block *hclwrite.Block) bool {
+	return tfhcl.GetResourceType(block) == m.newType && block.Body().GetAttribute("settings") != nil
+}
+
 // TransformConfig transforms the HCL configuration from v4 to v5
 func (m *V4ToV5Migrator) TransformConfig(ctx *transform.Context, block *hclwrite.Block) (*transform.TransformResult, error) {
 	// Capture original resource type before any modifications (for moved block generation)
@@ -81,8 +87,12 @@ func (m *V4ToV5Migrator) TransformConfig(ctx *transform.Context, block *hclwrite
 	// schema. The v5 provider's own StateUpgrader (migration/v500/transform.go) also
 	// explicitly drops them. Confirmed with the service team: these settings were
 	// removed from the Gateway Settings API surface in v5.
-	tfhcl.RemoveBlocksByType(body, "ssh_session_log")
-	tfhcl.RemoveBlocksByType(body, "payload_log")
+	for _, blockName := range []string{"ssh_session_log", "payload_log"} {
+		tfhcl.RemoveBlocksByType(body, blockName)
+		for _, dynamicBlock := range tfhcl.FindDynamicBlocks(body, blockName) {
+			body.RemoveBlock(dynamicBlock)
+		}
+	}
 
 	// Rename resource type if it's the old type
 	if originalResourceType == "cloudflare_teams_account" {
@@ -92,6 +102,13 @@ func (m *V4ToV5Migrator) TransformConfig(ctx *transform.Context, block *hclwrite
 	// Compile all other settings into settings block
 	settingsTokens := m.buildSettingsBlock(block)
 
+	// Dynamic blocks left over are kept, with a warning. The logging and proxy
+	// blocks move to resources of their own, so cannot be for expressions.
+	for _, blockName := range []string{"logging", "proxy", "block_page", "body_scanning", "fips", "antivirus",
+		"extended_email_matching", "custom_certificate", "certificate"} {
+		ctx.WarnDynamicBlocks(body, tfhcl.GetResourceType(block)+"."+resourceName, blockName)
+	}
+
 	var allBlocks []*hclwrite.Block
 
 	// When we have additional resources, create a fresh block for gateway settings to avoid formatting issues
@@ -195,20 +212,22 @@ func (m *V4ToV5Migrator) buildSettingsBlock(block *hclwrite.Block) []hclwrite.Ob
 		"certificate",
 	}
 
+	// A dynamic block becomes one() of a for expression over its content
+	var convertedBlocks []*hclwrite.Block
 	for _, blockName := range blockNames {
-		blocks := tfhcl.FindBlocksByType(body, blockName)
+		blocks := tfhcl.FindBlocksWithDynamic(body, blockName)
 		if len(blocks) > 0 {
 			// Get the first block (MaxItems:1 means there's only one)
 			block := blocks[0]
 
 			// Special handling for antivirus - has nested notification_settings
 			if blockName == "antivirus" {
-				antivirusBody := block.Body()
+				antivirusBody := tfhcl.ContentBlock(block).Body()
 
 				// First rename the field in the block
-				notificationBlocks := tfhcl.FindBlocksByType(antivirusBody, "notification_settings")
+				notificationBlocks := tfhcl.FindBlocksWithDynamic(antivirusBody, "notification_settings")
 				if len(notificationBlocks) > 0 {
-					notifBody := notificationBlocks[0].Body()
+					notifBody := tfhcl.ContentBlock(notificationBlocks[0]).Body()
 					tfhcl.RenameAttribute(notifBody, "message", "msg")
 				}
 
@@ -217,12 +236,18 @@ func (m *V4ToV5Migrator) buildSettingsBlock(block *hclwrite.Block) []hclwrite.Ob
 			}
 
 			// Build tokens from block using helper
-			blockTokens := tfhcl.BuildObjectFromBlock(block)
+			var blockTokens hclwrite.Tokens
+			if block.Type() == "dynamic" {
+				blockTokens = hclwrite.TokensForFunctionCall("one", tfhcl.DynamicBlockToForExpression(block, tfhcl.BuildObjectFromBlock))
+			} else {
+				blockTokens = tfhcl.BuildObjectFromBlock(block)
+			}
 
 			settingsTokens = append(settingsTokens, hclwrite.ObjectAttrTokens{
 				Name:  hclwrite.TokensForIdentifier(blockName),
 				Value: blockTokens,
 			})
+			convertedBlocks = append(convertedBlocks, block)
 		}
 	}
 
@@ -239,6 +264,9 @@ func (m *V4ToV5Migrator) buildSettingsBlock(block *hclwrite.Block) []hclwrite.Ob
 	for _, blockName := range blockNames {
 		tfhcl.RemoveBlocksByType(body, blockName)
 	}
+	for _, block := range convertedBlocks {
+		body.RemoveBlock(block)
+	}
 
 	// Create the settings wrapper
 	if len(settingsTokens) > 0 {
@@ -333,6 +361,11 @@ func (m *V4ToV5Migrator) createFreshGatewaySettingsBlock(originalBlock *hclwrite
 		body.SetAttributeRaw("settings", hclwrite.TokensForObject(settingsTokens))
 	}
 
+	// Keep the dynamic blocks that could not be converted
+	for _, dynamicBlock := range tfhcl.FindBlocksByType(originalBody, "dynamic") {
+		body.AppendBlock(tfhcl.CloneBlock(dynamicBlock))
+	}
+
 	return block
 }
 
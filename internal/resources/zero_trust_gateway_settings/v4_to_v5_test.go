@@ -426,6 +426,82 @@ moved {
   to   = cloudflare_zero_trust_gateway_settings.test
 }
 `,
+		},
+		{
+			Name: "dynamic fips and antivirus blocks",
+			Input: `
+resource "cloudflare_zero_trust_gateway_settings" "test" {
+  account_id = "f037e56e89293a057740de681ac9abbe"
+
+  dynamic "fips" {
+    for_each = var.fips_tls ? [true] : []
+    content {
+      tls = fips.value
+    }
+  }
+
+  dynamic "antivirus" {
+    for_each = var.antivirus == null ? [] : [var.antivirus]
+    content {
+      enabled_download_phase = antivirus.value.download
+      notification_settings {
+        enabled = true
+        message = antivirus.value.message
+      }
+    }
+  }
+}`,
+			Expected: `
+resource "cloudflare_zero_trust_gateway_settings" "test" {
+  account_id = "f037e56e89293a057740de681ac9abbe"
+  settings = {
+    browser_isolation = {
+      url_browser_isolation_enabled = false
+      non_identity_enabled          = false
+    }
+    fips = one([for value in var.fips_tls ? [true] : [] : {
+      tls = value
+    }])
+    antivirus = one([for value in var.antivirus == null ? [] : [var.antivirus] : {
+      enabled_download_phase = value.download
+      notification_settings = {
+        enabled = true
+        msg     = value.message
+      }
+    }])
+  }
+}`,
+		},
+		{
+			Name: "dynamic logging block left unchanged",
+			Input: `
+resource "cloudflare_zero_trust_gateway_settings" "test" {
+  account_id = "f037e56e89293a057740de681ac9abbe"
+
+  dynamic "logging" {
+    for_each = var.logging
+    content {
+      redact_pii = logging.value.redact_pii
+    }
+  }
+}`,
+			Expected: `
+resource "cloudflare_zero_trust_gateway_settings" "test" {
+  account_id = "f037e56e89293a057740de681ac9abbe"
+
+  dynamic "logging" {
+    for_each = var.logging
+    content {
+      redact_pii = logging.value.redact_pii
+    }
+  }
+  settings = {
+    browser_isolation = {
+      url_browser_isolation_enabled = false
+      non_identity_enabled          = false
+    }
+  }
+}`,
 		},
 		{
 			Name: "extended_email_matching",
@@ -1,6 +1,7 @@
 package zero_trust_list
 
 import (
+	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/hashicorp/hcl/v2/hclwrite"
 
 	"github.com/cloudflare/tf-migrate/internal"
@@ -49,6 +50,32 @@ func (m *V4ToV5Migrator) GetResourceRename() ([]string, string) {
 	return []string{"cloudflare_teams_list", "cloudflare_zero_trust_list"}, "cloudflare_zero_trust_list"
 }
 
+// IsAlreadyMigrated implements the AlreadyMigratedDetector interface.
+// A v5-named list is already migrated unless it still has items_with_description
+// (as blocks or an attribute) or an items list of plain strings.
+func (m *V4ToV5Migrator) IsAlreadyMigrated(block *hclwrite.Block) bool {
+	body := block.Body()
+	if tfhcl.GetResourceType(block) != m.newType {
+		return false
+	}
+	if tfhcl.FindBlockByType(body, "items_with_description") != nil || body.GetAttribute("items_with_description") != nil {
+		return false
+	}
+
+	items := body.GetAttribute("items")
+	if items == nil {
+		return true
+	}
+	// v4: items = ["a", "b"]; v5: items = [{ value = "a" }]
+	for _, tok := range items.Expr().BuildTokens(nil) {
+		if tok.Type == hclsyntax.TokenOBrack || tok.Type == hclsyntax.TokenNewline {
+			continue
+		}
+		return tok.Type != hclsyntax.TokenOQuote
+	}
+	return true
+}
+
 func (m *V4ToV5Migrator) TransformConfig(ctx *transform.Context, block *hclwrite.Block) (*transform.TransformResult, error) {
 	// Capture original resource type before any modifications (for moved block generation)
 	originalResourceType := tfhcl.GetResourceType(block)
@@ -3,7 +3,11 @@ package zero_trust_list
 import (
 	"testing"
 
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+
 	"github.com/cloudflare/tf-migrate/internal/testhelpers"
+	"github.com/cloudflare/tf-migrate/internal/transform"
 )
 
 func TestV4ToV5Transformation(t *testing.T) {
@@ -691,3 +695,65 @@ resource "cloudflare_zero_trust_list" "already_v5_empty" {
 
 	testhelpers.RunConfigTransformTests(t, tests, migrator)
 }
+
+func TestIsAlreadyMigrated(t *testing.T) {
+	migrator := NewV4ToV5Migrator().(transform.AlreadyMigratedDetector)
+
+	tests := []struct {
+		name     string
+		input    string
+		expected bool
+	}{
+		{
+			name: "v4 name",
+			input: `resource "cloudflare_teams_list" "a" {
+  items = [{ value = "10.0.0.1" }]
+}`,
+			expected: false,
+		},
+		{
+			name: "v5 name with string items",
+			input: `resource "cloudflare_zero_trust_list" "a" {
+  items = ["10.0.0.1"]
+}`,
+			expected: false,
+		},
+		{
+			name: "v5 name with items_with_description blocks",
+			input: `resource "cloudflare_zero_trust_list" "a" {
+  items_with_description {
+    value = "10.0.0.1"
+  }
+}`,
+			expected: false,
+		},
+		{
+			name: "v5 name with object items",
+			input: `resource "cloudflare_zero_trust_list" "a" {
+  items = [
+    { value = "10.0.0.1", description = null },
+  ]
+}`,
+			expected: true,
+		},
+		{
+			name: "v5 name with items from a local",
+			input: `resource "cloudflare_zero_trust_list" "a" {
+  items = local.tunnel_routes
+}`,
+			expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, diags := hclwrite.ParseConfig([]byte(tt.input), "test.tf", hcl.InitialPos)
+			if diags.HasErrors() {
+				t.Fatalf("Failed to parse input: %v", diags)
+			}
+			if got := migrator.IsAlreadyMigrated(file.Body().Blocks()[0]); got != tt.expected {
+				t.Errorf("IsAlreadyMigrated() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package zero_trust_local_fallback_domain
 import (
 	"fmt"
 	"strings"
+	"sync"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
@@ -14,10 +15,12 @@ import (
 )
 
 type V4ToV5Migrator struct {
-	oldType             string
-	oldTypeDeprecated   string
-	newTypeDefault      string
-	newTypeCustom       string
+	oldType           string
+	oldTypeDeprecated string
+	newTypeDefault    string
+	newTypeCustom     string
+
+	mu                  sync.Mutex // guards lastTransformedType; files are transformed in parallel
 	lastTransformedType string
 }
 
@@ -39,6 +42,8 @@ func NewV4ToV5Migrator() transform.ResourceTransformer {
 func (m *V4ToV5Migrator) GetResourceType() string {
 	// Return the type used in the last transformation
 	// If not set, default to default profile
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	if m.lastTransformedType != "" {
 		return m.lastTransformedType
 	}
@@ -188,7 +193,9 @@ func (m *V4ToV5Migrator) TransformConfig(ctx *transform.Context, block *hclwrite
 	} else {
 		newResourceType = m.newTypeDefault
 	}
+	m.mu.Lock()
 	m.lastTransformedType = newResourceType
+	m.mu.Unlock()
 
 	// Rename resource type to appropriate v5 resource
 	currentType := tfhcl.GetResourceType(block)
@@ -200,18 +207,18 @@ func (m *V4ToV5Migrator) TransformConfig(ctx *transform.Context, block *hclwrite
 		body.RemoveAttribute("policy_id")
 	}
 
-	// Convert dynamic "domains" blocks to for-expressions before static block conversion.
-	// Many users write:
+	// Dynamic "domains" blocks become for-expressions. Many users write:
 	//   dynamic "domains" { for_each = toset([...]) content { suffix = domains.value } }
 	// The v5 provider expects:
 	//   domains = [for value in toset([...]) : { suffix = value }]
 	//
-	// When multiple dynamic "domains" blocks exist, they are automatically merged
-	// via concat() by ConvertDynamicBlocksToForExpression.
+	// When multiple dynamic "domains" blocks exist, or static ones alongside,
+	// they are merged via concat() by ConvertBlocksToAttributeList.
 	dynamicDomainsCount := 0
-	for _, dynBlock := range tfhcl.FindBlocksByType(body, "dynamic") {
-		labels := dynBlock.Labels()
-		if len(labels) == 0 || labels[0] != "domains" {
+	staticDomainsCount := 0
+	for _, dynBlock := range tfhcl.FindBlocksWithDynamic(body, "domains") {
+		if dynBlock.Type() != "dynamic" {
+			staticDomainsCount++
 			continue
 		}
 		dynamicDomainsCount++
@@ -248,28 +255,17 @@ Please verify the generated output preserves all intended domain entries and ord
 		})
 	}
 
-	tfhcl.ConvertDynamicBlocksToForExpression(body, "domains")
-
-	// Convert static domains blocks to attribute array.
-	// If dynamic blocks already produced a "domains" attribute, we need to merge
-	// the static blocks into it via concat() rather than overwriting.
-	staticDomainsBlocks := tfhcl.FindBlocksByType(body, "domains")
-	hasDynamicDomains := dynamicDomainsCount > 0 && body.GetAttribute("domains") != nil
-
-	if hasDynamicDomains && len(staticDomainsBlocks) > 0 {
-		// Mixed case: merge dynamic for-expression(s) and static blocks via concat().
-		existingTokens := body.GetAttribute("domains").Expr().BuildTokens(nil)
-		tfhcl.MergeStaticBlocksIntoAttribute(body, "domains", existingTokens)
-
+	if dynamicDomainsCount > 0 && staticDomainsCount > 0 {
 		ctx.Diagnostics = append(ctx.Diagnostics, &hcl.Diagnostic{
 			Severity: hcl.DiagWarning,
 			Summary:  fmt.Sprintf("Mixed static and dynamic 'domains' blocks merged via concat(): %s.%s", newResourceType, resourceName),
 			Detail:   "Both static domains blocks and dynamic domains blocks were found. They have been merged into a single attribute using concat(). Please verify the generated output.",
 		})
-	} else {
-		tfhcl.ConvertBlocksToAttributeList(body, "domains", nil)
 	}
 
+	// Convert static and dynamic domains blocks to the domains attribute.
+	tfhcl.ConvertBlocksToAttributeList(body, "domains", nil)
+
 	// Generate moved block
 	from := oldType + "." + resourceName
 	to := newResourceType + "." + resourceName
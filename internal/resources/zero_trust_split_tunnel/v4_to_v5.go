@@ -28,14 +28,14 @@ tf-migrate has:
   ✓ Generated a 'removed' block to drop the state entry without destroying
     the Cloudflare resource.
   ✓ Removed the original resource block from the configuration.
-  ✓ Merged static 'tunnels {}' blocks into the associated device profile
-    (if the profile is in the same file).
+  ✓ Merged 'tunnels {}' blocks into the associated device profile
+    (if the profile is in the same file). 'dynamic "tunnels"' blocks are
+    written as for expressions.
 
-Action required — if your resource used 'dynamic "tunnels"' blocks:
-  tf-migrate cannot evaluate dynamic expressions. The dynamic block content
-  has been preserved in a comment at the end of the file. Move the tunnel
-  entries manually into the 'exclude' or 'include' attribute of the device
-  profile using a for-expression.`
+Action required — if the profile is not in the same file:
+  The tunnel configuration has been preserved in a comment at the end of
+  the file. Move the tunnel entries manually into the 'exclude' or
+  'include' attribute of the device profile.`
 
 // isSplitTunnelType returns true for both v4 split tunnel type names.
 func isSplitTunnelType(resourceType string) bool {
@@ -68,7 +68,7 @@ func (m *V4ToV5Migrator) Preprocess(content string) string {
 
 // TransformConfig generates a removed block and removes the original split tunnel resource.
 // The device profile migrator calls ProcessCrossResourceConfigMigration which merges
-// static tunnel data into profiles. Dynamic tunnels are preserved in comments.
+// tunnel data into profiles.
 func (m *V4ToV5Migrator) TransformConfig(ctx *transform.Context, block *hclwrite.Block) (*transform.TransformResult, error) {
 	resourceType := tfhcl.GetResourceType(block)
 	resourceName := tfhcl.GetResourceName(block)
@@ -153,33 +153,19 @@ func ProcessCrossResourceConfigMigration(file *hclwrite.File) {
 		}
 	}
 
-	// Separate split tunnels with dynamic blocks — these cannot be merged
-	// and will be preserved as warning comments.
-	dynamicTunnelBlocks := []*hclwrite.Block{}
-	staticSplitTunnelsByParent := make(map[string][]*hclwrite.Block)
-	for parentName, blocks := range splitTunnelsByParent {
-		for _, block := range blocks {
-			if hasDynamicTunnels(block) {
-				dynamicTunnelBlocks = append(dynamicTunnelBlocks, block)
-			} else {
-				staticSplitTunnelsByParent[parentName] = append(staticSplitTunnelsByParent[parentName], block)
-			}
-		}
-	}
-
-	// Step 2: Merge default profile split tunnels (static only)
+	// Step 2: Merge default profile split tunnels
 	if defaultProfileBlock != nil {
-		defaultTunnels := staticSplitTunnelsByParent[""]
+		defaultTunnels := splitTunnelsByParent[""]
 		mergeSplitTunnelsIntoProfile(defaultTunnels, defaultProfileBlock)
-	} else if len(staticSplitTunnelsByParent[""]) > 0 {
+	} else if len(splitTunnelsByParent[""]) > 0 {
 		// Have split tunnels for the default profile but no default profile resource in this file.
 		// Collect them for end-of-file warnings — the blocks will be removed in Step 4.
-		orphanedDefaultTunnels = append(orphanedDefaultTunnels, staticSplitTunnelsByParent[""]...)
+		orphanedDefaultTunnels = append(orphanedDefaultTunnels, splitTunnelsByParent[""]...)
 	}
 
-	// Step 3: Merge custom profile split tunnels (static only)
+	// Step 3: Merge custom profile split tunnels
 	for profileName, profileBlock := range customProfiles {
-		tunnels := staticSplitTunnelsByParent[profileName]
+		tunnels := splitTunnelsByParent[profileName]
 		mergeSplitTunnelsIntoProfile(tunnels, profileBlock)
 	}
 
@@ -237,23 +223,8 @@ func ProcessCrossResourceConfigMigration(file *hclwrite.File) {
 		})
 	}
 
-	// Step 5c: Handle dynamic tunnel blocks — these could not be merged
-	for _, tunnelBlock := range dynamicTunnelBlocks {
-		tunnelResourceName := tfhcl.GetResourceName(tunnelBlock)
-		warningMsg := fmt.Sprintf(
-			"Split tunnel %q uses dynamic \"tunnels\" blocks that cannot be statically evaluated. "+
-				"Move the tunnel entries manually into the 'exclude' or 'include' attribute of "+
-				"the associated device profile using a for-expression.",
-			tunnelResourceName,
-		)
-		migrationWarnings = append(migrationWarnings, migrationWarning{
-			message: warningMsg,
-			block:   tunnelBlock,
-		})
-	}
-
 	// Step 6: Handle split tunnels referencing non-existent profiles
-	for profileName, tunnels := range staticSplitTunnelsByParent {
+	for profileName, tunnels := range splitTunnelsByParent {
 		if profileName == "" {
 			continue // Default profile already handled
 		}
@@ -340,8 +311,8 @@ func extractResourceNameFromReference(tokenStr, resourceType string) string {
 
 // mergeSplitTunnelsIntoProfile merges multiple split_tunnel resources into a device profile.
 // Static tunnels {} blocks are extracted and set as cty values on the profile's
-// include/exclude attributes. Dynamic "tunnels" blocks cannot be statically evaluated,
-// so they are flagged via hasDynamicTunnels for warning generation by the caller.
+// include/exclude attributes. Dynamic "tunnels" blocks become for expressions,
+// joined with the static tunnels of the same mode via concat().
 func mergeSplitTunnelsIntoProfile(splitTunnelBlocks []*hclwrite.Block, profileBlock *hclwrite.Block) {
 	if len(splitTunnelBlocks) == 0 {
 		return
@@ -349,8 +320,9 @@ func mergeSplitTunnelsIntoProfile(splitTunnelBlocks []*hclwrite.Block, profileBl
 
 	profileBody := profileBlock.Body()
 
-	// Group tunnels by mode (exclude vs include)
-	tunnelsByMode := make(map[string][]cty.Value)
+	// Group tunnels by mode (exclude vs include), as runs of static tunnels
+	// separated by the for expressions of dynamic blocks
+	tunnelsByMode := make(map[string][]tunnelList)
 
 	for _, splitTunnelBlock := range splitTunnelBlocks {
 		splitTunnelBody := splitTunnelBlock.Body()
@@ -361,9 +333,16 @@ func mergeSplitTunnelsIntoProfile(splitTunnelBlocks []*hclwrite.Block, profileBl
 			mode = modeVal
 		}
 
-		// Extract static tunnels from this split_tunnel resource
-		tunnelsBlocks := tfhcl.FindBlocksByType(splitTunnelBody, "tunnels")
-		for _, tunnelBlock := range tunnelsBlocks {
+		// Extract tunnels from this split_tunnel resource
+		for _, tunnelBlock := range tfhcl.FindBlocksWithDynamic(splitTunnelBody, "tunnels") {
+			lists := tunnelsByMode[mode]
+			if tunnelBlock.Type() == "dynamic" {
+				tunnelsByMode[mode] = append(lists, tunnelList{
+					forExpr: tfhcl.DynamicBlockToForExpression(tunnelBlock, tfhcl.BuildObjectFromBlock),
+				})
+				continue
+			}
+
 			tunnelMap := make(map[string]cty.Value)
 			tunnelBody := tunnelBlock.Body()
 
@@ -377,30 +356,53 @@ func mergeSplitTunnelsIntoProfile(splitTunnelBlocks []*hclwrite.Block, profileBl
 				tunnelMap["host"] = cty.StringVal(host)
 			}
 
-			if len(tunnelMap) > 0 {
-				tunnelsByMode[mode] = append(tunnelsByMode[mode], cty.ObjectVal(tunnelMap))
+			if len(tunnelMap) == 0 {
+				continue
+			}
+			if len(lists) == 0 || lists[len(lists)-1].forExpr != nil {
+				lists = append(lists, tunnelList{})
 			}
+			lists[len(lists)-1].values = append(lists[len(lists)-1].values, cty.ObjectVal(tunnelMap))
+			tunnelsByMode[mode] = lists
 		}
 	}
 
 	// Set the merged tunnels in deterministic order (include first, then exclude)
-	if tunnels, ok := tunnelsByMode["include"]; ok && len(tunnels) > 0 {
-		profileBody.SetAttributeValue("include", cty.TupleVal(tunnels))
-	}
-	if tunnels, ok := tunnelsByMode["exclude"]; ok && len(tunnels) > 0 {
-		profileBody.SetAttributeValue("exclude", cty.TupleVal(tunnels))
+	for _, mode := range []string{"include", "exclude"} {
+		lists := tunnelsByMode[mode]
+		switch {
+		case len(lists) == 0:
+			continue
+		case len(lists) == 1 && lists[0].forExpr == nil:
+			profileBody.SetAttributeValue(mode, cty.TupleVal(lists[0].values))
+		default:
+			profileBody.SetAttributeRaw(mode, concatTunnelLists(lists))
+		}
 	}
 }
 
-// hasDynamicTunnels returns true if the split tunnel block contains any
-// dynamic "tunnels" blocks (which cannot be statically extracted).
-func hasDynamicTunnels(splitTunnelBlock *hclwrite.Block) bool {
-	for _, block := range splitTunnelBlock.Body().Blocks() {
-		if block.Type() == "dynamic" && len(block.Labels()) > 0 && block.Labels()[0] == "tunnels" {
-			return true
+// tunnelList is either a run of static tunnels or the for expression of a
+// dynamic "tunnels" block.
+type tunnelList struct {
+	values  []cty.Value
+	forExpr hclwrite.Tokens
+}
+
+// concatTunnelLists returns the expression for lists, joined with concat()
+// when there are several.
+func concatTunnelLists(lists []tunnelList) hclwrite.Tokens {
+	exprs := make([]hclwrite.Tokens, 0, len(lists))
+	for _, list := range lists {
+		if list.forExpr != nil {
+			exprs = append(exprs, list.forExpr)
+		} else {
+			exprs = append(exprs, hclwrite.TokensForValue(cty.TupleVal(list.values)))
 		}
 	}
-	return false
+	if len(exprs) == 1 {
+		return exprs[0]
+	}
+	return tfhcl.BuildConcatExpression(exprs)
 }
 
 // addMigrationCommentAtEndOfFile adds a warning comment at the end of the file,
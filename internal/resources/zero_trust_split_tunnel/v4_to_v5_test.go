@@ -143,10 +143,10 @@ resource "cloudflare_zero_trust_split_tunnel" "zt_exclude" {
 	}
 }
 
-// TestDynamicTunnelsGenerateWarning verifies that split tunnels with dynamic "tunnels"
-// blocks are handled correctly: removed from the file, and a warning comment is added.
+// TestDynamicTunnelsMergedAsForExpression verifies that dynamic "tunnels" blocks
+// are merged into the profile as a for expression and the split tunnel is removed.
 // Regression test for https://github.com/cloudflare/tf-migrate/issues/289.
-func TestDynamicTunnelsGenerateWarning(t *testing.T) {
+func TestDynamicTunnelsMergedAsForExpression(t *testing.T) {
 	input := `resource "cloudflare_zero_trust_device_custom_profile" "example" {
   account_id = "abc123"
 }
@@ -169,10 +169,8 @@ resource "cloudflare_split_tunnel" "dynamic_exclude" {
 	}
 
 	ProcessCrossResourceConfigMigration(file)
-	result := string(file.Bytes())
+	result := string(hclwrite.Format(file.Bytes()))
 
-	// The split_tunnel resource block should be removed (the string may appear
-	// inside a MIGRATION_WARNING comment preserving the block content — that's fine).
 	body := file.Body()
 	for _, block := range body.Blocks() {
 		if block.Type() == "resource" && len(block.Labels()) >= 2 {
@@ -182,27 +180,21 @@ resource "cloudflare_split_tunnel" "dynamic_exclude" {
 		}
 	}
 
-	// A warning comment should be added for the dynamic tunnels
-	if !strings.Contains(result, "MIGRATION_WARNING") {
-		t.Error("Expected a MIGRATION_WARNING comment for dynamic tunnels")
-	}
-	if !strings.Contains(result, "dynamic") {
-		t.Error("Expected the warning to mention dynamic blocks")
+	if strings.Contains(result, "MIGRATION_WARNING") {
+		t.Errorf("Did not expect a MIGRATION_WARNING for dynamic tunnels, got:\n%s", result)
 	}
 
-	// The profile should NOT have exclude/include set (dynamic can't be merged)
-	profileBlock := findResourceBlock(body, "cloudflare_zero_trust_device_custom_profile", "example")
-	if profileBlock == nil {
-		t.Fatal("Expected device custom profile block to still exist")
-	}
-	if profileBlock.Body().GetAttribute("exclude") != nil || profileBlock.Body().GetAttribute("include") != nil {
-		t.Error("Did not expect exclude/include on profile — dynamic tunnels can't be statically merged")
+	expected := `exclude = [for value in local.exclude_list : {
+    address     = value
+    description = "Managed via Terraform."
+  }]`
+	if !strings.Contains(result, expected) {
+		t.Errorf("Expected profile to contain:\n%s\ngot:\n%s", expected, result)
 	}
 }
 
 // TestMixedStaticAndDynamicTunnels verifies that when a file has both static
-// and dynamic split tunnel resources, static ones are merged and dynamic ones
-// get a warning comment.
+// and dynamic split tunnel resources for the same mode, they are merged with concat().
 func TestMixedStaticAndDynamicTunnels(t *testing.T) {
 	input := `resource "cloudflare_zero_trust_device_default_profile" "default" {
   account_id = "abc123"
@@ -222,8 +214,9 @@ resource "cloudflare_split_tunnel" "dynamic_exclude" {
   mode       = "exclude"
   dynamic "tunnels" {
     for_each = local.exclude_list
+    iterator = tunnel
     content {
-      address = tunnels.value
+      address = tunnel.value
     }
   }
 }`
@@ -233,7 +226,7 @@ resource "cloudflare_split_tunnel" "dynamic_exclude" {
 	}
 
 	ProcessCrossResourceConfigMigration(file)
-	result := string(file.Bytes())
+	result := string(hclwrite.Format(file.Bytes()))
 
 	// Both split_tunnel resource blocks should be removed from the AST
 	body := file.Body()
@@ -245,14 +238,20 @@ resource "cloudflare_split_tunnel" "dynamic_exclude" {
 		}
 	}
 
-	// Static tunnels merged into profile
-	if !strings.Contains(result, `"192.168.1.0/24"`) {
-		t.Error("Expected static tunnel merged into profile")
+	expected := `exclude = concat(
+    [{
+      address     = "192.168.1.0/24"
+      description = "Static local"
+    }],
+    [for value in local.exclude_list : {
+      address = value
+    }],
+  )`
+	if !strings.Contains(result, expected) {
+		t.Errorf("Expected profile to contain:\n%s\ngot:\n%s", expected, result)
 	}
-
-	// Dynamic tunnels get a warning
-	if !strings.Contains(result, "MIGRATION_WARNING") {
-		t.Error("Expected MIGRATION_WARNING for dynamic tunnels")
+	if strings.Contains(result, "MIGRATION_WARNING") {
+		t.Errorf("Did not expect a MIGRATION_WARNING, got:\n%s", result)
 	}
 }
 
@@ -4,6 +4,7 @@ import (
 	"sort"
 	"strings"
 
+	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclwrite"
 
 	"github.com/cloudflare/tf-migrate/internal"
@@ -189,10 +190,11 @@ func (m *V4ToV5Migrator) TransformConfig(ctx *transform.Context, block *hclwrite
 			addOriginRequestDefaults(originReqBody)
 		}
 
-		// Remove ip_rules from nested origin_request blocks within ingress_rule
-		ingressBlocks := tfhcl.FindBlocksByType(configBody, "ingress_rule")
+		// Remove ip_rules from nested origin_request blocks within ingress_rule,
+		// including the content of dynamic ingress_rule blocks
+		ingressBlocks := tfhcl.FindBlocksWithDynamic(configBody, "ingress_rule")
 		for _, ingressBlock := range ingressBlocks {
-			nestedOriginReqBlocks := tfhcl.FindBlocksByType(ingressBlock.Body(), "origin_request")
+			nestedOriginReqBlocks := tfhcl.FindBlocksByType(tfhcl.ContentBlock(ingressBlock).Body(), "origin_request")
 			for _, nestedOriginReqBlock := range nestedOriginReqBlocks {
 				nestedOriginReqBody := nestedOriginReqBlock.Body()
 				tfhcl.RemoveBlocksByType(nestedOriginReqBody, "ip_rules")
@@ -209,10 +211,24 @@ func (m *V4ToV5Migrator) TransformConfig(ctx *transform.Context, block *hclwrite
 		}
 	}
 
+	// A dynamic ingress_rule block that cannot be converted keeps config a
+	// block, so that it is not dropped
+	keepConfigBlock := false
+	for _, configBlock := range configBlocks {
+		for _, dynamicBlock := range tfhcl.FindDynamicBlocks(configBlock.Body(), "ingress_rule") {
+			if !tfhcl.IsConvertibleDynamicBlock(dynamicBlock) {
+				keepConfigBlock = true
+			}
+		}
+		if keepConfigBlock {
+			ctx.WarnDynamicBlocks(configBlock.Body(), "cloudflare_zero_trust_tunnel_cloudflared_config."+resourceName, "ingress_rule")
+		}
+	}
+
 	// Now convert config block syntax to attribute syntax
 	// v4: config { } → v5: config = { }
 	// This needs to handle nested structures recursively
-	if len(configBlocks) > 0 {
+	if len(configBlocks) > 0 && !keepConfigBlock {
 		// Define which blocks should always be arrays (ingress, even with 1 element)
 		alwaysArrayFields := map[string]bool{
 			"ingress":      true, // ingress is always an array in v5
@@ -222,8 +238,21 @@ func (m *V4ToV5Migrator) TransformConfig(ctx *transform.Context, block *hclwrite
 		// First, rename ingress_rule blocks to ingress before conversion
 		for _, configBlock := range configBlocks {
 			configBody := configBlock.Body()
-			ingressRuleBlocks := tfhcl.FindBlocksByType(configBody, "ingress_rule")
+			ingressRuleBlocks := tfhcl.FindBlocksWithDynamic(configBody, "ingress_rule")
 			for _, ingressBlock := range ingressRuleBlocks {
+				// A dynamic block is relabelled; its iterator keeps the old
+				// name so the references in its content still resolve
+				if ingressBlock.Type() == "dynamic" {
+					newIngressBlock := tfhcl.CloneBlock(ingressBlock)
+					newIngressBlock.SetLabels([]string{"ingress"})
+					if newIngressBlock.Body().GetAttribute("iterator") == nil {
+						newIngressBlock.Body().SetAttributeTraversal("iterator", hcl.Traversal{hcl.TraverseRoot{Name: "ingress_rule"}})
+					}
+					configBody.AppendBlock(newIngressBlock)
+					configBody.RemoveBlock(ingressBlock)
+					continue
+				}
+
 				// Change the block type by creating a new block with the correct type
 				newIngressBlock := hclwrite.NewBlock("ingress", nil)
 				// Copy all attributes in alphabetical order for deterministic output
@@ -60,6 +60,42 @@ moved {
       }
     ]
   }
+}`,
+			},
+			{
+				Name: "Dynamic ingress_rule blocks",
+				Input: `resource "cloudflare_zero_trust_tunnel_cloudflared_config" "example" {
+  account_id = "f037e56e89293a057740de681ac9abbe"
+  tunnel_id  = "f70ff02e-f290-4d76-8c21-c00e98a7fbde"
+  config {
+    dynamic "ingress_rule" {
+      for_each = var.hostnames
+      content {
+        hostname = ingress_rule.key
+        service  = ingress_rule.value
+      }
+    }
+    ingress_rule {
+      service = "http_status:404"
+    }
+  }
+}`,
+				Expected: `resource "cloudflare_zero_trust_tunnel_cloudflared_config" "example" {
+  account_id = "f037e56e89293a057740de681ac9abbe"
+  tunnel_id  = "f70ff02e-f290-4d76-8c21-c00e98a7fbde"
+  config = {
+    ingress = concat(
+      [for key, value in var.hostnames : {
+        hostname = key
+        service  = value
+      }],
+      [
+        {
+          service = "http_status:404"
+        }
+      ],
+    )
+  }
 }`,
 			},
 			{
@@ -0,0 +1,234 @@
+package rules
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/cloudflare/tf-migrate/internal"
+	"github.com/cloudflare/tf-migrate/internal/transform"
+	tfhcl "github.com/cloudflare/tf-migrate/internal/transform/hcl"
+)
+
+// Migrator is the ResourceTransformer that applies a Rule.
+type Migrator struct {
+	rule *Rule
+}
+
+// NewMigrator returns the migrator of rule.
+func NewMigrator(rule *Rule) *Migrator {
+	return &Migrator{rule: rule}
+}
+
+// Register registers the migrators of rules with the migration registry,
+// replacing any migrator registered for the same type and versions.
+func Register(rules []*Rule) {
+	for _, rule := range rules {
+		internal.RegisterMigrator(rule.Type, rule.SourceVersion, rule.TargetVersion, NewMigrator(rule))
+	}
+}
+
+// MustRegister parses a rule file holding exactly one rule, registers its
+// migrator and returns it. It panics if the file is invalid, and is meant for
+// rule files embedded in the binary.
+func MustRegister(data []byte, filename string) transform.ResourceTransformer {
+	rules, err := Parse(data, filename)
+	if err != nil {
+		panic(err)
+	}
+	if len(rules) != 1 {
+		panic(fmt.Sprintf("%s: expected exactly one rule, found %d", filename, len(rules)))
+	}
+	Register(rules)
+	return NewMigrator(rules[0])
+}
+
+// Rule returns the rule the migrator applies.
+func (m *Migrator) Rule() *Rule {
+	return m.rule
+}
+
+func (m *Migrator) CanHandle(resourceType string) bool {
+	return resourceType == m.rule.Type
+}
+
+func (m *Migrator) GetResourceType() string {
+	return m.rule.TargetType()
+}
+
+func (m *Migrator) Preprocess(content string) string {
+	return content
+}
+
+// GetResourceRename implements the ResourceRenamer interface. Types that do
+// not change are returned too, so that they take part in cross-file
+// reference updates.
+func (m *Migrator) GetResourceRename() ([]string, string) {
+	return []string{m.rule.Type}, m.targetReferenceType()
+}
+
+// GetAttributeRenames implements the AttributeRenamer interface.
+func (m *Migrator) GetAttributeRenames() []transform.AttributeRename {
+	var renames []transform.AttributeRename
+	for _, from := range sortedKeys(m.rule.Rename) {
+		renames = append(renames, transform.AttributeRename{
+			ResourceType: m.targetReferenceType(),
+			OldAttribute: from,
+			NewAttribute: m.rule.Rename[from],
+		})
+	}
+	return renames
+}
+
+// targetReferenceType returns the target type as it appears in references,
+// with the data. prefix of data sources.
+func (m *Migrator) targetReferenceType() string {
+	if m.rule.IsDataSource() {
+		return "data." + m.rule.TargetType()
+	}
+	return m.rule.TargetType()
+}
+
+func (m *Migrator) TransformConfig(ctx *transform.Context, block *hclwrite.Block) (*transform.TransformResult, error) {
+	rule := m.rule
+	body := block.Body()
+	name := tfhcl.GetResourceName(block)
+	address := rule.Type + "." + name
+
+	// Diagnostics are decided on the block as written, before migration.
+	for _, d := range rule.Diagnostics {
+		if d.When != "" && body.GetAttribute(d.When) == nil && tfhcl.FindBlockByType(body, d.When) == nil {
+			continue
+		}
+		expand := strings.NewReplacer("{type}", rule.Type, "{name}", name, "{address}", address).Replace
+		ctx.Diagnostics = append(ctx.Diagnostics, &hcl.Diagnostic{
+			Severity: severity(d.Severity),
+			Summary:  expand(d.Summary),
+			Detail:   expand(d.Detail),
+		})
+	}
+
+	for _, from := range sortedKeys(rule.Rename) {
+		tfhcl.RenameAttribute(body, from, rule.Rename[from])
+	}
+	for _, attr := range rule.Remove {
+		tfhcl.RemoveAttributes(body, attr)
+		tfhcl.RemoveBlocksByType(body, attr)
+	}
+	for _, c := range rule.BlocksToAttribute {
+		attrName := c.Attribute
+		if attrName == "" {
+			attrName = c.Block
+		}
+		if c.List {
+			tfhcl.ConvertBlocksToAttributeList(body, c.Block, nil)
+			if attrName != c.Block {
+				tfhcl.RenameAttribute(body, c.Block, attrName)
+			}
+		} else {
+			tfhcl.ConvertBlocksToAttribute(body, c.Block, attrName, nil)
+		}
+	}
+	for _, attr := range sortedKeys(rule.Defaults) {
+		tfhcl.EnsureAttribute(body, attr, rule.Defaults[attr])
+	}
+
+	if rule.RenameTo == "" {
+		return &transform.TransformResult{
+			Blocks:         []*hclwrite.Block{block},
+			RemoveOriginal: false,
+		}, nil
+	}
+
+	tfhcl.RenameResourceType(block, rule.SourceType(), rule.RenameTo)
+	blocks := []*hclwrite.Block{block}
+	switch rule.Generate {
+	case GenerateMoved:
+		blocks = append(blocks, tfhcl.CreateMovedBlock(address, rule.RenameTo+"."+name))
+	case GenerateImport:
+		blocks = append(blocks,
+			tfhcl.CreateRemovedBlock(address),
+			tfhcl.CreateImportBlockWithTokens(rule.RenameTo, name, importIDTokens(rule.ImportID, body)),
+		)
+	}
+
+	return &transform.TransformResult{
+		Blocks:         blocks,
+		RemoveOriginal: true,
+	}, nil
+}
+
+// importIDTokens builds the import ID template: each {attribute} placeholder
+// becomes an interpolation of the attribute's expression, or is kept as
+// written when the block does not set the attribute. An ID with only
+// literal values is written as a plain string.
+func importIDTokens(template string, body *hclwrite.Body) hclwrite.Tokens {
+	tokens := hclwrite.Tokens{{Type: hclsyntax.TokenOQuote, Bytes: []byte(`"`)}}
+	var literal strings.Builder
+	flush := func() {
+		if literal.Len() > 0 {
+			tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenQuotedLit, Bytes: []byte(literal.String())})
+			literal.Reset()
+		}
+	}
+
+	last := 0
+	for _, match := range placeholder.FindAllStringSubmatchIndex(template, -1) {
+		literal.WriteString(escapeTemplate(template[last:match[0]]))
+		last = match[1]
+
+		attr := body.GetAttribute(template[match[2]:match[3]])
+		if attr == nil {
+			literal.WriteString(escapeTemplate(template[match[0]:match[1]]))
+			continue
+		}
+		if value, isLiteral, ok := tfhcl.EvaluateAttribute(attr, nil); ok && isLiteral && value.Type() == cty.String && !value.IsNull() {
+			literal.WriteString(escapeTemplate(value.AsString()))
+			continue
+		}
+		flush()
+		tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenTemplateInterp, Bytes: []byte("${")})
+		for i, tok := range attr.Expr().BuildTokens(nil) {
+			tok := *tok
+			if i == 0 {
+				tok.SpacesBefore = 0
+			}
+			tokens = append(tokens, &tok)
+		}
+		tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenTemplateSeqEnd, Bytes: []byte("}")})
+	}
+	literal.WriteString(escapeTemplate(template[last:]))
+	flush()
+
+	return append(tokens, &hclwrite.Token{Type: hclsyntax.TokenCQuote, Bytes: []byte(`"`)})
+}
+
+// escapeTemplate escapes text for a quoted template.
+func escapeTemplate(text string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "${", "$${", "%{", "%%{").Replace(text)
+}
+
+func severity(s string) hcl.DiagnosticSeverity {
+	switch s {
+	case SeverityError:
+		return hcl.DiagError
+	case SeverityInfo:
+		return transform.DiagInfo
+	default:
+		return hcl.DiagWarning
+	}
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
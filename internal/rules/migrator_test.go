@@ -0,0 +1,261 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudflare/tf-migrate/internal/testhelpers"
+	"github.com/cloudflare/tf-migrate/internal/transform"
+)
+
+func mustParse(t *testing.T, yaml string) []*Rule {
+	t.Helper()
+	rules, err := Parse([]byte(yaml), "test.yaml")
+	require.NoError(t, err)
+	return rules
+}
+
+func TestMigrator(t *testing.T) {
+	rules := mustParse(t, `
+rules:
+  - type: cloudflare_widget
+    rename_to: cloudflare_gizmo
+    rename:
+      value: content
+    remove: [legacy, old_settings]
+    defaults:
+      proxied: false
+    blocks_to_attribute:
+      - block: settings
+      - block: rule
+        attribute: rules
+        list: true
+  - type: cloudflare_zone_widget
+    rename_to: cloudflare_zone_gizmo
+    generate: import
+    import_id: "{zone_id}/{gizmo_id}"
+  - type: data.cloudflare_widgets
+    rename_to: cloudflare_gizmos
+`)
+
+	t.Run("Resource", func(t *testing.T) {
+		testhelpers.RunConfigTransformTests(t, []testhelpers.ConfigTestCase{
+			{
+				Name: "renames, removes, converts and moves",
+				Input: `resource "cloudflare_widget" "example" {
+  zone_id = "abc"
+  value   = "192.0.2.1"
+  legacy  = true
+
+  old_settings {
+    mode = "off"
+  }
+
+  settings {
+    mode = "on"
+  }
+
+  rule {
+    action = "block"
+  }
+
+  rule {
+    action = "allow"
+  }
+}`,
+				Expected: `resource "cloudflare_gizmo" "example" {
+  zone_id  = "abc"
+  content  = "192.0.2.1"
+  settings = {
+    mode = "on"
+  }
+  rules = [{
+    action = "block"
+  }, {
+    action = "allow"
+  }]
+  proxied = false
+}
+
+moved {
+  from = cloudflare_widget.example
+  to   = cloudflare_gizmo.example
+}`,
+			},
+			{
+				Name: "keeps set defaults",
+				Input: `resource "cloudflare_widget" "example" {
+  zone_id = "abc"
+  proxied = true
+}`,
+				Expected: `resource "cloudflare_gizmo" "example" {
+  zone_id = "abc"
+  proxied = true
+}
+
+moved {
+  from = cloudflare_widget.example
+  to   = cloudflare_gizmo.example
+}`,
+			},
+		}, NewMigrator(rules[0]))
+	})
+
+	t.Run("Import", func(t *testing.T) {
+		testhelpers.RunConfigTransformTests(t, []testhelpers.ConfigTestCase{
+			{
+				Name: "literal and expression import ID",
+				Input: `resource "cloudflare_zone_widget" "example" {
+  zone_id   = var.zone_id
+  gizmo_id  = "g1"
+}`,
+				Expected: `resource "cloudflare_zone_gizmo" "example" {
+  zone_id  = var.zone_id
+  gizmo_id = "g1"
+}
+
+removed {
+  from = cloudflare_zone_widget.example
+  lifecycle {
+    destroy = false
+  }
+}
+
+import {
+  to = cloudflare_zone_gizmo.example
+  id = "${var.zone_id}/g1"
+}`,
+			},
+		}, NewMigrator(rules[1]))
+	})
+
+	t.Run("DataSource", func(t *testing.T) {
+		testhelpers.RunConfigTransformTests(t, []testhelpers.ConfigTestCase{
+			{
+				Name: "renamed without moved block",
+				Input: `data "cloudflare_widgets" "all" {
+  zone_id = "abc"
+}`,
+				Expected: `data "cloudflare_gizmos" "all" {
+  zone_id = "abc"
+}`,
+			},
+		}, NewMigrator(rules[2]))
+	})
+}
+
+func TestMigratorInterfaces(t *testing.T) {
+	rules := mustParse(t, `
+rules:
+  - type: cloudflare_widget
+    rename_to: cloudflare_gizmo
+    rename: {value: content, b: c}
+  - type: data.cloudflare_widgets
+    rename: {widgets: result}
+`)
+
+	widget := NewMigrator(rules[0])
+	assert.True(t, widget.CanHandle("cloudflare_widget"))
+	assert.False(t, widget.CanHandle("cloudflare_gizmo"))
+	assert.Equal(t, "cloudflare_gizmo", widget.GetResourceType())
+	oldTypes, newType := widget.GetResourceRename()
+	assert.Equal(t, []string{"cloudflare_widget"}, oldTypes)
+	assert.Equal(t, "cloudflare_gizmo", newType)
+	assert.Equal(t, []transform.AttributeRename{
+		{ResourceType: "cloudflare_gizmo", OldAttribute: "b", NewAttribute: "c"},
+		{ResourceType: "cloudflare_gizmo", OldAttribute: "value", NewAttribute: "content"},
+	}, widget.GetAttributeRenames())
+
+	widgets := NewMigrator(rules[1])
+	assert.True(t, widgets.CanHandle("data.cloudflare_widgets"))
+	assert.Equal(t, "cloudflare_widgets", widgets.GetResourceType())
+	oldTypes, newType = widgets.GetResourceRename()
+	assert.Equal(t, []string{"data.cloudflare_widgets"}, oldTypes)
+	assert.Equal(t, "data.cloudflare_widgets", newType)
+	assert.Equal(t, []transform.AttributeRename{
+		{ResourceType: "data.cloudflare_widgets", OldAttribute: "widgets", NewAttribute: "result"},
+	}, widgets.GetAttributeRenames())
+}
+
+func TestMigratorDiagnostics(t *testing.T) {
+	rules := mustParse(t, `
+rules:
+  - type: cloudflare_widget
+    remove: [legacy]
+    diagnostics:
+      - summary: cloudflare_widget needs review
+      - when: legacy
+        severity: error
+        summary: legacy was removed
+        detail: "Set {address} up again without legacy."
+`)
+
+	transformBlock := func(src string) hcl.Diagnostics {
+		file, diags := hclwrite.ParseConfig([]byte(src), "test.tf", hcl.InitialPos)
+		require.False(t, diags.HasErrors())
+		ctx := &transform.Context{}
+		_, err := NewMigrator(rules[0]).TransformConfig(ctx, file.Body().Blocks()[0])
+		require.NoError(t, err)
+		return ctx.Diagnostics
+	}
+
+	diags := transformBlock(`resource "cloudflare_widget" "a" {}`)
+	require.Len(t, diags, 1)
+	assert.Equal(t, hcl.DiagWarning, diags[0].Severity)
+	assert.Equal(t, "cloudflare_widget needs review", diags[0].Summary)
+
+	diags = transformBlock("resource \"cloudflare_widget\" \"b\" {\n  legacy = true\n}\n")
+	require.Len(t, diags, 2)
+	assert.Equal(t, hcl.DiagError, diags[1].Severity)
+	assert.Equal(t, "Set cloudflare_widget.b up again without legacy.", diags[1].Detail)
+}
+
+func TestProvider(t *testing.T) {
+	builtin := NewMigrator(mustParse(t, "rules:\n  - type: cloudflare_widget\n  - type: cloudflare_other\n")[0])
+	other := NewMigrator(mustParse(t, "rules:\n  - type: cloudflare_other\n")[0])
+	base := transform.NewMigrationProvider(
+		func(resourceType, source, target string) transform.ResourceTransformer {
+			switch resourceType {
+			case "cloudflare_widget":
+				return builtin
+			case "cloudflare_other":
+				return other
+			}
+			return nil
+		},
+		func(source, target string, resources ...string) []transform.ResourceTransformer {
+			return []transform.ResourceTransformer{builtin, other}
+		},
+	)
+
+	p := Provider(base, mustParse(t, `
+rules:
+  - type: cloudflare_widget
+    rename_to: cloudflare_gizmo
+  - type: cloudflare_new
+  - type: cloudflare_future
+    source_version: v5
+    target_version: v6
+`))
+
+	widget := p.GetMigrator("cloudflare_widget", "v4", "v5")
+	require.NotNil(t, widget)
+	assert.Equal(t, "cloudflare_gizmo", widget.GetResourceType())
+	assert.Same(t, other, p.GetMigrator("cloudflare_other", "v4", "v5"))
+	assert.NotNil(t, p.GetMigrator("cloudflare_new", "v4", "v5"))
+	assert.Nil(t, p.GetMigrator("cloudflare_future", "v4", "v5"))
+
+	var types []string
+	for _, m := range p.GetAllMigrators("v4", "v5") {
+		types = append(types, m.GetResourceType())
+	}
+	assert.ElementsMatch(t, []string{"cloudflare_other", "cloudflare_gizmo", "cloudflare_new"}, types)
+
+	filtered := p.GetAllMigrators("v4", "v5", "cloudflare_new")
+	require.Len(t, filtered, 3) // the base provider of this test ignores the filter
+	assert.Equal(t, "cloudflare_new", filtered[2].GetResourceType())
+}
@@ -0,0 +1,69 @@
+package rules
+
+import (
+	"github.com/cloudflare/tf-migrate/internal/transform"
+)
+
+// provider layers the migrators of rules over a base provider without
+// touching the migration registry.
+type provider struct {
+	base      transform.MigrationProvider
+	migrators map[string]*Migrator // "type:source:target" -> migrator
+}
+
+// Provider returns a MigrationProvider that serves the migrators of rules,
+// and base for every other type. A rule replaces the base migrator of its
+// type.
+func Provider(base transform.MigrationProvider, rules []*Rule) transform.MigrationProvider {
+	p := &provider{base: base, migrators: make(map[string]*Migrator, len(rules))}
+	for _, rule := range rules {
+		p.migrators[key(rule.Type, rule.SourceVersion, rule.TargetVersion)] = NewMigrator(rule)
+	}
+	return p
+}
+
+func key(resourceType, sourceVersion, targetVersion string) string {
+	return resourceType + ":" + sourceVersion + ":" + targetVersion
+}
+
+func (p *provider) GetMigrator(resourceType, sourceVersion, targetVersion string) transform.ResourceTransformer {
+	if m, ok := p.migrators[key(resourceType, sourceVersion, targetVersion)]; ok {
+		return m
+	}
+	return p.base.GetMigrator(resourceType, sourceVersion, targetVersion)
+}
+
+func (p *provider) GetAllMigrators(sourceVersion, targetVersion string, resources ...string) []transform.ResourceTransformer {
+	var own []*Migrator
+	if len(resources) > 0 {
+		for _, r := range resources {
+			if m, ok := p.migrators[key(r, sourceVersion, targetVersion)]; ok {
+				own = append(own, m)
+			}
+		}
+	} else {
+		for _, m := range p.migrators {
+			if m.rule.SourceVersion == sourceVersion && m.rule.TargetVersion == targetVersion {
+				own = append(own, m)
+			}
+		}
+	}
+
+	var result []transform.ResourceTransformer
+	for _, migrator := range p.base.GetAllMigrators(sourceVersion, targetVersion, resources...) {
+		replaced := false
+		for _, m := range own {
+			if migrator.CanHandle(m.rule.Type) {
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			result = append(result, migrator)
+		}
+	}
+	for _, m := range own {
+		result = append(result, m)
+	}
+	return result
+}
@@ -0,0 +1,259 @@
+// Package rules implements declarative migration rules: YAML files that
+// describe how a resource or data source type migrates, interpreted by a
+// generic transform.ResourceTransformer. Rules cover migrations made only of
+// attribute renames, removals, defaults, block to attribute conversions,
+// a type rename with its moved or import block, and diagnostics; anything
+// else needs a migrator written in Go.
+//
+// A rule file holds a list of rules:
+//
+//	rules:
+//	  - type: cloudflare_worker_domain
+//	    rename_to: cloudflare_workers_custom_domain
+//	    rename:
+//	      hostname: name
+//	    remove: [legacy_flag]
+//	    defaults:
+//	      enabled: true
+//	    blocks_to_attribute:
+//	      - block: settings
+//	    diagnostics:
+//	      - when: legacy_flag
+//	        summary: legacy_flag was removed
+//	        detail: "{address} no longer supports legacy_flag."
+package rules
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Values of Rule.Generate.
+const (
+	GenerateMoved  = "moved"
+	GenerateImport = "import"
+	GenerateNone   = "none"
+)
+
+// Values of Diagnostic.Severity.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityInfo    = "info"
+)
+
+var (
+	identifier  = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+	placeholder = regexp.MustCompile(`\{([a-z0-9_]+)\}`)
+)
+
+// File is the content of a rule file.
+type File struct {
+	Rules []*Rule `yaml:"rules"`
+}
+
+// Rule describes the migration of one resource or data source type.
+type Rule struct {
+	// Type is the source type, e.g. "cloudflare_queue", or
+	// "data.cloudflare_zones" for a data source.
+	Type string `yaml:"type"`
+	// SourceVersion and TargetVersion select the migration path the rule
+	// applies to. They default to v4 and v5.
+	SourceVersion string `yaml:"source_version,omitempty"`
+	TargetVersion string `yaml:"target_version,omitempty"`
+
+	// RenameTo is the target type, without the data. prefix of data
+	// sources. Empty means the type is unchanged.
+	RenameTo string `yaml:"rename_to,omitempty"`
+	// Generate selects the block generated for a renamed resource: moved
+	// (the default), import, which drops the old address with a removed
+	// block and imports the new one by ImportID, or none.
+	Generate string `yaml:"generate,omitempty"`
+	// ImportID is the import ID of the new resource when Generate is import.
+	// {attribute} placeholders are replaced with the value of the attribute
+	// in the migrated block.
+	ImportID string `yaml:"import_id,omitempty"`
+
+	// Rename maps old attribute names to new ones. References to renamed
+	// attributes are rewritten across files.
+	Rename map[string]string `yaml:"rename,omitempty"`
+	// Remove lists attributes and blocks that no longer exist.
+	Remove []string `yaml:"remove,omitempty"`
+	// Defaults sets attributes that are not set, after renames. Values are
+	// strings, numbers or booleans.
+	Defaults map[string]interface{} `yaml:"defaults,omitempty"`
+	// BlocksToAttribute converts nested blocks to attributes.
+	BlocksToAttribute []BlockConversion `yaml:"blocks_to_attribute,omitempty"`
+	// Diagnostics are reported for every migrated block, or only for
+	// blocks that set an attribute.
+	Diagnostics []Diagnostic `yaml:"diagnostics,omitempty"`
+
+	// file is the rule file the rule was read from.
+	file string
+}
+
+// BlockConversion converts the blocks of one type to an attribute.
+type BlockConversion struct {
+	Block string `yaml:"block"`
+	// Attribute is the name of the attribute; it defaults to Block.
+	Attribute string `yaml:"attribute,omitempty"`
+	// List converts every block into an element of a list. Without it, a
+	// single block becomes an object.
+	List bool `yaml:"list,omitempty"`
+}
+
+// Diagnostic is a diagnostic a rule reports. Summary and Detail may use the
+// {type}, {name} and {address} placeholders of the migrated block.
+type Diagnostic struct {
+	// Severity is error, warning (the default) or info.
+	Severity string `yaml:"severity,omitempty"`
+	Summary  string `yaml:"summary"`
+	Detail   string `yaml:"detail,omitempty"`
+	// When reports the diagnostic only for blocks that set this attribute
+	// or block before migration.
+	When string `yaml:"when,omitempty"`
+}
+
+// IsDataSource reports whether the rule migrates a data source.
+func (r *Rule) IsDataSource() bool {
+	return strings.HasPrefix(r.Type, "data.")
+}
+
+// SourceType returns the type of the migrated blocks, without the data.
+// prefix.
+func (r *Rule) SourceType() string {
+	return strings.TrimPrefix(r.Type, "data.")
+}
+
+// TargetType returns the type after migration, without the data. prefix.
+func (r *Rule) TargetType() string {
+	if r.RenameTo != "" {
+		return r.RenameTo
+	}
+	return r.SourceType()
+}
+
+// File returns the name of the rule file the rule was read from.
+func (r *Rule) File() string {
+	return r.file
+}
+
+// Load reads a rule file.
+func Load(path string) ([]*Rule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rule file: %w", err)
+	}
+	return Parse(data, path)
+}
+
+// Parse parses the rules of a rule file, applying defaults and validating
+// every rule. filename is used in errors. Unknown keys are errors, so that a
+// misspelt key is not silently ignored.
+func Parse(data []byte, filename string) ([]*Rule, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+
+	var f File
+	if err := dec.Decode(&f); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+
+	seen := make(map[string]bool)
+	for i, rule := range f.Rules {
+		if rule == nil {
+			return nil, fmt.Errorf("%s: rule %d is empty", filename, i+1)
+		}
+		rule.file = filepath.Base(filename)
+		if rule.SourceVersion == "" {
+			rule.SourceVersion = "v4"
+		}
+		if rule.TargetVersion == "" {
+			rule.TargetVersion = "v5"
+		}
+		if rule.Generate == "" {
+			rule.Generate = GenerateNone
+			if rule.RenameTo != "" && !rule.IsDataSource() {
+				rule.Generate = GenerateMoved
+			}
+		}
+		if err := rule.validate(); err != nil {
+			return nil, fmt.Errorf("%s: rule %d (%s): %w", filename, i+1, rule.Type, err)
+		}
+
+		key := rule.Type + ":" + rule.SourceVersion + ":" + rule.TargetVersion
+		if seen[key] {
+			return nil, fmt.Errorf("%s: rule %d: %s is defined more than once", filename, i+1, rule.Type)
+		}
+		seen[key] = true
+	}
+	return f.Rules, nil
+}
+
+func (r *Rule) validate() error {
+	if r.Type == "" {
+		return errors.New("type is required")
+	}
+	if !identifier.MatchString(r.SourceType()) {
+		return fmt.Errorf("invalid type %q", r.Type)
+	}
+	if r.RenameTo != "" && !identifier.MatchString(r.RenameTo) {
+		return fmt.Errorf("invalid rename_to %q: give the type without the data. prefix", r.RenameTo)
+	}
+
+	switch r.Generate {
+	case GenerateNone:
+	case GenerateMoved, GenerateImport:
+		if r.RenameTo == "" {
+			return fmt.Errorf("generate: %s needs rename_to", r.Generate)
+		}
+		if r.IsDataSource() {
+			return fmt.Errorf("generate: %s is not supported for data sources", r.Generate)
+		}
+	default:
+		return fmt.Errorf("invalid generate %q: use moved, import or none", r.Generate)
+	}
+	if (r.Generate == GenerateImport) != (r.ImportID != "") {
+		return errors.New("import_id must be set exactly when generate is import")
+	}
+
+	for from, to := range r.Rename {
+		if !identifier.MatchString(from) || !identifier.MatchString(to) {
+			return fmt.Errorf("invalid rename %q: %q", from, to)
+		}
+		if _, ok := r.Rename[to]; ok {
+			return fmt.Errorf("rename %s: %s is itself renamed", from, to)
+		}
+	}
+	for name, value := range r.Defaults {
+		switch value.(type) {
+		case string, int, float64, bool:
+		default:
+			return fmt.Errorf("default %s must be a string, number or boolean", name)
+		}
+	}
+	for _, c := range r.BlocksToAttribute {
+		if c.Block == "" {
+			return errors.New("blocks_to_attribute: block is required")
+		}
+	}
+	for _, d := range r.Diagnostics {
+		switch d.Severity {
+		case "", SeverityError, SeverityWarning, SeverityInfo:
+		default:
+			return fmt.Errorf("invalid diagnostic severity %q: use error, warning or info", d.Severity)
+		}
+		if d.Summary == "" {
+			return errors.New("diagnostic summary is required")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package rules
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	rules, err := Parse([]byte(`
+rules:
+  - type: cloudflare_worker_domain
+    rename_to: cloudflare_workers_custom_domain
+    rename:
+      hostname: name
+    defaults:
+      enabled: true
+      ttl: 1
+  - type: data.cloudflare_widgets
+    rename_to: cloudflare_gizmos
+  - type: cloudflare_queue
+    source_version: v5
+    target_version: v6
+`), "custom.yaml")
+	require.NoError(t, err)
+	require.Len(t, rules, 3)
+
+	assert.Equal(t, "v4", rules[0].SourceVersion)
+	assert.Equal(t, "v5", rules[0].TargetVersion)
+	assert.Equal(t, GenerateMoved, rules[0].Generate)
+	assert.Equal(t, map[string]interface{}{"enabled": true, "ttl": 1}, rules[0].Defaults)
+	assert.Equal(t, "custom.yaml", rules[0].File())
+
+	assert.True(t, rules[1].IsDataSource())
+	assert.Equal(t, "cloudflare_widgets", rules[1].SourceType())
+	assert.Equal(t, "cloudflare_gizmos", rules[1].TargetType())
+	assert.Equal(t, GenerateNone, rules[1].Generate)
+
+	assert.Equal(t, "cloudflare_queue", rules[2].TargetType())
+	assert.Equal(t, GenerateNone, rules[2].Generate)
+
+	empty, err := Parse(nil, "empty.yaml")
+	require.NoError(t, err)
+	assert.Empty(t, empty)
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		err  string
+	}{
+		{"unknown key", "rules:\n  - type: cloudflare_a\n    renames: {a: b}\n", "field renames not found"},
+		{"missing type", "rules:\n  - rename: {a: b}\n", "rule 1 (): type is required"},
+		{"invalid type", "rules:\n  - type: Cloudflare-A\n", `invalid type "Cloudflare-A"`},
+		{"prefixed rename_to", "rules:\n  - type: data.cloudflare_a\n    rename_to: data.cloudflare_b\n", "give the type without the data. prefix"},
+		{"generate without rename", "rules:\n  - type: cloudflare_a\n    generate: moved\n", "generate: moved needs rename_to"},
+		{"moved data source", "rules:\n  - type: data.cloudflare_a\n    rename_to: cloudflare_b\n    generate: moved\n", "not supported for data sources"},
+		{"import without id", "rules:\n  - type: cloudflare_a\n    rename_to: cloudflare_b\n    generate: import\n", "import_id must be set"},
+		{"chained rename", "rules:\n  - type: cloudflare_a\n    rename: {a: b, b: c}\n", "rename a: b is itself renamed"},
+		{"list default", "rules:\n  - type: cloudflare_a\n    defaults: {a: [1]}\n", "default a must be a string, number or boolean"},
+		{"severity", "rules:\n  - type: cloudflare_a\n    diagnostics:\n      - severity: fatal\n        summary: x\n", `invalid diagnostic severity "fatal"`},
+		{"duplicate", "rules:\n  - type: cloudflare_a\n  - type: cloudflare_a\n", "cloudflare_a is defined more than once"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.yaml), "rules.yaml")
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "rules.yaml")
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("rules:\n  - type: cloudflare_a\n"), 0644))
+
+	rules, err := Load(path)
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	assert.Equal(t, "cloudflare_a", rules[0].Type)
+
+	_, err = Load(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.ErrorContains(t, err, "failed to read rule file")
+}
@@ -0,0 +1,98 @@
+// Package schema loads the Cloudflare provider schema printed by
+// `terraform providers schema -json` and validates configuration against it,
+// so that migrated files can be checked offline, before `terraform init`.
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ProviderSchema is the schema of the Cloudflare provider.
+type ProviderSchema struct {
+	Provider          *Schema            `json:"provider"`
+	ResourceSchemas   map[string]*Schema `json:"resource_schemas"`
+	DataSourceSchemas map[string]*Schema `json:"data_source_schemas"`
+}
+
+// Schema is the schema of a provider configuration, resource or data source.
+type Schema struct {
+	Version int    `json:"version"`
+	Block   *Block `json:"block"`
+}
+
+// Block is the schema of a configuration block.
+type Block struct {
+	Attributes map[string]*Attribute   `json:"attributes"`
+	BlockTypes map[string]*NestedBlock `json:"block_types"`
+}
+
+// Attribute is the schema of an attribute. Attributes of the v5 provider
+// that hold objects are described by NestedType rather than Type.
+type Attribute struct {
+	Type       json.RawMessage `json:"type"`
+	NestedType *NestedType     `json:"nested_type"`
+	Required   bool            `json:"required"`
+	Optional   bool            `json:"optional"`
+	Computed   bool            `json:"computed"`
+	Deprecated bool            `json:"deprecated"`
+}
+
+// ComputedOnly reports whether the attribute is set by the provider and
+// cannot be set in configuration.
+func (a *Attribute) ComputedOnly() bool {
+	return a.Computed && !a.Optional && !a.Required
+}
+
+// NestedType is the schema of an attribute holding objects.
+type NestedType struct {
+	Attributes  map[string]*Attribute `json:"attributes"`
+	NestingMode string                `json:"nesting_mode"` // single, list, set or map
+}
+
+// NestedBlock is the schema of a nested block type.
+type NestedBlock struct {
+	Block       *Block `json:"block"`
+	NestingMode string `json:"nesting_mode"`
+	MinItems    int    `json:"min_items"`
+	MaxItems    int    `json:"max_items"`
+}
+
+// providerSchemas is the document printed by `terraform providers schema -json`.
+type providerSchemas struct {
+	FormatVersion   string                     `json:"format_version"`
+	ProviderSchemas map[string]*ProviderSchema `json:"provider_schemas"`
+}
+
+// Load reads the Cloudflare provider schema from a file.
+func Load(path string) (*ProviderSchema, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read provider schema: %w", err)
+	}
+	s, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse provider schema %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// Parse parses the output of `terraform providers schema -json` (or
+// `tofu providers schema -json`) and returns the Cloudflare provider schema.
+func Parse(data []byte) (*ProviderSchema, error) {
+	var doc providerSchemas
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if doc.FormatVersion == "" {
+		return nil, fmt.Errorf("expected the output of `terraform providers schema -json`")
+	}
+	for address, s := range doc.ProviderSchemas {
+		if strings.HasSuffix(address, "/cloudflare/cloudflare") || address == "cloudflare/cloudflare" {
+			return s, nil
+		}
+	}
+	return nil, fmt.Errorf("the schema does not include the cloudflare/cloudflare provider")
+}
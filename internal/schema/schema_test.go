@@ -0,0 +1,115 @@
+package schema
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testSchema is a trimmed down `terraform providers schema -json` document
+// in the shape of the v5 provider.
+const testSchema = `{
+  "format_version": "1.0",
+  "provider_schemas": {
+    "registry.terraform.io/cloudflare/cloudflare": {
+      "provider": {
+        "version": 0,
+        "block": {
+          "attributes": {
+            "api_token": {"type": "string", "optional": true, "sensitive": true},
+            "base_url": {"type": "string", "optional": true}
+          }
+        }
+      },
+      "resource_schemas": {
+        "cloudflare_dns_record": {
+          "version": 0,
+          "block": {
+            "attributes": {
+              "id": {"type": "string", "computed": true},
+              "zone_id": {"type": "string", "required": true},
+              "name": {"type": "string", "required": true},
+              "type": {"type": "string", "required": true},
+              "ttl": {"type": "number", "required": true},
+              "content": {"type": "string", "optional": true},
+              "data": {
+                "nested_type": {
+                  "attributes": {
+                    "priority": {"type": "number", "optional": true},
+                    "target": {"type": "string", "optional": true},
+                    "flags": {"type": "dynamic", "optional": true}
+                  },
+                  "nesting_mode": "single"
+                },
+                "optional": true
+              }
+            }
+          }
+        },
+        "cloudflare_ruleset": {
+          "version": 0,
+          "block": {
+            "attributes": {
+              "zone_id": {"type": "string", "optional": true},
+              "name": {"type": "string", "required": true},
+              "rules": {
+                "nested_type": {
+                  "attributes": {
+                    "action": {"type": "string", "required": true},
+                    "expression": {"type": "string", "required": true},
+                    "id": {"type": "string", "computed": true}
+                  },
+                  "nesting_mode": "list"
+                },
+                "optional": true
+              }
+            },
+            "block_types": {
+              "timeouts": {
+                "nesting_mode": "single",
+                "block": {"attributes": {"create": {"type": "string", "optional": true}}}
+              }
+            }
+          }
+        }
+      },
+      "data_source_schemas": {
+        "cloudflare_zone": {
+          "version": 0,
+          "block": {"attributes": {"zone_id": {"type": "string", "optional": true}}}
+        }
+      }
+    }
+  }
+}`
+
+func TestParse(t *testing.T) {
+	s, err := Parse([]byte(testSchema))
+	require.NoError(t, err)
+	require.Contains(t, s.ResourceSchemas, "cloudflare_dns_record")
+	assert.True(t, s.ResourceSchemas["cloudflare_dns_record"].Block.Attributes["zone_id"].Required)
+	assert.True(t, s.ResourceSchemas["cloudflare_dns_record"].Block.Attributes["id"].ComputedOnly())
+	assert.Equal(t, "list", s.ResourceSchemas["cloudflare_ruleset"].Block.Attributes["rules"].NestedType.NestingMode)
+	assert.Contains(t, s.DataSourceSchemas, "cloudflare_zone")
+
+	_, err = Parse([]byte(`{"format_version": "1.0", "provider_schemas": {"registry.terraform.io/hashicorp/aws": {}}}`))
+	assert.ErrorContains(t, err, "cloudflare/cloudflare")
+
+	_, err = Parse([]byte(`{"version": 4}`))
+	assert.ErrorContains(t, err, "terraform providers schema -json")
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schema.json")
+	require.NoError(t, os.WriteFile(path, []byte(testSchema), 0644))
+
+	s, err := Load(path)
+	require.NoError(t, err)
+	assert.NotNil(t, s.Provider)
+
+	_, err = Load(filepath.Join(t.TempDir(), "missing.json"))
+	assert.ErrorContains(t, err, "failed to read provider schema")
+}
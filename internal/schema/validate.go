@@ -0,0 +1,267 @@
+package schema
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+
+	tfhcl "github.com/cloudflare/tf-migrate/internal/transform/hcl"
+)
+
+// Meta-arguments Terraform accepts in every resource and data block, and in
+// every provider block.
+var (
+	metaArguments         = map[string]bool{"count": true, "for_each": true, "provider": true, "depends_on": true}
+	providerMetaArguments = map[string]bool{"alias": true, "version": true}
+	resourceBlocks        = map[string]bool{"lifecycle": true, "provisioner": true, "connection": true}
+	dataBlocks            = map[string]bool{"lifecycle": true}
+)
+
+// Validate checks the cloudflare_* resource and data blocks, and the
+// cloudflare provider block, of a configuration file in native syntax
+// against the provider schema. It reports unknown attributes and blocks,
+// missing required attributes and blocks, attributes written as blocks and
+// blocks written as attributes, and computed-only attributes that are set.
+// All problems are warnings: the file is still written, but needs fixing
+// before `terraform validate` passes.
+//
+// filename is used in diagnostics, whose Subject ranges point into content.
+// A .tf.json file is validated through its native syntax form, so its
+// diagnostics carry no line or Subject.
+func Validate(s *ProviderSchema, filename string, content []byte) hcl.Diagnostics {
+	v := &validator{filename: filename, noLines: tfhcl.IsJSONConfigFile(filename)}
+	native, err := tfhcl.NativeSyntax(content, filename)
+	if err != nil {
+		return nil
+	}
+	file, diags := hclsyntax.ParseConfig(native, filename, hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil
+	}
+	body, ok := file.Body.(*hclsyntax.Body)
+	if !ok {
+		return nil
+	}
+
+	for _, block := range body.Blocks {
+		switch {
+		case block.Type == "resource" && len(block.Labels) == 2 && strings.HasPrefix(block.Labels[0], "cloudflare_"):
+			v.validateTopLevel(block, block.Labels[0]+"."+block.Labels[1], s.ResourceSchemas[block.Labels[0]], resourceBlocks)
+		case block.Type == "data" && len(block.Labels) == 2 && strings.HasPrefix(block.Labels[0], "cloudflare_"):
+			v.validateTopLevel(block, "data."+block.Labels[0]+"."+block.Labels[1], s.DataSourceSchemas[block.Labels[0]], dataBlocks)
+		case block.Type == "provider" && len(block.Labels) == 1 && block.Labels[0] == "cloudflare" && s.Provider != nil:
+			v.validateBody(block, block.Body, "provider.cloudflare", "provider", s.Provider.Block, providerMetaArguments, nil)
+		}
+	}
+	return v.diags
+}
+
+type validator struct {
+	filename string
+	noLines  bool
+	diags    hcl.Diagnostics
+}
+
+func (v *validator) validateTopLevel(block *hclsyntax.Block, address string, s *Schema, allowedBlocks map[string]bool) {
+	if s == nil || s.Block == nil {
+		v.report(block.TypeRange, fmt.Sprintf("Type not in provider schema: %s", block.Labels[0]),
+			fmt.Sprintf("%s uses a type the provider schema does not define. It may not have been migrated.", address))
+		return
+	}
+	v.validateBody(block, block.Body, address, block.Labels[0], s.Block, metaArguments, allowedBlocks)
+}
+
+// validateBody checks the body of block against schema. For dynamic blocks,
+// body is the body of the content block. address names the block in
+// diagnostics, e.g. "cloudflare_ruleset.r.rules"; path is the same without
+// the resource name, so that diagnostics about the same attribute of
+// different resources are consolidated. metaArgs and allowedBlocks are the
+// meta-arguments and blocks Terraform accepts besides the schema, if any.
+func (v *validator) validateBody(block *hclsyntax.Block, body *hclsyntax.Body, address, path string, schema *Block, metaArgs, allowedBlocks map[string]bool) {
+	for _, name := range sortedAttributeNames(body) {
+		attr := body.Attributes[name]
+		if metaArgs[name] {
+			continue
+		}
+		attrSchema, ok := schema.Attributes[name]
+		switch {
+		case ok && attrSchema.ComputedOnly():
+			v.report(attr.NameRange, fmt.Sprintf("Computed attribute set: %s.%s", path, name),
+				fmt.Sprintf("%s sets %s, which is computed by the provider and cannot be set in configuration.", address, name))
+		case ok:
+			if attrSchema.NestedType != nil {
+				v.validateNestedValue(attr.Expr, address+"."+name, path+"."+name, attrSchema.NestedType)
+			}
+		case schema.BlockTypes[name] != nil:
+			v.report(attr.NameRange, fmt.Sprintf("Attribute should be a block: %s.%s", path, name),
+				fmt.Sprintf("%s sets %s as an attribute, but the provider schema defines it as a block: write %s { ... }.", address, name, name))
+		default:
+			v.report(attr.NameRange, fmt.Sprintf("Unsupported attribute: %s.%s", path, name),
+				fmt.Sprintf("%s sets %s, which the provider schema does not define.", address, name))
+		}
+	}
+
+	present := make(map[string]bool)
+	for _, child := range body.Blocks {
+		blockType := child.Type
+		blockBody := child.Body
+		if blockType == "dynamic" && len(child.Labels) == 1 {
+			blockType = child.Labels[0]
+			for _, inner := range child.Body.Blocks {
+				if inner.Type == "content" {
+					blockBody = inner.Body
+				}
+			}
+		} else if allowedBlocks[blockType] {
+			continue
+		}
+		present[blockType] = true
+
+		nested, ok := schema.BlockTypes[blockType]
+		switch {
+		case ok && nested.Block != nil:
+			v.validateBody(child, blockBody, address+"."+blockType, path+"."+blockType, nested.Block, nil, nil)
+		case ok:
+			// A block type without a body schema cannot be checked further.
+		case schema.Attributes[blockType] != nil:
+			v.report(child.TypeRange, fmt.Sprintf("Block should be an attribute: %s.%s", path, blockType),
+				fmt.Sprintf("%s writes %s as a block, but the provider schema defines it as an attribute: write %s = { ... }.", address, blockType, blockType))
+		default:
+			v.report(child.TypeRange, fmt.Sprintf("Unsupported block: %s.%s", path, blockType),
+				fmt.Sprintf("%s has a %s block, which the provider schema does not define.", address, blockType))
+		}
+	}
+
+	for _, name := range sortedKeys(schema.Attributes) {
+		if schema.Attributes[name].Required && body.Attributes[name] == nil && !present[name] {
+			v.report(block.DefRange(), fmt.Sprintf("Missing required attribute: %s.%s", path, name),
+				fmt.Sprintf("%s does not set %s, which the provider schema requires.", address, name))
+		}
+	}
+	for _, name := range sortedKeys(schema.BlockTypes) {
+		if schema.BlockTypes[name].MinItems > 0 && !present[name] && body.Attributes[name] == nil {
+			v.report(block.DefRange(), fmt.Sprintf("Missing required block: %s.%s", path, name),
+				fmt.Sprintf("%s has no %s block, which the provider schema requires.", address, name))
+		}
+	}
+}
+
+// validateNestedValue checks the keys of object literals assigned to an
+// attribute with a nested type. Values that are not literals, such as
+// variables or for expressions, cannot be checked statically and are skipped.
+func (v *validator) validateNestedValue(expr hclsyntax.Expression, address, path string, nested *NestedType) {
+	var objects []*hclsyntax.ObjectConsExpr
+	switch nested.NestingMode {
+	case "single":
+		if obj, ok := expr.(*hclsyntax.ObjectConsExpr); ok {
+			objects = append(objects, obj)
+		}
+	case "list", "set":
+		if tuple, ok := expr.(*hclsyntax.TupleConsExpr); ok {
+			for _, item := range tuple.Exprs {
+				if obj, ok := item.(*hclsyntax.ObjectConsExpr); ok {
+					objects = append(objects, obj)
+				}
+			}
+		}
+	case "map":
+		if obj, ok := expr.(*hclsyntax.ObjectConsExpr); ok {
+			for _, item := range obj.Items {
+				if value, ok := item.ValueExpr.(*hclsyntax.ObjectConsExpr); ok {
+					objects = append(objects, value)
+				}
+			}
+		}
+	}
+
+	for _, obj := range objects {
+		set := make(map[string]bool)
+		for _, item := range obj.Items {
+			key, ok := objectKey(item.KeyExpr)
+			if !ok {
+				// A computed key means not every key is known.
+				set = nil
+				continue
+			}
+			if set != nil {
+				set[key] = true
+			}
+			attrSchema, ok := nested.Attributes[key]
+			switch {
+			case !ok:
+				v.report(item.KeyExpr.Range(), fmt.Sprintf("Unsupported attribute: %s.%s", path, key),
+					fmt.Sprintf("%s sets %s, which the provider schema does not define.", address, key))
+			case attrSchema.ComputedOnly():
+				v.report(item.KeyExpr.Range(), fmt.Sprintf("Computed attribute set: %s.%s", path, key),
+					fmt.Sprintf("%s sets %s, which is computed by the provider and cannot be set in configuration.", address, key))
+			case attrSchema.NestedType != nil:
+				v.validateNestedValue(item.ValueExpr, address+"."+key, path+"."+key, attrSchema.NestedType)
+			}
+		}
+		if set == nil {
+			continue
+		}
+		for _, name := range sortedKeys(nested.Attributes) {
+			if nested.Attributes[name].Required && !set[name] {
+				v.report(obj.OpenRange, fmt.Sprintf("Missing required attribute: %s.%s", path, name),
+					fmt.Sprintf("%s does not set %s, which the provider schema requires.", address, name))
+			}
+		}
+	}
+}
+
+// objectKey returns the key of an object item when it is a static name or
+// string.
+func objectKey(expr hclsyntax.Expression) (string, bool) {
+	if wrapped, ok := expr.(*hclsyntax.ObjectConsKeyExpr); ok {
+		if name := hcl.ExprAsKeyword(wrapped.Wrapped); name != "" && !wrapped.ForceNonLiteral {
+			return name, true
+		}
+		expr = wrapped.Wrapped
+	}
+	if template, ok := expr.(*hclsyntax.TemplateExpr); ok && template.IsStringLiteral() {
+		value, diags := template.Value(nil)
+		if !diags.HasErrors() {
+			return value.AsString(), true
+		}
+	}
+	return "", false
+}
+
+func (v *validator) report(rng hcl.Range, summary, detail string) {
+	diag := &hcl.Diagnostic{
+		Severity: hcl.DiagWarning,
+		Summary:  summary,
+		Detail:   fmt.Sprintf("In %s (line %d)\n\n%s", filepath.Base(v.filename), rng.Start.Line, detail),
+		Subject:  rng.Ptr(),
+	}
+	if v.noLines {
+		diag.Detail = fmt.Sprintf("In %s\n\n%s", filepath.Base(v.filename), detail)
+		diag.Subject = nil
+	}
+	v.diags = append(v.diags, diag)
+}
+
+func sortedAttributeNames(body *hclsyntax.Body) []string {
+	names := make([]string, 0, len(body.Attributes))
+	for name := range body.Attributes {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return body.Attributes[names[i]].SrcRange.Start.Byte < body.Attributes[names[j]].SrcRange.Start.Byte
+	})
+	return names
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,156 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	s, err := Parse([]byte(testSchema))
+	require.NoError(t, err)
+
+	summaries := func(diags hcl.Diagnostics) []string {
+		var out []string
+		for _, diag := range diags {
+			out = append(out, diag.Summary)
+		}
+		return out
+	}
+
+	t.Run("valid configuration", func(t *testing.T) {
+		diags := Validate(s, "main.tf", []byte(`
+provider "cloudflare" {
+  api_token = var.token
+}
+
+resource "cloudflare_dns_record" "srv" {
+  count   = 2
+  zone_id = var.zone_id
+  name    = "_sip._tcp"
+  type    = "SRV"
+  ttl     = 1
+  data = {
+    priority = 5
+    target   = "sip.example.com"
+  }
+  lifecycle {
+    ignore_changes = [ttl]
+  }
+}
+
+resource "cloudflare_ruleset" "r" {
+  name  = "r"
+  rules = [{ action = "block", expression = "true" }]
+  timeouts {
+    create = "1m"
+  }
+}
+
+resource "aws_instance" "ignored" {
+  anything = true
+}
+
+data "cloudflare_zone" "z" {
+  zone_id = var.zone_id
+}
+`))
+		assert.Empty(t, diags)
+	})
+
+	t.Run("problems are reported with file and line", func(t *testing.T) {
+		diags := Validate(s, "/config/dns.tf", []byte(`resource "cloudflare_dns_record" "www" {
+  zone_id = var.zone_id
+  name    = "www"
+  value   = "192.0.2.1"
+  id      = "abc"
+  data {
+    priority = 5
+  }
+}
+`))
+		assert.Equal(t, []string{
+			"Unsupported attribute: cloudflare_dns_record.value",
+			"Computed attribute set: cloudflare_dns_record.id",
+			"Block should be an attribute: cloudflare_dns_record.data",
+			"Missing required attribute: cloudflare_dns_record.ttl",
+			"Missing required attribute: cloudflare_dns_record.type",
+		}, summaries(diags))
+
+		require.NotNil(t, diags[0].Subject)
+		assert.Equal(t, 4, diags[0].Subject.Start.Line)
+		assert.Equal(t, "/config/dns.tf", diags[0].Subject.Filename)
+		assert.Equal(t, hcl.DiagWarning, diags[0].Severity)
+		assert.Contains(t, diags[0].Detail, "In dns.tf (line 4)")
+		assert.Contains(t, diags[0].Detail, "cloudflare_dns_record.www sets value")
+	})
+
+	t.Run("nested attributes and blocks", func(t *testing.T) {
+		diags := Validate(s, "main.tf", []byte(`resource "cloudflare_ruleset" "r" {
+  name  = "r"
+  rules = [
+    { action = "block", expression = "true", enabled = true },
+    { action = "skip", id = "x" },
+    local.rule,
+  ]
+  timeouts = {
+    create = "1m"
+  }
+  dynamic "rules" {
+    for_each = var.rules
+    content {
+      action = rules.value
+    }
+  }
+}
+`))
+		assert.Equal(t, []string{
+			"Unsupported attribute: cloudflare_ruleset.rules.enabled",
+			"Computed attribute set: cloudflare_ruleset.rules.id",
+			"Missing required attribute: cloudflare_ruleset.rules.expression",
+			"Attribute should be a block: cloudflare_ruleset.timeouts",
+			"Block should be an attribute: cloudflare_ruleset.rules",
+		}, summaries(diags))
+	})
+
+	t.Run("provider meta-arguments", func(t *testing.T) {
+		diags := Validate(s, "main.tf", []byte(`provider "cloudflare" {
+  alias     = "secondary"
+  version   = "~> 5.0"
+  api_token = var.token
+}
+`))
+		assert.Empty(t, diags)
+	})
+
+	t.Run("unknown types and provider arguments", func(t *testing.T) {
+		diags := Validate(s, "main.tf", []byte(`provider "cloudflare" {
+  api_hostname = "api.example.com"
+}
+
+resource "cloudflare_record" "old" {
+  zone_id = var.zone_id
+}
+`))
+		assert.Equal(t, []string{
+			"Unsupported attribute: provider.api_hostname",
+			"Type not in provider schema: cloudflare_record",
+		}, summaries(diags))
+	})
+
+	t.Run("JSON configuration", func(t *testing.T) {
+		diags := Validate(s, "main.tf.json", []byte(`{
+  "resource": {
+    "cloudflare_dns_record": {
+      "www": {"zone_id": "z", "name": "www", "type": "A", "ttl": 1, "value": "192.0.2.1"}
+    }
+  }
+}`))
+		require.Len(t, diags, 1)
+		assert.Equal(t, "Unsupported attribute: cloudflare_dns_record.value", diags[0].Summary)
+		assert.Nil(t, diags[0].Subject)
+		assert.Contains(t, diags[0].Detail, "In main.tf.json\n")
+	})
+}
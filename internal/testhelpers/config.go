@@ -17,6 +17,10 @@ type ConfigTestCase struct {
 	Name     string
 	Input    string
 	Expected string
+	// State is an optional state snapshot the migrator can read from.
+	State *transform.StateSnapshot
+	// EvalContext optionally holds the variables and locals of the module.
+	EvalContext *hcl.EvalContext
 }
 
 // runConfigTransformTest runs a single configuration transformation test
@@ -33,9 +37,11 @@ func runConfigTransformTest(t *testing.T, tt ConfigTestCase, migrator transform.
 
 	// Step 3: Create context with preprocessed content
 	ctx := &transform.Context{
-		Content:  []byte(processedContent),
-		Filename: "test.tf",
-		CFGFile:  file,
+		Content:     []byte(processedContent),
+		Filename:    "test.tf",
+		CFGFile:     file,
+		State:       tt.State,
+		EvalContext: tt.EvalContext,
 	}
 
 	// Step 4: Transform using HCL CFGFile
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/hashicorp/hcl/v2/hclwrite"
 )
 
@@ -124,8 +125,8 @@ func compareAttributeValues(t *testing.T, path string, expected, actual *hclwrit
 	actualStr = strings.TrimSpace(actualStr)
 
 	// If both start with '{', they're object expressions - parse and compare recursively
-	if strings.HasPrefix(expectedStr, "{") && strings.HasPrefix(actualStr, "{") {
-		return compareObjectExpression(t, path, expectedStr, actualStr)
+	if isObjectConstructor(expectedTokens) && isObjectConstructor(actualTokens) {
+		return compareObjectExpression(t, path, string(expectedTokens.Bytes()), string(actualTokens.Bytes()))
 	}
 
 	// For non-object values, compare tokens with normalization
@@ -202,7 +203,7 @@ func compareObjectTokens(t *testing.T, path string, expected, actual hclwrite.To
 		expectedStr := strings.TrimSpace(tokensToString(expectedValue))
 		actualStr := strings.TrimSpace(tokensToString(actualValue))
 
-		if strings.HasPrefix(expectedStr, "{") && strings.HasPrefix(actualStr, "{") {
+		if isObjectConstructor(expectedValue) && isObjectConstructor(actualValue) {
 			// Nested object - compare recursively
 			if !compareObjectTokens(t, path+"."+key, expectedValue, actualValue) {
 				equal = false
@@ -255,7 +256,7 @@ func compareArrayTokens(t *testing.T, path string, expected, actual hclwrite.Tok
 		expectedStr := strings.TrimSpace(tokensToString(expectedElements[i]))
 		actualStr := strings.TrimSpace(tokensToString(actualElements[i]))
 
-		if strings.HasPrefix(expectedStr, "{") && strings.HasPrefix(actualStr, "{") {
+		if isObjectConstructor(expectedElements[i]) && isObjectConstructor(actualElements[i]) {
 			// Nested object - compare recursively
 			if !compareObjectTokens(t, elementPath, expectedElements[i], actualElements[i]) {
 				equal = false
@@ -467,6 +468,26 @@ func normalizeTokens(tokens hclwrite.Tokens) hclwrite.Tokens {
 	return result
 }
 
+// isObjectConstructor reports whether tokens are an object constructor, such as
+// { key = value }, rather than an object for expression, { for k, v in m : k => v },
+// which is compared as a simple value.
+func isObjectConstructor(tokens hclwrite.Tokens) bool {
+	var significant hclwrite.Tokens
+	for _, tok := range tokens {
+		if tok.Type != hclsyntax.TokenNewline {
+			significant = append(significant, tok)
+		}
+		if len(significant) == 3 {
+			break
+		}
+	}
+	if len(significant) == 0 || significant[0].Type != hclsyntax.TokenOBrace {
+		return false
+	}
+	return len(significant) < 3 || significant[1].Type != hclsyntax.TokenIdent ||
+		string(significant[1].Bytes) != "for" || significant[2].Type != hclsyntax.TokenIdent
+}
+
 // tokensToString converts tokens to a string representation for error messages
 func tokensToString(tokens hclwrite.Tokens) string {
 	var result strings.Builder
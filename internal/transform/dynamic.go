@@ -0,0 +1,28 @@
+package transform
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+
+	tfhcl "github.com/cloudflare/tf-migrate/internal/transform/hcl"
+)
+
+// WarnDynamicBlocks records a warning for each dynamic block generating
+// blocks of blockType that is left in body, because the migrator could not
+// write it as a for expression in the attribute that replaces those blocks in
+// v5. resource is the address of the resource, e.g.
+// "cloudflare_load_balancer_monitor.example".
+func (ctx *Context) WarnDynamicBlocks(body *hclwrite.Body, resource, blockType string) {
+	for range tfhcl.FindDynamicBlocks(body, blockType) {
+		ctx.Diagnostics = append(ctx.Diagnostics, &hcl.Diagnostic{
+			Severity: hcl.DiagWarning,
+			Summary:  fmt.Sprintf("Dynamic block requires manual migration: %s", resource),
+			Detail: fmt.Sprintf(`The dynamic "%s" block could not be rewritten as a for expression and was left unchanged.
+
+The v5 provider uses an attribute instead of %s blocks. Rewrite the dynamic block as a for
+expression in that attribute, or use for_each at the resource level.`, blockType, blockType),
+		})
+	}
+}
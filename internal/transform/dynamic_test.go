@@ -0,0 +1,38 @@
+package transform
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWarnDynamicBlocks(t *testing.T) {
+	file, diags := hclwrite.ParseConfig([]byte(`resource "cloudflare_healthcheck" "example" {
+  header {
+    header = "Host"
+    values = ["example.com"]
+  }
+  dynamic "header" {
+    for_each = var.headers
+    content {
+      header = header.key
+    }
+  }
+  dynamic "origins" {
+    for_each = var.origins
+    content {}
+  }
+}`), "main.tf", hcl.InitialPos)
+	require.False(t, diags.HasErrors())
+
+	ctx := &Context{}
+	ctx.WarnDynamicBlocks(file.Body().Blocks()[0].Body(), "cloudflare_healthcheck.example", "header")
+
+	require.Len(t, ctx.Diagnostics, 1)
+	assert.Equal(t, hcl.DiagWarning, ctx.Diagnostics[0].Severity)
+	assert.Equal(t, "Dynamic block requires manual migration: cloudflare_healthcheck.example", ctx.Diagnostics[0].Summary)
+	assert.Contains(t, ctx.Diagnostics[0].Detail, `dynamic "header"`)
+}
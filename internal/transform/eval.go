@@ -0,0 +1,46 @@
+package transform
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+
+	tfhcl "github.com/cloudflare/tf-migrate/internal/transform/hcl"
+)
+
+// EvaluateAttribute returns the value of attr when it can be known without
+// planning: a literal, or an expression over the module's variables and
+// locals (see tfhcl.BuildEvalContext). It reports false for anything else, in
+// which case migrators keep their behaviour for dynamic values.
+//
+// Values resolved through variables or locals are recorded as an info
+// diagnostic, since they reflect defaults and .tfvars files rather than what
+// a particular plan will use.
+func (ctx *Context) EvaluateAttribute(attr *hclwrite.Attribute) (cty.Value, bool) {
+	value, literal, ok := tfhcl.EvaluateAttribute(attr, ctx.EvalContext)
+	if !ok {
+		return cty.NilVal, false
+	}
+	if !literal {
+		expr := strings.TrimSpace(string(attr.Expr().BuildTokens(nil).Bytes()))
+		ctx.Diagnostics = append(ctx.Diagnostics, &hcl.Diagnostic{
+			Severity: DiagInfo,
+			Summary:  fmt.Sprintf("Resolved %s statically in %s", expr, ctx.Filename),
+			Detail:   "The value was taken from variable defaults, locals and .tfvars files. Check that it matches the value used when you plan.",
+		})
+	}
+	return value, true
+}
+
+// EvaluateString is EvaluateAttribute for attributes that hold a string. It
+// reports false when the value is null or not a string.
+func (ctx *Context) EvaluateString(attr *hclwrite.Attribute) (string, bool) {
+	value, ok := ctx.EvaluateAttribute(attr)
+	if !ok || value.IsNull() || value.Type() != cty.String {
+		return "", false
+	}
+	return value.AsString(), true
+}
@@ -99,7 +99,16 @@ func GetResourceName(block *hclwrite.Block) string {
 //	    value = "letsencrypt.org"  # Renamed by preProcess
 //	  }
 //	}
+//
+// Dynamic blocks of the type are converted too, with preProcess called on
+// their content blocks, and the attribute becomes one() of the list of
+// generated objects.
 func ConvertBlocksToAttribute(body *hclwrite.Body, blockType, attrName string, preProcess func(*hclwrite.Block)) {
+	if blocks := FindBlocksWithDynamic(body, blockType); hasDynamicBlock(blocks) {
+		convertBlocksToOneAttribute(body, blocks, attrName, preProcess)
+		return
+	}
+
 	var blocksToRemove []*hclwrite.Block
 
 	for _, block := range body.Blocks() {
@@ -112,9 +121,11 @@ func ConvertBlocksToAttribute(body *hclwrite.Body, blockType, attrName string, p
 			preProcess(block)
 		}
 
-		// Convert block to object tokens
+		// Convert block to object tokens, keeping the comments around it
+		// above the attribute
+		c := blockComments(block)
 		objTokens := BuildObjectFromBlock(block)
-		body.SetAttributeRaw(attrName, objTokens)
+		setAttributeRawWithComments(body, attrName, objTokens, append(c.lead, c.line...))
 		blocksToRemove = append(blocksToRemove, block)
 	}
 
@@ -229,21 +240,50 @@ func ProcessBlocksOfType(body *hclwrite.Body, blockType string, processor func(*
 
 // ConvertSingleBlockToAttribute converts the first block of a type to an attribute
 // This is useful when a resource changes from having a single block to an attribute
+// When the block is generated by a dynamic block, the attribute becomes one() of
+// the list of generated objects
 func ConvertSingleBlockToAttribute(body *hclwrite.Body, blockType, attrName string) bool {
+	if blocks := FindBlocksWithDynamic(body, blockType); hasDynamicBlock(blocks) {
+		convertBlocksToOneAttribute(body, blocks, attrName, nil)
+		return true
+	}
+
 	block := FindBlockByType(body, blockType)
 	if block == nil {
 		return false
 	}
 
+	c := blockComments(block)
 	objTokens := BuildObjectFromBlock(block)
 
-	body.SetAttributeRaw(attrName, objTokens)
+	setAttributeRawWithComments(body, attrName, objTokens, append(c.lead, c.line...))
 	body.RemoveBlock(block)
 	return true
 }
 
+// convertBlocksToOneAttribute sets attribute attrName to one() of the list
+// of objects produced by blocks, static and dynamic, for a single nested
+// block that a dynamic block generates. preProcess, if not nil, is called on
+// each static block and dynamic content block first.
+func convertBlocksToOneAttribute(body *hclwrite.Body, blocks []*hclwrite.Block, attrName string, preProcess func(*hclwrite.Block)) {
+	if preProcess != nil {
+		for _, block := range blocks {
+			preProcess(ContentBlock(block))
+		}
+	}
+
+	list, comments := buildBlockList(blocks, BuildObjectFromBlock, blockComments)
+	setAttributeRawWithComments(body, attrName, tokensForOne(list), comments)
+	for _, block := range blocks {
+		body.RemoveBlock(block)
+	}
+}
+
 // ConvertBlocksToAttributeList converts multiple blocks of a certain type to an array attribute.
 // The preProcess function is called on each block before conversion (can be nil).
+// Comments above and inside each block are kept next to its object.
+// Dynamic blocks of the type become for expressions, merged with the static
+// blocks via concat(); preProcess is called on their content blocks.
 //
 // Example - Converting destinations blocks to array attribute:
 //
@@ -282,54 +322,32 @@ func ConvertSingleBlockToAttribute(body *hclwrite.Body, blockType, attrName stri
 //	  ]
 //	}
 func ConvertBlocksToAttributeList(body *hclwrite.Body, blockType string, preProcess func(*hclwrite.Block)) bool {
-	blocks := FindBlocksByType(body, blockType)
+	blocks := FindBlocksWithDynamic(body, blockType)
 	if len(blocks) == 0 {
 		return false
 	}
 
-	var arrayTokens hclwrite.Tokens
-
-	// Opening bracket
-	arrayTokens = append(arrayTokens, &hclwrite.Token{
-		Type:  hclsyntax.TokenOBrack,
-		Bytes: []byte("["),
-	})
-
-	// Process each block
-	for i, block := range blocks {
-		// Apply preprocessing if provided
-		if preProcess != nil {
-			preProcess(block)
+	// Apply preprocessing if provided
+	if preProcess != nil {
+		for _, block := range blocks {
+			preProcess(ContentBlock(block))
 		}
+	}
 
-		// Add comma and newline for all but first element
-		if i > 0 {
-			arrayTokens = append(arrayTokens, &hclwrite.Token{
-				Type:  hclsyntax.TokenComma,
-				Bytes: []byte(","),
-			})
+	if hasDynamicBlock(blocks) {
+		// Dynamic blocks become for expressions, merged with the static
+		// blocks around them
+		arrayTokens, comments := buildBlockList(blocks, BuildObjectFromBlock, blockComments)
+		setAttributeRawWithComments(body, blockType, arrayTokens, comments)
+		for _, block := range blocks {
+			body.RemoveBlock(block)
 		}
-
-		// Add newline before each object
-		arrayTokens = append(arrayTokens, &hclwrite.Token{
-			Type:  hclsyntax.TokenNewline,
-			Bytes: []byte("\n"),
-		})
-
-		// Convert block to object tokens
-		objTokens := BuildObjectFromBlock(block)
-		arrayTokens = append(arrayTokens, objTokens...)
+		return true
 	}
 
-	// Closing newline and bracket
-	arrayTokens = append(arrayTokens, &hclwrite.Token{
-		Type:  hclsyntax.TokenNewline,
-		Bytes: []byte("\n"),
-	})
-	arrayTokens = append(arrayTokens, &hclwrite.Token{
-		Type:  hclsyntax.TokenCBrack,
-		Bytes: []byte("]"),
-	})
+	// Convert each block to an object on its own line, keeping the comments
+	// around the block next to its object
+	arrayTokens := tokensForObjectList(blocks, BuildObjectFromBlock)
 
 	// Set the attribute with the array using the same name as the block type
 	body.SetAttributeRaw(blockType, arrayTokens)
@@ -365,15 +383,10 @@ func MergeStaticBlocksIntoAttribute(body *hclwrite.Body, blockType string, exist
 	}
 
 	// Build static blocks as an array: [{ ... }, { ... }]
-	var objectTokens []hclwrite.Tokens
-	for _, block := range blocks {
-		objTokens := BuildObjectFromBlock(block)
-		objectTokens = append(objectTokens, objTokens)
-	}
-	staticArrayTokens := BuildArrayFromObjects(objectTokens)
+	staticArrayTokens := buildArrayFromBlocks(blocks, BuildObjectFromBlock, blockComments)
 
 	// Wrap both in concat()
-	merged := buildConcatExpression([]hclwrite.Tokens{existingExprTokens, staticArrayTokens})
+	merged := BuildConcatExpression([]hclwrite.Tokens{existingExprTokens, staticArrayTokens})
 	body.SetAttributeRaw(blockType, merged)
 
 	// Remove the static blocks
@@ -478,30 +491,93 @@ func CreateImportBlockWithTokens(resourceType, resourceName string, idTokens hcl
 	return block
 }
 
+// IsMigrationBlock reports whether block is a moved, import or removed block,
+// i.e. one of the state-migration blocks created by CreateMovedBlock,
+// CreateImportBlock and CreateRemovedBlock.
+func IsMigrationBlock(block *hclwrite.Block) bool {
+	switch block.Type() {
+	case "moved", "import", "removed":
+		return true
+	}
+	return false
+}
+
+// MigrationBlockKey returns an identity key for moved, import and removed
+// blocks, or "" for any other block. Two blocks with the same key describe the
+// same state operation, so the key can be used to avoid emitting duplicates.
+func MigrationBlockKey(block *hclwrite.Block) string {
+	var attrs []string
+	switch block.Type() {
+	case "moved":
+		attrs = []string{"from", "to"}
+	case "import":
+		attrs = []string{"to"}
+	case "removed":
+		attrs = []string{"from"}
+	default:
+		return ""
+	}
+
+	key := block.Type()
+	for _, name := range attrs {
+		attr := block.Body().GetAttribute(name)
+		if attr == nil {
+			return ""
+		}
+		expr := strings.Join(strings.Fields(string(attr.Expr().BuildTokens(nil).Bytes())), "")
+		key += "|" + expr
+	}
+	return key
+}
+
+// CloneBlock returns a copy of block that shares no tokens with it, or nil if
+// the block does not render to valid HCL.
+func CloneBlock(block *hclwrite.Block) *hclwrite.Block {
+	file, diags := hclwrite.ParseConfig(block.BuildTokens(nil).Bytes(), "", hcl.InitialPos)
+	if diags.HasErrors() || len(file.Body().Blocks()) == 0 {
+		return nil
+	}
+	return file.Body().Blocks()[0]
+}
+
 // BuildObjectFromBlock creates object tokens from a block's attributes
-// Useful for converting block syntax to object syntax
+// Useful for converting block syntax to object syntax. Comments on and
+// between the attributes are carried into the object; comments around the
+// block itself are left to the caller.
 func BuildObjectFromBlock(block *hclwrite.Block) hclwrite.Tokens {
+	comments := collectBodyComments(block.Body())
+
 	// Get attributes in their original order
 	orderedAttrs := AttributesOrdered(block.Body())
 
-	// Build a list of attribute tokens preserving the original order
-	var attrs []hclwrite.ObjectAttrTokens
-
+	tokens := hclwrite.Tokens{{Type: hclsyntax.TokenOBrace, Bytes: []byte("{")}}
+	if len(orderedAttrs) > 0 || len(comments.trailing) > 0 {
+		tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenNewline, Bytes: []byte("\n")})
+	}
 	for _, attrInfo := range orderedAttrs {
-		// Create tokens for the attribute name (as a simple identifier)
-		nameTokens := hclwrite.TokensForIdentifier(attrInfo.Name)
-
-		// Get the value tokens from the attribute's expression
-		valueTokens := attrInfo.Attribute.Expr().BuildTokens(nil)
-
-		attrs = append(attrs, hclwrite.ObjectAttrTokens{
-			Name:  nameTokens,
-			Value: valueTokens,
-		})
+		c := comments.attrs[attrInfo.Name]
+		tokens = append(tokens, c.lead...)
+		tokens = append(tokens, hclwrite.TokensForIdentifier(attrInfo.Name)...)
+		tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenEqual, Bytes: []byte("=")})
+		tokens = append(tokens, attrInfo.Attribute.Expr().BuildTokens(nil)...)
+		tokens = appendLineEnd(tokens, c.line)
 	}
+	tokens = append(tokens, comments.trailing...)
+	return append(tokens, &hclwrite.Token{Type: hclsyntax.TokenCBrace, Bytes: []byte("}")})
+}
 
-	// Use the built-in TokensForObject function to create properly formatted object tokens
-	return hclwrite.TokensForObject(attrs)
+// buildArrayFromBlocks creates array tokens from the objects built from
+// blocks by build. When the blocks carry comments, as returned by comments,
+// the objects are written one per line so the comments can go along.
+func buildArrayFromBlocks(blocks []*hclwrite.Block, build func(*hclwrite.Block) hclwrite.Tokens, comments func(*hclwrite.Block) itemComments) hclwrite.Tokens {
+	if hasBlockComments(blocks, comments) {
+		return tokensForObjectListWithComments(blocks, build, comments)
+	}
+	var objectTokens []hclwrite.Tokens
+	for _, block := range blocks {
+		objectTokens = append(objectTokens, build(block))
+	}
+	return BuildArrayFromObjects(objectTokens)
 }
 
 // RemoveEmptyBlocks removes blocks with no attributes or nested blocks
@@ -533,7 +609,7 @@ func ConvertBlockToAttributeWithNested(body *hclwrite.Body, blockName string) {
 // ConvertBlockToAttributeWithNestedAndArrays converts blocks to attributes with explicit array field specification
 // alwaysArrayFields: map of block types that should always be arrays (even with 1 element)
 func ConvertBlockToAttributeWithNestedAndArrays(body *hclwrite.Body, blockName string, alwaysArrayFields map[string]bool) {
-	blocks := FindBlocksByType(body, blockName)
+	blocks := FindBlocksWithDynamic(body, blockName)
 	if len(blocks) == 0 {
 		return
 	}
@@ -541,37 +617,37 @@ func ConvertBlockToAttributeWithNestedAndArrays(body *hclwrite.Body, blockName s
 	// Check if this block type should always be an array (even with 1 element)
 	forceArray := alwaysArrayFields != nil && alwaysArrayFields[blockName]
 
+	if hasDynamicBlock(blocks) {
+		// Dynamic blocks become for expressions, merged with the static
+		// blocks via concat(); a lone dynamic block of a MaxItems:1 type
+		// becomes one() of its list
+		tokens, comments := buildBlockList(blocks, func(block *hclwrite.Block) hclwrite.Tokens {
+			return buildObjectFromBlockRecursiveWithArrays(block.Body(), 0, alwaysArrayFields)
+		}, blockComments)
+		if len(blocks) == 1 && !forceArray {
+			tokens = tokensForOne(tokens)
+		}
+		setAttributeRawWithComments(body, blockName, tokens, comments)
+		for _, block := range blocks {
+			body.RemoveBlock(block)
+		}
+		return
+	}
+
 	// Group blocks by their type to identify TypeList vs MaxItems:1
 	// For ruleset, we know action_parameters is MaxItems:1, but nested blocks vary
 	if len(blocks) == 1 && !forceArray {
 		// MaxItems:1 - convert to single object
 		block := blocks[0]
+		c := blockComments(block)
 		tokens := buildObjectFromBlockRecursiveWithArrays(block.Body(), 0, alwaysArrayFields)
-		body.SetAttributeRaw(blockName, tokens)
+		setAttributeRawWithComments(body, blockName, tokens, append(c.lead, c.line...))
 		body.RemoveBlock(block)
 	} else {
 		// TypeList - convert to array of objects
-		var arrayElements []hclwrite.Tokens
-		for _, block := range blocks {
-			objTokens := buildObjectFromBlockRecursiveWithArrays(block.Body(), 0, alwaysArrayFields)
-			arrayElements = append(arrayElements, objTokens)
-		}
-
-		// Build array tokens
-		tokens := hclwrite.Tokens{
-			{Type: hclsyntax.TokenOBrack, Bytes: []byte("[")},
-			{Type: hclsyntax.TokenNewline, Bytes: []byte("\n")},
-		}
-		for i, elem := range arrayElements {
-			// Add indentation
-			tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenIdent, Bytes: []byte("    ")})
-			tokens = append(tokens, elem...)
-			if i < len(arrayElements)-1 {
-				tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenComma, Bytes: []byte(",")})
-			}
-			tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenNewline, Bytes: []byte("\n")})
-		}
-		tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenCBrack, Bytes: []byte("]")})
+		tokens := tokensForObjectList(blocks, func(block *hclwrite.Block) hclwrite.Tokens {
+			return buildObjectFromBlockRecursiveWithArrays(block.Body(), 0, alwaysArrayFields)
+		})
 
 		body.SetAttributeRaw(blockName, tokens)
 		for _, block := range blocks {
@@ -593,20 +669,28 @@ func buildObjectFromBlockRecursiveWithArrays(body *hclwrite.Body, indentLevel in
 		{Type: hclsyntax.TokenOBrace, Bytes: []byte("{")},
 		{Type: hclsyntax.TokenNewline, Bytes: []byte("\n")},
 	}
+	comments := collectBodyComments(body)
 
 	// First, add all attributes
 	orderedAttrs := AttributesOrdered(body)
 	for _, attrInfo := range orderedAttrs {
+		c := comments.attrs[attrInfo.Name]
+		tokens = append(tokens, c.lead...)
 		tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenIdent, Bytes: []byte(indent + "  " + attrInfo.Name)})
 		tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenEqual, Bytes: []byte(" = ")})
 		tokens = append(tokens, attrInfo.Attribute.Expr().BuildTokens(nil)...)
-		tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenNewline, Bytes: []byte("\n")})
+		tokens = appendLineEnd(tokens, c.line)
 	}
 
-	// Then, handle nested blocks - group by type
+	// Then, handle nested blocks - group by type, with dynamic blocks grouped
+	// under the type of the blocks they generate
 	blocksByType := make(map[string][]*hclwrite.Block)
 	for _, block := range body.Blocks() {
-		blocksByType[block.Type()] = append(blocksByType[block.Type()], block)
+		blockType := block.Type()
+		if IsConvertibleDynamicBlock(block) {
+			blockType = block.Labels()[0]
+		}
+		blocksByType[blockType] = append(blocksByType[blockType], block)
 	}
 
 	// Sort block types for deterministic output
@@ -622,13 +706,31 @@ func buildObjectFromBlockRecursiveWithArrays(body *hclwrite.Body, indentLevel in
 		// Check if this block type should always be an array (even with 1 element)
 		forceArray := alwaysArrayFields != nil && alwaysArrayFields[blockType]
 
-		if len(blocks) == 1 && !forceArray {
+		if hasDynamicBlock(blocks) {
+			// Dynamic blocks - for expressions merged with the static blocks,
+			// wrapped in one() for a lone dynamic block of a MaxItems:1 type
+			list, listComments := buildBlockList(blocks, func(block *hclwrite.Block) hclwrite.Tokens {
+				return buildObjectFromBlockRecursiveWithArrays(block.Body(), indentLevel+1, alwaysArrayFields)
+			}, func(block *hclwrite.Block) itemComments {
+				return comments.blocks[block]
+			})
+			if len(blocks) == 1 && !forceArray {
+				list = tokensForOne(list)
+			}
+			tokens = append(tokens, listComments...)
+			tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenIdent, Bytes: []byte(indent + "  " + blockType)})
+			tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenEqual, Bytes: []byte(" = ")})
+			tokens = append(tokens, list...)
+			tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenNewline, Bytes: []byte("\n")})
+		} else if len(blocks) == 1 && !forceArray {
 			// MaxItems:1 - single nested object
+			c := comments.blocks[blocks[0]]
+			tokens = append(tokens, c.lead...)
 			tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenIdent, Bytes: []byte(indent + "  " + blockType)})
 			tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenEqual, Bytes: []byte(" = ")})
 			nestedTokens := buildObjectFromBlockRecursiveWithArrays(blocks[0].Body(), indentLevel+1, alwaysArrayFields)
 			tokens = append(tokens, nestedTokens...)
-			tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenNewline, Bytes: []byte("\n")})
+			tokens = appendLineEnd(tokens, c.line)
 		} else {
 			// TypeList - array of objects (or forceArray is true)
 			tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenIdent, Bytes: []byte(indent + "  " + blockType)})
@@ -637,13 +739,15 @@ func buildObjectFromBlockRecursiveWithArrays(body *hclwrite.Body, indentLevel in
 			tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenNewline, Bytes: []byte("\n")})
 
 			for i, block := range blocks {
+				c := comments.blocks[block]
+				tokens = append(tokens, c.lead...)
 				tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenIdent, Bytes: []byte(indent + "    ")})
 				nestedTokens := buildObjectFromBlockRecursiveWithArrays(block.Body(), indentLevel+1, alwaysArrayFields)
 				tokens = append(tokens, nestedTokens...)
 				if i < len(blocks)-1 {
 					tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenComma, Bytes: []byte(",")})
 				}
-				tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenNewline, Bytes: []byte("\n")})
+				tokens = appendLineEnd(tokens, c.line)
 			}
 
 			tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenIdent, Bytes: []byte(indent + "  ]")})
@@ -651,6 +755,7 @@ func buildObjectFromBlockRecursiveWithArrays(body *hclwrite.Body, indentLevel in
 		}
 	}
 
+	tokens = append(tokens, comments.trailing...)
 	tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenIdent, Bytes: []byte(indent + "}")})
 	return tokens
 }
@@ -887,7 +992,9 @@ func copyMetaArguments(original, newBlock *hclwrite.Block, attributeRenames map[
 // This handles the case where v4 used dynamic blocks and v5 uses array attributes with for expressions.
 //
 // When multiple dynamic blocks of the same type exist, they are merged using concat()
-// so that no data is silently lost.
+// so that no data is silently lost. Static blocks of the type are left alone; the
+// block-to-attribute helpers, such as ConvertBlocksToAttributeList, convert dynamic
+// blocks together with their static siblings.
 //
 // Example (single dynamic block):
 //
@@ -928,25 +1035,19 @@ func copyMetaArguments(original, newBlock *hclwrite.Block, attributeRenames map[
 //	  [for value in local.secondary_entries : { suffix = value }],
 //	)
 func ConvertDynamicBlocksToForExpression(body *hclwrite.Body, targetBlockType string) {
-	// Find all dynamic blocks
-	dynamicBlocks := FindBlocksByType(body, "dynamic")
-
 	// First pass: collect all matching dynamic blocks and build their for-expressions.
+	// Nested blocks in the content, dynamic or not, become nested attributes.
 	var forExprs []hclwrite.Tokens
 	var matchedBlocks []*hclwrite.Block
 
-	for _, dynamicBlock := range dynamicBlocks {
-		// Check if this dynamic block's label matches our target
-		labels := dynamicBlock.Labels()
-		if len(labels) == 0 || labels[0] != targetBlockType {
-			continue
-		}
-
-		forExprTokens := buildForExprFromDynamicBlock(dynamicBlock, targetBlockType)
-		if forExprTokens == nil {
+	for _, dynamicBlock := range FindBlocksWithDynamic(body, targetBlockType) {
+		if dynamicBlock.Type() != "dynamic" {
 			continue
 		}
 
+		forExprTokens := DynamicBlockToForExpression(dynamicBlock, func(content *hclwrite.Block) hclwrite.Tokens {
+			return buildObjectFromBlockRecursive(content.Body(), 0)
+		})
 		forExprs = append(forExprs, forExprTokens)
 		matchedBlocks = append(matchedBlocks, dynamicBlock)
 	}
@@ -961,7 +1062,7 @@ func ConvertDynamicBlocksToForExpression(body *hclwrite.Body, targetBlockType st
 		body.SetAttributeRaw(targetBlockType, forExprs[0])
 	} else {
 		// Multiple dynamic blocks — wrap in concat() so nothing is lost.
-		body.SetAttributeRaw(targetBlockType, buildConcatExpression(forExprs))
+		body.SetAttributeRaw(targetBlockType, BuildConcatExpression(forExprs))
 	}
 
 	for _, block := range matchedBlocks {
@@ -969,68 +1070,7 @@ func ConvertDynamicBlocksToForExpression(body *hclwrite.Body, targetBlockType st
 	}
 }
 
-// buildForExprFromDynamicBlock converts a single dynamic block into a for-expression
-// token sequence, e.g. [for value in <expr> : { ... }]. Returns nil if the block
-// is missing required elements (for_each, content).
-func buildForExprFromDynamicBlock(dynamicBlock *hclwrite.Block, targetBlockType string) hclwrite.Tokens {
-	dynamicBody := dynamicBlock.Body()
-
-	// Get the for_each expression
-	forEachAttr := dynamicBody.GetAttribute("for_each")
-	if forEachAttr == nil {
-		return nil
-	}
-	forEachTokens := forEachAttr.Expr().BuildTokens(nil)
-
-	// Get the iterator name (default is the block label)
-	iteratorName := targetBlockType
-	if iteratorAttr := dynamicBody.GetAttribute("iterator"); iteratorAttr != nil {
-		iterTokens := iteratorAttr.Expr().BuildTokens(nil)
-		for _, token := range iterTokens {
-			if token.Type == hclsyntax.TokenIdent {
-				iteratorName = string(token.Bytes)
-				break
-			}
-		}
-	}
-
-	// Find the content block
-	contentBlock := FindBlockByType(dynamicBody, "content")
-	if contentBlock == nil {
-		return nil
-	}
-
-	// Before building the object, convert any nested blocks to attributes
-	contentBody := contentBlock.Body()
-	for _, nestedBlock := range contentBody.Blocks() {
-		nestedBlockType := nestedBlock.Type()
-		objTokens := BuildObjectFromBlock(nestedBlock)
-		contentBody.SetAttributeRaw(nestedBlockType, objTokens)
-		contentBody.RemoveBlock(nestedBlock)
-	}
-
-	// Build the for expression: [for value in <expr> : { ... }]
-	var tokens hclwrite.Tokens
-
-	tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenOBrack, Bytes: []byte("[")})
-	tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenIdent, Bytes: []byte("for")})
-	tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenIdent, Bytes: []byte("value")})
-	tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenIdent, Bytes: []byte("in")})
-
-	tokens = append(tokens, forEachTokens...)
-
-	tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenColon, Bytes: []byte(":")})
-
-	objTokens := BuildObjectFromBlock(contentBlock)
-	objTokens = replaceIteratorReferences(objTokens, iteratorName)
-	tokens = append(tokens, objTokens...)
-
-	tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenCBrack, Bytes: []byte("]")})
-
-	return tokens
-}
-
-// buildConcatExpression wraps multiple token sequences in a concat(...) call.
+// BuildConcatExpression wraps multiple list expressions in a concat(...) call.
 // Each element is placed on its own line for readability.
 //
 // Output shape:
@@ -1039,7 +1079,7 @@ func buildForExprFromDynamicBlock(dynamicBlock *hclwrite.Block, targetBlockType
 //	  <expr1>,
 //	  <expr2>,
 //	)
-func buildConcatExpression(exprs []hclwrite.Tokens) hclwrite.Tokens {
+func BuildConcatExpression(exprs []hclwrite.Tokens) hclwrite.Tokens {
 	var tokens hclwrite.Tokens
 
 	// "concat("
@@ -1066,39 +1106,6 @@ func buildConcatExpression(exprs []hclwrite.Tokens) hclwrite.Tokens {
 	return tokens
 }
 
-// replaceIteratorReferences replaces iterator references in tokens
-// For example, replaces "origins.value.name" with "value.name"
-func replaceIteratorReferences(tokens hclwrite.Tokens, iteratorName string) hclwrite.Tokens {
-	result := make(hclwrite.Tokens, 0, len(tokens))
-	i := 0
-
-	for i < len(tokens) {
-		token := tokens[i]
-
-		// Look for pattern: iteratorName DOT value DOT ...
-		// We want to remove the "iteratorName DOT" part
-		if token.Type == hclsyntax.TokenIdent && string(token.Bytes) == iteratorName {
-			// Check if next token is a dot
-			if i+1 < len(tokens) && tokens[i+1].Type == hclsyntax.TokenDot {
-				// Check if token after dot is "value"
-				if i+2 < len(tokens) && tokens[i+2].Type == hclsyntax.TokenIdent && string(tokens[i+2].Bytes) == "value" {
-					// Skip the iterator name and the first dot, keep "value"
-					// This converts "origins.value.name" to "value.name"
-					i++ // Skip iterator name
-					i++ // Skip first dot
-					// Continue with "value"
-					continue
-				}
-			}
-		}
-
-		result = append(result, token)
-		i++
-	}
-
-	return result
-}
-
 // ConvertBlocksToArrayAttribute converts multiple blocks to an array attribute
 // This is useful when migrating from v4 block syntax to v5 array attribute syntax
 //
@@ -1124,10 +1131,10 @@ func replaceIteratorReferences(tokens hclwrite.Tokens, iteratorName string) hclw
 //
 // If no blocks are found and emptyIfNone is true, sets an empty array [].
 //
-// NOTE: This function does NOT automatically convert dynamic blocks to for expressions.
-// If you need to convert dynamic blocks, call ConvertDynamicBlocksToForExpression separately before this function.
+// Dynamic blocks of the type become for expressions, merged with the static
+// blocks via concat() as described for BuildListFromBlocks.
 func ConvertBlocksToArrayAttribute(body *hclwrite.Body, blockType string, emptyIfNone bool) {
-	blocks := FindBlocksByType(body, blockType)
+	blocks := FindBlocksWithDynamic(body, blockType)
 
 	if len(blocks) == 0 {
 		if emptyIfNone {
@@ -1136,19 +1143,14 @@ func ConvertBlocksToArrayAttribute(body *hclwrite.Body, blockType string, emptyI
 		return
 	}
 
-	// Convert each block to object tokens
-	var objectTokens []hclwrite.Tokens
-	for _, block := range blocks {
-		objTokens := BuildObjectFromBlock(block)
-		objectTokens = append(objectTokens, objTokens)
-	}
-
-	// Build array tokens from the objects and set as attribute
-	arrayTokens := BuildArrayFromObjects(objectTokens)
-	body.SetAttributeRaw(blockType, arrayTokens)
+	// Build array tokens from the blocks and set as attribute
+	arrayTokens, comments := buildBlockList(blocks, BuildObjectFromBlock, blockComments)
+	setAttributeRawWithComments(body, blockType, arrayTokens, comments)
 
 	// Remove all original blocks
-	RemoveBlocksByType(body, blockType)
+	for _, block := range blocks {
+		body.RemoveBlock(block)
+	}
 }
 
 // AddLifecycleIgnoreChanges adds or updates a lifecycle block's ignore_changes list
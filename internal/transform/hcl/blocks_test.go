@@ -1633,3 +1633,247 @@ resource "cloudflare_example" "test" {
 		assert.NotEqual(t, "domains", block.Type(), "static domains blocks should be removed")
 	}
 }
+
+func TestMigrationBlockKey(t *testing.T) {
+	input := `
+moved {
+  from = cloudflare_record.a
+  to   = cloudflare_dns_record.a
+}
+
+moved {
+  from = cloudflare_record.a
+  to = cloudflare_dns_record.a
+}
+
+import {
+  to = cloudflare_zone_setting.a
+  id = "one"
+}
+
+import {
+  to = cloudflare_zone_setting.a
+  id = "two"
+}
+
+removed {
+  from = cloudflare_zone_settings_override.a
+}
+
+resource "cloudflare_dns_record" "a" {}
+`
+	file, diags := hclwrite.ParseConfig([]byte(input), "", hcl.InitialPos)
+	require.False(t, diags.HasErrors())
+	blocks := file.Body().Blocks()
+
+	// Formatting differences do not change the key
+	assert.Equal(t, MigrationBlockKey(blocks[0]), MigrationBlockKey(blocks[1]))
+	// Only one import per address is allowed, whatever the ID
+	assert.Equal(t, MigrationBlockKey(blocks[2]), MigrationBlockKey(blocks[3]))
+	assert.NotEqual(t, MigrationBlockKey(blocks[0]), MigrationBlockKey(blocks[2]))
+	assert.NotEmpty(t, MigrationBlockKey(blocks[4]))
+	assert.True(t, IsMigrationBlock(blocks[4]))
+
+	assert.Empty(t, MigrationBlockKey(blocks[5]))
+	assert.False(t, IsMigrationBlock(blocks[5]))
+}
+
+func TestCloneBlock(t *testing.T) {
+	file, diags := hclwrite.ParseConfig([]byte(`resource "cloudflare_record" "a" {
+  name = "www" # the web server
+}
+`), "", hcl.InitialPos)
+	require.False(t, diags.HasErrors())
+	block := file.Body().Blocks()[0]
+
+	clone := CloneBlock(block)
+	require.NotNil(t, clone)
+	assert.Equal(t, string(block.BuildTokens(nil).Bytes()), string(clone.BuildTokens(nil).Bytes()))
+
+	block.SetLabels([]string{"cloudflare_dns_record", "a"})
+	block.Body().RemoveAttribute("name")
+	assert.Equal(t, []string{"cloudflare_record", "a"}, clone.Labels())
+	assert.NotNil(t, clone.Body().GetAttribute("name"))
+}
+
+func TestBlockToAttributeHelpers_PreserveComments(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		convert  func(body *hclwrite.Body)
+		expected string
+	}{
+		{
+			name: "ConvertBlocksToAttributeList",
+			input: `resource "test" "example" {
+  # Public entry point
+  destinations {
+    type = "public" # reachable from the internet
+    # Temporary until the new domain is live
+    uri = "https://app.example.com"
+  }
+
+  destinations {
+    type = "private"
+
+    # Office network
+    cidr = "10.0.0.0/24"
+    # more ranges to follow
+  } # private
+}`,
+			convert: func(body *hclwrite.Body) {
+				ConvertBlocksToAttributeList(body, "destinations", nil)
+			},
+			expected: `resource "test" "example" {
+
+  destinations = [
+    # Public entry point
+    {
+      type = "public" # reachable from the internet
+      # Temporary until the new domain is live
+      uri = "https://app.example.com"
+    },
+    {
+      type = "private"
+      # Office network
+      cidr = "10.0.0.0/24"
+      # more ranges to follow
+    } # private
+  ]
+}`,
+		},
+		{
+			name: "ConvertSingleBlockToAttribute",
+			input: `resource "test" "example" {
+  name = "test"
+
+  # Rate limited on purpose
+  settings {
+    /* requests per minute */ limit = 10
+  }
+}`,
+			convert: func(body *hclwrite.Body) {
+				ConvertSingleBlockToAttribute(body, "settings", "settings")
+			},
+			expected: `resource "test" "example" {
+  name = "test"
+
+  # Rate limited on purpose
+  settings = {
+    /* requests per minute */ limit = 10
+  }
+}`,
+		},
+		{
+			name: "ConvertBlocksToAttribute",
+			input: `resource "test" "example" {
+  data {
+    flags = "0" # critical flag off
+  }
+}`,
+			convert: func(body *hclwrite.Body) {
+				ConvertBlocksToAttribute(body, "data", "data", nil)
+			},
+			expected: `resource "test" "example" {
+  data = {
+    flags = "0" # critical flag off
+  }
+}`,
+		},
+		{
+			name: "ConvertBlocksToArrayAttribute",
+			input: `resource "test" "example" {
+  # Added for the audit
+  headers {
+    id = "header_1" # x-request-id
+  }
+  headers {
+    id = "header_2"
+  }
+}`,
+			convert: func(body *hclwrite.Body) {
+				ConvertBlocksToArrayAttribute(body, "headers", false)
+			},
+			expected: `resource "test" "example" {
+  headers = [
+    # Added for the audit
+    {
+      id = "header_1" # x-request-id
+    },
+    {
+      id = "header_2"
+    }
+  ]
+}`,
+		},
+		{
+			name: "MergeStaticBlocksIntoAttribute",
+			input: `resource "test" "example" {
+  domains = [for d in local.domains : { suffix = d }]
+  # Legacy domain
+  domains {
+    suffix = "old.example.com"
+  }
+}`,
+			convert: func(body *hclwrite.Body) {
+				MergeStaticBlocksIntoAttribute(body, "domains", body.GetAttribute("domains").Expr().BuildTokens(nil))
+			},
+			expected: `resource "test" "example" {
+  domains = concat(
+    [for d in local.domains : { suffix = d }],
+    [
+      # Legacy domain
+      {
+        suffix = "old.example.com"
+      }
+    ],
+  )
+}`,
+		},
+		{
+			name: "ConvertBlockToAttributeWithNested",
+			input: `resource "test" "example" {
+  # Block bots on the login page
+  action_parameters {
+    # Keep in sync with the WAF rule
+    response {
+      status_code = 403 # forbidden
+    }
+
+    # Products skipped for the health check
+    products = ["waf"]
+    # end of parameters
+  }
+}`,
+			convert: func(body *hclwrite.Body) {
+				ConvertBlockToAttributeWithNested(body, "action_parameters")
+			},
+			expected: `resource "test" "example" {
+  # Block bots on the login page
+  action_parameters = {
+    # Products skipped for the health check
+    products = ["waf"]
+    # Keep in sync with the WAF rule
+    response = {
+      status_code = 403 # forbidden
+    }
+    # end of parameters
+  }
+}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, diags := hclwrite.ParseConfig([]byte(tt.input), "test.tf", hcl.InitialPos)
+			require.False(t, diags.HasErrors())
+
+			tt.convert(file.Body().Blocks()[0].Body())
+			output := string(hclwrite.Format(file.Bytes()))
+			assert.Equal(t, tt.expected, output)
+
+			_, diags = hclwrite.ParseConfig([]byte(output), "test.tf", hcl.InitialPos)
+			assert.False(t, diags.HasErrors(), "the converted configuration parses: %s", diags.Error())
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package hcl
+
+import (
+	"bytes"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+// itemComments holds the comments of an attribute or nested block that its
+// expression or body tokens leave out.
+type itemComments struct {
+	// lead holds the comment lines above the item, including comments
+	// separated from it by blank lines.
+	lead hclwrite.Tokens
+	// line holds the comment after the item, on its last line.
+	line hclwrite.Tokens
+}
+
+// bodyComments holds the comments of the items of a body, as copies that can
+// be written into generated tokens.
+type bodyComments struct {
+	attrs  map[string]itemComments
+	blocks map[*hclwrite.Block]itemComments
+	// trailing holds the comments after the last item of the body.
+	trailing hclwrite.Tokens
+}
+
+// collectBodyComments returns the comments of body. Comments that hclwrite
+// does not attach to an item, because a blank line separates them from it,
+// are attributed to the next item, or to the end of the body.
+func collectBodyComments(body *hclwrite.Body) bodyComments {
+	comments := bodyComments{
+		attrs:  make(map[string]itemComments),
+		blocks: make(map[*hclwrite.Block]itemComments),
+	}
+
+	// Item tokens are shared with the body's token sequence, so each item's
+	// span can be located by token identity.
+	type item struct {
+		attr   string
+		block  *hclwrite.Block
+		tokens hclwrite.Tokens
+	}
+	byFirstToken := make(map[*hclwrite.Token]item)
+	for name, attr := range body.Attributes() {
+		if tokens := attr.BuildTokens(nil); len(tokens) > 0 {
+			byFirstToken[tokens[0]] = item{attr: name, tokens: tokens}
+		}
+	}
+	for _, block := range body.Blocks() {
+		if tokens := block.BuildTokens(nil); len(tokens) > 0 {
+			byFirstToken[tokens[0]] = item{block: block, tokens: tokens}
+		}
+	}
+
+	var pending hclwrite.Tokens
+	all := body.BuildTokens(nil)
+	for i := 0; i < len(all); i++ {
+		it, ok := byFirstToken[all[i]]
+		if !ok {
+			if all[i].Type == hclsyntax.TokenComment {
+				pending = append(pending, copyToken(all[i]))
+			}
+			continue
+		}
+		i += len(it.tokens) - 1
+
+		c := splitItemComments(it.tokens)
+		c.lead = append(pending, c.lead...)
+		pending = nil
+		if it.block != nil {
+			comments.blocks[it.block] = c
+		} else {
+			comments.attrs[it.attr] = c
+		}
+	}
+	comments.trailing = pending
+	return comments
+}
+
+// blockComments returns copies of the comments above block and after its
+// closing brace.
+func blockComments(block *hclwrite.Block) itemComments {
+	return splitItemComments(block.BuildTokens(nil))
+}
+
+// splitItemComments returns copies of the leading and line comments of the
+// tokens of an attribute or block.
+func splitItemComments(tokens hclwrite.Tokens) itemComments {
+	var c itemComments
+	start := 0
+	for start < len(tokens) && tokens[start].Type == hclsyntax.TokenComment {
+		c.lead = append(c.lead, copyToken(tokens[start]))
+		start++
+	}
+
+	end := len(tokens)
+	if end > start && tokens[end-1].Type == hclsyntax.TokenNewline {
+		end--
+	}
+	lineStart := end
+	for lineStart > start && tokens[lineStart-1].Type == hclsyntax.TokenComment {
+		lineStart--
+	}
+	for _, tok := range tokens[lineStart:end] {
+		c.line = append(c.line, copyToken(tok))
+	}
+	return c
+}
+
+// appendLineEnd appends the line comments of an item, or a newline when it
+// has none, so that the tokens that follow start on a line of their own.
+func appendLineEnd(tokens, line hclwrite.Tokens) hclwrite.Tokens {
+	tokens = append(tokens, line...)
+	if len(line) == 0 || !bytes.HasSuffix(line[len(line)-1].Bytes, []byte("\n")) {
+		tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenNewline, Bytes: []byte("\n")})
+	}
+	return tokens
+}
+
+// tokensForObjectList returns a list of the objects built from blocks, one
+// per line, with the comments above and after each block carried along.
+func tokensForObjectList(blocks []*hclwrite.Block, build func(*hclwrite.Block) hclwrite.Tokens) hclwrite.Tokens {
+	return tokensForObjectListWithComments(blocks, build, blockComments)
+}
+
+// tokensForObjectListWithComments is tokensForObjectList with the comments of
+// each block given by comments.
+func tokensForObjectListWithComments(blocks []*hclwrite.Block, build func(*hclwrite.Block) hclwrite.Tokens, comments func(*hclwrite.Block) itemComments) hclwrite.Tokens {
+	tokens := hclwrite.Tokens{
+		{Type: hclsyntax.TokenOBrack, Bytes: []byte("[")},
+		{Type: hclsyntax.TokenNewline, Bytes: []byte("\n")},
+	}
+	for i, block := range blocks {
+		c := comments(block)
+		tokens = append(tokens, c.lead...)
+		tokens = append(tokens, build(block)...)
+		if i < len(blocks)-1 {
+			tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenComma, Bytes: []byte(",")})
+		}
+		tokens = appendLineEnd(tokens, c.line)
+	}
+	return append(tokens, &hclwrite.Token{Type: hclsyntax.TokenCBrack, Bytes: []byte("]")})
+}
+
+// hasBlockComments reports whether any of blocks has comments, as returned
+// by comments.
+func hasBlockComments(blocks []*hclwrite.Block, comments func(*hclwrite.Block) itemComments) bool {
+	for _, block := range blocks {
+		c := comments(block)
+		if len(c.lead) > 0 || len(c.line) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// setAttributeRawWithComments sets attribute name of body to tokens, like
+// SetAttributeRaw, and writes comments on the lines above it when the
+// attribute is new.
+func setAttributeRawWithComments(body *hclwrite.Body, name string, tokens, comments hclwrite.Tokens) {
+	if body.GetAttribute(name) == nil && len(comments) > 0 {
+		body.AppendUnstructuredTokens(comments)
+	}
+	body.SetAttributeRaw(name, tokens)
+}
+
+func copyToken(tok *hclwrite.Token) *hclwrite.Token {
+	return &hclwrite.Token{
+		Type:  tok.Type,
+		Bytes: append([]byte(nil), tok.Bytes...),
+	}
+}
@@ -0,0 +1,424 @@
+package hcl
+
+import (
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+// IsDynamicBlockOf reports whether block is a dynamic block that generates
+// blocks of blockType.
+func IsDynamicBlockOf(block *hclwrite.Block, blockType string) bool {
+	labels := block.Labels()
+	return block.Type() == "dynamic" && len(labels) == 1 && labels[0] == blockType
+}
+
+// FindBlocksWithDynamic returns the blocks of body that produce blocks of
+// blockType, in source order: the blocks of that type and the dynamic blocks
+// that generate them. Dynamic blocks that cannot be written as a for
+// expression, because they lack for_each or content or set labels, are left
+// out.
+func FindBlocksWithDynamic(body *hclwrite.Body, blockType string) []*hclwrite.Block {
+	var blocks []*hclwrite.Block
+	for _, block := range body.Blocks() {
+		if block.Type() == blockType || (IsDynamicBlockOf(block, blockType) && IsConvertibleDynamicBlock(block)) {
+			blocks = append(blocks, block)
+		}
+	}
+	return blocks
+}
+
+// ContentBlock returns the block whose body holds the arguments of the blocks
+// that block produces: the content block of a dynamic block, or block itself.
+func ContentBlock(block *hclwrite.Block) *hclwrite.Block {
+	if block.Type() == "dynamic" {
+		return FindBlockByType(block.Body(), "content")
+	}
+	return block
+}
+
+// DynamicBlockToForExpression returns a for expression with one element for
+// each block the dynamic block generates. element builds that element from
+// the content block; references to the iterator in it are rewritten to the
+// variables of the for expression, and the key variable is only declared when
+// the iterator's key is used. Returns nil if the dynamic block lacks for_each
+// or content, or sets labels, or if element returns nil.
+//
+// Example:
+//
+// Before:
+//
+//	dynamic "origins" {
+//	  for_each = var.origins
+//	  iterator = origin
+//	  content {
+//	    name    = origin.key
+//	    address = origin.value
+//	  }
+//	}
+//
+// After calling DynamicBlockToForExpression(block, BuildObjectFromBlock):
+//
+//	[for key, value in var.origins : {
+//	  name    = key
+//	  address = value
+//	}]
+func DynamicBlockToForExpression(dynamicBlock *hclwrite.Block, element func(content *hclwrite.Block) hclwrite.Tokens) hclwrite.Tokens {
+	if !IsConvertibleDynamicBlock(dynamicBlock) {
+		return nil
+	}
+	return dynamicForTokens(dynamicBlock, element(FindBlockByType(dynamicBlock.Body(), "content")), hclsyntax.TokenOBrack, hclsyntax.TokenCBrack)
+}
+
+// DynamicBlockToMapExpression returns an object for expression with one
+// element for each block the dynamic block generates, keyed by the keyAttr
+// argument of its content and holding the valueAttr argument. Returns nil if
+// the dynamic block can't be written as a for expression or its content lacks
+// either argument.
+//
+// Example:
+//
+// Before:
+//
+//	dynamic "header" {
+//	  for_each = var.headers
+//	  content {
+//	    header = header.key
+//	    values = header.value
+//	  }
+//	}
+//
+// After calling DynamicBlockToMapExpression(block, "header", "values"):
+//
+//	{ for key, value in var.headers : key => value }
+func DynamicBlockToMapExpression(dynamicBlock *hclwrite.Block, keyAttr, valueAttr string) hclwrite.Tokens {
+	if !IsConvertibleDynamicBlock(dynamicBlock) {
+		return nil
+	}
+	content := FindBlockByType(dynamicBlock.Body(), "content").Body()
+	key, value := content.GetAttribute(keyAttr), content.GetAttribute(valueAttr)
+	if key == nil || value == nil {
+		return nil
+	}
+	element := key.Expr().BuildTokens(nil)
+	element = append(element, &hclwrite.Token{Type: hclsyntax.TokenFatArrow, Bytes: []byte("=>"), SpacesBefore: 1})
+	element = append(element, value.Expr().BuildTokens(nil)...)
+	return dynamicForTokens(dynamicBlock, element, hclsyntax.TokenOBrace, hclsyntax.TokenCBrace)
+}
+
+// dynamicForTokens returns the for expression of a convertible dynamic block
+// over element, between the open and close brackets.
+func dynamicForTokens(dynamicBlock *hclwrite.Block, elementTokens hclwrite.Tokens, open, close hclsyntax.TokenType) hclwrite.Tokens {
+	if elementTokens == nil {
+		return nil
+	}
+	body := dynamicBlock.Body()
+	iterator := dynamicBlock.Labels()[0]
+	if iteratorAttr := body.GetAttribute("iterator"); iteratorAttr != nil {
+		for _, token := range iteratorAttr.Expr().BuildTokens(nil) {
+			if token.Type == hclsyntax.TokenIdent {
+				iterator = string(token.Bytes)
+				break
+			}
+		}
+	}
+
+	// Variables of for expressions nested in the element, written by the
+	// user or generated for nested dynamic blocks, must not be shadowed
+	valueVar := forVariableName(elementTokens, "value", iterator)
+	keyVar := forVariableName(elementTokens, "key", iterator)
+	elementTokens, usesKey := replaceIteratorReferences(elementTokens, iterator, valueVar, keyVar)
+
+	// Keywords and variables are spaced so the tokens stay apart when their
+	// bytes are parsed again before formatting
+	tokens := hclwrite.Tokens{
+		{Type: open, Bytes: []byte(bracketBytes[open])},
+		{Type: hclsyntax.TokenIdent, Bytes: []byte("for")},
+	}
+	if usesKey {
+		tokens = append(tokens,
+			&hclwrite.Token{Type: hclsyntax.TokenIdent, Bytes: []byte(keyVar), SpacesBefore: 1},
+			&hclwrite.Token{Type: hclsyntax.TokenComma, Bytes: []byte(",")},
+		)
+	}
+	tokens = append(tokens,
+		&hclwrite.Token{Type: hclsyntax.TokenIdent, Bytes: []byte(valueVar), SpacesBefore: 1},
+		&hclwrite.Token{Type: hclsyntax.TokenIdent, Bytes: []byte("in"), SpacesBefore: 1},
+	)
+	for i, token := range body.GetAttribute("for_each").Expr().BuildTokens(nil) {
+		if i == 0 {
+			token = &hclwrite.Token{Type: token.Type, Bytes: token.Bytes, SpacesBefore: 1}
+		}
+		tokens = append(tokens, token)
+	}
+	tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenColon, Bytes: []byte(":"), SpacesBefore: 1})
+	tokens = append(tokens, elementTokens...)
+	return append(tokens, &hclwrite.Token{Type: close, Bytes: []byte(bracketBytes[close])})
+}
+
+var bracketBytes = map[hclsyntax.TokenType]string{
+	hclsyntax.TokenOBrack: "[",
+	hclsyntax.TokenCBrack: "]",
+	hclsyntax.TokenOBrace: "{",
+	hclsyntax.TokenCBrace: "}",
+}
+
+// BuildMapFromBlocks returns a map expression for blocks, as returned by
+// FindBlocksWithDynamic, that each hold one entry: the keyAttr argument is the
+// key and the valueAttr argument the value. Static blocks become an object,
+// skipping those that lack either argument, and each dynamic block an object
+// for expression (see DynamicBlockToMapExpression). The parts are joined with
+// merge() in source order when there are several. It also returns the blocks
+// it converted; dynamic blocks whose content lacks either argument are left
+// out. The tokens are nil when there is nothing to convert.
+//
+// Example:
+//
+// Before:
+//
+//	header {
+//	  header = "Host"
+//	  values = ["example.com"]
+//	}
+//	dynamic "header" {
+//	  for_each = var.headers
+//	  content {
+//	    header = header.key
+//	    values = header.value
+//	  }
+//	}
+//
+// After calling BuildMapFromBlocks(blocks, "header", "values"):
+//
+//	merge(
+//	  { "Host" = ["example.com"] },
+//	  { for key, value in var.headers : key => value },
+//	)
+func BuildMapFromBlocks(blocks []*hclwrite.Block, keyAttr, valueAttr string) (hclwrite.Tokens, []*hclwrite.Block) {
+	var parts []hclwrite.Tokens
+	var converted []*hclwrite.Block
+	var static []hclwrite.ObjectAttrTokens
+	flushStatic := func() {
+		if len(static) > 0 {
+			parts = append(parts, hclwrite.TokensForObject(static))
+			static = nil
+		}
+	}
+	for _, block := range blocks {
+		if block.Type() != "dynamic" {
+			converted = append(converted, block)
+			key, value := block.Body().GetAttribute(keyAttr), block.Body().GetAttribute(valueAttr)
+			if key != nil && value != nil {
+				static = append(static, hclwrite.ObjectAttrTokens{
+					Name:  key.Expr().BuildTokens(nil),
+					Value: value.Expr().BuildTokens(nil),
+				})
+			}
+			continue
+		}
+		forTokens := DynamicBlockToMapExpression(block, keyAttr, valueAttr)
+		if forTokens == nil {
+			continue
+		}
+		flushStatic()
+		parts = append(parts, forTokens)
+		converted = append(converted, block)
+	}
+	flushStatic()
+
+	return TokensForMerge(parts), converted
+}
+
+// TokensForMerge joins object expressions with merge(), one per line, or
+// returns the only one. Returns nil if parts is empty.
+func TokensForMerge(parts []hclwrite.Tokens) hclwrite.Tokens {
+	switch len(parts) {
+	case 0:
+		return nil
+	case 1:
+		return parts[0]
+	}
+	tokens := hclwrite.Tokens{
+		{Type: hclsyntax.TokenIdent, Bytes: []byte("merge")},
+		{Type: hclsyntax.TokenOParen, Bytes: []byte("(")},
+		{Type: hclsyntax.TokenNewline, Bytes: []byte("\n")},
+	}
+	for _, part := range parts {
+		tokens = append(tokens, part...)
+		tokens = append(tokens,
+			&hclwrite.Token{Type: hclsyntax.TokenComma, Bytes: []byte(",")},
+			&hclwrite.Token{Type: hclsyntax.TokenNewline, Bytes: []byte("\n")},
+		)
+	}
+	return append(tokens, &hclwrite.Token{Type: hclsyntax.TokenCParen, Bytes: []byte(")")})
+}
+
+// FindDynamicBlocks returns the dynamic blocks of body that generate blocks of
+// blockType, including those FindBlocksWithDynamic leaves out.
+func FindDynamicBlocks(body *hclwrite.Body, blockType string) []*hclwrite.Block {
+	var blocks []*hclwrite.Block
+	for _, block := range body.Blocks() {
+		if IsDynamicBlockOf(block, blockType) {
+			blocks = append(blocks, block)
+		}
+	}
+	return blocks
+}
+
+// BuildListFromBlocks returns a list expression for blocks, as returned by
+// FindBlocksWithDynamic. Consecutive static blocks become a list of the
+// objects build returns for them, and each dynamic block a for expression
+// whose elements build returns for its content block. The parts are joined
+// with concat() in source order when there are several.
+//
+// Example:
+//
+// Before:
+//
+//	domains {
+//	  suffix = "example.com"
+//	}
+//	dynamic "domains" {
+//	  for_each = var.suffixes
+//	  content {
+//	    suffix = domains.value
+//	  }
+//	}
+//
+// After calling BuildListFromBlocks(blocks, BuildObjectFromBlock):
+//
+//	concat(
+//	  [{ suffix = "example.com" }],
+//	  [for value in var.suffixes : { suffix = value }],
+//	)
+func BuildListFromBlocks(blocks []*hclwrite.Block, build func(*hclwrite.Block) hclwrite.Tokens) hclwrite.Tokens {
+	tokens, _ := buildBlockList(blocks, build, blockComments)
+	return tokens
+}
+
+// buildBlockList returns the list expression of BuildListFromBlocks, with
+// comments, as returned by comments, carried next to the object or for
+// expression of each block. When the list is a single for expression, the
+// comments of its dynamic block are returned for the caller to write above
+// the attribute.
+func buildBlockList(blocks []*hclwrite.Block, build func(*hclwrite.Block) hclwrite.Tokens, comments func(*hclwrite.Block) itemComments) (hclwrite.Tokens, hclwrite.Tokens) {
+	type listPart struct {
+		tokens   hclwrite.Tokens
+		comments itemComments
+	}
+	var parts []listPart
+	var static []*hclwrite.Block
+	flushStatic := func() {
+		if len(static) > 0 {
+			parts = append(parts, listPart{tokens: buildArrayFromBlocks(static, build, comments)})
+			static = nil
+		}
+	}
+	for _, block := range blocks {
+		if block.Type() != "dynamic" {
+			static = append(static, block)
+			continue
+		}
+		flushStatic()
+		parts = append(parts, listPart{
+			tokens:   DynamicBlockToForExpression(block, build),
+			comments: comments(block),
+		})
+	}
+	flushStatic()
+
+	if len(parts) == 1 {
+		return parts[0].tokens, append(parts[0].comments.lead, parts[0].comments.line...)
+	}
+
+	tokens := hclwrite.Tokens{
+		{Type: hclsyntax.TokenIdent, Bytes: []byte("concat")},
+		{Type: hclsyntax.TokenOParen, Bytes: []byte("(")},
+		{Type: hclsyntax.TokenNewline, Bytes: []byte("\n")},
+	}
+	for _, part := range parts {
+		tokens = append(tokens, part.comments.lead...)
+		tokens = append(tokens, part.tokens...)
+		tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenComma, Bytes: []byte(",")})
+		tokens = appendLineEnd(tokens, part.comments.line)
+	}
+	return append(tokens, &hclwrite.Token{Type: hclsyntax.TokenCParen, Bytes: []byte(")")}), nil
+}
+
+// hasDynamicBlock reports whether any of blocks is a dynamic block.
+func hasDynamicBlock(blocks []*hclwrite.Block) bool {
+	for _, block := range blocks {
+		if block.Type() == "dynamic" {
+			return true
+		}
+	}
+	return false
+}
+
+// IsConvertibleDynamicBlock reports whether block is a dynamic block that
+// DynamicBlockToForExpression can write as a for expression.
+func IsConvertibleDynamicBlock(block *hclwrite.Block) bool {
+	if block.Type() != "dynamic" || len(block.Labels()) != 1 {
+		return false
+	}
+	body := block.Body()
+	return body.GetAttribute("for_each") != nil &&
+		body.GetAttribute("labels") == nil &&
+		FindBlockByType(body, "content") != nil
+}
+
+// tokensForOne wraps a list expression in one(), which returns its only
+// element, or null when it is empty.
+func tokensForOne(list hclwrite.Tokens) hclwrite.Tokens {
+	tokens := hclwrite.Tokens{
+		{Type: hclsyntax.TokenIdent, Bytes: []byte("one")},
+		{Type: hclsyntax.TokenOParen, Bytes: []byte("(")},
+	}
+	tokens = append(tokens, list...)
+	return append(tokens, &hclwrite.Token{Type: hclsyntax.TokenCParen, Bytes: []byte(")")})
+}
+
+// forVariableName returns name, or name prefixed with the iterator when name
+// is already used as a variable in tokens.
+func forVariableName(tokens hclwrite.Tokens, name, iterator string) string {
+	for i, token := range tokens {
+		if token.Type != hclsyntax.TokenIdent || string(token.Bytes) != name {
+			continue
+		}
+		if i > 0 && tokens[i-1].Type == hclsyntax.TokenDot {
+			continue // an attribute, as in each.value
+		}
+		if i+1 < len(tokens) && tokens[i+1].Type == hclsyntax.TokenEqual {
+			continue // an object key
+		}
+		return iterator + "_" + name
+	}
+	return name
+}
+
+// replaceIteratorReferences rewrites references to the iterator of a dynamic
+// block, iterator.value and iterator.key, to valueVar and keyVar. It reports
+// whether the key is referenced.
+func replaceIteratorReferences(tokens hclwrite.Tokens, iterator, valueVar, keyVar string) (hclwrite.Tokens, bool) {
+	result := make(hclwrite.Tokens, 0, len(tokens))
+	usesKey := false
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+		if token.Type == hclsyntax.TokenIdent && string(token.Bytes) == iterator &&
+			(i == 0 || tokens[i-1].Type != hclsyntax.TokenDot) &&
+			i+2 < len(tokens) && tokens[i+1].Type == hclsyntax.TokenDot && tokens[i+2].Type == hclsyntax.TokenIdent {
+			switch string(tokens[i+2].Bytes) {
+			case "value":
+				result = append(result, &hclwrite.Token{Type: hclsyntax.TokenIdent, Bytes: []byte(valueVar), SpacesBefore: token.SpacesBefore})
+				i += 2
+				continue
+			case "key":
+				result = append(result, &hclwrite.Token{Type: hclsyntax.TokenIdent, Bytes: []byte(keyVar), SpacesBefore: token.SpacesBefore})
+				usesKey = true
+				i += 2
+				continue
+			}
+		}
+		result = append(result, token)
+	}
+	return result, usesKey
+}
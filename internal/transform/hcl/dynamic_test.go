@@ -0,0 +1,332 @@
+package hcl
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDynamicBlockToForExpression(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name: "list for_each",
+			input: `dynamic "origins" {
+  for_each = var.origins
+  content {
+    name    = origins.value.name
+    address = origins.value.address
+  }
+}`,
+			expected: `[for value in var.origins : {
+  name    = value.name
+  address = value.address
+}]`,
+		},
+		{
+			name: "map for_each with custom iterator",
+			input: `dynamic "origins" {
+  for_each = var.origins_by_name
+  iterator = origin
+  content {
+    name    = origin.key
+    address = origin.value
+    weight  = var.origin.weight
+  }
+}`,
+			expected: `[for key, value in var.origins_by_name : {
+  name    = key
+  address = value
+  weight  = var.origin.weight
+}]`,
+		},
+		{
+			name: "variable names used in the content",
+			input: `dynamic "origins" {
+  for_each = var.origins
+  content {
+    name    = origins.key
+    headers = [for key, value in origins.value : "${key}=${value}"]
+  }
+}`,
+			expected: `[for origins_key, origins_value in var.origins : {
+  name    = origins_key
+  headers = [for key, value in origins_value : "${key}=${value}"]
+}]`,
+		},
+		{
+			name: "missing content",
+			input: `dynamic "origins" {
+  for_each = var.origins
+}`,
+		},
+		{
+			name: "labels",
+			input: `dynamic "origins" {
+  for_each = var.origins
+  labels   = [origins.key]
+  content {
+    address = origins.value
+  }
+}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, diags := hclwrite.ParseConfig([]byte(tt.input), "test.tf", hcl.InitialPos)
+			require.False(t, diags.HasErrors())
+
+			tokens := DynamicBlockToForExpression(file.Body().Blocks()[0], BuildObjectFromBlock)
+			if tt.expected == "" {
+				assert.Nil(t, tokens)
+				return
+			}
+			assert.Equal(t, tt.expected, string(hclwrite.Format(tokens.Bytes())))
+		})
+	}
+}
+
+func TestBlockToAttributeHelpers_ConvertDynamicBlocks(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		convert  func(body *hclwrite.Body)
+		expected string
+	}{
+		{
+			name: "ConvertBlocksToAttributeList",
+			input: `resource "test" "example" {
+  # Public entry point
+  destinations {
+    uri = "https://app.example.com"
+  }
+
+  # One per office
+  dynamic "destinations" {
+    for_each = var.offices
+    iterator = office
+    content {
+      cidr = office.value.cidr
+    }
+  }
+
+  destinations {
+    cidr = "10.0.0.0/24"
+  }
+}`,
+			convert: func(body *hclwrite.Body) {
+				ConvertBlocksToAttributeList(body, "destinations", nil)
+			},
+			expected: `resource "test" "example" {
+
+
+  destinations = concat(
+    [
+      # Public entry point
+      {
+        uri = "https://app.example.com"
+      }
+    ],
+    # One per office
+    [for value in var.offices : {
+      cidr = value.cidr
+    }],
+    [{
+      cidr = "10.0.0.0/24"
+    }],
+  )
+}`,
+		},
+		{
+			name: "ConvertBlocksToArrayAttribute",
+			input: `resource "test" "example" {
+  dynamic "headers" {
+    for_each = var.headers
+    content {
+      id      = headers.key
+      enabled = headers.value
+    }
+  }
+}`,
+			convert: func(body *hclwrite.Body) {
+				ConvertBlocksToArrayAttribute(body, "headers", false)
+			},
+			expected: `resource "test" "example" {
+  headers = [for key, value in var.headers : {
+    id      = key
+    enabled = value
+  }]
+}`,
+		},
+		{
+			name: "ConvertSingleBlockToAttribute",
+			input: `resource "test" "example" {
+  dynamic "settings" {
+    for_each = var.limit == null ? [] : [var.limit]
+    content {
+      limit = settings.value
+    }
+  }
+}`,
+			convert: func(body *hclwrite.Body) {
+				ConvertSingleBlockToAttribute(body, "settings", "settings")
+			},
+			expected: `resource "test" "example" {
+  settings = one([for value in var.limit == null ? [] : [var.limit] : {
+    limit = value
+  }])
+}`,
+		},
+		{
+			name: "ConvertBlockToAttributeWithNestedAndArrays",
+			input: `resource "test" "example" {
+  dynamic "rules" {
+    for_each = var.rules
+    iterator = rule
+    content {
+      expression = rule.value.expression
+      dynamic "headers" {
+        for_each = rule.value.headers
+        content {
+          name = headers.key
+          value = headers.value
+        }
+      }
+      dynamic "cache_key" {
+        for_each = rule.value.cache ? [1] : []
+        content {
+          ignore_query_strings_order = true
+        }
+      }
+    }
+  }
+}`,
+			convert: func(body *hclwrite.Body) {
+				ConvertBlockToAttributeWithNestedAndArrays(body, "rules", map[string]bool{"rules": true, "headers": true})
+			},
+			expected: `resource "test" "example" {
+  rules = [for rule_value in var.rules : {
+    expression = rule_value.expression
+    cache_key = one([for value in rule_value.cache ? [1] : [] : {
+      ignore_query_strings_order = true
+    }])
+    headers = [for key, value in rule_value.headers : {
+      name  = key
+      value = value
+    }]
+  }]
+}`,
+		},
+		{
+			name: "non-convertible dynamic block is left alone",
+			input: `resource "test" "example" {
+  headers {
+    id = "header_1"
+  }
+  dynamic "headers" {
+    for_each = var.headers
+  }
+}`,
+			convert: func(body *hclwrite.Body) {
+				ConvertBlocksToArrayAttribute(body, "headers", false)
+			},
+			expected: `resource "test" "example" {
+  dynamic "headers" {
+    for_each = var.headers
+  }
+  headers = [{
+    id = "header_1"
+  }]
+}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, diags := hclwrite.ParseConfig([]byte(tt.input), "test.tf", hcl.InitialPos)
+			require.False(t, diags.HasErrors())
+
+			tt.convert(file.Body().Blocks()[0].Body())
+			output := string(hclwrite.Format(file.Bytes()))
+			assert.Equal(t, tt.expected, output)
+
+			_, diags = hclwrite.ParseConfig([]byte(output), "test.tf", hcl.InitialPos)
+			assert.False(t, diags.HasErrors(), "the converted configuration parses: %s", diags.Error())
+		})
+	}
+}
+
+func TestBuildMapFromBlocks(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		expected  string
+		converted int
+	}{
+		{
+			name: "static blocks",
+			input: `header {
+  header = "Host"
+  values = ["example.com"]
+}`,
+			expected: `{
+  "Host" = ["example.com"]
+}`,
+			converted: 1,
+		},
+		{
+			name: "static and dynamic blocks",
+			input: `header {
+  header = "Host"
+  values = ["example.com"]
+}
+dynamic "header" {
+  for_each = var.headers
+  iterator = h
+  content {
+    header = h.key
+    values = h.value
+  }
+}`,
+			expected: `merge(
+  {
+    "Host" = ["example.com"]
+  },
+  { for key, value in var.headers : key => value },
+)`,
+			converted: 2,
+		},
+		{
+			name: "dynamic block that cannot be converted",
+			input: `dynamic "header" {
+  for_each = var.headers
+  content {
+    header = header.key
+  }
+}`,
+			converted: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, diags := hclwrite.ParseConfig([]byte(tt.input), "test.tf", hcl.InitialPos)
+			require.False(t, diags.HasErrors())
+
+			tokens, converted := BuildMapFromBlocks(FindBlocksWithDynamic(file.Body(), "header"), "header", "values")
+			assert.Len(t, converted, tt.converted)
+			if tt.expected == "" {
+				assert.Nil(t, tokens)
+				return
+			}
+			assert.Equal(t, tt.expected, string(hclwrite.Format(tokens.Bytes())))
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package hcl
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	hcljson "github.com/hashicorp/hcl/v2/json"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+	"github.com/zclconf/go-cty/cty/function/stdlib"
+)
+
+// SourceFile is the name and content of a configuration or variable
+// definitions file.
+type SourceFile struct {
+	Name    string
+	Content []byte
+}
+
+// evalFunctions are the Terraform functions available when evaluating
+// expressions statically. Only pure functions that commonly build resource
+// arguments from variables are included.
+var evalFunctions = map[string]function.Function{
+	"coalesce":  stdlib.CoalesceFunc,
+	"concat":    stdlib.ConcatFunc,
+	"format":    stdlib.FormatFunc,
+	"join":      stdlib.JoinFunc,
+	"lookup":    stdlib.LookupFunc,
+	"lower":     stdlib.LowerFunc,
+	"merge":     stdlib.MergeFunc,
+	"replace":   stdlib.ReplaceFunc,
+	"trimspace": stdlib.TrimSpaceFunc,
+	"upper":     stdlib.UpperFunc,
+}
+
+// BuildEvalContext returns an evaluation context holding the values of the
+// input variables and locals of a module. configFiles are the module's
+// configuration files; varFiles are variable definitions files (.tfvars,
+// .tfvars.json) in the order Terraform loads them, later files taking
+// precedence.
+//
+// A variable gets its value from the last variable definitions file that sets
+// it, and otherwise from its default. Locals are evaluated against the
+// variables and each other. Variables and locals whose value cannot be known
+// statically are left out, so that expressions referencing them fail to
+// evaluate instead of producing a wrong value.
+func BuildEvalContext(configFiles []SourceFile, varFiles []SourceFile) *hcl.EvalContext {
+	return buildEvalContext(configFiles, varFiles, true)
+}
+
+// BuildModuleEvalContext returns the evaluation context of a module called
+// by another one. Its variables are set by the arguments of the module call,
+// not by their defaults, so they are all left out; only locals that do not
+// depend on them are resolved.
+func BuildModuleEvalContext(configFiles []SourceFile) *hcl.EvalContext {
+	return buildEvalContext(configFiles, nil, false)
+}
+
+func buildEvalContext(configFiles []SourceFile, varFiles []SourceFile, defaults bool) *hcl.EvalContext {
+	declared := make(map[string]bool)
+	variables := make(map[string]cty.Value)
+	localExprs := make(map[string]hcl.Expression)
+
+	for _, file := range configFiles {
+		native, err := NativeSyntax(file.Content, file.Name)
+		if err != nil {
+			continue
+		}
+		parsed, diags := hclsyntax.ParseConfig(native, file.Name, hcl.InitialPos)
+		if diags.HasErrors() {
+			continue
+		}
+		body, ok := parsed.Body.(*hclsyntax.Body)
+		if !ok {
+			continue
+		}
+		for _, block := range body.Blocks {
+			switch {
+			case block.Type == "variable" && len(block.Labels) == 1:
+				name := block.Labels[0]
+				declared[name] = true
+				if attr, ok := block.Body.Attributes["default"]; ok && defaults {
+					if value, ok := staticValue(attr.Expr, nil); ok {
+						variables[name] = value
+					}
+				}
+			case block.Type == "locals":
+				for name, attr := range block.Body.Attributes {
+					localExprs[name] = attr.Expr
+				}
+			}
+		}
+	}
+
+	for _, file := range varFiles {
+		var parsed *hcl.File
+		var diags hcl.Diagnostics
+		if strings.HasSuffix(file.Name, ".json") {
+			parsed, diags = hcljson.Parse(file.Content, file.Name)
+		} else {
+			parsed, diags = hclsyntax.ParseConfig(file.Content, file.Name, hcl.InitialPos)
+		}
+		if diags.HasErrors() {
+			continue
+		}
+		attrs, _ := parsed.Body.JustAttributes()
+		for name, attr := range attrs {
+			// Values for undeclared variables are ignored, as in Terraform.
+			if !declared[name] {
+				continue
+			}
+			if value, ok := staticValue(attr.Expr, nil); ok {
+				variables[name] = value
+			} else {
+				delete(variables, name)
+			}
+		}
+	}
+
+	// Locals may reference each other in any order, so evaluate them until no
+	// more can be resolved.
+	locals := make(map[string]cty.Value)
+	names := make([]string, 0, len(localExprs))
+	for name := range localExprs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for progress := true; progress; {
+		progress = false
+		ctx := newEvalContext(variables, locals)
+		for _, name := range names {
+			if _, done := locals[name]; done {
+				continue
+			}
+			if value, ok := staticValue(localExprs[name], ctx); ok {
+				locals[name] = value
+				progress = true
+			}
+		}
+	}
+
+	return newEvalContext(variables, locals)
+}
+
+func newEvalContext(variables, locals map[string]cty.Value) *hcl.EvalContext {
+	return &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"var":   cty.ObjectVal(variables),
+			"local": cty.ObjectVal(locals),
+		},
+		Functions: evalFunctions,
+	}
+}
+
+// staticValue evaluates expr and reports whether it has a wholly known value.
+func staticValue(expr hcl.Expression, ctx *hcl.EvalContext) (cty.Value, bool) {
+	value, diags := expr.Value(ctx)
+	if diags.HasErrors() || !value.IsWhollyKnown() {
+		return cty.NilVal, false
+	}
+	return value, true
+}
+
+// EvaluateAttribute evaluates the expression of attr against ctx, which may
+// be nil to evaluate literals only. It reports false when the expression
+// references anything ctx does not define, such as resource attributes, or
+// calls an unsupported function. The second result reports whether the
+// expression is a plain literal, i.e. references no variables.
+func EvaluateAttribute(attr *hclwrite.Attribute, ctx *hcl.EvalContext) (value cty.Value, literal bool, ok bool) {
+	if attr == nil {
+		return cty.NilVal, false, false
+	}
+	expr, diags := hclsyntax.ParseExpression(attr.Expr().BuildTokens(nil).Bytes(), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return cty.NilVal, false, false
+	}
+	literal = len(expr.Variables()) == 0
+	value, ok = staticValue(expr, ctx)
+	return value, literal, ok
+}
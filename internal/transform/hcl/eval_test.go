@@ -0,0 +1,121 @@
+package hcl
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestBuildEvalContext(t *testing.T) {
+	configFiles := []SourceFile{
+		{Name: "variables.tf", Content: []byte(`
+variable "record_type" {
+  default = "a"
+}
+variable "zone" {
+  default = "example.com"
+}
+variable "no_default" {}
+variable "overridden" {
+  default = "default"
+}
+`)},
+		{Name: "locals.tf.json", Content: []byte(`{"locals": {"fqdn": "www.${local.zone}"}}`)},
+		{Name: "main.tf", Content: []byte(`
+locals {
+  kind    = upper(var.record_type)
+  zone    = var.zone
+  unknown = cloudflare_zone.z.id
+  missing = var.no_default
+}
+`)},
+	}
+	varFiles := []SourceFile{
+		{Name: "terraform.tfvars", Content: []byte("overridden = \"first\"\nundeclared = \"x\"\n")},
+		{Name: "prod.auto.tfvars.json", Content: []byte(`{"overridden": "second"}`)},
+	}
+
+	ctx := BuildEvalContext(configFiles, varFiles)
+
+	vars := ctx.Variables["var"]
+	assert.Equal(t, cty.StringVal("a"), vars.GetAttr("record_type"))
+	assert.Equal(t, cty.StringVal("second"), vars.GetAttr("overridden"))
+	assert.False(t, vars.Type().HasAttribute("no_default"))
+	assert.False(t, vars.Type().HasAttribute("undeclared"))
+
+	locals := ctx.Variables["local"]
+	assert.Equal(t, cty.StringVal("A"), locals.GetAttr("kind"))
+	assert.Equal(t, cty.StringVal("www.example.com"), locals.GetAttr("fqdn"))
+	assert.False(t, locals.Type().HasAttribute("unknown"))
+	assert.False(t, locals.Type().HasAttribute("missing"))
+}
+
+func TestBuildModuleEvalContext(t *testing.T) {
+	ctx := BuildModuleEvalContext([]SourceFile{{Name: "main.tf", Content: []byte(`
+variable "hostname" {
+  default = null
+}
+locals {
+  fqdn   = "www.${var.hostname}"
+  region = "eu"
+}
+`)}})
+
+	// Variables are set by the caller, so their defaults are not used.
+	assert.False(t, ctx.Variables["var"].Type().HasAttribute("hostname"))
+	locals := ctx.Variables["local"]
+	assert.False(t, locals.Type().HasAttribute("fqdn"))
+	assert.Equal(t, cty.StringVal("eu"), locals.GetAttr("region"))
+}
+
+func TestEvaluateAttribute(t *testing.T) {
+	file, diags := hclwrite.ParseConfig([]byte(`
+literal  = "A"
+variable = var.record_type
+template = "${local.prefix}-record"
+resource = cloudflare_zone.z.id
+nothing  = null
+`), "test.tf", hcl.InitialPos)
+	require.False(t, diags.HasErrors())
+	body := file.Body()
+
+	ctx := BuildEvalContext([]SourceFile{{Name: "main.tf", Content: []byte(`
+variable "record_type" {
+  default = "CNAME"
+}
+locals {
+  prefix = "www"
+}
+`)}}, nil)
+
+	value, literal, ok := EvaluateAttribute(body.GetAttribute("literal"), nil)
+	require.True(t, ok)
+	assert.True(t, literal)
+	assert.Equal(t, cty.StringVal("A"), value)
+
+	_, _, ok = EvaluateAttribute(body.GetAttribute("variable"), nil)
+	assert.False(t, ok, "variables need a context")
+
+	value, literal, ok = EvaluateAttribute(body.GetAttribute("variable"), ctx)
+	require.True(t, ok)
+	assert.False(t, literal)
+	assert.Equal(t, cty.StringVal("CNAME"), value)
+
+	value, _, ok = EvaluateAttribute(body.GetAttribute("template"), ctx)
+	require.True(t, ok)
+	assert.Equal(t, cty.StringVal("www-record"), value)
+
+	_, _, ok = EvaluateAttribute(body.GetAttribute("resource"), ctx)
+	assert.False(t, ok)
+
+	value, _, ok = EvaluateAttribute(body.GetAttribute("nothing"), ctx)
+	require.True(t, ok)
+	assert.True(t, value.IsNull())
+
+	_, _, ok = EvaluateAttribute(body.GetAttribute("absent"), ctx)
+	assert.False(t, ok)
+}
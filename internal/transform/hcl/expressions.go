@@ -8,20 +8,6 @@ import (
 	"github.com/hashicorp/hcl/v2/hclwrite"
 )
 
-// StripIteratorValueSuffix removes the .value suffix from iterator expressions.
-// For example, "item.value" becomes "item" when iteratorName is "item".
-func StripIteratorValueSuffix(expr string, iteratorName string) string {
-	if iteratorName == "" {
-		return expr
-	}
-	// Replace iterator.value with just the iterator name
-	valueSuffix := iteratorName + ".value"
-	if strings.Contains(expr, valueSuffix) {
-		expr = strings.ReplaceAll(expr, valueSuffix, iteratorName)
-	}
-	return expr
-}
-
 // ConvertEnabledDisabledInExpr converts "enabled"/"disabled" string literals
 // to true/false boolean values in an expression string.
 func ConvertEnabledDisabledInExpr(expr string) string {
@@ -33,19 +19,23 @@ func ConvertEnabledDisabledInExpr(expr string) string {
 // SetAttributeFromExpressionString parses an expression string and sets it as an attribute.
 // Returns an error if the expression cannot be parsed.
 func SetAttributeFromExpressionString(body *hclwrite.Body, attrName string, exprStr string) error {
-	// Create a temporary HCL file to parse the expression
-	tempHCL := attrName + " = " + exprStr
-	file, diags := hclwrite.ParseConfig([]byte(tempHCL), "expr", hcl.InitialPos)
-	if diags.HasErrors() {
-		return diags
+	tokens, err := TokensForExpressionString(exprStr)
+	if err != nil {
+		return err
 	}
+	body.SetAttributeRaw(attrName, tokens)
+	return nil
+}
 
-	// Extract the attribute and set it on the body
-	if attr := file.Body().GetAttribute(attrName); attr != nil {
-		body.SetAttributeRaw(attrName, attr.Expr().BuildTokens(nil))
+// TokensForExpressionString parses an expression string into tokens.
+// Returns an error if the expression cannot be parsed.
+func TokensForExpressionString(exprStr string) (hclwrite.Tokens, error) {
+	// Create a temporary HCL file to parse the expression
+	file, diags := hclwrite.ParseConfig([]byte("expr = "+exprStr), "expr", hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, diags
 	}
-
-	return nil
+	return file.Body().GetAttribute("expr").Expr().BuildTokens(nil), nil
 }
 
 // IsExpressionAttribute checks if an attribute contains a non-literal expression
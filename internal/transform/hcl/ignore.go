@@ -0,0 +1,164 @@
+package hcl
+
+import (
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+const (
+	// IgnoreDirective, in a comment directly above a resource or data block,
+	// leaves the block as it is:
+	//
+	//	# tf-migrate:ignore hand-migrated later
+	//	resource "cloudflare_list" "allowed" {
+	IgnoreDirective = "tf-migrate:ignore"
+
+	// IgnoreFileDirective, in a comment before the first block or attribute
+	// of a file, leaves the whole file as it is.
+	IgnoreFileDirective = "tf-migrate:ignore-file"
+)
+
+// IgnoreAnnotations holds what a file asks tf-migrate to leave alone.
+type IgnoreAnnotations struct {
+	// File is set when the file carries IgnoreFileDirective.
+	File bool
+	// Blocks holds the resource and data blocks annotated with
+	// IgnoreDirective, in source order.
+	Blocks []IgnoredBlock
+}
+
+// IgnoredBlock is a resource or data block annotated with IgnoreDirective.
+type IgnoredBlock struct {
+	// Type is the resource type, prefixed with "data." for datasources.
+	Type string
+	Name string
+	// Range covers the block, from its type keyword to its closing brace.
+	Range hcl.Range
+}
+
+// Empty reports whether nothing in the file is ignored.
+func (a IgnoreAnnotations) Empty() bool {
+	return !a.File && len(a.Blocks) == 0
+}
+
+// Ignores reports whether the block of resourceType, prefixed with "data."
+// for datasources, named name is ignored.
+func (a IgnoreAnnotations) Ignores(resourceType, name string) bool {
+	if a.File {
+		return true
+	}
+	for _, b := range a.Blocks {
+		if b.Type == resourceType && b.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Covers reports whether rng lies in the ignored file or in an ignored block.
+func (a IgnoreAnnotations) Covers(rng hcl.Range) bool {
+	if a.File {
+		return true
+	}
+	for _, b := range a.Blocks {
+		if b.Range.ContainsOffset(rng.Start.Byte) {
+			return true
+		}
+	}
+	return false
+}
+
+// FindIgnoreAnnotations returns the ignore annotations of content, which is in
+// native syntax. Annotations are line comments (#, //) or block comments on
+// lines of their own. A block is ignored when IgnoreDirective is in the run
+// of comment lines directly above it, without a blank line in between; text
+// after the directive is free-form. Content that does not parse has no
+// ignored blocks, but may still be an ignored file.
+func FindIgnoreAnnotations(content []byte, filename string) IgnoreAnnotations {
+	var annotations IgnoreAnnotations
+
+	// comments maps the last line of each comment that stands on lines of its
+	// own to its directive.
+	type comment struct {
+		firstLine int
+		directive string
+	}
+	comments := make(map[int]comment)
+
+	tokens, _ := hclsyntax.LexConfig(content, filename, hcl.InitialPos)
+	seenCode := false
+	lastCodeLine := 0
+	for _, tok := range tokens {
+		switch tok.Type {
+		case hclsyntax.TokenComment:
+			if tok.Range.Start.Line == lastCodeLine {
+				continue
+			}
+			text := strings.TrimRight(string(tok.Bytes), "\r\n")
+			d := commentDirective(text)
+			if d == IgnoreFileDirective && !seenCode {
+				annotations.File = true
+			}
+			last := tok.Range.Start.Line + strings.Count(text, "\n")
+			comments[last] = comment{firstLine: tok.Range.Start.Line, directive: d}
+		case hclsyntax.TokenNewline, hclsyntax.TokenEOF:
+		default:
+			seenCode = true
+			lastCodeLine = tok.Range.End.Line
+		}
+	}
+
+	file, diags := hclsyntax.ParseConfig(content, filename, hcl.InitialPos)
+	if diags.HasErrors() {
+		return annotations
+	}
+	body, ok := file.Body.(*hclsyntax.Body)
+	if !ok {
+		return annotations
+	}
+	for _, block := range body.Blocks {
+		if (block.Type != "resource" && block.Type != "data") || len(block.Labels) < 2 {
+			continue
+		}
+		for line := block.TypeRange.Start.Line - 1; ; {
+			c, ok := comments[line]
+			if !ok {
+				break
+			}
+			if c.directive == IgnoreDirective {
+				resourceType := block.Labels[0]
+				if block.Type == "data" {
+					resourceType = "data." + resourceType
+				}
+				annotations.Blocks = append(annotations.Blocks, IgnoredBlock{
+					Type:  resourceType,
+					Name:  block.Labels[1],
+					Range: block.Range(),
+				})
+				break
+			}
+			line = c.firstLine - 1
+		}
+	}
+	return annotations
+}
+
+// commentDirective returns the first word of a comment, without its comment
+// markers.
+func commentDirective(text string) string {
+	switch {
+	case strings.HasPrefix(text, "#"):
+		text = text[1:]
+	case strings.HasPrefix(text, "//"):
+		text = text[2:]
+	case strings.HasPrefix(text, "/*"):
+		text = strings.TrimSuffix(text[2:], "*/")
+	}
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[0]
+}
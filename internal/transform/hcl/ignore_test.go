@@ -0,0 +1,82 @@
+package hcl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindIgnoreAnnotations(t *testing.T) {
+	input := `# Lists are migrated by hand.
+# tf-migrate:ignore until the new format is agreed
+# owner: network team
+resource "cloudflare_list" "allowed" {
+  kind = "ip" # tf-migrate:ignore
+}
+
+// tf-migrate:ignore
+data "cloudflare_zones" "all" {}
+
+# tf-migrate:ignore
+
+resource "cloudflare_record" "separated" {}
+
+resource "cloudflare_record" "trailing" {} # tf-migrate:ignore
+resource "cloudflare_record" "next" {}
+
+/* tf-migrate:ignore */
+resource "cloudflare_record" "block_comment" {}
+
+# tf-migrate:ignore-file
+resource "cloudflare_record" "late_file_directive" {}
+`
+	annotations := FindIgnoreAnnotations([]byte(input), "main.tf")
+	assert.False(t, annotations.File, "ignore-file after the first block does not apply")
+
+	var got []string
+	for _, b := range annotations.Blocks {
+		got = append(got, b.Type+"."+b.Name)
+	}
+	assert.Equal(t, []string{
+		"cloudflare_list.allowed",
+		"data.cloudflare_zones.all",
+		"cloudflare_record.block_comment",
+	}, got)
+
+	assert.True(t, annotations.Ignores("cloudflare_list", "allowed"))
+	assert.False(t, annotations.Ignores("cloudflare_record", "next"))
+	assert.Equal(t, `resource "cloudflare_list" "allowed" {
+  kind = "ip" # tf-migrate:ignore
+}`, sourceText(input, annotations.Blocks[0].Range))
+
+	refsInput := []byte(`resource "a" "b" {
+  x = cloudflare_record.r.id
+}
+# tf-migrate:ignore
+resource "a" "c" {
+  x = cloudflare_record.r.id
+}
+`)
+	refs, diags := FindReferences(refsInput, "main.tf")
+	assert.False(t, diags.HasErrors())
+	annotations = FindIgnoreAnnotations(refsInput, "main.tf")
+	if assert.Len(t, refs, 2) {
+		assert.False(t, annotations.Covers(refs[0].TypeRange))
+		assert.True(t, annotations.Covers(refs[1].TypeRange))
+	}
+}
+
+func TestFindIgnoreAnnotations_File(t *testing.T) {
+	for name, input := range map[string]string{
+		"first line":     "# tf-migrate:ignore-file\nresource \"cloudflare_record\" \"r\" {}\n",
+		"after comments": "# Managed by hand.\n\n// tf-migrate:ignore-file\n\nresource \"cloudflare_record\" \"r\" {}\n",
+		"empty file":     "# tf-migrate:ignore-file\n",
+	} {
+		annotations := FindIgnoreAnnotations([]byte(input), "main.tf")
+		assert.True(t, annotations.File, name)
+		assert.True(t, annotations.Ignores("cloudflare_record", "r"), name)
+		assert.False(t, annotations.Empty(), name)
+	}
+
+	assert.True(t, FindIgnoreAnnotations([]byte("resource \"cloudflare_record\" \"r\" {}\n"), "main.tf").Empty())
+}
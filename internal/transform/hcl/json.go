@@ -0,0 +1,948 @@
+package hcl
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Terraform's JSON configuration syntax (.tf.json) cannot be edited with
+// hclwrite. Instead, a .tf.json file is converted to an equivalent file in
+// native syntax with JSONToNative, migrated like any other file, and converted
+// back with NativeToJSON.
+//
+// JSON syntax does not say whether an object is a nested block or an object
+// attribute; Terraform decides using the provider schema. Without one, the
+// conversion uses the table of v4 nested blocks in json_blocks.go: inside a
+// resource or data body, an object is a block only if the table lists it for
+// that type, and any other object is an attribute. An array of objects is
+// read as a repeated block, the only way JSON syntax writes one, except in
+// bodies that only hold arguments (locals, module, variable, output,
+// required_providers, ...). Meta-argument blocks such as lifecycle and
+// dynamic are blocks wherever Terraform allows them. Both forms are written
+// back to JSON the same way, so the conversion is lossless for anything a
+// migrator leaves untouched.
+
+// jsonCommentAttribute is the native attribute a "//" comment property is
+// converted to, so that comments survive the round trip.
+const jsonCommentAttribute = "tf_migrate_json_comment"
+
+// IsJSONConfigFile reports whether filename uses Terraform's JSON syntax.
+func IsJSONConfigFile(filename string) bool {
+	return strings.HasSuffix(filename, ".tf.json")
+}
+
+// NativeSyntax returns content in native syntax: a .tf.json file is converted
+// with JSONToNative, any other file is returned as it is.
+func NativeSyntax(content []byte, filename string) ([]byte, error) {
+	if !IsJSONConfigFile(filename) {
+		return content, nil
+	}
+	return JSONToNative(content, filename)
+}
+
+// ParseConfigFile parses content as a configuration file in either syntax.
+// A .tf.json file is converted with JSONToNative first.
+func ParseConfigFile(content []byte, filename string) (*hclwrite.File, hcl.Diagnostics) {
+	if IsJSONConfigFile(filename) {
+		native, err := JSONToNative(content, filename)
+		if err != nil {
+			return nil, hcl.Diagnostics{{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid JSON configuration",
+				Detail:   err.Error(),
+			}}
+		}
+		content = native
+	}
+	return hclwrite.ParseConfig(content, filename, hcl.InitialPos)
+}
+
+// jsonBodySchema describes how the properties of a JSON body object map to
+// native attributes and blocks.
+type jsonBodySchema struct {
+	// blocks lists the nested block types that are known for the body.
+	blocks map[string]jsonBlockSchema
+	// attributesOnly reads any other object-valued property as an attribute.
+	// Otherwise it is read as a nested block.
+	attributesOnly bool
+	// staticRefs lists the properties whose strings are bare references
+	// (e.g. depends_on = ["cloudflare_record.x"]) rather than templates.
+	staticRefs map[string]bool
+}
+
+type jsonBlockSchema struct {
+	labels int
+	body   *jsonBodySchema
+}
+
+var (
+	jsonNestedBlockSchema = &jsonBodySchema{}
+	jsonArgumentsSchema   = &jsonBodySchema{attributesOnly: true}
+	jsonConditionSchema   = jsonArgumentsSchema
+
+	jsonLifecycleSchema = &jsonBodySchema{
+		attributesOnly: true,
+		blocks: map[string]jsonBlockSchema{
+			"precondition":  {body: jsonConditionSchema},
+			"postcondition": {body: jsonConditionSchema},
+		},
+		staticRefs: map[string]bool{"ignore_changes": true, "replace_triggered_by": true},
+	}
+
+	jsonDynamicSchema = &jsonBodySchema{
+		attributesOnly: true,
+		blocks: map[string]jsonBlockSchema{
+			"content": {body: jsonNestedBlockSchema},
+		},
+	}
+
+	jsonResourceSchema = &jsonBodySchema{
+		blocks: map[string]jsonBlockSchema{
+			"lifecycle":   {body: jsonLifecycleSchema},
+			"dynamic":     {labels: 1, body: jsonDynamicSchema},
+			"provisioner": {labels: 1, body: jsonNestedBlockSchema},
+			"connection":  {body: jsonArgumentsSchema},
+		},
+		staticRefs: map[string]bool{"provider": true, "depends_on": true},
+	}
+
+	jsonTopLevelBlocks = map[string]jsonBlockSchema{
+		"resource": {labels: 2, body: jsonResourceSchema},
+		"data":     {labels: 2, body: jsonResourceSchema},
+		"provider": {labels: 1, body: jsonNestedBlockSchema},
+		"variable": {labels: 1, body: &jsonBodySchema{
+			attributesOnly: true,
+			blocks:         map[string]jsonBlockSchema{"validation": {body: jsonConditionSchema}},
+		}},
+		"output": {labels: 1, body: &jsonBodySchema{
+			attributesOnly: true,
+			blocks:         map[string]jsonBlockSchema{"precondition": {body: jsonConditionSchema}},
+			staticRefs:     map[string]bool{"depends_on": true},
+		}},
+		"module": {labels: 1, body: &jsonBodySchema{
+			attributesOnly: true,
+			staticRefs:     map[string]bool{"depends_on": true, "providers": true},
+		}},
+		"locals": {body: jsonArgumentsSchema},
+		"terraform": {body: &jsonBodySchema{
+			attributesOnly: true,
+			blocks: map[string]jsonBlockSchema{
+				"required_providers": {body: jsonArgumentsSchema},
+				"backend":            {labels: 1, body: jsonArgumentsSchema},
+				"cloud":              {body: jsonNestedBlockSchema},
+			},
+		}},
+		"moved": {body: &jsonBodySchema{
+			attributesOnly: true,
+			staticRefs:     map[string]bool{"from": true, "to": true},
+		}},
+		"removed": {body: &jsonBodySchema{
+			attributesOnly: true,
+			blocks:         map[string]jsonBlockSchema{"lifecycle": {body: jsonArgumentsSchema}},
+			staticRefs:     map[string]bool{"from": true},
+		}},
+		"import": {body: &jsonBodySchema{
+			attributesOnly: true,
+			staticRefs:     map[string]bool{"to": true, "provider": true},
+		}},
+	}
+
+	jsonRootSchema = &jsonBodySchema{blocks: jsonTopLevelBlocks}
+)
+
+// block returns the schema of the nested block type name, and whether name
+// is a block at all. An object is an attribute unless the schema lists it as
+// a block, while an array of objects is read as a repeated block, which is
+// how JSON syntax writes them. Objects with keys that are not identifiers
+// can only be attributes.
+func (s *jsonBodySchema) block(name string, value *jsonValue) (jsonBlockSchema, bool) {
+	if block, ok := s.blocks[name]; ok && (block.labels > 0 || value.isBlockShaped()) {
+		return block, true
+	}
+	if s == jsonRootSchema {
+		return jsonBlockSchema{body: jsonNestedBlockSchema}, true
+	}
+	if s.attributesOnly {
+		return jsonBlockSchema{}, false
+	}
+	if name == "dynamic" {
+		return jsonBlockSchema{labels: 1, body: jsonDynamicSchema}, true
+	}
+	if value.kind != jsonArray || !value.isBlockShaped() {
+		return jsonBlockSchema{}, false
+	}
+	return jsonBlockSchema{body: jsonNestedBlockSchema}, true
+}
+
+// bodySchema returns the schema of the body of a block nested in s, once its
+// labels are known: resources are looked up by type, and the content of a
+// dynamic block has the schema of the block it generates.
+func (s *jsonBodySchema) bodySchema(blockType string, labels []string, block jsonBlockSchema) *jsonBodySchema {
+	switch {
+	case s == jsonRootSchema && (blockType == "resource" || blockType == "data"):
+		return jsonResourceSchemaFor(labels[0])
+	case blockType == "dynamic" && block.body == jsonDynamicSchema:
+		return &jsonBodySchema{
+			attributesOnly: true,
+			blocks: map[string]jsonBlockSchema{
+				"content": {body: s.blockSchema(labels[0])},
+			},
+		}
+	}
+	return block.body
+}
+
+// blockSchema returns the schema of a native block nested in s. Unlike
+// block, the native syntax already says that it is a block.
+func (s *jsonBodySchema) blockSchema(name string) *jsonBodySchema {
+	if block, ok := s.blocks[name]; ok {
+		return block.body
+	}
+	return jsonNestedBlockSchema
+}
+
+type jsonKind int
+
+const (
+	jsonNull jsonKind = iota
+	jsonBool
+	jsonNumber
+	jsonString
+	jsonArray
+	jsonObject
+)
+
+// jsonValue is a decoded JSON value that keeps the order of object
+// properties, which encoding/json maps do not.
+type jsonValue struct {
+	kind    jsonKind
+	text    string // string value, or number literal
+	boolean bool
+	keys    []string     // object property names, in order
+	values  []*jsonValue // object property values, parallel to keys
+	items   []*jsonValue // array elements
+}
+
+// isBlockShaped reports whether v can be read as one or more nested blocks:
+// objects whose keys are all identifiers.
+func (v *jsonValue) isBlockShaped() bool {
+	switch v.kind {
+	case jsonObject:
+		for _, key := range v.keys {
+			if key != "//" && !identifierPattern.MatchString(key) {
+				return false
+			}
+		}
+		return true
+	case jsonArray:
+		if len(v.items) == 0 {
+			return false
+		}
+		for _, item := range v.items {
+			if item.kind != jsonObject || !item.isBlockShaped() {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func decodeJSON(src []byte) (*jsonValue, error) {
+	dec := json.NewDecoder(bytes.NewReader(src))
+	dec.UseNumber()
+	value, err := decodeJSONValue(dec)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("unexpected content after the top-level value")
+	}
+	return value, nil
+}
+
+func decodeJSONValue(dec *json.Decoder) (*jsonValue, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t := tok.(type) {
+	case json.Delim:
+		switch t {
+		case '{':
+			value := &jsonValue{kind: jsonObject}
+			for dec.More() {
+				keyTok, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				key, ok := keyTok.(string)
+				if !ok {
+					return nil, fmt.Errorf("expected object key, got %v", keyTok)
+				}
+				child, err := decodeJSONValue(dec)
+				if err != nil {
+					return nil, err
+				}
+				value.keys = append(value.keys, key)
+				value.values = append(value.values, child)
+			}
+			_, err := dec.Token()
+			return value, err
+		case '[':
+			value := &jsonValue{kind: jsonArray}
+			for dec.More() {
+				child, err := decodeJSONValue(dec)
+				if err != nil {
+					return nil, err
+				}
+				value.items = append(value.items, child)
+			}
+			_, err := dec.Token()
+			return value, err
+		}
+	case string:
+		return &jsonValue{kind: jsonString, text: t}, nil
+	case json.Number:
+		return &jsonValue{kind: jsonNumber, text: t.String()}, nil
+	case bool:
+		return &jsonValue{kind: jsonBool, boolean: t}, nil
+	case nil:
+		return &jsonValue{kind: jsonNull}, nil
+	}
+	return nil, fmt.Errorf("unexpected JSON token %v", tok)
+}
+
+// JSONToNative converts a .tf.json file to native syntax.
+func JSONToNative(src []byte, filename string) ([]byte, error) {
+	root, err := decodeJSON(src)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", filename, err)
+	}
+	if root.kind != jsonObject {
+		return nil, fmt.Errorf("failed to decode %s: the top-level value must be an object", filename)
+	}
+
+	w := &nativeWriter{filename: filename}
+	if err := w.writeBody(root, jsonRootSchema); err != nil {
+		return nil, fmt.Errorf("failed to convert %s to native syntax: %w", filename, err)
+	}
+
+	native := hclwrite.Format([]byte(w.buf.String()))
+	if _, diags := hclwrite.ParseConfig(native, filename, hcl.InitialPos); diags.HasErrors() {
+		return nil, fmt.Errorf("failed to convert %s to native syntax: %s", filename, diags.Error())
+	}
+	return native, nil
+}
+
+type nativeWriter struct {
+	filename string
+	depth    int
+	buf      strings.Builder
+}
+
+func (w *nativeWriter) writeBody(body *jsonValue, schema *jsonBodySchema) error {
+	for i, key := range body.keys {
+		value := body.values[i]
+
+		if key == "//" {
+			w.writeAttributeName(jsonCommentAttribute)
+			w.writeExpr(value)
+			w.buf.WriteString("\n")
+			continue
+		}
+
+		if block, ok := schema.block(key, value); ok {
+			if err := w.writeBlocks(key, nil, block.labels, value, schema, block); err != nil {
+				return err
+			}
+			continue
+		}
+
+		w.writeAttributeName(key)
+		if schema.staticRefs[key] {
+			w.writeStatic(value)
+		} else {
+			w.writeExpr(value)
+		}
+		w.buf.WriteString("\n")
+	}
+	return nil
+}
+
+// writeBlocks writes the blocks of type blockType held by value, which has
+// one level of object nesting per remaining label. At every level, an array
+// holds several objects of that level, as JSON syntax allows. parent is the
+// schema of the body the blocks are written in.
+func (w *nativeWriter) writeBlocks(blockType string, labels []string, remaining int, value *jsonValue, parent *jsonBodySchema, block jsonBlockSchema) error {
+	if value.kind == jsonArray {
+		for _, item := range value.items {
+			if item.kind == jsonArray {
+				return fmt.Errorf("%s: unexpected nested array", jsonBlockPath(blockType, labels))
+			}
+			if err := w.writeBlocks(blockType, labels, remaining, item, parent, block); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if value.kind != jsonObject {
+		return fmt.Errorf("%s: expected an object or an array of objects", jsonBlockPath(blockType, labels))
+	}
+
+	if remaining > 0 {
+		for i, label := range value.keys {
+			// Terraform ignores comments where it expects labels
+			if label == "//" {
+				continue
+			}
+			if err := w.writeBlocks(blockType, append(append([]string{}, labels...), label), remaining-1, value.values[i], parent, block); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	w.buf.WriteString(blockType)
+	for _, label := range labels {
+		w.buf.WriteString(" ")
+		w.buf.WriteString(quoteNative(label))
+	}
+	w.buf.WriteString(" {\n")
+	w.depth++
+	if err := w.writeBody(value, parent.bodySchema(blockType, labels, block)); err != nil {
+		return err
+	}
+	w.depth--
+	w.buf.WriteString("}\n")
+	if w.depth == 0 {
+		w.buf.WriteString("\n")
+	}
+	return nil
+}
+
+// jsonBlockPath returns the block type and labels read so far, for errors.
+func jsonBlockPath(blockType string, labels []string) string {
+	return strings.Join(append([]string{blockType}, labels...), ".")
+}
+
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+func (w *nativeWriter) writeAttributeName(name string) {
+	w.buf.WriteString(name)
+	w.buf.WriteString(" = ")
+}
+
+func (w *nativeWriter) writeObjectKey(key string) {
+	if identifierPattern.MatchString(key) {
+		w.buf.WriteString(key)
+	} else {
+		w.writeTemplate(key)
+	}
+	w.buf.WriteString(" = ")
+}
+
+// writeExpr writes value as a native expression. JSON strings are templates.
+func (w *nativeWriter) writeExpr(value *jsonValue) {
+	switch value.kind {
+	case jsonNull:
+		w.buf.WriteString("null")
+	case jsonBool:
+		w.buf.WriteString(strconv.FormatBool(value.boolean))
+	case jsonNumber:
+		w.buf.WriteString(value.text)
+	case jsonString:
+		w.writeTemplate(value.text)
+	case jsonArray:
+		w.buf.WriteString("[")
+		for i, item := range value.items {
+			if i > 0 {
+				w.buf.WriteString(", ")
+			}
+			w.writeExpr(item)
+		}
+		w.buf.WriteString("]")
+	case jsonObject:
+		w.buf.WriteString("{\n")
+		for i, key := range value.keys {
+			w.writeObjectKey(key)
+			w.writeExpr(value.values[i])
+			w.buf.WriteString("\n")
+		}
+		w.buf.WriteString("}")
+	}
+}
+
+// writeStatic writes value as bare references, e.g. the elements of
+// depends_on.
+func (w *nativeWriter) writeStatic(value *jsonValue) {
+	switch value.kind {
+	case jsonString:
+		w.buf.WriteString(value.text)
+	case jsonArray:
+		w.buf.WriteString("[")
+		for i, item := range value.items {
+			if i > 0 {
+				w.buf.WriteString(", ")
+			}
+			w.writeStatic(item)
+		}
+		w.buf.WriteString("]")
+	case jsonObject:
+		w.buf.WriteString("{\n")
+		for i, key := range value.keys {
+			w.writeObjectKey(key)
+			w.writeStatic(value.values[i])
+			w.buf.WriteString("\n")
+		}
+		w.buf.WriteString("}")
+	default:
+		w.writeExpr(value)
+	}
+}
+
+// writeTemplate writes a JSON string, which is a template, as a native
+// expression: a single interpolation such as "${var.x}" becomes the bare
+// expression, anything else a quoted template.
+func (w *nativeWriter) writeTemplate(text string) {
+	if !strings.Contains(text, "${") && !strings.Contains(text, "%{") {
+		w.buf.WriteString(quoteNative(text))
+		return
+	}
+
+	expr, diags := hclsyntax.ParseTemplate([]byte(text), w.filename, hcl.InitialPos)
+	if diags.HasErrors() {
+		// Terraform would reject the template too; keep the text as it is.
+		w.buf.WriteString(quoteNative(text))
+		return
+	}
+	if wrap, ok := expr.(*hclsyntax.TemplateWrapExpr); ok {
+		rng := wrap.Wrapped.Range()
+		w.buf.WriteString(text[rng.Start.Byte:rng.End.Byte])
+		return
+	}
+
+	tokens, _ := hclsyntax.LexTemplate([]byte(text), w.filename, hcl.InitialPos)
+	w.buf.WriteString(`"`)
+	depth, pos := 0, 0
+	for _, tok := range tokens {
+		if tok.Type == hclsyntax.TokenEOF {
+			break
+		}
+		start, end := tok.Range.Start.Byte, tok.Range.End.Byte
+		if depth == 0 && tok.Type == hclsyntax.TokenStringLit {
+			w.buf.WriteString(escapeNativeLiteral(text[start:end]))
+		} else {
+			w.buf.WriteString(text[pos:end])
+		}
+		pos = end
+
+		switch tok.Type {
+		case hclsyntax.TokenTemplateInterp, hclsyntax.TokenTemplateControl:
+			depth++
+		case hclsyntax.TokenTemplateSeqEnd:
+			depth--
+		}
+	}
+	w.buf.WriteString(`"`)
+}
+
+// quoteNative returns text as a native quoted string with no interpolation.
+func quoteNative(text string) string {
+	text = strings.ReplaceAll(text, "${", "$${")
+	text = strings.ReplaceAll(text, "%{", "%%{")
+	return `"` + escapeNativeLiteral(text) + `"`
+}
+
+// escapeNativeLiteral escapes the literal part of a quoted template.
+// Template escapes ($${ and %%{) are left as they are.
+func escapeNativeLiteral(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		switch r {
+		case '\\':
+			b.WriteString(`\\`)
+		case '"':
+			b.WriteString(`\"`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// NativeToJSON converts a native syntax file produced by JSONToNative, and
+// then migrated, back to JSON syntax.
+func NativeToJSON(src []byte, filename string) ([]byte, error) {
+	file, diags := hclsyntax.ParseConfig(src, filename, hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("failed to parse %s: %s", filename, diags.Error())
+	}
+	body, ok := file.Body.(*hclsyntax.Body)
+	if !ok {
+		return nil, fmt.Errorf("failed to parse %s: unexpected body type %T", filename, file.Body)
+	}
+
+	r := &jsonReader{src: src}
+	root := r.body(body, jsonRootSchema)
+
+	var buf bytes.Buffer
+	writeJSON(&buf, root, "")
+	buf.WriteString("\n")
+	return buf.Bytes(), nil
+}
+
+// jsonBlockList is the set of blocks with the same type and labels. It is
+// written as an object when it holds one block, or an array otherwise.
+type jsonBlockList struct {
+	bodies []*jsonValue
+}
+
+type jsonReader struct {
+	src []byte
+}
+
+func (r *jsonReader) text(rng hcl.Range) string {
+	return string(r.src[rng.Start.Byte:rng.End.Byte])
+}
+
+func (r *jsonReader) body(body *hclsyntax.Body, schema *jsonBodySchema) *jsonValue {
+	type item struct {
+		start int
+		attr  *hclsyntax.Attribute
+		block *hclsyntax.Block
+	}
+	var items []item
+	for _, attr := range body.Attributes {
+		items = append(items, item{start: attr.SrcRange.Start.Byte, attr: attr})
+	}
+	for _, block := range body.Blocks {
+		items = append(items, item{start: block.TypeRange.Start.Byte, block: block})
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].start < items[j].start })
+
+	out := &jsonValue{kind: jsonObject}
+	lists := make(map[*jsonValue]*jsonBlockList)
+	for _, it := range items {
+		if it.attr != nil {
+			name := it.attr.Name
+			if name == jsonCommentAttribute {
+				name = "//"
+			}
+			var value *jsonValue
+			if schema.staticRefs[it.attr.Name] {
+				value = r.static(it.attr.Expr)
+			} else {
+				value = r.expr(it.attr.Expr)
+			}
+			out.keys = append(out.keys, name)
+			out.values = append(out.values, value)
+			continue
+		}
+
+		block := it.block
+		parent := out
+		path := append([]string{block.Type}, block.Labels...)
+		for i, key := range path {
+			child := parent.property(key)
+			if child == nil {
+				child = &jsonValue{kind: jsonObject}
+				parent.keys = append(parent.keys, key)
+				parent.values = append(parent.values, child)
+				if i == len(path)-1 {
+					lists[child] = &jsonBlockList{}
+				}
+			}
+			parent = child
+		}
+		if list, ok := lists[parent]; ok {
+			list.bodies = append(list.bodies, r.body(block.Body, schema.blockSchema(block.Type)))
+		}
+	}
+
+	resolveBlockLists(out, lists)
+	return out
+}
+
+// resolveBlockLists replaces each placeholder for a jsonBlockList with an
+// object (one block) or an array of objects.
+func resolveBlockLists(value *jsonValue, lists map[*jsonValue]*jsonBlockList) {
+	if value.kind != jsonObject {
+		return
+	}
+	for i, child := range value.values {
+		list, ok := lists[child]
+		if !ok {
+			resolveBlockLists(child, lists)
+			continue
+		}
+		if len(list.bodies) == 1 {
+			value.values[i] = list.bodies[0]
+		} else {
+			value.values[i] = &jsonValue{kind: jsonArray, items: list.bodies}
+		}
+	}
+}
+
+func (v *jsonValue) property(key string) *jsonValue {
+	for i, k := range v.keys {
+		if k == key {
+			return v.values[i]
+		}
+	}
+	return nil
+}
+
+// static converts bare references back to JSON strings.
+func (r *jsonReader) static(expr hclsyntax.Expression) *jsonValue {
+	switch e := expr.(type) {
+	case *hclsyntax.TupleConsExpr:
+		out := &jsonValue{kind: jsonArray}
+		for _, item := range e.Exprs {
+			out.items = append(out.items, r.static(item))
+		}
+		return out
+	case *hclsyntax.ObjectConsExpr:
+		out := &jsonValue{kind: jsonObject}
+		for _, item := range e.Items {
+			out.keys = append(out.keys, r.objectKey(item.KeyExpr))
+			out.values = append(out.values, r.static(item.ValueExpr))
+		}
+		return out
+	}
+	return &jsonValue{kind: jsonString, text: r.text(expr.Range())}
+}
+
+// expr converts a native expression to its JSON representation. Literals,
+// tuples and objects are converted structurally; templates keep their
+// interpolations; anything else becomes a "${...}" template.
+func (r *jsonReader) expr(expr hclsyntax.Expression) *jsonValue {
+	switch e := expr.(type) {
+	case *hclsyntax.LiteralValueExpr:
+		if value, ok := literalJSON(e.Val); ok {
+			return value
+		}
+	case *hclsyntax.UnaryOpExpr:
+		if lit, ok := e.Val.(*hclsyntax.LiteralValueExpr); ok && e.Op == hclsyntax.OpNegate && lit.Val.Type() == cty.Number {
+			if value, ok := literalJSON(lit.Val.Negate()); ok {
+				return value
+			}
+		}
+	case *hclsyntax.TemplateExpr:
+		if e.IsStringLiteral() {
+			value, _ := e.Parts[0].(*hclsyntax.LiteralValueExpr)
+			return &jsonValue{kind: jsonString, text: value.Val.AsString()}
+		}
+		return &jsonValue{kind: jsonString, text: r.template(e)}
+	case *hclsyntax.TemplateWrapExpr:
+		return &jsonValue{kind: jsonString, text: "${" + r.text(e.Wrapped.Range()) + "}"}
+	case *hclsyntax.ParenthesesExpr:
+		return &jsonValue{kind: jsonString, text: "${" + r.text(e.Expression.Range()) + "}"}
+	case *hclsyntax.TupleConsExpr:
+		out := &jsonValue{kind: jsonArray}
+		for _, item := range e.Exprs {
+			out.items = append(out.items, r.expr(item))
+		}
+		return out
+	case *hclsyntax.ObjectConsExpr:
+		out := &jsonValue{kind: jsonObject}
+		for _, item := range e.Items {
+			out.keys = append(out.keys, r.objectKey(item.KeyExpr))
+			out.values = append(out.values, r.expr(item.ValueExpr))
+		}
+		return out
+	}
+	return &jsonValue{kind: jsonString, text: "${" + r.text(expr.Range()) + "}"}
+}
+
+func (r *jsonReader) objectKey(expr hclsyntax.Expression) string {
+	if key, ok := expr.(*hclsyntax.ObjectConsKeyExpr); ok {
+		if name := hcl.ExprAsKeyword(key.Wrapped); name != "" && !key.ForceNonLiteral {
+			return name
+		}
+		expr = key.Wrapped
+	}
+	value := r.expr(expr)
+	if value.kind == jsonString || value.kind == jsonNumber {
+		return value.text
+	}
+	return r.text(expr.Range())
+}
+
+func literalJSON(val cty.Value) (*jsonValue, bool) {
+	switch {
+	case val.IsNull():
+		return &jsonValue{kind: jsonNull}, true
+	case val.Type() == cty.Bool:
+		return &jsonValue{kind: jsonBool, boolean: val.True()}, true
+	case val.Type() == cty.Number:
+		return &jsonValue{kind: jsonNumber, text: val.AsBigFloat().Text('f', -1)}, true
+	case val.Type() == cty.String:
+		return &jsonValue{kind: jsonString, text: val.AsString()}, true
+	}
+	return nil, false
+}
+
+// template returns the JSON template text for a native template: literal
+// parts are unescaped, interpolations and directives are kept as written.
+func (r *jsonReader) template(expr *hclsyntax.TemplateExpr) string {
+	src := r.text(expr.SrcRange)
+	if strings.HasPrefix(src, "<<") {
+		return r.heredocTemplate(expr)
+	}
+
+	tokens, _ := hclsyntax.LexExpression([]byte(src), "", hcl.InitialPos)
+	var b strings.Builder
+	depth, pos := 0, 0
+	for _, tok := range tokens {
+		start, end := tok.Range.Start.Byte, tok.Range.End.Byte
+		switch {
+		case depth == 0 && (tok.Type == hclsyntax.TokenOQuote || tok.Type == hclsyntax.TokenCQuote || tok.Type == hclsyntax.TokenEOF):
+		case depth == 0 && tok.Type == hclsyntax.TokenQuotedLit:
+			b.WriteString(unescapeNativeLiteral(src[start:end]))
+		default:
+			b.WriteString(src[pos:end])
+		}
+		pos = end
+
+		switch tok.Type {
+		case hclsyntax.TokenTemplateInterp, hclsyntax.TokenTemplateControl:
+			depth++
+		case hclsyntax.TokenTemplateSeqEnd:
+			depth--
+		}
+	}
+	return b.String()
+}
+
+// heredocTemplate returns the JSON template text for a heredoc.
+func (r *jsonReader) heredocTemplate(expr *hclsyntax.TemplateExpr) string {
+	var b strings.Builder
+	for _, part := range expr.Parts {
+		if lit, ok := part.(*hclsyntax.LiteralValueExpr); ok && lit.Val.Type() == cty.String {
+			text := strings.ReplaceAll(lit.Val.AsString(), "${", "$${")
+			b.WriteString(strings.ReplaceAll(text, "%{", "%%{"))
+			continue
+		}
+		b.WriteString("${" + r.text(part.Range()) + "}")
+	}
+	return b.String()
+}
+
+// unescapeNativeLiteral decodes the escape sequences of a quoted template
+// literal. Template escapes ($${ and %%{) are left as they are, since JSON
+// templates use the same ones.
+func unescapeNativeLiteral(text string) string {
+	var b strings.Builder
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		if c != '\\' || i+1 >= len(text) {
+			b.WriteByte(c)
+			continue
+		}
+		i++
+		switch text[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 't':
+			b.WriteByte('\t')
+		case '"':
+			b.WriteByte('"')
+		case '\\':
+			b.WriteByte('\\')
+		case 'u', 'U':
+			size := 4
+			if text[i] == 'U' {
+				size = 8
+			}
+			if i+size < len(text) {
+				if code, err := strconv.ParseUint(text[i+1:i+1+size], 16, 32); err == nil && utf8.ValidRune(rune(code)) {
+					b.WriteRune(rune(code))
+					i += size
+					continue
+				}
+			}
+			b.WriteByte('\\')
+			b.WriteByte(text[i])
+		default:
+			b.WriteByte('\\')
+			b.WriteByte(text[i])
+		}
+	}
+	return b.String()
+}
+
+// writeJSON writes value with two-space indentation.
+func writeJSON(buf *bytes.Buffer, value *jsonValue, indent string) {
+	switch value.kind {
+	case jsonNull:
+		buf.WriteString("null")
+	case jsonBool:
+		buf.WriteString(strconv.FormatBool(value.boolean))
+	case jsonNumber:
+		buf.WriteString(value.text)
+	case jsonString:
+		writeJSONString(buf, value.text)
+	case jsonArray:
+		if len(value.items) == 0 {
+			buf.WriteString("[]")
+			return
+		}
+		buf.WriteString("[\n")
+		for i, item := range value.items {
+			buf.WriteString(indent + "  ")
+			writeJSON(buf, item, indent+"  ")
+			if i < len(value.items)-1 {
+				buf.WriteString(",")
+			}
+			buf.WriteString("\n")
+		}
+		buf.WriteString(indent + "]")
+	case jsonObject:
+		if len(value.keys) == 0 {
+			buf.WriteString("{}")
+			return
+		}
+		buf.WriteString("{\n")
+		for i, key := range value.keys {
+			buf.WriteString(indent + "  ")
+			writeJSONString(buf, key)
+			buf.WriteString(": ")
+			writeJSON(buf, value.values[i], indent+"  ")
+			if i < len(value.keys)-1 {
+				buf.WriteString(",")
+			}
+			buf.WriteString("\n")
+		}
+		buf.WriteString(indent + "}")
+	}
+}
+
+func writeJSONString(buf *bytes.Buffer, text string) {
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(text)
+	// Encode appends a newline.
+	buf.Truncate(buf.Len() - 1)
+}
@@ -0,0 +1,117 @@
+package hcl
+
+import "strings"
+
+// jsonResourceBlocks lists the nested block types of the v4 resources and
+// data sources, by dotted path from the resource body. JSON syntax does not
+// say whether an object is a block or an attribute, so .tf.json files are
+// read with this schema: a property listed here is a block, any other
+// object is an attribute.
+var jsonResourceBlocks = map[string][]string{
+	"cloudflare_access_rule":                              {"configuration"},
+	"cloudflare_api_shield":                               {"auth_id_characteristics"},
+	"cloudflare_api_token":                                {"condition", "condition.request_ip", "policy"},
+	"cloudflare_custom_hostname":                          {"ssl", "ssl.settings"},
+	"cloudflare_custom_ssl":                               {"custom_ssl_options", "custom_ssl_priority"},
+	"cloudflare_healthcheck":                              {"header"},
+	"cloudflare_list":                                     {"item", "item.value", "item.value.hostname", "item.value.redirect"},
+	"cloudflare_load_balancer":                            {"adaptive_routing", "location_strategy", "random_steering", "region_pools", "rules", "rules.fixed_response", "rules.overrides", "rules.overrides.adaptive_routing", "rules.overrides.location_strategy", "rules.overrides.random_steering", "rules.overrides.region_pools", "rules.overrides.session_affinity_attributes", "session_affinity_attributes"},
+	"cloudflare_load_balancer_monitor":                    {"header"},
+	"cloudflare_load_balancer_pool":                       {"load_shedding", "origin_steering", "origins", "origins.header"},
+	"cloudflare_load_balancer_pools":                      {"filter"},
+	"cloudflare_logpush_job":                              {"output_options"},
+	"cloudflare_managed_headers":                          {"managed_request_headers", "managed_response_headers"},
+	"cloudflare_notification_policy":                      {"email_integration", "filters", "pagerduty_integration", "webhooks_integration"},
+	"cloudflare_page_rule":                                {"actions", "actions.cache_key_fields", "actions.cache_key_fields.cookie", "actions.cache_key_fields.host", "actions.cache_key_fields.query_string", "actions.cache_key_fields.user", "actions.cache_ttl_by_status", "actions.forwarding_url", "actions.minify"},
+	"cloudflare_pages_project":                            {"build_config", "deployment_configs", "deployment_configs.preview", "deployment_configs.preview.placement", "deployment_configs.production", "deployment_configs.production.placement", "source", "source.config"},
+	"cloudflare_record":                                   {"data"},
+	"cloudflare_regional_hostname":                        {"timeouts"},
+	"cloudflare_ruleset":                                  {"rules", "rules.action_parameters", "rules.action_parameters.autominify", "rules.action_parameters.cache_key", "rules.action_parameters.cache_key.custom_key", "rules.action_parameters.cache_key.custom_key.query_string", "rules.action_parameters.cache_reserve", "rules.action_parameters.edge_ttl", "rules.action_parameters.edge_ttl.status_code_ttl", "rules.action_parameters.edge_ttl.status_code_ttl.status_code_range", "rules.action_parameters.from_value", "rules.action_parameters.from_value.target_url", "rules.action_parameters.headers", "rules.action_parameters.origin", "rules.action_parameters.overrides", "rules.action_parameters.overrides.rules", "rules.action_parameters.response", "rules.action_parameters.serve_stale", "rules.action_parameters.sni", "rules.action_parameters.uri", "rules.action_parameters.uri.path", "rules.action_parameters.uri.query", "rules.logging"},
+	"cloudflare_rulesets":                                 {"filter"},
+	"cloudflare_snippet":                                  {"files"},
+	"cloudflare_snippet_rules":                            {"rules"},
+	"cloudflare_spectrum_application":                     {"dns", "edge_ips", "origin_dns"},
+	"cloudflare_workers_script":                           {"analytics_engine_binding", "d1_database_binding", "hyperdrive_config_binding", "kv_namespace_binding", "placement", "plain_text_binding", "queue_binding", "r2_bucket_binding", "secret_text_binding", "service_binding", "webassembly_binding"},
+	"cloudflare_zero_trust_access_application":            {"cors_headers", "destinations", "footer_links", "landing_page_design", "saas_app", "saas_app.custom_attribute", "saas_app.custom_attribute.source", "saas_app.custom_claim", "saas_app.custom_claim.source", "saas_app.hybrid_and_implicit_options", "saas_app.refresh_token_options", "scim_config", "scim_config.authentication", "scim_config.mappings", "scim_config.mappings.operations", "target_criteria"},
+	"cloudflare_zero_trust_access_group":                  {"exclude", "exclude.auth_context", "exclude.azure", "exclude.external_evaluation", "exclude.github", "exclude.gsuite", "exclude.okta", "exclude.saml", "include", "include.auth_context", "include.azure", "include.external_evaluation", "include.github", "include.gsuite", "include.okta", "include.saml", "require", "require.auth_context", "require.azure", "require.external_evaluation", "require.github", "require.gsuite", "require.okta", "require.saml"},
+	"cloudflare_zero_trust_access_identity_provider":      {"config", "scim_config"},
+	"cloudflare_zero_trust_access_mtls_hostname_settings": {"settings"},
+	"cloudflare_zero_trust_access_organization":           {"custom_pages", "login_design"},
+	"cloudflare_zero_trust_access_policy":                 {"approval_group", "connection_rules", "connection_rules.ssh", "exclude", "exclude.auth_context", "exclude.azure", "exclude.external_evaluation", "exclude.github", "exclude.gsuite", "exclude.okta", "exclude.saml", "include", "include.auth_context", "include.azure", "include.external_evaluation", "include.github", "include.gsuite", "include.okta", "include.saml", "require", "require.auth_context", "require.azure", "require.external_evaluation", "require.github", "require.gsuite", "require.okta", "require.saml"},
+	"cloudflare_zero_trust_device_managed_networks":       {"config"},
+	"cloudflare_zero_trust_device_posture_integration":    {"config"},
+	"cloudflare_zero_trust_device_posture_rule":           {"input", "input.locations", "match"},
+	"cloudflare_zero_trust_dex_test":                      {"data"},
+	"cloudflare_zero_trust_dlp_profile":                   {"context_awareness", "context_awareness.skip", "entry", "entry.pattern"},
+	"cloudflare_zero_trust_gateway_policy":                {"rule_settings", "rule_settings.audit_ssh", "rule_settings.biso_admin_controls", "rule_settings.check_session", "rule_settings.dns_resolvers", "rule_settings.dns_resolvers.ipv4", "rule_settings.l4override", "rule_settings.notification_settings", "rule_settings.payload_log"},
+	"cloudflare_zero_trust_gateway_settings":              {"antivirus", "antivirus.notification_settings", "block_page", "body_scanning", "certificate", "extended_email_matching", "fips", "logging", "logging.settings_by_rule_type", "logging.settings_by_rule_type.dns", "logging.settings_by_rule_type.http", "logging.settings_by_rule_type.l4", "payload_log", "proxy", "ssh_session_log"},
+	"cloudflare_zero_trust_list":                          {"items_with_description"},
+	"cloudflare_zero_trust_local_fallback_domain":         {"domains"},
+	"cloudflare_zero_trust_split_tunnel":                  {"tunnels"},
+	"cloudflare_zero_trust_tunnel_cloudflared_config":     {"config", "config.ingress_rule", "config.ingress_rule.origin_request", "config.ingress_rule.origin_request.access", "config.ingress_rule.origin_request.ip_rules", "config.origin_request", "config.origin_request.access", "config.origin_request.ip_rules", "config.warp_routing"},
+	"cloudflare_zone_settings_override":                   {"settings", "settings.minify", "settings.mobile_redirect", "settings.nel", "settings.security_header"},
+	"cloudflare_zones":                                    {"filter"},
+}
+
+// jsonResourceAliases maps v4 resource types that were deprecated in favour
+// of another v4 name to the name their blocks are listed under.
+var jsonResourceAliases = map[string]string{
+	"cloudflare_access_application":                  "cloudflare_zero_trust_access_application",
+	"cloudflare_access_group":                        "cloudflare_zero_trust_access_group",
+	"cloudflare_access_identity_provider":            "cloudflare_zero_trust_access_identity_provider",
+	"cloudflare_access_mutual_tls_hostname_settings": "cloudflare_zero_trust_access_mtls_hostname_settings",
+	"cloudflare_access_organization":                 "cloudflare_zero_trust_access_organization",
+	"cloudflare_access_policy":                       "cloudflare_zero_trust_access_policy",
+	"cloudflare_device_dex_test":                     "cloudflare_zero_trust_dex_test",
+	"cloudflare_device_managed_networks":             "cloudflare_zero_trust_device_managed_networks",
+	"cloudflare_device_posture_integration":          "cloudflare_zero_trust_device_posture_integration",
+	"cloudflare_device_posture_rule":                 "cloudflare_zero_trust_device_posture_rule",
+	"cloudflare_dlp_profile":                         "cloudflare_zero_trust_dlp_profile",
+	"cloudflare_fallback_domain":                     "cloudflare_zero_trust_local_fallback_domain",
+	"cloudflare_split_tunnel":                        "cloudflare_zero_trust_split_tunnel",
+	"cloudflare_teams_account":                       "cloudflare_zero_trust_gateway_settings",
+	"cloudflare_teams_list":                          "cloudflare_zero_trust_list",
+	"cloudflare_teams_rule":                          "cloudflare_zero_trust_gateway_policy",
+	"cloudflare_tunnel_config":                       "cloudflare_zero_trust_tunnel_cloudflared_config",
+	"cloudflare_worker_script":                       "cloudflare_workers_script",
+}
+
+// jsonResourceSchemas is built from jsonResourceBlocks.
+var jsonResourceSchemas = buildJSONResourceSchemas()
+
+func buildJSONResourceSchemas() map[string]*jsonBodySchema {
+	schemas := make(map[string]*jsonBodySchema, len(jsonResourceBlocks))
+	for resourceType, paths := range jsonResourceBlocks {
+		root := &jsonBodySchema{
+			blocks:     make(map[string]jsonBlockSchema, len(jsonResourceSchema.blocks)),
+			staticRefs: jsonResourceSchema.staticRefs,
+		}
+		for name, block := range jsonResourceSchema.blocks {
+			root.blocks[name] = block
+		}
+		for _, path := range paths {
+			body := root
+			for _, name := range strings.Split(path, ".") {
+				block, ok := body.blocks[name]
+				if !ok || block.body == jsonNestedBlockSchema {
+					block = jsonBlockSchema{body: &jsonBodySchema{blocks: map[string]jsonBlockSchema{}}}
+					body.blocks[name] = block
+				}
+				body = block.body
+			}
+		}
+		schemas[resourceType] = root
+	}
+	for alias, resourceType := range jsonResourceAliases {
+		schemas[alias] = schemas[resourceType]
+	}
+	return schemas
+}
+
+// jsonResourceSchemaFor returns the body schema of a resource or data source.
+func jsonResourceSchemaFor(resourceType string) *jsonBodySchema {
+	if schema, ok := jsonResourceSchemas[resourceType]; ok {
+		return schema
+	}
+	return jsonResourceSchema
+}
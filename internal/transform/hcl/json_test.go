@@ -0,0 +1,356 @@
+package hcl
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsJSONConfigFile(t *testing.T) {
+	assert.True(t, IsJSONConfigFile("main.tf.json"))
+	assert.True(t, IsJSONConfigFile("modules/dns/records.tf.json"))
+	assert.False(t, IsJSONConfigFile("main.tf"))
+	assert.False(t, IsJSONConfigFile("terraform.tfstate.json"))
+	assert.False(t, IsJSONConfigFile("package.json"))
+}
+
+func TestJSONToNative(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name: "resource with templates and nested block",
+			input: `{
+  "resource": {
+    "cloudflare_record": {
+      "srv": {
+        "zone_id": "${var.zone_id}",
+        "name": "_sip-${var.env}",
+        "ttl": 3600,
+        "proxied": false,
+        "data": {"priority": 10, "target": "sip.example.com"}
+      }
+    }
+  }
+}`,
+			expected: `resource "cloudflare_record" "srv" {
+  zone_id = var.zone_id
+  name    = "_sip-${var.env}"
+  ttl     = 3600
+  proxied = false
+  data {
+    priority = 10
+    target   = "sip.example.com"
+  }
+}
+
+`,
+		},
+		{
+			name: "array of objects is a repeated block",
+			input: `{
+  "resource": {
+    "cloudflare_ruleset": {
+      "r": {
+        "rules": [{"action": "block"}, {"action": "skip"}]
+      }
+    }
+  }
+}`,
+			expected: `resource "cloudflare_ruleset" "r" {
+  rules {
+    action = "block"
+  }
+  rules {
+    action = "skip"
+  }
+}
+
+`,
+		},
+		{
+			name: "objects that are not known blocks are attributes",
+			input: `{
+  "resource": {
+    "cloudflare_api_token": {
+      "t": {
+        "name": "api",
+        "policy": {
+          "permission_groups": ["abc"],
+          "resources": {"com.cloudflare.api.account.*": "*"}
+        },
+        "meta": {"owner": "dns"}
+      }
+    }
+  }
+}`,
+			expected: `resource "cloudflare_api_token" "t" {
+  name = "api"
+  policy {
+    permission_groups = ["abc"]
+    resources = {
+      "com.cloudflare.api.account.*" = "*"
+    }
+  }
+  meta = {
+    owner = "dns"
+  }
+}
+
+`,
+		},
+		{
+			name: "static references, escapes and comments",
+			input: `{
+  "//": "managed by CI",
+  "resource": {
+    "cloudflare_zone": {
+      "z": {
+        "zone": "a\"b\\c $${literal}",
+        "depends_on": ["cloudflare_account.a"],
+        "lifecycle": {"ignore_changes": ["plan"]}
+      }
+    }
+  }
+}`,
+			expected: `tf_migrate_json_comment = "managed by CI"
+resource "cloudflare_zone" "z" {
+  zone       = "a\"b\\c $${literal}"
+  depends_on = [cloudflare_account.a]
+  lifecycle {
+    ignore_changes = [plan]
+  }
+}
+
+`,
+		},
+		{
+			name: "argument-only bodies keep objects as attributes",
+			input: `{
+  "locals": {"tags": {"env": "prod", "team-name": "dns"}},
+  "terraform": {
+    "required_providers": {"cloudflare": {"source": "cloudflare/cloudflare", "version": "~> 4.0"}}
+  }
+}`,
+			expected: `locals {
+  tags = {
+    env       = "prod"
+    team-name = "dns"
+  }
+}
+
+terraform {
+  required_providers {
+    cloudflare = {
+      source  = "cloudflare/cloudflare"
+      version = "~> 4.0"
+    }
+  }
+}
+
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := JSONToNative([]byte(tt.input), "main.tf.json")
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, string(got))
+		})
+	}
+}
+
+func TestJSONToNativeErrors(t *testing.T) {
+	_, err := JSONToNative([]byte(`{"resource": `), "main.tf.json")
+	assert.Error(t, err)
+
+	_, err = JSONToNative([]byte(`["resource"]`), "main.tf.json")
+	assert.ErrorContains(t, err, "must be an object")
+
+	// Blocks of an unexpected shape fail rather than being left out
+	_, err = JSONToNative([]byte(`{"resource": {"cloudflare_record": "www"}}`), "main.tf.json")
+	assert.ErrorContains(t, err, "resource.cloudflare_record: expected an object or an array of objects")
+
+	_, err = JSONToNative([]byte(`{"resource": [[{"cloudflare_record": {}}]]}`), "main.tf.json")
+	assert.ErrorContains(t, err, "resource: unexpected nested array")
+}
+
+func TestNativeToJSON(t *testing.T) {
+	input := `tf_migrate_json_comment = "managed by CI"
+resource "cloudflare_dns_record" "www" {
+  zone_id = var.zone_id
+  name    = "www-${var.env}\n"
+  ttl     = -1
+  content = upper("a")
+  tags    = ["a", local.tag]
+  data = {
+    priority = 10
+  }
+  depends_on = [cloudflare_zone.z]
+}
+
+moved {
+  from = cloudflare_record.www
+  to   = cloudflare_dns_record.www
+}
+
+moved {
+  from = cloudflare_record.mx
+  to   = cloudflare_dns_record.mx
+}
+`
+	expected := `{
+  "//": "managed by CI",
+  "resource": {
+    "cloudflare_dns_record": {
+      "www": {
+        "zone_id": "${var.zone_id}",
+        "name": "www-${var.env}\n",
+        "ttl": -1,
+        "content": "${upper(\"a\")}",
+        "tags": [
+          "a",
+          "${local.tag}"
+        ],
+        "data": {
+          "priority": 10
+        },
+        "depends_on": [
+          "cloudflare_zone.z"
+        ]
+      }
+    }
+  },
+  "moved": [
+    {
+      "from": "cloudflare_record.www",
+      "to": "cloudflare_dns_record.www"
+    },
+    {
+      "from": "cloudflare_record.mx",
+      "to": "cloudflare_dns_record.mx"
+    }
+  ]
+}
+`
+	got, err := NativeToJSON([]byte(input), "main.tf.json")
+	require.NoError(t, err)
+	assert.Equal(t, expected, string(got))
+	assert.True(t, json.Valid(got))
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	input := `{
+  "//": "round trip",
+  "resource": {
+    "cloudflare_record": {
+      "a": {
+        "name": "a \"quoted\" ${var.x} $${escaped} %{ if var.y }y%{ endif }",
+        "value": "${lookup(var.m, \"k\", \"d\")}",
+        "ttl": 1.5,
+        "data": [{"flags": 0}, {"flags": 1}],
+        "lifecycle": {"ignore_changes": ["ttl"]}
+      },
+      "b": {"name": "é\t\n"}
+    }
+  },
+  "output": {
+    "o": {"value": {"k": "${cloudflare_record.a.id}"}}
+  }
+}
+`
+	native, err := JSONToNative([]byte(input), "main.tf.json")
+	require.NoError(t, err)
+	got, err := NativeToJSON(native, "main.tf.json")
+	require.NoError(t, err)
+
+	var want, have interface{}
+	require.NoError(t, json.Unmarshal([]byte(input), &want))
+	require.NoError(t, json.Unmarshal(got, &have))
+	// hclwrite.Format normalises the spacing inside template directives.
+	want.(map[string]interface{})["resource"].(map[string]interface{})["cloudflare_record"].(map[string]interface{})["a"].(map[string]interface{})["name"] =
+		`a "quoted" ${var.x} $${escaped} %{if var.y}y%{endif}`
+	assert.Equal(t, want, have)
+}
+
+func TestJSONRoundTrip_ArrayForms(t *testing.T) {
+	// JSON syntax allows an array of objects at every label level.
+	input := `{
+  "provider": {"cloudflare": [{"rps": 10}, {"alias": "secondary"}]},
+  "resource": [
+    {"cloudflare_record": {"a": {"name": "a"}}},
+    {"cloudflare_record": [{"b": {"name": "b"}}, {"c": [{"name": "c"}]}]},
+    {"cloudflare_zone": {"z": {"zone": "example.com"}}}
+  ]
+}
+`
+	native, err := JSONToNative([]byte(input), "main.tf.json")
+	require.NoError(t, err)
+	assert.Equal(t, `provider "cloudflare" {
+  rps = 10
+}
+
+provider "cloudflare" {
+  alias = "secondary"
+}
+
+resource "cloudflare_record" "a" {
+  name = "a"
+}
+
+resource "cloudflare_record" "b" {
+  name = "b"
+}
+
+resource "cloudflare_record" "c" {
+  name = "c"
+}
+
+resource "cloudflare_zone" "z" {
+  zone = "example.com"
+}
+
+`, string(native))
+
+	got, err := NativeToJSON(native, "main.tf.json")
+	require.NoError(t, err)
+	var have interface{}
+	require.NoError(t, json.Unmarshal(got, &have))
+	assert.Equal(t, map[string]interface{}{
+		"provider": map[string]interface{}{
+			"cloudflare": []interface{}{
+				map[string]interface{}{"rps": float64(10)},
+				map[string]interface{}{"alias": "secondary"},
+			},
+		},
+		"resource": map[string]interface{}{
+			"cloudflare_record": map[string]interface{}{
+				"a": map[string]interface{}{"name": "a"},
+				"b": map[string]interface{}{"name": "b"},
+				"c": map[string]interface{}{"name": "c"},
+			},
+			"cloudflare_zone": map[string]interface{}{
+				"z": map[string]interface{}{"zone": "example.com"},
+			},
+		},
+	}, have)
+}
+
+func TestParseConfigFile(t *testing.T) {
+	file, diags := ParseConfigFile([]byte(`{"resource": {"cloudflare_zone": {"z": {"zone": "example.com"}}}}`), "main.tf.json")
+	require.False(t, diags.HasErrors(), diags.Error())
+	require.Len(t, file.Body().Blocks(), 1)
+	assert.Equal(t, []string{"cloudflare_zone", "z"}, file.Body().Blocks()[0].Labels())
+
+	_, diags = ParseConfigFile([]byte(`{`), "main.tf.json")
+	assert.True(t, diags.HasErrors())
+
+	file, diags = ParseConfigFile([]byte(`resource "cloudflare_zone" "z" {}`), "main.tf")
+	require.False(t, diags.HasErrors(), diags.Error())
+	assert.Len(t, file.Body().Blocks(), 1)
+}
@@ -0,0 +1,297 @@
+package hcl
+
+import (
+	"sort"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// Reference is a reference to a resource or datasource instance found in an
+// expression, e.g. cloudflare_record.example["a"].hostname.
+type Reference struct {
+	// Type is the resource type, prefixed with "data." for datasources.
+	Type string
+	// Name is the resource name.
+	Name string
+	// Attribute is the first attribute accessed on the instance, after any
+	// index key or splat. It is empty when the instance itself is referenced.
+	Attribute string
+
+	// TypeRange covers Type in the source, including the "data." prefix.
+	TypeRange hcl.Range
+	// AttributeRange covers Attribute in the source, without the leading dot.
+	AttributeRange hcl.Range
+}
+
+// FindReferences parses content as native HCL syntax and returns every
+// reference to a resource or datasource instance in its expressions, in source
+// order. References inside string literals, heredoc text and comments are not
+// references and are never returned. Top-level blocks whose type is in
+// skipBlockTypes (e.g. "moved", "removed") are not searched.
+//
+// The following forms are recognised:
+//
+//	cloudflare_record.example.hostname
+//	cloudflare_record.example["key"].hostname
+//	cloudflare_record.example[count.index].hostname
+//	cloudflare_record.example[*].hostname
+//	cloudflare_record.example.*.hostname
+//	data.cloudflare_zones.example.zones[0].id
+func FindReferences(content []byte, filename string, skipBlockTypes ...string) ([]Reference, hcl.Diagnostics) {
+	file, diags := hclsyntax.ParseConfig(content, filename, hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	body, ok := file.Body.(*hclsyntax.Body)
+	if !ok {
+		return nil, nil
+	}
+	return FindBodyReferences(body, skipBlockTypes...), nil
+}
+
+// FindBodyReferences is FindReferences for a body that is already parsed, so
+// that callers which need more from the syntax tree parse a file only once.
+func FindBodyReferences(body *hclsyntax.Body, skipBlockTypes ...string) []Reference {
+	skip := make(map[string]bool, len(skipBlockTypes))
+	for _, blockType := range skipBlockTypes {
+		skip[blockType] = true
+	}
+
+	finder := &referenceFinder{}
+	for _, attr := range body.Attributes {
+		hclsyntax.Walk(attr, finder)
+	}
+	for _, block := range body.Blocks {
+		if skip[block.Type] {
+			continue
+		}
+		hclsyntax.Walk(block, finder)
+	}
+
+	sort.Slice(finder.refs, func(i, j int) bool {
+		return finder.refs[i].TypeRange.Start.Byte < finder.refs[j].TypeRange.Start.Byte
+	})
+	return finder.refs
+}
+
+// referenceFinder is an hclsyntax.Walker that records references. It keeps the
+// stack of enclosing nodes so that an attribute applied to an index or splat
+// expression can be attributed to the instance it is applied to.
+type referenceFinder struct {
+	stack []hclsyntax.Node
+	refs  []Reference
+}
+
+func (f *referenceFinder) Enter(node hclsyntax.Node) hcl.Diagnostics {
+	if expr, ok := node.(*hclsyntax.ScopeTraversalExpr); ok {
+		if ref, ok := f.reference(expr); ok {
+			f.refs = append(f.refs, ref)
+		}
+	}
+	f.stack = append(f.stack, node)
+	return nil
+}
+
+func (f *referenceFinder) Exit(node hclsyntax.Node) hcl.Diagnostics {
+	f.stack = f.stack[:len(f.stack)-1]
+	return nil
+}
+
+// reference returns the Reference rooted at expr, if expr refers to a
+// resource or datasource instance.
+func (f *referenceFinder) reference(expr *hclsyntax.ScopeTraversalExpr) (Reference, bool) {
+	traversal := expr.Traversal
+	if len(traversal) < 2 {
+		return Reference{}, false
+	}
+
+	root, ok := traversal[0].(hcl.TraverseRoot)
+	if !ok {
+		return Reference{}, false
+	}
+
+	// Resource references have the form <type>.<name>, datasources
+	// data.<type>.<name>. Anything else rooted at a built-in name is not a
+	// resource reference.
+	typeName := root.Name
+	nameIndex := 1
+	switch root.Name {
+	case "data":
+		if len(traversal) < 3 {
+			return Reference{}, false
+		}
+		typeStep, ok := traversal[1].(hcl.TraverseAttr)
+		if !ok {
+			return Reference{}, false
+		}
+		typeName = "data." + typeStep.Name
+		nameIndex = 2
+	case "var", "local", "module", "count", "each", "path", "terraform", "self":
+		return Reference{}, false
+	}
+
+	nameStep, ok := traversal[nameIndex].(hcl.TraverseAttr)
+	if !ok {
+		return Reference{}, false
+	}
+
+	ref := Reference{
+		Type: typeName,
+		Name: nameStep.Name,
+		TypeRange: hcl.Range{
+			Filename: root.SrcRange.Filename,
+			Start:    root.SrcRange.Start,
+			End:      traversal[nameIndex-1].SourceRange().End,
+		},
+	}
+
+	if attr, ok := attributeAfterInstance(traversal[nameIndex+1:]); ok {
+		ref.setAttribute(attr)
+	} else if len(traversal) == nameIndex+1 {
+		if attr, ok := f.parentAttribute(expr); ok {
+			ref.setAttribute(attr)
+		}
+	}
+
+	return ref, true
+}
+
+func (r *Reference) setAttribute(attr hcl.TraverseAttr) {
+	r.Attribute = attr.Name
+	// The step's range includes the leading dot.
+	end := attr.SrcRange.End
+	start := end
+	start.Byte -= len(attr.Name)
+	start.Column -= len(attr.Name)
+	r.AttributeRange = hcl.Range{Filename: attr.SrcRange.Filename, Start: start, End: end}
+}
+
+// attributeAfterInstance returns the first attribute in the steps following
+// the instance name, skipping a single literal index key such as ["a"] or
+// the legacy .0 form.
+func attributeAfterInstance(steps hcl.Traversal) (hcl.TraverseAttr, bool) {
+	if len(steps) == 0 {
+		return hcl.TraverseAttr{}, false
+	}
+	if _, ok := steps[0].(hcl.TraverseIndex); ok {
+		steps = steps[1:]
+	}
+	if len(steps) == 0 {
+		return hcl.TraverseAttr{}, false
+	}
+	attr, ok := steps[0].(hcl.TraverseAttr)
+	return attr, ok
+}
+
+// parentAttribute returns the attribute applied to expr by its enclosing
+// expressions, for instances indexed by a non-literal key
+// (cloudflare_record.x[count.index].hostname) or splatted
+// (cloudflare_record.x[*].hostname).
+func (f *referenceFinder) parentAttribute(expr hclsyntax.Expression) (hcl.TraverseAttr, bool) {
+	if len(f.stack) == 0 {
+		return hcl.TraverseAttr{}, false
+	}
+
+	switch parent := f.stack[len(f.stack)-1].(type) {
+	case *hclsyntax.IndexExpr:
+		if parent.Collection != expr || len(f.stack) < 2 {
+			return hcl.TraverseAttr{}, false
+		}
+		outer, ok := f.stack[len(f.stack)-2].(*hclsyntax.RelativeTraversalExpr)
+		if !ok || outer.Source != hclsyntax.Expression(parent) || len(outer.Traversal) == 0 {
+			return hcl.TraverseAttr{}, false
+		}
+		attr, ok := outer.Traversal[0].(hcl.TraverseAttr)
+		return attr, ok
+	case *hclsyntax.SplatExpr:
+		if parent.Source != expr {
+			return hcl.TraverseAttr{}, false
+		}
+		each, ok := parent.Each.(*hclsyntax.RelativeTraversalExpr)
+		if !ok || len(each.Traversal) == 0 {
+			return hcl.TraverseAttr{}, false
+		}
+		attr, ok := each.Traversal[0].(hcl.TraverseAttr)
+		return attr, ok
+	}
+	return hcl.TraverseAttr{}, false
+}
+
+// TextEdit replaces the bytes covered by Range with Text.
+type TextEdit struct {
+	Range hcl.Range
+	Text  string
+}
+
+// ApplyEdits returns content with edits applied. Edits must not overlap;
+// they may be given in any order.
+func ApplyEdits(content []byte, edits []TextEdit) []byte {
+	if len(edits) == 0 {
+		return content
+	}
+
+	sorted := make([]TextEdit, len(edits))
+	copy(sorted, edits)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Range.Start.Byte < sorted[j].Range.Start.Byte
+	})
+
+	out := make([]byte, 0, len(content))
+	last := 0
+	for _, edit := range sorted {
+		out = append(out, content[last:edit.Range.Start.Byte]...)
+		out = append(out, edit.Text...)
+		last = edit.Range.End.Byte
+	}
+	return append(out, content[last:]...)
+}
+
+// ModuleOutputReference is a reference to an output of a module call, e.g.
+// module.dns.record_hostname or module.dns["a"].record_hostname.
+type ModuleOutputReference struct {
+	// Module is the name of the module block.
+	Module string
+	// Output is the name of the output. It is empty when the module call
+	// itself is referenced.
+	Output string
+	// Range covers the whole traversal.
+	Range hcl.Range
+}
+
+// FindModuleOutputReferences parses content as native HCL syntax and returns
+// every reference to a module output in its expressions, in source order.
+func FindModuleOutputReferences(content []byte, filename string) ([]ModuleOutputReference, hcl.Diagnostics) {
+	file, diags := hclsyntax.ParseConfig(content, filename, hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	body, ok := file.Body.(*hclsyntax.Body)
+	if !ok {
+		return nil, nil
+	}
+
+	var refs []ModuleOutputReference
+	hclsyntax.VisitAll(body, func(node hclsyntax.Node) hcl.Diagnostics {
+		expr, ok := node.(*hclsyntax.ScopeTraversalExpr)
+		if !ok || len(expr.Traversal) < 2 || expr.Traversal.RootName() != "module" {
+			return nil
+		}
+		moduleStep, ok := expr.Traversal[1].(hcl.TraverseAttr)
+		if !ok {
+			return nil
+		}
+
+		ref := ModuleOutputReference{Module: moduleStep.Name, Range: expr.SrcRange}
+		if output, ok := attributeAfterInstance(expr.Traversal[2:]); ok {
+			ref.Output = output.Name
+		}
+		refs = append(refs, ref)
+		return nil
+	})
+
+	sort.Slice(refs, func(i, j int) bool {
+		return refs[i].Range.Start.Byte < refs[j].Range.Start.Byte
+	})
+	return refs, nil
+}
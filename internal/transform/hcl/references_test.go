@@ -0,0 +1,103 @@
+package hcl
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindReferences(t *testing.T) {
+	input := `resource "cloudflare_dns_record" "a" {
+  content = cloudflare_record.plain.hostname
+  name    = cloudflare_record.keyed["a"].hostname
+  ttl     = length(cloudflare_record.splat[*].hostname)
+  comment = "cloudflare_record.literal.hostname ${cloudflare_record.interp.id}"
+  zone_id = data.cloudflare_zones.z.zones[0].id
+  tags    = [var.tag, local.tag, each.value, count.index]
+  proxied = cloudflare_record.counted[count.index].proxied
+}
+
+moved {
+  from = cloudflare_record.skipped
+  to   = cloudflare_dns_record.skipped
+}
+
+output "o" {
+  value = cloudflare_record.legacy.0.hostname
+}
+`
+	refs, diags := FindReferences([]byte(input), "main.tf", "moved")
+	require.False(t, diags.HasErrors(), diags.Error())
+
+	type found struct{ Type, Name, Attribute string }
+	var got []found
+	for _, ref := range refs {
+		got = append(got, found{ref.Type, ref.Name, ref.Attribute})
+		assert.Equal(t, ref.Type, sourceText(input, ref.TypeRange))
+		if ref.Attribute != "" {
+			assert.Equal(t, ref.Attribute, sourceText(input, ref.AttributeRange))
+		}
+	}
+
+	assert.Equal(t, []found{
+		{"cloudflare_record", "plain", "hostname"},
+		{"cloudflare_record", "keyed", "hostname"},
+		{"cloudflare_record", "splat", "hostname"},
+		{"cloudflare_record", "interp", "id"},
+		{"data.cloudflare_zones", "z", "zones"},
+		{"cloudflare_record", "counted", "proxied"},
+		{"cloudflare_record", "legacy", "hostname"},
+	}, got)
+}
+
+func TestFindReferencesParseError(t *testing.T) {
+	_, diags := FindReferences([]byte(`a = {`), "main.tf")
+	assert.True(t, diags.HasErrors())
+}
+
+func TestApplyEdits(t *testing.T) {
+	content := []byte("abc def ghi")
+	edits := []TextEdit{
+		{Range: byteRange(8, 11), Text: "GHI!"},
+		{Range: byteRange(0, 3), Text: "A"},
+	}
+	assert.Equal(t, "A def GHI!", string(ApplyEdits(content, edits)))
+	assert.Equal(t, "abc def ghi", string(ApplyEdits(content, nil)))
+}
+
+func sourceText(content string, rng hcl.Range) string {
+	return content[rng.Start.Byte:rng.End.Byte]
+}
+
+func byteRange(start, end int) hcl.Range {
+	return hcl.Range{Start: hcl.Pos{Byte: start}, End: hcl.Pos{Byte: end}}
+}
+
+func TestFindModuleOutputReferences(t *testing.T) {
+	input := `output "hostname" {
+  value = module.dns.record_hostname
+}
+
+locals {
+  ids   = module.zones["a"].zone_id
+  whole = module.dns
+  other = cloudflare_record.x.hostname
+}
+`
+	refs, diags := FindModuleOutputReferences([]byte(input), "main.tf")
+	require.False(t, diags.HasErrors(), diags.Error())
+
+	type found struct{ Module, Output string }
+	var got []found
+	for _, ref := range refs {
+		got = append(got, found{ref.Module, ref.Output})
+	}
+	assert.Equal(t, []found{
+		{"dns", "record_hostname"},
+		{"zones", "zone_id"},
+		{"dns", ""},
+	}, got)
+	assert.Equal(t, "module.dns.record_hostname", sourceText(input, refs[0].Range))
+}
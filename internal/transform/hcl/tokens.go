@@ -2,6 +2,8 @@
 package hcl
 
 import (
+	"strings"
+
 	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/hashicorp/hcl/v2/hclwrite"
 	"github.com/zclconf/go-cty/cty"
@@ -39,6 +41,19 @@ func BuildResourceReference(resourceType, resourceName string) hclwrite.Tokens {
 	}
 }
 
+// TraversalString returns the source of a traversal attribute without
+// whitespace, e.g. "cloudflare_access_application.admin_api" for
+// `from = cloudflare_access_application.admin_api`.
+func TraversalString(attr *hclwrite.Attribute) string {
+	var parts []string
+	for _, tok := range attr.Expr().BuildTokens(nil) {
+		if s := strings.TrimSpace(string(tok.Bytes)); s != "" {
+			parts = append(parts, s)
+		}
+	}
+	return strings.Join(parts, "")
+}
+
 // TokensForSimpleValue creates tokens for a simple value (string, number, bool)
 // This is a low-level utility for creating HCL tokens from Go primitive types
 func TokensForSimpleValue(val interface{}) hclwrite.Tokens {
@@ -76,11 +91,21 @@ func TokensForEmptyArray() hclwrite.Tokens {
 // Result in HCL:
 //
 //	# MIGRATION WARNING: Cannot determine list kind for merging list_item resources
+//
+// The comment is not added again if the body already contains it, so that
+// re-running a migration does not stack duplicate warnings.
 func AppendWarningComment(body *hclwrite.Body, message string) {
+	text := "# MIGRATION WARNING: " + message
+	for _, tok := range body.BuildTokens(nil) {
+		if tok.Type == hclsyntax.TokenComment && strings.TrimSpace(string(tok.Bytes)) == text {
+			return
+		}
+	}
+
 	comment := hclwrite.Tokens{
 		&hclwrite.Token{
 			Type:  hclsyntax.TokenComment,
-			Bytes: []byte("# MIGRATION WARNING: " + message + "\n"),
+			Bytes: []byte(text + "\n"),
 		},
 	}
 	body.AppendUnstructuredTokens(comment)
@@ -4,6 +4,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/hashicorp/hcl/v2/hclwrite"
 	"github.com/stretchr/testify/assert"
@@ -588,3 +589,31 @@ func TestAppendWarningComment_PreservesFormatting(t *testing.T) {
 	assert.Contains(t, result, `"value3"`)
 	assert.Contains(t, result, "# MIGRATION WARNING: Manual review required")
 }
+
+func TestAppendWarningComment_Idempotent(t *testing.T) {
+	file := hclwrite.NewEmptyFile()
+	body := file.Body()
+	body.SetAttributeRaw("test", TokensForSimpleValue("value"))
+
+	AppendWarningComment(body, "Needs manual review")
+	AppendWarningComment(body, "Needs manual review")
+	AppendWarningComment(body, "Something else")
+
+	result := string(file.Bytes())
+	assert.Equal(t, 1, strings.Count(result, "# MIGRATION WARNING: Needs manual review"))
+	assert.Equal(t, 1, strings.Count(result, "# MIGRATION WARNING: Something else"))
+
+	// A warning already present in parsed config is detected as well
+	parsed, diags := hclwrite.ParseConfig(file.Bytes(), "test.tf", hcl.Pos{Line: 1, Column: 1})
+	assert.False(t, diags.HasErrors())
+	AppendWarningComment(parsed.Body(), "Needs manual review")
+	assert.Equal(t, 1, strings.Count(string(parsed.Bytes()), "# MIGRATION WARNING: Needs manual review"))
+}
+
+func TestTraversalString(t *testing.T) {
+	file, diags := hclwrite.ParseConfig([]byte("moved {\n  from = cloudflare_record . www[\"a\"]\n  to   = module.dns.cloudflare_dns_record.www\n}\n"), "main.tf", hcl.InitialPos)
+	assert.False(t, diags.HasErrors())
+	body := file.Body().Blocks()[0].Body()
+	assert.Equal(t, `cloudflare_record.www["a"]`, TraversalString(body.GetAttribute("from")))
+	assert.Equal(t, "module.dns.cloudflare_dns_record.www", TraversalString(body.GetAttribute("to")))
+}
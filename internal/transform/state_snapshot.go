@@ -0,0 +1,159 @@
+package transform
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/tidwall/gjson"
+)
+
+// StateSnapshot holds the managed resource instances of a local copy of the
+// v4 state, so that migrators can read values the configuration does not
+// spell out. It is read from a file and never refreshed: migrations that use
+// it stay fully offline.
+//
+// Two formats are accepted:
+//   - the output of `terraform show -json` (or `tofu show -json`), including
+//     the prior_state of a JSON plan
+//   - the raw state written by `terraform state pull`
+type StateSnapshot struct {
+	instances []StateInstance
+}
+
+// StateInstance is a single instance of a managed resource in a state snapshot.
+type StateInstance struct {
+	Module     string // module address, e.g. "module.dns"; empty for the root module
+	Type       string // resource type as recorded in state, e.g. "cloudflare_record"
+	Name       string
+	IndexKey   gjson.Result // count index or for_each key; does not exist for single instances
+	Attributes gjson.Result
+}
+
+// LoadStateSnapshot reads a state snapshot from path.
+func LoadStateSnapshot(path string) (*StateSnapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state snapshot: %w", err)
+	}
+	snapshot, err := ParseStateSnapshot(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse state snapshot %s: %w", path, err)
+	}
+	return snapshot, nil
+}
+
+// ParseStateSnapshot parses a state snapshot in either supported format.
+func ParseStateSnapshot(data []byte) (*StateSnapshot, error) {
+	if !gjson.ValidBytes(data) {
+		return nil, fmt.Errorf("not valid JSON")
+	}
+	root := gjson.ParseBytes(data)
+	if !root.IsObject() {
+		return nil, fmt.Errorf("expected a JSON object")
+	}
+
+	snapshot := &StateSnapshot{}
+	switch {
+	case root.Get("values").Exists():
+		snapshot.addShowModule(root.Get("values.root_module"))
+	case root.Get("prior_state").Exists():
+		snapshot.addShowModule(root.Get("prior_state.values.root_module"))
+	case root.Get("resources").Exists():
+		snapshot.addRawResources(root.Get("resources"))
+	case root.Get("format_version").Exists():
+		// terraform show -json of an empty state has no values at all.
+	default:
+		return nil, fmt.Errorf("expected the output of `terraform show -json` or `terraform state pull`")
+	}
+	return snapshot, nil
+}
+
+// addShowModule adds the managed resources of a module in the
+// `terraform show -json` format, and of its child modules.
+func (s *StateSnapshot) addShowModule(module gjson.Result) {
+	address := module.Get("address").String()
+	for _, resource := range module.Get("resources").Array() {
+		if resource.Get("mode").String() != "managed" {
+			continue
+		}
+		s.instances = append(s.instances, StateInstance{
+			Module:     address,
+			Type:       resource.Get("type").String(),
+			Name:       resource.Get("name").String(),
+			IndexKey:   resource.Get("index"),
+			Attributes: resource.Get("values"),
+		})
+	}
+	for _, child := range module.Get("child_modules").Array() {
+		s.addShowModule(child)
+	}
+}
+
+// addRawResources adds the managed resources of a raw state file.
+func (s *StateSnapshot) addRawResources(resources gjson.Result) {
+	for _, resource := range resources.Array() {
+		if resource.Get("mode").String() != "managed" {
+			continue
+		}
+		for _, instance := range resource.Get("instances").Array() {
+			s.instances = append(s.instances, StateInstance{
+				Module:     resource.Get("module").String(),
+				Type:       resource.Get("type").String(),
+				Name:       resource.Get("name").String(),
+				IndexKey:   instance.Get("index_key"),
+				Attributes: instance.Get("attributes"),
+			})
+		}
+	}
+}
+
+// Len returns the number of resource instances in the snapshot.
+func (s *StateSnapshot) Len() int {
+	if s == nil {
+		return 0
+	}
+	return len(s.instances)
+}
+
+// Instances returns the instances of the resource with the given type and
+// name. A configuration file does not know which module address it is
+// instantiated at, so instances from every module are returned.
+func (s *StateSnapshot) Instances(resourceType, name string) []StateInstance {
+	if s == nil {
+		return nil
+	}
+	var found []StateInstance
+	for _, instance := range s.instances {
+		if instance.Type == resourceType && instance.Name == name {
+			found = append(found, instance)
+		}
+	}
+	return found
+}
+
+// StateAttribute returns the value of an attribute of a resource in the state
+// snapshot. The value is only returned when every instance of the resource
+// (count or for_each instances, or the same module called more than once)
+// holds the same value, because it is written into configuration shared by
+// all of them. It reports false when no snapshot was given, the resource is
+// not in it, or the instances disagree.
+func (ctx *Context) StateAttribute(resourceType, name, attribute string) (gjson.Result, bool) {
+	if ctx.State == nil {
+		return gjson.Result{}, false
+	}
+	instances := ctx.State.Instances(resourceType, name)
+	if len(instances) == 0 {
+		return gjson.Result{}, false
+	}
+
+	value := instances[0].Attributes.Get(attribute)
+	if !value.Exists() || value.Type == gjson.Null {
+		return gjson.Result{}, false
+	}
+	for _, instance := range instances[1:] {
+		if other := instance.Attributes.Get(attribute); other.Raw != value.Raw {
+			return gjson.Result{}, false
+		}
+	}
+	return value, true
+}
@@ -0,0 +1,151 @@
+package transform
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const showJSONSnapshot = `{
+  "format_version": "1.0",
+  "terraform_version": "1.9.0",
+  "values": {
+    "root_module": {
+      "resources": [
+        {
+          "address": "cloudflare_record.www",
+          "mode": "managed",
+          "type": "cloudflare_record",
+          "name": "www",
+          "values": {"id": "rec1", "type": "A"}
+        },
+        {
+          "address": "data.cloudflare_zone.z",
+          "mode": "data",
+          "type": "cloudflare_zone",
+          "name": "z",
+          "values": {"id": "zone"}
+        }
+      ],
+      "child_modules": [
+        {
+          "address": "module.dns",
+          "resources": [
+            {
+              "address": "module.dns.cloudflare_record.www",
+              "mode": "managed",
+              "type": "cloudflare_record",
+              "name": "www",
+              "values": {"id": "rec2", "type": "A"}
+            }
+          ]
+        }
+      ]
+    }
+  }
+}`
+
+const rawStateSnapshot = `{
+  "version": 4,
+  "terraform_version": "1.9.0",
+  "resources": [
+    {
+      "mode": "managed",
+      "type": "cloudflare_record",
+      "name": "mx",
+      "instances": [
+        {"index_key": "a", "attributes": {"id": "rec1", "type": "MX", "priority": 10}},
+        {"index_key": "b", "attributes": {"id": "rec2", "type": "MX", "priority": 20}}
+      ]
+    },
+    {
+      "mode": "data",
+      "type": "cloudflare_zone",
+      "name": "z",
+      "instances": [{"attributes": {"id": "zone"}}]
+    }
+  ]
+}`
+
+func TestParseStateSnapshot(t *testing.T) {
+	t.Run("terraform show -json", func(t *testing.T) {
+		snapshot, err := ParseStateSnapshot([]byte(showJSONSnapshot))
+		require.NoError(t, err)
+		assert.Equal(t, 2, snapshot.Len())
+
+		instances := snapshot.Instances("cloudflare_record", "www")
+		require.Len(t, instances, 2)
+		assert.Equal(t, "", instances[0].Module)
+		assert.Equal(t, "module.dns", instances[1].Module)
+		assert.Equal(t, "rec2", instances[1].Attributes.Get("id").String())
+		assert.Empty(t, snapshot.Instances("cloudflare_zone", "z"))
+	})
+
+	t.Run("prior state of a JSON plan", func(t *testing.T) {
+		snapshot, err := ParseStateSnapshot([]byte(`{"format_version": "1.2", "prior_state": ` + showJSONSnapshot + `}`))
+		require.NoError(t, err)
+		assert.Equal(t, 2, snapshot.Len())
+	})
+
+	t.Run("terraform state pull", func(t *testing.T) {
+		snapshot, err := ParseStateSnapshot([]byte(rawStateSnapshot))
+		require.NoError(t, err)
+
+		instances := snapshot.Instances("cloudflare_record", "mx")
+		require.Len(t, instances, 2)
+		assert.Equal(t, "b", instances[1].IndexKey.String())
+		assert.Equal(t, int64(20), instances[1].Attributes.Get("priority").Int())
+		assert.Empty(t, snapshot.Instances("cloudflare_zone", "z"))
+	})
+
+	t.Run("empty state", func(t *testing.T) {
+		snapshot, err := ParseStateSnapshot([]byte(`{"format_version": "1.0"}`))
+		require.NoError(t, err)
+		assert.Equal(t, 0, snapshot.Len())
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		_, err := ParseStateSnapshot([]byte(`{`))
+		assert.ErrorContains(t, err, "not valid JSON")
+
+		_, err = ParseStateSnapshot([]byte(`{"foo": "bar"}`))
+		assert.ErrorContains(t, err, "terraform show -json")
+	})
+}
+
+func TestLoadStateSnapshot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	require.NoError(t, os.WriteFile(path, []byte(rawStateSnapshot), 0644))
+
+	snapshot, err := LoadStateSnapshot(path)
+	require.NoError(t, err)
+	assert.Equal(t, 2, snapshot.Len())
+
+	_, err = LoadStateSnapshot(filepath.Join(t.TempDir(), "missing.json"))
+	assert.ErrorContains(t, err, "failed to read state snapshot")
+}
+
+func TestContextStateAttribute(t *testing.T) {
+	snapshot, err := ParseStateSnapshot([]byte(rawStateSnapshot))
+	require.NoError(t, err)
+	ctx := &Context{State: snapshot}
+
+	value, ok := ctx.StateAttribute("cloudflare_record", "mx", "type")
+	require.True(t, ok)
+	assert.Equal(t, "MX", value.String())
+
+	_, ok = ctx.StateAttribute("cloudflare_record", "mx", "priority")
+	assert.False(t, ok, "instances disagree")
+
+	_, ok = ctx.StateAttribute("cloudflare_record", "mx", "proxied")
+	assert.False(t, ok, "attribute not in state")
+
+	_, ok = ctx.StateAttribute("cloudflare_record", "other", "type")
+	assert.False(t, ok, "resource not in state")
+
+	_, ok = (&Context{}).StateAttribute("cloudflare_record", "mx", "type")
+	assert.False(t, ok, "no snapshot")
+}
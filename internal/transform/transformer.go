@@ -3,6 +3,8 @@ package transform
 import (
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclwrite"
+
+	tfhcl "github.com/cloudflare/tf-migrate/internal/transform/hcl"
 )
 
 // DiagInfo is an informational diagnostic severity level.
@@ -23,6 +25,73 @@ type Context struct {
 	Resources     []string
 	SourceVersion string // Source provider version (e.g., "v4")
 	TargetVersion string // Target provider version (e.g., "v5")
+
+	// CollectMigrationBlocks diverts the moved, import and removed blocks
+	// returned by migrators into MigrationBlocks instead of writing them next
+	// to their resource, so the caller can emit them into a dedicated file.
+	CollectMigrationBlocks bool
+	MigrationBlocks        []*hclwrite.Block
+
+	// JSONSource holds the original content of a .tf.json file, which the
+	// pipeline migrates in native syntax and converts back to JSON at the end.
+	// It is nil for files in native syntax.
+	JSONSource []byte
+
+	// State is the v4 state snapshot given with --state-file, or nil. Use
+	// StateAttribute to read from it.
+	State *StateSnapshot
+
+	// EvalContext holds the variables and locals of the module the file
+	// belongs to, or is nil. Use EvaluateAttribute to evaluate against it.
+	EvalContext *hcl.EvalContext
+
+	// SkipTypes holds the resource types, with the "data." prefix for data
+	// sources, whose blocks are left as they are in this file.
+	SkipTypes map[string]bool
+
+	// Ignored holds the "# tf-migrate:ignore" annotations of the file, set
+	// by PreprocessHandler. Ignored blocks are left as they are.
+	Ignored tfhcl.IgnoreAnnotations
+
+	// Review, when set, is called for every block a migrator transformed,
+	// before the transformation is applied, and decides what is written in
+	// its place. Files are reviewed one block at a time, in source order.
+	Review func(*BlockReview) (ReviewDecision, error)
+}
+
+// BlockReview describes the transformation of one block for Context.Review.
+type BlockReview struct {
+	Filename string
+	// Address is the resource address of the block, e.g.
+	// "cloudflare_record.www" or "data.cloudflare_zones.all".
+	Address string
+	// Original is a copy of the block as it was before the transformation.
+	Original *hclwrite.Block
+	// Result holds the blocks the migrator produced. For in-place
+	// transformations it holds the transformed block itself.
+	Result *TransformResult
+	// Diagnostics are the diagnostics the migrator reported for the block.
+	Diagnostics hcl.Diagnostics
+}
+
+// ReviewAction is the outcome of reviewing a block.
+type ReviewAction int
+
+const (
+	// ReviewAccept applies the transformation.
+	ReviewAccept ReviewAction = iota
+	// ReviewReject keeps the original block, marked as one to migrate by
+	// hand, and drops the diagnostics of its transformation.
+	ReviewReject
+	// ReviewEdit writes ReviewDecision.Blocks in place of the original block.
+	ReviewEdit
+)
+
+// ReviewDecision is the answer of Context.Review for a block.
+type ReviewDecision struct {
+	Action ReviewAction
+	// Blocks holds the blocks to write for ReviewEdit.
+	Blocks []*hclwrite.Block
 }
 
 // TransformResult represents the result of a resource transformation
@@ -134,6 +203,25 @@ type InvalidAttributeReferenceDetector interface {
 	GetInvalidAttributeReferences() []InvalidAttributeReference
 }
 
+// AlreadyMigratedDetector is an optional interface for migrators whose v4 and
+// v5 configuration share a resource type name. IsAlreadyMigrated reports whether
+// the block is already in v5 form; such blocks are left untouched so that
+// re-running a migration over migrated configuration is a no-op.
+type AlreadyMigratedDetector interface {
+	IsAlreadyMigrated(block *hclwrite.Block) bool
+}
+
+// ConfigScanner is an optional interface for migrators whose transformation of
+// one block depends on blocks of their type in other files. Files are
+// transformed in parallel, so such a migrator cannot rely on having seen the
+// other files first; instead ScanConfig is called with every block it handles,
+// in every file, before any file is transformed. Calls may be concurrent.
+// Reset is called before each scan, to forget what an earlier one saw.
+type ConfigScanner interface {
+	ScanConfig(block *hclwrite.Block)
+	Reset()
+}
+
 // MigrationProvider specifies the interface for a migrator provider
 // This is used to provide a way to get migrators for a given resource type
 // a migrator defines the strategy which a resource uses to migrate the resource
@@ -3,6 +3,7 @@ package verifydrift
 import (
 	"fmt"
 	"io/fs"
+	"os"
 	"path"
 	"strings"
 
@@ -44,10 +45,24 @@ type ExemptedGroup struct {
 	Lines []string
 }
 
-// Verify analyses planText against the embedded drift exemptions and returns
-// a structured result. Resources are auto-detected from the plan output.
-func Verify(planText string) (VerifyResult, error) {
-	cfg, err := loadEmbeddedExemptions(planText)
+// Verify analyses planText against the embedded drift exemptions, and the
+// exemptions of extraFiles, and returns a structured result. Resources are
+// auto-detected from the plan output.
+func Verify(planText string, extraFiles ...string) (VerifyResult, error) {
+	var extra []*e2e.DriftExemptionsConfig
+	for _, file := range extraFiles {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return VerifyResult{}, fmt.Errorf("reading drift exemptions: %w", err)
+		}
+		cfg, err := e2e.ParseDriftExemptionsConfig(data, "file:"+file)
+		if err != nil {
+			return VerifyResult{}, fmt.Errorf("parsing drift exemptions %s: %w", file, err)
+		}
+		extra = append(extra, cfg)
+	}
+
+	cfg, err := loadEmbeddedExemptions(planText, extra...)
 	if err != nil {
 		return VerifyResult{}, fmt.Errorf("loading drift exemptions: %w", err)
 	}
@@ -76,13 +91,13 @@ func Verify(planText string) (VerifyResult, error) {
 // loadEmbeddedExemptions builds a *DriftExemptionsConfig by reading from the
 // embedded FS. It always loads all per-resource YAML files regardless of the
 // plan contents — the full set is small and it ensures no exemption is missed.
-func loadEmbeddedExemptions(planText string) (*e2e.DriftExemptionsConfig, error) {
+func loadEmbeddedExemptions(planText string, extra ...*e2e.DriftExemptionsConfig) (*e2e.DriftExemptionsConfig, error) {
 	// Write the embedded FS to a temp directory so LoadDriftExemptionsFromDir
 	// (which expects an os.DirFS-style path) can consume it. This avoids
 	// duplicating the YAML parsing logic.
 	//
 	// We use a virtual approach instead: parse directly from the embed.FS.
-	return loadFromFS(embeddedExemptions)
+	return loadFromFS(embeddedExemptions, extra...)
 }
 
 // loadFromFS parses a DriftExemptionsConfig from an fs.FS whose layout mirrors
@@ -90,7 +105,10 @@ func loadEmbeddedExemptions(planText string) (*e2e.DriftExemptionsConfig, error)
 //
 //	exemptions/global-drift-exemptions.yaml
 //	exemptions/drift-exemptions/<resource>.yaml
-func loadFromFS(fsys fs.FS) (*e2e.DriftExemptionsConfig, error) {
+//
+// The exemptions of extra are checked before the global ones, and like them
+// apply to every resource type unless they list resource_types.
+func loadFromFS(fsys fs.FS, extra ...*e2e.DriftExemptionsConfig) (*e2e.DriftExemptionsConfig, error) {
 	globalData, err := fs.ReadFile(fsys, "exemptions/global-drift-exemptions.yaml")
 	if err != nil {
 		return nil, fmt.Errorf("reading global exemptions: %w", err)
@@ -100,6 +118,11 @@ func loadFromFS(fsys fs.FS) (*e2e.DriftExemptionsConfig, error) {
 	if err != nil {
 		return nil, fmt.Errorf("parsing global exemptions: %w", err)
 	}
+	var exemptions []e2e.DriftExemption
+	for _, cfg := range extra {
+		exemptions = append(exemptions, cfg.Exemptions...)
+	}
+	globalCfg.Exemptions = append(exemptions, globalCfg.Exemptions...)
 
 	resourceCfgs := make(map[string]*e2e.DriftExemptionsConfig)
 
@@ -1,6 +1,8 @@
 package verifydrift
 
 import (
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -92,6 +94,33 @@ func TestVerify_DetectsResources(t *testing.T) {
 	}
 }
 
+func TestVerify_ExtraExemptionsFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "drift-exemptions.yaml")
+	err := os.WriteFile(file, []byte(`version: 1
+exemptions:
+  - name: "record_value_rotation"
+    description: "Record values are rotated outside Terraform."
+    resource_types: ["cloudflare_dns_record"]
+    patterns: ['value = ".*" -> ".*"']
+    enabled: true
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := Verify(planRealDrift, file)
+	if err != nil {
+		t.Fatalf("Verify returned error: %v", err)
+	}
+	if result.HasUnexpected {
+		t.Errorf("expected the drift to be exempted by %s, got UnexpectedDrift=%v", file, result.UnexpectedDrift)
+	}
+
+	if _, err := Verify(planRealDrift, filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Errorf("expected an error for a missing exemptions file")
+	}
+}
+
 // --- parseExemptionTag unit tests ---
 
 func TestParseExemptionTag_WithTag(t *testing.T) {
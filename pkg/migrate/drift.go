@@ -0,0 +1,56 @@
+package migrate
+
+import (
+	"github.com/cloudflare/tf-migrate/internal/verifydrift"
+)
+
+// DriftReport is the outcome of VerifyDrift.
+type DriftReport struct {
+	// Resources lists the Cloudflare resource types found in the plan, e.g.
+	// "dns_record".
+	Resources []string
+	// Exempted groups the plan changes that are known, expected consequences
+	// of the migration by the exemption rule that matched them.
+	Exempted []ExemptedDrift
+	// Unexpected lists the plan changes no exemption matched. They need
+	// attention before applying.
+	Unexpected []string
+	// Computed lists the "(known after apply)" changes, which are always
+	// ignored.
+	Computed []string
+}
+
+// ExemptedDrift is a set of plan lines matched by one exemption rule.
+type ExemptedDrift struct {
+	Rule        string
+	Description string
+	Lines       []string
+}
+
+// HasUnexpected reports whether the plan contains unexpected drift.
+func (r *DriftReport) HasUnexpected() bool {
+	return len(r.Unexpected) > 0
+}
+
+// VerifyDrift checks the text output of `terraform plan`, run after a
+// migration, against the drift exemptions that ship with tf-migrate.
+func VerifyDrift(plan []byte) (*DriftReport, error) {
+	result, err := verifydrift.Verify(string(plan))
+	if err != nil {
+		return nil, err
+	}
+
+	report := &DriftReport{
+		Resources:  result.DetectedResources,
+		Unexpected: result.UnexpectedDrift,
+		Computed:   result.ComputedLines,
+	}
+	for _, g := range result.ExemptedGroups {
+		report.Exempted = append(report.Exempted, ExemptedDrift{
+			Rule:        g.RuleName,
+			Description: g.Description,
+			Lines:       g.Lines,
+		})
+	}
+	return report, nil
+}
@@ -0,0 +1,96 @@
+package migrate
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+
+	tfhcl "github.com/cloudflare/tf-migrate/internal/transform/hcl"
+)
+
+// evalContexts builds, once per directory, the evaluation context that lets
+// migrators resolve expressions over variables and locals. The variables of a
+// directory that another one among the files calls as a local module are set
+// by the module call, so they are left unresolved.
+type evalContexts struct {
+	files   map[string][]byte
+	modules map[string]bool
+	byDir   map[string]*hcl.EvalContext
+}
+
+func newEvalContexts(files map[string][]byte) *evalContexts {
+	return &evalContexts{files: files, modules: calledModules(files), byDir: make(map[string]*hcl.EvalContext)}
+}
+
+// calledModules returns the directories that the module blocks in files call
+// with a local source.
+func calledModules(files map[string][]byte) map[string]bool {
+	modules := make(map[string]bool)
+	for path, content := range files {
+		if !isConfigFile(path) {
+			continue
+		}
+		parsed, diags := tfhcl.ParseConfigFile(content, filepath.Base(path))
+		if diags.HasErrors() {
+			continue
+		}
+		for _, block := range parsed.Body().Blocks() {
+			if block.Type() != "module" || len(block.Labels()) != 1 {
+				continue
+			}
+			source := tfhcl.ExtractStringFromAttribute(block.Body().GetAttribute("source"))
+			if strings.HasPrefix(source, "./") || strings.HasPrefix(source, "../") {
+				modules[filepath.Join(filepath.Dir(path), source)] = true
+			}
+		}
+	}
+	return modules
+}
+
+// forFile returns the evaluation context of the directory containing file.
+func (e *evalContexts) forFile(file string) *hcl.EvalContext {
+	dir := filepath.Dir(file)
+	if ctx, ok := e.byDir[dir]; ok {
+		return ctx
+	}
+
+	var configFiles []tfhcl.SourceFile
+	var auto []string
+	vars := make(map[string][]byte)
+	for _, path := range sortedPaths(e.files) {
+		if filepath.Dir(path) != dir {
+			continue
+		}
+		name := filepath.Base(path)
+		switch {
+		case isConfigFile(path):
+			configFiles = append(configFiles, tfhcl.SourceFile{Name: path, Content: e.files[path]})
+		case name == "terraform.tfvars" || name == "terraform.tfvars.json":
+			vars[name] = e.files[path]
+		case strings.HasSuffix(name, ".auto.tfvars") || strings.HasSuffix(name, ".auto.tfvars.json"):
+			vars[name] = e.files[path]
+			auto = append(auto, name)
+		}
+	}
+	sort.Strings(auto)
+
+	// Terraform loads terraform.tfvars, then terraform.tfvars.json, then the
+	// *.auto.tfvars files in lexical order.
+	var varFiles []tfhcl.SourceFile
+	for _, name := range append([]string{"terraform.tfvars", "terraform.tfvars.json"}, auto...) {
+		if content, ok := vars[name]; ok {
+			varFiles = append(varFiles, tfhcl.SourceFile{Name: name, Content: content})
+		}
+	}
+
+	var ctx *hcl.EvalContext
+	if e.modules[dir] {
+		ctx = tfhcl.BuildModuleEvalContext(configFiles)
+	} else {
+		ctx = tfhcl.BuildEvalContext(configFiles, varFiles)
+	}
+	e.byDir[dir] = ctx
+	return ctx
+}
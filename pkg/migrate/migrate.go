@@ -0,0 +1,359 @@
+// Package migrate is the Go API of tf-migrate. It migrates Terraform
+// configuration held in memory, scans it before a migration, and checks a
+// plan taken after one for unexpected drift.
+//
+// Nothing in this package reads or writes the filesystem, prints, or exits
+// the process: configuration goes in as a map of path to content, and
+// results and diagnostics come back as values. Paths are only used to name
+// files in results and to group files into modules by directory.
+package migrate
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/hcl/v2"
+
+	"github.com/cloudflare/tf-migrate/internal"
+	"github.com/cloudflare/tf-migrate/internal/pipeline"
+	"github.com/cloudflare/tf-migrate/internal/postprocess"
+	"github.com/cloudflare/tf-migrate/internal/registry"
+	"github.com/cloudflare/tf-migrate/internal/rules"
+	"github.com/cloudflare/tf-migrate/internal/schema"
+	"github.com/cloudflare/tf-migrate/internal/transform"
+	tfhcl "github.com/cloudflare/tf-migrate/internal/transform/hcl"
+)
+
+// Default migration path.
+const (
+	DefaultSourceVersion = "v4"
+	DefaultTargetVersion = "v5"
+)
+
+var supportedPaths = map[string]bool{
+	"v4-v5": true,
+	"v5-v5": true,
+}
+
+var registerOnce sync.Once
+
+// migrateMu serialises migrations: the registered migrators are shared, and
+// those that scan the configuration keep what they saw until the files are
+// transformed.
+var migrateMu sync.Mutex
+
+// Options configures a migration or a preflight scan. The zero value
+// migrates every resource type from v4 to v5.
+type Options struct {
+	// SourceVersion and TargetVersion select the migration path. They
+	// default to DefaultSourceVersion and DefaultTargetVersion.
+	SourceVersion string
+	TargetVersion string
+
+	// Resources restricts the migration to these resource types, e.g.
+	// "cloudflare_record" or "data.cloudflare_zone". Empty means all types.
+	Resources []string
+
+	// State is a state snapshot, in `terraform show -json` or
+	// `terraform state pull` format, that migrators read v4 attribute values
+	// from when the configuration does not spell them out.
+	State []byte
+
+	// ProviderSchema is `terraform providers schema -json` output to
+	// validate the migrated files against, as with --schema-file.
+	ProviderSchema []byte
+
+	// Rules maps the names of declarative migration rule files to their
+	// content, as with --rules-file. A rule replaces the built-in migrator
+	// of its type. The rules only apply to this call.
+	Rules map[string][]byte
+
+	// Logger receives debug logging. It defaults to a logger that discards
+	// everything.
+	Logger hclog.Logger
+}
+
+func (o Options) withDefaults() Options {
+	if o.SourceVersion == "" {
+		o.SourceVersion = DefaultSourceVersion
+	}
+	if o.TargetVersion == "" {
+		o.TargetVersion = DefaultTargetVersion
+	}
+	if o.Logger == nil {
+		o.Logger = hclog.NewNullLogger()
+	}
+	return o
+}
+
+func (o Options) validate() error {
+	if !supportedPaths[o.SourceVersion+"-"+o.TargetVersion] {
+		return fmt.Errorf("unsupported migration path: %s-%s", o.SourceVersion, o.TargetVersion)
+	}
+	return nil
+}
+
+// providers returns the migrators of the registry, which is populated on
+// first use, with the migrators of o.Rules layered over them.
+func (o Options) providers() (transform.MigrationProvider, error) {
+	registerOnce.Do(registry.RegisterAllMigrations)
+	resources := o.Resources
+	var providers transform.MigrationProvider = transform.NewMigrationProvider(
+		internal.GetMigrator,
+		func(source, target string, _ ...string) []transform.ResourceTransformer {
+			return internal.GetAllMigrators(source, target, resources...)
+		},
+	)
+	if len(o.Rules) == 0 {
+		return providers, nil
+	}
+
+	names := make([]string, 0, len(o.Rules))
+	for name := range o.Rules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var all []*rules.Rule
+	for _, name := range names {
+		parsed, err := rules.Parse(o.Rules[name], name)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, parsed...)
+	}
+	return rules.Provider(providers, all), nil
+}
+
+// Severity is the severity of a Diagnostic.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	// SeverityInfo diagnostics are informational, such as a value resolved
+	// from variable defaults. The CLI only shows them with --verbose.
+	SeverityInfo Severity = "info"
+)
+
+// Diagnostic is a problem or note reported while migrating.
+type Diagnostic struct {
+	Severity Severity
+	Summary  string
+	Detail   string
+	// File is the path of the file the diagnostic is about, when known.
+	File string
+	// Line is the 1-based line in File, or 0 when unknown.
+	Line int
+}
+
+func (d Diagnostic) String() string {
+	var b strings.Builder
+	if d.File != "" {
+		b.WriteString(d.File)
+		if d.Line > 0 {
+			fmt.Fprintf(&b, ":%d", d.Line)
+		}
+		b.WriteString(": ")
+	}
+	fmt.Fprintf(&b, "%s: %s", d.Severity, d.Summary)
+	if d.Detail != "" {
+		b.WriteString("; " + d.Detail)
+	}
+	return b.String()
+}
+
+// Result is the outcome of Migrate.
+type Result struct {
+	// Files holds every input file: the migrated content of configuration
+	// files, and every other file unchanged.
+	Files map[string][]byte
+	// Changed lists, sorted, the paths whose content differs from the input.
+	Changed []string
+	// Diagnostics holds the diagnostics of all files, in path order.
+	Diagnostics []Diagnostic
+}
+
+// HasErrors reports whether any diagnostic is an error. A file with an error
+// is returned unchanged.
+func (r *Result) HasErrors() bool {
+	for _, d := range r.Diagnostics {
+		if d.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// Migrate migrates files, a map of path to content. Files ending in .tf or
+// .tf.json are migrated; all others, including .tfvars files, are returned
+// unchanged. After every file is migrated, references to renamed resource
+// types and attributes are rewritten across all files, as the CLI does.
+//
+// Variables and locals are resolved per directory from the configuration
+// files in that directory and the terraform.tfvars and *.auto.tfvars files
+// among files. The variables of a directory called as a local module by
+// another one are set by the module call and are left unresolved. A file
+// that fails to parse or migrate is reported with an error diagnostic and
+// returned unchanged; the error return is only for invalid options.
+//
+// Each call only works on the files it is given. Concurrent calls are run
+// one at a time.
+func Migrate(files map[string][]byte, opts Options) (*Result, error) {
+	migrateMu.Lock()
+	defer migrateMu.Unlock()
+
+	opts = opts.withDefaults()
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+
+	var state *transform.StateSnapshot
+	if len(opts.State) > 0 {
+		var err error
+		if state, err = transform.ParseStateSnapshot(opts.State); err != nil {
+			return nil, err
+		}
+	}
+
+	var providerSchema *schema.ProviderSchema
+	if len(opts.ProviderSchema) > 0 {
+		var err error
+		if providerSchema, err = schema.Parse(opts.ProviderSchema); err != nil {
+			return nil, err
+		}
+	}
+
+	providers, err := opts.providers()
+	if err != nil {
+		return nil, err
+	}
+
+	log := opts.Logger
+	p := pipeline.BuildConfigPipeline(log, providers)
+	evalContexts := newEvalContexts(files)
+
+	paths := sortedPaths(files)
+	var sources []tfhcl.SourceFile
+	for _, path := range paths {
+		if isConfigFile(path) {
+			sources = append(sources, tfhcl.SourceFile{Name: path, Content: files[path]})
+		}
+	}
+	pipeline.ScanConfigs(log, providers, opts.SourceVersion, opts.TargetVersion, sources, 0)
+
+	result := &Result{Files: make(map[string][]byte, len(files))}
+	var configPaths []string
+	contents := make(map[string]string)
+	for _, path := range paths {
+		content := files[path]
+		result.Files[path] = content
+		if !isConfigFile(path) {
+			continue
+		}
+
+		ctx := &transform.Context{
+			Content:       content,
+			Filename:      filepath.Base(path),
+			FilePath:      path,
+			Diagnostics:   make(hcl.Diagnostics, 0),
+			Metadata:      make(map[string]interface{}),
+			SourceVersion: opts.SourceVersion,
+			TargetVersion: opts.TargetVersion,
+			Resources:     opts.Resources,
+			State:         state,
+			EvalContext:   evalContexts.forFile(path),
+		}
+		transformed, err := transformFile(p, ctx)
+		diags := ctx.Diagnostics
+		if err != nil && !diags.HasErrors() {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Failed to migrate file",
+				Detail:   err.Error(),
+			})
+		}
+		result.Diagnostics = append(result.Diagnostics, convertDiagnostics(path, diags)...)
+		if err != nil {
+			log.Debug("Failed to migrate file", "file", path, "error", err)
+			continue
+		}
+
+		configPaths = append(configPaths, path)
+		contents[path] = string(transformed)
+	}
+
+	rules := postprocess.CollectRules(log, providers.GetAllMigrators(opts.SourceVersion, opts.TargetVersion, opts.Resources...))
+	if !rules.Empty() {
+		post := postprocess.Apply(log, rules, configPaths, contents, 0)
+		result.Diagnostics = append(result.Diagnostics, convertDiagnostics("", post.Diagnostics)...)
+	}
+
+	if providerSchema != nil {
+		for _, path := range configPaths {
+			diags := schema.Validate(providerSchema, path, []byte(contents[path]))
+			result.Diagnostics = append(result.Diagnostics, convertDiagnostics(path, diags)...)
+		}
+	}
+
+	for _, path := range configPaths {
+		if contents[path] != string(files[path]) {
+			result.Files[path] = []byte(contents[path])
+			result.Changed = append(result.Changed, path)
+		}
+	}
+	return result, nil
+}
+
+// transformFile runs the pipeline over one file, turning a panic in a
+// migrator into an error so that one bad file cannot crash the caller.
+func transformFile(p *pipeline.Pipeline, ctx *transform.Context) (content []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("migrator panicked: %v", r)
+		}
+	}()
+	return p.Transform(ctx)
+}
+
+// isConfigFile reports whether path is a Terraform configuration file.
+func isConfigFile(path string) bool {
+	return strings.HasSuffix(path, ".tf") || tfhcl.IsJSONConfigFile(filepath.Base(path))
+}
+
+func sortedPaths(files map[string][]byte) []string {
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// convertDiagnostics converts hcl diagnostics. Diagnostics without a subject
+// are attributed to file.
+func convertDiagnostics(file string, diags hcl.Diagnostics) []Diagnostic {
+	out := make([]Diagnostic, 0, len(diags))
+	for _, d := range diags {
+		diag := Diagnostic{Summary: d.Summary, Detail: d.Detail, File: file}
+		switch d.Severity {
+		case hcl.DiagError:
+			diag.Severity = SeverityError
+		case hcl.DiagWarning:
+			diag.Severity = SeverityWarning
+		default:
+			diag.Severity = SeverityInfo
+		}
+		if d.Subject != nil {
+			if file == "" {
+				diag.File = d.Subject.Filename
+			}
+			diag.Line = d.Subject.Start.Line
+		}
+		out = append(out, diag)
+	}
+	return out
+}
@@ -0,0 +1,296 @@
+package migrate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const records = `resource "cloudflare_record" "www" {
+  zone_id = var.zone_id
+  name    = "www"
+  type    = "A"
+  value   = "192.0.2.1"
+}
+`
+
+const outputs = `output "hostname" {
+  value = cloudflare_record.www.hostname
+}
+`
+
+func TestMigrate(t *testing.T) {
+	files := map[string][]byte{
+		"dns/records.tf":  []byte(records),
+		"dns/outputs.tf":  []byte(outputs),
+		"dns/README.md":   []byte("# DNS\n"),
+		"other/empty.tf":  []byte("locals {}\n"),
+		"other/notes.txt": []byte("cloudflare_record.www.hostname\n"),
+	}
+
+	result, err := Migrate(files, Options{})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"dns/outputs.tf", "dns/records.tf"}, result.Changed)
+	assert.Len(t, result.Files, len(files))
+	assert.Contains(t, string(result.Files["dns/records.tf"]), `resource "cloudflare_dns_record" "www"`)
+	assert.Contains(t, string(result.Files["dns/records.tf"]), `content = "192.0.2.1"`)
+	assert.Contains(t, string(result.Files["dns/outputs.tf"]), "cloudflare_dns_record.www.name")
+	assert.Equal(t, "# DNS\n", string(result.Files["dns/README.md"]))
+	assert.Equal(t, "cloudflare_record.www.hostname\n", string(result.Files["other/notes.txt"]))
+	assert.False(t, result.HasErrors())
+
+	// The input is never modified.
+	assert.Equal(t, records, string(files["dns/records.tf"]))
+}
+
+func TestMigrateParseError(t *testing.T) {
+	files := map[string][]byte{
+		"bad.tf":  []byte("resource \"cloudflare_record\" {\n"),
+		"good.tf": []byte(records),
+	}
+
+	result, err := Migrate(files, Options{})
+	require.NoError(t, err)
+
+	assert.True(t, result.HasErrors())
+	assert.Equal(t, []string{"good.tf"}, result.Changed)
+	assert.Equal(t, string(files["bad.tf"]), string(result.Files["bad.tf"]))
+	for _, d := range result.Diagnostics {
+		if d.Severity == SeverityError {
+			assert.Equal(t, "bad.tf", d.File)
+		}
+	}
+}
+
+func TestMigrateVariables(t *testing.T) {
+	files := map[string][]byte{
+		"dns/main.tf": []byte(`variable "type" {
+  default = "A"
+}
+
+resource "cloudflare_record" "srv" {
+  zone_id = "abc"
+  name    = "_sip._tcp"
+  type    = var.type
+  data {
+    priority = 10
+    weight   = 5
+    port     = 5060
+    target   = "sip.example.com"
+  }
+}
+`),
+		"dns/terraform.tfvars": []byte("type = \"SRV\"\n"),
+	}
+
+	result, err := Migrate(files, Options{})
+	require.NoError(t, err)
+
+	// Resolved as SRV from terraform.tfvars, data stays a structured attribute.
+	assert.Contains(t, string(result.Files["dns/main.tf"]), "data = {")
+	var resolved bool
+	for _, d := range result.Diagnostics {
+		if d.Severity == SeverityInfo && strings.Contains(d.Summary, "var.type") {
+			resolved = true
+			assert.Equal(t, "dns/main.tf", d.File)
+		}
+	}
+	assert.True(t, resolved, "expected var.type to be resolved statically, got %v", result.Diagnostics)
+}
+
+func TestMigrateModuleVariables(t *testing.T) {
+	files := map[string][]byte{
+		"main.tf": []byte(`module "aop" {
+  source   = "./mod"
+  hostname = "app.example.com"
+}
+`),
+		"mod/main.tf": []byte(`variable "hostname" {
+  default = null
+}
+
+resource "cloudflare_authenticated_origin_pulls" "a" {
+  zone_id  = "abc"
+  hostname = var.hostname
+  enabled  = true
+}
+`),
+	}
+
+	result, err := Migrate(files, Options{})
+	require.NoError(t, err)
+
+	// The caller sets hostname, so the default does not decide the type.
+	migrated := string(result.Files["mod/main.tf"])
+	assert.Contains(t, migrated, `resource "cloudflare_authenticated_origin_pulls" "a"`)
+	assert.Contains(t, migrated, "hostname = var.hostname")
+}
+
+func TestMigrateCallsAreIndependent(t *testing.T) {
+	a := map[string][]byte{"main.tf": []byte(`resource "cloudflare_authenticated_origin_pulls_certificate" "cert" {
+  zone_id     = "abc"
+  type        = "per-hostname"
+  certificate = "cert"
+  private_key = "key"
+}
+`)}
+	b := map[string][]byte{"main.tf": []byte(`resource "cloudflare_authenticated_origin_pulls" "aop" {
+  zone_id                                = "abc"
+  hostname                               = "app.example.com"
+  authenticated_origin_pulls_certificate = cloudflare_authenticated_origin_pulls_certificate.cert.id
+  enabled                                = true
+}
+`)}
+
+	first, err := Migrate(b, Options{})
+	require.NoError(t, err)
+	assert.Contains(t, string(first.Files["main.tf"]), "cert_id  = cloudflare_authenticated_origin_pulls_certificate.cert.id")
+
+	// The per-hostname certificate of an earlier call is not in b.
+	_, err = Migrate(a, Options{})
+	require.NoError(t, err)
+	second, err := Migrate(b, Options{})
+	require.NoError(t, err)
+	assert.Equal(t, string(first.Files["main.tf"]), string(second.Files["main.tf"]))
+}
+
+func TestMigrateSchemaValidation(t *testing.T) {
+	providerSchema := `{
+  "format_version": "1.0",
+  "provider_schemas": {
+    "registry.terraform.io/cloudflare/cloudflare": {
+      "resource_schemas": {
+        "cloudflare_dns_record": {
+          "version": 0,
+          "block": {
+            "attributes": {
+              "zone_id": {"type": "string", "required": true},
+              "name": {"type": "string", "required": true},
+              "type": {"type": "string", "required": true},
+              "ttl": {"type": "number", "required": true}
+            }
+          }
+        }
+      }
+    }
+  }
+}`
+	files := map[string][]byte{"records.tf": []byte(records)}
+
+	result, err := Migrate(files, Options{ProviderSchema: []byte(providerSchema)})
+	require.NoError(t, err)
+
+	var unknown bool
+	for _, d := range result.Diagnostics {
+		if strings.Contains(d.Summary, "content") {
+			unknown = true
+			assert.Equal(t, SeverityWarning, d.Severity)
+			assert.Equal(t, "records.tf", d.File)
+			assert.Positive(t, d.Line)
+		}
+	}
+	assert.True(t, unknown, "expected content to be reported as unknown, got %v", result.Diagnostics)
+}
+
+func TestMigrateRules(t *testing.T) {
+	files := map[string][]byte{
+		"main.tf": []byte(`resource "cloudflare_widget" "w" {
+  zone_id = "abc"
+  label   = "w"
+}
+`),
+		"outputs.tf": []byte(`output "label" {
+  value = cloudflare_widget.w.label
+}
+`),
+	}
+	ruleFile := []byte(`rules:
+  - type: cloudflare_widget
+    rename_to: cloudflare_gizmo
+    rename:
+      label: name
+`)
+
+	result, err := Migrate(files, Options{Rules: map[string][]byte{"widget.yaml": ruleFile}})
+	require.NoError(t, err)
+	assert.Contains(t, string(result.Files["main.tf"]), `resource "cloudflare_gizmo" "w"`)
+	assert.Contains(t, string(result.Files["main.tf"]), "from = cloudflare_widget.w")
+	assert.Contains(t, string(result.Files["outputs.tf"]), "cloudflare_gizmo.w.name")
+
+	// The rules only apply to the call they are given to.
+	result, err = Migrate(files, Options{})
+	require.NoError(t, err)
+	assert.Empty(t, result.Changed)
+
+	_, err = Migrate(files, Options{Rules: map[string][]byte{"bad.yaml": []byte("rules:\n  - rename_to: x\n")}})
+	assert.ErrorContains(t, err, "bad.yaml: rule 1 (): type is required")
+}
+
+func TestMigrateInvalidOptions(t *testing.T) {
+	_, err := Migrate(nil, Options{SourceVersion: "v3"})
+	assert.EqualError(t, err, "unsupported migration path: v3-v5")
+
+	_, err = Migrate(nil, Options{State: []byte("not json")})
+	assert.Error(t, err)
+}
+
+func TestPreflight(t *testing.T) {
+	files := map[string][]byte{
+		"main.tf": []byte(records + `
+resource "cloudflare_made_up_thing" "x" {
+  zone_id = "abc"
+}
+`),
+		"moved.tf": []byte(`moved {
+  from = cloudflare_record.www
+  to   = cloudflare_dns_record.www
+}
+`),
+	}
+
+	report, err := Preflight(files, Options{})
+	require.NoError(t, err)
+
+	require.Len(t, report.Resources, 2)
+	assert.Equal(t, PreflightResource{
+		File:    "main.tf",
+		Type:    "cloudflare_record",
+		Name:    "www",
+		Class:   ClassRenamed,
+		NewType: "cloudflare_dns_record",
+	}, report.Resources[0])
+	assert.Equal(t, ClassUnsupported, report.Resources[1].Class)
+	assert.Equal(t, []MovedBlock{{
+		File:     "moved.tf",
+		FromType: "cloudflare_record",
+		FromName: "www",
+		ToType:   "cloudflare_dns_record",
+		ToName:   "www",
+	}}, report.MovedBlocks)
+	require.Len(t, report.Warnings, 1)
+	assert.Contains(t, report.Warnings[0], "moved.tf")
+}
+
+func TestVerifyDrift(t *testing.T) {
+	plan := `
+Terraform used the selected providers to generate the following execution plan.
+
+  # module.dns_record.cloudflare_dns_record.example will be updated in-place
+  ~ resource "cloudflare_dns_record" "example" {
+      ~ ttl   = (known after apply)
+      ~ value = "old-value" -> "new-value"
+    }
+
+Plan: 0 to add, 1 to change, 0 to destroy.
+`
+	report, err := VerifyDrift([]byte(plan))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"dns_record"}, report.Resources)
+	assert.True(t, report.HasUnexpected())
+	require.Len(t, report.Unexpected, 1)
+	assert.Contains(t, report.Unexpected[0], `"old-value" -> "new-value"`)
+}
@@ -0,0 +1,115 @@
+package migrate
+
+import (
+	"github.com/cloudflare/tf-migrate/internal/preflight"
+)
+
+// ResourceClass describes how a resource will be handled by a migration.
+type ResourceClass string
+
+const (
+	// ClassAutoMigrated resources are migrated fully automatically.
+	ClassAutoMigrated ResourceClass = "auto-migrated"
+	// ClassRenamed resources change type; a moved block is generated.
+	ClassRenamed ResourceClass = "renamed"
+	// ClassManualIntervention resources are migrated but need manual steps.
+	ClassManualIntervention ResourceClass = "manual"
+	// ClassUnsupported resources have no migrator.
+	ClassUnsupported ResourceClass = "unsupported"
+	// ClassIgnored resources are annotated with "# tf-migrate:ignore", or
+	// their file with "# tf-migrate:ignore-file", and are left as they are.
+	ClassIgnored ResourceClass = "ignored"
+)
+
+// PreflightResource is a Cloudflare resource found by Preflight.
+type PreflightResource struct {
+	File  string
+	Type  string
+	Name  string
+	Class ResourceClass
+	// NewType is the type the resource is renamed to; only set for
+	// ClassRenamed.
+	NewType string
+	// Detail explains what to do for ClassManualIntervention and
+	// ClassUnsupported resources, and why ClassIgnored ones are ignored.
+	Detail string
+}
+
+// MovedBlock is a hand-written moved block found by Preflight.
+type MovedBlock struct {
+	File     string
+	FromType string
+	FromName string
+	ToType   string
+	ToName   string
+}
+
+// PreflightReport is the outcome of Preflight.
+type PreflightReport struct {
+	Resources   []PreflightResource
+	MovedBlocks []MovedBlock
+	// Warnings describe hand-written moved blocks that duplicate or conflict
+	// with the ones the migration generates.
+	Warnings []string
+}
+
+// Preflight scans files, a map of path to content, before a migration: it
+// classifies every Cloudflare resource by how it will be migrated, and warns
+// about hand-written moved blocks that conflict with the migration. Only
+// .tf and .tf.json files are scanned; files that fail to parse are skipped.
+func Preflight(files map[string][]byte, opts Options) (*PreflightReport, error) {
+	opts = opts.withDefaults()
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+
+	providers, err := opts.providers()
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, path := range sortedPaths(files) {
+		if isConfigFile(path) {
+			paths = append(paths, path)
+		}
+	}
+
+	scan := preflight.Scan(opts.Logger, preflight.Options{
+		Providers:     providers,
+		SourceVersion: opts.SourceVersion,
+		TargetVersion: opts.TargetVersion,
+		Resources:     opts.Resources,
+	}, paths, files)
+
+	report := &PreflightReport{Warnings: scan.Warnings}
+	for _, r := range scan.Resources {
+		report.Resources = append(report.Resources, PreflightResource{
+			File:    r.File,
+			Type:    r.ResourceType,
+			Name:    r.ResourceName,
+			Class:   resourceClass(r.Class),
+			NewType: r.NewType,
+			Detail:  r.Detail,
+		})
+	}
+	for _, mb := range scan.MovedBlocks {
+		report.MovedBlocks = append(report.MovedBlocks, MovedBlock(mb))
+	}
+	return report, nil
+}
+
+func resourceClass(c preflight.Class) ResourceClass {
+	switch c {
+	case preflight.ClassRenamed:
+		return ClassRenamed
+	case preflight.ClassManualIntervention:
+		return ClassManualIntervention
+	case preflight.ClassUnsupported:
+		return ClassUnsupported
+	case preflight.ClassIgnored:
+		return ClassIgnored
+	default:
+		return ClassAutoMigrated
+	}
+}